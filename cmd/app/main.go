@@ -5,15 +5,19 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"go-commerce/internal/domain"
 	"go-commerce/internal/handler/http"
 	"go-commerce/internal/handler/response"
+	"go-commerce/internal/repository/memory"
 	"go-commerce/internal/repository/mysql"
 	"go-commerce/internal/service"
 	"go-commerce/internal/usecase"
 	"go-commerce/pkg/config"
 	"go-commerce/pkg/database"
 	"go-commerce/pkg/jwt"
+	"go-commerce/pkg/pagination"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -47,6 +51,9 @@ import (
 func main() {
 	// Load configuration
 	cfg := config.Load()
+	if err := cfg.Validate(); err != nil {
+		log.Fatal("Invalid configuration: ", err)
+	}
 
 	// Initialize database
 	db, err := database.NewMySQLConnection(cfg)
@@ -54,8 +61,22 @@ func main() {
 		log.Fatal("Failed to connect to database:", err)
 	}
 
-	// Initialize JWT manager
+	// Initialize JWT manager (asymmetric keys when a key directory is configured)
 	jwtManager := jwt.NewJWTManager(cfg.JWT.Secret, cfg.JWT.ExpireHours, cfg.JWT.RefreshExpireHours)
+	if cfg.JWT.KeysDir != "" {
+		legacySecret := ""
+		if cfg.JWT.AcceptLegacyHS256 {
+			legacySecret = cfg.JWT.Secret
+		}
+		jwtManager, err = jwt.NewKeyDirJWTManager(cfg.JWT.KeysDir, cfg.JWT.SigningKeyID, legacySecret, cfg.JWT.ExpireHours, cfg.JWT.RefreshExpireHours)
+		if err != nil {
+			log.Fatal("Failed to load JWT signing keys:", err)
+		}
+	}
+	if cfg.JWT.AcceptUntypedUntil != "" {
+		acceptUntypedUntil, _ := time.Parse(time.DateOnly, cfg.JWT.AcceptUntypedUntil)
+		jwtManager.AcceptUntypedTokensUntil(acceptUntypedUntil)
+	}
 
 	// Initialize repositories
 	userRepo := mysql.NewUserRepository(db)
@@ -64,27 +85,138 @@ func main() {
 	addressRepo := mysql.NewAddressRepository(db)
 	productRepo := mysql.NewProductRepository(db)
 	photoRepo := mysql.NewPhotoProdukRepository(db)
+	productVariantRepo := mysql.NewProductVariantRepository(db)
 	transactionRepo := mysql.NewTransactionRepository(db)
+	checkoutRepo := mysql.NewCheckoutRepository(db)
 	transactionItemRepo := mysql.NewTransactionItemRepository(db)
 	productLogRepo := mysql.NewProductLogRepository(db)
 	paymentIntentRepo := mysql.NewPaymentIntentRepository(db)
+	refreshTokenRepo := mysql.NewRefreshTokenRepository(db)
+	revocationStore := mysql.NewTokenRevocationRepository(db)
+	passwordResetRepo := mysql.NewPasswordResetRepository(db)
+	loginThrottleRepo := mysql.NewLoginThrottleRepository(db)
+	roleRepo := mysql.NewRoleRepository(db)
+	recoveryCodeRepo := mysql.NewTwoFactorRecoveryCodeRepository(db)
+	settingRepo := mysql.NewSettingRepository(db)
+	cartRepo := mysql.NewCartRepository(db)
+	stockReservationRepo := mysql.NewStockReservationRepository(db)
+	paymentEventRepo := mysql.NewPaymentEventRepository(db)
+	refundRepo := mysql.NewRefundRepository(db)
+	statusHistoryRepo := mysql.NewTransactionStatusHistoryRepository(db)
+	shipmentRepo := mysql.NewShipmentRepository(db)
+	shippingRateRepo := mysql.NewShippingRateRepository(db)
+	disputeRepo := mysql.NewDisputeRepository(db)
+	invoiceSequenceRepo := mysql.NewInvoiceSequenceRepository(db)
+	searchQueryRepo := mysql.NewSearchQueryRepository(db)
+	reviewRepo := mysql.NewReviewRepository(db)
+
+	// Initialize product search index
+	var productSearchIndex domain.ProductSearchIndex
+	switch cfg.Search.Backend {
+	case "memory":
+		productSearchIndex = memory.NewProductSearchIndex()
+	default:
+		productSearchIndex = mysql.NewProductSearchIndex(db)
+	}
 
 	// Initialize services
 	regionService := service.NewIndonesiaRegionService()
-	backgroundService := service.NewBackgroundService()
+	courierTracker := service.NewFileCourierTracker(cfg.Shipping.TrackingFile)
+	mailer := service.NewMailer(cfg.Mail)
+	backgroundService := service.NewBackgroundService(revocationStore, refreshTokenRepo, passwordResetRepo, loginThrottleRepo)
 
 	// Start background jobs
 	backgroundService.StartCleanupJobs()
+	if cfg.JWT.KeysDir != "" {
+		backgroundService.StartKeyReloadJob(jwtManager, time.Duration(cfg.JWT.KeysReloadMinutes)*time.Minute)
+	}
+
+	// Initialize payment providers
+	paymentProviders, err := newPaymentProviders(cfg)
+	if err != nil {
+		log.Fatal("Failed to set up payment providers:", err)
+	}
 
 	// Initialize usecases
-	authUsecase := usecase.NewAuthUsecase(userRepo, storeRepo, jwtManager, db)
+	emailVerificationUsecase := usecase.NewEmailVerificationUsecase(userRepo, mailer, jwtManager, usecase.EmailVerificationConfig{
+		VerificationURL:     cfg.Auth.EmailVerificationURL,
+		TokenTTL:            time.Duration(cfg.Auth.EmailVerificationExpireHours) * time.Hour,
+		ResendCooldown:      time.Duration(cfg.Auth.EmailVerificationResendSeconds) * time.Second,
+		RequireForCheckout:  cfg.Auth.RequireVerifiedEmailForCheckout,
+		RequireForStoreOpen: cfg.Auth.RequireVerifiedEmailForStore,
+	})
+	passwordResetUsecase := usecase.NewPasswordResetUsecase(userRepo, passwordResetRepo, refreshTokenRepo, revocationStore, mailer, jwtManager, usecase.PasswordResetConfig{
+		ResetURL: cfg.Auth.PasswordResetURL,
+		TokenTTL: time.Duration(cfg.Auth.PasswordResetExpireMinutes) * time.Minute,
+	})
+	loginThrottleUsecase := usecase.NewLoginThrottleUsecase(loginThrottleRepo, userRepo, usecase.LoginThrottleConfig{
+		MaxAccountAttempts: cfg.Auth.LoginMaxAccountAttempts,
+		MaxIPAttempts:      cfg.Auth.LoginMaxIPAttempts,
+		AttemptWindow:      time.Duration(cfg.Auth.LoginAttemptWindowMinutes) * time.Minute,
+		BaseLockout:        time.Duration(cfg.Auth.LoginLockoutBaseSeconds) * time.Second,
+		MaxLockout:         time.Duration(cfg.Auth.LoginLockoutMaxSeconds) * time.Second,
+	})
+	twoFactorUsecase := usecase.NewTwoFactorUsecase(userRepo, recoveryCodeRepo, settingRepo, jwtManager, usecase.TwoFactorConfig{
+		Issuer:            cfg.Auth.TwoFactorIssuer,
+		ChallengeTTL:      time.Duration(cfg.Auth.TwoFactorChallengeMinutes) * time.Minute,
+		RecoveryCodeCount: cfg.Auth.TwoFactorRecoveryCodes,
+	})
+	authUsecase := usecase.NewAuthUsecase(userRepo, storeRepo, roleRepo, refreshTokenRepo, revocationStore, emailVerificationUsecase, loginThrottleUsecase, twoFactorUsecase, jwtManager, db)
+	roleUsecase := usecase.NewRoleUsecase(roleRepo, userRepo, revocationStore, jwtManager)
 	userUsecase := usecase.NewUserUsecase(userRepo)
-	storeUsecase := usecase.NewStoreUsecase(storeRepo)
-	categoryUsecase := usecase.NewCategoryUsecase(categoryRepo)
+	searchSuggestUsecase := usecase.NewSearchSuggestUsecase(memory.NewSearchSuggestIndex(), searchQueryRepo, productRepo, categoryRepo, storeRepo)
+	storeUsecase := usecase.NewStoreUsecase(storeRepo, emailVerificationUsecase, searchSuggestUsecase)
+	categoryUsecase := usecase.NewCategoryUsecase(categoryRepo, searchSuggestUsecase)
 	addressUsecase := usecase.NewAddressUsecase(addressRepo, regionService)
-	productUsecase := usecase.NewProductUsecase(productRepo, photoRepo, storeRepo, categoryRepo)
-	transactionUsecase := usecase.NewTransactionUsecase(transactionRepo, transactionItemRepo, productLogRepo, productRepo, addressRepo, userRepo, storeRepo)
-	paymentIntentUsecase := usecase.NewPaymentIntentUsecase(paymentIntentRepo, transactionRepo, transactionUsecase)
+	productUsecase := usecase.NewProductUsecase(productRepo, photoRepo, storeRepo, categoryRepo, productSearchIndex, searchSuggestUsecase)
+	productVariantUsecase := usecase.NewProductVariantUsecase(productVariantRepo, productRepo, photoRepo, storeRepo)
+	stockReservationUsecase := usecase.NewStockReservationUsecase(stockReservationRepo, productRepo, transactionRepo, usecase.StockReservationConfig{
+		HoldTTL: time.Duration(cfg.Checkout.StockHoldMinutes) * time.Minute,
+	})
+	orderStatusUsecase := usecase.NewOrderStatusUsecase(transactionRepo, statusHistoryRepo)
+	shippingRateProviders := usecase.NewShippingRateProviders()
+	shippingRateProviders.Register(service.NewRateTableShippingProvider(shippingRateRepo), "jne", "jnt", "sicepat", "pos", "anteraja", "tiki")
+	shippingUsecase := usecase.NewShippingUsecase(productRepo, storeRepo, addressRepo, shippingRateProviders)
+	invoiceNumberer := usecase.NewInvoiceNumberer(invoiceSequenceRepo, usecase.InvoiceNumberConfig{
+		InvoiceFormat:  cfg.Invoice.InvoiceFormat,
+		CheckoutFormat: cfg.Invoice.CheckoutFormat,
+	})
+	transactionUsecase := usecase.NewTransactionUsecase(transactionRepo, checkoutRepo, transactionItemRepo, productLogRepo, productRepo, addressRepo, userRepo, storeRepo, stockReservationUsecase, orderStatusUsecase, shippingUsecase, invoiceNumberer, emailVerificationUsecase)
+	paymentIntentUsecase := usecase.NewPaymentIntentUsecase(paymentIntentRepo, checkoutRepo, transactionRepo, transactionUsecase, paymentProviders, usecase.PaymentIntentConfig{
+		TTL: time.Duration(cfg.Payment.IntentExpireMinutes) * time.Minute,
+	})
+	paymentCallbackUsecase := usecase.NewPaymentCallbackUsecase(paymentEventRepo, paymentIntentRepo, paymentIntentUsecase, paymentProviders, usecase.PaymentCallbackConfig{
+		GatewaySecrets: cfg.Payment.GatewaySecrets,
+		Tolerance:      time.Duration(cfg.Payment.CallbackToleranceSeconds) * time.Second,
+	})
+	refundUsecase := usecase.NewRefundUsecase(refundRepo, transactionRepo, transactionItemRepo, checkoutRepo, productLogRepo, productRepo, orderStatusUsecase, paymentIntentUsecase)
+	shipmentUsecase := usecase.NewShipmentUsecase(shipmentRepo, transactionRepo, storeRepo, orderStatusUsecase, courierTracker)
+	orderCompletionUsecase := usecase.NewOrderCompletionUsecase(disputeRepo, transactionRepo, storeRepo, orderStatusUsecase, refundUsecase, usecase.OrderCompletionConfig{
+		AutoConfirmAfter: time.Duration(cfg.Order.AutoConfirmDays) * 24 * time.Hour,
+	})
+	cartUsecase := usecase.NewCartUsecase(cartRepo, transactionUsecase)
+	reviewUsecase := usecase.NewReviewUsecase(reviewRepo, transactionRepo, transactionItemRepo, productRepo, storeRepo, productSearchIndex)
+
+	// The in-process search index starts empty
+	if cfg.Search.Backend == "memory" {
+		indexed, err := productUsecase.RebuildSearchIndex()
+		if err != nil {
+			log.Fatal("Failed to build product search index:", err)
+		}
+		log.Printf("Indexed %d products for search", indexed)
+	}
+	suggestions, err := searchSuggestUsecase.Rebuild()
+	if err != nil {
+		log.Fatal("Failed to build search suggestions:", err)
+	}
+	log.Printf("Loaded %d search suggestions", suggestions)
+
+	// Start usecase-driven background jobs
+	backgroundService.StartStockReleaseJob(stockReservationUsecase, time.Duration(cfg.Checkout.StockReleaseIntervalSeconds)*time.Second)
+	backgroundService.StartPaymentExpiryJob(paymentIntentUsecase, time.Duration(cfg.Payment.ExpirySweepIntervalSeconds)*time.Second)
+	backgroundService.StartShipmentTrackingJob(shipmentUsecase, time.Duration(cfg.Shipping.TrackingPollIntervalSeconds)*time.Second)
+	backgroundService.StartOrderAutoConfirmJob(orderCompletionUsecase, time.Duration(cfg.Order.AutoConfirmIntervalSeconds)*time.Second)
+	backgroundService.StartSuggestionRebuildJob(searchSuggestUsecase, time.Duration(cfg.Search.SuggestRebuildIntervalMinutes)*time.Minute)
 
 	// Initialize Fiber app
 	app := fiber.New(fiber.Config{
@@ -116,15 +248,22 @@ func main() {
 	systemHandler := http.NewSystemHandler()
 
 	// Setup routes
-	router := http.NewRouter(app, jwtManager)
-	router.SetupAuthRoutes(authUsecase)
+	router := http.NewRouter(app, jwtManager, revocationStore, pagination.NewSigner(cfg.Paging.CursorSecret))
+	router.SetupWellKnownRoutes()
+	router.SetupAuthRoutes(authUsecase, emailVerificationUsecase, passwordResetUsecase, loginThrottleUsecase)
+	router.SetupTwoFactorRoutes(authUsecase, twoFactorUsecase)
+	router.SetupRoleRoutes(roleUsecase)
 	router.SetupUserRoutes(userUsecase)
 	router.SetupStoreRoutes(storeUsecase)
 	router.SetupCategoryRoutes(categoryUsecase)
 	router.SetupAddressRoutes(addressUsecase)
-	router.SetupProductRoutes(productUsecase)
-	router.SetupTransactionRoutes(transactionUsecase, paymentIntentUsecase)
-	router.SetupPaymentIntentRoutes(paymentIntentUsecase)
+	router.SetupProductRoutes(productUsecase, productVariantUsecase)
+	router.SetupReviewRoutes(reviewUsecase)
+	router.SetupSearchRoutes(searchSuggestUsecase)
+	router.SetupTransactionRoutes(transactionUsecase, paymentIntentUsecase, refundUsecase, shipmentUsecase, orderCompletionUsecase)
+	router.SetupPaymentIntentRoutes(paymentIntentUsecase, paymentCallbackUsecase)
+	router.SetupCartRoutes(cartUsecase)
+	router.SetupShippingRoutes(shippingUsecase)
 
 	// Swagger documentation
 	app.Get("/swagger/*", fiberSwagger.WrapHandler)
@@ -163,3 +302,25 @@ func main() {
 	sqlDB.Close()
 	log.Println("Server stopped")
 }
+
+// newPaymentProviders gives every payment method a provider. Staff settle
+// payments by hand unless the mock gateway is enabled to collect them.
+func newPaymentProviders(cfg *config.Config) (*usecase.PaymentProviders, error) {
+	paymentProviders := usecase.NewPaymentProviders()
+	paymentProviders.Register(service.NewManualPaymentProvider(), domain.PaymentIntentMethods...)
+
+	if cfg.Payment.MockGatewayEnabled {
+		mockGateway := service.NewMockPaymentGateway(cfg.Payment.GatewaySecrets[service.MockPaymentGatewayName], cfg.Payment.CallbackBaseURL)
+		mockGatewayURL, err := mockGateway.Start(cfg.Payment.MockGatewayAddr)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Payment.GatewaySecrets[service.MockPaymentGatewayName] = mockGateway.Secret()
+		paymentProviders.Register(service.NewMockPaymentProvider(mockGatewayURL), domain.PaymentIntentMethods...)
+	}
+
+	if err := paymentProviders.Validate(domain.PaymentIntentMethods...); err != nil {
+		return nil, err
+	}
+	return paymentProviders, nil
+}
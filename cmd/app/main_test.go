@@ -0,0 +1,32 @@
+package main
+
+import (
+	"testing"
+
+	"go-commerce/internal/domain"
+	"go-commerce/internal/service"
+	"go-commerce/pkg/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPaymentProviders_ProductionConfig(t *testing.T) {
+	// Setup - production refuses the mock gateway
+	t.Setenv("APP_ENV", "production")
+	t.Setenv("JWT_SECRET", "production-secret")
+	t.Setenv("PAYMENT_MOCK_GATEWAY_ENABLED", "")
+	cfg := config.Load()
+	require.NoError(t, cfg.Validate())
+
+	// Execute
+	providers, err := newPaymentProviders(cfg)
+
+	// Assert
+	require.NoError(t, err)
+	for _, method := range domain.PaymentIntentMethods {
+		provider, ok := providers.ForMethod(method)
+		require.True(t, ok, method)
+		assert.Equal(t, service.ManualPaymentProviderName, provider.Name())
+	}
+}
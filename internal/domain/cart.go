@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"time"
+)
+
+// Cart is the server-side shopping cart of a user. Each user has at most one.
+type Cart struct {
+	ID        uint64      `json:"id" gorm:"primaryKey;column:id"`
+	UserID    uint64      `json:"user_id" gorm:"column:id_user;type:bigint unsigned;not null;uniqueIndex:idx_carts_user"`
+	CreatedAt time.Time   `json:"created_at" gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP"`
+	UpdatedAt time.Time   `json:"updated_at" gorm:"column:updated_at;type:timestamp;default:CURRENT_TIMESTAMP"`
+	Items     []*CartItem `json:"items" gorm:"foreignKey:CartID;references:ID"`
+
+	// Computed from live product data when the cart is read
+	Total         float64 `json:"total" gorm:"-"`
+	CheckoutReady bool    `json:"checkout_ready" gorm:"-"`
+}
+
+func (Cart) TableName() string {
+	return "carts"
+}
+
+type CartItem struct {
+	ID        uint64    `json:"id" gorm:"primaryKey;column:id"`
+	CartID    uint64    `json:"cart_id" gorm:"column:id_cart;type:bigint unsigned;not null;uniqueIndex:idx_cart_items_cart_variant"`
+	ProductID uint64    `json:"product_id" gorm:"column:id_produk;type:bigint unsigned;not null;uniqueIndex:idx_cart_items_cart_variant"`
+	VariantID uint64    `json:"variant_id" gorm:"column:id_varian;type:bigint unsigned;not null;default:0;uniqueIndex:idx_cart_items_cart_variant"`
+	Quantity  int       `json:"quantity" gorm:"column:kuantitas;type:int;not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"column:updated_at;type:timestamp;default:CURRENT_TIMESTAMP"`
+
+	// Computed from live product data when the cart is read. Issue holds the
+	// reason the item cannot be checked out, e.g. INSUFFICIENT_STOCK.
+	Product   *Product        `json:"product,omitempty" gorm:"-"`
+	Variant   *ProductVariant `json:"variant,omitempty" gorm:"-"`
+	UnitPrice float64         `json:"unit_price" gorm:"-"`
+	Subtotal  float64         `json:"subtotal" gorm:"-"`
+	Available bool            `json:"available" gorm:"-"`
+	Issue     string          `json:"issue,omitempty" gorm:"-"`
+}
+
+func (CartItem) TableName() string {
+	return "cart_items"
+}
+
+type CartRepository interface {
+	// GetOrCreateByUserID returns the user's cart with its items, creating an
+	// empty cart on first use
+	GetOrCreateByUserID(userID uint64) (*Cart, error)
+	// SaveItem sets the quantity of a product, or of one of its variants, in
+	// the cart, adding the line when it does not exist yet
+	SaveItem(cartID, productID, variantID uint64, quantity int) error
+	RemoveItem(cartID, productID, variantID uint64) (bool, error)
+	Clear(cartID uint64) error
+	// ClearWithTx empties the cart inside dbTx and returns how many lines
+	// were removed
+	ClearWithTx(dbTx interface{}, cartID uint64) (int64, error)
+}
+
+// Request DTOs
+type AddCartItemRequest struct {
+	ProductID uint64 `json:"product_id" validate:"required"`
+	VariantID uint64 `json:"variant_id"`
+	Quantity  int    `json:"quantity" validate:"required,min=1"`
+}
+
+type UpdateCartItemRequest struct {
+	Quantity int `json:"quantity" validate:"required,min=1"`
+}
+
+type CheckoutCartRequest struct {
+	AlamatPengiriman uint64                  `json:"alamat_pengiriman" validate:"required"`
+	MetodeBayar      string                  `json:"metode_bayar" validate:"required,oneof=transfer cod ewallet credit_card"`
+	Shipping         []ShippingChoiceRequest `json:"shipping" validate:"omitempty,dive"`
+}
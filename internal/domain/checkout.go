@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"time"
+)
+
+// Checkout is what the buyer pays for. Its items are split into one
+// Transaction (order) per store, and a single payment settles all of them.
+type Checkout struct {
+	ID               uint64     `json:"id" gorm:"primaryKey;column:id"`
+	UserID           uint64     `json:"user_id" gorm:"column:id_user;type:bigint unsigned;not null;index:idx_checkouts_user"`
+	AlamatPengiriman uint64     `json:"alamat_pengiriman" gorm:"column:alamat_pengiriman;type:bigint unsigned;not null"`
+	HargaTotal       float64    `json:"harga_total" gorm:"column:harga_total;type:decimal(14,2);not null"`
+	KodeCheckout     string     `json:"kode_checkout" gorm:"column:kode_checkout;type:varchar(255);uniqueIndex:idx_checkouts_kode;not null"`
+	MetodeBayar      string     `json:"metode_bayar" gorm:"column:metode_bayar;type:enum('transfer','cod','ewallet','credit_card')"`
+	Status           string     `json:"status_pembayaran" gorm:"column:status_pembayaran;type:enum('pending','paid','failed','refunded','cancelled');default:pending"`
+	PaidAt           *time.Time `json:"paid_at" gorm:"column:paid_at;type:timestamp"`
+	CreatedAt        time.Time  `json:"created_at" gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP"`
+	UpdatedAt        time.Time  `json:"updated_at" gorm:"column:updated_at;type:timestamp;default:CURRENT_TIMESTAMP"`
+
+	// Relations
+	User         *User          `json:"user,omitempty" gorm:"foreignKey:UserID;references:ID"`
+	Alamat       *Address       `json:"alamat,omitempty" gorm:"foreignKey:AlamatPengiriman;references:ID"`
+	Transactions []*Transaction `json:"transactions,omitempty" gorm:"foreignKey:CheckoutID;references:ID"`
+}
+
+func (Checkout) TableName() string {
+	return "checkouts"
+}
+
+type CheckoutRepository interface {
+	CreateWithTx(dbTx interface{}, checkout *Checkout) error
+	// GetByID loads the checkout with its orders and their items
+	GetByID(id uint64) (*Checkout, error)
+	UpdateStatus(id uint64, status string) error
+	UpdateStatusWithTx(dbTx interface{}, id uint64, status string) error
+	// TransitionStatusWithTx moves the checkout out of the from status and
+	// reports whether it was still in it, so concurrent settlements of the
+	// same checkout cannot both win
+	TransitionStatusWithTx(dbTx interface{}, id uint64, from, to string) (bool, error)
+	// MarkPaidWithTx moves a pending checkout to paid at the time the
+	// gateway reported, and reports whether it was still pending
+	MarkPaidWithTx(dbTx interface{}, id uint64, paidAt time.Time) (bool, error)
+	// SubtractTotalWithTx lowers the amount due, e.g. when one of the orders
+	// is cancelled before payment
+	SubtractTotalWithTx(dbTx interface{}, id uint64, amount float64) error
+	// HasPendingIntentWithTx locks the pending payment intents of the
+	// checkout and reports whether there are any, so a payment cannot be
+	// settled while the amount due changes
+	HasPendingIntentWithTx(dbTx interface{}, id uint64) (bool, error)
+	// ExpirePendingIntentsWithTx expires the pending payment intents of the
+	// checkout, e.g. when it is cancelled
+	ExpirePendingIntentsWithTx(dbTx interface{}, id uint64) error
+}
@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"time"
+)
+
+const (
+	DisputeStatusOpen              = "open"
+	DisputeStatusRefunded          = "refunded"
+	DisputeStatusPartiallyRefunded = "partially_refunded"
+	DisputeStatusRejected          = "rejected"
+)
+
+// Ways an admin can settle a dispute
+const (
+	DisputeResolutionRefund        = "refund"
+	DisputeResolutionPartialRefund = "partial_refund"
+	DisputeResolutionReject        = "reject"
+)
+
+// MaxDisputePhotos is how many evidence photos a dispute can carry
+const MaxDisputePhotos = 5
+
+// Dispute is a buyer's complaint about a shipped order. While it is open the
+// order is not completed; an admin settles it with a refund, a partial
+// refund or a rejection.
+type Dispute struct {
+	ID             uint64     `json:"id" gorm:"primaryKey;column:id"`
+	TransactionID  uint64     `json:"transaction_id" gorm:"column:id_trx;type:bigint unsigned;not null;uniqueIndex:idx_disputes_trx"`
+	UserID         uint64     `json:"user_id" gorm:"column:id_user;type:bigint unsigned;not null"`
+	Reason         string     `json:"reason" gorm:"column:reason;type:text;not null"`
+	Status         string     `json:"status" gorm:"column:status;type:enum('open','refunded','partially_refunded','rejected');default:open;index:idx_disputes_status"`
+	ResolutionNote string     `json:"resolution_note" gorm:"column:resolution_note;type:varchar(255)"`
+	RefundID       *uint64    `json:"refund_id" gorm:"column:id_refund;type:bigint unsigned"`
+	ResolvedBy     *uint64    `json:"resolved_by" gorm:"column:resolved_by;type:bigint unsigned"`
+	ResolvedAt     *time.Time `json:"resolved_at" gorm:"column:resolved_at;type:timestamp"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP"`
+	UpdatedAt      time.Time  `json:"updated_at" gorm:"column:updated_at;type:timestamp;default:CURRENT_TIMESTAMP"`
+
+	// Relations
+	Photos []*DisputePhoto `json:"photos" gorm:"foreignKey:DisputeID;references:ID"`
+}
+
+func (Dispute) TableName() string {
+	return "disputes"
+}
+
+// DisputePhoto is an evidence photo uploaded with a dispute
+type DisputePhoto struct {
+	ID        uint64    `json:"id" gorm:"primaryKey;column:id"`
+	DisputeID uint64    `json:"dispute_id" gorm:"column:id_dispute;type:bigint unsigned;not null;index:idx_dispute_photos_dispute"`
+	URL       string    `json:"url" gorm:"column:url;type:varchar(255);not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP"`
+}
+
+func (DisputePhoto) TableName() string {
+	return "dispute_photos"
+}
+
+// Request DTOs
+type OpenDisputeRequest struct {
+	Reason string `json:"reason" form:"reason" validate:"required,min=10,max=1000"`
+}
+
+// ResolveDisputeRequest settles a dispute. A partial refund returns the given
+// items and/or amount like a refund of the order does; a full refund returns
+// everything not refunded yet.
+type ResolveDisputeRequest struct {
+	Resolution string              `json:"resolution" validate:"required,oneof=refund partial_refund reject"`
+	Items      []RefundItemRequest `json:"items" validate:"omitempty,dive"`
+	Amount     float64             `json:"amount" validate:"omitempty,gt=0"`
+	Note       string              `json:"note" validate:"max=255"`
+}
+
+// Repository interfaces
+type DisputeRepository interface {
+	// CreateWithTx stores the dispute with its photos
+	CreateWithTx(dbTx interface{}, dispute *Dispute) error
+	GetByID(id uint64) (*Dispute, error)
+	// GetByIDWithLock locks the dispute until the database transaction ends
+	GetByIDWithLock(dbTx interface{}, id uint64) (*Dispute, error)
+	GetByTransactionID(transactionID uint64) (*Dispute, error)
+	// HasOpenWithTx reports whether the order has an open dispute
+	HasOpenWithTx(dbTx interface{}, transactionID uint64) (bool, error)
+	GetByStatus(status string, limit, offset int) ([]*Dispute, int64, error)
+	// ResolveWithTx stores the status, note, refund and resolver of the dispute
+	ResolveWithTx(dbTx interface{}, dispute *Dispute) error
+}
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"time"
+)
+
+// Sequences numbered by InvoiceSequenceRepository
+const (
+	SequenceInvoice  = "invoice"
+	SequenceCheckout = "checkout"
+)
+
+// InvoiceSequence is the last number handed out by one sequence on one day,
+// per store for invoices and store 0 for checkouts
+type InvoiceSequence struct {
+	Name    string    `json:"name" gorm:"primaryKey;column:name;type:varchar(20)"`
+	Day     time.Time `json:"day" gorm:"primaryKey;column:day;type:date"`
+	StoreID uint64    `json:"store_id" gorm:"primaryKey;column:id_toko;type:bigint unsigned"`
+	LastSeq int64     `json:"last_seq" gorm:"column:last_seq;type:bigint unsigned;not null"`
+}
+
+func (InvoiceSequence) TableName() string {
+	return "invoice_sequences"
+}
+
+// Repository interfaces
+type InvoiceSequenceRepository interface {
+	// Next increments the sequence of the day and store and returns the new
+	// number, starting at 1. It commits on its own, so concurrent checkouts
+	// on any instance never get the same number; a checkout that rolls back
+	// leaves a gap.
+	Next(name string, day time.Time, storeID uint64) (int64, error)
+}
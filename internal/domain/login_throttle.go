@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"strconv"
+	"time"
+)
+
+const (
+	LoginThrottleScopeAccount = "account"
+	LoginThrottleScopeIP      = "ip"
+)
+
+// LoginThrottle counts recent failed logins for one account or client IP.
+// Subject is the user ID for the account scope and the IP address otherwise.
+type LoginThrottle struct {
+	Scope        string     `json:"scope" gorm:"primaryKey;column:scope;type:varchar(16)"`
+	Subject      string     `json:"subject" gorm:"primaryKey;column:subject;type:varchar(64)"`
+	FailedCount  int        `json:"failed_count" gorm:"column:failed_count;not null;default:0"`
+	LastFailedAt time.Time  `json:"last_failed_at" gorm:"column:last_failed_at;type:timestamp;not null"`
+	LockedUntil  *time.Time `json:"locked_until" gorm:"column:locked_until;type:timestamp"`
+}
+
+func (LoginThrottle) TableName() string {
+	return "login_throttles"
+}
+
+type LoginThrottleRepository interface {
+	Get(scope, subject string) (*LoginThrottle, error)
+	// RegisterFailure atomically counts a failed attempt. The counter starts
+	// over when the previous failure and lockout are older than window.
+	RegisterFailure(scope, subject string, now time.Time, window time.Duration) (*LoginThrottle, error)
+	Lock(scope, subject string, until time.Time) error
+	Reset(scope, subject string) error
+	DeleteStale(before time.Time) (int64, error)
+}
+
+// ThrottleError is returned while logins are refused for an account or IP.
+// Error returns the code so handlers can keep matching on err.Error().
+type ThrottleError struct {
+	Code       string
+	RetryAfter time.Duration
+}
+
+func (e *ThrottleError) Error() string {
+	return e.Code
+}
+
+// RetryAfterSeconds rounds RetryAfter up to whole seconds for the Retry-After header
+func (e *ThrottleError) RetryAfterSeconds() string {
+	seconds := int64((e.RetryAfter + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	return strconv.FormatInt(seconds, 10)
+}
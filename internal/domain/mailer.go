@@ -0,0 +1,13 @@
+package domain
+
+// EmailMessage is a plain-text email sent to a single recipient
+type EmailMessage struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers transactional emails such as verification links
+type Mailer interface {
+	Send(msg *EmailMessage) error
+}
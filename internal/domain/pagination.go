@@ -0,0 +1,29 @@
+package domain
+
+// Cursor marks a row of a sorted list by its sort key and its ID, which
+// breaks ties. Clients receive it as a signed token and pass it back to read
+// the page after it, or before it when Backward is set.
+type Cursor struct {
+	// Scope names the list the cursor was issued for, e.g. "products"
+	Scope    string `json:"s"`
+	SortBy   string `json:"o"`
+	Key      string `json:"k"`
+	ID       uint64 `json:"i"`
+	Backward bool   `json:"b,omitempty"`
+}
+
+// CursorPage asks for Limit rows past Cursor in SortBy order, or for the
+// first rows when Cursor is nil. Repositories return up to Limit+1 rows,
+// nearest to the cursor first, so the caller can tell whether more follow.
+type CursorPage struct {
+	SortBy string
+	Cursor *Cursor
+	Limit  int
+}
+
+// CursorLinks are the cursors of the pages before and after a page, nil when
+// there is no such page
+type CursorLinks struct {
+	Next *Cursor
+	Prev *Cursor
+}
@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"time"
+)
+
+// PasswordResetToken is a single-use token emailed to a user who forgot their
+// password. Only the SHA-256 hash of the token is stored.
+type PasswordResetToken struct {
+	ID        uint64     `json:"id" gorm:"primaryKey;column:id"`
+	UserID    uint64     `json:"user_id" gorm:"column:id_user;type:bigint unsigned;index:idx_password_reset_tokens_user;not null"`
+	TokenHash string     `json:"-" gorm:"column:token_hash;type:char(64);uniqueIndex:idx_password_reset_tokens_hash;not null"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"column:expires_at;type:timestamp;not null"`
+	UsedAt    *time.Time `json:"used_at" gorm:"column:used_at;type:timestamp"`
+	CreatedAt time.Time  `json:"created_at" gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP"`
+}
+
+func (PasswordResetToken) TableName() string {
+	return "password_reset_tokens"
+}
+
+type PasswordResetRepository interface {
+	Create(token *PasswordResetToken) error
+	GetByTokenHash(tokenHash string) (*PasswordResetToken, error)
+	// MarkUsed consumes an unused token. It returns false when the token was
+	// already used, so a token can only reset the password once.
+	MarkUsed(id uint64, usedAt time.Time) (bool, error)
+	// InvalidateByUserID consumes every outstanding token of the user
+	InvalidateByUserID(userID uint64, usedAt time.Time) error
+	DeleteExpired(before time.Time) (int64, error)
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,min=6"`
+}
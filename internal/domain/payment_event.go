@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"time"
+)
+
+const (
+	PaymentEventReceived  = "received"
+	PaymentEventProcessed = "processed"
+	PaymentEventDuplicate = "duplicate"
+	PaymentEventRejected  = "rejected"
+	PaymentEventFailed    = "failed"
+)
+
+// PaymentEvent is the audit record of one payment gateway callback
+type PaymentEvent struct {
+	ID              uint64     `json:"id" gorm:"primaryKey;column:id"`
+	PaymentIntentID *uint64    `json:"payment_intent_id" gorm:"column:payment_intent_id;type:bigint unsigned;index:idx_payment_events_intent"`
+	Gateway         string     `json:"gateway" gorm:"column:gateway;type:varchar(50);not null"`
+	GatewayRef      *string    `json:"gateway_ref" gorm:"column:gateway_ref;type:varchar(255)"`
+	ClaimedRef      *string    `json:"-" gorm:"column:claimed_ref;type:varchar(255)"`
+	Status          string     `json:"status" gorm:"column:status;type:varchar(20)"`
+	Outcome         string     `json:"outcome" gorm:"column:outcome;type:enum('received','processed','duplicate','rejected','failed');not null"`
+	Message         string     `json:"message" gorm:"column:message;type:varchar(255)"`
+	Payload         string     `json:"payload" gorm:"column:payload;type:text"`
+	PaidAt          *time.Time `json:"paid_at" gorm:"column:paid_at;type:timestamp"`
+	RemoteIP        string     `json:"remote_ip" gorm:"column:remote_ip;type:varchar(45)"`
+	CreatedAt       time.Time  `json:"created_at" gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP"`
+	UpdatedAt       time.Time  `json:"updated_at" gorm:"column:updated_at;type:timestamp;default:CURRENT_TIMESTAMP"`
+}
+
+func (PaymentEvent) TableName() string {
+	return "payment_events"
+}
+
+// PaymentCallback is a gateway callback as received, before its signature is
+// checked
+type PaymentCallback struct {
+	IntentID  uint
+	Gateway   string
+	Timestamp string
+	Signature string
+	Body      []byte
+	RemoteIP  string
+}
+
+// PaymentCallbackRequest is the signed body a gateway posts
+type PaymentCallbackRequest struct {
+	Status     string `json:"status" validate:"required,oneof=success failed"`
+	GatewayRef string `json:"gateway_ref" validate:"required"`
+	PaidAt     string `json:"paid_at"`
+}
+
+type PaymentEventRepository interface {
+	Create(event *PaymentEvent) error
+	// Claim stores an event that claims its gateway reference. It reports
+	// false, without storing anything, when the reference was claimed before.
+	Claim(event *PaymentEvent) (bool, error)
+	UpdateOutcome(id uint64, outcome, message string) error
+	// ReleaseClaim marks the event failed and frees its gateway reference so
+	// the gateway can retry the callback
+	ReleaseClaim(id uint64, message string) error
+}
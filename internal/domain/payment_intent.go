@@ -10,32 +10,67 @@ const (
 	PaymentIntentStatusPending PaymentIntentStatus = "pending"
 	PaymentIntentStatusSuccess PaymentIntentStatus = "success"
 	PaymentIntentStatusFailed  PaymentIntentStatus = "failed"
+	PaymentIntentStatusExpired PaymentIntentStatus = "expired"
+	// A paid intent whose checkout could not be fulfilled is refunded, or
+	// needs a refund by hand when its provider could not refund it
+	PaymentIntentStatusRefunded    PaymentIntentStatus = "refunded"
+	PaymentIntentStatusNeedsRefund PaymentIntentStatus = "needs_refund"
 )
 
+// PaymentIntentMethods are the methods an intent can be paid with; each needs
+// a payment provider
+var PaymentIntentMethods = []string{"transfer", "ewallet", "credit_card"}
+
 type PaymentIntent struct {
-	ID        uint                `json:"id" gorm:"primaryKey"`
-	TrxID     uint                `json:"trx_id" gorm:"not null;index"`
-	Method    string              `json:"method" gorm:"size:50;not null"`
-	Status    PaymentIntentStatus `json:"status" gorm:"size:20;not null;default:'pending'"`
-	ExpiredAt time.Time           `json:"expired_at" gorm:"not null"`
-	CreatedAt time.Time           `json:"created_at"`
-	UpdatedAt time.Time           `json:"updated_at"`
-	
+	ID             uint                `json:"id" gorm:"primaryKey"`
+	CheckoutID     uint                `json:"checkout_id" gorm:"not null;index"`
+	Method         string              `json:"method" gorm:"size:50;not null"`
+	Status         PaymentIntentStatus `json:"status" gorm:"size:20;not null;default:'pending'"`
+	Amount         float64             `json:"amount" gorm:"type:decimal(14,2);not null;default:0"`
+	Provider       string              `json:"provider,omitempty" gorm:"size:50"`
+	ProviderRef    string              `json:"provider_ref,omitempty" gorm:"size:255"`
+	VirtualAccount string              `json:"virtual_account,omitempty" gorm:"size:50"`
+	QRPayload      string              `json:"qr_payload,omitempty" gorm:"type:text"`
+	PaymentURL     string              `json:"payment_url,omitempty" gorm:"size:500"`
+	ExpiredAt      time.Time           `json:"expired_at" gorm:"not null"`
+	CreatedAt      time.Time           `json:"created_at"`
+	UpdatedAt      time.Time           `json:"updated_at"`
+
 	// Relations
-	Transaction *Transaction `json:"transaction,omitempty" gorm:"foreignKey:TrxID"`
+	Checkout *Checkout `json:"checkout,omitempty" gorm:"foreignKey:CheckoutID"`
 }
 
 type PaymentIntentRepository interface {
 	Create(intent *PaymentIntent) error
 	GetByID(id uint) (*PaymentIntent, error)
-	GetByTrxID(trxID uint) (*PaymentIntent, error)
+	// GetByCheckoutID returns the latest intent of the checkout
+	GetByCheckoutID(checkoutID uint) (*PaymentIntent, error)
 	UpdateStatus(id uint, status PaymentIntentStatus) error
-	ExpireByTrxID(trxID uint) error
+	// AttachCharge stores the provider charge that collects the intent
+	AttachCharge(id uint, charge *Charge) error
+	// TransitionStatus moves an intent out of the from status and reports
+	// whether it was still in it, so each intent is settled exactly once
+	TransitionStatus(id uint, from, to PaymentIntentStatus) (bool, error)
+	TransitionStatusWithTx(dbTx interface{}, id uint, from, to PaymentIntentStatus) (bool, error)
+	// GetExpiredPendingWithLock locks pending intents past their expiry,
+	// skipping rows another worker has already locked
+	GetExpiredPendingWithLock(dbTx interface{}, now time.Time, limit int) ([]*PaymentIntent, error)
+	ExpireByCheckoutID(checkoutID uint) error
 }
 
 type PaymentIntentUsecase interface {
-	CreatePaymentIntent(trxID uint, method string) (*PaymentIntent, error)
-	ProcessPaymentSuccess(intentID uint) error
+	CreatePaymentIntent(checkoutID uint, method string) (*PaymentIntent, error)
+	// CreatePaymentIntentForOrder pays the whole checkout of the order
+	CreatePaymentIntentForOrder(transactionID uint64, method string) (*PaymentIntent, error)
+	ProcessPaymentSuccess(intentID uint, paidAt time.Time) error
 	ProcessPaymentFailed(intentID uint) error
-	ExpireIntentsByTrxID(trxID uint) error
+	// GetPaymentIntent returns the buyer's intent, first asking its provider
+	// for news while it is still pending
+	GetPaymentIntent(userID uint64, intentID uint) (*PaymentIntent, error)
+	// RefundPayment returns amount of a paid checkout through its provider
+	// and gives the provider's refund reference. Retries must reuse the
+	// idempotency key so the money is returned once.
+	RefundPayment(checkoutID uint, amount float64, idempotencyKey string) (string, error)
+	ExpireIntentsByCheckoutID(checkoutID uint) error
+	ExpireStaleIntents() (int, error)
 }
\ No newline at end of file
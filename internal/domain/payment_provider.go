@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"time"
+)
+
+const (
+	ChargeStatusPending = "pending"
+	ChargeStatusPaid    = "paid"
+	ChargeStatusFailed  = "failed"
+	ChargeStatusExpired = "expired"
+)
+
+// ChargeRequest asks a provider to collect the amount due for an intent
+type ChargeRequest struct {
+	IntentID  uint
+	Method    string
+	Amount    float64
+	ExpiresAt time.Time
+}
+
+// Charge is what the buyer needs to pay: a virtual account number for bank
+// transfers, a QR payload for e-wallets or a payment page for cards
+type Charge struct {
+	Provider       string
+	Reference      string
+	VirtualAccount string
+	QRPayload      string
+	PaymentURL     string
+}
+
+// ChargeStatus is the provider's view of a charge
+type ChargeStatus struct {
+	Status string
+	PaidAt *time.Time
+}
+
+// PaymentWebhook is a provider callback decoded into a payment outcome
+type PaymentWebhook struct {
+	Status     string // success or failed
+	GatewayRef string
+	PaidAt     *time.Time
+}
+
+// PaymentProvider is a payment gateway integration. The provider's Name is
+// also the gateway name its callbacks are signed under.
+type PaymentProvider interface {
+	Name() string
+	CreateCharge(req *ChargeRequest) (*Charge, error)
+	GetStatus(reference string) (*ChargeStatus, error)
+	// Refund returns part or all of a paid charge and gives the provider's
+	// reference of the refund. A refund sent again with the same idempotency
+	// key gives the first refund's reference instead of paying out twice.
+	Refund(reference string, amount float64, idempotencyKey string) (string, error)
+	ParseWebhook(body []byte) (*PaymentWebhook, error)
+}
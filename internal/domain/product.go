@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"strconv"
 	"time"
 
 	"gorm.io/gorm"
@@ -13,12 +14,16 @@ type Product struct {
 	HargaReseller   float64        `json:"harga_reseller" gorm:"column:harga_reseller;type:decimal(12,2);not null" validate:"required,min=0"`
 	HargaKonsumen   float64        `json:"harga_konsumen" gorm:"column:harga_konsumen;type:decimal(12,2);not null" validate:"required,min=0"`
 	Stok            int            `json:"stok" gorm:"column:stok;type:int;default:0"`
+	StokDitahan     int            `json:"stok_ditahan" gorm:"column:stok_ditahan;type:int;default:0"`
 	Deskripsi       string         `json:"deskripsi" gorm:"column:deskripsi;type:text"`
 	IDToko          uint64         `json:"id_toko" gorm:"column:id_toko;type:bigint unsigned;not null;index:idx_produk_toko"`
 	IDCategory      uint64         `json:"id_category" gorm:"column:id_category;type:bigint unsigned;not null;index:idx_produk_category"`
 	Status          string         `json:"status" gorm:"column:status;type:enum('active','inactive');default:active;index:idx_produk_status" validate:"oneof=active inactive"`
 	Berat           int            `json:"berat" gorm:"column:berat;type:int;default:0"`
 	SoldCount       int            `json:"sold_count" gorm:"column:sold_count;type:int;default:0"`
+	Rating          float64        `json:"rating" gorm:"column:rating;type:decimal(2,1);default:0.0;index:idx_produk_rating"`
+	RatingCount     int            `json:"rating_count" gorm:"column:rating_count;type:int;default:0"`
+	RatingSum       int            `json:"-" gorm:"column:rating_sum;type:int;default:0"`
 	CreatedAt       time.Time      `json:"created_at" gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP"`
 	UpdatedAt       time.Time      `json:"updated_at" gorm:"column:updated_at;type:timestamp;default:CURRENT_TIMESTAMP"`
 	DeletedAt       gorm.DeletedAt `json:"-" gorm:"column:deleted_at;type:timestamp;index:idx_produk_deleted_at"`
@@ -27,12 +32,69 @@ type Product struct {
 	Toko     Store        `json:"toko,omitempty" gorm:"foreignKey:IDToko"`
 	Category Category     `json:"category,omitempty" gorm:"foreignKey:IDCategory"`
 	Photos   []PhotoProduk `json:"photos,omitempty" gorm:"foreignKey:IDProduk"`
+	Options  []ProductOption  `json:"options,omitempty" gorm:"foreignKey:IDProduk"`
+	Variants []ProductVariant `json:"variants,omitempty" gorm:"foreignKey:IDProduk"`
 }
 
 func (Product) TableName() string {
 	return "produk"
 }
 
+// AvailableStock is the stock that is not held by unpaid checkouts
+func (p *Product) AvailableStock() int {
+	return p.Stok - p.StokDitahan
+}
+
+// HasVariants reports whether the product is sold per variant. Variants must
+// be loaded.
+func (p *Product) HasVariants() bool {
+	return len(p.Variants) > 0
+}
+
+// TotalStock is the stock of the product, or the summed stock of its variants
+// when it has any. Variants must be loaded.
+func (p *Product) TotalStock() int {
+	if !p.HasVariants() {
+		return p.Stok
+	}
+	total := 0
+	for _, variant := range p.Variants {
+		total += variant.Stok
+	}
+	return total
+}
+
+// Variant returns the loaded variant with the given ID, or nil
+func (p *Product) Variant(id uint64) *ProductVariant {
+	for i := range p.Variants {
+		if p.Variants[i].ID == id {
+			return &p.Variants[i]
+		}
+	}
+	return nil
+}
+
+// Cursor returns the cursor of p in the sortBy order of product lists, or
+// false when products cannot be paged by cursor in that order
+func (p *Product) Cursor(sortBy string) (*Cursor, bool) {
+	var key string
+	switch sortBy {
+	case "newest", "oldest":
+		key = p.CreatedAt.Format(time.RFC3339Nano)
+	case "price_asc", "price_desc":
+		key = strconv.FormatFloat(p.HargaKonsumen, 'f', -1, 64)
+	case "popular":
+		key = strconv.Itoa(p.SoldCount)
+	case "rating":
+		key = strconv.FormatFloat(p.Rating, 'f', -1, 64)
+	case "name_asc", "name_desc":
+		key = p.NamaProduk
+	default:
+		return nil, false
+	}
+	return &Cursor{SortBy: sortBy, Key: key, ID: p.ID}, true
+}
+
 type PhotoProduk struct {
 	ID        uint64         `json:"id" gorm:"primaryKey;column:id"`
 	IDProduk  uint64         `json:"id_produk" gorm:"column:id_produk;type:bigint unsigned;not null;index:idx_foto_produk_produk"`
@@ -60,11 +122,28 @@ type ProductRepository interface {
 	GetByTokoID(tokoID uint64, limit, offset int, search string) ([]*Product, int64, error)
 	GetAll(limit, offset int, search, categoryID string) ([]*Product, int64, error)
 	GetAllWithFilter(filter *ProductFilter) ([]*Product, int64, error)
+	// GetAllWithCursor pages the active products matching filter by cursor;
+	// the sort, page and limit fields of filter are ignored
+	GetAllWithCursor(filter *ProductFilter, page *CursorPage) ([]*Product, error)
+	GetByTokoIDWithCursor(tokoID uint64, search string, page *CursorPage) ([]*Product, error)
+	// GetByIDs returns the active products among ids in the order of ids
+	GetByIDs(ids []uint64) ([]*Product, error)
 	GetByStatus(status string, limit, offset int) ([]*Product, int64, error)
 	Update(product *Product) error
-	GetStockWithLock(dbTx interface{}, productID uint64) (int, error)
-	UpdateStockWithTx(dbTx interface{}, productID uint64, quantity int) error
+	// GetStockWithLock locks the stock row until dbTx ends and returns the
+	// available stock, i.e. stock not held by reservations. The stock methods
+	// work on the variant of the product when variantID is not 0, and on the
+	// product itself otherwise.
+	GetStockWithLock(dbTx interface{}, productID, variantID uint64) (int, error)
+	UpdateStockWithTx(dbTx interface{}, productID, variantID uint64, quantity int) error
+	// UpdateHeldStockWithTx adds quantity to the held stock counter; a
+	// negative quantity releases it
+	UpdateHeldStockWithTx(dbTx interface{}, productID, variantID uint64, quantity int) error
 	UpdateSoldCountWithTx(dbTx interface{}, productID uint64, quantity int) error
+	// AddRatingWithTx adds rating to the rating sum and count to the rating
+	// count of the product and recomputes its average; negative values take
+	// a review out
+	AddRatingWithTx(dbTx interface{}, productID uint64, rating, count int) error
 	Delete(id uint64) error
 	CheckOwnership(productID, tokoID uint64) error
 }
@@ -102,9 +181,11 @@ type UpdateProductRequest struct {
 type ProductFilter struct {
 	Search     string `json:"search"`
 	CategoryID string `json:"category_id"`
+	StoreID    string `json:"store_id"`
 	MinPrice   string `json:"min_price"`
 	MaxPrice   string `json:"max_price"`
-	SortBy     string `json:"sort_by"` // price_asc, price_desc, newest, oldest, popular
+	MinRating  string `json:"min_rating"` // products rated at least this much
+	SortBy     string `json:"sort_by"`    // relevance, price_asc, price_desc, newest, oldest, popular, rating
 	Page       int    `json:"page"`
 	Limit      int    `json:"limit"`
 }
\ No newline at end of file
@@ -0,0 +1,81 @@
+package domain
+
+import "strconv"
+
+// ProductSearchQuery is a parsed product search. Zero values mean no filter;
+// SortBy defaults to relevance when Query has terms and to newest otherwise.
+type ProductSearchQuery struct {
+	Query      string
+	CategoryID uint64
+	StoreID    uint64
+	MinPrice   *float64
+	MaxPrice   *float64
+	MinRating  *float64
+	SortBy     string // relevance, price_asc, price_desc, newest, oldest, popular, rating, name_asc, name_desc
+	Limit      int
+	Offset     int
+}
+
+// PriceBucket is a consumer price range used for facet counts. Max is
+// exclusive; 0 means no upper bound.
+type PriceBucket struct {
+	Min float64
+	Max float64
+}
+
+// Key identifies the bucket in facet counts, e.g. "50000-100000" or
+// "1000000-"
+func (b PriceBucket) Key() string {
+	key := strconv.FormatFloat(b.Min, 'f', -1, 64) + "-"
+	if b.Max > 0 {
+		key += strconv.FormatFloat(b.Max, 'f', -1, 64)
+	}
+	return key
+}
+
+// Contains reports whether price falls in the bucket
+func (b PriceBucket) Contains(price float64) bool {
+	return price >= b.Min && (b.Max == 0 || price < b.Max)
+}
+
+// PriceBuckets are the price ranges of the price facet, in ascending order
+var PriceBuckets = []PriceBucket{
+	{Min: 0, Max: 50000},
+	{Min: 50000, Max: 100000},
+	{Min: 100000, Max: 250000},
+	{Min: 250000, Max: 500000},
+	{Min: 500000, Max: 1000000},
+	{Min: 1000000},
+}
+
+// FacetCount is the number of matching products with one facet value
+type FacetCount struct {
+	Value string `json:"value"`
+	Label string `json:"label,omitempty"`
+	Count int64  `json:"count"`
+}
+
+// ProductSearchFacets count the matching products per category, price
+// bucket and store
+type ProductSearchFacets struct {
+	Categories  []FacetCount `json:"categories"`
+	PriceRanges []FacetCount `json:"price_ranges"`
+	Stores      []FacetCount `json:"stores"`
+}
+
+// ProductSearchResult holds one page of matching product IDs in result order
+type ProductSearchResult struct {
+	ProductIDs []uint64
+	Total      int64
+	Facets     *ProductSearchFacets
+}
+
+// ProductSearchIndex finds active products by text. Implementations rank by
+// relevance, tolerate small typos and ignore Indonesian stop-words.
+type ProductSearchIndex interface {
+	// Index adds or refreshes product. Products that are not active are
+	// removed from the index.
+	Index(product *Product) error
+	Remove(productID uint64) error
+	Search(query *ProductSearchQuery) (*ProductSearchResult, error)
+}
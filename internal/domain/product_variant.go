@@ -0,0 +1,165 @@
+package domain
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ProductOption is one dimension a product varies in, e.g. size with the
+// values S, M and L. Every variant of the product picks one value of each
+// option.
+type ProductOption struct {
+	ID        uint64    `json:"id" gorm:"primaryKey;column:id"`
+	IDProduk  uint64    `json:"id_produk" gorm:"column:id_produk;type:bigint unsigned;not null;index:idx_opsi_produk_produk"`
+	Name      string    `json:"name" gorm:"column:name;type:varchar(50);not null"`
+	Values    []string  `json:"values" gorm:"column:option_values;type:json;serializer:json;not null"`
+	Position  int       `json:"position" gorm:"column:position;type:int;default:0"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"column:updated_at;type:timestamp;default:CURRENT_TIMESTAMP"`
+}
+
+func (ProductOption) TableName() string {
+	return "opsi_produk"
+}
+
+// HasValue reports whether value is one of the option's values
+func (o *ProductOption) HasValue(value string) bool {
+	for _, v := range o.Values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// ProductVariant is a sellable SKU of a product. Price and weight fall back to
+// the product when not set; stock is always kept per variant, so the stock of
+// the product itself is not used once it has variants.
+type ProductVariant struct {
+	ID            uint64            `json:"id" gorm:"primaryKey;column:id"`
+	IDProduk      uint64            `json:"id_produk" gorm:"column:id_produk;type:bigint unsigned;not null;index:idx_varian_produk_produk"`
+	SKU           string            `json:"sku" gorm:"column:sku;type:varchar(100);uniqueIndex:idx_varian_produk_sku;not null"`
+	Attributes    map[string]string `json:"attributes" gorm:"column:attributes;type:json;serializer:json;not null"`
+	HargaReseller *float64          `json:"harga_reseller" gorm:"column:harga_reseller;type:decimal(12,2)"`
+	HargaKonsumen *float64          `json:"harga_konsumen" gorm:"column:harga_konsumen;type:decimal(12,2)"`
+	Stok          int               `json:"stok" gorm:"column:stok;type:int;default:0"`
+	StokDitahan   int               `json:"stok_ditahan" gorm:"column:stok_ditahan;type:int;default:0"`
+	Berat         *int              `json:"berat" gorm:"column:berat;type:int"`
+	IDFoto        *uint64           `json:"id_foto" gorm:"column:id_foto;type:bigint unsigned"`
+	Status        string            `json:"status" gorm:"column:status;type:enum('active','inactive');default:active"`
+	CreatedAt     time.Time         `json:"created_at" gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP"`
+	UpdatedAt     time.Time         `json:"updated_at" gorm:"column:updated_at;type:timestamp;default:CURRENT_TIMESTAMP"`
+	DeletedAt     gorm.DeletedAt    `json:"-" gorm:"column:deleted_at;type:timestamp;index:idx_varian_produk_deleted_at"`
+
+	// Relations
+	Photo *PhotoProduk `json:"photo,omitempty" gorm:"foreignKey:IDFoto"`
+}
+
+func (ProductVariant) TableName() string {
+	return "varian_produk"
+}
+
+// AvailableStock is the stock that is not held by unpaid checkouts
+func (v *ProductVariant) AvailableStock() int {
+	return v.Stok - v.StokDitahan
+}
+
+// PriceFor is the consumer price of the variant, or of product without an
+// override
+func (v *ProductVariant) PriceFor(product *Product) float64 {
+	if v.HargaKonsumen != nil {
+		return *v.HargaKonsumen
+	}
+	return product.HargaKonsumen
+}
+
+// ResellerPriceFor is the reseller price of the variant, or of product
+// without an override
+func (v *ProductVariant) ResellerPriceFor(product *Product) float64 {
+	if v.HargaReseller != nil {
+		return *v.HargaReseller
+	}
+	return product.HargaReseller
+}
+
+// WeightFor is the weight of the variant in grams, or of product without an
+// override
+func (v *ProductVariant) WeightFor(product *Product) int {
+	if v.Berat != nil {
+		return *v.Berat
+	}
+	return product.Berat
+}
+
+// Label lists the attribute values in option order, e.g. "M, Merah"
+func (v *ProductVariant) Label(options []ProductOption) string {
+	values := make([]string, 0, len(v.Attributes))
+	seen := make(map[string]bool, len(options))
+	for _, option := range options {
+		if value, ok := v.Attributes[option.Name]; ok {
+			values = append(values, value)
+			seen[option.Name] = true
+		}
+	}
+
+	// Attributes of options that no longer exist, in a stable order
+	var rest []string
+	for name := range v.Attributes {
+		if !seen[name] {
+			rest = append(rest, name)
+		}
+	}
+	sort.Strings(rest)
+	for _, name := range rest {
+		values = append(values, v.Attributes[name])
+	}
+
+	return strings.Join(values, ", ")
+}
+
+type ProductVariantRepository interface {
+	// ReplaceOptions swaps the options of a product for options in one
+	// database transaction
+	ReplaceOptions(productID uint64, options []*ProductOption) error
+	GetOptionsByProductID(productID uint64) ([]*ProductOption, error)
+	Create(variant *ProductVariant) error
+	GetByID(id uint64) (*ProductVariant, error)
+	GetByProductID(productID uint64) ([]*ProductVariant, error)
+	GetBySKU(sku string) (*ProductVariant, error)
+	Update(variant *ProductVariant) error
+	Delete(id uint64) error
+}
+
+// Request DTOs
+type ProductOptionRequest struct {
+	Name   string   `json:"name" validate:"required,max=50"`
+	Values []string `json:"values" validate:"required,min=1,dive,required,max=50"`
+}
+
+type SetProductOptionsRequest struct {
+	Options []ProductOptionRequest `json:"options" validate:"max=3,dive"`
+}
+
+type CreateProductVariantRequest struct {
+	SKU           string            `json:"sku" validate:"required,max=100"`
+	Attributes    map[string]string `json:"attributes" validate:"required"`
+	HargaReseller *float64          `json:"harga_reseller,omitempty" validate:"omitempty,min=0"`
+	HargaKonsumen *float64          `json:"harga_konsumen,omitempty" validate:"omitempty,min=0"`
+	Stok          int               `json:"stok" validate:"min=0"`
+	Berat         *int              `json:"berat,omitempty" validate:"omitempty,min=0"`
+	IDFoto        *uint64           `json:"id_foto,omitempty"`
+	Status        string            `json:"status" validate:"omitempty,oneof=active inactive"`
+}
+
+type UpdateProductVariantRequest struct {
+	SKU           *string  `json:"sku,omitempty" validate:"omitempty,max=100"`
+	HargaReseller *float64 `json:"harga_reseller,omitempty" validate:"omitempty,min=0"`
+	HargaKonsumen *float64 `json:"harga_konsumen,omitempty" validate:"omitempty,min=0"`
+	Stok          *int     `json:"stok,omitempty" validate:"omitempty,min=0"`
+	Berat         *int     `json:"berat,omitempty" validate:"omitempty,min=0"`
+	IDFoto        *uint64  `json:"id_foto,omitempty"`
+	Status        *string  `json:"status,omitempty" validate:"omitempty,oneof=active inactive"`
+}
@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"time"
+)
+
+// RefreshToken records every refresh token handed out to a client. Tokens
+// produced by rotating the same login share a FamilyID so the whole chain can
+// be revoked when an already-rotated token is replayed.
+type RefreshToken struct {
+	ID         uint64     `json:"id" gorm:"primaryKey;column:id"`
+	TokenID    string     `json:"token_id" gorm:"column:jti;type:varchar(64);uniqueIndex:idx_refresh_tokens_jti;not null"`
+	FamilyID   string     `json:"family_id" gorm:"column:family_id;type:varchar(64);index:idx_refresh_tokens_family;not null"`
+	UserID     uint64     `json:"user_id" gorm:"column:id_user;type:bigint unsigned;index:idx_refresh_tokens_user;not null"`
+	ExpiresAt  time.Time  `json:"expires_at" gorm:"column:expires_at;type:timestamp;not null"`
+	RotatedAt  *time.Time `json:"rotated_at" gorm:"column:rotated_at;type:timestamp"`
+	ReplacedBy string     `json:"replaced_by" gorm:"column:replaced_by;type:varchar(64)"`
+	RevokedAt  *time.Time `json:"revoked_at" gorm:"column:revoked_at;type:timestamp"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP"`
+}
+
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
+
+type RefreshTokenRepository interface {
+	Create(token *RefreshToken) error
+	GetByTokenID(tokenID string) (*RefreshToken, error)
+	// MarkRotated flags an active token as used. It returns false when the
+	// token was already rotated or revoked, which signals a replay.
+	MarkRotated(tokenID, replacedBy string) (bool, error)
+	RevokeFamily(familyID string) error
+	RevokeByUserID(userID uint64) error
+	DeleteExpired(before time.Time) (int64, error)
+}
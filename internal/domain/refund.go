@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"time"
+)
+
+type RefundStatus string
+
+// A refund is recorded as pending and completed once the payment provider
+// returned the money. Failed refunds can be sent again.
+const (
+	RefundStatusPending   RefundStatus = "pending"
+	RefundStatusCompleted RefundStatus = "completed"
+	RefundStatusFailed    RefundStatus = "failed"
+)
+
+// Refund is one entry of the refund ledger. A paid order can be refunded in
+// several parts, per item or by amount, until its refundable balance is used.
+type Refund struct {
+	ID            uint64       `json:"id" gorm:"primaryKey;column:id"`
+	TransactionID uint64       `json:"transaction_id" gorm:"column:id_trx;type:bigint unsigned;not null;index:idx_refunds_trx"`
+	CheckoutID    uint64       `json:"checkout_id" gorm:"column:id_checkout;type:bigint unsigned;not null"`
+	Amount        float64      `json:"amount" gorm:"column:amount;type:decimal(14,2);not null"`
+	Reason        string       `json:"reason" gorm:"column:reason;type:varchar(255)"`
+	RefundedBy    uint64       `json:"refunded_by" gorm:"column:refunded_by;type:bigint unsigned;not null"`
+	ProviderRef   string       `json:"provider_ref" gorm:"column:provider_ref;type:varchar(255)"`
+	Status        RefundStatus `json:"status" gorm:"column:status;type:varchar(20);not null;default:'completed'"`
+	CreatedAt     time.Time    `json:"created_at" gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP"`
+
+	// Relations
+	Items []*RefundItem `json:"items" gorm:"foreignKey:RefundID;references:ID"`
+}
+
+func (Refund) TableName() string {
+	return "refunds"
+}
+
+// RefundItem is the quantity of one order item returned by a refund
+type RefundItem struct {
+	ID                uint64  `json:"id" gorm:"primaryKey;column:id"`
+	RefundID          uint64  `json:"refund_id" gorm:"column:id_refund;type:bigint unsigned;not null;index:idx_refund_items_refund"`
+	TransactionItemID uint64  `json:"transaction_item_id" gorm:"column:id_detail_trx;type:bigint unsigned;not null"`
+	ProductID         uint64  `json:"product_id" gorm:"column:id_produk;type:bigint unsigned;not null"`
+	VariantID         uint64  `json:"variant_id" gorm:"column:id_varian;type:bigint unsigned;not null;default:0"`
+	Quantity          int     `json:"quantity" gorm:"column:kuantitas;type:int;not null"`
+	Amount            float64 `json:"amount" gorm:"column:amount;type:decimal(14,2);not null"`
+}
+
+func (RefundItem) TableName() string {
+	return "refund_items"
+}
+
+// Request DTOs
+
+// RefundRequest refunds part of a paid order. Without items and amount the
+// whole remaining balance and all remaining items are refunded. Items restore
+// their stock; the amount defaults to the value of the refunded items.
+type RefundRequest struct {
+	Items  []RefundItemRequest `json:"items" validate:"omitempty,dive"`
+	Amount float64             `json:"amount" validate:"omitempty,gt=0"`
+	Reason string              `json:"reason" validate:"max=255"`
+}
+
+type RefundItemRequest struct {
+	TransactionItemID uint64 `json:"transaction_item_id" validate:"required"`
+	Quantity          int    `json:"quantity" validate:"required,min=1"`
+}
+
+// Response DTOs
+
+// TransactionRefunds is the refund ledger of one order
+type TransactionRefunds struct {
+	TransactionID    uint64    `json:"transaction_id"`
+	HargaTotal       float64   `json:"harga_total"`
+	RefundedAmount   float64   `json:"refunded_amount"`
+	RefundableAmount float64   `json:"refundable_amount"`
+	Refunds          []*Refund `json:"refunds"`
+}
+
+// Repository interfaces
+type RefundRepository interface {
+	// CreateWithTx stores the refund together with its items
+	CreateWithTx(dbTx interface{}, refund *Refund) error
+	GetByID(id uint64) (*Refund, error)
+	GetByTransactionID(transactionID uint64) ([]*Refund, error)
+	// UpdateStatus records the outcome of sending the refund to the payment
+	// provider
+	UpdateStatus(id uint64, status RefundStatus, providerRef string) error
+}
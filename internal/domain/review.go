@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"time"
+)
+
+const (
+	ReviewStatusVisible = "visible"
+	ReviewStatusHidden  = "hidden"
+)
+
+// MaxReviewPhotos is how many photos a review can carry
+const MaxReviewPhotos = 5
+
+// Review is a buyer's rating of one purchased order item. Visible reviews
+// count towards the rating of the product and its store; an admin can hide
+// abusive ones, which takes them out of both.
+type Review struct {
+	ID                uint64     `json:"id" gorm:"primaryKey;column:id"`
+	TransactionItemID uint64     `json:"transaction_item_id" gorm:"column:id_detail_trx;type:bigint unsigned;not null;uniqueIndex:idx_product_reviews_detail_trx"`
+	TransactionID     uint64     `json:"transaction_id" gorm:"column:id_trx;type:bigint unsigned;not null"`
+	ProductID         uint64     `json:"product_id" gorm:"column:id_produk;type:bigint unsigned;not null;index:idx_product_reviews_produk"`
+	StoreID           uint64     `json:"store_id" gorm:"column:id_toko;type:bigint unsigned;not null"`
+	UserID            uint64     `json:"user_id" gorm:"column:id_user;type:bigint unsigned;not null"`
+	Rating            int        `json:"rating" gorm:"column:rating;type:tinyint unsigned;not null"`
+	Comment           string     `json:"comment" gorm:"column:comment;type:text"`
+	Status            string     `json:"status" gorm:"column:status;type:enum('visible','hidden');default:visible"`
+	Reply             string     `json:"reply" gorm:"column:reply;type:text"`
+	RepliedAt         *time.Time `json:"replied_at" gorm:"column:replied_at;type:timestamp"`
+	HiddenReason      string     `json:"hidden_reason,omitempty" gorm:"column:hidden_reason;type:varchar(255)"`
+	HiddenBy          *uint64    `json:"hidden_by,omitempty" gorm:"column:hidden_by;type:bigint unsigned"`
+	HiddenAt          *time.Time `json:"hidden_at,omitempty" gorm:"column:hidden_at;type:timestamp"`
+	CreatedAt         time.Time  `json:"created_at" gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP"`
+	UpdatedAt         time.Time  `json:"updated_at" gorm:"column:updated_at;type:timestamp;default:CURRENT_TIMESTAMP"`
+
+	// Relations
+	Photos []*ReviewPhoto `json:"photos" gorm:"foreignKey:ReviewID;references:ID"`
+}
+
+func (Review) TableName() string {
+	return "product_reviews"
+}
+
+// ReviewPhoto is a photo uploaded with a review
+type ReviewPhoto struct {
+	ID        uint64    `json:"id" gorm:"primaryKey;column:id"`
+	ReviewID  uint64    `json:"review_id" gorm:"column:id_review;type:bigint unsigned;not null;index:idx_review_photos_review"`
+	URL       string    `json:"url" gorm:"column:url;type:varchar(255);not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP"`
+}
+
+func (ReviewPhoto) TableName() string {
+	return "review_photos"
+}
+
+// Request DTOs
+type CreateReviewRequest struct {
+	TransactionItemID uint64 `json:"transaction_item_id" form:"transaction_item_id" validate:"required"`
+	Rating            int    `json:"rating" form:"rating" validate:"required,min=1,max=5"`
+	Comment           string `json:"comment" form:"comment" validate:"max=2000"`
+}
+
+type ReplyReviewRequest struct {
+	Reply string `json:"reply" validate:"required,max=1000"`
+}
+
+type HideReviewRequest struct {
+	Reason string `json:"reason" validate:"max=255"`
+}
+
+// Repository interfaces
+type ReviewRepository interface {
+	// CreateWithTx stores the review with its photos
+	CreateWithTx(dbTx interface{}, review *Review) error
+	GetByID(id uint64) (*Review, error)
+	// GetByIDWithLock locks the review until the database transaction ends
+	GetByIDWithLock(dbTx interface{}, id uint64) (*Review, error)
+	GetByTransactionItemID(transactionItemID uint64) (*Review, error)
+	// GetVisibleByProductID lists the visible reviews of a product, newest
+	// first
+	GetVisibleByProductID(productID uint64, limit, offset int) ([]*Review, int64, error)
+	// UpdateWithTx stores the status, reply and moderation fields of review
+	UpdateWithTx(dbTx interface{}, review *Review) error
+}
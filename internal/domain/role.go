@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"time"
+)
+
+const (
+	RoleSuperAdmin   = "super_admin"
+	RoleCatalogAdmin = "catalog_admin"
+	RoleFinanceAdmin = "finance_admin"
+	RoleSupport      = "support"
+	RoleSeller       = "seller"
+)
+
+const (
+	PermissionStoresSuspend      = "stores:suspend"
+	PermissionProductsModerate   = "products:moderate"
+	PermissionCategoriesManage   = "categories:manage"
+	PermissionTransactionsRefund = "transactions:refund"
+	PermissionTransactionsView   = "transactions:view"
+	PermissionPaymentsSimulate   = "payments:simulate"
+	PermissionUsersUnlock        = "users:unlock"
+	PermissionRolesManage        = "roles:manage"
+	PermissionSecurityManage     = "security:manage"
+	PermissionDisputesResolve    = "disputes:resolve"
+	PermissionReviewsModerate    = "reviews:moderate"
+)
+
+type Role struct {
+	ID          uint64        `json:"id" gorm:"primaryKey"`
+	Name        string        `json:"name" gorm:"uniqueIndex:idx_roles_name;not null"`
+	Description string        `json:"description"`
+	Permissions []*Permission `json:"permissions,omitempty" gorm:"many2many:role_permissions;joinForeignKey:id_role;joinReferences:id_permission"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+func (Role) TableName() string {
+	return "roles"
+}
+
+type Permission struct {
+	ID          uint64    `json:"id" gorm:"primaryKey"`
+	Name        string    `json:"name" gorm:"uniqueIndex:idx_permissions_name;not null"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (Permission) TableName() string {
+	return "permissions"
+}
+
+type UserRole struct {
+	UserID    uint64    `json:"user_id" gorm:"primaryKey;column:id_user"`
+	RoleID    uint64    `json:"role_id" gorm:"primaryKey;column:id_role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (UserRole) TableName() string {
+	return "user_roles"
+}
+
+type RoleRepository interface {
+	GetAll() ([]*Role, error)
+	GetByName(name string) (*Role, error)
+	// GetUserRoles returns the user's roles with their permissions loaded
+	GetUserRoles(userID uint64) ([]*Role, error)
+	AssignToUser(userID, roleID uint64) error
+	AssignToUserWithTx(dbTx interface{}, userID, roleID uint64) error
+	RevokeFromUser(userID, roleID uint64) (bool, error)
+}
+
+type AssignRoleRequest struct {
+	Role string `json:"role" validate:"required"`
+}
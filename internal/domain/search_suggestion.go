@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"time"
+)
+
+// Kinds of suggestion entries
+const (
+	SuggestionQuery    = "query"
+	SuggestionProduct  = "product"
+	SuggestionCategory = "category"
+	SuggestionStore    = "store"
+)
+
+// SearchQuery counts how often a normalized search text was searched, e.g.
+// "kemeja flanel"
+type SearchQuery struct {
+	Query          string    `json:"query" gorm:"primaryKey;column:query;type:varchar(100)"`
+	SearchCount    int64     `json:"search_count" gorm:"column:search_count;type:bigint unsigned;not null;default:0"`
+	LastSearchedAt time.Time `json:"last_searched_at" gorm:"column:last_searched_at;type:timestamp;default:CURRENT_TIMESTAMP"`
+}
+
+func (SearchQuery) TableName() string {
+	return "search_queries"
+}
+
+// SuggestionEntry is one completion in the suggestion index. Weight breaks
+// ties between entries searched equally often, e.g. the sold count of a
+// product or the search count of a query.
+type SuggestionEntry struct {
+	Kind   string
+	ID     uint64
+	Text   string
+	Slug   string
+	Weight int64
+}
+
+// Suggestion is a completion of what the user is typing
+type Suggestion struct {
+	ID   uint64 `json:"id,omitempty"`
+	Text string `json:"text"`
+	Slug string `json:"slug,omitempty"`
+}
+
+// SearchSuggestions are the completions of a prefix per kind, best first
+type SearchSuggestions struct {
+	Queries    []Suggestion `json:"queries"`
+	Products   []Suggestion `json:"products"`
+	Categories []Suggestion `json:"categories"`
+	Stores     []Suggestion `json:"stores"`
+}
+
+// SearchSuggestIndex completes prefixes of any word of an entry's text.
+// Entries are ranked by how often the popular queries completing the same
+// prefix were searched for them.
+type SearchSuggestIndex interface {
+	// Load replaces all entries at once
+	Load(entries []*SuggestionEntry)
+	// Put adds or replaces the entry with the same kind and ID; queries are
+	// keyed by their text
+	Put(entry *SuggestionEntry)
+	Remove(kind string, id uint64)
+	// RecordQuery adds count searches of query
+	RecordQuery(query string, count int64)
+	Suggest(prefix string, limit int) *SearchSuggestions
+}
+
+// Repository interfaces
+type SearchQueryRepository interface {
+	// Increment counts one more search of query
+	Increment(query string, searchedAt time.Time) error
+	// GetPopular returns the most searched queries first
+	GetPopular(limit int) ([]*SearchQuery, error)
+}
@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"time"
+)
+
+// Setting names
+const (
+	SettingRequireTwoFactorForAdmins = "auth.require_two_factor_for_admins"
+)
+
+// Setting is a runtime-adjustable option that admins can change without a
+// redeploy
+type Setting struct {
+	Name      string    `json:"name" gorm:"primaryKey"`
+	Value     string    `json:"value" gorm:"not null"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (Setting) TableName() string {
+	return "settings"
+}
+
+type SettingRepository interface {
+	Get(name string) (*Setting, error)
+	Set(name, value string) error
+}
@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"time"
+)
+
+const (
+	ShipmentStatusInTransit = "in_transit"
+	ShipmentStatusDelivered = "delivered"
+)
+
+// Shipment is how an order was sent: the courier, its service level and the
+// tracking number (resi) the seller got from the courier
+type Shipment struct {
+	ID                    uint64     `json:"id" gorm:"primaryKey;column:id"`
+	TransactionID         uint64     `json:"transaction_id" gorm:"column:id_trx;type:bigint unsigned;not null;uniqueIndex:idx_shipments_trx"`
+	Courier               string     `json:"courier" gorm:"column:courier;type:varchar(20);not null"`
+	Service               string     `json:"service" gorm:"column:service;type:varchar(50);not null"`
+	TrackingNumber        string     `json:"tracking_number" gorm:"column:tracking_number;type:varchar(100);not null"`
+	EstimatedDeliveryDate *time.Time `json:"estimated_delivery_date" gorm:"column:estimated_delivery_date;type:date"`
+	Status                string     `json:"status" gorm:"column:status;type:enum('in_transit','delivered');default:in_transit;index:idx_shipments_polling"`
+	DeliveredAt           *time.Time `json:"delivered_at" gorm:"column:delivered_at;type:timestamp"`
+	LastPolledAt          *time.Time `json:"last_polled_at" gorm:"column:last_polled_at;type:timestamp;index:idx_shipments_polling"`
+	CreatedAt             time.Time  `json:"created_at" gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP"`
+	UpdatedAt             time.Time  `json:"updated_at" gorm:"column:updated_at;type:timestamp;default:CURRENT_TIMESTAMP"`
+
+	// Relations
+	Events []*ShipmentEvent `json:"events" gorm:"foreignKey:ShipmentID;references:ID"`
+}
+
+func (Shipment) TableName() string {
+	return "shipments"
+}
+
+// ShipmentEvent is one tracking update reported by the courier
+type ShipmentEvent struct {
+	ID          uint64    `json:"id" gorm:"primaryKey;column:id"`
+	ShipmentID  uint64    `json:"shipment_id" gorm:"column:id_shipment;type:bigint unsigned;not null;uniqueIndex:idx_shipment_events_unique"`
+	Status      string    `json:"status" gorm:"column:status;type:varchar(50);not null;uniqueIndex:idx_shipment_events_unique"`
+	Description string    `json:"description" gorm:"column:description;type:varchar(255)"`
+	Location    string    `json:"location" gorm:"column:location;type:varchar(255)"`
+	OccurredAt  time.Time `json:"occurred_at" gorm:"column:occurred_at;type:timestamp;not null;uniqueIndex:idx_shipment_events_unique"`
+	CreatedAt   time.Time `json:"created_at" gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP"`
+}
+
+func (ShipmentEvent) TableName() string {
+	return "shipment_events"
+}
+
+// TrackingResult is what a courier reports for a tracking number. Events are
+// the full history; DeliveredAt is set once the parcel was delivered.
+type TrackingResult struct {
+	Events      []TrackingEvent
+	DeliveredAt *time.Time
+}
+
+type TrackingEvent struct {
+	Status      string    `json:"status"`
+	Description string    `json:"description"`
+	Location    string    `json:"location"`
+	OccurredAt  time.Time `json:"occurred_at"`
+}
+
+// CourierTracker looks up tracking numbers at the couriers
+type CourierTracker interface {
+	Track(courier, trackingNumber string) (*TrackingResult, error)
+}
+
+// Request DTOs
+type ShipOrderRequest struct {
+	Courier               string `json:"courier" validate:"required,oneof=jne jnt sicepat pos anteraja tiki"`
+	Service               string `json:"service" validate:"required,max=50"`
+	TrackingNumber        string `json:"tracking_number" validate:"required,max=100"`
+	EstimatedDeliveryDate string `json:"estimated_delivery_date" validate:"omitempty,datetime=2006-01-02"`
+}
+
+// Repository interfaces
+type ShipmentRepository interface {
+	CreateWithTx(dbTx interface{}, shipment *Shipment) error
+	// GetByTransactionID returns the shipment with its events, oldest first
+	GetByTransactionID(transactionID uint64) (*Shipment, error)
+	// GetDueForTracking returns in-transit shipments of shipped orders that
+	// were not polled since polledBefore
+	GetDueForTracking(polledBefore time.Time, limit int) ([]*Shipment, error)
+	// AddEvents stores the events that are not stored yet and returns how many
+	// were new
+	AddEvents(shipmentID uint64, events []*ShipmentEvent) (int, error)
+	MarkPolled(id uint64, at time.Time) error
+	MarkDeliveredWithTx(dbTx interface{}, id uint64, deliveredAt time.Time) error
+}
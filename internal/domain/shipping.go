@@ -0,0 +1,72 @@
+package domain
+
+// ShippingRateRule is one row of the shipping rate table. Empty region
+// columns match any region, so a rule can price a city pair, a province pair
+// or act as the nationwide fallback of a courier service.
+type ShippingRateRule struct {
+	ID                    uint64  `json:"id" gorm:"primaryKey;column:id"`
+	Courier               string  `json:"courier" gorm:"column:courier;type:varchar(20);not null"`
+	Service               string  `json:"service" gorm:"column:service;type:varchar(50);not null"`
+	OriginProvinceID      string  `json:"origin_province_id" gorm:"column:origin_province_id;type:varchar(10)"`
+	OriginCityID          string  `json:"origin_city_id" gorm:"column:origin_city_id;type:varchar(10)"`
+	DestinationProvinceID string  `json:"destination_province_id" gorm:"column:destination_province_id;type:varchar(10)"`
+	DestinationCityID     string  `json:"destination_city_id" gorm:"column:destination_city_id;type:varchar(10)"`
+	PricePerKg            float64 `json:"price_per_kg" gorm:"column:price_per_kg;type:decimal(12,2);not null"`
+	EstimatedDays         string  `json:"estimated_days" gorm:"column:estimated_days;type:varchar(10)"`
+}
+
+func (ShippingRateRule) TableName() string {
+	return "shipping_rates"
+}
+
+// ShippingParcel is what one store sends to the buyer: the regions it travels
+// between and its total weight in grams
+type ShippingParcel struct {
+	OriginProvinceID      string
+	OriginCityID          string
+	DestinationProvinceID string
+	DestinationCityID     string
+	WeightGrams           int
+}
+
+// ShippingRate is the price of sending a parcel with one courier service
+type ShippingRate struct {
+	Courier       string  `json:"courier"`
+	Service       string  `json:"service"`
+	Fee           float64 `json:"fee"`
+	EstimatedDays string  `json:"estimated_days"`
+}
+
+// ShippingRateProvider prices parcels for the couriers it knows
+type ShippingRateProvider interface {
+	Name() string
+	Rates(parcel ShippingParcel) ([]ShippingRate, error)
+}
+
+// ShippingQuote lists the shipping options of the part of a cart that one
+// store sends
+type ShippingQuote struct {
+	StoreID     uint64         `json:"store_id"`
+	WeightGrams int            `json:"weight_grams"`
+	Rates       []ShippingRate `json:"rates"`
+}
+
+// Request DTOs
+type ShippingQuoteRequest struct {
+	AlamatPengiriman uint64                         `json:"alamat_pengiriman" validate:"required"`
+	Items            []CreateTransactionItemRequest `json:"items" validate:"required,min=1,dive"`
+}
+
+// ShippingChoiceRequest picks the courier service one store ships with.
+// Stores without a choice ship with their cheapest option.
+type ShippingChoiceRequest struct {
+	StoreID uint64 `json:"store_id" validate:"required"`
+	Courier string `json:"courier" validate:"required"`
+	Service string `json:"service" validate:"required"`
+}
+
+// Repository interfaces
+type ShippingRateRepository interface {
+	// FindMatching returns every rule whose regions match the parcel
+	FindMatching(parcel ShippingParcel) ([]*ShippingRateRule, error)
+}
@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"time"
+)
+
+const (
+	StockReservationHeld      = "held"
+	StockReservationReleased  = "released"
+	StockReservationConverted = "converted"
+)
+
+// StockReservation holds stock of one product or variant for an unpaid order.
+// While it is held, the quantity is counted in the StokDitahan of the product,
+// or of the variant when VariantID is set.
+type StockReservation struct {
+	ID            uint64    `json:"id" gorm:"primaryKey;column:id"`
+	CheckoutID    uint64    `json:"checkout_id" gorm:"column:id_checkout;type:bigint unsigned;not null;index:idx_stock_reservations_checkout"`
+	TransactionID uint64    `json:"transaction_id" gorm:"column:id_trx;type:bigint unsigned;not null;index:idx_stock_reservations_trx"`
+	ProductID     uint64    `json:"product_id" gorm:"column:id_produk;type:bigint unsigned;not null"`
+	VariantID     uint64    `json:"variant_id" gorm:"column:id_varian;type:bigint unsigned;not null;default:0"`
+	Quantity      int       `json:"quantity" gorm:"column:kuantitas;type:int;not null"`
+	Status        string    `json:"status" gorm:"column:status;type:enum('held','released','converted');default:held;index:idx_stock_reservations_expiry"`
+	ExpiresAt     time.Time `json:"expires_at" gorm:"column:expires_at;type:timestamp;not null;index:idx_stock_reservations_expiry"`
+	CreatedAt     time.Time `json:"created_at" gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP"`
+	UpdatedAt     time.Time `json:"updated_at" gorm:"column:updated_at;type:timestamp;default:CURRENT_TIMESTAMP"`
+}
+
+func (StockReservation) TableName() string {
+	return "stock_reservations"
+}
+
+type StockReservationRepository interface {
+	CreateWithTx(dbTx interface{}, reservation *StockReservation) error
+	GetHeldByCheckoutIDWithTx(dbTx interface{}, checkoutID uint64) ([]*StockReservation, error)
+	GetHeldByTransactionIDWithTx(dbTx interface{}, transactionID uint64) ([]*StockReservation, error)
+	// GetExpiredCheckoutIDs returns checkouts that still hold stock past the
+	// expiry of their reservations
+	GetExpiredCheckoutIDs(now time.Time, limit int) ([]uint64, error)
+	// TransitionWithTx moves a reservation out of the from status and reports
+	// whether it was still in it, so each hold is settled exactly once
+	TransitionWithTx(dbTx interface{}, id uint64, from, to string) (bool, error)
+	// ExtendByCheckoutID pushes the expiry of held reservations out to until
+	ExtendByCheckoutID(checkoutID uint64, until time.Time) error
+}
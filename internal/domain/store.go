@@ -14,6 +14,10 @@ type Store struct {
 	Description string         `json:"description" gorm:"column:deskripsi;type:text"`
 	Status      string         `json:"status" gorm:"column:status;type:enum('pending','active','inactive','suspended');default:pending;index:idx_toko_status" validate:"omitempty,oneof=pending active inactive suspended"`
 	Rating      float64        `json:"rating" gorm:"column:rating;type:decimal(2,1);default:0.0;index:idx_toko_rating"`
+	RatingCount int            `json:"rating_count" gorm:"column:rating_count;type:int;default:0"`
+	RatingSum   int            `json:"-" gorm:"column:rating_sum;type:int;default:0"`
+	ProvinceID  string         `json:"province_id" gorm:"column:province_id;type:varchar(10)"`
+	CityID      string         `json:"city_id" gorm:"column:city_id;type:varchar(10)"`
 	CreatedAt   time.Time      `json:"created_at" gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP"`
 	UpdatedAt   time.Time      `json:"updated_at" gorm:"column:updated_at;type:timestamp;default:CURRENT_TIMESTAMP"`
 	DeletedAt   gorm.DeletedAt `json:"-" gorm:"column:deleted_at;type:timestamp;index:idx_toko_deleted_at"`
@@ -26,6 +30,21 @@ func (Store) TableName() string {
 	return "toko"
 }
 
+// Cursor returns the cursor of s in the sortBy order of store lists, or false
+// when stores cannot be paged by cursor in that order
+func (s *Store) Cursor(sortBy string) (*Cursor, bool) {
+	var key string
+	switch sortBy {
+	case "newest", "oldest":
+		key = s.CreatedAt.Format(time.RFC3339Nano)
+	case "name_asc", "name_desc":
+		key = s.Name
+	default:
+		return nil, false
+	}
+	return &Cursor{SortBy: sortBy, Key: key, ID: s.ID}, true
+}
+
 type StoreRepository interface {
 	Create(store *Store) error
 	GetByID(id uint64) (*Store, error)
@@ -34,8 +53,13 @@ type StoreRepository interface {
 	Delete(id uint64) error
 	GetAll(limit, offset int, search string) ([]*Store, int64, error)
 	GetActiveStores(limit, offset int, search string) ([]*Store, int64, error)
+	GetActiveStoresWithCursor(search string, page *CursorPage) ([]*Store, error)
 	GetPendingStores(limit, offset int, search string) ([]*Store, int64, error)
 	GetActiveStoreByUserID(userID uint64) (*Store, error)
+	// AddRatingWithTx adds rating to the rating sum and count to the rating
+	// count of the store and recomputes its average; negative values take a
+	// review out
+	AddRatingWithTx(dbTx interface{}, storeID uint64, rating, count int) error
 }
 
 type CreateStoreRequest struct {
@@ -48,4 +72,7 @@ type UpdateStoreRequest struct {
 	Name        *string `json:"name,omitempty" validate:"omitempty,min=2,max=255"`
 	Description *string `json:"description,omitempty"`
 	PhotoURL    *string `json:"url_foto,omitempty"`
+	// ProvinceID and CityID are where the store ships from
+	ProvinceID *string `json:"province_id,omitempty" validate:"omitempty,max=10"`
+	CityID     *string `json:"city_id,omitempty" validate:"omitempty,max=10"`
 }
\ No newline at end of file
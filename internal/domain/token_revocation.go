@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"time"
+)
+
+// RevokedToken is a denylist entry for a single access token (by jti). It
+// only needs to live until the token itself would have expired.
+type RevokedToken struct {
+	TokenID   string    `json:"token_id" gorm:"primaryKey;column:jti;type:varchar(64)"`
+	UserID    uint64    `json:"user_id" gorm:"column:id_user;type:bigint unsigned;not null;index:idx_revoked_tokens_user"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"column:expires_at;type:timestamp;not null;index:idx_revoked_tokens_expires_at"`
+	RevokedAt time.Time `json:"revoked_at" gorm:"column:revoked_at;type:timestamp;default:CURRENT_TIMESTAMP"`
+}
+
+func (RevokedToken) TableName() string {
+	return "revoked_tokens"
+}
+
+// UserTokenRevocation invalidates every token of a user issued before
+// RevokedBefore ("log out all devices").
+type UserTokenRevocation struct {
+	UserID        uint64    `json:"user_id" gorm:"primaryKey;column:id_user;type:bigint unsigned"`
+	RevokedBefore time.Time `json:"revoked_before" gorm:"column:revoked_before;type:timestamp;not null"`
+	ExpiresAt     time.Time `json:"expires_at" gorm:"column:expires_at;type:timestamp;not null;index:idx_user_token_revocations_expires_at"`
+	UpdatedAt     time.Time `json:"updated_at" gorm:"column:updated_at;type:timestamp;default:CURRENT_TIMESTAMP"`
+}
+
+func (UserTokenRevocation) TableName() string {
+	return "user_token_revocations"
+}
+
+// TokenRevocationStore keeps track of access tokens that must be rejected
+// before their natural expiry
+type TokenRevocationStore interface {
+	Revoke(tokenID string, userID uint64, expiresAt time.Time) error
+	RevokeAllForUser(userID uint64, revokedBefore, expiresAt time.Time) error
+	IsRevoked(tokenID string, userID uint64, issuedAt time.Time) (bool, error)
+	PurgeExpired(now time.Time) (int64, error)
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
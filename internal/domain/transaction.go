@@ -7,20 +7,30 @@ import (
 type Transaction struct {
 	ID                uint64     `json:"id" gorm:"primaryKey;column:id"`
 	UserID            uint64     `json:"user_id" gorm:"column:id_user;type:bigint unsigned;not null;index:idx_trx_user"`
+	CheckoutID        uint64     `json:"checkout_id" gorm:"column:id_checkout;type:bigint unsigned;index:idx_trx_checkout"`
+	StoreID           uint64     `json:"store_id" gorm:"column:id_toko;type:bigint unsigned;index:idx_trx_toko"`
 	AlamatPengiriman  uint64     `json:"alamat_pengiriman" gorm:"column:alamat_pengiriman;type:bigint unsigned;not null"`
 	HargaTotal        float64    `json:"harga_total" gorm:"column:harga_total;type:decimal(14,2);not null"`
+	ShippingCourier   string     `json:"shipping_courier" gorm:"column:shipping_courier;type:varchar(20)"`
+	ShippingService   string     `json:"shipping_service" gorm:"column:shipping_service;type:varchar(50)"`
+	ShippingWeight    int        `json:"shipping_weight" gorm:"column:shipping_weight;type:int;not null;default:0"`
+	ShippingFee       float64    `json:"shipping_fee" gorm:"column:shipping_fee;type:decimal(12,2);not null;default:0"`
+	RefundedAmount    float64    `json:"refunded_amount" gorm:"column:refunded_amount;type:decimal(14,2);not null;default:0"`
 	KodeInvoice       string     `json:"kode_invoice" gorm:"column:kode_invoice;type:varchar(255);unique;not null;index:idx_trx_invoice"`
 	MetodeBayar       string     `json:"metode_bayar" gorm:"column:metode_bayar;type:enum('transfer','cod','ewallet','credit_card')" validate:"omitempty,oneof=transfer cod ewallet credit_card"`
 	Status            string     `json:"status_pembayaran" gorm:"column:status_pembayaran;type:enum('pending','paid','failed','refunded','cancelled','shipped','done');default:pending;index:idx_trx_status_pembayaran" validate:"omitempty,oneof=pending paid failed refunded cancelled shipped done"`
 	OrderStatus       string     `json:"order_status" gorm:"column:order_status;type:enum('created','processed','shipped','delivered','cancelled');default:created;index:idx_trx_order_status" validate:"omitempty,oneof=created processed shipped delivered cancelled"`
 	PaidAt            *time.Time `json:"paid_at" gorm:"column:paid_at;type:timestamp"`
 	ShippedAt         *time.Time `json:"shipped_at" gorm:"column:shipped_at;type:timestamp"`
+	DeliveredAt       *time.Time `json:"delivered_at" gorm:"column:delivered_at;type:timestamp"`
+	CompletedAt       *time.Time `json:"completed_at" gorm:"column:completed_at;type:timestamp"`
 	CreatedAt         time.Time  `json:"created_at" gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP;index:idx_trx_created"`
 	UpdatedAt         time.Time  `json:"updated_at" gorm:"column:updated_at;type:timestamp;default:CURRENT_TIMESTAMP"`
 
 	// Relations
 	User            *User               `json:"user,omitempty" gorm:"foreignKey:UserID;references:ID"`
 	Alamat          *Address            `json:"alamat,omitempty" gorm:"foreignKey:AlamatPengiriman;references:ID"`
+	Store           *Store              `json:"store,omitempty" gorm:"foreignKey:StoreID;references:ID"`
 	TransactionItems []*TransactionItem `json:"transaction_items,omitempty" gorm:"foreignKey:TransactionID;references:ID"`
 }
 
@@ -28,12 +38,28 @@ func (Transaction) TableName() string {
 	return "trx"
 }
 
+// Cursor returns the cursor of t in the sortBy order of transaction lists,
+// or false when transactions cannot be paged by cursor in that order
+func (t *Transaction) Cursor(sortBy string) (*Cursor, bool) {
+	switch sortBy {
+	case "newest", "oldest":
+		return &Cursor{SortBy: sortBy, Key: t.CreatedAt.Format(time.RFC3339Nano), ID: t.ID}, true
+	}
+	return nil, false
+}
+
+// RefundableAmount is the part of the order total not refunded yet
+func (t *Transaction) RefundableAmount() float64 {
+	return t.HargaTotal - t.RefundedAmount
+}
+
 type TransactionItem struct {
 	ID                   uint64    `json:"id" gorm:"primaryKey;column:id"`
 	TransactionID        uint64    `json:"transaction_id" gorm:"column:id_trx;type:bigint unsigned;not null;index:idx_detail_trx_trx"`
 	ProductLogID         uint64    `json:"product_log_id" gorm:"column:id_log_produk;type:bigint unsigned;not null;index:idx_detail_trx_log_produk"`
 	StoreID              uint64    `json:"store_id" gorm:"column:id_toko;type:bigint unsigned;not null;index:idx_detail_trx_toko"`
 	Quantity             int       `json:"quantity" gorm:"column:kuantitas;type:int;not null"`
+	RefundedQuantity     int       `json:"refunded_quantity" gorm:"column:refunded_quantity;type:int;not null;default:0"`
 	HargaSatuan          float64   `json:"harga_satuan" gorm:"column:harga_satuan;type:decimal(12,2);not null"`
 	HargaTotal           float64   `json:"harga_total" gorm:"column:harga_total;type:decimal(14,2);not null"`
 	NamaProdukSnapshot   string    `json:"nama_produk_snapshot" gorm:"column:nama_produk_snapshot;type:varchar(255);not null"`
@@ -60,6 +86,10 @@ type ProductLog struct {
 	Deskripsi      string    `json:"deskripsi" gorm:"column:deskripsi;type:text"`
 	StoreID        uint64    `json:"store_id" gorm:"column:id_toko;type:bigint;not null;index:idx_log_produk_toko"`
 	CategoryID     uint64    `json:"category_id" gorm:"column:id_category;type:bigint;not null"`
+	// The variant bought, if the product has variants
+	VariantID         uint64            `json:"variant_id" gorm:"column:id_varian;type:bigint unsigned;not null;default:0"`
+	VariantSKU        string            `json:"variant_sku,omitempty" gorm:"column:variant_sku;type:varchar(100)"`
+	VariantAttributes map[string]string `json:"variant_attributes,omitempty" gorm:"column:variant_attributes;type:json;serializer:json"`
 	CreatedAt      time.Time `json:"created_at" gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP;index:idx_log_produk_created"`
 	UpdatedAt      time.Time `json:"updated_at" gorm:"column:updated_at;type:timestamp;default:CURRENT_TIMESTAMP"`
 }
@@ -73,10 +103,13 @@ type CreateTransactionRequest struct {
 	AlamatPengiriman uint64                        `json:"alamat_pengiriman" validate:"required"`
 	MetodeBayar      string                        `json:"metode_bayar" validate:"required,oneof=transfer cod ewallet credit_card"`
 	Items            []CreateTransactionItemRequest `json:"items" validate:"required,min=1"`
+	Shipping         []ShippingChoiceRequest        `json:"shipping" validate:"omitempty,dive"`
 }
 
 type CreateTransactionItemRequest struct {
 	ProductID uint64 `json:"product_id" validate:"required"`
+	// VariantID is required for products with variants
+	VariantID uint64 `json:"variant_id"`
 	Quantity  int    `json:"quantity" validate:"required,min=1"`
 }
 
@@ -84,22 +117,40 @@ type CreateTransactionItemRequest struct {
 type TransactionRepository interface {
 	Create(tx *Transaction) error
 	GetByID(id uint64) (*Transaction, error)
+	GetByInvoice(kodeInvoice string) (*Transaction, error)
 	GetByUserID(userID uint64, limit, offset int) ([]*Transaction, int64, error)
+	GetByUserIDWithCursor(userID uint64, page *CursorPage) ([]*Transaction, error)
+	GetByStoreID(storeID uint64, limit, offset int) ([]*Transaction, int64, error)
 	GetByStatus(status string, limit, offset int) ([]*Transaction, int64, error)
 	Update(tx *Transaction) error
-	UpdateStatus(id uint64, status string) error
-	UpdateStatusWithTx(dbTx interface{}, id uint64, status string) error
-	UpdateOrderStatus(id uint64, orderStatus string) error
+	// TransitionStatusWithTx moves the payment status out of from and reports
+	// whether it was still in it. Paying and completing stamp paid_at and
+	// completed_at with at.
+	TransitionStatusWithTx(dbTx interface{}, id uint64, from, to string, at time.Time) (bool, error)
+	// TransitionOrderStatusWithTx moves the order status out of from and
+	// reports whether it was still in it. Shipping and delivery stamp
+	// shipped_at and delivered_at with at.
+	TransitionOrderStatusWithTx(dbTx interface{}, id uint64, from, to string, at time.Time) (bool, error)
 	BeginTx() (interface{}, error)
 	CommitTx(tx interface{}) error
 	RollbackTx(tx interface{}) error
 	CreateWithTx(dbTx interface{}, tx *Transaction) error
+	// GetByIDWithLock loads an order with its items and locks it until the
+	// database transaction ends
+	GetByIDWithLock(dbTx interface{}, id uint64) (*Transaction, error)
+	AddRefundedAmountWithTx(dbTx interface{}, id uint64, amount float64) error
+	// GetDueForCompletion returns paid orders that were shipped before
+	// shippedBefore and have no open dispute
+	GetDueForCompletion(shippedBefore time.Time, limit int) ([]*Transaction, error)
 }
 
 type TransactionItemRepository interface {
 	Create(item *TransactionItem) error
 	CreateWithTx(dbTx interface{}, item *TransactionItem) error
 	GetByTransactionID(transactionID uint64) ([]*TransactionItem, error)
+	// GetByID returns the item with its order and product snapshot
+	GetByID(id uint64) (*TransactionItem, error)
+	AddRefundedQuantityWithTx(dbTx interface{}, id uint64, quantity int) error
 }
 
 type ProductLogRepository interface {
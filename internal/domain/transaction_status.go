@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"time"
+)
+
+// Payment statuses of an order (trx.status_pembayaran)
+const (
+	PaymentStatusPending   = "pending"
+	PaymentStatusPaid      = "paid"
+	PaymentStatusFailed    = "failed"
+	PaymentStatusRefunded  = "refunded"
+	PaymentStatusCancelled = "cancelled"
+	// PaymentStatusDone marks a completed order whose money can be paid out
+	// to the seller; it can no longer be refunded
+	PaymentStatusDone = "done"
+)
+
+// Fulfilment statuses of an order (trx.order_status)
+const (
+	OrderStatusCreated   = "created"
+	OrderStatusProcessed = "processed"
+	OrderStatusShipped   = "shipped"
+	OrderStatusDelivered = "delivered"
+	OrderStatusCancelled = "cancelled"
+)
+
+// Status fields recorded in the status history
+const (
+	StatusFieldPayment = "payment"
+	StatusFieldOrder   = "order"
+)
+
+// Who changed a status
+const (
+	StatusActorBuyer  = "buyer"
+	StatusActorSeller = "seller"
+	StatusActorAdmin  = "admin"
+	StatusActorSystem = "system"
+)
+
+var paymentTransitions = map[string][]string{
+	PaymentStatusPending: {PaymentStatusPaid, PaymentStatusFailed, PaymentStatusCancelled},
+	PaymentStatusPaid:    {PaymentStatusRefunded, PaymentStatusDone},
+}
+
+var orderTransitions = map[string][]string{
+	OrderStatusCreated:   {OrderStatusProcessed, OrderStatusCancelled},
+	OrderStatusProcessed: {OrderStatusShipped, OrderStatusCancelled},
+	OrderStatusShipped:   {OrderStatusDelivered},
+}
+
+// CanTransition reports whether the state machine of the status field allows
+// moving from one status to another
+func CanTransition(field, from, to string) bool {
+	transitions := paymentTransitions
+	if field == StatusFieldOrder {
+		transitions = orderTransitions
+	}
+
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusActor is who made a status change. UserID is 0 for the system.
+type StatusActor struct {
+	Role   string
+	UserID uint64
+}
+
+// SystemActor changes statuses from payment callbacks and background jobs
+var SystemActor = StatusActor{Role: StatusActorSystem}
+
+// TransactionStatusHistory is one status change of an order
+type TransactionStatusHistory struct {
+	ID            uint64    `json:"id" gorm:"primaryKey;column:id"`
+	TransactionID uint64    `json:"transaction_id" gorm:"column:id_trx;type:bigint unsigned;not null;index:idx_trx_status_history_trx"`
+	Field         string    `json:"field" gorm:"column:field;type:enum('payment','order');not null"`
+	FromStatus    string    `json:"from_status" gorm:"column:from_status;type:varchar(20);not null"`
+	ToStatus      string    `json:"to_status" gorm:"column:to_status;type:varchar(20);not null"`
+	ActorRole     string    `json:"actor_role" gorm:"column:actor_role;type:enum('buyer','seller','admin','system');not null"`
+	ActorID       *uint64   `json:"actor_id" gorm:"column:actor_id;type:bigint unsigned"`
+	Reason        string    `json:"reason" gorm:"column:reason;type:varchar(255)"`
+	CreatedAt     time.Time `json:"created_at" gorm:"column:created_at;type:timestamp(3);default:CURRENT_TIMESTAMP(3)"`
+}
+
+func (TransactionStatusHistory) TableName() string {
+	return "trx_status_history"
+}
+
+// Response DTOs
+
+// TransactionTimeline is the current status of an order with every status
+// change that led to it
+type TransactionTimeline struct {
+	TransactionID uint64                      `json:"transaction_id"`
+	KodeInvoice   string                      `json:"kode_invoice"`
+	Status        string                      `json:"status_pembayaran"`
+	OrderStatus   string                      `json:"order_status"`
+	CreatedAt     time.Time                   `json:"created_at"`
+	PaidAt        *time.Time                  `json:"paid_at"`
+	ShippedAt     *time.Time                  `json:"shipped_at"`
+	DeliveredAt   *time.Time                  `json:"delivered_at"`
+	CompletedAt   *time.Time                  `json:"completed_at"`
+	Events        []*TransactionStatusHistory `json:"events"`
+}
+
+// Repository interfaces
+type TransactionStatusHistoryRepository interface {
+	CreateWithTx(dbTx interface{}, entry *TransactionStatusHistory) error
+	GetByTransactionID(transactionID uint64) ([]*TransactionStatusHistory, error)
+}
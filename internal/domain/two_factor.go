@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"time"
+)
+
+// TwoFactorRecoveryCode is a single-use code that replaces a TOTP code when
+// the user has lost their authenticator. Only the SHA-256 hash is stored.
+type TwoFactorRecoveryCode struct {
+	ID        uint64     `json:"id" gorm:"primaryKey"`
+	UserID    uint64     `json:"user_id" gorm:"column:id_user;not null"`
+	CodeHash  string     `json:"-" gorm:"column:code_hash;not null"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (TwoFactorRecoveryCode) TableName() string {
+	return "two_factor_recovery_codes"
+}
+
+type TwoFactorRecoveryCodeRepository interface {
+	// ReplaceForUser discards the user's existing codes and stores new ones
+	ReplaceForUser(userID uint64, codeHashes []string) error
+	// Consume marks a code as used. It returns false when the code does not
+	// exist or was already used.
+	Consume(userID uint64, codeHash string, usedAt time.Time) (bool, error)
+	DeleteByUserID(userID uint64) error
+}
+
+type TwoFactorEnrollResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type TwoFactorRecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// TwoFactorLoginRequest completes a login that returned a challenge token.
+// Code is either the current TOTP code or an unused recovery code.
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required"`
+	ClientIP       string `json:"-"`
+}
+
+type TwoFactorPolicy struct {
+	RequireForAdmins bool `json:"require_for_admins"`
+}
+
+type UpdateTwoFactorPolicyRequest struct {
+	RequireForAdmins *bool `json:"require_for_admins" validate:"required"`
+}
@@ -20,8 +20,13 @@ type User struct {
 	CityID          *uint64        `json:"city_id" gorm:"column:id_kota"`
 	IsAdmin         bool           `json:"is_admin" gorm:"default:false"`
 	EmailVerifiedAt *time.Time     `json:"email_verified_at"`
+	VerifySentAt    *time.Time     `json:"-" gorm:"column:email_verification_sent_at"`
 	LastLoginAt     *time.Time     `json:"last_login_at"`
 	Status          string         `json:"status" gorm:"default:active"`
+	LockedUntil     *time.Time     `json:"locked_until,omitempty" gorm:"column:locked_until"`
+	TOTPSecret      string         `json:"-" gorm:"column:totp_secret"`
+	TOTPEnabledAt   *time.Time     `json:"two_factor_enabled_at" gorm:"column:totp_enabled_at"`
+	TOTPLastStep    int64          `json:"-" gorm:"column:totp_last_step;default:0"`
 	CreatedAt       time.Time      `json:"created_at"`
 	UpdatedAt       time.Time      `json:"updated_at"`
 	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"`
@@ -39,6 +44,20 @@ type UserRepository interface {
 	Update(user *User) error
 	UpdateLastLogin(userID uint64, lastLogin time.Time) error
 	UpdateProfile(userID uint64, updates map[string]interface{}) error
+	MarkEmailVerified(userID uint64, verifiedAt time.Time) error
+	// ReserveVerificationEmail records that a verification email is being sent.
+	// It returns false when the previous one was sent after notBefore.
+	ReserveVerificationEmail(userID uint64, sentAt, notBefore time.Time) (bool, error)
+	LockAccount(userID uint64, until time.Time) error
+	UnlockAccount(userID uint64) error
+	// SetTOTPSecret stores a pending TOTP secret; 2FA stays disabled until
+	// EnableTOTP is called
+	SetTOTPSecret(userID uint64, secret string) error
+	EnableTOTP(userID uint64, enabledAt time.Time) error
+	DisableTOTP(userID uint64) error
+	// UseTOTPStep records the time step of an accepted TOTP code. It returns
+	// false when a code from that step or a later one was already used.
+	UseTOTPStep(userID uint64, step int64) (bool, error)
 }
 
 type RegisterRequest struct {
@@ -52,18 +71,30 @@ type RegisterRequest struct {
 type LoginRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required"`
+	ClientIP string `json:"-"`
 }
 
+// AuthResponse carries the issued tokens. When the account has 2FA enabled,
+// Login returns only TwoFactorRequired and a ChallengeToken to be exchanged
+// at /auth/2fa/verify.
 type AuthResponse struct {
-	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token"`
-	User         *User  `json:"user"`
+	AccessToken            string   `json:"access_token,omitempty"`
+	RefreshToken           string   `json:"refresh_token,omitempty"`
+	User                   *User    `json:"user,omitempty"`
+	Roles                  []string `json:"roles,omitempty"`
+	TwoFactorRequired      bool     `json:"two_factor_required,omitempty"`
+	ChallengeToken         string   `json:"challenge_token,omitempty"`
+	TwoFactorSetupRequired bool     `json:"two_factor_setup_required,omitempty"`
 }
 
 type RefreshTokenRequest struct {
 	RefreshToken string `json:"refresh_token" validate:"required"`
 }
 
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
 type UpdateProfileRequest struct {
 	Name        string  `json:"name,omitempty" validate:"omitempty,min=2,max=100"`
 	Phone       string  `json:"phone,omitempty" validate:"omitempty,min=10,max=15"`
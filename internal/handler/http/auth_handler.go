@@ -1,7 +1,10 @@
 package http
 
 import (
+	"errors"
+
 	"go-commerce/internal/domain"
+	"go-commerce/internal/handler/middleware"
 	"go-commerce/internal/handler/response"
 	"go-commerce/internal/usecase"
 
@@ -51,13 +54,14 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 
 // Login godoc
 // @Summary User login (Public)
-// @Description Authenticate user with email and password. This is a public endpoint accessible to everyone.
+// @Description Authenticate user with email and password. When the account has two-factor authentication enabled, the response carries only a challenge token to complete at /auth/2fa/verify. This is a public endpoint accessible to everyone.
 // @Tags Authentication
 // @Accept json
 // @Produce json
 // @Param request body domain.LoginRequest true "Login request"
 // @Success 200 {object} response.Response{data=domain.AuthResponse} "Login successful"
 // @Failure 400 {object} response.Response "Bad request"
+// @Failure 429 {object} response.Response "Too many failed attempts, see Retry-After header"
 // @Router /auth/login [post]
 func (h *AuthHandler) Login(c *fiber.Ctx) error {
 	var req domain.LoginRequest
@@ -69,26 +73,96 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 		return response.BadRequest(c, "Validation failed: "+err.Error())
 	}
 
+	req.ClientIP = c.IP()
+
 	authResponse, err := h.authUsecase.Login(&req)
 	if err != nil {
+		var throttleErr *domain.ThrottleError
+		if errors.As(err, &throttleErr) {
+			c.Set(fiber.HeaderRetryAfter, throttleErr.RetryAfterSeconds())
+			return response.TooManyRequests(c, err.Error())
+		}
 		return response.BadRequest(c, err.Error())
 	}
 
+	if authResponse.TwoFactorRequired {
+		return response.Success(c, "Two-factor authentication required", authResponse)
+	}
+
 	return response.Success(c, "Login successful", authResponse)
 }
 
+// RefreshToken godoc
+// @Summary Refresh access token (Public)
+// @Description Exchange a refresh token for a new access/refresh token pair. The presented refresh token is rotated and cannot be used again; replaying it revokes the whole session.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body domain.RefreshTokenRequest true "Refresh token request"
+// @Success 200 {object} response.Response{data=domain.AuthResponse} "Token refreshed successfully"
+// @Failure 400 {object} response.Response "Bad request"
+// @Failure 401 {object} response.Response "Invalid, expired or reused refresh token"
+// @Router /auth/refresh [post]
+func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
+	var req domain.RefreshTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return response.BadRequest(c, "Validation failed: "+err.Error())
+	}
+
+	authResponse, err := h.authUsecase.RefreshToken(&req)
+	if err != nil {
+		return response.Unauthorized(c, err.Error())
+	}
+
+	return response.Success(c, "Token refreshed successfully", authResponse)
+}
+
 // Logout godoc
 // @Summary User logout (Authenticated User)
-// @Description Logout current user. Requires authentication.
+// @Description Logout current session. The access token used for the request is revoked immediately; when a refresh token is sent, its session is revoked too. Requires authentication.
 // @Tags Authentication
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Param request body domain.LogoutRequest false "Optional refresh token of the session"
 // @Success 200 {object} response.Response "Logout successful"
 // @Failure 401 {object} response.Response "Unauthorized"
 // @Router /auth/logout [post]
 func (h *AuthHandler) Logout(c *fiber.Ctx) error {
-	// In a real implementation, you might want to blacklist the token
-	// For now, we'll just return success
+	var req domain.LogoutRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return response.BadRequest(c, "Invalid request body")
+		}
+	}
+
+	userID := middleware.GetUserID(c)
+	if err := h.authUsecase.Logout(userID, middleware.GetTokenID(c), middleware.GetTokenExpiresAt(c), &req); err != nil {
+		return response.BadRequest(c, err.Error())
+	}
+
 	return response.Success(c, "Logout successful", nil)
-}
\ No newline at end of file
+}
+
+// LogoutAll godoc
+// @Summary Logout from all devices (Authenticated User)
+// @Description Revoke every access and refresh token issued to the current user. Requires authentication.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response "Logged out from all devices"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Router /auth/logout-all [post]
+func (h *AuthHandler) LogoutAll(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if err := h.authUsecase.LogoutAll(userID); err != nil {
+		return response.InternalServerError(c, err.Error())
+	}
+
+	return response.Success(c, "Logged out from all devices", nil)
+}
@@ -0,0 +1,244 @@
+package http
+
+import (
+	"strconv"
+
+	"go-commerce/internal/domain"
+	"go-commerce/internal/handler/middleware"
+	"go-commerce/internal/handler/response"
+	"go-commerce/internal/usecase"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type CartHandler struct {
+	cartUsecase *usecase.CartUsecase
+	validator   *validator.Validate
+}
+
+func NewCartHandler(cartUsecase *usecase.CartUsecase) *CartHandler {
+	return &CartHandler{
+		cartUsecase: cartUsecase,
+		validator:   validator.New(),
+	}
+}
+
+// GetCart godoc
+// @Summary Get current user's cart (Authenticated User)
+// @Description Get the cart with every item re-validated against current price, product/store status and stock. Items that cannot be checked out carry an issue code.
+// @Tags Cart
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=domain.Cart} "Cart retrieved successfully"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /cart [get]
+func (h *CartHandler) GetCart(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		return response.Unauthorized(c, "User not authenticated")
+	}
+
+	cart, err := h.cartUsecase.GetCart(userID)
+	if err != nil {
+		return response.InternalServerError(c, err.Error())
+	}
+
+	return response.Success(c, "Cart retrieved successfully", cart)
+}
+
+// AddItem godoc
+// @Summary Add a product to the cart (Authenticated User)
+// @Description Add a product to the cart. Products with variants need a variant_id. If the product or variant is already in the cart its quantity is increased.
+// @Tags Cart
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body domain.AddCartItemRequest true "Cart item"
+// @Success 200 {object} response.Response{data=domain.Cart} "Item added to cart"
+// @Failure 400 {object} response.Response "Bad request - validation failed, product unavailable or insufficient stock"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Router /cart/items [post]
+func (h *CartHandler) AddItem(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		return response.Unauthorized(c, "User not authenticated")
+	}
+
+	var req domain.AddCartItemRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return response.BadRequest(c, "Validation failed: "+err.Error())
+	}
+
+	cart, err := h.cartUsecase.AddItem(userID, &req)
+	if err != nil {
+		return response.BadRequest(c, err.Error())
+	}
+
+	return response.Success(c, "Item added to cart", cart)
+}
+
+// UpdateItem godoc
+// @Summary Update cart item quantity (Authenticated User)
+// @Description Set the quantity of a product, or of one of its variants, that is already in the cart
+// @Tags Cart
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param productId path int true "Product ID"
+// @Param variant_id query int false "Variant ID, for products with variants"
+// @Param request body domain.UpdateCartItemRequest true "New quantity"
+// @Success 200 {object} response.Response{data=domain.Cart} "Cart updated successfully"
+// @Failure 400 {object} response.Response "Bad request - validation failed, product unavailable or insufficient stock"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 404 {object} response.Response "Product is not in the cart"
+// @Router /cart/items/{productId} [put]
+func (h *CartHandler) UpdateItem(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		return response.Unauthorized(c, "User not authenticated")
+	}
+
+	productID, err := strconv.ParseUint(c.Params("productId"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid product ID")
+	}
+
+	variantID, err := strconv.ParseUint(c.Query("variant_id", "0"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid variant ID")
+	}
+
+	var req domain.UpdateCartItemRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return response.BadRequest(c, "Validation failed: "+err.Error())
+	}
+
+	cart, err := h.cartUsecase.UpdateItem(userID, productID, variantID, &req)
+	if err != nil {
+		if err.Error() == "CART_ITEM_NOT_FOUND" {
+			return response.NotFound(c, err.Error())
+		}
+		return response.BadRequest(c, err.Error())
+	}
+
+	return response.Success(c, "Cart updated successfully", cart)
+}
+
+// RemoveItem godoc
+// @Summary Remove a product from the cart (Authenticated User)
+// @Description Remove a product, or one of its variants, from the cart
+// @Tags Cart
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param productId path int true "Product ID"
+// @Param variant_id query int false "Variant ID, for products with variants"
+// @Success 200 {object} response.Response{data=domain.Cart} "Item removed from cart"
+// @Failure 400 {object} response.Response "Invalid product ID"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 404 {object} response.Response "Product is not in the cart"
+// @Router /cart/items/{productId} [delete]
+func (h *CartHandler) RemoveItem(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		return response.Unauthorized(c, "User not authenticated")
+	}
+
+	productID, err := strconv.ParseUint(c.Params("productId"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid product ID")
+	}
+
+	variantID, err := strconv.ParseUint(c.Query("variant_id", "0"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid variant ID")
+	}
+
+	cart, err := h.cartUsecase.RemoveItem(userID, productID, variantID)
+	if err != nil {
+		if err.Error() == "CART_ITEM_NOT_FOUND" {
+			return response.NotFound(c, err.Error())
+		}
+		return response.InternalServerError(c, err.Error())
+	}
+
+	return response.Success(c, "Item removed from cart", cart)
+}
+
+// ClearCart godoc
+// @Summary Clear the cart (Authenticated User)
+// @Description Remove every item from the cart
+// @Tags Cart
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response "Cart cleared successfully"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /cart [delete]
+func (h *CartHandler) ClearCart(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		return response.Unauthorized(c, "User not authenticated")
+	}
+
+	if err := h.cartUsecase.ClearCart(userID); err != nil {
+		return response.InternalServerError(c, err.Error())
+	}
+
+	return response.Success(c, "Cart cleared successfully", nil)
+}
+
+// Checkout godoc
+// @Summary Checkout the cart (Authenticated User)
+// @Description Create a transaction from every item in the cart and empty the cart (atomic operation). Requires authentication.
+// @Tags Cart
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body domain.CheckoutCartRequest true "Shipping address and payment method"
+// @Success 201 {object} response.Response{data=domain.Transaction} "Checkout completed successfully"
+// @Failure 400 {object} response.Response "Bad request - empty cart, product unavailable or insufficient stock"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden - email not verified"
+// @Failure 409 {object} response.Response "Cart changed during checkout"
+// @Router /cart/checkout [post]
+func (h *CartHandler) Checkout(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		return response.Unauthorized(c, "User not authenticated")
+	}
+
+	var req domain.CheckoutCartRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return response.BadRequest(c, "Validation failed: "+err.Error())
+	}
+
+	transaction, err := h.cartUsecase.Checkout(userID, &req)
+	if err != nil {
+		switch err.Error() {
+		case "EMAIL_NOT_VERIFIED":
+			return response.Forbidden(c, err.Error())
+		case "CART_CHANGED":
+			return response.Conflict(c, err.Error())
+		}
+		return response.BadRequest(c, err.Error())
+	}
+
+	return response.Created(c, "Checkout completed successfully", transaction)
+}
@@ -0,0 +1,89 @@
+package http
+
+import (
+	"errors"
+	"strconv"
+
+	"go-commerce/internal/domain"
+	"go-commerce/internal/handler/response"
+	"go-commerce/pkg/pagination"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Lists name their cursors so that a cursor of one list is refused by another
+const (
+	productCursorScope       = "products"
+	myProductCursorScope     = "products/my"
+	storeCursorScope         = "stores"
+	myTransactionCursorScope = "transactions/my"
+)
+
+// cursorMode reports whether a list request asks for cursor pagination,
+// either with pagination=cursor or by passing back a cursor. Without it lists
+// keep paging by page number.
+func cursorMode(c *fiber.Ctx) bool {
+	return c.Query("pagination") == "cursor" || c.Query("cursor") != ""
+}
+
+// cursorPage reads the limit, sort_by and cursor parameters of a list in
+// cursor mode. A cursor keeps the sort order it was issued for, so sort_by
+// may be left out next to it but must not differ.
+func cursorPage(c *fiber.Ctx, cursors *pagination.Signer, scope string) (*domain.CursorPage, error) {
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	page := &domain.CursorPage{SortBy: c.Query("sort_by", ""), Limit: limit}
+
+	token := c.Query("cursor", "")
+	if token == "" {
+		return page, nil
+	}
+
+	var cursor domain.Cursor
+	if err := cursors.Decode(token, &cursor); err != nil || cursor.Scope != scope {
+		return nil, errors.New("invalid cursor")
+	}
+	if page.SortBy != "" && page.SortBy != cursor.SortBy {
+		return nil, errors.New("cursor was issued for sort_by=" + cursor.SortBy)
+	}
+
+	page.SortBy = cursor.SortBy
+	page.Cursor = &cursor
+	return page, nil
+}
+
+// cursorMeta signs the cursors of the pages around a cursor page
+func cursorMeta(cursors *pagination.Signer, scope string, page *domain.CursorPage, links *domain.CursorLinks) (response.PaginationMeta, error) {
+	meta := response.PaginationMeta{Limit: page.Limit}
+
+	for _, link := range []struct {
+		cursor *domain.Cursor
+		token  *string
+	}{
+		{links.Next, &meta.NextCursor},
+		{links.Prev, &meta.PrevCursor},
+	} {
+		if link.cursor == nil {
+			continue
+		}
+		link.cursor.Scope = scope
+		token, err := cursors.Encode(link.cursor)
+		if err != nil {
+			return response.PaginationMeta{}, err
+		}
+		*link.token = token
+	}
+
+	return meta, nil
+}
+
+// cursorError answers errors of a cursor list: bad sort orders and cursors
+// are the client's, anything else is ours
+func cursorError(c *fiber.Ctx, err error) error {
+	switch err.Error() {
+	case "INVALID_SORT_BY":
+		return response.BadRequest(c, "sort_by is not supported with cursor pagination")
+	case "INVALID_CURSOR":
+		return response.BadRequest(c, "invalid cursor")
+	}
+	return response.InternalServerError(c, err.Error())
+}
@@ -0,0 +1,261 @@
+package http
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"go-commerce/internal/domain"
+	"go-commerce/internal/handler/middleware"
+	"go-commerce/internal/handler/response"
+	"go-commerce/internal/usecase"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type DisputeHandler struct {
+	orderCompletionUsecase *usecase.OrderCompletionUsecase
+	validator              *validator.Validate
+}
+
+func NewDisputeHandler(orderCompletionUsecase *usecase.OrderCompletionUsecase) *DisputeHandler {
+	return &DisputeHandler{
+		orderCompletionUsecase: orderCompletionUsecase,
+		validator:              validator.New(),
+	}
+}
+
+// OpenDispute godoc
+// @Summary Dispute an order (Buyer)
+// @Description Buyer disputes a shipped order within the confirmation window, e.g. when the parcel is damaged or incomplete. Send the reason and up to 5 evidence photos (JPG, JPEG, PNG, max 5MB each) as multipart form data. The order is not completed while the dispute is open; an admin resolves it with a refund, a partial refund or a rejection.
+// @Tags Transactions - Buyer Operations
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Transaction ID"
+// @Param reason formData string true "What is wrong with the order"
+// @Param photos formData file false "Evidence photos"
+// @Success 201 {object} response.Response{data=domain.Dispute} "Dispute opened successfully"
+// @Failure 400 {object} response.Response "Bad request - validation failed, order not disputable or window closed"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Transaction not found"
+// @Failure 409 {object} response.Response "Order already disputed"
+// @Router /transactions/{id}/dispute [post]
+func (h *DisputeHandler) OpenDispute(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+
+	transactionID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid transaction ID")
+	}
+
+	req := domain.OpenDisputeRequest{Reason: c.FormValue("reason")}
+	if err := h.validator.Struct(&req); err != nil {
+		return response.BadRequest(c, "Validation failed: "+err.Error())
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		return response.BadRequest(c, "Invalid form data")
+	}
+	files := form.File["photos"]
+	if len(files) > domain.MaxDisputePhotos {
+		return response.BadRequest(c, fmt.Sprintf("Too many photos. Maximum %d allowed", domain.MaxDisputePhotos))
+	}
+
+	for _, file := range files {
+		if !isValidImageType(file.Header.Get("Content-Type")) {
+			return response.BadRequest(c, "Invalid file type. Only JPG, JPEG, PNG allowed")
+		}
+		if file.Size > 5*1024*1024 {
+			return response.BadRequest(c, "File size too large. Maximum 5MB allowed")
+		}
+	}
+
+	// Create upload directory if not exists
+	uploadDir := "uploads/disputes"
+	if err := os.MkdirAll(uploadDir, 0755); err != nil {
+		return response.InternalServerError(c, "Failed to create upload directory")
+	}
+
+	var filePaths []string
+	var photoURLs []string
+	removeUploads := func() {
+		for _, filePath := range filePaths {
+			os.Remove(filePath)
+		}
+	}
+	for _, file := range files {
+		filename := generateFileName(file.Filename)
+		filePath := filepath.Join(uploadDir, filename)
+		if err := c.SaveFile(file, filePath); err != nil {
+			removeUploads()
+			return response.InternalServerError(c, "Failed to save file")
+		}
+		filePaths = append(filePaths, filePath)
+		photoURLs = append(photoURLs, fmt.Sprintf("/uploads/disputes/%s", filename))
+	}
+
+	dispute, err := h.orderCompletionUsecase.OpenDispute(userID, transactionID, &req, photoURLs)
+	if err != nil {
+		// Delete uploaded files if the dispute was not opened
+		removeUploads()
+		switch err.Error() {
+		case "transaction not found":
+			return response.NotFound(c, err.Error())
+		case "forbidden":
+			return response.Forbidden(c, err.Error())
+		case "DISPUTE_ALREADY_OPENED":
+			return response.Conflict(c, err.Error())
+		}
+		return response.BadRequest(c, err.Error())
+	}
+
+	return response.Created(c, "Dispute opened successfully", dispute)
+}
+
+// GetDispute godoc
+// @Summary Get the dispute of an order (Authenticated User)
+// @Description Get the dispute of an order with its evidence photos and resolution. Available to the buyer, the seller of the store and admins with the disputes:resolve permission.
+// @Tags Transactions
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Transaction ID"
+// @Success 200 {object} response.Response{data=domain.Dispute} "Dispute retrieved successfully"
+// @Failure 400 {object} response.Response "Invalid transaction ID"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Transaction or dispute not found"
+// @Router /transactions/{id}/dispute [get]
+func (h *DisputeHandler) GetDispute(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+
+	transactionID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid transaction ID")
+	}
+
+	dispute, err := h.orderCompletionUsecase.GetDispute(userID, transactionID, middleware.HasPermission(c, domain.PermissionDisputesResolve))
+	if err != nil {
+		switch err.Error() {
+		case "transaction not found", "dispute not found":
+			return response.NotFound(c, err.Error())
+		case "forbidden":
+			return response.Forbidden(c, err.Error())
+		}
+		return response.InternalServerError(c, err.Error())
+	}
+
+	return response.Success(c, "Dispute retrieved successfully", dispute)
+}
+
+// ListDisputes godoc
+// @Summary List disputes (Admin)
+// @Description List disputes by status, oldest first. Lists open disputes by default. Admin access required.
+// @Tags Disputes - Admin Operations
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Dispute status" Enums(open, refunded, partially_refunded, rejected)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(10)
+// @Success 200 {object} response.PaginatedResponse{data=[]domain.Dispute} "Disputes retrieved successfully"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Router /admin/disputes [get]
+func (h *DisputeHandler) ListDisputes(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+
+	disputes, total, err := h.orderCompletionUsecase.ListDisputes(c.Query("status"), page, limit)
+	if err != nil {
+		return response.InternalServerError(c, err.Error())
+	}
+
+	meta := response.PaginationMeta{
+		Page:      page,
+		Limit:     limit,
+		Total:     total,
+		TotalPage: int((total + int64(limit) - 1) / int64(limit)),
+	}
+
+	return response.SuccessWithMeta(c, "Disputes retrieved successfully", disputes, meta)
+}
+
+// GetDisputeByID godoc
+// @Summary Get dispute (Admin)
+// @Description Get a dispute with its evidence photos. Admin access required.
+// @Tags Disputes - Admin Operations
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Dispute ID"
+// @Success 200 {object} response.Response{data=domain.Dispute} "Dispute retrieved successfully"
+// @Failure 400 {object} response.Response "Invalid dispute ID"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Dispute not found"
+// @Router /admin/disputes/{id} [get]
+func (h *DisputeHandler) GetDisputeByID(c *fiber.Ctx) error {
+	disputeID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid dispute ID")
+	}
+
+	dispute, err := h.orderCompletionUsecase.GetDisputeByID(disputeID)
+	if err != nil {
+		return response.NotFound(c, err.Error())
+	}
+
+	return response.Success(c, "Dispute retrieved successfully", dispute)
+}
+
+// ResolveDispute godoc
+// @Summary Resolve dispute (Admin)
+// @Description Admin settles an open dispute. `refund` refunds everything not refunded yet; `partial_refund` refunds the given items and/or amount and completes the order; `reject` completes the order as it is. Admin access required.
+// @Tags Disputes - Admin Operations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Dispute ID"
+// @Param request body domain.ResolveDisputeRequest true "Resolution"
+// @Success 200 {object} response.Response{data=domain.Dispute} "Dispute resolved successfully"
+// @Failure 400 {object} response.Response "Bad request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Dispute not found"
+// @Failure 409 {object} response.Response "Dispute already resolved"
+// @Failure 500 {object} response.Response "Payment provider refund failed"
+// @Router /admin/disputes/{id}/resolve [put]
+func (h *DisputeHandler) ResolveDispute(c *fiber.Ctx) error {
+	adminID := middleware.GetUserID(c)
+
+	disputeID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid dispute ID")
+	}
+
+	var req domain.ResolveDisputeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		return response.BadRequest(c, "Validation failed: "+err.Error())
+	}
+
+	dispute, err := h.orderCompletionUsecase.ResolveDispute(adminID, disputeID, &req)
+	if err != nil {
+		switch err.Error() {
+		case "dispute not found", "transaction not found":
+			return response.NotFound(c, err.Error())
+		case "DISPUTE_ALREADY_RESOLVED":
+			return response.Conflict(c, err.Error())
+		case "PAYMENT_REFUND_FAILED":
+			return response.InternalServerError(c, err.Error())
+		}
+		return response.BadRequest(c, err.Error())
+	}
+
+	return response.Success(c, "Dispute resolved successfully", dispute)
+}
@@ -0,0 +1,83 @@
+package http
+
+import (
+	"go-commerce/internal/domain"
+	"go-commerce/internal/handler/middleware"
+	"go-commerce/internal/handler/response"
+	"go-commerce/internal/usecase"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type EmailVerificationHandler struct {
+	emailVerificationUsecase *usecase.EmailVerificationUsecase
+	validator                *validator.Validate
+}
+
+func NewEmailVerificationHandler(emailVerificationUsecase *usecase.EmailVerificationUsecase) *EmailVerificationHandler {
+	return &EmailVerificationHandler{
+		emailVerificationUsecase: emailVerificationUsecase,
+		validator:                validator.New(),
+	}
+}
+
+// VerifyEmail godoc
+// @Summary Verify email address (Public)
+// @Description Confirm ownership of an email address using the token sent in the verification email. This is a public endpoint accessible to everyone.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body domain.VerifyEmailRequest true "Verification token"
+// @Success 200 {object} response.Response "Email verified successfully"
+// @Failure 400 {object} response.Response "Invalid or expired token"
+// @Failure 409 {object} response.Response "Email already verified"
+// @Router /auth/verify-email [post]
+func (h *EmailVerificationHandler) VerifyEmail(c *fiber.Ctx) error {
+	var req domain.VerifyEmailRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return response.BadRequest(c, "Validation failed: "+err.Error())
+	}
+
+	if err := h.emailVerificationUsecase.VerifyEmail(&req); err != nil {
+		if err.Error() == "EMAIL_ALREADY_VERIFIED" {
+			return response.Conflict(c, err.Error())
+		}
+		return response.BadRequest(c, err.Error())
+	}
+
+	return response.Success(c, "Email verified successfully", nil)
+}
+
+// ResendVerificationEmail godoc
+// @Summary Resend verification email (Authenticated User)
+// @Description Send a new verification link to the current user's email address. Requests are rate limited per user. Requires authentication.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response "Verification email sent"
+// @Failure 400 {object} response.Response "Bad request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 409 {object} response.Response "Email already verified"
+// @Failure 429 {object} response.Response "Too many requests"
+// @Router /auth/verify-email/resend [post]
+func (h *EmailVerificationHandler) ResendVerificationEmail(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+
+	if err := h.emailVerificationUsecase.ResendVerificationEmail(userID); err != nil {
+		switch err.Error() {
+		case "EMAIL_ALREADY_VERIFIED":
+			return response.Conflict(c, err.Error())
+		case "VERIFICATION_EMAIL_RATE_LIMITED":
+			return response.TooManyRequests(c, err.Error())
+		}
+		return response.BadRequest(c, err.Error())
+	}
+
+	return response.Success(c, "Verification email sent", nil)
+}
@@ -0,0 +1,30 @@
+package http
+
+import (
+	"go-commerce/pkg/jwt"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type JWKSHandler struct {
+	jwtManager *jwt.JWTManager
+}
+
+func NewJWKSHandler(jwtManager *jwt.JWTManager) *JWKSHandler {
+	return &JWKSHandler{
+		jwtManager: jwtManager,
+	}
+}
+
+// GetJWKS godoc
+// @Summary JSON Web Key Set
+// @Description Public keys that verify tokens issued by this service, in standard JWKS format (not wrapped in the API response envelope). Tokens name their key in the kid header.
+// @Tags System
+// @Produce json
+// @Success 200 {object} jwt.JSONWebKeySet "Verification keys"
+// @Router /.well-known/jwks.json [get]
+func (h *JWKSHandler) GetJWKS(c *fiber.Ctx) error {
+	// Short cache so verifiers pick up rotated keys quickly
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(h.jwtManager.JWKS())
+}
@@ -0,0 +1,54 @@
+package http
+
+import (
+	"strconv"
+
+	"go-commerce/internal/handler/response"
+	"go-commerce/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type LoginThrottleHandler struct {
+	loginThrottleUsecase *usecase.LoginThrottleUsecase
+}
+
+func NewLoginThrottleHandler(loginThrottleUsecase *usecase.LoginThrottleUsecase) *LoginThrottleHandler {
+	return &LoginThrottleHandler{
+		loginThrottleUsecase: loginThrottleUsecase,
+	}
+}
+
+// UnlockAccount godoc
+// @Summary Unlock a user account (Admin only)
+// @Description Lift a temporary lockout caused by repeated failed logins and reset the account's failure counter.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} response.Response "Account unlocked successfully"
+// @Failure 400 {object} response.Response "Bad request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden - admin only"
+// @Failure 404 {object} response.Response "User not found"
+// @Failure 409 {object} response.Response "Conflict - account not locked"
+// @Router /admin/users/{id}/unlock [put]
+func (h *LoginThrottleHandler) UnlockAccount(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid user ID")
+	}
+
+	if err := h.loginThrottleUsecase.UnlockAccount(id); err != nil {
+		switch err.Error() {
+		case "user not found":
+			return response.NotFound(c, err.Error())
+		case "ACCOUNT_NOT_LOCKED":
+			return response.Conflict(c, err.Error())
+		}
+		return response.BadRequest(c, err.Error())
+	}
+
+	return response.Success(c, "Account unlocked successfully", nil)
+}
@@ -0,0 +1,76 @@
+package http
+
+import (
+	"go-commerce/internal/domain"
+	"go-commerce/internal/handler/response"
+	"go-commerce/internal/usecase"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type PasswordResetHandler struct {
+	passwordResetUsecase *usecase.PasswordResetUsecase
+	validator            *validator.Validate
+}
+
+func NewPasswordResetHandler(passwordResetUsecase *usecase.PasswordResetUsecase) *PasswordResetHandler {
+	return &PasswordResetHandler{
+		passwordResetUsecase: passwordResetUsecase,
+		validator:            validator.New(),
+	}
+}
+
+// ForgotPassword godoc
+// @Summary Request a password reset (Public)
+// @Description Email a single-use password reset link. The response is the same whether or not the email is registered. This is a public endpoint accessible to everyone.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body domain.ForgotPasswordRequest true "Account email"
+// @Success 200 {object} response.Response "Reset instructions sent if the email is registered"
+// @Failure 400 {object} response.Response "Bad request"
+// @Router /auth/forgot-password [post]
+func (h *PasswordResetHandler) ForgotPassword(c *fiber.Ctx) error {
+	var req domain.ForgotPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return response.BadRequest(c, "Validation failed: "+err.Error())
+	}
+
+	if err := h.passwordResetUsecase.ForgotPassword(&req); err != nil {
+		return response.InternalServerError(c, err.Error())
+	}
+
+	return response.Success(c, "If the email is registered, password reset instructions have been sent", nil)
+}
+
+// ResetPassword godoc
+// @Summary Reset password (Public)
+// @Description Set a new password using a reset token. The token can only be used once and every existing session of the user is revoked. This is a public endpoint accessible to everyone.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body domain.ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} response.Response "Password reset successfully"
+// @Failure 400 {object} response.Response "Invalid or expired token"
+// @Router /auth/reset-password [post]
+func (h *PasswordResetHandler) ResetPassword(c *fiber.Ctx) error {
+	var req domain.ResetPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return response.BadRequest(c, "Validation failed: "+err.Error())
+	}
+
+	if err := h.passwordResetUsecase.ResetPassword(&req); err != nil {
+		return response.BadRequest(c, err.Error())
+	}
+
+	return response.Success(c, "Password reset successfully", nil)
+}
@@ -2,8 +2,11 @@ package http
 
 import (
 	"go-commerce/internal/domain"
+	"go-commerce/internal/handler/middleware"
 	"go-commerce/internal/handler/response"
+	"go-commerce/internal/usecase"
 	"strconv"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
@@ -12,21 +15,58 @@ import (
 var validate = validator.New()
 
 type PaymentIntentHandler struct {
-	paymentIntentUC domain.PaymentIntentUsecase
+	paymentIntentUC   domain.PaymentIntentUsecase
+	paymentCallbackUC *usecase.PaymentCallbackUsecase
 }
 
-func NewPaymentIntentHandler(paymentIntentUC domain.PaymentIntentUsecase) *PaymentIntentHandler {
+func NewPaymentIntentHandler(paymentIntentUC domain.PaymentIntentUsecase, paymentCallbackUC *usecase.PaymentCallbackUsecase) *PaymentIntentHandler {
 	return &PaymentIntentHandler{
-		paymentIntentUC: paymentIntentUC,
+		paymentIntentUC:   paymentIntentUC,
+		paymentCallbackUC: paymentCallbackUC,
 	}
 }
 
 type CreatePaymentIntentRequest struct {
-	Method string `json:"method" validate:"required,oneof=bank_transfer credit_card e_wallet"`
+	Method string `json:"method" validate:"required,oneof=transfer ewallet credit_card"`
 }
 
 // @Summary Create Payment Intent
-// @Description Create payment intent for transaction
+// @Description Create payment intent for a checkout. One payment covers every per-store order of the checkout.
+// @Tags Payment Intent
+// @Accept json
+// @Produce json
+// @Param id path int true "Checkout ID"
+// @Param request body CreatePaymentIntentRequest true "Payment method"
+// @Success 201 {object} response.Response{data=domain.PaymentIntent}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Security BearerAuth
+// @Router /checkouts/{id}/pay [post]
+func (h *PaymentIntentHandler) CreatePaymentIntent(c *fiber.Ctx) error {
+	checkoutID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return response.BadRequest(c, "Invalid checkout ID")
+	}
+
+	var req CreatePaymentIntentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		return response.BadRequest(c, err.Error())
+	}
+
+	intent, err := h.paymentIntentUC.CreatePaymentIntent(uint(checkoutID), req.Method)
+	if err != nil {
+		return response.BadRequest(c, err.Error())
+	}
+
+	return response.Success(c, "Payment intent created successfully", intent)
+}
+
+// @Summary Create Payment Intent for an order
+// @Description Kept for clients that pay per order. Creates the payment intent of the checkout the order belongs to, so the intent covers every order of that checkout. Use POST /checkouts/{id}/pay instead.
 // @Tags Payment Intent
 // @Accept json
 // @Produce json
@@ -36,9 +76,10 @@ type CreatePaymentIntentRequest struct {
 // @Failure 400 {object} response.Response
 // @Failure 404 {object} response.Response
 // @Security BearerAuth
+// @Deprecated
 // @Router /transactions/{id}/pay [post]
-func (h *PaymentIntentHandler) CreatePaymentIntent(c *fiber.Ctx) error {
-	trxID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+func (h *PaymentIntentHandler) CreatePaymentIntentForOrder(c *fiber.Ctx) error {
+	trxID, err := strconv.ParseUint(c.Params("id"), 10, 64)
 	if err != nil {
 		return response.BadRequest(c, "Invalid transaction ID")
 	}
@@ -52,14 +93,50 @@ func (h *PaymentIntentHandler) CreatePaymentIntent(c *fiber.Ctx) error {
 		return response.BadRequest(c, err.Error())
 	}
 
-	intent, err := h.paymentIntentUC.CreatePaymentIntent(uint(trxID), req.Method)
+	intent, err := h.paymentIntentUC.CreatePaymentIntentForOrder(trxID, req.Method)
 	if err != nil {
+		if err.Error() == "transaction not found" {
+			return response.NotFound(c, err.Error())
+		}
 		return response.BadRequest(c, err.Error())
 	}
 
 	return response.Success(c, "Payment intent created successfully", intent)
 }
 
+// @Summary Get Payment Intent
+// @Description Get a payment intent of my checkout with its virtual account, QR payload or payment page. A pending intent is first checked with its payment provider.
+// @Tags Payment Intent
+// @Produce json
+// @Param intentId path int true "Payment Intent ID"
+// @Success 200 {object} response.Response{data=domain.PaymentIntent}
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Security BearerAuth
+// @Router /payments/{intentId} [get]
+func (h *PaymentIntentHandler) GetPaymentIntent(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+
+	intentID, err := strconv.ParseUint(c.Params("intentId"), 10, 32)
+	if err != nil {
+		return response.BadRequest(c, "Invalid payment intent ID")
+	}
+
+	intent, err := h.paymentIntentUC.GetPaymentIntent(userID, uint(intentID))
+	if err != nil {
+		switch err.Error() {
+		case "access denied":
+			return response.Forbidden(c, err.Error())
+		case "payment intent not found":
+			return response.NotFound(c, err.Error())
+		default:
+			return response.InternalServerError(c, err.Error())
+		}
+	}
+
+	return response.Success(c, "Payment intent retrieved successfully", intent)
+}
+
 // @Summary Simulate Payment Success (Admin Only)
 // @Description Simulate payment success callback - Admin only endpoint
 // @Tags Payment Simulation (Admin)
@@ -77,7 +154,7 @@ func (h *PaymentIntentHandler) SimulatePaymentSuccess(c *fiber.Ctx) error {
 		return response.BadRequest(c, "Invalid payment intent ID")
 	}
 
-	err = h.paymentIntentUC.ProcessPaymentSuccess(uint(intentID))
+	err = h.paymentIntentUC.ProcessPaymentSuccess(uint(intentID), time.Now())
 	if err != nil {
 		return response.BadRequest(c, err.Error())
 	}
@@ -110,21 +187,19 @@ func (h *PaymentIntentHandler) SimulatePaymentFailed(c *fiber.Ctx) error {
 	return response.Success(c, "Payment failed processed", nil)
 }
 
-type PaymentCallbackRequest struct {
-	Status     string `json:"status" validate:"required,oneof=success failed"`
-	GatewayRef string `json:"gateway_ref"`
-	PaidAt     string `json:"paid_at"`
-}
-
 // @Summary Payment Gateway Callback
-// @Description Receive payment status from payment gateway - updates payment intent
+// @Description Receive payment status from a payment gateway. The raw body must be signed with the gateway's secret: X-Payment-Signature is the hex HMAC-SHA256 of "<X-Payment-Timestamp>.<body>". gateway_ref must be the provider_ref of the intent. Each gateway_ref is applied once; replays are acknowledged and ignored.
 // @Tags Payment Gateway Callback
 // @Accept json
 // @Produce json
 // @Param intentId path int true "Payment Intent ID"
-// @Param request body PaymentCallbackRequest true "Payment status from gateway"
+// @Param X-Payment-Gateway header string true "Gateway name"
+// @Param X-Payment-Timestamp header string true "Unix time the callback was signed"
+// @Param X-Payment-Signature header string true "Hex HMAC-SHA256 signature"
+// @Param request body domain.PaymentCallbackRequest true "Payment status from gateway"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
 // @Router /callbacks/payments/{intentId} [post]
 func (h *PaymentIntentHandler) OnPaymentCallback(c *fiber.Ctx) error {
 	intentID, err := strconv.ParseUint(c.Params("intentId"), 10, 32)
@@ -132,28 +207,26 @@ func (h *PaymentIntentHandler) OnPaymentCallback(c *fiber.Ctx) error {
 		return response.BadRequest(c, "Invalid payment intent ID")
 	}
 
-	var req PaymentCallbackRequest
-	if err := c.BodyParser(&req); err != nil {
-		return response.BadRequest(c, "Invalid request body")
-	}
-
-	if err := validate.Struct(&req); err != nil {
-		return response.BadRequest(c, err.Error())
-	}
-
-	// Route to appropriate handler based on status
-	switch req.Status {
-	case "success":
-		err = h.paymentIntentUC.ProcessPaymentSuccess(uint(intentID))
-	case "failed":
-		err = h.paymentIntentUC.ProcessPaymentFailed(uint(intentID))
-	default:
-		return response.BadRequest(c, "Invalid payment status")
+	duplicate, err := h.paymentCallbackUC.HandleCallback(&domain.PaymentCallback{
+		IntentID:  uint(intentID),
+		Gateway:   c.Get("X-Payment-Gateway"),
+		Timestamp: c.Get("X-Payment-Timestamp"),
+		Signature: c.Get("X-Payment-Signature"),
+		Body:      c.Body(),
+		RemoteIP:  c.IP(),
+	})
+	if err != nil {
+		switch err.Error() {
+		case "UNKNOWN_GATEWAY", "INVALID_SIGNATURE", "CALLBACK_EXPIRED":
+			return response.Unauthorized(c, err.Error())
+		default:
+			return response.BadRequest(c, err.Error())
+		}
 	}
 
-	if err != nil {
-		return response.BadRequest(c, err.Error())
+	if duplicate {
+		return response.Success(c, "Duplicate payment callback ignored", nil)
 	}
 
 	return response.Success(c, "Payment callback processed", nil)
-}
\ No newline at end of file
+}
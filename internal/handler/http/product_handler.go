@@ -12,6 +12,7 @@ import (
 	"go-commerce/internal/handler/middleware"
 	"go-commerce/internal/handler/response"
 	"go-commerce/internal/usecase"
+	"go-commerce/pkg/pagination"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
@@ -20,12 +21,14 @@ import (
 
 type ProductHandler struct {
 	productUsecase *usecase.ProductUsecase
+	cursors        *pagination.Signer
 	validator      *validator.Validate
 }
 
-func NewProductHandler(productUsecase *usecase.ProductUsecase) *ProductHandler {
+func NewProductHandler(productUsecase *usecase.ProductUsecase, cursors *pagination.Signer) *ProductHandler {
 	return &ProductHandler{
 		productUsecase: productUsecase,
+		cursors:        cursors,
 		validator:      validator.New(),
 	}
 }
@@ -64,7 +67,7 @@ func (h *ProductHandler) CreateProduct(c *fiber.Ctx) error {
 
 // GetMyProducts godoc
 // @Summary Get current user's products (Seller only)
-// @Description Get all products owned by the authenticated user with pagination and search. Only store owners can access their products.
+// @Description Get all products owned by the authenticated user with pagination and search. Only store owners can access their products. Pages by number by default; pagination=cursor or a cursor from meta.next_cursor/meta.prev_cursor pages by cursor instead.
 // @Tags Products
 // @Accept json
 // @Produce json
@@ -72,12 +75,20 @@ func (h *ProductHandler) CreateProduct(c *fiber.Ctx) error {
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page" default(10)
 // @Param search query string false "Search by product name"
+// @Param pagination query string false "cursor to page by cursor"
+// @Param cursor query string false "Cursor of the page to read"
+// @Param sort_by query string false "Sort by in cursor mode: newest (default), oldest, price_asc, price_desc, popular, name_asc, name_desc"
 // @Success 200 {object} response.PaginatedResponse{data=[]domain.Product} "Products retrieved successfully"
+// @Failure 400 {object} response.Response "Invalid cursor or sort order"
 // @Failure 401 {object} response.Response "Unauthorized"
 // @Failure 403 {object} response.Response "Forbidden - store owner only"
 // @Failure 500 {object} response.Response "Internal server error"
 // @Router /products/my [get]
 func (h *ProductHandler) GetMyProducts(c *fiber.Ctx) error {
+	if cursorMode(c) {
+		return h.getMyProductsByCursor(c)
+	}
+
 	page, _ := strconv.Atoi(c.Query("page", "1"))
 	limit, _ := strconv.Atoi(c.Query("limit", "10"))
 	search := c.Query("search", "")
@@ -96,20 +107,45 @@ func (h *ProductHandler) GetMyProducts(c *fiber.Ctx) error {
 	})
 }
 
+func (h *ProductHandler) getMyProductsByCursor(c *fiber.Ctx) error {
+	page, err := cursorPage(c, h.cursors, myProductCursorScope)
+	if err != nil {
+		return response.BadRequest(c, err.Error())
+	}
+
+	userID := middleware.GetUserID(c)
+	products, links, err := h.productUsecase.GetMyProductsByCursor(userID, c.Query("search", ""), page)
+	if err != nil {
+		return cursorError(c, err)
+	}
+
+	meta, err := cursorMeta(h.cursors, myProductCursorScope, page, links)
+	if err != nil {
+		return response.InternalServerError(c, err.Error())
+	}
+
+	return response.Paginated(c, "Products retrieved successfully", products, meta)
+}
+
 // GetAllProducts godoc
 // @Summary Get all products (Public)
-// @Description Get all products with pagination and filtering. This is a public endpoint accessible to everyone.
+// @Description Get all products with pagination and filtering. This is a public endpoint accessible to everyone. Pages by number by default; pagination=cursor or a cursor from meta.next_cursor/meta.prev_cursor pages by cursor instead, which does not support search.
 // @Tags Products
 // @Accept json
 // @Produce json
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page" default(10)
-// @Param search query string false "Search by product name"
+// @Param search query string false "Full-text search in product name and description. Tolerates small typos, ignores Indonesian stop-words and adds facet counts by category, price range and store to the response."
 // @Param category_id query string false "Filter by category ID"
+// @Param store_id query string false "Filter by store ID"
 // @Param min_price query string false "Minimum price filter"
 // @Param max_price query string false "Maximum price filter"
-// @Param sort_by query string false "Sort by: newest, oldest, price_asc, price_desc, popular, name_asc, name_desc" default(newest)
-// @Success 200 {object} response.PaginatedResponse{data=[]domain.Product} "Products retrieved successfully"
+// @Param min_rating query string false "Minimum average rating filter, 1 to 5"
+// @Param sort_by query string false "Sort by: relevance (default with search), newest (default without), oldest, price_asc, price_desc, popular, rating, name_asc, name_desc"
+// @Param pagination query string false "cursor to page by cursor"
+// @Param cursor query string false "Cursor of the page to read"
+// @Success 200 {object} response.PaginatedResponse{data=[]domain.Product,facets=domain.ProductSearchFacets} "Products retrieved successfully"
+// @Failure 400 {object} response.Response "Invalid cursor or sort order"
 // @Failure 500 {object} response.Response "Internal server error"
 // @Router /products [get]
 func (h *ProductHandler) GetAllProducts(c *fiber.Ctx) error {
@@ -119,13 +155,33 @@ func (h *ProductHandler) GetAllProducts(c *fiber.Ctx) error {
 	filter := &domain.ProductFilter{
 		Search:     c.Query("search", ""),
 		CategoryID: c.Query("category_id", ""),
+		StoreID:    c.Query("store_id", ""),
 		MinPrice:   c.Query("min_price", ""),
 		MaxPrice:   c.Query("max_price", ""),
-		SortBy:     c.Query("sort_by", "newest"),
+		MinRating:  c.Query("min_rating", ""),
+		SortBy:     c.Query("sort_by", ""),
 		Page:       page,
 		Limit:      limit,
 	}
 
+	if cursorMode(c) {
+		return h.getAllProductsByCursor(c, filter)
+	}
+
+	if filter.Search != "" {
+		products, total, facets, err := h.productUsecase.SearchProducts(filter)
+		if err != nil {
+			return response.InternalServerError(c, err.Error())
+		}
+
+		return response.PaginatedWithFacets(c, "Products retrieved successfully", products, response.PaginationMeta{
+			Page:      filter.Page,
+			Limit:     filter.Limit,
+			Total:     total,
+			TotalPage: (int(total) + filter.Limit - 1) / filter.Limit,
+		}, facets)
+	}
+
 	products, total, err := h.productUsecase.GetAllProducts(filter)
 	if err != nil {
 		return response.InternalServerError(c, err.Error())
@@ -139,6 +195,29 @@ func (h *ProductHandler) GetAllProducts(c *fiber.Ctx) error {
 	})
 }
 
+func (h *ProductHandler) getAllProductsByCursor(c *fiber.Ctx, filter *domain.ProductFilter) error {
+	if filter.Search != "" {
+		return response.BadRequest(c, "search is not supported with cursor pagination")
+	}
+
+	page, err := cursorPage(c, h.cursors, productCursorScope)
+	if err != nil {
+		return response.BadRequest(c, err.Error())
+	}
+
+	products, links, err := h.productUsecase.GetAllProductsByCursor(filter, page)
+	if err != nil {
+		return cursorError(c, err)
+	}
+
+	meta, err := cursorMeta(h.cursors, productCursorScope, page, links)
+	if err != nil {
+		return response.InternalServerError(c, err.Error())
+	}
+
+	return response.Paginated(c, "Products retrieved successfully", products, meta)
+}
+
 // GetProductByID godoc
 // @Summary Get product by ID (Public)
 // @Description Get a single product by its ID. This is a public endpoint accessible to everyone.
@@ -0,0 +1,185 @@
+package http
+
+import (
+	"strconv"
+
+	"go-commerce/internal/domain"
+	"go-commerce/internal/handler/middleware"
+	"go-commerce/internal/handler/response"
+	"go-commerce/internal/usecase"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ProductVariantHandler struct {
+	productVariantUsecase *usecase.ProductVariantUsecase
+	validator             *validator.Validate
+}
+
+func NewProductVariantHandler(productVariantUsecase *usecase.ProductVariantUsecase) *ProductVariantHandler {
+	return &ProductVariantHandler{
+		productVariantUsecase: productVariantUsecase,
+		validator:             validator.New(),
+	}
+}
+
+// SetOptions godoc
+// @Summary Set product options (Seller only)
+// @Description Replace the options a product varies in, e.g. `{"options": [{"name": "Ukuran", "values": ["S", "M", "L"]}]}`. Up to 3 options. Values used by existing variants cannot be removed. Only the product owner can manage options.
+// @Tags Products
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Product ID"
+// @Param request body domain.SetProductOptionsRequest true "Product options"
+// @Success 200 {object} response.Response{data=[]domain.ProductOption} "Product options updated successfully"
+// @Failure 400 {object} response.Response "Bad request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 409 {object} response.Response "Options still used by variants"
+// @Router /products/{id}/options [put]
+func (h *ProductVariantHandler) SetOptions(c *fiber.Ctx) error {
+	productID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid product ID")
+	}
+
+	var req domain.SetProductOptionsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		return response.BadRequest(c, "Validation failed: "+err.Error())
+	}
+
+	userID := middleware.GetUserID(c)
+	options, err := h.productVariantUsecase.SetOptions(userID, productID, &req)
+	if err != nil {
+		return variantError(c, err)
+	}
+
+	return response.Success(c, "Product options updated successfully", options)
+}
+
+// CreateVariant godoc
+// @Summary Create product variant (Seller only)
+// @Description Add a variant SKU to a product. `attributes` picks one value of every option, e.g. `{"Ukuran": "M", "Warna": "Merah"}`. Price and weight fall back to the product when left out; stock is kept per variant. `id_foto` links one of the product's photos. Only the product owner can manage variants.
+// @Tags Products
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Product ID"
+// @Param request body domain.CreateProductVariantRequest true "Variant"
+// @Success 201 {object} response.Response{data=domain.ProductVariant} "Variant created successfully"
+// @Failure 400 {object} response.Response "Bad request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 409 {object} response.Response "SKU or attribute combination already exists"
+// @Router /products/{id}/variants [post]
+func (h *ProductVariantHandler) CreateVariant(c *fiber.Ctx) error {
+	productID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid product ID")
+	}
+
+	var req domain.CreateProductVariantRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		return response.BadRequest(c, "Validation failed: "+err.Error())
+	}
+
+	userID := middleware.GetUserID(c)
+	variant, err := h.productVariantUsecase.CreateVariant(userID, productID, &req)
+	if err != nil {
+		return variantError(c, err)
+	}
+
+	return response.Created(c, "Variant created successfully", variant)
+}
+
+// UpdateVariant godoc
+// @Summary Update product variant (Seller only)
+// @Description Update the SKU, price and weight overrides, stock, photo or status of a variant. Only the product owner can manage variants.
+// @Tags Products
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Product ID"
+// @Param variantId path int true "Variant ID"
+// @Param request body domain.UpdateProductVariantRequest true "Variant update"
+// @Success 200 {object} response.Response{data=domain.ProductVariant} "Variant updated successfully"
+// @Failure 400 {object} response.Response "Bad request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 404 {object} response.Response "Variant not found"
+// @Failure 409 {object} response.Response "SKU already exists"
+// @Router /products/{id}/variants/{variantId} [put]
+func (h *ProductVariantHandler) UpdateVariant(c *fiber.Ctx) error {
+	productID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid product ID")
+	}
+
+	variantID, err := strconv.ParseUint(c.Params("variantId"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid variant ID")
+	}
+
+	var req domain.UpdateProductVariantRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		return response.BadRequest(c, "Validation failed: "+err.Error())
+	}
+
+	userID := middleware.GetUserID(c)
+	variant, err := h.productVariantUsecase.UpdateVariant(userID, productID, variantID, &req)
+	if err != nil {
+		return variantError(c, err)
+	}
+
+	return response.Success(c, "Variant updated successfully", variant)
+}
+
+// DeleteVariant godoc
+// @Summary Delete product variant (Seller only)
+// @Description Remove a variant from sale. Only the product owner can manage variants.
+// @Tags Products
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Product ID"
+// @Param variantId path int true "Variant ID"
+// @Success 200 {object} response.Response "Variant deleted successfully"
+// @Failure 400 {object} response.Response "Bad request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 404 {object} response.Response "Variant not found"
+// @Router /products/{id}/variants/{variantId} [delete]
+func (h *ProductVariantHandler) DeleteVariant(c *fiber.Ctx) error {
+	productID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid product ID")
+	}
+
+	variantID, err := strconv.ParseUint(c.Params("variantId"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid variant ID")
+	}
+
+	userID := middleware.GetUserID(c)
+	if err := h.productVariantUsecase.DeleteVariant(userID, productID, variantID); err != nil {
+		return variantError(c, err)
+	}
+
+	return response.Success(c, "Variant deleted successfully", nil)
+}
+
+func variantError(c *fiber.Ctx, err error) error {
+	switch err.Error() {
+	case "variant not found":
+		return response.NotFound(c, err.Error())
+	case "SKU_ALREADY_EXISTS", "VARIANT_ALREADY_EXISTS", "OPTIONS_IN_USE":
+		return response.Conflict(c, err.Error())
+	}
+	return response.BadRequest(c, err.Error())
+}
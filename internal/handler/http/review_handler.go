@@ -0,0 +1,288 @@
+package http
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"go-commerce/internal/domain"
+	"go-commerce/internal/handler/middleware"
+	"go-commerce/internal/handler/response"
+	"go-commerce/internal/usecase"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ReviewHandler struct {
+	reviewUsecase *usecase.ReviewUsecase
+	validator     *validator.Validate
+}
+
+func NewReviewHandler(reviewUsecase *usecase.ReviewUsecase) *ReviewHandler {
+	return &ReviewHandler{
+		reviewUsecase: reviewUsecase,
+		validator:     validator.New(),
+	}
+}
+
+// CreateReview godoc
+// @Summary Review a purchased item (Buyer)
+// @Description Buyer reviews an item of their order once the order is delivered or done. Each order item can be reviewed once. Send the rating (1-5), an optional comment and up to 5 photos (JPG, JPEG, PNG, max 5MB each) as multipart form data. The rating counts towards the rating of the product and its store.
+// @Tags Reviews
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param transaction_item_id formData int true "Order item ID"
+// @Param rating formData int true "Rating from 1 to 5"
+// @Param comment formData string false "Review text"
+// @Param photos formData file false "Review photos"
+// @Success 201 {object} response.Response{data=domain.Review} "Review created successfully"
+// @Failure 400 {object} response.Response "Bad request - validation failed or order not delivered yet"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Order item not found"
+// @Failure 409 {object} response.Response "Item already reviewed"
+// @Router /reviews [post]
+func (h *ReviewHandler) CreateReview(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+
+	var req domain.CreateReviewRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid form data")
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		return response.BadRequest(c, "Validation failed: "+err.Error())
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		return response.BadRequest(c, "Invalid form data")
+	}
+	files := form.File["photos"]
+	if len(files) > domain.MaxReviewPhotos {
+		return response.BadRequest(c, fmt.Sprintf("Too many photos. Maximum %d allowed", domain.MaxReviewPhotos))
+	}
+
+	for _, file := range files {
+		if !isValidImageType(file.Header.Get("Content-Type")) {
+			return response.BadRequest(c, "Invalid file type. Only JPG, JPEG, PNG allowed")
+		}
+		if file.Size > 5*1024*1024 {
+			return response.BadRequest(c, "File size too large. Maximum 5MB allowed")
+		}
+	}
+
+	// Create upload directory if not exists
+	uploadDir := "uploads/reviews"
+	if err := os.MkdirAll(uploadDir, 0755); err != nil {
+		return response.InternalServerError(c, "Failed to create upload directory")
+	}
+
+	var filePaths []string
+	var photoURLs []string
+	removeUploads := func() {
+		for _, filePath := range filePaths {
+			os.Remove(filePath)
+		}
+	}
+	for _, file := range files {
+		filename := generateFileName(file.Filename)
+		filePath := filepath.Join(uploadDir, filename)
+		if err := c.SaveFile(file, filePath); err != nil {
+			removeUploads()
+			return response.InternalServerError(c, "Failed to save file")
+		}
+		filePaths = append(filePaths, filePath)
+		photoURLs = append(photoURLs, fmt.Sprintf("/uploads/reviews/%s", filename))
+	}
+
+	review, err := h.reviewUsecase.CreateReview(userID, &req, photoURLs)
+	if err != nil {
+		// Delete uploaded files if the review was not created
+		removeUploads()
+		switch err.Error() {
+		case "transaction item not found":
+			return response.NotFound(c, err.Error())
+		case "forbidden":
+			return response.Forbidden(c, err.Error())
+		case "REVIEW_ALREADY_EXISTS":
+			return response.Conflict(c, err.Error())
+		}
+		return response.BadRequest(c, err.Error())
+	}
+
+	return response.Created(c, "Review created successfully", review)
+}
+
+// GetProductReviews godoc
+// @Summary Get product reviews (Public)
+// @Description Get the visible reviews of a product with their photos and seller replies, newest first. This is a public endpoint accessible to everyone.
+// @Tags Reviews
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(10)
+// @Success 200 {object} response.PaginatedResponse{data=[]domain.Review} "Reviews retrieved successfully"
+// @Failure 400 {object} response.Response "Invalid product ID"
+// @Failure 404 {object} response.Response "Product not found"
+// @Router /products/{id}/reviews [get]
+func (h *ReviewHandler) GetProductReviews(c *fiber.Ctx) error {
+	productID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid product ID")
+	}
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	reviews, total, err := h.reviewUsecase.GetProductReviews(productID, page, limit)
+	if err != nil {
+		if err.Error() == "product not found" {
+			return response.NotFound(c, err.Error())
+		}
+		return response.InternalServerError(c, err.Error())
+	}
+
+	return response.Paginated(c, "Reviews retrieved successfully", reviews, response.PaginationMeta{
+		Page:      page,
+		Limit:     limit,
+		Total:     total,
+		TotalPage: (int(total) + limit - 1) / limit,
+	})
+}
+
+// ReplyReview godoc
+// @Summary Reply to a review (Seller)
+// @Description Seller replies to a review of their store. A review can be replied to once.
+// @Tags Reviews
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Review ID"
+// @Param request body domain.ReplyReviewRequest true "Reply"
+// @Success 200 {object} response.Response{data=domain.Review} "Review replied successfully"
+// @Failure 400 {object} response.Response "Bad request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Review or store not found"
+// @Failure 409 {object} response.Response "Review already replied"
+// @Router /reviews/{id}/reply [put]
+func (h *ReviewHandler) ReplyReview(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+
+	reviewID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid review ID")
+	}
+
+	var req domain.ReplyReviewRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		return response.BadRequest(c, "Validation failed: "+err.Error())
+	}
+
+	review, err := h.reviewUsecase.ReplyReview(userID, reviewID, &req)
+	if err != nil {
+		switch err.Error() {
+		case "review not found", "store not found":
+			return response.NotFound(c, err.Error())
+		case "forbidden":
+			return response.Forbidden(c, err.Error())
+		case "REVIEW_ALREADY_REPLIED":
+			return response.Conflict(c, err.Error())
+		}
+		return response.BadRequest(c, err.Error())
+	}
+
+	return response.Success(c, "Review replied successfully", review)
+}
+
+// HideReview godoc
+// @Summary Hide review (Admin)
+// @Description Admin hides an abusive review. Hidden reviews are not listed and do not count towards product and store ratings. Admin access required.
+// @Tags Reviews - Admin Operations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Review ID"
+// @Param request body domain.HideReviewRequest false "Reason"
+// @Success 200 {object} response.Response{data=domain.Review} "Review hidden successfully"
+// @Failure 400 {object} response.Response "Bad request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Review not found"
+// @Failure 409 {object} response.Response "Review already hidden"
+// @Router /admin/reviews/{id}/hide [put]
+func (h *ReviewHandler) HideReview(c *fiber.Ctx) error {
+	adminID := middleware.GetUserID(c)
+
+	reviewID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid review ID")
+	}
+
+	var req domain.HideReviewRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return response.BadRequest(c, "Invalid request body")
+		}
+		if err := h.validator.Struct(&req); err != nil {
+			return response.BadRequest(c, "Validation failed: "+err.Error())
+		}
+	}
+
+	review, err := h.reviewUsecase.HideReview(adminID, reviewID, &req)
+	if err != nil {
+		return reviewModerationError(c, err)
+	}
+
+	return response.Success(c, "Review hidden successfully", review)
+}
+
+// UnhideReview godoc
+// @Summary Unhide review (Admin)
+// @Description Admin shows a hidden review again; it counts towards the ratings again. Admin access required.
+// @Tags Reviews - Admin Operations
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Review ID"
+// @Success 200 {object} response.Response{data=domain.Review} "Review unhidden successfully"
+// @Failure 400 {object} response.Response "Invalid review ID"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Review not found"
+// @Failure 409 {object} response.Response "Review not hidden"
+// @Router /admin/reviews/{id}/unhide [put]
+func (h *ReviewHandler) UnhideReview(c *fiber.Ctx) error {
+	reviewID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid review ID")
+	}
+
+	review, err := h.reviewUsecase.UnhideReview(reviewID)
+	if err != nil {
+		return reviewModerationError(c, err)
+	}
+
+	return response.Success(c, "Review unhidden successfully", review)
+}
+
+func reviewModerationError(c *fiber.Ctx, err error) error {
+	switch err.Error() {
+	case "review not found":
+		return response.NotFound(c, err.Error())
+	case "REVIEW_ALREADY_HIDDEN", "REVIEW_NOT_HIDDEN":
+		return response.Conflict(c, err.Error())
+	}
+	return response.InternalServerError(c, err.Error())
+}
@@ -0,0 +1,149 @@
+package http
+
+import (
+	"strconv"
+
+	"go-commerce/internal/domain"
+	"go-commerce/internal/handler/middleware"
+	"go-commerce/internal/handler/response"
+	"go-commerce/internal/usecase"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type RoleHandler struct {
+	roleUsecase *usecase.RoleUsecase
+	validator   *validator.Validate
+}
+
+func NewRoleHandler(roleUsecase *usecase.RoleUsecase) *RoleHandler {
+	return &RoleHandler{
+		roleUsecase: roleUsecase,
+		validator:   validator.New(),
+	}
+}
+
+// GetAllRoles godoc
+// @Summary List roles (Admin only)
+// @Description List every role with the permissions it grants. Requires the roles:manage permission.
+// @Tags Roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]domain.Role} "Roles retrieved successfully"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden - permission required"
+// @Router /admin/roles [get]
+func (h *RoleHandler) GetAllRoles(c *fiber.Ctx) error {
+	roles, err := h.roleUsecase.GetAllRoles()
+	if err != nil {
+		return response.InternalServerError(c, err.Error())
+	}
+
+	return response.Success(c, "Roles retrieved successfully", roles)
+}
+
+// GetUserRoles godoc
+// @Summary List roles of a user (Admin only)
+// @Description List the roles assigned to a user. Requires the roles:manage permission.
+// @Tags Roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} response.Response{data=[]domain.Role} "User roles retrieved successfully"
+// @Failure 400 {object} response.Response "Bad request"
+// @Failure 403 {object} response.Response "Forbidden - permission required"
+// @Failure 404 {object} response.Response "User not found"
+// @Router /admin/users/{id}/roles [get]
+func (h *RoleHandler) GetUserRoles(c *fiber.Ctx) error {
+	userID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid user ID")
+	}
+
+	roles, err := h.roleUsecase.GetUserRoles(userID)
+	if err != nil {
+		if err.Error() == "user not found" {
+			return response.NotFound(c, err.Error())
+		}
+		return response.InternalServerError(c, err.Error())
+	}
+
+	return response.Success(c, "User roles retrieved successfully", roles)
+}
+
+// AssignRole godoc
+// @Summary Assign a role to a user (Admin only)
+// @Description Grant a role to a user. The user's access tokens are revoked so refreshed tokens carry the new role. Requires the roles:manage permission.
+// @Tags Roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param request body domain.AssignRoleRequest true "Role to assign"
+// @Success 200 {object} response.Response "Role assigned successfully"
+// @Failure 400 {object} response.Response "Bad request"
+// @Failure 403 {object} response.Response "Forbidden - permission required"
+// @Failure 404 {object} response.Response "User or role not found"
+// @Router /admin/users/{id}/roles [post]
+func (h *RoleHandler) AssignRole(c *fiber.Ctx) error {
+	userID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid user ID")
+	}
+
+	var req domain.AssignRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return response.BadRequest(c, "Validation failed: "+err.Error())
+	}
+
+	if err := h.roleUsecase.AssignRole(userID, &req); err != nil {
+		if err.Error() == "user not found" || err.Error() == "role not found" {
+			return response.NotFound(c, err.Error())
+		}
+		return response.BadRequest(c, err.Error())
+	}
+
+	return response.Success(c, "Role assigned successfully", nil)
+}
+
+// RevokeRole godoc
+// @Summary Revoke a role from a user (Admin only)
+// @Description Remove a role from a user. Admins cannot revoke their own roles. Requires the roles:manage permission.
+// @Tags Roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param role path string true "Role name"
+// @Success 200 {object} response.Response "Role revoked successfully"
+// @Failure 400 {object} response.Response "Bad request"
+// @Failure 403 {object} response.Response "Forbidden - permission required"
+// @Failure 404 {object} response.Response "User or role not found"
+// @Failure 409 {object} response.Response "Conflict - role not assigned"
+// @Router /admin/users/{id}/roles/{role} [delete]
+func (h *RoleHandler) RevokeRole(c *fiber.Ctx) error {
+	userID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid user ID")
+	}
+
+	actorID := middleware.GetUserID(c)
+	if err := h.roleUsecase.RevokeRole(actorID, userID, c.Params("role")); err != nil {
+		switch err.Error() {
+		case "user not found", "role not found":
+			return response.NotFound(c, err.Error())
+		case "ROLE_NOT_ASSIGNED":
+			return response.Conflict(c, err.Error())
+		}
+		return response.BadRequest(c, err.Error())
+	}
+
+	return response.Success(c, "Role revoked successfully", nil)
+}
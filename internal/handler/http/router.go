@@ -5,24 +5,39 @@ import (
 	"go-commerce/internal/handler/middleware"
 	"go-commerce/internal/usecase"
 	"go-commerce/pkg/jwt"
+	"go-commerce/pkg/pagination"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 type Router struct {
-	app        *fiber.App
-	jwtManager *jwt.JWTManager
+	app             *fiber.App
+	jwtManager      *jwt.JWTManager
+	revocationStore domain.TokenRevocationStore
+	cursors         *pagination.Signer
 }
 
-func NewRouter(app *fiber.App, jwtManager *jwt.JWTManager) *Router {
+func NewRouter(app *fiber.App, jwtManager *jwt.JWTManager, revocationStore domain.TokenRevocationStore, cursors *pagination.Signer) *Router {
 	return &Router{
-		app:        app,
-		jwtManager: jwtManager,
+		app:             app,
+		jwtManager:      jwtManager,
+		revocationStore: revocationStore,
+		cursors:         cursors,
 	}
 }
 
-func (r *Router) SetupAuthRoutes(authUsecase *usecase.AuthUsecase) {
+func (r *Router) SetupWellKnownRoutes() {
+	jwksHandler := NewJWKSHandler(r.jwtManager)
+
+	// Served outside /api/v1 where JWKS consumers expect it
+	r.app.Get("/.well-known/jwks.json", jwksHandler.GetJWKS)
+}
+
+func (r *Router) SetupAuthRoutes(authUsecase *usecase.AuthUsecase, emailVerificationUsecase *usecase.EmailVerificationUsecase, passwordResetUsecase *usecase.PasswordResetUsecase, loginThrottleUsecase *usecase.LoginThrottleUsecase) {
 	authHandler := NewAuthHandler(authUsecase)
+	emailVerificationHandler := NewEmailVerificationHandler(emailVerificationUsecase)
+	passwordResetHandler := NewPasswordResetHandler(passwordResetUsecase)
+	loginThrottleHandler := NewLoginThrottleHandler(loginThrottleUsecase)
 	
 	api := r.app.Group("/api/v1")
 	auth := api.Group("/auth")
@@ -30,9 +45,42 @@ func (r *Router) SetupAuthRoutes(authUsecase *usecase.AuthUsecase) {
 	// Public routes
 	auth.Post("/register", authHandler.Register)
 	auth.Post("/login", authHandler.Login)
+	auth.Post("/refresh", authHandler.RefreshToken)
+	auth.Post("/verify-email", emailVerificationHandler.VerifyEmail)
+	auth.Post("/forgot-password", passwordResetHandler.ForgotPassword)
+	auth.Post("/reset-password", passwordResetHandler.ResetPassword)
 
 	// Protected routes
-	auth.Post("/logout", middleware.JWTMiddleware(r.jwtManager), authHandler.Logout)
+	auth.Post("/logout", middleware.JWTMiddleware(r.jwtManager, r.revocationStore), authHandler.Logout)
+	auth.Post("/logout-all", middleware.JWTMiddleware(r.jwtManager, r.revocationStore), authHandler.LogoutAll)
+	auth.Post("/verify-email/resend", middleware.JWTMiddleware(r.jwtManager, r.revocationStore), emailVerificationHandler.ResendVerificationEmail)
+
+	// Admin routes
+	admin := api.Group("/admin")
+	admin.Put("/users/:id/unlock", middleware.JWTMiddleware(r.jwtManager, r.revocationStore), middleware.RequirePermission(domain.PermissionUsersUnlock), loginThrottleHandler.UnlockAccount)
+}
+
+func (r *Router) SetupTwoFactorRoutes(authUsecase *usecase.AuthUsecase, twoFactorUsecase *usecase.TwoFactorUsecase) {
+	twoFactorHandler := NewTwoFactorHandler(authUsecase, twoFactorUsecase)
+
+	api := r.app.Group("/api/v1")
+	twoFactor := api.Group("/auth/2fa")
+
+	// Public route, second step of a login
+	twoFactor.Post("/verify", twoFactorHandler.VerifyLogin)
+
+	// Protected routes
+	jwtMiddleware := middleware.JWTMiddleware(r.jwtManager, r.revocationStore)
+	twoFactor.Post("/enroll", jwtMiddleware, twoFactorHandler.Enroll)
+	twoFactor.Post("/confirm", jwtMiddleware, twoFactorHandler.Confirm)
+	twoFactor.Post("/disable", jwtMiddleware, twoFactorHandler.Disable)
+	twoFactor.Post("/recovery-codes", jwtMiddleware, twoFactorHandler.RegenerateRecoveryCodes)
+
+	// Admin routes
+	admin := api.Group("/admin")
+	requireAdmin := middleware.RequirePermission(domain.PermissionSecurityManage)
+	admin.Get("/security/two-factor", jwtMiddleware, requireAdmin, twoFactorHandler.GetPolicy)
+	admin.Put("/security/two-factor", jwtMiddleware, requireAdmin, twoFactorHandler.UpdatePolicy)
 }
 
 func (r *Router) SetupUserRoutes(userUsecase *usecase.UserUsecase) {
@@ -42,15 +90,15 @@ func (r *Router) SetupUserRoutes(userUsecase *usecase.UserUsecase) {
 	users := api.Group("/users")
 
 	// Protected routes
-	users.Get("/my", middleware.JWTMiddleware(r.jwtManager), userHandler.GetProfile)
-	users.Get("/profile", middleware.JWTMiddleware(r.jwtManager), userHandler.GetProfile)
-	users.Put("/my", middleware.JWTMiddleware(r.jwtManager), userHandler.UpdateProfile)
-	users.Put("/profile", middleware.JWTMiddleware(r.jwtManager), userHandler.UpdateProfile)
-	users.Put("/my/password", middleware.JWTMiddleware(r.jwtManager), userHandler.ChangePassword)
+	users.Get("/my", middleware.JWTMiddleware(r.jwtManager, r.revocationStore), userHandler.GetProfile)
+	users.Get("/profile", middleware.JWTMiddleware(r.jwtManager, r.revocationStore), userHandler.GetProfile)
+	users.Put("/my", middleware.JWTMiddleware(r.jwtManager, r.revocationStore), userHandler.UpdateProfile)
+	users.Put("/profile", middleware.JWTMiddleware(r.jwtManager, r.revocationStore), userHandler.UpdateProfile)
+	users.Put("/my/password", middleware.JWTMiddleware(r.jwtManager, r.revocationStore), userHandler.ChangePassword)
 }
 
 func (r *Router) SetupStoreRoutes(storeUsecase *usecase.StoreUsecase) {
-	storeHandler := NewStoreHandler(storeUsecase)
+	storeHandler := NewStoreHandler(storeUsecase, r.cursors)
 	
 	api := r.app.Group("/api/v1")
 	stores := api.Group("/stores")
@@ -59,7 +107,7 @@ func (r *Router) SetupStoreRoutes(storeUsecase *usecase.StoreUsecase) {
 	stores.Get("/", storeHandler.GetAllStores)
 
 	// Protected routes
-	jwtMiddleware := middleware.JWTMiddleware(r.jwtManager)
+	jwtMiddleware := middleware.JWTMiddleware(r.jwtManager, r.revocationStore)
 	stores.Post("/", jwtMiddleware, storeHandler.CreateStore)
 	stores.Get("/my", jwtMiddleware, storeHandler.GetMyStore)
 	stores.Put("/my", jwtMiddleware, storeHandler.UpdateMyStore)
@@ -73,8 +121,8 @@ func (r *Router) SetupStoreRoutes(storeUsecase *usecase.StoreUsecase) {
 
 	// Admin routes - COMMENTED: Pending approval logic disabled
 	admin := api.Group("/admin")
-	adminMiddleware := middleware.JWTMiddleware(r.jwtManager)
-	requireAdmin := middleware.RequireAdmin()
+	adminMiddleware := middleware.JWTMiddleware(r.jwtManager, r.revocationStore)
+	requireAdmin := middleware.RequirePermission(domain.PermissionStoresSuspend)
 	// admin.Get("/stores/pending", adminMiddleware, requireAdmin, storeHandler.GetPendingStores)
 	// admin.Put("/stores/:id/approve", adminMiddleware, requireAdmin, storeHandler.ApproveStore)
 	// admin.Put("/stores/:id/reject", adminMiddleware, requireAdmin, storeHandler.RejectStore)
@@ -93,8 +141,8 @@ func (r *Router) SetupCategoryRoutes(categoryUsecase *usecase.CategoryUsecase) {
 	categories.Get("/:id", categoryHandler.GetCategoryByID)
 
 	// Admin only routes
-	adminMiddleware := middleware.JWTMiddleware(r.jwtManager)
-	requireAdmin := middleware.RequireAdmin()
+	adminMiddleware := middleware.JWTMiddleware(r.jwtManager, r.revocationStore)
+	requireAdmin := middleware.RequirePermission(domain.PermissionCategoriesManage)
 	categories.Post("/", adminMiddleware, requireAdmin, categoryHandler.CreateCategory)
 	categories.Put("/:id", adminMiddleware, requireAdmin, categoryHandler.UpdateCategory)
 	categories.Put("/:id/activate", adminMiddleware, requireAdmin, categoryHandler.ActivateCategory)
@@ -109,7 +157,7 @@ func (r *Router) SetupAddressRoutes(addressUsecase *usecase.AddressUsecase) {
 	addresses := api.Group("/addresses")
 
 	// Protected routes (user can only manage their own addresses)
-	jwtMiddleware := middleware.JWTMiddleware(r.jwtManager)
+	jwtMiddleware := middleware.JWTMiddleware(r.jwtManager, r.revocationStore)
 	addresses.Get("/", jwtMiddleware, addressHandler.GetMyAddresses)
 	addresses.Get("/default", jwtMiddleware, addressHandler.GetDefaultAddress)
 	addresses.Post("/", jwtMiddleware, addressHandler.CreateAddress)
@@ -124,20 +172,21 @@ func (r *Router) SetupAddressRoutes(addressUsecase *usecase.AddressUsecase) {
 	regions.Get("/provinces/:provinceId/cities", addressHandler.GetCitiesByProvince)
 }
 
-func (r *Router) SetupProductRoutes(productUsecase *usecase.ProductUsecase) {
-	productHandler := NewProductHandler(productUsecase)
+func (r *Router) SetupProductRoutes(productUsecase *usecase.ProductUsecase, productVariantUsecase *usecase.ProductVariantUsecase) {
+	productHandler := NewProductHandler(productUsecase, r.cursors)
+	productVariantHandler := NewProductVariantHandler(productVariantUsecase)
 	
 	api := r.app.Group("/api/v1")
 	products := api.Group("/products")
 
 	// Public routes
 	products.Get("/", productHandler.GetAllProducts)
-	products.Get("/status", middleware.JWTMiddleware(r.jwtManager), middleware.RequireAdmin(), productHandler.GetProductsByStatus)
+	products.Get("/status", middleware.JWTMiddleware(r.jwtManager, r.revocationStore), middleware.RequirePermission(domain.PermissionProductsModerate), productHandler.GetProductsByStatus)
 	products.Get("/search/slug", productHandler.SearchProductsBySlug)
 	products.Get("/slug/:slug", productHandler.GetProductBySlug)
 
 	// Protected routes (store owner only)
-	jwtMiddleware := middleware.JWTMiddleware(r.jwtManager)
+	jwtMiddleware := middleware.JWTMiddleware(r.jwtManager, r.revocationStore)
 	products.Get("/my", jwtMiddleware, productHandler.GetMyProducts)
 	products.Post("/", jwtMiddleware, productHandler.CreateProduct)
 	products.Put("/:id", jwtMiddleware, productHandler.UpdateProduct)
@@ -148,6 +197,12 @@ func (r *Router) SetupProductRoutes(productUsecase *usecase.ProductUsecase) {
 	products.Put("/:id/photos/:photoId/primary", jwtMiddleware, productHandler.SetPrimaryPhoto)
 	products.Delete("/:id/photos/:photoId", jwtMiddleware, productHandler.DeleteProductPhoto)
 
+	// Option and variant management routes
+	products.Put("/:id/options", jwtMiddleware, productVariantHandler.SetOptions)
+	products.Post("/:id/variants", jwtMiddleware, productVariantHandler.CreateVariant)
+	products.Put("/:id/variants/:variantId", jwtMiddleware, productVariantHandler.UpdateVariant)
+	products.Delete("/:id/variants/:variantId", jwtMiddleware, productVariantHandler.DeleteVariant)
+
 	// Product status management (seller only)
 	products.Put("/:id/activate", jwtMiddleware, productHandler.ActivateProduct)
 	products.Put("/:id/deactivate", jwtMiddleware, productHandler.DeactivateProduct)
@@ -157,58 +212,161 @@ func (r *Router) SetupProductRoutes(productUsecase *usecase.ProductUsecase) {
 
 	// Admin routes
 	admin := api.Group("/admin")
-	adminMiddleware := middleware.JWTMiddleware(r.jwtManager)
-	requireAdmin := middleware.RequireAdmin()
+	adminMiddleware := middleware.JWTMiddleware(r.jwtManager, r.revocationStore)
+	requireAdmin := middleware.RequirePermission(domain.PermissionProductsModerate)
 	admin.Put("/products/:id/suspend", adminMiddleware, requireAdmin, productHandler.SuspendProduct)
 	admin.Put("/products/:id/unsuspend", adminMiddleware, requireAdmin, productHandler.UnsuspendProduct)
 }
 
-func (r *Router) SetupTransactionRoutes(transactionUsecase *usecase.TransactionUsecase, paymentIntentUsecase domain.PaymentIntentUsecase) {
-	transactionHandler := NewTransactionHandler(transactionUsecase, paymentIntentUsecase)
+func (r *Router) SetupSearchRoutes(searchSuggestUsecase *usecase.SearchSuggestUsecase) {
+	searchHandler := NewSearchHandler(searchSuggestUsecase)
+
+	api := r.app.Group("/api/v1")
+	search := api.Group("/search")
+
+	// Public routes
+	search.Get("/suggest", searchHandler.Suggest)
+}
+
+func (r *Router) SetupTransactionRoutes(transactionUsecase *usecase.TransactionUsecase, paymentIntentUsecase domain.PaymentIntentUsecase, refundUsecase *usecase.RefundUsecase, shipmentUsecase *usecase.ShipmentUsecase, orderCompletionUsecase *usecase.OrderCompletionUsecase) {
+	transactionHandler := NewTransactionHandler(transactionUsecase, paymentIntentUsecase, refundUsecase, shipmentUsecase, orderCompletionUsecase, r.cursors)
+	disputeHandler := NewDisputeHandler(orderCompletionUsecase)
 	
 	api := r.app.Group("/api/v1")
 	transactions := api.Group("/transactions")
 
 	// Protected routes - Buyer operations
-	jwtMiddleware := middleware.JWTMiddleware(r.jwtManager)
+	jwtMiddleware := middleware.JWTMiddleware(r.jwtManager, r.revocationStore)
 	transactions.Post("/", jwtMiddleware, transactionHandler.CreateTransaction)
 	transactions.Get("/my", jwtMiddleware, transactionHandler.GetMyTransactions)
 	transactions.Put("/:id/confirm-delivery", jwtMiddleware, transactionHandler.ConfirmDelivered)
 	transactions.Put("/:id/cancel", jwtMiddleware, transactionHandler.CancelTransaction)
+	transactions.Post("/:id/dispute", jwtMiddleware, disputeHandler.OpenDispute)
+	transactions.Get("/:id/dispute", jwtMiddleware, disputeHandler.GetDispute)
+	transactions.Get("/:id/timeline", jwtMiddleware, transactionHandler.GetTransactionTimeline)
+	transactions.Get("/:id/shipment", jwtMiddleware, transactionHandler.GetShipment)
+
+	// Transaction by invoice code; codes contain slashes
+	transactions.Get("/invoice/*", jwtMiddleware, transactionHandler.GetTransactionByInvoice)
 
 	// Transaction by ID (must be after /my routes)
 	transactions.Get("/:id", jwtMiddleware, transactionHandler.GetTransactionByID)
 
-	// Seller operations
+	// Checkout with its per-store orders
+	checkouts := api.Group("/checkouts")
+	checkouts.Get("/:id", jwtMiddleware, transactionHandler.GetCheckoutByID)
+
+	// Seller operations - only on orders of the seller's own store
 	seller := api.Group("/seller")
+	seller.Get("/transactions", jwtMiddleware, transactionHandler.GetSellerTransactions)
+	seller.Get("/transactions/:id", jwtMiddleware, transactionHandler.GetSellerTransactionByID)
 	seller.Put("/transactions/:id/process", jwtMiddleware, transactionHandler.ProcessOrder)
 	seller.Put("/transactions/:id/ship", jwtMiddleware, transactionHandler.ShipOrder)
 
 	// Admin operations
 	admin := api.Group("/admin")
-	adminMiddleware := middleware.JWTMiddleware(r.jwtManager)
-	requireAdmin := middleware.RequireAdmin()
+	adminMiddleware := middleware.JWTMiddleware(r.jwtManager, r.revocationStore)
+	requireAdmin := middleware.RequirePermission(domain.PermissionTransactionsRefund)
 	admin.Put("/transactions/:id/refund", adminMiddleware, requireAdmin, transactionHandler.RefundTransaction)
+	admin.Get("/transactions/:id/refunds", adminMiddleware, requireAdmin, transactionHandler.GetTransactionRefunds)
+	admin.Post("/refunds/:id/retry", adminMiddleware, requireAdmin, transactionHandler.RetryRefund)
+
+	// Dispute resolution
+	requireDisputes := middleware.RequirePermission(domain.PermissionDisputesResolve)
+	admin.Get("/disputes", adminMiddleware, requireDisputes, disputeHandler.ListDisputes)
+	admin.Get("/disputes/:id", adminMiddleware, requireDisputes, disputeHandler.GetDisputeByID)
+	admin.Put("/disputes/:id/resolve", adminMiddleware, requireDisputes, disputeHandler.ResolveDispute)
 }
 
-func (r *Router) SetupPaymentIntentRoutes(paymentIntentUsecase domain.PaymentIntentUsecase) {
-	paymentIntentHandler := NewPaymentIntentHandler(paymentIntentUsecase)
+func (r *Router) SetupReviewRoutes(reviewUsecase *usecase.ReviewUsecase) {
+	reviewHandler := NewReviewHandler(reviewUsecase)
+
+	api := r.app.Group("/api/v1")
+	reviews := api.Group("/reviews")
+
+	// Public routes
+	api.Get("/products/:id/reviews", reviewHandler.GetProductReviews)
+
+	// Protected routes - buyers review, sellers reply
+	jwtMiddleware := middleware.JWTMiddleware(r.jwtManager, r.revocationStore)
+	reviews.Post("/", jwtMiddleware, reviewHandler.CreateReview)
+	reviews.Put("/:id/reply", jwtMiddleware, reviewHandler.ReplyReview)
+
+	// Admin routes
+	admin := api.Group("/admin")
+	adminMiddleware := middleware.JWTMiddleware(r.jwtManager, r.revocationStore)
+	requireAdmin := middleware.RequirePermission(domain.PermissionReviewsModerate)
+	admin.Put("/reviews/:id/hide", adminMiddleware, requireAdmin, reviewHandler.HideReview)
+	admin.Put("/reviews/:id/unhide", adminMiddleware, requireAdmin, reviewHandler.UnhideReview)
+}
+
+func (r *Router) SetupPaymentIntentRoutes(paymentIntentUsecase domain.PaymentIntentUsecase, paymentCallbackUsecase *usecase.PaymentCallbackUsecase) {
+	paymentIntentHandler := NewPaymentIntentHandler(paymentIntentUsecase, paymentCallbackUsecase)
 	
 	api := r.app.Group("/api/v1")
+	checkouts := api.Group("/checkouts")
+
+	// Payment intent creation (buyer) - one payment per checkout
+	jwtMiddleware := middleware.JWTMiddleware(r.jwtManager, r.revocationStore)
+	checkouts.Post("/:id/pay", jwtMiddleware, paymentIntentHandler.CreatePaymentIntent)
+
+	// Per-order payment kept for older clients; pays the order's checkout
 	transactions := api.Group("/transactions")
+	transactions.Post("/:id/pay", jwtMiddleware, paymentIntentHandler.CreatePaymentIntentForOrder)
 
-	// Payment intent creation (buyer)
-	jwtMiddleware := middleware.JWTMiddleware(r.jwtManager)
-	transactions.Post("/:id/pay", jwtMiddleware, paymentIntentHandler.CreatePaymentIntent)
+	payments := api.Group("/payments")
+	payments.Get("/:intentId", jwtMiddleware, paymentIntentHandler.GetPaymentIntent)
 
 	// Admin payment simulation endpoints
 	admin := api.Group("/admin")
-	adminMiddleware := middleware.JWTMiddleware(r.jwtManager)
-	requireAdmin := middleware.RequireAdmin()
+	adminMiddleware := middleware.JWTMiddleware(r.jwtManager, r.revocationStore)
+	requireAdmin := middleware.RequirePermission(domain.PermissionPaymentsSimulate)
 	admin.Put("/payments/:intentId/simulate-success", adminMiddleware, requireAdmin, paymentIntentHandler.SimulatePaymentSuccess)
 	admin.Put("/payments/:intentId/simulate-failed", adminMiddleware, requireAdmin, paymentIntentHandler.SimulatePaymentFailed)
 
-	// Payment gateway callback (updates payment intent)
+	// Payment gateway callback (updates payment intent, HMAC signed)
 	callbacks := api.Group("/callbacks")
 	callbacks.Post("/payments/:intentId", paymentIntentHandler.OnPaymentCallback)
-}
\ No newline at end of file
+}
+
+func (r *Router) SetupRoleRoutes(roleUsecase *usecase.RoleUsecase) {
+	roleHandler := NewRoleHandler(roleUsecase)
+
+	api := r.app.Group("/api/v1")
+	admin := api.Group("/admin")
+
+	// Role management (admin with roles:manage only)
+	adminMiddleware := middleware.JWTMiddleware(r.jwtManager, r.revocationStore)
+	requireAdmin := middleware.RequirePermission(domain.PermissionRolesManage)
+	admin.Get("/roles", adminMiddleware, requireAdmin, roleHandler.GetAllRoles)
+	admin.Get("/users/:id/roles", adminMiddleware, requireAdmin, roleHandler.GetUserRoles)
+	admin.Post("/users/:id/roles", adminMiddleware, requireAdmin, roleHandler.AssignRole)
+	admin.Delete("/users/:id/roles/:role", adminMiddleware, requireAdmin, roleHandler.RevokeRole)
+}
+
+func (r *Router) SetupCartRoutes(cartUsecase *usecase.CartUsecase) {
+	cartHandler := NewCartHandler(cartUsecase)
+
+	api := r.app.Group("/api/v1")
+	cart := api.Group("/cart")
+
+	// Protected routes - cart owner only
+	jwtMiddleware := middleware.JWTMiddleware(r.jwtManager, r.revocationStore)
+	cart.Get("/", jwtMiddleware, cartHandler.GetCart)
+	cart.Delete("/", jwtMiddleware, cartHandler.ClearCart)
+	cart.Post("/items", jwtMiddleware, cartHandler.AddItem)
+	cart.Put("/items/:productId", jwtMiddleware, cartHandler.UpdateItem)
+	cart.Delete("/items/:productId", jwtMiddleware, cartHandler.RemoveItem)
+	cart.Post("/checkout", jwtMiddleware, cartHandler.Checkout)
+}
+
+func (r *Router) SetupShippingRoutes(shippingUsecase *usecase.ShippingUsecase) {
+	shippingHandler := NewShippingHandler(shippingUsecase)
+
+	api := r.app.Group("/api/v1")
+	shipping := api.Group("/shipping")
+
+	// Protected routes - quotes are for the buyer's own addresses
+	jwtMiddleware := middleware.JWTMiddleware(r.jwtManager, r.revocationStore)
+	shipping.Post("/quote", jwtMiddleware, shippingHandler.Quote)
+}
@@ -0,0 +1,42 @@
+package http
+
+import (
+	"strconv"
+	"strings"
+
+	"go-commerce/internal/handler/response"
+	"go-commerce/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type SearchHandler struct {
+	searchSuggestUsecase *usecase.SearchSuggestUsecase
+}
+
+func NewSearchHandler(searchSuggestUsecase *usecase.SearchSuggestUsecase) *SearchHandler {
+	return &SearchHandler{
+		searchSuggestUsecase: searchSuggestUsecase,
+	}
+}
+
+// Suggest godoc
+// @Summary Search suggestions (Public)
+// @Description Complete what the user is typing with popular past searches, product names, categories and active stores. Any word of a name can be completed, e.g. "flanel p" suggests "Kemeja Flanel Pria". Suggestions are ranked by how often matching searches were made and are served from memory, so they are cheap enough to request on every keystroke.
+// @Tags Search
+// @Produce json
+// @Param q query string true "What the user typed so far"
+// @Param limit query int false "Suggestions per kind, at most 10" default(5)
+// @Success 200 {object} response.Response{data=domain.SearchSuggestions} "Suggestions retrieved successfully"
+// @Failure 400 {object} response.Response "Missing query"
+// @Router /search/suggest [get]
+func (h *SearchHandler) Suggest(c *fiber.Ctx) error {
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" {
+		return response.BadRequest(c, "q is required")
+	}
+	limit, _ := strconv.Atoi(c.Query("limit", "5"))
+
+	suggestions := h.searchSuggestUsecase.Suggest(q, limit)
+	return response.Success(c, "Suggestions retrieved successfully", suggestions)
+}
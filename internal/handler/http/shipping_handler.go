@@ -0,0 +1,58 @@
+package http
+
+import (
+	"go-commerce/internal/domain"
+	"go-commerce/internal/handler/middleware"
+	"go-commerce/internal/handler/response"
+	"go-commerce/internal/usecase"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ShippingHandler struct {
+	shippingUsecase *usecase.ShippingUsecase
+	validator       *validator.Validate
+}
+
+func NewShippingHandler(shippingUsecase *usecase.ShippingUsecase) *ShippingHandler {
+	return &ShippingHandler{
+		shippingUsecase: shippingUsecase,
+		validator:       validator.New(),
+	}
+}
+
+// Quote godoc
+// @Summary Quote shipping costs (Authenticated User)
+// @Description Get the shipping options for the given items to one of my addresses. Items are grouped per store like at checkout; each store's parcel is priced from its total product weight and the store and address regions, cheapest option first. Pass the chosen courier and service per store in the `shipping` field of the checkout.
+// @Tags Shipping
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body domain.ShippingQuoteRequest true "Delivery address and items"
+// @Success 200 {object} response.Response{data=[]domain.ShippingQuote} "Shipping quote retrieved successfully"
+// @Failure 400 {object} response.Response "Bad request - validation failed, unknown address or product"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Router /shipping/quote [post]
+func (h *ShippingHandler) Quote(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		return response.Unauthorized(c, "User not authenticated")
+	}
+
+	var req domain.ShippingQuoteRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return response.BadRequest(c, "Validation failed: "+err.Error())
+	}
+
+	quotes, err := h.shippingUsecase.Quote(userID, &req)
+	if err != nil {
+		return response.BadRequest(c, err.Error())
+	}
+
+	return response.Success(c, "Shipping quote retrieved successfully", quotes)
+}
@@ -7,6 +7,7 @@ import (
 	"go-commerce/internal/handler/middleware"
 	"go-commerce/internal/handler/response"
 	"go-commerce/internal/usecase"
+	"go-commerce/pkg/pagination"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
@@ -14,12 +15,14 @@ import (
 
 type StoreHandler struct {
 	storeUsecase *usecase.StoreUsecase
+	cursors      *pagination.Signer
 	validator    *validator.Validate
 }
 
-func NewStoreHandler(storeUsecase *usecase.StoreUsecase) *StoreHandler {
+func NewStoreHandler(storeUsecase *usecase.StoreUsecase, cursors *pagination.Signer) *StoreHandler {
 	return &StoreHandler{
 		storeUsecase: storeUsecase,
+		cursors:      cursors,
 		validator:    validator.New(),
 	}
 }
@@ -86,17 +89,25 @@ func (h *StoreHandler) UpdateMyStore(c *fiber.Ctx) error {
 
 // GetAllStores godoc
 // @Summary Get all active stores (Public)
-// @Description Get all active stores with pagination and search. Only shows active stores to public.
+// @Description Get all active stores with pagination and search. Only shows active stores to public. Pages by number by default; pagination=cursor or a cursor from meta.next_cursor/meta.prev_cursor pages by cursor instead.
 // @Tags Stores
 // @Accept json
 // @Produce json
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page" default(10)
 // @Param search query string false "Search by store name"
+// @Param pagination query string false "cursor to page by cursor"
+// @Param cursor query string false "Cursor of the page to read"
+// @Param sort_by query string false "Sort by in cursor mode: newest (default), oldest, name_asc, name_desc"
 // @Success 200 {object} response.PaginatedResponse{data=[]domain.Store} "Stores retrieved successfully"
+// @Failure 400 {object} response.Response "Invalid cursor or sort order"
 // @Failure 500 {object} response.Response "Internal server error"
 // @Router /stores [get]
 func (h *StoreHandler) GetAllStores(c *fiber.Ctx) error {
+	if cursorMode(c) {
+		return h.getAllStoresByCursor(c)
+	}
+
 	page, _ := strconv.Atoi(c.Query("page", "1"))
 	limit, _ := strconv.Atoi(c.Query("limit", "10"))
 	search := c.Query("search", "")
@@ -109,6 +120,25 @@ func (h *StoreHandler) GetAllStores(c *fiber.Ctx) error {
 	return response.Paginated(c, "Stores retrieved successfully", stores, meta)
 }
 
+func (h *StoreHandler) getAllStoresByCursor(c *fiber.Ctx) error {
+	page, err := cursorPage(c, h.cursors, storeCursorScope)
+	if err != nil {
+		return response.BadRequest(c, err.Error())
+	}
+
+	stores, links, err := h.storeUsecase.GetActiveStoresByCursor(c.Query("search", ""), page)
+	if err != nil {
+		return cursorError(c, err)
+	}
+
+	meta, err := cursorMeta(h.cursors, storeCursorScope, page, links)
+	if err != nil {
+		return response.InternalServerError(c, err.Error())
+	}
+
+	return response.Paginated(c, "Stores retrieved successfully", stores, meta)
+}
+
 // GetStoreByID godoc
 // @Summary Get store by ID (Public)
 // @Description Get a single active store by its ID. Only shows active stores to public.
@@ -146,6 +176,7 @@ func (h *StoreHandler) GetStoreByID(c *fiber.Ctx) error {
 // @Success 201 {object} response.Response{data=domain.Store} "Store created successfully"
 // @Failure 400 {object} response.Response "Bad request"
 // @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden - email not verified"
 // @Router /stores [post]
 func (h *StoreHandler) CreateStore(c *fiber.Ctx) error {
 	userID := middleware.GetUserID(c)
@@ -164,6 +195,9 @@ func (h *StoreHandler) CreateStore(c *fiber.Ctx) error {
 
 	store, err := h.storeUsecase.CreateStore(userID, &req)
 	if err != nil {
+		if err.Error() == "EMAIL_NOT_VERIFIED" {
+			return response.Forbidden(c, err.Error())
+		}
 		return response.BadRequest(c, err.Error())
 	}
 
@@ -179,14 +213,14 @@ func (h *StoreHandler) CreateStore(c *fiber.Ctx) error {
 // @Success 200 {object} response.Response "Store activated successfully"
 // @Failure 400 {object} response.Response "Bad request"
 // @Failure 401 {object} response.Response "Unauthorized"
-// @Failure 403 {object} response.Response "Forbidden - store suspended"
+// @Failure 403 {object} response.Response "Forbidden - store suspended or email not verified"
 // @Failure 409 {object} response.Response "Conflict - already active or profile incomplete"
 // @Router /stores/my/activate [put]
 func (h *StoreHandler) ActivateStore(c *fiber.Ctx) error {
 	userID := middleware.GetUserID(c)
 	err := h.storeUsecase.ActivateStore(userID)
 	if err != nil {
-		if err.Error() == "STORE_SUSPENDED_BY_ADMIN" {
+		if err.Error() == "STORE_SUSPENDED_BY_ADMIN" || err.Error() == "EMAIL_NOT_VERIFIED" {
 			return response.Forbidden(c, err.Error())
 		}
 		if err.Error() == "STORE_ALREADY_ACTIVE" || err.Error() == "STORE_PROFILE_INCOMPLETE" {
@@ -5,34 +5,45 @@ import (
 	"go-commerce/internal/usecase"
 	"go-commerce/internal/handler/response"
 	"go-commerce/internal/handler/middleware"
+	"go-commerce/pkg/pagination"
+	"net/url"
 	"strconv"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 type TransactionHandler struct {
-	transactionUsecase   *usecase.TransactionUsecase
-	paymentIntentUsecase domain.PaymentIntentUsecase
+	transactionUsecase     *usecase.TransactionUsecase
+	paymentIntentUsecase   domain.PaymentIntentUsecase
+	refundUsecase          *usecase.RefundUsecase
+	shipmentUsecase        *usecase.ShipmentUsecase
+	orderCompletionUsecase *usecase.OrderCompletionUsecase
+	cursors                *pagination.Signer
 }
 
-func NewTransactionHandler(transactionUsecase *usecase.TransactionUsecase, paymentIntentUsecase domain.PaymentIntentUsecase) *TransactionHandler {
+func NewTransactionHandler(transactionUsecase *usecase.TransactionUsecase, paymentIntentUsecase domain.PaymentIntentUsecase, refundUsecase *usecase.RefundUsecase, shipmentUsecase *usecase.ShipmentUsecase, orderCompletionUsecase *usecase.OrderCompletionUsecase, cursors *pagination.Signer) *TransactionHandler {
 	return &TransactionHandler{
-		transactionUsecase:   transactionUsecase,
-		paymentIntentUsecase: paymentIntentUsecase,
+		transactionUsecase:     transactionUsecase,
+		paymentIntentUsecase:   paymentIntentUsecase,
+		refundUsecase:          refundUsecase,
+		shipmentUsecase:        shipmentUsecase,
+		orderCompletionUsecase: orderCompletionUsecase,
+		cursors:                cursors,
 	}
 }
 
 // CreateTransaction godoc
 // @Summary Create a new transaction (Authenticated User)
-// @Description Create a new transaction with multiple items (atomic operation). Requires authentication.
+// @Description Create a checkout with multiple items (atomic operation). Items are split into one order per store; a single payment covers the whole checkout. Each order gets a shipping fee line for the courier service chosen for its store in `shipping`, or the cheapest one (see POST /shipping/quote). Requires authentication.
 // @Tags Transactions
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param request body domain.CreateTransactionRequest true "Transaction creation request"
-// @Success 200 {object} response.Response{data=domain.Transaction} "Transaction created successfully"
+// @Success 200 {object} response.Response{data=domain.Checkout} "Transaction created successfully"
 // @Failure 400 {object} response.Response "Bad request"
 // @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden - email not verified"
 // @Router /transactions [post]
 func (h *TransactionHandler) CreateTransaction(c *fiber.Ctx) error {
 	userID := middleware.GetUserID(c)
@@ -42,12 +53,15 @@ func (h *TransactionHandler) CreateTransaction(c *fiber.Ctx) error {
 		return response.BadRequest(c, "Invalid request body")
 	}
 
-	transaction, err := h.transactionUsecase.CreateTransaction(userID, &req)
+	checkout, err := h.transactionUsecase.CreateTransaction(userID, &req)
 	if err != nil {
+		if err.Error() == "EMAIL_NOT_VERIFIED" {
+			return response.Forbidden(c, err.Error())
+		}
 		return response.BadRequest(c, err.Error())
 	}
 
-	return response.Success(c, "Transaction created successfully", transaction)
+	return response.Success(c, "Transaction created successfully", checkout)
 }
 
 // GetTransactionByID godoc
@@ -79,22 +93,93 @@ func (h *TransactionHandler) GetTransactionByID(c *fiber.Ctx) error {
 	return response.Success(c, "Transaction retrieved successfully", transaction)
 }
 
+// GetTransactionByInvoice godoc
+// @Summary Get transaction by invoice code (Authenticated User)
+// @Description Look up an order by its invoice code, e.g. INV/20261017/12/3. The slashes may be sent as is or encoded as %2F. Available to the buyer, the seller of the store and staff with the transactions:view permission.
+// @Tags Transactions
+// @Produce json
+// @Security BearerAuth
+// @Param code path string true "Invoice code"
+// @Success 200 {object} response.Response{data=domain.Transaction} "Transaction retrieved successfully"
+// @Failure 400 {object} response.Response "Invalid invoice code"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 404 {object} response.Response "Transaction not found"
+// @Router /transactions/invoice/{code} [get]
+func (h *TransactionHandler) GetTransactionByInvoice(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+
+	kodeInvoice, err := url.PathUnescape(c.Params("*"))
+	if err != nil || kodeInvoice == "" {
+		return response.BadRequest(c, "Invalid invoice code")
+	}
+
+	transaction, err := h.transactionUsecase.GetTransactionByInvoice(userID, kodeInvoice, middleware.HasPermission(c, domain.PermissionTransactionsView))
+	if err != nil {
+		return response.NotFound(c, err.Error())
+	}
+
+	return response.Success(c, "Transaction retrieved successfully", transaction)
+}
+
+// GetTransactionTimeline godoc
+// @Summary Get transaction status timeline (Authenticated User)
+// @Description Get the payment and order status history of a transaction: every change with its actor, old and new status and reason. Available to the buyer, the seller of the store and staff with the transactions:view permission.
+// @Tags Transactions
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Transaction ID"
+// @Success 200 {object} response.Response{data=domain.TransactionTimeline} "Transaction timeline retrieved successfully"
+// @Failure 400 {object} response.Response "Invalid transaction ID"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Transaction not found"
+// @Router /transactions/{id}/timeline [get]
+func (h *TransactionHandler) GetTransactionTimeline(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+
+	transactionID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid transaction ID")
+	}
+
+	timeline, err := h.transactionUsecase.GetTransactionTimeline(userID, transactionID, middleware.HasPermission(c, domain.PermissionTransactionsView))
+	if err != nil {
+		switch err.Error() {
+		case "transaction not found":
+			return response.NotFound(c, err.Error())
+		case "forbidden":
+			return response.Forbidden(c, err.Error())
+		}
+		return response.InternalServerError(c, err.Error())
+	}
+
+	return response.Success(c, "Transaction timeline retrieved successfully", timeline)
+}
+
 // GetMyTransactions godoc
 // @Summary Get my transactions (Authenticated User)
-// @Description Get current user's transaction history with pagination. Requires authentication.
+// @Description Get current user's transaction history with pagination. Requires authentication. Pages by number by default; pagination=cursor or a cursor from meta.next_cursor/meta.prev_cursor pages by cursor instead.
 // @Tags Transactions
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page" default(10)
+// @Param pagination query string false "cursor to page by cursor"
+// @Param cursor query string false "Cursor of the page to read"
+// @Param sort_by query string false "Sort by in cursor mode: newest (default), oldest"
 // @Success 200 {object} response.PaginatedResponse{data=[]domain.Transaction} "Transactions retrieved successfully"
+// @Failure 400 {object} response.Response "Invalid cursor or sort order"
 // @Failure 401 {object} response.Response "Unauthorized"
 // @Failure 500 {object} response.Response "Internal server error"
 // @Router /transactions/my [get]
 func (h *TransactionHandler) GetMyTransactions(c *fiber.Ctx) error {
 	userID := middleware.GetUserID(c)
 
+	if cursorMode(c) {
+		return h.getMyTransactionsByCursor(c, userID)
+	}
+
 	page, _ := strconv.Atoi(c.Query("page", "1"))
 	limit, _ := strconv.Atoi(c.Query("limit", "10"))
 
@@ -113,7 +198,118 @@ func (h *TransactionHandler) GetMyTransactions(c *fiber.Ctx) error {
 	return response.SuccessWithMeta(c, "Transactions retrieved successfully", transactions, meta)
 }
 
+func (h *TransactionHandler) getMyTransactionsByCursor(c *fiber.Ctx, userID uint64) error {
+	page, err := cursorPage(c, h.cursors, myTransactionCursorScope)
+	if err != nil {
+		return response.BadRequest(c, err.Error())
+	}
+
+	transactions, links, err := h.transactionUsecase.GetMyTransactionsByCursor(userID, page)
+	if err != nil {
+		return cursorError(c, err)
+	}
+
+	meta, err := cursorMeta(h.cursors, myTransactionCursorScope, page, links)
+	if err != nil {
+		return response.InternalServerError(c, err.Error())
+	}
+
+	return response.SuccessWithMeta(c, "Transactions retrieved successfully", transactions, meta)
+}
+
+
+
+// GetCheckoutByID godoc
+// @Summary Get checkout by ID (Authenticated User)
+// @Description Get a checkout with its per-store orders. Only the buyer can access it.
+// @Tags Transactions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Checkout ID"
+// @Success 200 {object} response.Response{data=domain.Checkout} "Checkout retrieved successfully"
+// @Failure 400 {object} response.Response "Invalid checkout ID"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 404 {object} response.Response "Checkout not found"
+// @Router /checkouts/{id} [get]
+func (h *TransactionHandler) GetCheckoutByID(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+
+	checkoutID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid checkout ID")
+	}
+
+	checkout, err := h.transactionUsecase.GetCheckoutByID(userID, checkoutID)
+	if err != nil {
+		return response.NotFound(c, err.Error())
+	}
+
+	return response.Success(c, "Checkout retrieved successfully", checkout)
+}
+
+// GetSellerTransactions godoc
+// @Summary Get my store's orders (Seller)
+// @Description Get the orders placed at the seller's store with pagination. Requires authentication.
+// @Tags Transactions - Seller Operations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(10)
+// @Success 200 {object} response.PaginatedResponse{data=[]domain.Transaction} "Transactions retrieved successfully"
+// @Failure 400 {object} response.Response "Store not found"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Router /seller/transactions [get]
+func (h *TransactionHandler) GetSellerTransactions(c *fiber.Ctx) error {
+	sellerID := middleware.GetUserID(c)
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+
+	transactions, total, err := h.transactionUsecase.GetSellerTransactions(sellerID, page, limit)
+	if err != nil {
+		return response.BadRequest(c, err.Error())
+	}
+
+	meta := response.PaginationMeta{
+		Page:      page,
+		Limit:     limit,
+		Total:     total,
+		TotalPage: int((total + int64(limit) - 1) / int64(limit)),
+	}
+
+	return response.SuccessWithMeta(c, "Transactions retrieved successfully", transactions, meta)
+}
+
+// GetSellerTransactionByID godoc
+// @Summary Get one of my store's orders (Seller)
+// @Description Get an order placed at the seller's store. Requires authentication.
+// @Tags Transactions - Seller Operations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Transaction ID"
+// @Success 200 {object} response.Response{data=domain.Transaction} "Transaction retrieved successfully"
+// @Failure 400 {object} response.Response "Invalid transaction ID"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 404 {object} response.Response "Transaction not found"
+// @Router /seller/transactions/{id} [get]
+func (h *TransactionHandler) GetSellerTransactionByID(c *fiber.Ctx) error {
+	sellerID := middleware.GetUserID(c)
+
+	transactionID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid transaction ID")
+	}
 
+	transaction, err := h.transactionUsecase.GetSellerTransactionByID(sellerID, transactionID)
+	if err != nil {
+		return response.NotFound(c, err.Error())
+	}
+
+	return response.Success(c, "Transaction retrieved successfully", transaction)
+}
 
 // ProcessOrder godoc
 // @Summary Process order (Seller)
@@ -146,16 +342,19 @@ func (h *TransactionHandler) ProcessOrder(c *fiber.Ctx) error {
 
 // ShipOrder godoc
 // @Summary Ship order (Seller)
-// @Description Seller ships processed order. Requires authentication.
+// @Description Seller hands a processed order to a courier, giving the courier code, service level, tracking number and optionally the estimated delivery date. The order is then tracked at the courier until it is delivered. Requires authentication.
 // @Tags Transactions - Seller Operations
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "Transaction ID"
-// @Success 200 {object} response.Response "Order shipped successfully"
+// @Param request body domain.ShipOrderRequest true "Shipment details"
+// @Success 200 {object} response.Response{data=domain.Shipment} "Order shipped successfully"
 // @Failure 400 {object} response.Response "Bad request"
 // @Failure 401 {object} response.Response "Unauthorized"
 // @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Transaction not found"
+// @Failure 409 {object} response.Response "Order cannot be shipped in its current status"
 // @Router /seller/transactions/{id}/ship [put]
 func (h *TransactionHandler) ShipOrder(c *fiber.Ctx) error {
 	sellerID := middleware.GetUserID(c)
@@ -165,17 +364,68 @@ func (h *TransactionHandler) ShipOrder(c *fiber.Ctx) error {
 		return response.BadRequest(c, "Invalid transaction ID")
 	}
 
-	err = h.transactionUsecase.ShipOrder(sellerID, transactionID)
+	var req domain.ShipOrderRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+	if err := validate.Struct(&req); err != nil {
+		return response.BadRequest(c, err.Error())
+	}
+
+	shipment, err := h.shipmentUsecase.ShipOrder(sellerID, transactionID, &req)
 	if err != nil {
+		switch err.Error() {
+		case "transaction not found":
+			return response.NotFound(c, err.Error())
+		case "forbidden":
+			return response.Forbidden(c, err.Error())
+		case "INVALID_STATUS_TRANSITION":
+			return response.Conflict(c, err.Error())
+		}
 		return response.BadRequest(c, err.Error())
 	}
 
-	return response.Success(c, "Order shipped successfully", nil)
+	return response.Success(c, "Order shipped successfully", shipment)
+}
+
+// GetShipment godoc
+// @Summary Track shipment (Authenticated User)
+// @Description Get the courier, tracking number, estimated delivery date and tracking events of a shipped order. Available to the buyer, the seller of the store and staff with the transactions:view permission.
+// @Tags Transactions
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Transaction ID"
+// @Success 200 {object} response.Response{data=domain.Shipment} "Shipment retrieved successfully"
+// @Failure 400 {object} response.Response "Invalid transaction ID"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Transaction or shipment not found"
+// @Router /transactions/{id}/shipment [get]
+func (h *TransactionHandler) GetShipment(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+
+	transactionID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid transaction ID")
+	}
+
+	shipment, err := h.shipmentUsecase.GetShipment(userID, transactionID, middleware.HasPermission(c, domain.PermissionTransactionsView))
+	if err != nil {
+		switch err.Error() {
+		case "transaction not found", "shipment not found":
+			return response.NotFound(c, err.Error())
+		case "forbidden":
+			return response.Forbidden(c, err.Error())
+		}
+		return response.InternalServerError(c, err.Error())
+	}
+
+	return response.Success(c, "Shipment retrieved successfully", shipment)
 }
 
 // ConfirmDelivered godoc
 // @Summary Confirm delivery (Buyer)
-// @Description Buyer confirms order has been delivered. Requires authentication.
+// @Description Buyer confirms the order has been delivered, which completes it and releases the money to the seller. Orders not confirmed or disputed are completed automatically once the confirmation window after shipping ends. Requires authentication.
 // @Tags Transactions - Buyer Operations
 // @Accept json
 // @Produce json
@@ -185,6 +435,7 @@ func (h *TransactionHandler) ShipOrder(c *fiber.Ctx) error {
 // @Failure 400 {object} response.Response "Bad request"
 // @Failure 401 {object} response.Response "Unauthorized"
 // @Failure 403 {object} response.Response "Forbidden"
+// @Failure 409 {object} response.Response "Order cannot be completed in its current status or has an open dispute"
 // @Router /transactions/{id}/confirm-delivery [put]
 func (h *TransactionHandler) ConfirmDelivered(c *fiber.Ctx) error {
 	userID := middleware.GetUserID(c)
@@ -194,8 +445,14 @@ func (h *TransactionHandler) ConfirmDelivered(c *fiber.Ctx) error {
 		return response.BadRequest(c, "Invalid transaction ID")
 	}
 
-	err = h.transactionUsecase.ConfirmDelivered(userID, transactionID)
+	err = h.orderCompletionUsecase.ConfirmDelivered(userID, transactionID)
 	if err != nil {
+		switch err.Error() {
+		case "forbidden":
+			return response.Forbidden(c, err.Error())
+		case "INVALID_STATUS_TRANSITION", "DISPUTE_OPEN":
+			return response.Conflict(c, err.Error())
+		}
 		return response.BadRequest(c, err.Error())
 	}
 
@@ -204,16 +461,17 @@ func (h *TransactionHandler) ConfirmDelivered(c *fiber.Ctx) error {
 
 // CancelTransaction godoc
 // @Summary Cancel transaction (Buyer)
-// @Description Buyer cancels unpaid transaction. Requires authentication.
+// @Description Buyer cancels one unpaid order. The amount due for its checkout is reduced, and the checkout is cancelled with its last order. While a payment intent for the checkout is pending only its last order can be cancelled, since the intent charges the old amount. Requires authentication.
 // @Tags Transactions - Buyer Operations
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "Transaction ID"
-// @Success 200 {object} response.Response "Transaction cancelled successfully"
+// @Success 200 {object} response.Response{data=domain.Checkout} "Transaction cancelled successfully"
 // @Failure 400 {object} response.Response "Bad request"
 // @Failure 401 {object} response.Response "Unauthorized"
 // @Failure 403 {object} response.Response "Forbidden"
+// @Failure 409 {object} response.Response "Payment intent pending"
 // @Router /transactions/{id}/cancel [put]
 func (h *TransactionHandler) CancelTransaction(c *fiber.Ctx) error {
 	userID := middleware.GetUserID(c)
@@ -224,41 +482,128 @@ func (h *TransactionHandler) CancelTransaction(c *fiber.Ctx) error {
 	}
 
 	// Cancel transaction
-	err = h.transactionUsecase.CancelTransaction(userID, transactionID)
+	checkout, err := h.transactionUsecase.CancelTransaction(userID, transactionID)
 	if err != nil {
+		if err.Error() == "PAYMENT_INTENT_PENDING" {
+			return response.Conflict(c, err.Error())
+		}
 		return response.BadRequest(c, err.Error())
 	}
 
-	// Expire any active payment intents for this transaction
-	// This prevents payment success after cancellation (Koreksi #3)
-	h.paymentIntentUsecase.ExpireIntentsByTrxID(uint(transactionID))
-
-	return response.Success(c, "Transaction cancelled successfully", nil)
+	return response.Success(c, "Transaction cancelled successfully", checkout)
 }
 
 // RefundTransaction godoc
 // @Summary Refund transaction (Admin)
-// @Description Admin refunds paid transaction. Admin access required.
+// @Description Admin refunds part or all of a paid transaction. Refunded items restore their stock. Without a body the whole remaining balance and all remaining items are refunded; an amount without items refunds money only. The refund is recorded before the payment provider is asked for the money; when the provider fails it stays on the ledger as failed and can be retried. Admin access required.
 // @Tags Transactions - Admin Operations
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "Transaction ID"
-// @Success 200 {object} response.Response "Transaction refunded successfully"
+// @Param request body domain.RefundRequest false "Items, amount and reason of the refund"
+// @Success 200 {object} response.Response{data=domain.Refund} "Transaction refunded successfully"
 // @Failure 400 {object} response.Response "Bad request"
 // @Failure 401 {object} response.Response "Unauthorized"
 // @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Transaction not found"
+// @Failure 500 {object} response.Response "Payment provider refund failed"
 // @Router /admin/transactions/{id}/refund [put]
 func (h *TransactionHandler) RefundTransaction(c *fiber.Ctx) error {
+	actorID := middleware.GetUserID(c)
+
 	transactionID, err := strconv.ParseUint(c.Params("id"), 10, 64)
 	if err != nil {
 		return response.BadRequest(c, "Invalid transaction ID")
 	}
 
-	err = h.transactionUsecase.RefundTransaction(transactionID)
+	var req domain.RefundRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return response.BadRequest(c, "Invalid request body")
+		}
+		if err := validate.Struct(&req); err != nil {
+			return response.BadRequest(c, err.Error())
+		}
+	}
+
+	refund, err := h.refundUsecase.RefundTransaction(actorID, transactionID, &req)
+	if err != nil {
+		switch err.Error() {
+		case "transaction not found":
+			return response.NotFound(c, err.Error())
+		case "PAYMENT_REFUND_FAILED":
+			return response.InternalServerError(c, err.Error())
+		}
+		return response.BadRequest(c, err.Error())
+	}
+
+	return response.Success(c, "Transaction refunded successfully", refund)
+}
+
+// RetryRefund godoc
+// @Summary Retry a refund (Admin)
+// @Description Admin sends a pending or failed refund to the payment provider again. The provider returns the money of a refund once, however often it is retried. Admin access required.
+// @Tags Transactions - Admin Operations
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Refund ID"
+// @Success 200 {object} response.Response{data=domain.Refund} "Refund completed successfully"
+// @Failure 400 {object} response.Response "Bad request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Refund not found"
+// @Failure 409 {object} response.Response "Refund already completed"
+// @Failure 500 {object} response.Response "Payment provider refund failed"
+// @Router /admin/refunds/{id}/retry [post]
+func (h *TransactionHandler) RetryRefund(c *fiber.Ctx) error {
+	refundID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid refund ID")
+	}
+
+	refund, err := h.refundUsecase.RetryRefund(refundID)
 	if err != nil {
+		switch err.Error() {
+		case "refund not found":
+			return response.NotFound(c, err.Error())
+		case "REFUND_ALREADY_COMPLETED":
+			return response.Conflict(c, err.Error())
+		case "PAYMENT_REFUND_FAILED":
+			return response.InternalServerError(c, err.Error())
+		}
 		return response.BadRequest(c, err.Error())
 	}
 
-	return response.Success(c, "Transaction refunded successfully", nil)
+	return response.Success(c, "Refund completed successfully", refund)
+}
+
+// GetTransactionRefunds godoc
+// @Summary List refunds of a transaction (Admin)
+// @Description Get the refund ledger of a transaction with its refunded and refundable amount. Admin access required.
+// @Tags Transactions - Admin Operations
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Transaction ID"
+// @Success 200 {object} response.Response{data=domain.TransactionRefunds} "Refunds retrieved successfully"
+// @Failure 400 {object} response.Response "Bad request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Transaction not found"
+// @Router /admin/transactions/{id}/refunds [get]
+func (h *TransactionHandler) GetTransactionRefunds(c *fiber.Ctx) error {
+	transactionID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid transaction ID")
+	}
+
+	refunds, err := h.refundUsecase.GetTransactionRefunds(transactionID)
+	if err != nil {
+		if err.Error() == "transaction not found" {
+			return response.NotFound(c, err.Error())
+		}
+		return response.InternalServerError(c, err.Error())
+	}
+
+	return response.Success(c, "Refunds retrieved successfully", refunds)
 }
\ No newline at end of file
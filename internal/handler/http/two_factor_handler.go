@@ -0,0 +1,237 @@
+package http
+
+import (
+	"errors"
+
+	"go-commerce/internal/domain"
+	"go-commerce/internal/handler/middleware"
+	"go-commerce/internal/handler/response"
+	"go-commerce/internal/usecase"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type TwoFactorHandler struct {
+	authUsecase      *usecase.AuthUsecase
+	twoFactorUsecase *usecase.TwoFactorUsecase
+	validator        *validator.Validate
+}
+
+func NewTwoFactorHandler(authUsecase *usecase.AuthUsecase, twoFactorUsecase *usecase.TwoFactorUsecase) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		authUsecase:      authUsecase,
+		twoFactorUsecase: twoFactorUsecase,
+		validator:        validator.New(),
+	}
+}
+
+// Enroll godoc
+// @Summary Start two-factor enrollment (Authenticated User)
+// @Description Generate a TOTP secret and the otpauth URI to scan with an authenticator app. Two-factor authentication is enabled once a code is confirmed.
+// @Tags Two-Factor Authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=domain.TwoFactorEnrollResponse} "Two-factor enrollment started"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 409 {object} response.Response "Conflict - two-factor already enabled"
+// @Router /auth/2fa/enroll [post]
+func (h *TwoFactorHandler) Enroll(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+
+	enrollment, err := h.twoFactorUsecase.Enroll(userID)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return response.Success(c, "Two-factor enrollment started", enrollment)
+}
+
+// Confirm godoc
+// @Summary Confirm two-factor enrollment (Authenticated User)
+// @Description Enable two-factor authentication with the first code from the authenticator app. Returns one-time recovery codes that are shown only once.
+// @Tags Two-Factor Authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body domain.TwoFactorCodeRequest true "Current TOTP code"
+// @Success 200 {object} response.Response{data=domain.TwoFactorRecoveryCodesResponse} "Two-factor authentication enabled"
+// @Failure 400 {object} response.Response "Bad request or invalid code"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 409 {object} response.Response "Conflict - two-factor already enabled"
+// @Router /auth/2fa/confirm [post]
+func (h *TwoFactorHandler) Confirm(c *fiber.Ctx) error {
+	req, err := h.parseCodeRequest(c)
+	if err != nil {
+		return response.BadRequest(c, err.Error())
+	}
+
+	codes, err := h.twoFactorUsecase.Confirm(middleware.GetUserID(c), req)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return response.Success(c, "Two-factor authentication enabled", codes)
+}
+
+// Disable godoc
+// @Summary Disable two-factor authentication (Authenticated User)
+// @Description Turn two-factor authentication off. Requires a current TOTP code or an unused recovery code.
+// @Tags Two-Factor Authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body domain.TwoFactorCodeRequest true "TOTP or recovery code"
+// @Success 200 {object} response.Response "Two-factor authentication disabled"
+// @Failure 400 {object} response.Response "Bad request or invalid code"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Router /auth/2fa/disable [post]
+func (h *TwoFactorHandler) Disable(c *fiber.Ctx) error {
+	req, err := h.parseCodeRequest(c)
+	if err != nil {
+		return response.BadRequest(c, err.Error())
+	}
+
+	if err := h.twoFactorUsecase.Disable(middleware.GetUserID(c), req); err != nil {
+		return h.handleError(c, err)
+	}
+
+	return response.Success(c, "Two-factor authentication disabled", nil)
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary Regenerate recovery codes (Authenticated User)
+// @Description Replace all recovery codes. Requires a current TOTP code. Previous recovery codes stop working.
+// @Tags Two-Factor Authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body domain.TwoFactorCodeRequest true "Current TOTP code"
+// @Success 200 {object} response.Response{data=domain.TwoFactorRecoveryCodesResponse} "Recovery codes regenerated"
+// @Failure 400 {object} response.Response "Bad request or invalid code"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Router /auth/2fa/recovery-codes [post]
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	req, err := h.parseCodeRequest(c)
+	if err != nil {
+		return response.BadRequest(c, err.Error())
+	}
+
+	codes, err := h.twoFactorUsecase.RegenerateRecoveryCodes(middleware.GetUserID(c), req)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return response.Success(c, "Recovery codes regenerated", codes)
+}
+
+// VerifyLogin godoc
+// @Summary Complete a two-factor login (Public)
+// @Description Exchange the challenge token returned by login plus a TOTP or recovery code for an access/refresh token pair.
+// @Tags Two-Factor Authentication
+// @Accept json
+// @Produce json
+// @Param request body domain.TwoFactorLoginRequest true "Challenge token and code"
+// @Success 200 {object} response.Response{data=domain.AuthResponse} "Login successful"
+// @Failure 400 {object} response.Response "Bad request"
+// @Failure 401 {object} response.Response "Invalid challenge or code"
+// @Failure 429 {object} response.Response "Too many failed attempts, see Retry-After header"
+// @Router /auth/2fa/verify [post]
+func (h *TwoFactorHandler) VerifyLogin(c *fiber.Ctx) error {
+	var req domain.TwoFactorLoginRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return response.BadRequest(c, "Validation failed: "+err.Error())
+	}
+
+	req.ClientIP = c.IP()
+
+	authResponse, err := h.authUsecase.VerifyTwoFactorLogin(&req)
+	if err != nil {
+		var throttleErr *domain.ThrottleError
+		if errors.As(err, &throttleErr) {
+			c.Set(fiber.HeaderRetryAfter, throttleErr.RetryAfterSeconds())
+			return response.TooManyRequests(c, err.Error())
+		}
+		return response.Unauthorized(c, err.Error())
+	}
+
+	return response.Success(c, "Login successful", authResponse)
+}
+
+// GetPolicy godoc
+// @Summary Get the two-factor policy (Admin only)
+// @Description Show whether accounts with admin privileges must use two-factor authentication. Requires the security:manage permission.
+// @Tags Two-Factor Authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=domain.TwoFactorPolicy} "Two-factor policy retrieved successfully"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden - permission required"
+// @Router /admin/security/two-factor [get]
+func (h *TwoFactorHandler) GetPolicy(c *fiber.Ctx) error {
+	policy, err := h.twoFactorUsecase.GetPolicy()
+	if err != nil {
+		return response.InternalServerError(c, err.Error())
+	}
+
+	return response.Success(c, "Two-factor policy retrieved successfully", policy)
+}
+
+// UpdatePolicy godoc
+// @Summary Update the two-factor policy (Admin only)
+// @Description Require two-factor authentication for every account with admin privileges. Admins without 2FA then receive tokens without admin permissions until they enroll. Requires the security:manage permission.
+// @Tags Two-Factor Authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body domain.UpdateTwoFactorPolicyRequest true "Two-factor policy"
+// @Success 200 {object} response.Response{data=domain.TwoFactorPolicy} "Two-factor policy updated successfully"
+// @Failure 400 {object} response.Response "Bad request"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden - permission required"
+// @Router /admin/security/two-factor [put]
+func (h *TwoFactorHandler) UpdatePolicy(c *fiber.Ctx) error {
+	var req domain.UpdateTwoFactorPolicyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return response.BadRequest(c, "Validation failed: "+err.Error())
+	}
+
+	policy, err := h.twoFactorUsecase.UpdatePolicy(&req)
+	if err != nil {
+		return response.InternalServerError(c, err.Error())
+	}
+
+	return response.Success(c, "Two-factor policy updated successfully", policy)
+}
+
+func (h *TwoFactorHandler) parseCodeRequest(c *fiber.Ctx) (*domain.TwoFactorCodeRequest, error) {
+	var req domain.TwoFactorCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return nil, errors.New("Invalid request body")
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return nil, errors.New("Validation failed: " + err.Error())
+	}
+	return &req, nil
+}
+
+func (h *TwoFactorHandler) handleError(c *fiber.Ctx, err error) error {
+	switch err.Error() {
+	case "user not found":
+		return response.NotFound(c, err.Error())
+	case "TWO_FACTOR_ALREADY_ENABLED":
+		return response.Conflict(c, err.Error())
+	}
+	return response.BadRequest(c, err.Error())
+}
@@ -2,14 +2,16 @@ package middleware
 
 import (
 	"strings"
+	"time"
 
+	"go-commerce/internal/domain"
 	"go-commerce/internal/handler/response"
 	"go-commerce/pkg/jwt"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-func JWTMiddleware(jwtManager *jwt.JWTManager) fiber.Handler {
+func JWTMiddleware(jwtManager *jwt.JWTManager, revocationStore domain.TokenRevocationStore) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		// Get Authorization header
 		authHeader := c.Get("Authorization")
@@ -29,20 +31,42 @@ func JWTMiddleware(jwtManager *jwt.JWTManager) fiber.Handler {
 			return response.Unauthorized(c, "Invalid or expired token")
 		}
 
+		// Reject tokens revoked by logout
+		if revocationStore != nil {
+			var issuedAt time.Time
+			if claims.IssuedAt != nil {
+				issuedAt = claims.IssuedAt.Time
+			}
+			revoked, err := revocationStore.IsRevoked(claims.ID, claims.UserID, issuedAt)
+			if err != nil {
+				return response.InternalServerError(c, "Failed to verify token")
+			}
+			if revoked {
+				return response.Unauthorized(c, "Token has been revoked")
+			}
+		}
+
 		// Set user info in context
 		c.Locals("user_id", claims.UserID)
 		c.Locals("user_email", claims.Email)
 		c.Locals("is_admin", claims.IsAdmin)
+		c.Locals("roles", claims.Roles)
+		c.Locals("permissions", claims.Permissions)
+		c.Locals("token_id", claims.ID)
+		if claims.ExpiresAt != nil {
+			c.Locals("token_expires_at", claims.ExpiresAt.Time)
+		}
 
 		return c.Next()
 	}
 }
 
-func RequireAdmin() fiber.Handler {
+// RequirePermission allows the request only when one of the caller's roles
+// grants permission. Must run after JWTMiddleware.
+func RequirePermission(permission string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		isAdmin, ok := c.Locals("is_admin").(bool)
-		if !ok || !isAdmin {
-			return response.Forbidden(c, "Admin access required")
+		if !HasPermission(c, permission) {
+			return response.Forbidden(c, "Permission "+permission+" required")
 		}
 		return c.Next()
 	}
@@ -58,6 +82,31 @@ func GetUserEmail(c *fiber.Ctx) string {
 	return email
 }
 
+func GetTokenID(c *fiber.Ctx) string {
+	tokenID, _ := c.Locals("token_id").(string)
+	return tokenID
+}
+
+func GetTokenExpiresAt(c *fiber.Ctx) time.Time {
+	expiresAt, _ := c.Locals("token_expires_at").(time.Time)
+	return expiresAt
+}
+
+func GetRoles(c *fiber.Ctx) []string {
+	roles, _ := c.Locals("roles").([]string)
+	return roles
+}
+
+func HasPermission(c *fiber.Ctx, permission string) bool {
+	permissions, _ := c.Locals("permissions").([]string)
+	for _, p := range permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
 func IsAdmin(c *fiber.Ctx) bool {
 	isAdmin, _ := c.Locals("is_admin").(bool)
 	return isAdmin
@@ -8,11 +8,15 @@ type Response struct {
 	Data    interface{} `json:"data,omitempty"`
 }
 
+// PaginationMeta describes a page of a list. Cursor pages only set Limit and
+// the cursors: they are not numbered and not counted.
 type PaginationMeta struct {
-	Page      int   `json:"page"`
-	Limit     int   `json:"limit"`
-	Total     int64 `json:"total"`
-	TotalPage int   `json:"total_page"`
+	Page       int    `json:"page"`
+	Limit      int    `json:"limit"`
+	Total      int64  `json:"total"`
+	TotalPage  int    `json:"total_page"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
 }
 
 type PaginatedResponse struct {
@@ -20,6 +24,7 @@ type PaginatedResponse struct {
 	Message string        `json:"message"`
 	Data   interface{}    `json:"data"`
 	Meta   PaginationMeta `json:"meta"`
+	Facets interface{}    `json:"facets,omitempty"`
 }
 
 func Success(c *fiber.Ctx, message string, data interface{}) error {
@@ -73,6 +78,13 @@ func Conflict(c *fiber.Ctx, message string) error {
 	})
 }
 
+func TooManyRequests(c *fiber.Ctx, message string) error {
+	return c.Status(fiber.StatusTooManyRequests).JSON(Response{
+		Status:  "error",
+		Message: message,
+	})
+}
+
 func InternalServerError(c *fiber.Ctx, message string) error {
 	return c.Status(fiber.StatusInternalServerError).JSON(Response{
 		Status:  "error",
@@ -89,6 +101,17 @@ func Paginated(c *fiber.Ctx, message string, data interface{}, meta PaginationMe
 	})
 }
 
+// PaginatedWithFacets is Paginated with facet counts, e.g. of a search
+func PaginatedWithFacets(c *fiber.Ctx, message string, data interface{}, meta PaginationMeta, facets interface{}) error {
+	return c.Status(fiber.StatusOK).JSON(PaginatedResponse{
+		Status:  "success",
+		Message: message,
+		Data:    data,
+		Meta:    meta,
+		Facets:  facets,
+	})
+}
+
 func SuccessWithMeta(c *fiber.Ctx, message string, data interface{}, meta PaginationMeta) error {
 	return c.Status(fiber.StatusOK).JSON(PaginatedResponse{
 		Status:  "success",
@@ -0,0 +1,425 @@
+package memory
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go-commerce/internal/domain"
+	"go-commerce/pkg/utils"
+)
+
+// Words in the product name count more than words in the description
+const (
+	nameWeight        = 3.0
+	descriptionWeight = 1.0
+)
+
+// Score factors for terms that only match an indexed word approximately
+const (
+	prefixFactor = 0.8
+	typo1Factor  = 0.6
+	typo2Factor  = 0.4
+)
+
+type searchDoc struct {
+	id           uint64
+	name         string
+	categoryID   uint64
+	categoryName string
+	storeID      uint64
+	storeName    string
+	price        float64
+	soldCount    int
+	rating       float64
+	ratingCount  int
+	createdAt    time.Time
+	length       float64
+}
+
+// productSearchIndex is an inverted index from words to the products that
+// contain them, weighted by where the word appears
+type productSearchIndex struct {
+	mu       sync.RWMutex
+	docs     map[uint64]*searchDoc
+	postings map[string]map[uint64]float64
+	// totalLength sums the weighted length of all docs for length
+	// normalization
+	totalLength float64
+}
+
+func NewProductSearchIndex() domain.ProductSearchIndex {
+	return &productSearchIndex{
+		docs:     make(map[uint64]*searchDoc),
+		postings: make(map[string]map[uint64]float64),
+	}
+}
+
+func (s *productSearchIndex) Index(product *domain.Product) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.remove(product.ID)
+	if product.Status != "active" || product.DeletedAt.Valid {
+		return nil
+	}
+
+	doc := &searchDoc{
+		id:           product.ID,
+		name:         product.NamaProduk,
+		categoryID:   product.IDCategory,
+		categoryName: product.Category.Name,
+		storeID:      product.IDToko,
+		storeName:    product.Toko.Name,
+		price:        product.HargaKonsumen,
+		soldCount:    product.SoldCount,
+		rating:       product.Rating,
+		ratingCount:  product.RatingCount,
+		createdAt:    product.CreatedAt,
+	}
+
+	frequencies := make(map[string]float64)
+	for _, field := range []struct {
+		text   string
+		weight float64
+	}{
+		{product.NamaProduk, nameWeight},
+		{product.Deskripsi, descriptionWeight},
+	} {
+		for _, token := range utils.SearchTokens(field.text) {
+			if utils.IsStopWord(token) {
+				continue
+			}
+			frequencies[token] += field.weight
+			doc.length += field.weight
+		}
+	}
+
+	for token, frequency := range frequencies {
+		if s.postings[token] == nil {
+			s.postings[token] = make(map[uint64]float64)
+		}
+		s.postings[token][product.ID] = frequency
+	}
+	s.docs[product.ID] = doc
+	s.totalLength += doc.length
+
+	return nil
+}
+
+func (s *productSearchIndex) Remove(productID uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.remove(productID)
+	return nil
+}
+
+// remove drops productID from the index; callers hold the write lock
+func (s *productSearchIndex) remove(productID uint64) {
+	doc, ok := s.docs[productID]
+	if !ok {
+		return
+	}
+
+	for token, docs := range s.postings {
+		if _, ok := docs[productID]; !ok {
+			continue
+		}
+		delete(docs, productID)
+		if len(docs) == 0 {
+			delete(s.postings, token)
+		}
+	}
+	s.totalLength -= doc.length
+	delete(s.docs, productID)
+}
+
+func (s *productSearchIndex) Search(query *domain.ProductSearchQuery) (*domain.ProductSearchResult, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	terms := utils.SearchTerms(query.Query)
+
+	var scores map[uint64]float64
+	if len(terms) > 0 {
+		scores = s.score(terms)
+	} else {
+		scores = make(map[uint64]float64, len(s.docs))
+		for id := range s.docs {
+			scores[id] = 0
+		}
+	}
+
+	matches := make([]*searchDoc, 0, len(scores))
+	for id := range scores {
+		doc := s.docs[id]
+		if query.CategoryID != 0 && doc.categoryID != query.CategoryID {
+			continue
+		}
+		if query.StoreID != 0 && doc.storeID != query.StoreID {
+			continue
+		}
+		if query.MinPrice != nil && doc.price < *query.MinPrice {
+			continue
+		}
+		if query.MaxPrice != nil && doc.price > *query.MaxPrice {
+			continue
+		}
+		if query.MinRating != nil && doc.rating < *query.MinRating {
+			continue
+		}
+		matches = append(matches, doc)
+	}
+
+	sortBy := query.SortBy
+	if sortBy == "" || (sortBy == "relevance" && len(terms) == 0) {
+		sortBy = "newest"
+		if len(terms) > 0 {
+			sortBy = "relevance"
+		}
+	}
+	sortDocs(matches, scores, sortBy)
+
+	result := &domain.ProductSearchResult{
+		ProductIDs: []uint64{},
+		Total:      int64(len(matches)),
+		Facets:     facets(matches),
+	}
+	for i := query.Offset; i < len(matches) && i < query.Offset+query.Limit; i++ {
+		result.ProductIDs = append(result.ProductIDs, matches[i].id)
+	}
+
+	return result, nil
+}
+
+// score returns the BM25 score of every doc that matches all terms. A term
+// matches an indexed word exactly, as a prefix, or within a small edit
+// distance, with lower scores for the looser matches.
+func (s *productSearchIndex) score(terms []string) map[uint64]float64 {
+	const k1, b = 1.2, 0.75
+
+	n := float64(len(s.docs))
+	avgLength := 1.0
+	if n > 0 && s.totalLength > 0 {
+		avgLength = s.totalLength / n
+	}
+
+	var scores map[uint64]float64
+	for _, term := range terms {
+		termScores := make(map[uint64]float64)
+		for token, docs := range s.postings {
+			factor := matchFactor(term, token)
+			if factor == 0 {
+				continue
+			}
+
+			df := float64(len(docs))
+			idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+			for id, tf := range docs {
+				norm := tf + k1*(1-b+b*s.docs[id].length/avgLength)
+				score := factor * idf * tf * (k1 + 1) / norm
+				if score > termScores[id] {
+					termScores[id] = score
+				}
+			}
+		}
+
+		if scores == nil {
+			scores = termScores
+			continue
+		}
+		for id, score := range scores {
+			if termScore, ok := termScores[id]; ok {
+				scores[id] = score + termScore
+			} else {
+				delete(scores, id)
+			}
+		}
+	}
+
+	return scores
+}
+
+// matchFactor weighs how well the query term matches an indexed token, or
+// returns 0 when it does not match. Short terms only match by prefix since
+// one typo already changes too much of them.
+func matchFactor(term, token string) float64 {
+	if term == token {
+		return 1
+	}
+	if len(term) >= 2 && strings.HasPrefix(token, term) {
+		return prefixFactor
+	}
+
+	maxEdits := 0
+	switch {
+	case len(term) >= 8:
+		maxEdits = 2
+	case len(term) >= 4:
+		maxEdits = 1
+	}
+	if maxEdits == 0 {
+		return 0
+	}
+
+	distance := editDistance(term, token, maxEdits)
+	switch {
+	case distance > maxEdits:
+		return 0
+	case distance == 1:
+		return typo1Factor
+	default:
+		return typo2Factor
+	}
+}
+
+// editDistance is the Levenshtein distance between a and b, or max+1 once it
+// exceeds max
+func editDistance(a, b string, max int) int {
+	ra, rb := []rune(a), []rune(b)
+	if diff := len(ra) - len(rb); diff > max || -diff > max {
+		return max + 1
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			rowMin = min(rowMin, curr[j])
+		}
+		if rowMin > max {
+			return max + 1
+		}
+		prev, curr = curr, prev
+	}
+
+	if prev[len(rb)] > max {
+		return max + 1
+	}
+	return prev[len(rb)]
+}
+
+func sortDocs(docs []*searchDoc, scores map[uint64]float64, sortBy string) {
+	sort.Slice(docs, func(i, j int) bool {
+		a, b := docs[i], docs[j]
+		switch sortBy {
+		case "relevance":
+			if scores[a.id] != scores[b.id] {
+				return scores[a.id] > scores[b.id]
+			}
+			if a.soldCount != b.soldCount {
+				return a.soldCount > b.soldCount
+			}
+		case "price_asc":
+			if a.price != b.price {
+				return a.price < b.price
+			}
+		case "price_desc":
+			if a.price != b.price {
+				return a.price > b.price
+			}
+		case "oldest":
+			if !a.createdAt.Equal(b.createdAt) {
+				return a.createdAt.Before(b.createdAt)
+			}
+			return a.id < b.id
+		case "popular":
+			if a.soldCount != b.soldCount {
+				return a.soldCount > b.soldCount
+			}
+		case "rating":
+			if a.rating != b.rating {
+				return a.rating > b.rating
+			}
+			if a.ratingCount != b.ratingCount {
+				return a.ratingCount > b.ratingCount
+			}
+		case "name_asc":
+			if a.name != b.name {
+				return strings.ToLower(a.name) < strings.ToLower(b.name)
+			}
+		case "name_desc":
+			if a.name != b.name {
+				return strings.ToLower(a.name) > strings.ToLower(b.name)
+			}
+		}
+
+		// Newest first, also as the tie-breaker of the other orders
+		if !a.createdAt.Equal(b.createdAt) {
+			return a.createdAt.After(b.createdAt)
+		}
+		return a.id > b.id
+	})
+}
+
+func facets(docs []*searchDoc) *domain.ProductSearchFacets {
+	categories := make(map[uint64]*domain.FacetCount)
+	stores := make(map[uint64]*domain.FacetCount)
+	prices := make([]int64, len(domain.PriceBuckets))
+
+	for _, doc := range docs {
+		if categories[doc.categoryID] == nil {
+			categories[doc.categoryID] = &domain.FacetCount{
+				Value: strconv.FormatUint(doc.categoryID, 10),
+				Label: doc.categoryName,
+			}
+		}
+		categories[doc.categoryID].Count++
+
+		if stores[doc.storeID] == nil {
+			stores[doc.storeID] = &domain.FacetCount{
+				Value: strconv.FormatUint(doc.storeID, 10),
+				Label: doc.storeName,
+			}
+		}
+		stores[doc.storeID].Count++
+
+		for i, bucket := range domain.PriceBuckets {
+			if bucket.Contains(doc.price) {
+				prices[i]++
+				break
+			}
+		}
+	}
+
+	result := &domain.ProductSearchFacets{
+		Categories:  sortedFacets(categories),
+		PriceRanges: []domain.FacetCount{},
+		Stores:      sortedFacets(stores),
+	}
+	for i, bucket := range domain.PriceBuckets {
+		if prices[i] > 0 {
+			result.PriceRanges = append(result.PriceRanges, domain.FacetCount{Value: bucket.Key(), Count: prices[i]})
+		}
+	}
+	return result
+}
+
+// sortedFacets orders facet values by count, most frequent first
+func sortedFacets(counts map[uint64]*domain.FacetCount) []domain.FacetCount {
+	result := make([]domain.FacetCount, 0, len(counts))
+	for _, count := range counts {
+		result = append(result, *count)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Value < result[j].Value
+	})
+	return result
+}
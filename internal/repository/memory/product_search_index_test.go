@@ -0,0 +1,117 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"go-commerce/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestSearchIndex(t *testing.T) domain.ProductSearchIndex {
+	index := NewProductSearchIndex()
+	now := time.Now()
+
+	products := []*domain.Product{
+		{ID: 1, NamaProduk: "Kemeja Flanel Pria", Deskripsi: "Kemeja lengan panjang untuk kerja", HargaKonsumen: 150000,
+			IDCategory: 10, IDToko: 100, Status: "active", SoldCount: 5, Rating: 4.5, RatingCount: 10, CreatedAt: now.Add(-3 * time.Hour),
+			Category: domain.Category{Name: "Kemeja"}, Toko: domain.Store{Name: "Toko Baju"}},
+		{ID: 2, NamaProduk: "Kaos Polos Hitam", Deskripsi: "Cocok dipadukan dengan kemeja", HargaKonsumen: 45000,
+			IDCategory: 11, IDToko: 100, Status: "active", SoldCount: 20, Rating: 3, RatingCount: 4, CreatedAt: now.Add(-2 * time.Hour),
+			Category: domain.Category{Name: "Kaos"}, Toko: domain.Store{Name: "Toko Baju"}},
+		{ID: 3, NamaProduk: "Kemeja Batik", Deskripsi: "Batik tulis", HargaKonsumen: 275000,
+			IDCategory: 10, IDToko: 200, Status: "active", SoldCount: 1, Rating: 4.5, RatingCount: 2, CreatedAt: now.Add(-1 * time.Hour),
+			Category: domain.Category{Name: "Kemeja"}, Toko: domain.Store{Name: "Batik Solo"}},
+		{ID: 4, NamaProduk: "Kemeja Lama", HargaKonsumen: 90000,
+			IDCategory: 10, IDToko: 200, Status: "inactive", CreatedAt: now},
+	}
+	for _, product := range products {
+		assert.NoError(t, index.Index(product))
+	}
+
+	return index
+}
+
+func TestProductSearchIndex_RanksNameMatchesFirst(t *testing.T) {
+	index := newTestSearchIndex(t)
+
+	result, err := index.Search(&domain.ProductSearchQuery{Query: "kemeja", Limit: 10})
+
+	assert.NoError(t, err)
+	// The inactive product is not indexed, and the description match of
+	// product 2 ranks below the name matches
+	assert.Equal(t, int64(3), result.Total)
+	assert.Len(t, result.ProductIDs, 3)
+	assert.Equal(t, uint64(2), result.ProductIDs[2])
+}
+
+func TestProductSearchIndex_ToleratesTypos(t *testing.T) {
+	index := newTestSearchIndex(t)
+
+	for _, query := range []string{"kemja", "flannel", "flan"} {
+		result, err := index.Search(&domain.ProductSearchQuery{Query: query, Limit: 10})
+
+		assert.NoError(t, err)
+		assert.Contains(t, result.ProductIDs, uint64(1), query)
+	}
+}
+
+func TestProductSearchIndex_IgnoresStopWords(t *testing.T) {
+	index := newTestSearchIndex(t)
+
+	result, err := index.Search(&domain.ProductSearchQuery{Query: "kemeja untuk pria", Limit: 10})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{1}, result.ProductIDs)
+}
+
+func TestProductSearchIndex_FiltersAndFacets(t *testing.T) {
+	index := newTestSearchIndex(t)
+	maxPrice := 200000.0
+
+	result, err := index.Search(&domain.ProductSearchQuery{Query: "kemeja", MaxPrice: &maxPrice, SortBy: "price_asc", Limit: 10})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{2, 1}, result.ProductIDs)
+	assert.Equal(t, []domain.FacetCount{
+		{Value: "10", Label: "Kemeja", Count: 1},
+		{Value: "11", Label: "Kaos", Count: 1},
+	}, result.Facets.Categories)
+	assert.Equal(t, []domain.FacetCount{
+		{Value: "0-50000", Count: 1},
+		{Value: "100000-250000", Count: 1},
+	}, result.Facets.PriceRanges)
+	assert.Equal(t, []domain.FacetCount{{Value: "100", Label: "Toko Baju", Count: 2}}, result.Facets.Stores)
+}
+
+func TestProductSearchIndex_ReindexAndRemove(t *testing.T) {
+	index := newTestSearchIndex(t)
+
+	// Renamed products are found by their new name only
+	assert.NoError(t, index.Index(&domain.Product{ID: 3, NamaProduk: "Blus Batik", Status: "active"}))
+	result, err := index.Search(&domain.ProductSearchQuery{Query: "kemeja batik", Limit: 10})
+	assert.NoError(t, err)
+	assert.Empty(t, result.ProductIDs)
+
+	assert.NoError(t, index.Remove(3))
+	result, err = index.Search(&domain.ProductSearchQuery{Query: "batik", Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), result.Total)
+}
+
+func TestProductSearchIndex_SortsAndFiltersByRating(t *testing.T) {
+	index := newTestSearchIndex(t)
+
+	result, err := index.Search(&domain.ProductSearchQuery{Query: "kemeja", SortBy: "rating", Limit: 10})
+
+	// Equal ratings rank the product with more reviews first
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{1, 3, 2}, result.ProductIDs)
+
+	minRating := 4.0
+	result, err = index.Search(&domain.ProductSearchQuery{Query: "kemeja", MinRating: &minRating, Limit: 10})
+
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []uint64{1, 3}, result.ProductIDs)
+}
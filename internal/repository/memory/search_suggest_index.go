@@ -0,0 +1,263 @@
+package memory
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"go-commerce/internal/domain"
+	"go-commerce/pkg/utils"
+)
+
+// maxSuggestKeyLength caps the indexed length of every suffix; longer
+// prefixes are not completed
+const maxSuggestKeyLength = 100
+
+// maxBoostQueries is how many of the most searched matching queries rank the
+// other entries
+const maxBoostQueries = 20
+
+// suggestKey identifies an entry; queries have no ID and are keyed by their
+// normalized text
+type suggestKey struct {
+	kind  string
+	id    uint64
+	query string
+}
+
+type suggestEntry struct {
+	domain.SuggestionEntry
+	normalized string
+}
+
+type suggestNode struct {
+	children map[rune]*suggestNode
+	entries  map[suggestKey]struct{}
+}
+
+func newSuggestNode() *suggestNode {
+	return &suggestNode{
+		children: make(map[rune]*suggestNode),
+		entries:  make(map[suggestKey]struct{}),
+	}
+}
+
+// searchSuggestIndex is a trie over every word-boundary suffix of the
+// normalized entry texts, so "flanel p" completes "Kemeja Flanel Pria". Each
+// node holds the entries below it, so a lookup is one walk down the prefix.
+type searchSuggestIndex struct {
+	mu      sync.RWMutex
+	root    *suggestNode
+	entries map[suggestKey]*suggestEntry
+}
+
+func NewSearchSuggestIndex() domain.SearchSuggestIndex {
+	return &searchSuggestIndex{
+		root:    newSuggestNode(),
+		entries: make(map[suggestKey]*suggestEntry),
+	}
+}
+
+func (s *searchSuggestIndex) Load(entries []*domain.SuggestionEntry) {
+	fresh := &searchSuggestIndex{
+		root:    newSuggestNode(),
+		entries: make(map[suggestKey]*suggestEntry, len(entries)),
+	}
+	for _, entry := range entries {
+		fresh.put(entry)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.root = fresh.root
+	s.entries = fresh.entries
+}
+
+func (s *searchSuggestIndex) Put(entry *domain.SuggestionEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.put(entry)
+}
+
+func (s *searchSuggestIndex) Remove(kind string, id uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.remove(suggestKey{kind: kind, id: id})
+}
+
+func (s *searchSuggestIndex) RecordQuery(query string, count int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	normalized := normalizeSuggestText(query)
+	if existing, ok := s.entries[suggestKey{kind: domain.SuggestionQuery, query: normalized}]; ok {
+		existing.Weight += count
+		return
+	}
+	s.put(&domain.SuggestionEntry{Kind: domain.SuggestionQuery, Text: normalized, Weight: count})
+}
+
+func (s *searchSuggestIndex) Suggest(prefix string, limit int) *domain.SearchSuggestions {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := &domain.SearchSuggestions{
+		Queries:    []domain.Suggestion{},
+		Products:   []domain.Suggestion{},
+		Categories: []domain.Suggestion{},
+		Stores:     []domain.Suggestion{},
+	}
+
+	node := s.root
+	for _, r := range normalizeSuggestText(prefix) {
+		node = node.children[r]
+		if node == nil {
+			return result
+		}
+	}
+	if node == s.root {
+		return result
+	}
+
+	byKind := make(map[string][]*suggestEntry)
+	for key := range node.entries {
+		byKind[key.kind] = append(byKind[key.kind], s.entries[key])
+	}
+
+	// The most searched queries for this prefix rank everything else
+	queries := byKind[domain.SuggestionQuery]
+	sort.Slice(queries, func(i, j int) bool {
+		if queries[i].Weight != queries[j].Weight {
+			return queries[i].Weight > queries[j].Weight
+		}
+		return queries[i].normalized < queries[j].normalized
+	})
+	boostQueries := queries
+	if len(boostQueries) > maxBoostQueries {
+		boostQueries = boostQueries[:maxBoostQueries]
+	}
+
+	result.Queries = suggestions(queries, limit)
+	for kind, target := range map[string]*[]domain.Suggestion{
+		domain.SuggestionProduct:  &result.Products,
+		domain.SuggestionCategory: &result.Categories,
+		domain.SuggestionStore:    &result.Stores,
+	} {
+		entries := byKind[kind]
+		boosts := make(map[*suggestEntry]int64, len(entries))
+		for _, entry := range entries {
+			for _, query := range boostQueries {
+				if strings.Contains(" "+entry.normalized, " "+query.normalized) {
+					boosts[entry] += query.Weight
+				}
+			}
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			a, b := entries[i], entries[j]
+			if boosts[a] != boosts[b] {
+				return boosts[a] > boosts[b]
+			}
+			if a.Weight != b.Weight {
+				return a.Weight > b.Weight
+			}
+			if len(a.normalized) != len(b.normalized) {
+				return len(a.normalized) < len(b.normalized)
+			}
+			return a.normalized < b.normalized
+		})
+		*target = suggestions(entries, limit)
+	}
+
+	return result
+}
+
+// put indexes entry; callers hold the write lock
+func (s *searchSuggestIndex) put(entry *domain.SuggestionEntry) {
+	key := suggestKey{kind: entry.Kind, id: entry.ID}
+	normalized := normalizeSuggestText(entry.Text)
+	if entry.Kind == domain.SuggestionQuery {
+		key = suggestKey{kind: entry.Kind, query: normalized}
+	}
+
+	s.remove(key)
+	if normalized == "" {
+		return
+	}
+
+	indexed := &suggestEntry{SuggestionEntry: *entry, normalized: normalized}
+	s.entries[key] = indexed
+	for _, suffix := range suggestSuffixes(normalized) {
+		node := s.root
+		for _, r := range suffix {
+			child := node.children[r]
+			if child == nil {
+				child = newSuggestNode()
+				node.children[r] = child
+			}
+			child.entries[key] = struct{}{}
+			node = child
+		}
+	}
+}
+
+// remove drops key and prunes nodes left without entries; callers hold the
+// write lock
+func (s *searchSuggestIndex) remove(key suggestKey) {
+	existing, ok := s.entries[key]
+	if !ok {
+		return
+	}
+	delete(s.entries, key)
+
+	for _, suffix := range suggestSuffixes(existing.normalized) {
+		node := s.root
+		for _, r := range suffix {
+			child := node.children[r]
+			if child == nil {
+				break
+			}
+			delete(child.entries, key)
+			if len(child.entries) == 0 {
+				delete(node.children, r)
+				break
+			}
+			node = child
+		}
+	}
+}
+
+// suggestSuffixes are the suffixes of text that start at a word, e.g.
+// "kemeja flanel" and "flanel" for "kemeja flanel"
+func suggestSuffixes(text string) []string {
+	var suffixes []string
+	for i := 0; i < len(text); i++ {
+		if i == 0 || text[i-1] == ' ' {
+			suffix := []rune(text[i:])
+			if len(suffix) > maxSuggestKeyLength {
+				suffix = suffix[:maxSuggestKeyLength]
+			}
+			suffixes = append(suffixes, string(suffix))
+		}
+	}
+	return suffixes
+}
+
+// normalizeSuggestText lowercases text and keeps its words separated by
+// single spaces
+func normalizeSuggestText(text string) string {
+	return strings.Join(utils.SearchTokens(text), " ")
+}
+
+func suggestions(entries []*suggestEntry, limit int) []domain.Suggestion {
+	result := make([]domain.Suggestion, 0, min(len(entries), limit))
+	for _, entry := range entries {
+		if len(result) == limit {
+			break
+		}
+		result = append(result, domain.Suggestion{ID: entry.ID, Text: entry.Text, Slug: entry.Slug})
+	}
+	return result
+}
@@ -0,0 +1,65 @@
+package memory
+
+import (
+	"testing"
+
+	"go-commerce/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestSuggestIndex() domain.SearchSuggestIndex {
+	index := NewSearchSuggestIndex()
+	index.Load([]*domain.SuggestionEntry{
+		{Kind: domain.SuggestionProduct, ID: 1, Text: "Kemeja Flanel Pria", Slug: "kemeja-flanel-pria", Weight: 5},
+		{Kind: domain.SuggestionProduct, ID: 2, Text: "Kemeja Batik", Slug: "kemeja-batik", Weight: 50},
+		{Kind: domain.SuggestionProduct, ID: 3, Text: "Kaos Polos", Slug: "kaos-polos"},
+		{Kind: domain.SuggestionCategory, ID: 10, Text: "Kemeja", Slug: "kemeja"},
+		{Kind: domain.SuggestionStore, ID: 100, Text: "Kemeja Kita"},
+		{Kind: domain.SuggestionQuery, Text: "kemeja flanel", Weight: 30},
+		{Kind: domain.SuggestionQuery, Text: "kemeja batik", Weight: 10},
+	})
+	return index
+}
+
+func TestSearchSuggestIndex_CompletesAnyWord(t *testing.T) {
+	index := newTestSuggestIndex()
+
+	result := index.Suggest("flanel p", 5)
+
+	assert.Equal(t, []domain.Suggestion{{ID: 1, Text: "Kemeja Flanel Pria", Slug: "kemeja-flanel-pria"}}, result.Products)
+	assert.Empty(t, result.Categories)
+	assert.Empty(t, result.Stores)
+}
+
+func TestSearchSuggestIndex_RanksBySearchFrequency(t *testing.T) {
+	index := newTestSuggestIndex()
+
+	result := index.Suggest("Kem", 5)
+
+	// "kemeja flanel" was searched more often than "kemeja batik", although
+	// the batik shirt sells better
+	assert.Equal(t, []domain.Suggestion{{Text: "kemeja flanel"}, {Text: "kemeja batik"}}, result.Queries)
+	assert.Equal(t, []uint64{1, 2}, []uint64{result.Products[0].ID, result.Products[1].ID})
+	assert.Equal(t, []domain.Suggestion{{ID: 10, Text: "Kemeja", Slug: "kemeja"}}, result.Categories)
+	assert.Equal(t, []domain.Suggestion{{ID: 100, Text: "Kemeja Kita"}}, result.Stores)
+
+	// Searches change the ranking as they come in
+	index.RecordQuery("Kemeja  Batik", 25)
+	result = index.Suggest("kem", 1)
+	assert.Equal(t, []domain.Suggestion{{Text: "kemeja batik"}}, result.Queries)
+	assert.Equal(t, uint64(2), result.Products[0].ID)
+}
+
+func TestSearchSuggestIndex_PutAndRemove(t *testing.T) {
+	index := newTestSuggestIndex()
+
+	// Renaming replaces the old completions
+	index.Put(&domain.SuggestionEntry{Kind: domain.SuggestionProduct, ID: 3, Text: "Kaos Oversize"})
+	assert.Empty(t, index.Suggest("polos", 5).Products)
+	assert.Len(t, index.Suggest("over", 5).Products, 1)
+
+	index.Remove(domain.SuggestionProduct, 3)
+	assert.Empty(t, index.Suggest("ka", 5).Products)
+	assert.Len(t, index.Suggest("kemeja", 5).Products, 2)
+}
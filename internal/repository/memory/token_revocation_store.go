@@ -0,0 +1,81 @@
+// Package memory provides in-process implementations of domain stores. They
+// are meant for tests and single-instance development setups; state is lost
+// on restart and is not shared between app instances.
+package memory
+
+import (
+	"sync"
+	"time"
+
+	"go-commerce/internal/domain"
+)
+
+type tokenRevocationStore struct {
+	mu          sync.RWMutex
+	tokens      map[string]time.Time
+	revocations map[uint64]domain.UserTokenRevocation
+}
+
+func NewTokenRevocationStore() domain.TokenRevocationStore {
+	return &tokenRevocationStore{
+		tokens:      make(map[string]time.Time),
+		revocations: make(map[uint64]domain.UserTokenRevocation),
+	}
+}
+
+func (s *tokenRevocationStore) Revoke(tokenID string, userID uint64, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tokens[tokenID] = expiresAt
+	return nil
+}
+
+func (s *tokenRevocationStore) RevokeAllForUser(userID uint64, revokedBefore, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.revocations[userID] = domain.UserTokenRevocation{
+		UserID:        userID,
+		RevokedBefore: revokedBefore,
+		ExpiresAt:     expiresAt,
+		UpdatedAt:     time.Now(),
+	}
+	return nil
+}
+
+func (s *tokenRevocationStore) IsRevoked(tokenID string, userID uint64, issuedAt time.Time) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.tokens[tokenID]; ok && tokenID != "" {
+		return true, nil
+	}
+
+	if revocation, ok := s.revocations[userID]; ok {
+		return issuedAt.Before(revocation.RevokedBefore), nil
+	}
+
+	return false, nil
+}
+
+func (s *tokenRevocationStore) PurgeExpired(now time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var purged int64
+	for tokenID, expiresAt := range s.tokens {
+		if expiresAt.Before(now) {
+			delete(s.tokens, tokenID)
+			purged++
+		}
+	}
+	for userID, revocation := range s.revocations {
+		if revocation.ExpiresAt.Before(now) {
+			delete(s.revocations, userID)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
@@ -0,0 +1,63 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenRevocationStore_Revoke(t *testing.T) {
+	store := NewTokenRevocationStore()
+	now := time.Now()
+
+	assert.NoError(t, store.Revoke("token-1", 1, now.Add(time.Hour)))
+
+	revoked, err := store.IsRevoked("token-1", 1, now)
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+
+	revoked, err = store.IsRevoked("token-2", 1, now)
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+}
+
+func TestTokenRevocationStore_RevokeAllForUser(t *testing.T) {
+	store := NewTokenRevocationStore()
+	now := time.Now()
+
+	assert.NoError(t, store.RevokeAllForUser(1, now, now.Add(time.Hour)))
+
+	// Tokens issued before the cutoff are rejected
+	revoked, err := store.IsRevoked("old-token", 1, now.Add(-time.Minute))
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+
+	// Tokens issued after the cutoff are still valid
+	revoked, err = store.IsRevoked("new-token", 1, now.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+
+	// Other users are unaffected
+	revoked, err = store.IsRevoked("other-token", 2, now.Add(-time.Minute))
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+}
+
+func TestTokenRevocationStore_PurgeExpired(t *testing.T) {
+	store := NewTokenRevocationStore()
+	now := time.Now()
+
+	assert.NoError(t, store.Revoke("expired", 1, now.Add(-time.Minute)))
+	assert.NoError(t, store.Revoke("active", 1, now.Add(time.Hour)))
+	assert.NoError(t, store.RevokeAllForUser(2, now.Add(-time.Hour), now.Add(-time.Minute)))
+
+	purged, err := store.PurgeExpired(now)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), purged)
+
+	revoked, _ := store.IsRevoked("expired", 1, now)
+	assert.False(t, revoked)
+	revoked, _ = store.IsRevoked("active", 1, now)
+	assert.True(t, revoked)
+}
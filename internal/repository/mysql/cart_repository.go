@@ -0,0 +1,60 @@
+package mysql
+
+import (
+	"go-commerce/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type cartRepository struct {
+	db *gorm.DB
+}
+
+func NewCartRepository(db *gorm.DB) domain.CartRepository {
+	return &cartRepository{db: db}
+}
+
+func (r *cartRepository) GetOrCreateByUserID(userID uint64) (*domain.Cart, error) {
+	// The unique index on id_user keeps concurrent first requests from
+	// creating two carts for the same user
+	err := r.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&domain.Cart{UserID: userID}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var cart domain.Cart
+	err = r.db.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).Where("id_user = ?", userID).First(&cart).Error
+	if err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+func (r *cartRepository) SaveItem(cartID, productID, variantID uint64, quantity int) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id_cart"}, {Name: "id_produk"}, {Name: "id_varian"}},
+		DoUpdates: clause.AssignmentColumns([]string{"kuantitas", "updated_at"}),
+	}).Create(&domain.CartItem{CartID: cartID, ProductID: productID, VariantID: variantID, Quantity: quantity}).Error
+}
+
+func (r *cartRepository) RemoveItem(cartID, productID, variantID uint64) (bool, error) {
+	result := r.db.Where("id_cart = ? AND id_produk = ? AND id_varian = ?", cartID, productID, variantID).Delete(&domain.CartItem{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *cartRepository) Clear(cartID uint64) error {
+	return r.db.Where("id_cart = ?", cartID).Delete(&domain.CartItem{}).Error
+}
+
+func (r *cartRepository) ClearWithTx(dbTx interface{}, cartID uint64) (int64, error) {
+	gormTx := dbTx.(*gorm.DB)
+	result := gormTx.Where("id_cart = ?", cartID).Delete(&domain.CartItem{})
+	return result.RowsAffected, result.Error
+}
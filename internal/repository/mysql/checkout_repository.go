@@ -0,0 +1,97 @@
+package mysql
+
+import (
+	"time"
+
+	"go-commerce/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type checkoutRepository struct {
+	db *gorm.DB
+}
+
+func NewCheckoutRepository(db *gorm.DB) domain.CheckoutRepository {
+	return &checkoutRepository{db: db}
+}
+
+func (r *checkoutRepository) CreateWithTx(dbTx interface{}, checkout *domain.Checkout) error {
+	gormTx := dbTx.(*gorm.DB)
+	return gormTx.Create(checkout).Error
+}
+
+func (r *checkoutRepository) GetByID(id uint64) (*domain.Checkout, error) {
+	var checkout domain.Checkout
+	err := r.db.Preload("Alamat").
+		Preload("Transactions", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id ASC")
+		}).
+		Preload("Transactions.TransactionItems").
+		First(&checkout, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &checkout, nil
+}
+
+func (r *checkoutRepository) UpdateStatus(id uint64, status string) error {
+	return r.db.Model(&domain.Checkout{}).Where("id = ?", id).Updates(checkoutStatusUpdates(status)).Error
+}
+
+func (r *checkoutRepository) UpdateStatusWithTx(dbTx interface{}, id uint64, status string) error {
+	gormTx := dbTx.(*gorm.DB)
+	return gormTx.Model(&domain.Checkout{}).Where("id = ?", id).Updates(checkoutStatusUpdates(status)).Error
+}
+
+func (r *checkoutRepository) SubtractTotalWithTx(dbTx interface{}, id uint64, amount float64) error {
+	gormTx := dbTx.(*gorm.DB)
+	return gormTx.Model(&domain.Checkout{}).Where("id = ?", id).Update("harga_total", gorm.Expr("harga_total - ?", amount)).Error
+}
+
+func (r *checkoutRepository) HasPendingIntentWithTx(dbTx interface{}, id uint64) (bool, error) {
+	gormTx := dbTx.(*gorm.DB)
+	var intents []*domain.PaymentIntent
+	err := gormTx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("checkout_id = ? AND status = ?", id, domain.PaymentIntentStatusPending).
+		Find(&intents).Error
+	return len(intents) > 0, err
+}
+
+func (r *checkoutRepository) ExpirePendingIntentsWithTx(dbTx interface{}, id uint64) error {
+	gormTx := dbTx.(*gorm.DB)
+	return gormTx.Model(&domain.PaymentIntent{}).
+		Where("checkout_id = ? AND status = ?", id, domain.PaymentIntentStatusPending).
+		Update("status", domain.PaymentIntentStatusExpired).Error
+}
+
+func (r *checkoutRepository) TransitionStatusWithTx(dbTx interface{}, id uint64, from, to string) (bool, error) {
+	gormTx := dbTx.(*gorm.DB)
+	result := gormTx.Model(&domain.Checkout{}).
+		Where("id = ? AND status_pembayaran = ?", id, from).
+		Updates(checkoutStatusUpdates(to))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *checkoutRepository) MarkPaidWithTx(dbTx interface{}, id uint64, paidAt time.Time) (bool, error) {
+	gormTx := dbTx.(*gorm.DB)
+	result := gormTx.Model(&domain.Checkout{}).
+		Where("id = ? AND status_pembayaran = ?", id, "pending").
+		Updates(map[string]interface{}{"status_pembayaran": "paid", "paid_at": paidAt})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func checkoutStatusUpdates(status string) map[string]interface{} {
+	updates := map[string]interface{}{"status_pembayaran": status}
+	if status == "paid" {
+		updates["paid_at"] = time.Now()
+	}
+	return updates
+}
@@ -0,0 +1,67 @@
+package mysql
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"go-commerce/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// cursorColumn is the column a cursor sort order sorts by. Ties are broken by
+// id in the same direction, so every row has a distinct position.
+type cursorColumn struct {
+	name string
+	desc bool
+	// parse reads a cursor key back into a value of the column
+	parse func(key string) (interface{}, error)
+}
+
+func timeKey(key string) (interface{}, error) {
+	return time.Parse(time.RFC3339Nano, key)
+}
+
+func floatKey(key string) (interface{}, error) {
+	return strconv.ParseFloat(key, 64)
+}
+
+func intKey(key string) (interface{}, error) {
+	return strconv.ParseInt(key, 10, 64)
+}
+
+func stringKey(key string) (interface{}, error) {
+	return key, nil
+}
+
+// pageByCursor orders query for page and keeps the rows past its cursor,
+// reading one row more than the limit. Backward pages walk the order in
+// reverse, so the rows nearest to the cursor come first either way. Unlike
+// LIMIT/OFFSET the database seeks straight to the cursor, and rows inserted
+// or deleted meanwhile do not shift the pages.
+func pageByCursor(query *gorm.DB, columns map[string]cursorColumn, page *domain.CursorPage) (*gorm.DB, error) {
+	column, ok := columns[page.SortBy]
+	if !ok {
+		return nil, errors.New("INVALID_SORT_BY")
+	}
+
+	desc := column.desc
+	if page.Cursor != nil && page.Cursor.Backward {
+		desc = !desc
+	}
+	op, direction := ">", "ASC"
+	if desc {
+		op, direction = "<", "DESC"
+	}
+
+	if page.Cursor != nil {
+		key, err := column.parse(page.Cursor.Key)
+		if err != nil {
+			return nil, errors.New("INVALID_CURSOR")
+		}
+		query = query.Where("("+column.name+" "+op+" ? OR ("+column.name+" = ? AND id "+op+" ?))", key, key, page.Cursor.ID)
+	}
+
+	return query.Order(column.name + " " + direction + ", id " + direction).Limit(page.Limit + 1), nil
+}
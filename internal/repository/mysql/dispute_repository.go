@@ -0,0 +1,89 @@
+package mysql
+
+import (
+	"go-commerce/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type disputeRepository struct {
+	db *gorm.DB
+}
+
+func NewDisputeRepository(db *gorm.DB) domain.DisputeRepository {
+	return &disputeRepository{db: db}
+}
+
+func (r *disputeRepository) CreateWithTx(dbTx interface{}, dispute *domain.Dispute) error {
+	gormTx := dbTx.(*gorm.DB)
+	return gormTx.Create(dispute).Error
+}
+
+func (r *disputeRepository) GetByID(id uint64) (*domain.Dispute, error) {
+	var dispute domain.Dispute
+	err := r.db.Preload("Photos").First(&dispute, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &dispute, nil
+}
+
+func (r *disputeRepository) GetByIDWithLock(dbTx interface{}, id uint64) (*domain.Dispute, error) {
+	gormTx := dbTx.(*gorm.DB)
+	var dispute domain.Dispute
+	err := gormTx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&dispute, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &dispute, nil
+}
+
+func (r *disputeRepository) GetByTransactionID(transactionID uint64) (*domain.Dispute, error) {
+	var dispute domain.Dispute
+	err := r.db.Preload("Photos").Where("id_trx = ?", transactionID).First(&dispute).Error
+	if err != nil {
+		return nil, err
+	}
+	return &dispute, nil
+}
+
+func (r *disputeRepository) HasOpenWithTx(dbTx interface{}, transactionID uint64) (bool, error) {
+	gormTx := dbTx.(*gorm.DB)
+	var count int64
+	err := gormTx.Model(&domain.Dispute{}).
+		Where("id_trx = ? AND status = ?", transactionID, domain.DisputeStatusOpen).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// GetByStatus uses idx_disputes_status; the oldest disputes come first
+func (r *disputeRepository) GetByStatus(status string, limit, offset int) ([]*domain.Dispute, int64, error) {
+	var disputes []*domain.Dispute
+	var total int64
+
+	err := r.db.Model(&domain.Dispute{}).Where("status = ?", status).Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = r.db.Where("status = ?", status).
+		Preload("Photos").
+		Order("created_at ASC").
+		Limit(limit).Offset(offset).
+		Find(&disputes).Error
+
+	return disputes, total, err
+}
+
+func (r *disputeRepository) ResolveWithTx(dbTx interface{}, dispute *domain.Dispute) error {
+	gormTx := dbTx.(*gorm.DB)
+	return gormTx.Model(&domain.Dispute{}).Where("id = ?", dispute.ID).
+		Updates(map[string]interface{}{
+			"status":          dispute.Status,
+			"resolution_note": dispute.ResolutionNote,
+			"id_refund":       dispute.RefundID,
+			"resolved_by":     dispute.ResolvedBy,
+			"resolved_at":     dispute.ResolvedAt,
+		}).Error
+}
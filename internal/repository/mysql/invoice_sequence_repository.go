@@ -0,0 +1,35 @@
+package mysql
+
+import (
+	"time"
+
+	"go-commerce/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type invoiceSequenceRepository struct {
+	db *gorm.DB
+}
+
+func NewInvoiceSequenceRepository(db *gorm.DB) domain.InvoiceSequenceRepository {
+	return &invoiceSequenceRepository{db: db}
+}
+
+// Next bumps the counter with LAST_INSERT_ID(expr), which MySQL keeps per
+// connection, so the increment and the read are pinned to one connection
+func (r *invoiceSequenceRepository) Next(name string, day time.Time, storeID uint64) (int64, error) {
+	var seq int64
+	err := r.db.Connection(func(conn *gorm.DB) error {
+		err := conn.Exec(
+			"INSERT INTO invoice_sequences (name, day, id_toko, last_seq) VALUES (?, ?, ?, LAST_INSERT_ID(1)) "+
+				"ON DUPLICATE KEY UPDATE last_seq = LAST_INSERT_ID(last_seq + 1)",
+			name, day.Format("2006-01-02"), storeID,
+		).Error
+		if err != nil {
+			return err
+		}
+		return conn.Raw("SELECT LAST_INSERT_ID()").Scan(&seq).Error
+	})
+	return seq, err
+}
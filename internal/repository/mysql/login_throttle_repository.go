@@ -0,0 +1,60 @@
+package mysql
+
+import (
+	"time"
+
+	"go-commerce/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type loginThrottleRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginThrottleRepository(db *gorm.DB) domain.LoginThrottleRepository {
+	return &loginThrottleRepository{db: db}
+}
+
+func (r *loginThrottleRepository) Get(scope, subject string) (*domain.LoginThrottle, error) {
+	var throttle domain.LoginThrottle
+	err := r.db.Where("scope = ? AND subject = ?", scope, subject).First(&throttle).Error
+	if err != nil {
+		return nil, err
+	}
+	return &throttle, nil
+}
+
+// RegisterFailure upserts the counter in a single statement so concurrent
+// failures from several instances are all counted. failed_count is assigned
+// before last_failed_at, so the condition still sees the previous failure.
+func (r *loginThrottleRepository) RegisterFailure(scope, subject string, now time.Time, window time.Duration) (*domain.LoginThrottle, error) {
+	staleBefore := now.Add(-window)
+	err := r.db.Exec(`INSERT INTO login_throttles (scope, subject, failed_count, last_failed_at)
+		VALUES (?, ?, 1, ?)
+		ON DUPLICATE KEY UPDATE
+			failed_count = IF(last_failed_at < ? AND (locked_until IS NULL OR locked_until < ?), 1, failed_count + 1),
+			last_failed_at = VALUES(last_failed_at)`,
+		scope, subject, now, staleBefore, staleBefore).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.Get(scope, subject)
+}
+
+func (r *loginThrottleRepository) Lock(scope, subject string, until time.Time) error {
+	return r.db.Model(&domain.LoginThrottle{}).
+		Where("scope = ? AND subject = ?", scope, subject).
+		Update("locked_until", until).Error
+}
+
+func (r *loginThrottleRepository) Reset(scope, subject string) error {
+	return r.db.Where("scope = ? AND subject = ?", scope, subject).Delete(&domain.LoginThrottle{}).Error
+}
+
+// DeleteStale removes counters whose last failure and lockout ended before the cutoff
+func (r *loginThrottleRepository) DeleteStale(before time.Time) (int64, error) {
+	result := r.db.Where("last_failed_at < ? AND (locked_until IS NULL OR locked_until < ?)", before, before).
+		Delete(&domain.LoginThrottle{})
+	return result.RowsAffected, result.Error
+}
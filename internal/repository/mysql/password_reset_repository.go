@@ -0,0 +1,53 @@
+package mysql
+
+import (
+	"time"
+
+	"go-commerce/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type passwordResetRepository struct {
+	db *gorm.DB
+}
+
+func NewPasswordResetRepository(db *gorm.DB) domain.PasswordResetRepository {
+	return &passwordResetRepository{db: db}
+}
+
+func (r *passwordResetRepository) Create(token *domain.PasswordResetToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *passwordResetRepository) GetByTokenHash(tokenHash string) (*domain.PasswordResetToken, error) {
+	var token domain.PasswordResetToken
+	err := r.db.Where("token_hash = ?", tokenHash).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkUsed uses a conditional update so two concurrent resets with the same
+// token cannot both succeed
+func (r *passwordResetRepository) MarkUsed(id uint64, usedAt time.Time) (bool, error) {
+	result := r.db.Model(&domain.PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", usedAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *passwordResetRepository) InvalidateByUserID(userID uint64, usedAt time.Time) error {
+	return r.db.Model(&domain.PasswordResetToken{}).
+		Where("id_user = ? AND used_at IS NULL", userID).
+		Update("used_at", usedAt).Error
+}
+
+func (r *passwordResetRepository) DeleteExpired(before time.Time) (int64, error) {
+	result := r.db.Where("expires_at < ?", before).Delete(&domain.PasswordResetToken{})
+	return result.RowsAffected, result.Error
+}
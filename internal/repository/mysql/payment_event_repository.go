@@ -0,0 +1,44 @@
+package mysql
+
+import (
+	"go-commerce/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type paymentEventRepository struct {
+	db *gorm.DB
+}
+
+func NewPaymentEventRepository(db *gorm.DB) domain.PaymentEventRepository {
+	return &paymentEventRepository{db: db}
+}
+
+func (r *paymentEventRepository) Create(event *domain.PaymentEvent) error {
+	return r.db.Create(event).Error
+}
+
+func (r *paymentEventRepository) Claim(event *domain.PaymentEvent) (bool, error) {
+	// uniq_payment_events_claimed_ref makes the claim atomic across instances
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(event)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *paymentEventRepository) UpdateOutcome(id uint64, outcome, message string) error {
+	return r.db.Model(&domain.PaymentEvent{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"outcome": outcome,
+		"message": message,
+	}).Error
+}
+
+func (r *paymentEventRepository) ReleaseClaim(id uint64, message string) error {
+	return r.db.Model(&domain.PaymentEvent{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"outcome":     domain.PaymentEventFailed,
+		"message":     message,
+		"claimed_ref": nil,
+	}).Error
+}
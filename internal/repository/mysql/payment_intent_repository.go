@@ -2,7 +2,10 @@ package mysql
 
 import (
 	"go-commerce/internal/domain"
+	"time"
+
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type paymentIntentRepository struct {
@@ -19,16 +22,17 @@ func (r *paymentIntentRepository) Create(intent *domain.PaymentIntent) error {
 
 func (r *paymentIntentRepository) GetByID(id uint) (*domain.PaymentIntent, error) {
 	var intent domain.PaymentIntent
-	err := r.db.Preload("Transaction").First(&intent, id).Error
+	err := r.db.Preload("Checkout").First(&intent, id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &intent, nil
 }
 
-func (r *paymentIntentRepository) GetByTrxID(trxID uint) (*domain.PaymentIntent, error) {
+func (r *paymentIntentRepository) GetByCheckoutID(checkoutID uint) (*domain.PaymentIntent, error) {
 	var intent domain.PaymentIntent
-	err := r.db.Where("trx_id = ?", trxID).Preload("Transaction").First(&intent).Error
+	// The latest intent, e.g. the retry after a provider failed to issue a charge
+	err := r.db.Where("checkout_id = ?", checkoutID).Preload("Checkout").Order("id DESC").First(&intent).Error
 	if err != nil {
 		return nil, err
 	}
@@ -39,6 +43,44 @@ func (r *paymentIntentRepository) UpdateStatus(id uint, status domain.PaymentInt
 	return r.db.Model(&domain.PaymentIntent{}).Where("id = ?", id).Update("status", status).Error
 }
 
-func (r *paymentIntentRepository) ExpireByTrxID(trxID uint) error {
-	return r.db.Model(&domain.PaymentIntent{}).Where("trx_id = ? AND status = ?", trxID, domain.PaymentIntentStatusPending).Update("status", "expired").Error
+func (r *paymentIntentRepository) AttachCharge(id uint, charge *domain.Charge) error {
+	return r.db.Model(&domain.PaymentIntent{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"provider":        charge.Provider,
+		"provider_ref":    charge.Reference,
+		"virtual_account": charge.VirtualAccount,
+		"qr_payload":      charge.QRPayload,
+		"payment_url":     charge.PaymentURL,
+	}).Error
+}
+
+func (r *paymentIntentRepository) TransitionStatus(id uint, from, to domain.PaymentIntentStatus) (bool, error) {
+	return r.TransitionStatusWithTx(r.db, id, from, to)
+}
+
+func (r *paymentIntentRepository) TransitionStatusWithTx(dbTx interface{}, id uint, from, to domain.PaymentIntentStatus) (bool, error) {
+	gormTx := dbTx.(*gorm.DB)
+	result := gormTx.Model(&domain.PaymentIntent{}).
+		Where("id = ? AND status = ?", id, from).
+		Update("status", to)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GetExpiredPendingWithLock uses idx_payment_intents_expired_at; SKIP LOCKED
+// lets several instances sweep at once without waiting on each other
+func (r *paymentIntentRepository) GetExpiredPendingWithLock(dbTx interface{}, now time.Time, limit int) ([]*domain.PaymentIntent, error) {
+	gormTx := dbTx.(*gorm.DB)
+	var intents []*domain.PaymentIntent
+	err := gormTx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("expired_at <= ? AND status = ?", now, domain.PaymentIntentStatusPending).
+		Order("expired_at ASC").
+		Limit(limit).
+		Find(&intents).Error
+	return intents, err
+}
+
+func (r *paymentIntentRepository) ExpireByCheckoutID(checkoutID uint) error {
+	return r.db.Model(&domain.PaymentIntent{}).Where("checkout_id = ? AND status = ?", checkoutID, domain.PaymentIntentStatusPending).Update("status", domain.PaymentIntentStatusExpired).Error
 }
\ No newline at end of file
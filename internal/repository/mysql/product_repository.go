@@ -6,6 +6,7 @@ import (
 
 	"go-commerce/internal/domain"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type productRepository struct {
@@ -24,7 +25,7 @@ func (r *productRepository) GetByID(id uint64) (*domain.Product, error) {
 	var product domain.Product
 	err := r.db.Preload("Toko").Preload("Category").Preload("Photos", func(db *gorm.DB) *gorm.DB {
 		return db.Order("is_primary DESC, position ASC")
-	}).Where("id = ? AND status = ?", id, "active").First(&product).Error
+	}).Scopes(withVariants).Where("id = ? AND status = ?", id, "active").First(&product).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("product not found")
@@ -38,7 +39,7 @@ func (r *productRepository) GetBySlug(slug string) (*domain.Product, error) {
 	var product domain.Product
 	err := r.db.Preload("Toko").Preload("Category").Preload("Photos", func(db *gorm.DB) *gorm.DB {
 		return db.Order("is_primary DESC, position ASC")
-	}).Where("slug = ? AND status = ?", slug, "active").First(&product).Error
+	}).Scopes(withVariants).Where("slug = ? AND status = ?", slug, "active").First(&product).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("product not found")
@@ -109,26 +110,7 @@ func (r *productRepository) GetAllWithFilter(filter *domain.ProductFilter) ([]*d
 	var products []*domain.Product
 	var total int64
 
-	query := r.db.Model(&domain.Product{}).Where("status = ?", "active")
-
-	// Search filter (uses nama_produk and deskripsi)
-	if filter.Search != "" {
-		searchPattern := "%" + strings.ToLower(filter.Search) + "%"
-		query = query.Where("LOWER(nama_produk) LIKE ? OR LOWER(deskripsi) LIKE ?", searchPattern, searchPattern)
-	}
-
-	// Category filter (uses idx_produk_category index)
-	if filter.CategoryID != "" {
-		query = query.Where("id_category = ?", filter.CategoryID)
-	}
-
-	// Price range filter (uses idx_produk_harga_konsumen index)
-	if filter.MinPrice != "" {
-		query = query.Where("harga_konsumen >= ?", filter.MinPrice)
-	}
-	if filter.MaxPrice != "" {
-		query = query.Where("harga_konsumen <= ?", filter.MaxPrice)
-	}
+	query := r.db.Model(&domain.Product{}).Scopes(filterProducts(filter))
 
 	// Count total
 	if err := query.Count(&total).Error; err != nil {
@@ -148,6 +130,8 @@ func (r *productRepository) GetAllWithFilter(filter *domain.ProductFilter) ([]*d
 		orderBy = "created_at ASC"
 	case "popular":
 		orderBy = "sold_count DESC" // most sold first
+	case "rating":
+		orderBy = "rating DESC, rating_count DESC" // uses idx_produk_rating
 	case "name_asc":
 		orderBy = "nama_produk ASC"
 	case "name_desc":
@@ -167,13 +151,103 @@ func (r *productRepository) GetAllWithFilter(filter *domain.ProductFilter) ([]*d
 	return products, total, err
 }
 
+// productCursorColumns are the sort orders product lists can be paged in by
+// cursor
+var productCursorColumns = map[string]cursorColumn{
+	"newest":     {name: "created_at", desc: true, parse: timeKey},
+	"oldest":     {name: "created_at", parse: timeKey},
+	"price_asc":  {name: "harga_konsumen", parse: floatKey},
+	"price_desc": {name: "harga_konsumen", desc: true, parse: floatKey},
+	"popular":    {name: "sold_count", desc: true, parse: intKey},
+	"rating":     {name: "rating", desc: true, parse: floatKey},
+	"name_asc":   {name: "nama_produk", parse: stringKey},
+	"name_desc":  {name: "nama_produk", desc: true, parse: stringKey},
+}
+
+func (r *productRepository) GetAllWithCursor(filter *domain.ProductFilter, page *domain.CursorPage) ([]*domain.Product, error) {
+	query, err := pageByCursor(r.db.Model(&domain.Product{}).Scopes(filterProducts(filter)), productCursorColumns, page)
+	if err != nil {
+		return nil, err
+	}
+
+	var products []*domain.Product
+	err = query.Preload("Toko").Preload("Category").Preload("Photos", func(db *gorm.DB) *gorm.DB {
+		return db.Order("is_primary DESC, position ASC")
+	}).Find(&products).Error
+
+	return products, err
+}
+
+func (r *productRepository) GetByTokoIDWithCursor(tokoID uint64, search string, page *domain.CursorPage) ([]*domain.Product, error) {
+	query := r.db.Model(&domain.Product{}).Where("id_toko = ?", tokoID)
+
+	if search != "" {
+		searchPattern := "%" + strings.ToLower(search) + "%"
+		query = query.Where("LOWER(nama_produk) LIKE ? OR LOWER(deskripsi) LIKE ? OR LOWER(slug) LIKE ?", searchPattern, searchPattern, searchPattern)
+	}
+
+	query, err := pageByCursor(query, productCursorColumns, page)
+	if err != nil {
+		return nil, err
+	}
+
+	var products []*domain.Product
+	err = query.Preload("Category").Preload("Photos", func(db *gorm.DB) *gorm.DB {
+		return db.Order("is_primary DESC, position ASC")
+	}).Find(&products).Error
+
+	return products, err
+}
+
+// filterProducts keeps the active products matching the search, category,
+// store, price and rating fields of filter
+func filterProducts(filter *domain.ProductFilter) func(*gorm.DB) *gorm.DB {
+	return func(query *gorm.DB) *gorm.DB {
+		query = query.Where("status = ?", "active")
+
+		// Search filter (uses nama_produk and deskripsi)
+		if filter.Search != "" {
+			searchPattern := "%" + strings.ToLower(filter.Search) + "%"
+			query = query.Where("LOWER(nama_produk) LIKE ? OR LOWER(deskripsi) LIKE ?", searchPattern, searchPattern)
+		}
+
+		// Category filter (uses idx_produk_category index)
+		if filter.CategoryID != "" {
+			query = query.Where("id_category = ?", filter.CategoryID)
+		}
+
+		// Store filter (uses idx_produk_toko index)
+		if filter.StoreID != "" {
+			query = query.Where("id_toko = ?", filter.StoreID)
+		}
+
+		// Price range filter (uses idx_produk_harga_konsumen index)
+		if filter.MinPrice != "" {
+			query = query.Where("harga_konsumen >= ?", filter.MinPrice)
+		}
+		if filter.MaxPrice != "" {
+			query = query.Where("harga_konsumen <= ?", filter.MaxPrice)
+		}
+
+		// Rating filter (uses idx_produk_rating index)
+		if filter.MinRating != "" {
+			query = query.Where("rating >= ?", filter.MinRating)
+		}
+
+		return query
+	}
+}
+
 func (r *productRepository) Update(product *domain.Product) error {
 	return r.db.Save(product).Error
 }
 
-func (r *productRepository) UpdateStockWithTx(dbTx interface{}, productID uint64, quantity int) error {
-	gormTx := dbTx.(*gorm.DB)
-	return gormTx.Model(&domain.Product{}).Where("id = ?", productID).Update("stok", gorm.Expr("stok - ?", quantity)).Error
+func (r *productRepository) UpdateStockWithTx(dbTx interface{}, productID, variantID uint64, quantity int) error {
+	return stockRow(dbTx.(*gorm.DB), productID, variantID).Update("stok", gorm.Expr("stok - ?", quantity)).Error
+}
+
+func (r *productRepository) UpdateHeldStockWithTx(dbTx interface{}, productID, variantID uint64, quantity int) error {
+	return stockRow(dbTx.(*gorm.DB), productID, variantID).Update("stok_ditahan", gorm.Expr("stok_ditahan + ?", quantity)).Error
 }
 
 func (r *productRepository) UpdateSoldCountWithTx(dbTx interface{}, productID uint64, quantity int) error {
@@ -181,14 +255,38 @@ func (r *productRepository) UpdateSoldCountWithTx(dbTx interface{}, productID ui
 	return gormTx.Model(&domain.Product{}).Where("id = ?", productID).Update("sold_count", gorm.Expr("sold_count + ?", quantity)).Error
 }
 
-func (r *productRepository) GetStockWithLock(dbTx interface{}, productID uint64) (int, error) {
+// AddRatingWithTx relies on MySQL assigning left to right, so the average is
+// computed from the updated sum and count
+func (r *productRepository) AddRatingWithTx(dbTx interface{}, productID uint64, rating, count int) error {
 	gormTx := dbTx.(*gorm.DB)
-	var product domain.Product
-	err := gormTx.Select("stok").Where("id = ?", productID).Set("gorm:query_option", "FOR UPDATE").First(&product).Error
+	return gormTx.Exec(
+		`UPDATE produk SET rating_sum = rating_sum + ?, rating_count = rating_count + ?,
+			rating = IF(rating_count > 0, rating_sum / rating_count, 0) WHERE id = ?`,
+		rating, count, productID,
+	).Error
+}
+
+func (r *productRepository) GetStockWithLock(dbTx interface{}, productID, variantID uint64) (int, error) {
+	var stock struct {
+		Stok        int
+		StokDitahan int
+	}
+	err := stockRow(dbTx.(*gorm.DB), productID, variantID).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("stok", "stok_ditahan").Take(&stock).Error
 	if err != nil {
 		return 0, err
 	}
-	return product.Stok, nil
+	return stock.Stok - stock.StokDitahan, nil
+}
+
+// stockRow scopes a query to the row that keeps the stock: the variant when
+// variantID is set, the product otherwise. Deleted variants are included so
+// their holds can still be settled.
+func stockRow(gormTx *gorm.DB, productID, variantID uint64) *gorm.DB {
+	if variantID != 0 {
+		return gormTx.Unscoped().Model(&domain.ProductVariant{}).Where("id = ? AND id_produk = ?", variantID, productID)
+	}
+	return gormTx.Model(&domain.Product{}).Where("id = ?", productID)
 }
 
 func (r *productRepository) Delete(id uint64) error {
@@ -264,6 +362,34 @@ func (r *photoProdukRepository) SetPrimary(productID, photoID uint64) error {
 }
 
 // GetByStatus gets products by status (uses idx_produk_status index)
+func (r *productRepository) GetByIDs(ids []uint64) ([]*domain.Product, error) {
+	if len(ids) == 0 {
+		return []*domain.Product{}, nil
+	}
+
+	var products []*domain.Product
+	err := r.db.Preload("Toko").Preload("Category").Preload("Photos", func(db *gorm.DB) *gorm.DB {
+		return db.Order("is_primary DESC, position ASC")
+	}).Where("id IN ? AND status = ?", ids, "active").Find(&products).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Restore the order of ids, e.g. search relevance
+	byID := make(map[uint64]*domain.Product, len(products))
+	for _, product := range products {
+		byID[product.ID] = product
+	}
+	ordered := make([]*domain.Product, 0, len(products))
+	for _, id := range ids {
+		if product, ok := byID[id]; ok {
+			ordered = append(ordered, product)
+		}
+	}
+
+	return ordered, nil
+}
+
 func (r *productRepository) GetByStatus(status string, limit, offset int) ([]*domain.Product, int64, error) {
 	var products []*domain.Product
 	var total int64
@@ -313,7 +439,7 @@ func (r *productRepository) GetByIDForManagement(id uint64) (*domain.Product, er
 	var product domain.Product
 	err := r.db.Preload("Toko").Preload("Category").Preload("Photos", func(db *gorm.DB) *gorm.DB {
 		return db.Order("is_primary DESC, position ASC")
-	}).Where("id = ?", id).First(&product).Error
+	}).Scopes(withVariants).Where("id = ?", id).First(&product).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("product not found")
@@ -321,4 +447,12 @@ func (r *productRepository) GetByIDForManagement(id uint64) (*domain.Product, er
 		return nil, err
 	}
 	return &product, nil
-}
\ No newline at end of file
+}
+// withVariants loads the options and variants of a single product
+func withVariants(db *gorm.DB) *gorm.DB {
+	return db.Preload("Options", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position ASC")
+	}).Preload("Variants", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).Preload("Variants.Photo")
+}
@@ -0,0 +1,212 @@
+package mysql
+
+import (
+	"strings"
+
+	"go-commerce/internal/domain"
+	"go-commerce/pkg/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// minFulltextTermLength matches InnoDB's default innodb_ft_min_token_size;
+// shorter words are not in the full-text index
+const minFulltextTermLength = 3
+
+// productSearchIndex searches the FULLTEXT indexes on produk. MySQL keeps
+// them up to date, so Index and Remove have nothing to do. Typo tolerance is
+// limited to word endings: every term also matches by prefix, and terms of 5
+// or more letters also match without their last letter.
+type productSearchIndex struct {
+	db *gorm.DB
+}
+
+func NewProductSearchIndex(db *gorm.DB) domain.ProductSearchIndex {
+	return &productSearchIndex{db: db}
+}
+
+func (r *productSearchIndex) Index(product *domain.Product) error {
+	return nil
+}
+
+func (r *productSearchIndex) Remove(productID uint64) error {
+	return nil
+}
+
+func (r *productSearchIndex) Search(query *domain.ProductSearchQuery) (*domain.ProductSearchResult, error) {
+	against := booleanQuery(utils.SearchTerms(query.Query))
+
+	base := func() *gorm.DB {
+		q := r.db.Model(&domain.Product{}).Where("produk.status = ?", "active")
+		if against != "" {
+			q = q.Where("MATCH(produk.nama_produk, produk.deskripsi) AGAINST (? IN BOOLEAN MODE)", against)
+		}
+		if query.CategoryID != 0 {
+			q = q.Where("produk.id_category = ?", query.CategoryID)
+		}
+		if query.StoreID != 0 {
+			q = q.Where("produk.id_toko = ?", query.StoreID)
+		}
+		if query.MinPrice != nil {
+			q = q.Where("produk.harga_konsumen >= ?", *query.MinPrice)
+		}
+		if query.MaxPrice != nil {
+			q = q.Where("produk.harga_konsumen <= ?", *query.MaxPrice)
+		}
+		if query.MinRating != nil {
+			q = q.Where("produk.rating >= ?", *query.MinRating)
+		}
+		return q
+	}
+
+	result := &domain.ProductSearchResult{ProductIDs: []uint64{}}
+	if err := base().Count(&result.Total).Error; err != nil {
+		return nil, err
+	}
+
+	err := base().Order(searchOrder(query.SortBy, against)).
+		Limit(query.Limit).Offset(query.Offset).
+		Pluck("produk.id", &result.ProductIDs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result.Facets, err = r.facets(base)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *productSearchIndex) facets(base func() *gorm.DB) (*domain.ProductSearchFacets, error) {
+	type facetRow struct {
+		Value string
+		Label string
+		Count int64
+	}
+
+	var categories []facetRow
+	err := base().Select("produk.id_category AS value, categories.nama_category AS label, COUNT(*) AS count").
+		Joins("JOIN categories ON categories.id = produk.id_category").
+		Group("produk.id_category, categories.nama_category").
+		Order("count DESC, value ASC").
+		Scan(&categories).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var stores []facetRow
+	err = base().Select("produk.id_toko AS value, toko.nama_toko AS label, COUNT(*) AS count").
+		Joins("JOIN toko ON toko.id = produk.id_toko").
+		Group("produk.id_toko, toko.nama_toko").
+		Order("count DESC, value ASC").
+		Scan(&stores).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Buckets are contiguous from 0, so the first upper bound above the
+	// price picks the bucket
+	var bucketSQL strings.Builder
+	var bucketVars []interface{}
+	bucketSQL.WriteString("CASE")
+	for _, bucket := range domain.PriceBuckets {
+		if bucket.Max > 0 {
+			bucketSQL.WriteString(" WHEN produk.harga_konsumen < ? THEN ?")
+			bucketVars = append(bucketVars, bucket.Max, bucket.Key())
+		} else {
+			bucketSQL.WriteString(" ELSE ?")
+			bucketVars = append(bucketVars, bucket.Key())
+		}
+	}
+	bucketSQL.WriteString(" END")
+
+	var prices []facetRow
+	err = base().Select(bucketSQL.String()+" AS value, COUNT(*) AS count", bucketVars...).
+		Group("value").
+		Scan(&prices).Error
+	if err != nil {
+		return nil, err
+	}
+
+	toCounts := func(rows []facetRow) []domain.FacetCount {
+		counts := make([]domain.FacetCount, 0, len(rows))
+		for _, row := range rows {
+			counts = append(counts, domain.FacetCount{Value: row.Value, Label: row.Label, Count: row.Count})
+		}
+		return counts
+	}
+
+	// Price ranges are listed in bucket order rather than by count
+	priceCounts := make(map[string]int64, len(prices))
+	for _, row := range prices {
+		priceCounts[row.Value] = row.Count
+	}
+	priceRanges := []domain.FacetCount{}
+	for _, bucket := range domain.PriceBuckets {
+		if count := priceCounts[bucket.Key()]; count > 0 {
+			priceRanges = append(priceRanges, domain.FacetCount{Value: bucket.Key(), Count: count})
+		}
+	}
+
+	return &domain.ProductSearchFacets{
+		Categories:  toCounts(categories),
+		PriceRanges: priceRanges,
+		Stores:      toCounts(stores),
+	}, nil
+}
+
+// booleanQuery requires every term in BOOLEAN MODE syntax, e.g.
+// "+(>kemeja* <kemej*) +pria*". Terms are letters and digits only, so they
+// cannot inject operators.
+func booleanQuery(terms []string) string {
+	var parts []string
+	for _, term := range terms {
+		runes := []rune(term)
+		if len(runes) < minFulltextTermLength {
+			continue
+		}
+		if len(runes) >= 5 {
+			parts = append(parts, "+(>"+term+"* <"+string(runes[:len(runes)-1])+"*)")
+		} else {
+			parts = append(parts, "+"+term+"*")
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// searchOrder sorts by relevance when there is a full-text query, with name
+// matches weighted above description matches, and by the same orders as
+// GetAllWithFilter otherwise
+func searchOrder(sortBy, against string) interface{} {
+	if sortBy == "" || sortBy == "relevance" {
+		if against == "" {
+			return "produk.created_at DESC, produk.id DESC"
+		}
+		return clause.OrderBy{Expression: clause.Expr{
+			SQL: "MATCH(produk.nama_produk) AGAINST (? IN BOOLEAN MODE) * 3 + " +
+				"MATCH(produk.nama_produk, produk.deskripsi) AGAINST (? IN BOOLEAN MODE) DESC, produk.sold_count DESC, produk.id DESC",
+			Vars: []interface{}{against, against},
+		}}
+	}
+
+	switch sortBy {
+	case "price_asc":
+		return "produk.harga_konsumen ASC, produk.id DESC"
+	case "price_desc":
+		return "produk.harga_konsumen DESC, produk.id DESC"
+	case "oldest":
+		return "produk.created_at ASC, produk.id ASC"
+	case "popular":
+		return "produk.sold_count DESC, produk.id DESC"
+	case "rating":
+		return "produk.rating DESC, produk.rating_count DESC, produk.id DESC"
+	case "name_asc":
+		return "produk.nama_produk ASC, produk.id DESC"
+	case "name_desc":
+		return "produk.nama_produk DESC, produk.id DESC"
+	}
+	return "produk.created_at DESC, produk.id DESC"
+}
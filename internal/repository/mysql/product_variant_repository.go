@@ -0,0 +1,82 @@
+package mysql
+
+import (
+	"errors"
+
+	"go-commerce/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type productVariantRepository struct {
+	db *gorm.DB
+}
+
+func NewProductVariantRepository(db *gorm.DB) domain.ProductVariantRepository {
+	return &productVariantRepository{db: db}
+}
+
+func (r *productVariantRepository) ReplaceOptions(productID uint64, options []*domain.ProductOption) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id_produk = ?", productID).Delete(&domain.ProductOption{}).Error; err != nil {
+			return err
+		}
+		if len(options) == 0 {
+			return nil
+		}
+		return tx.Create(&options).Error
+	})
+}
+
+func (r *productVariantRepository) GetOptionsByProductID(productID uint64) ([]*domain.ProductOption, error) {
+	var options []*domain.ProductOption
+	err := r.db.Where("id_produk = ?", productID).Order("position ASC").Find(&options).Error
+	return options, err
+}
+
+func (r *productVariantRepository) Create(variant *domain.ProductVariant) error {
+	return r.db.Create(variant).Error
+}
+
+func (r *productVariantRepository) GetByID(id uint64) (*domain.ProductVariant, error) {
+	var variant domain.ProductVariant
+	err := r.db.Preload("Photo").First(&variant, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("variant not found")
+		}
+		return nil, err
+	}
+	return &variant, nil
+}
+
+func (r *productVariantRepository) GetByProductID(productID uint64) ([]*domain.ProductVariant, error) {
+	var variants []*domain.ProductVariant
+	err := r.db.Preload("Photo").Where("id_produk = ?", productID).Order("id ASC").Find(&variants).Error
+	return variants, err
+}
+
+// GetBySKU uses the unique idx_varian_produk_sku index
+func (r *productVariantRepository) GetBySKU(sku string) (*domain.ProductVariant, error) {
+	var variant domain.ProductVariant
+	err := r.db.Where("sku = ?", sku).First(&variant).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("variant not found")
+		}
+		return nil, err
+	}
+	return &variant, nil
+}
+
+// Update never writes the held stock, which only changes under the row locks
+// of the stock methods of the product repository
+func (r *productVariantRepository) Update(variant *domain.ProductVariant) error {
+	return r.db.Model(variant).Select(
+		"sku", "harga_reseller", "harga_konsumen", "stok", "berat", "id_foto", "status", "updated_at",
+	).Updates(variant).Error
+}
+
+func (r *productVariantRepository) Delete(id uint64) error {
+	return r.db.Delete(&domain.ProductVariant{}, id).Error
+}
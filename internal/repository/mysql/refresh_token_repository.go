@@ -0,0 +1,63 @@
+package mysql
+
+import (
+	"time"
+
+	"go-commerce/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type refreshTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewRefreshTokenRepository(db *gorm.DB) domain.RefreshTokenRepository {
+	return &refreshTokenRepository{db: db}
+}
+
+func (r *refreshTokenRepository) Create(token *domain.RefreshToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *refreshTokenRepository) GetByTokenID(tokenID string) (*domain.RefreshToken, error) {
+	var token domain.RefreshToken
+	err := r.db.Where("jti = ?", tokenID).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkRotated uses a conditional update so two concurrent refreshes with the
+// same token cannot both succeed
+func (r *refreshTokenRepository) MarkRotated(tokenID, replacedBy string) (bool, error) {
+	result := r.db.Model(&domain.RefreshToken{}).
+		Where("jti = ? AND rotated_at IS NULL AND revoked_at IS NULL", tokenID).
+		Updates(map[string]interface{}{
+			"rotated_at":  time.Now(),
+			"replaced_by": replacedBy,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *refreshTokenRepository) RevokeFamily(familyID string) error {
+	return r.db.Model(&domain.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *refreshTokenRepository) RevokeByUserID(userID uint64) error {
+	return r.db.Model(&domain.RefreshToken{}).
+		Where("id_user = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// DeleteExpired purges refresh tokens that can no longer be exchanged
+func (r *refreshTokenRepository) DeleteExpired(before time.Time) (int64, error) {
+	result := r.db.Where("expires_at < ?", before).Delete(&domain.RefreshToken{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,48 @@
+package mysql
+
+import (
+	"go-commerce/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type refundRepository struct {
+	db *gorm.DB
+}
+
+func NewRefundRepository(db *gorm.DB) domain.RefundRepository {
+	return &refundRepository{db: db}
+}
+
+func (r *refundRepository) CreateWithTx(dbTx interface{}, refund *domain.Refund) error {
+	gormTx := dbTx.(*gorm.DB)
+	// Creating the refund also creates its items
+	return gormTx.Create(refund).Error
+}
+
+func (r *refundRepository) GetByID(id uint64) (*domain.Refund, error) {
+	var refund domain.Refund
+	err := r.db.Preload("Items").First(&refund, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &refund, nil
+}
+
+func (r *refundRepository) GetByTransactionID(transactionID uint64) ([]*domain.Refund, error) {
+	var refunds []*domain.Refund
+	// Uses idx_refunds_trx index
+	err := r.db.Where("id_trx = ?", transactionID).
+		Preload("Items").
+		Order("id ASC").
+		Find(&refunds).Error
+	return refunds, err
+}
+
+func (r *refundRepository) UpdateStatus(id uint64, status domain.RefundStatus, providerRef string) error {
+	updates := map[string]interface{}{"status": status}
+	if providerRef != "" {
+		updates["provider_ref"] = providerRef
+	}
+	return r.db.Model(&domain.Refund{}).Where("id = ?", id).Updates(updates).Error
+}
@@ -0,0 +1,80 @@
+package mysql
+
+import (
+	"go-commerce/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type reviewRepository struct {
+	db *gorm.DB
+}
+
+func NewReviewRepository(db *gorm.DB) domain.ReviewRepository {
+	return &reviewRepository{db: db}
+}
+
+func (r *reviewRepository) CreateWithTx(dbTx interface{}, review *domain.Review) error {
+	gormTx := dbTx.(*gorm.DB)
+	return gormTx.Create(review).Error
+}
+
+func (r *reviewRepository) GetByID(id uint64) (*domain.Review, error) {
+	var review domain.Review
+	err := r.db.Preload("Photos").First(&review, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+func (r *reviewRepository) GetByIDWithLock(dbTx interface{}, id uint64) (*domain.Review, error) {
+	gormTx := dbTx.(*gorm.DB)
+	var review domain.Review
+	err := gormTx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Photos").First(&review, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+func (r *reviewRepository) GetByTransactionItemID(transactionItemID uint64) (*domain.Review, error) {
+	var review domain.Review
+	err := r.db.Preload("Photos").Where("id_detail_trx = ?", transactionItemID).First(&review).Error
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// GetVisibleByProductID uses idx_product_reviews_produk
+func (r *reviewRepository) GetVisibleByProductID(productID uint64, limit, offset int) ([]*domain.Review, int64, error) {
+	var reviews []*domain.Review
+	var total int64
+
+	query := r.db.Model(&domain.Review{}).Where("id_produk = ? AND status = ?", productID, domain.ReviewStatusVisible)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Preload("Photos").
+		Order("created_at DESC, id DESC").
+		Limit(limit).Offset(offset).
+		Find(&reviews).Error
+
+	return reviews, total, err
+}
+
+func (r *reviewRepository) UpdateWithTx(dbTx interface{}, review *domain.Review) error {
+	gormTx := dbTx.(*gorm.DB)
+	return gormTx.Model(&domain.Review{}).Where("id = ?", review.ID).
+		Updates(map[string]interface{}{
+			"status":        review.Status,
+			"reply":         review.Reply,
+			"replied_at":    review.RepliedAt,
+			"hidden_reason": review.HiddenReason,
+			"hidden_by":     review.HiddenBy,
+			"hidden_at":     review.HiddenAt,
+		}).Error
+}
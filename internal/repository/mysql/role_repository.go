@@ -0,0 +1,61 @@
+package mysql
+
+import (
+	"go-commerce/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type roleRepository struct {
+	db *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) domain.RoleRepository {
+	return &roleRepository{db: db}
+}
+
+func (r *roleRepository) GetAll() ([]*domain.Role, error) {
+	var roles []*domain.Role
+	err := r.db.Preload("Permissions").Order("id ASC").Find(&roles).Error
+	return roles, err
+}
+
+func (r *roleRepository) GetByName(name string) (*domain.Role, error) {
+	var role domain.Role
+	err := r.db.Where("name = ?", name).First(&role).Error
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (r *roleRepository) GetUserRoles(userID uint64) ([]*domain.Role, error) {
+	var roles []*domain.Role
+	err := r.db.Preload("Permissions").
+		Joins("JOIN user_roles ON user_roles.id_role = roles.id").
+		Where("user_roles.id_user = ?", userID).
+		Order("roles.id ASC").
+		Find(&roles).Error
+	return roles, err
+}
+
+func (r *roleRepository) AssignToUser(userID, roleID uint64) error {
+	return r.AssignToUserWithTx(r.db, userID, roleID)
+}
+
+func (r *roleRepository) AssignToUserWithTx(dbTx interface{}, userID, roleID uint64) error {
+	gormTx := dbTx.(*gorm.DB)
+	return gormTx.Clauses(clause.OnConflict{DoNothing: true}).Create(&domain.UserRole{
+		UserID: userID,
+		RoleID: roleID,
+	}).Error
+}
+
+func (r *roleRepository) RevokeFromUser(userID, roleID uint64) (bool, error) {
+	result := r.db.Where("id_user = ? AND id_role = ?", userID, roleID).Delete(&domain.UserRole{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,31 @@
+package mysql
+
+import (
+	"time"
+
+	"go-commerce/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type searchQueryRepository struct {
+	db *gorm.DB
+}
+
+func NewSearchQueryRepository(db *gorm.DB) domain.SearchQueryRepository {
+	return &searchQueryRepository{db: db}
+}
+
+func (r *searchQueryRepository) Increment(query string, searchedAt time.Time) error {
+	return r.db.Exec(
+		"INSERT INTO search_queries (query, search_count, last_searched_at) VALUES (?, 1, ?) "+
+			"ON DUPLICATE KEY UPDATE search_count = search_count + 1, last_searched_at = VALUES(last_searched_at)",
+		query, searchedAt,
+	).Error
+}
+
+func (r *searchQueryRepository) GetPopular(limit int) ([]*domain.SearchQuery, error) {
+	var queries []*domain.SearchQuery
+	err := r.db.Order("search_count DESC, query ASC").Limit(limit).Find(&queries).Error
+	return queries, err
+}
@@ -0,0 +1,32 @@
+package mysql
+
+import (
+	"go-commerce/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type settingRepository struct {
+	db *gorm.DB
+}
+
+func NewSettingRepository(db *gorm.DB) domain.SettingRepository {
+	return &settingRepository{db: db}
+}
+
+func (r *settingRepository) Get(name string) (*domain.Setting, error) {
+	var setting domain.Setting
+	err := r.db.Where("name = ?", name).First(&setting).Error
+	if err != nil {
+		return nil, err
+	}
+	return &setting, nil
+}
+
+func (r *settingRepository) Set(name, value string) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "updated_at"}),
+	}).Create(&domain.Setting{Name: name, Value: value}).Error
+}
@@ -0,0 +1,79 @@
+package mysql
+
+import (
+	"time"
+
+	"go-commerce/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type shipmentRepository struct {
+	db *gorm.DB
+}
+
+func NewShipmentRepository(db *gorm.DB) domain.ShipmentRepository {
+	return &shipmentRepository{db: db}
+}
+
+func (r *shipmentRepository) CreateWithTx(dbTx interface{}, shipment *domain.Shipment) error {
+	gormTx := dbTx.(*gorm.DB)
+	return gormTx.Create(shipment).Error
+}
+
+func (r *shipmentRepository) GetByTransactionID(transactionID uint64) (*domain.Shipment, error) {
+	var shipment domain.Shipment
+	err := r.db.Preload("Events", func(db *gorm.DB) *gorm.DB {
+		return db.Order("occurred_at ASC, id ASC")
+	}).Where("id_trx = ?", transactionID).First(&shipment).Error
+	if err != nil {
+		return nil, err
+	}
+	return &shipment, nil
+}
+
+// GetDueForTracking uses idx_shipments_polling. Shipments whose order left
+// the shipped status some other way, e.g. confirmed by the buyer, are skipped.
+func (r *shipmentRepository) GetDueForTracking(polledBefore time.Time, limit int) ([]*domain.Shipment, error) {
+	var shipments []*domain.Shipment
+	err := r.db.Joins("JOIN trx ON trx.id = shipments.id_trx").
+		Where("shipments.status = ? AND trx.order_status = ?", domain.ShipmentStatusInTransit, domain.OrderStatusShipped).
+		Where("shipments.last_polled_at IS NULL OR shipments.last_polled_at < ?", polledBefore).
+		Order("shipments.last_polled_at ASC").
+		Limit(limit).
+		Find(&shipments).Error
+	return shipments, err
+}
+
+// AddEvents relies on idx_shipment_events_unique to skip events stored by an
+// earlier poll
+func (r *shipmentRepository) AddEvents(shipmentID uint64, events []*domain.ShipmentEvent) (int, error) {
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	for _, event := range events {
+		event.ShipmentID = shipmentID
+	}
+
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&events)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return int(result.RowsAffected), nil
+}
+
+func (r *shipmentRepository) MarkPolled(id uint64, at time.Time) error {
+	return r.db.Model(&domain.Shipment{}).Where("id = ?", id).Update("last_polled_at", at).Error
+}
+
+func (r *shipmentRepository) MarkDeliveredWithTx(dbTx interface{}, id uint64, deliveredAt time.Time) error {
+	gormTx := dbTx.(*gorm.DB)
+	return gormTx.Model(&domain.Shipment{}).
+		Where("id = ? AND status = ?", id, domain.ShipmentStatusInTransit).
+		Updates(map[string]interface{}{
+			"status":       domain.ShipmentStatusDelivered,
+			"delivered_at": deliveredAt,
+		}).Error
+}
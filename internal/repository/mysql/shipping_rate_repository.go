@@ -0,0 +1,27 @@
+package mysql
+
+import (
+	"go-commerce/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type shippingRateRepository struct {
+	db *gorm.DB
+}
+
+func NewShippingRateRepository(db *gorm.DB) domain.ShippingRateRepository {
+	return &shippingRateRepository{db: db}
+}
+
+func (r *shippingRateRepository) FindMatching(parcel domain.ShippingParcel) ([]*domain.ShippingRateRule, error) {
+	var rules []*domain.ShippingRateRule
+	err := r.db.
+		Where("origin_province_id IS NULL OR origin_province_id = ?", parcel.OriginProvinceID).
+		Where("origin_city_id IS NULL OR origin_city_id = ?", parcel.OriginCityID).
+		Where("destination_province_id IS NULL OR destination_province_id = ?", parcel.DestinationProvinceID).
+		Where("destination_city_id IS NULL OR destination_city_id = ?", parcel.DestinationCityID).
+		Order("courier ASC, service ASC, id ASC").
+		Find(&rules).Error
+	return rules, err
+}
@@ -0,0 +1,68 @@
+package mysql
+
+import (
+	"time"
+
+	"go-commerce/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type stockReservationRepository struct {
+	db *gorm.DB
+}
+
+func NewStockReservationRepository(db *gorm.DB) domain.StockReservationRepository {
+	return &stockReservationRepository{db: db}
+}
+
+func (r *stockReservationRepository) CreateWithTx(dbTx interface{}, reservation *domain.StockReservation) error {
+	gormTx := dbTx.(*gorm.DB)
+	return gormTx.Create(reservation).Error
+}
+
+func (r *stockReservationRepository) GetHeldByCheckoutIDWithTx(dbTx interface{}, checkoutID uint64) ([]*domain.StockReservation, error) {
+	gormTx := dbTx.(*gorm.DB)
+	var reservations []*domain.StockReservation
+	err := gormTx.Where("id_checkout = ? AND status = ?", checkoutID, domain.StockReservationHeld).
+		Order("id ASC").
+		Find(&reservations).Error
+	return reservations, err
+}
+
+func (r *stockReservationRepository) GetHeldByTransactionIDWithTx(dbTx interface{}, transactionID uint64) ([]*domain.StockReservation, error) {
+	gormTx := dbTx.(*gorm.DB)
+	var reservations []*domain.StockReservation
+	err := gormTx.Where("id_trx = ? AND status = ?", transactionID, domain.StockReservationHeld).
+		Order("id ASC").
+		Find(&reservations).Error
+	return reservations, err
+}
+
+func (r *stockReservationRepository) GetExpiredCheckoutIDs(now time.Time, limit int) ([]uint64, error) {
+	var checkoutIDs []uint64
+	// Uses idx_stock_reservations_expiry index
+	err := r.db.Model(&domain.StockReservation{}).
+		Where("status = ? AND expires_at < ?", domain.StockReservationHeld, now).
+		Distinct().
+		Limit(limit).
+		Pluck("id_checkout", &checkoutIDs).Error
+	return checkoutIDs, err
+}
+
+func (r *stockReservationRepository) TransitionWithTx(dbTx interface{}, id uint64, from, to string) (bool, error) {
+	gormTx := dbTx.(*gorm.DB)
+	result := gormTx.Model(&domain.StockReservation{}).
+		Where("id = ? AND status = ?", id, from).
+		Update("status", to)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *stockReservationRepository) ExtendByCheckoutID(checkoutID uint64, until time.Time) error {
+	return r.db.Model(&domain.StockReservation{}).
+		Where("id_checkout = ? AND status = ? AND expires_at < ?", checkoutID, domain.StockReservationHeld, until).
+		Update("expires_at", until).Error
+}
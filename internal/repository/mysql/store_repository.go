@@ -90,6 +90,37 @@ func (r *storeRepository) GetActiveStores(limit, offset int, search string) ([]*
 
 	return stores, total, nil
 }
+
+// storeCursorColumns are the sort orders store lists can be paged in by
+// cursor
+var storeCursorColumns = map[string]cursorColumn{
+	"newest":    {name: "created_at", desc: true, parse: timeKey},
+	"oldest":    {name: "created_at", parse: timeKey},
+	"name_asc":  {name: "nama_toko", parse: stringKey},
+	"name_desc": {name: "nama_toko", desc: true, parse: stringKey},
+}
+
+// GetActiveStoresWithCursor pages the active stores by cursor
+func (r *storeRepository) GetActiveStoresWithCursor(search string, page *domain.CursorPage) ([]*domain.Store, error) {
+	query := r.db.Model(&domain.Store{}).Preload("User").Where("status = ?", "active")
+
+	if search != "" {
+		query = query.Where("nama_toko LIKE ?", "%"+search+"%")
+	}
+
+	query, err := pageByCursor(query, storeCursorColumns, page)
+	if err != nil {
+		return nil, err
+	}
+
+	var stores []*domain.Store
+	if err := query.Find(&stores).Error; err != nil {
+		return nil, err
+	}
+
+	return stores, nil
+}
+
 // GetPendingStores returns stores waiting for admin approval
 func (r *storeRepository) GetPendingStores(limit, offset int, search string) ([]*domain.Store, int64, error) {
 	var stores []*domain.Store
@@ -113,6 +144,17 @@ func (r *storeRepository) GetPendingStores(limit, offset int, search string) ([]
 
 	return stores, total, nil
 }
+// AddRatingWithTx relies on MySQL assigning left to right, so the average is
+// computed from the updated sum and count
+func (r *storeRepository) AddRatingWithTx(dbTx interface{}, storeID uint64, rating, count int) error {
+	gormTx := dbTx.(*gorm.DB)
+	return gormTx.Exec(
+		`UPDATE toko SET rating_sum = rating_sum + ?, rating_count = rating_count + ?,
+			rating = IF(rating_count > 0, rating_sum / rating_count, 0) WHERE id = ?`,
+		rating, count, storeID,
+	).Error
+}
+
 // GetActiveStoreByUserID returns active store for a user
 func (r *storeRepository) GetActiveStoreByUserID(userID uint64) (*domain.Store, error) {
 	var store domain.Store
@@ -0,0 +1,78 @@
+package mysql
+
+import (
+	"errors"
+	"time"
+
+	"go-commerce/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type tokenRevocationRepository struct {
+	db *gorm.DB
+}
+
+func NewTokenRevocationRepository(db *gorm.DB) domain.TokenRevocationStore {
+	return &tokenRevocationRepository{db: db}
+}
+
+func (r *tokenRevocationRepository) Revoke(tokenID string, userID uint64, expiresAt time.Time) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&domain.RevokedToken{
+		TokenID:   tokenID,
+		UserID:    userID,
+		ExpiresAt: expiresAt,
+		RevokedAt: time.Now(),
+	}).Error
+}
+
+func (r *tokenRevocationRepository) RevokeAllForUser(userID uint64, revokedBefore, expiresAt time.Time) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id_user"}},
+		DoUpdates: clause.AssignmentColumns([]string{"revoked_before", "expires_at", "updated_at"}),
+	}).Create(&domain.UserTokenRevocation{
+		UserID:        userID,
+		RevokedBefore: revokedBefore,
+		ExpiresAt:     expiresAt,
+		UpdatedAt:     time.Now(),
+	}).Error
+}
+
+func (r *tokenRevocationRepository) IsRevoked(tokenID string, userID uint64, issuedAt time.Time) (bool, error) {
+	if tokenID != "" {
+		var count int64
+		if err := r.db.Model(&domain.RevokedToken{}).Where("jti = ?", tokenID).Count(&count).Error; err != nil {
+			return false, err
+		}
+		if count > 0 {
+			return true, nil
+		}
+	}
+
+	var revocation domain.UserTokenRevocation
+	err := r.db.Where("id_user = ?", userID).First(&revocation).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return issuedAt.Before(revocation.RevokedBefore), nil
+}
+
+// PurgeExpired removes denylist entries whose tokens have expired anyway
+func (r *tokenRevocationRepository) PurgeExpired(now time.Time) (int64, error) {
+	tokens := r.db.Where("expires_at < ?", now).Delete(&domain.RevokedToken{})
+	if tokens.Error != nil {
+		return 0, tokens.Error
+	}
+
+	revocations := r.db.Where("expires_at < ?", now).Delete(&domain.UserTokenRevocation{})
+	if revocations.Error != nil {
+		return tokens.RowsAffected, revocations.Error
+	}
+
+	return tokens.RowsAffected + revocations.RowsAffected, nil
+}
@@ -27,4 +27,19 @@ func (r *transactionItemRepository) GetByTransactionID(transactionID uint64) ([]
 	var items []*domain.TransactionItem
 	err := r.db.Where("id_trx = ?", transactionID).Find(&items).Error
 	return items, err
+}
+
+func (r *transactionItemRepository) GetByID(id uint64) (*domain.TransactionItem, error) {
+	var item domain.TransactionItem
+	err := r.db.Preload("Transaction").Preload("ProductLog").First(&item, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *transactionItemRepository) AddRefundedQuantityWithTx(dbTx interface{}, id uint64, quantity int) error {
+	gormTx := dbTx.(*gorm.DB)
+	return gormTx.Model(&domain.TransactionItem{}).Where("id = ?", id).
+		Update("refunded_quantity", gorm.Expr("refunded_quantity + ?", quantity)).Error
 }
\ No newline at end of file
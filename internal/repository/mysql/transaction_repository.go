@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type transactionRepository struct {
@@ -28,6 +29,17 @@ func (r *transactionRepository) GetByID(id uint64) (*domain.Transaction, error)
 	return &tx, nil
 }
 
+// GetByInvoice uses the unique idx_trx_invoice index
+func (r *transactionRepository) GetByInvoice(kodeInvoice string) (*domain.Transaction, error) {
+	var tx domain.Transaction
+	err := r.db.Preload("User").Preload("Alamat").Preload("TransactionItems").
+		Where("kode_invoice = ?", kodeInvoice).First(&tx).Error
+	if err != nil {
+		return nil, err
+	}
+	return &tx, nil
+}
+
 func (r *transactionRepository) GetByUserID(userID uint64, limit, offset int) ([]*domain.Transaction, int64, error) {
 	var transactions []*domain.Transaction
 	var total int64
@@ -48,41 +60,86 @@ func (r *transactionRepository) GetByUserID(userID uint64, limit, offset int) ([
 	return transactions, total, err
 }
 
-func (r *transactionRepository) Update(tx *domain.Transaction) error {
-	return r.db.Save(tx).Error
+// transactionCursorColumns are the sort orders transaction lists can be
+// paged in by cursor
+var transactionCursorColumns = map[string]cursorColumn{
+	"newest": {name: "created_at", desc: true, parse: timeKey},
+	"oldest": {name: "created_at", parse: timeKey},
 }
 
-func (r *transactionRepository) UpdateStatus(id uint64, status string) error {
-	updates := map[string]interface{}{"status_pembayaran": status}
-	if status == "paid" {
-		updates["paid_at"] = time.Now()
+func (r *transactionRepository) GetByUserIDWithCursor(userID uint64, page *domain.CursorPage) ([]*domain.Transaction, error) {
+	query, err := pageByCursor(r.db.Where("id_user = ?", userID), transactionCursorColumns, page)
+	if err != nil {
+		return nil, err
 	}
-	return r.db.Model(&domain.Transaction{}).Where("id = ?", id).Updates(updates).Error
+
+	var transactions []*domain.Transaction
+	err = query.Preload("Alamat").
+		Preload("TransactionItems").
+		Find(&transactions).Error
+
+	return transactions, err
 }
 
-func (r *transactionRepository) UpdateStatusWithTx(dbTx interface{}, id uint64, status string) error {
-	gormTx := dbTx.(*gorm.DB)
-	updates := map[string]interface{}{"status_pembayaran": status}
-	if status == "paid" {
-		updates["paid_at"] = time.Now()
+// GetByStoreID gets the orders of one store (uses idx_trx_toko index)
+func (r *transactionRepository) GetByStoreID(storeID uint64, limit, offset int) ([]*domain.Transaction, int64, error) {
+	var transactions []*domain.Transaction
+	var total int64
+
+	err := r.db.Model(&domain.Transaction{}).Where("id_toko = ?", storeID).Count(&total).Error
+	if err != nil {
+		return nil, 0, err
 	}
-	return gormTx.Model(&domain.Transaction{}).Where("id = ?", id).Updates(updates).Error
+
+	err = r.db.Where("id_toko = ?", storeID).
+		Preload("User").
+		Preload("Alamat").
+		Preload("TransactionItems").
+		Order("created_at DESC").
+		Limit(limit).Offset(offset).
+		Find(&transactions).Error
+
+	return transactions, total, err
+}
+
+func (r *transactionRepository) Update(tx *domain.Transaction) error {
+	return r.db.Save(tx).Error
 }
 
-func (r *transactionRepository) UpdatePaymentStatus(id uint64, paymentStatus string) error {
-	updates := map[string]interface{}{"payment_status": paymentStatus}
-	if paymentStatus == "paid" {
-		updates["paid_at"] = time.Now()
+func (r *transactionRepository) TransitionStatusWithTx(dbTx interface{}, id uint64, from, to string, at time.Time) (bool, error) {
+	gormTx := dbTx.(*gorm.DB)
+	updates := map[string]interface{}{"status_pembayaran": to}
+	switch to {
+	case domain.PaymentStatusPaid:
+		updates["paid_at"] = at
+	case domain.PaymentStatusDone:
+		updates["completed_at"] = at
+	}
+	result := gormTx.Model(&domain.Transaction{}).
+		Where("id = ? AND status_pembayaran = ?", id, from).
+		Updates(updates)
+	if result.Error != nil {
+		return false, result.Error
 	}
-	return r.db.Model(&domain.Transaction{}).Where("id = ?", id).Updates(updates).Error
+	return result.RowsAffected > 0, nil
 }
 
-func (r *transactionRepository) UpdateOrderStatus(id uint64, orderStatus string) error {
-	updates := map[string]interface{}{"order_status": orderStatus}
-	if orderStatus == "shipped" {
-		updates["shipped_at"] = time.Now()
+func (r *transactionRepository) TransitionOrderStatusWithTx(dbTx interface{}, id uint64, from, to string, at time.Time) (bool, error) {
+	gormTx := dbTx.(*gorm.DB)
+	updates := map[string]interface{}{"order_status": to}
+	switch to {
+	case domain.OrderStatusShipped:
+		updates["shipped_at"] = at
+	case domain.OrderStatusDelivered:
+		updates["delivered_at"] = at
+	}
+	result := gormTx.Model(&domain.Transaction{}).
+		Where("id = ? AND order_status = ?", id, from).
+		Updates(updates)
+	if result.Error != nil {
+		return false, result.Error
 	}
-	return r.db.Model(&domain.Transaction{}).Where("id = ?", id).Updates(updates).Error
+	return result.RowsAffected > 0, nil
 }
 
 func (r *transactionRepository) BeginTx() (interface{}, error) {
@@ -104,6 +161,24 @@ func (r *transactionRepository) CreateWithTx(dbTx interface{}, tx *domain.Transa
 	return gormTx.Create(tx).Error
 }
 
+func (r *transactionRepository) GetByIDWithLock(dbTx interface{}, id uint64) (*domain.Transaction, error) {
+	gormTx := dbTx.(*gorm.DB)
+	var tx domain.Transaction
+	err := gormTx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("TransactionItems").
+		First(&tx, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &tx, nil
+}
+
+func (r *transactionRepository) AddRefundedAmountWithTx(dbTx interface{}, id uint64, amount float64) error {
+	gormTx := dbTx.(*gorm.DB)
+	return gormTx.Model(&domain.Transaction{}).Where("id = ?", id).
+		Update("refunded_amount", gorm.Expr("refunded_amount + ?", amount)).Error
+}
+
 // GetByStatus gets transactions by status (uses idx_trx_status_pembayaran index)
 func (r *transactionRepository) GetByStatus(status string, limit, offset int) ([]*domain.Transaction, int64, error) {
 	var transactions []*domain.Transaction
@@ -123,4 +198,18 @@ func (r *transactionRepository) GetByStatus(status string, limit, offset int) ([
 		Find(&transactions).Error
 
 	return transactions, total, err
-}
\ No newline at end of file
+}
+
+// GetDueForCompletion uses idx_trx_completion. Delivered orders are included
+// since the buyer may confirm delivery without completing the order.
+func (r *transactionRepository) GetDueForCompletion(shippedBefore time.Time, limit int) ([]*domain.Transaction, error) {
+	var transactions []*domain.Transaction
+	err := r.db.Where("status_pembayaran = ? AND order_status IN ?", domain.PaymentStatusPaid,
+		[]string{domain.OrderStatusShipped, domain.OrderStatusDelivered}).
+		Where("shipped_at < ?", shippedBefore).
+		Where("NOT EXISTS (SELECT 1 FROM disputes WHERE disputes.id_trx = trx.id AND disputes.status = ?)", domain.DisputeStatusOpen).
+		Order("shipped_at ASC").
+		Limit(limit).
+		Find(&transactions).Error
+	return transactions, err
+}
@@ -0,0 +1,29 @@
+package mysql
+
+import (
+	"go-commerce/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type transactionStatusHistoryRepository struct {
+	db *gorm.DB
+}
+
+func NewTransactionStatusHistoryRepository(db *gorm.DB) domain.TransactionStatusHistoryRepository {
+	return &transactionStatusHistoryRepository{db: db}
+}
+
+func (r *transactionStatusHistoryRepository) CreateWithTx(dbTx interface{}, entry *domain.TransactionStatusHistory) error {
+	gormTx := dbTx.(*gorm.DB)
+	return gormTx.Create(entry).Error
+}
+
+func (r *transactionStatusHistoryRepository) GetByTransactionID(transactionID uint64) ([]*domain.TransactionStatusHistory, error) {
+	var entries []*domain.TransactionStatusHistory
+	// Uses idx_trx_status_history_trx index
+	err := r.db.Where("id_trx = ?", transactionID).
+		Order("created_at ASC, id ASC").
+		Find(&entries).Error
+	return entries, err
+}
@@ -0,0 +1,49 @@
+package mysql
+
+import (
+	"time"
+
+	"go-commerce/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type twoFactorRecoveryCodeRepository struct {
+	db *gorm.DB
+}
+
+func NewTwoFactorRecoveryCodeRepository(db *gorm.DB) domain.TwoFactorRecoveryCodeRepository {
+	return &twoFactorRecoveryCodeRepository{db: db}
+}
+
+func (r *twoFactorRecoveryCodeRepository) ReplaceForUser(userID uint64, codeHashes []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id_user = ?", userID).Delete(&domain.TwoFactorRecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		codes := make([]*domain.TwoFactorRecoveryCode, 0, len(codeHashes))
+		for _, hash := range codeHashes {
+			codes = append(codes, &domain.TwoFactorRecoveryCode{UserID: userID, CodeHash: hash})
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// Consume uses a conditional update so a recovery code works exactly once
+func (r *twoFactorRecoveryCodeRepository) Consume(userID uint64, codeHash string, usedAt time.Time) (bool, error) {
+	result := r.db.Model(&domain.TwoFactorRecoveryCode{}).
+		Where("id_user = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", usedAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *twoFactorRecoveryCodeRepository) DeleteByUserID(userID uint64) error {
+	return r.db.Where("id_user = ?", userID).Delete(&domain.TwoFactorRecoveryCode{}).Error
+}
@@ -60,4 +60,78 @@ func (r *userRepository) UpdateLastLogin(userID uint64, lastLogin time.Time) err
 
 func (r *userRepository) UpdateProfile(userID uint64, updates map[string]interface{}) error {
 	return r.db.Model(&domain.User{}).Where("id = ?", userID).Updates(updates).Error
-}
\ No newline at end of file
+}
+
+func (r *userRepository) MarkEmailVerified(userID uint64, verifiedAt time.Time) error {
+	return r.db.Model(&domain.User{}).
+		Where("id = ? AND email_verified_at IS NULL", userID).
+		Update("email_verified_at", verifiedAt).Error
+}
+
+// ReserveVerificationEmail uses a conditional update so concurrent resend
+// requests cannot bypass the cooldown
+func (r *userRepository) ReserveVerificationEmail(userID uint64, sentAt, notBefore time.Time) (bool, error) {
+	result := r.db.Model(&domain.User{}).
+		Where("id = ? AND (email_verification_sent_at IS NULL OR email_verification_sent_at <= ?)", userID, notBefore).
+		Update("email_verification_sent_at", sentAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// LockAccount sets the temporary lockout. Blocked users keep their status.
+func (r *userRepository) LockAccount(userID uint64, until time.Time) error {
+	return r.db.Model(&domain.User{}).
+		Where("id = ? AND status IN ?", userID, []string{"active", "locked"}).
+		Updates(map[string]interface{}{
+			"status":       "locked",
+			"locked_until": until,
+		}).Error
+}
+
+func (r *userRepository) UnlockAccount(userID uint64) error {
+	return r.db.Model(&domain.User{}).
+		Where("id = ? AND status = ?", userID, "locked").
+		Updates(map[string]interface{}{
+			"status":       "active",
+			"locked_until": nil,
+		}).Error
+}
+func (r *userRepository) SetTOTPSecret(userID uint64, secret string) error {
+	return r.db.Model(&domain.User{}).
+		Where("id = ?", userID).
+		Updates(map[string]interface{}{
+			"totp_secret":     secret,
+			"totp_enabled_at": nil,
+			"totp_last_step":  0,
+		}).Error
+}
+
+func (r *userRepository) EnableTOTP(userID uint64, enabledAt time.Time) error {
+	return r.db.Model(&domain.User{}).
+		Where("id = ?", userID).
+		Update("totp_enabled_at", enabledAt).Error
+}
+
+func (r *userRepository) DisableTOTP(userID uint64) error {
+	return r.db.Model(&domain.User{}).
+		Where("id = ?", userID).
+		Updates(map[string]interface{}{
+			"totp_secret":     nil,
+			"totp_enabled_at": nil,
+			"totp_last_step":  0,
+		}).Error
+}
+
+// UseTOTPStep uses a conditional update so a code cannot be replayed, even by
+// two concurrent requests
+func (r *userRepository) UseTOTPStep(userID uint64, step int64) (bool, error) {
+	result := r.db.Model(&domain.User{}).
+		Where("id = ? AND totp_last_step < ?", userID, step).
+		Update("totp_last_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
@@ -3,12 +3,25 @@ package service
 import (
 	"log"
 	"time"
+
+	"go-commerce/internal/domain"
+	"go-commerce/pkg/jwt"
 )
 
-type BackgroundService struct{}
+type BackgroundService struct {
+	revocationStore   domain.TokenRevocationStore
+	refreshTokenRepo  domain.RefreshTokenRepository
+	passwordResetRepo domain.PasswordResetRepository
+	loginThrottleRepo domain.LoginThrottleRepository
+}
 
-func NewBackgroundService() *BackgroundService {
-	return &BackgroundService{}
+func NewBackgroundService(revocationStore domain.TokenRevocationStore, refreshTokenRepo domain.RefreshTokenRepository, passwordResetRepo domain.PasswordResetRepository, loginThrottleRepo domain.LoginThrottleRepository) *BackgroundService {
+	return &BackgroundService{
+		revocationStore:   revocationStore,
+		refreshTokenRepo:  refreshTokenRepo,
+		passwordResetRepo: passwordResetRepo,
+		loginThrottleRepo: loginThrottleRepo,
+	}
 }
 
 // StartCleanupJobs starts background cleanup jobs
@@ -44,9 +57,203 @@ func (s *BackgroundService) StartCleanupJobs() {
 	}()
 }
 
+// StartKeyReloadJob periodically re-reads the JWT key directory so signing
+// keys can be rotated without a restart
+func (s *BackgroundService) StartKeyReloadJob(jwtManager *jwt.JWTManager, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := jwtManager.ReloadKeys(); err != nil {
+				log.Printf("Background: Failed to reload JWT keys, keeping current keys: %v", err)
+			}
+		}
+	}()
+}
+
+// StockReleaser returns the stock of expired checkout holds to sale
+type StockReleaser interface {
+	ReleaseExpired() (int, error)
+}
+
+// StartStockReleaseJob periodically releases stock held by checkouts that
+// were never paid
+func (s *BackgroundService) StartStockReleaseJob(releaser StockReleaser, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			released, err := releaser.ReleaseExpired()
+			if err != nil {
+				log.Printf("Background: Failed to release expired stock holds: %v", err)
+			}
+			if released > 0 {
+				log.Printf("Background: Released stock holds of %d expired checkouts", released)
+			}
+		}
+	}()
+}
+
+// PaymentExpirer expires payment intents that were not paid in time
+type PaymentExpirer interface {
+	ExpireStaleIntents() (int, error)
+}
+
+// StartPaymentExpiryJob periodically expires stale payment intents and fails
+// their checkouts. The sweep locks rows with SKIP LOCKED, so it is safe to run
+// on every instance.
+func (s *BackgroundService) StartPaymentExpiryJob(expirer PaymentExpirer, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			expired, err := expirer.ExpireStaleIntents()
+			if err != nil {
+				log.Printf("Background: Failed to expire payment intents: %v", err)
+			}
+			if expired > 0 {
+				log.Printf("Background: Expired %d payment intents", expired)
+			}
+		}
+	}()
+}
+
+// ShipmentPoller follows shipped parcels at the couriers
+type ShipmentPoller interface {
+	PollTracking() (int, error)
+}
+
+// StartShipmentTrackingJob periodically fetches courier tracking events of
+// shipped orders and marks the delivered ones
+func (s *BackgroundService) StartShipmentTrackingJob(poller ShipmentPoller, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			delivered, err := poller.PollTracking()
+			if err != nil {
+				log.Printf("Background: Failed to poll shipment tracking: %v", err)
+			}
+			if delivered > 0 {
+				log.Printf("Background: Marked %d shipped orders delivered", delivered)
+			}
+		}
+	}()
+}
+
+// SuggestionRebuilder reloads the search suggestions from the database
+type SuggestionRebuilder interface {
+	Rebuild() (int, error)
+}
+
+// StartSuggestionRebuildJob periodically reloads the search suggestions, which
+// picks up catalog changes made through other app instances
+func (s *BackgroundService) StartSuggestionRebuildJob(rebuilder SuggestionRebuilder, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if _, err := rebuilder.Rebuild(); err != nil {
+				log.Printf("Background: Failed to rebuild search suggestions: %v", err)
+			}
+		}
+	}()
+}
+
+// OrderCompleter completes orders whose confirmation window ended
+type OrderCompleter interface {
+	AutoConfirmOrders() (int, error)
+}
+
+// StartOrderAutoConfirmJob periodically completes shipped orders the buyer
+// neither confirmed nor disputed within the confirmation window
+func (s *BackgroundService) StartOrderAutoConfirmJob(completer OrderCompleter, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			completed, err := completer.AutoConfirmOrders()
+			if err != nil {
+				log.Printf("Background: Failed to auto-confirm orders: %v", err)
+			}
+			if completed > 0 {
+				log.Printf("Background: Auto-confirmed %d orders", completed)
+			}
+		}
+	}()
+}
+
 func (s *BackgroundService) cleanupExpiredTokens() {
-	// Simulate cleanup
 	log.Println("Background: Cleaning up expired tokens...")
+	now := time.Now()
+
+	// Revocation entries are only needed until the token they block expires
+	if s.revocationStore != nil {
+		purged, err := s.revocationStore.PurgeExpired(now)
+		if err != nil {
+			log.Printf("Background: Failed to purge revoked tokens: %v", err)
+		} else {
+			log.Printf("Background: Purged %d expired revocation entries", purged)
+		}
+	}
+
+	if s.refreshTokenRepo != nil {
+		deleted, err := s.refreshTokenRepo.DeleteExpired(now)
+		if err != nil {
+			log.Printf("Background: Failed to delete expired refresh tokens: %v", err)
+		} else {
+			log.Printf("Background: Deleted %d expired refresh tokens", deleted)
+		}
+	}
+
+	if s.passwordResetRepo != nil {
+		deleted, err := s.passwordResetRepo.DeleteExpired(now)
+		if err != nil {
+			log.Printf("Background: Failed to delete expired password reset tokens: %v", err)
+		} else {
+			log.Printf("Background: Deleted %d expired password reset tokens", deleted)
+		}
+	}
+
+	// Failed-login counters older than a day no longer affect lockouts
+	if s.loginThrottleRepo != nil {
+		deleted, err := s.loginThrottleRepo.DeleteStale(now.Add(-24 * time.Hour))
+		if err != nil {
+			log.Printf("Background: Failed to delete stale login throttles: %v", err)
+		} else {
+			log.Printf("Background: Deleted %d stale login throttles", deleted)
+		}
+	}
 }
 
 func (s *BackgroundService) archiveOldTransactions() {
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"go-commerce/internal/domain"
+)
+
+// TrackingStatusDelivered is the event status couriers report once a parcel
+// reached the buyer
+const TrackingStatusDelivered = "delivered"
+
+// FileCourierTracker is a stand-in for the courier tracking APIs. It reads the
+// tracking history of each parcel from a JSON file keyed "courier:tracking
+// number", e.g.
+//
+//	{"jne:JNE123": [{"status": "picked_up", "occurred_at": "2026-10-17T09:00:00+07:00"}]}
+//
+// The file is read on every lookup, so events can be appended while the
+// server runs to simulate a parcel moving.
+type FileCourierTracker struct {
+	path string
+}
+
+func NewFileCourierTracker(path string) *FileCourierTracker {
+	return &FileCourierTracker{path: path}
+}
+
+// Track returns the events of the parcel, oldest first. Unknown parcels and a
+// missing file have no events yet.
+func (t *FileCourierTracker) Track(courier, trackingNumber string) (*domain.TrackingResult, error) {
+	data, err := os.ReadFile(t.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &domain.TrackingResult{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var parcels map[string][]domain.TrackingEvent
+	if err := json.Unmarshal(data, &parcels); err != nil {
+		return nil, fmt.Errorf("invalid courier tracking file %s: %w", t.path, err)
+	}
+
+	events := parcels[strings.ToLower(courier)+":"+trackingNumber]
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].OccurredAt.Before(events[j].OccurredAt)
+	})
+
+	result := &domain.TrackingResult{Events: events}
+	for _, event := range events {
+		if event.Status == TrackingStatusDelivered {
+			deliveredAt := event.OccurredAt
+			result.DeliveredAt = &deliveredAt
+			break
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileCourierTracker_Track(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tracking.json")
+	tracker := NewFileCourierTracker(path)
+
+	// No file yet: the parcel has no events
+	result, err := tracker.Track("jne", "JNE123")
+	require.NoError(t, err)
+	assert.Empty(t, result.Events)
+	assert.Nil(t, result.DeliveredAt)
+
+	// Events are returned oldest first; delivery is picked up from the file
+	// as soon as it is written
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"jne:JNE123": [
+			{"status": "delivered", "location": "Bandung", "occurred_at": "2026-10-17T14:00:00+07:00"},
+			{"status": "picked_up", "location": "Jakarta", "occurred_at": "2026-10-15T09:00:00+07:00"}
+		],
+		"tiki:TK1": [{"status": "picked_up", "occurred_at": "2026-10-16T09:00:00+07:00"}]
+	}`), 0o644))
+
+	result, err = tracker.Track("JNE", "JNE123")
+	require.NoError(t, err)
+	require.Len(t, result.Events, 2)
+	assert.Equal(t, "picked_up", result.Events[0].Status)
+	require.NotNil(t, result.DeliveredAt)
+	assert.True(t, result.DeliveredAt.Equal(result.Events[1].OccurredAt))
+
+	result, err = tracker.Track("tiki", "TK1")
+	require.NoError(t, err)
+	assert.Len(t, result.Events, 1)
+	assert.Nil(t, result.DeliveredAt)
+
+	// A broken file is reported instead of looking like an idle parcel
+	require.NoError(t, os.WriteFile(path, []byte(`{`), 0o644))
+	_, err = tracker.Track("jne", "JNE123")
+	assert.Error(t, err)
+}
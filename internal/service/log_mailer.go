@@ -0,0 +1,49 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"go-commerce/internal/domain"
+)
+
+// LogMailer is meant for local development. It writes every email to
+// outputDir as an .eml file, or to the application log when no directory is
+// configured.
+type LogMailer struct {
+	from      string
+	outputDir string
+	counter   uint64
+}
+
+func NewLogMailer(from, outputDir string) domain.Mailer {
+	return &LogMailer{
+		from:      from,
+		outputDir: outputDir,
+	}
+}
+
+func (m *LogMailer) Send(msg *domain.EmailMessage) error {
+	if m.outputDir == "" {
+		log.Printf("Mail to %s: %s\n%s", msg.To, msg.Subject, msg.Body)
+		return nil
+	}
+
+	if err := os.MkdirAll(m.outputDir, 0755); err != nil {
+		return fmt.Errorf("create mail output dir: %w", err)
+	}
+
+	seq := atomic.AddUint64(&m.counter, 1)
+	name := fmt.Sprintf("%s-%03d.eml", time.Now().Format("20060102-150405.000000"), seq)
+	path := filepath.Join(m.outputDir, name)
+	if err := os.WriteFile(path, buildMessage(m.from, msg), 0644); err != nil {
+		return fmt.Errorf("write email %s: %w", path, err)
+	}
+
+	log.Printf("Mail to %s written to %s", msg.To, path)
+	return nil
+}
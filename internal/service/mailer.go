@@ -0,0 +1,28 @@
+package service
+
+import (
+	"strings"
+
+	"go-commerce/internal/domain"
+	"go-commerce/pkg/config"
+)
+
+// NewMailer returns the mailer selected by MAIL_DRIVER ("smtp" or "log")
+func NewMailer(cfg config.MailConfig) domain.Mailer {
+	if cfg.Driver == "smtp" {
+		return NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.From)
+	}
+	return NewLogMailer(cfg.From, cfg.OutputDir)
+}
+
+func buildMessage(from string, msg *domain.EmailMessage) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + msg.To + "\r\n")
+	b.WriteString("Subject: " + msg.Subject + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
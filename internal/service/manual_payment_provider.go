@@ -0,0 +1,51 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"go-commerce/internal/domain"
+)
+
+// ManualPaymentProviderName is the provider of intents that staff settle by
+// hand
+const ManualPaymentProviderName = "manual"
+
+// ManualPaymentProvider collects payments outside of any gateway: the buyer
+// pays the shop's account directly and staff with the payments:simulate
+// permission mark the intent paid or failed once they checked the statement.
+// It has no callbacks, and refunds are paid out by finance staff.
+type ManualPaymentProvider struct{}
+
+func NewManualPaymentProvider() domain.PaymentProvider {
+	return &ManualPaymentProvider{}
+}
+
+func (p *ManualPaymentProvider) Name() string {
+	return ManualPaymentProviderName
+}
+
+func (p *ManualPaymentProvider) CreateCharge(req *domain.ChargeRequest) (*domain.Charge, error) {
+	return &domain.Charge{
+		Provider:  ManualPaymentProviderName,
+		Reference: fmt.Sprintf("MANUAL-%d", req.IntentID),
+	}, nil
+}
+
+// GetStatus leaves the intent pending; only staff settle manual payments
+func (p *ManualPaymentProvider) GetStatus(reference string) (*domain.ChargeStatus, error) {
+	return &domain.ChargeStatus{Status: domain.ChargeStatusPending}, nil
+}
+
+// Refund records the payout finance staff owe the buyer. The reference is
+// derived from the idempotency key, so a retried refund is recorded once.
+func (p *ManualPaymentProvider) Refund(reference string, amount float64, idempotencyKey string) (string, error) {
+	if idempotencyKey == "" {
+		return "", errors.New("manual refunds need an idempotency key")
+	}
+	return "MANUAL-REFUND-" + idempotencyKey, nil
+}
+
+func (p *ManualPaymentProvider) ParseWebhook(body []byte) (*domain.PaymentWebhook, error) {
+	return nil, errors.New("manual payments have no callbacks")
+}
@@ -0,0 +1,317 @@
+package service
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"go-commerce/internal/domain"
+	"go-commerce/pkg/webhook"
+)
+
+// MockPaymentGatewayName is the gateway name the mock gateway signs its
+// callbacks under
+const MockPaymentGatewayName = "mock"
+
+type mockCharge struct {
+	Reference      string     `json:"reference"`
+	IntentID       uint       `json:"intent_id"`
+	Method         string     `json:"method"`
+	Amount         float64    `json:"amount"`
+	Refunded       float64    `json:"refunded"`
+	Refunds        int        `json:"refunds"`
+	Status         string     `json:"status"`
+	VirtualAccount string     `json:"virtual_account,omitempty"`
+	QRPayload      string     `json:"qr_payload,omitempty"`
+	PaymentURL     string     `json:"payment_url,omitempty"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	PaidAt         *time.Time `json:"paid_at,omitempty"`
+
+	// refundKeys maps the idempotency key of each refund to its reference
+	refundKeys map[string]string
+}
+
+// MockPaymentGateway is an in-process HTTP stand-in for a payment gateway. It
+// issues virtual account numbers, QR payloads and card payment pages, and
+// sends signed callbacks when a charge is paid or failed through its
+// /charges/{ref}/pay and /charges/{ref}/fail endpoints, so the whole payment
+// flow runs offline.
+type MockPaymentGateway struct {
+	secret      string
+	callbackURL string
+	client      *http.Client
+	server      *http.Server
+
+	mu      sync.Mutex
+	seq     int
+	charges map[string]*mockCharge
+}
+
+// NewMockPaymentGateway creates a gateway that posts callbacks to
+// callbackURL/{intentId}. An empty secret is replaced by a random one.
+func NewMockPaymentGateway(secret, callbackURL string) *MockPaymentGateway {
+	if secret == "" {
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err != nil {
+			panic(err)
+		}
+		secret = hex.EncodeToString(b)
+	}
+
+	return &MockPaymentGateway{
+		secret:      secret,
+		callbackURL: callbackURL,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		charges: make(map[string]*mockCharge),
+	}
+}
+
+// Secret is the key the gateway signs callbacks with
+func (g *MockPaymentGateway) Secret() string {
+	return g.secret
+}
+
+// Start serves the gateway on addr, e.g. "127.0.0.1:0", and returns its base URL
+func (g *MockPaymentGateway) Start(addr string) (string, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return "", err
+	}
+
+	baseURL := "http://" + listener.Addr().String()
+	g.server = &http.Server{Handler: g.Handler()}
+	go func() {
+		if err := g.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Mock payment gateway stopped: %v", err)
+		}
+	}()
+
+	log.Printf("Mock payment gateway listening on %s", baseURL)
+	return baseURL, nil
+}
+
+func (g *MockPaymentGateway) Close() error {
+	if g.server == nil {
+		return nil
+	}
+	return g.server.Close()
+}
+
+func (g *MockPaymentGateway) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /charges", g.createCharge)
+	mux.HandleFunc("GET /charges/{ref}", g.getCharge)
+	mux.HandleFunc("POST /charges/{ref}/refunds", g.refundCharge)
+	mux.HandleFunc("POST /charges/{ref}/pay", g.payCharge)
+	mux.HandleFunc("POST /charges/{ref}/fail", g.failCharge)
+	return mux
+}
+
+type mockChargeRequest struct {
+	IntentID  uint      `json:"intent_id"`
+	Method    string    `json:"method"`
+	Amount    float64   `json:"amount"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (g *MockPaymentGateway) createCharge(w http.ResponseWriter, r *http.Request) {
+	var req mockChargeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeGatewayError(w, http.StatusBadRequest, "invalid charge request")
+		return
+	}
+	if req.IntentID == 0 || req.Amount <= 0 {
+		writeGatewayError(w, http.StatusBadRequest, "intent_id and a positive amount are required")
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.seq++
+	charge := &mockCharge{
+		Reference: fmt.Sprintf("MOCK-%d-%06d", time.Now().Unix(), g.seq),
+		IntentID:  req.IntentID,
+		Method:    req.Method,
+		Amount:    req.Amount,
+		Status:    domain.ChargeStatusPending,
+		ExpiresAt: req.ExpiresAt,
+	}
+
+	switch req.Method {
+	case "transfer":
+		charge.VirtualAccount = fmt.Sprintf("8808%012d", g.seq)
+	case "ewallet":
+		charge.QRPayload = fmt.Sprintf("MOCKQR|%s|%.2f", charge.Reference, charge.Amount)
+	case "credit_card":
+		charge.PaymentURL = "http://" + r.Host + "/charges/" + charge.Reference
+	default:
+		writeGatewayError(w, http.StatusBadRequest, "unsupported method")
+		return
+	}
+
+	g.charges[charge.Reference] = charge
+	writeGatewayJSON(w, http.StatusCreated, charge)
+}
+
+func (g *MockPaymentGateway) getCharge(w http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	charge, ok := g.charges[r.PathValue("ref")]
+	if !ok {
+		writeGatewayError(w, http.StatusNotFound, "charge not found")
+		return
+	}
+
+	// Unpaid charges lapse like they would at a real gateway
+	if charge.Status == domain.ChargeStatusPending && !charge.ExpiresAt.IsZero() && time.Now().After(charge.ExpiresAt) {
+		charge.Status = domain.ChargeStatusExpired
+	}
+	writeGatewayJSON(w, http.StatusOK, charge)
+}
+
+func (g *MockPaymentGateway) refundCharge(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Amount float64 `json:"amount"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Amount <= 0 {
+		writeGatewayError(w, http.StatusBadRequest, "a positive amount is required")
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	charge, ok := g.charges[r.PathValue("ref")]
+	if !ok {
+		writeGatewayError(w, http.StatusNotFound, "charge not found")
+		return
+	}
+	if charge.Status != domain.ChargeStatusPaid {
+		writeGatewayError(w, http.StatusConflict, "charge is not paid")
+		return
+	}
+
+	// A retried refund gives the first refund back instead of paying out again
+	key := r.Header.Get("Idempotency-Key")
+	if reference, ok := charge.refundKeys[key]; ok && key != "" {
+		writeGatewayJSON(w, http.StatusOK, map[string]interface{}{
+			"reference": reference,
+			"amount":    req.Amount,
+			"charge":    charge,
+		})
+		return
+	}
+
+	if charge.Refunded+req.Amount > charge.Amount+0.005 {
+		writeGatewayError(w, http.StatusConflict, "refund exceeds the paid amount")
+		return
+	}
+
+	charge.Refunded += req.Amount
+	charge.Refunds++
+	reference := fmt.Sprintf("%s-R%d", charge.Reference, charge.Refunds)
+	if key != "" {
+		if charge.refundKeys == nil {
+			charge.refundKeys = make(map[string]string)
+		}
+		charge.refundKeys[key] = reference
+	}
+	writeGatewayJSON(w, http.StatusOK, map[string]interface{}{
+		"reference": reference,
+		"amount":    req.Amount,
+		"charge":    charge,
+	})
+}
+
+func (g *MockPaymentGateway) payCharge(w http.ResponseWriter, r *http.Request) {
+	g.settle(w, r.PathValue("ref"), domain.ChargeStatusPaid)
+}
+
+func (g *MockPaymentGateway) failCharge(w http.ResponseWriter, r *http.Request) {
+	g.settle(w, r.PathValue("ref"), domain.ChargeStatusFailed)
+}
+
+// settle moves a pending charge to status and delivers the signed callback
+// before answering, so callers see the shop's reaction immediately
+func (g *MockPaymentGateway) settle(w http.ResponseWriter, reference, status string) {
+	g.mu.Lock()
+	charge, ok := g.charges[reference]
+	if !ok {
+		g.mu.Unlock()
+		writeGatewayError(w, http.StatusNotFound, "charge not found")
+		return
+	}
+	if charge.Status != domain.ChargeStatusPending {
+		g.mu.Unlock()
+		writeGatewayError(w, http.StatusConflict, "charge is not pending")
+		return
+	}
+
+	charge.Status = status
+	callback := domain.PaymentCallbackRequest{Status: "failed", GatewayRef: charge.Reference}
+	if status == domain.ChargeStatusPaid {
+		paidAt := time.Now().UTC().Truncate(time.Second)
+		charge.PaidAt = &paidAt
+		callback.Status = "success"
+		callback.PaidAt = paidAt.Format(time.RFC3339)
+	}
+	snapshot := *charge
+	g.mu.Unlock()
+
+	callbackStatus, err := g.sendCallback(snapshot.IntentID, &callback)
+	if err != nil {
+		log.Printf("Mock payment gateway failed to deliver callback for %s: %v", reference, err)
+	}
+
+	writeGatewayJSON(w, http.StatusOK, map[string]interface{}{
+		"charge":          snapshot,
+		"callback_status": callbackStatus,
+	})
+}
+
+func (g *MockPaymentGateway) sendCallback(intentID uint, callback *domain.PaymentCallbackRequest) (int, error) {
+	body, err := json.Marshal(callback)
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/%d", g.callbackURL, intentID), bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Payment-Gateway", MockPaymentGatewayName)
+	req.Header.Set("X-Payment-Timestamp", timestamp)
+	req.Header.Set("X-Payment-Signature", webhook.Sign(g.secret, timestamp, body))
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+func writeGatewayJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func writeGatewayError(w http.ResponseWriter, status int, message string) {
+	writeGatewayJSON(w, status, map[string]string{"error": message})
+}
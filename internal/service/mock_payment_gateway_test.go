@@ -0,0 +1,117 @@
+package service
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go-commerce/internal/domain"
+	"go-commerce/pkg/webhook"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startMockGateway runs a gateway whose callbacks are verified and decoded by
+// a stand-in shop endpoint
+func startMockGateway(t *testing.T) (domain.PaymentProvider, *MockPaymentGateway, string, chan *domain.PaymentWebhook) {
+	received := make(chan *domain.PaymentWebhook, 1)
+	var provider domain.PaymentProvider
+
+	shop := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		err := webhook.Verify("test-secret", r.Header.Get("X-Payment-Timestamp"), r.Header.Get("X-Payment-Signature"), body, time.Now(), time.Minute)
+		if err != nil || r.Header.Get("X-Payment-Gateway") != MockPaymentGatewayName || r.URL.Path != "/callbacks/11" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		payment, err := provider.ParseWebhook(body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received <- payment
+	}))
+	t.Cleanup(shop.Close)
+
+	gateway := NewMockPaymentGateway("test-secret", shop.URL+"/callbacks")
+	server := httptest.NewServer(gateway.Handler())
+	t.Cleanup(server.Close)
+
+	provider = NewMockPaymentProvider(server.URL)
+	return provider, gateway, server.URL, received
+}
+
+func TestMockPaymentGateway_TransferPaidFlow(t *testing.T) {
+	provider, _, gatewayURL, received := startMockGateway(t)
+
+	// Charge a bank transfer and get a virtual account
+	charge, err := provider.CreateCharge(&domain.ChargeRequest{IntentID: 11, Method: "transfer", Amount: 45000, ExpiresAt: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	assert.Equal(t, MockPaymentGatewayName, charge.Provider)
+	assert.Len(t, charge.VirtualAccount, 16)
+
+	status, err := provider.GetStatus(charge.Reference)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ChargeStatusPending, status.Status)
+
+	// The buyer pays; the gateway calls back with a signed body
+	resp, err := http.Post(gatewayURL+"/charges/"+charge.Reference+"/pay", "application/json", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	payment := <-received
+	assert.Equal(t, "success", payment.Status)
+	assert.Equal(t, charge.Reference, payment.GatewayRef)
+	assert.NotNil(t, payment.PaidAt)
+
+	status, err = provider.GetStatus(charge.Reference)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ChargeStatusPaid, status.Status)
+
+	// Refunds are capped at the paid amount
+	refundRef, err := provider.Refund(charge.Reference, 20000, "refund-1")
+	assert.NoError(t, err)
+	assert.Equal(t, charge.Reference+"-R1", refundRef)
+	_, err = provider.Refund(charge.Reference, 25000, "refund-2")
+	assert.NoError(t, err)
+	_, err = provider.Refund(charge.Reference, 1, "refund-3")
+	assert.Error(t, err)
+
+	// A retried refund is not paid out again
+	refundRef, err = provider.Refund(charge.Reference, 20000, "refund-1")
+	assert.NoError(t, err)
+	assert.Equal(t, charge.Reference+"-R1", refundRef)
+}
+
+func TestMockPaymentGateway_EwalletFailedFlow(t *testing.T) {
+	provider, _, gatewayURL, received := startMockGateway(t)
+
+	charge, err := provider.CreateCharge(&domain.ChargeRequest{IntentID: 11, Method: "ewallet", Amount: 12000, ExpiresAt: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(charge.QRPayload, "MOCKQR|"+charge.Reference))
+
+	resp, err := http.Post(gatewayURL+"/charges/"+charge.Reference+"/fail", "application/json", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	payment := <-received
+	assert.Equal(t, "failed", payment.Status)
+	assert.Nil(t, payment.PaidAt)
+
+	// Failed charges cannot be refunded
+	_, err = provider.Refund(charge.Reference, 12000, "refund-1")
+	assert.Error(t, err)
+}
+
+func TestMockPaymentGateway_RejectsUnknownMethod(t *testing.T) {
+	provider, _, _, _ := startMockGateway(t)
+
+	_, err := provider.CreateCharge(&domain.ChargeRequest{IntentID: 11, Method: "cod", Amount: 12000})
+	assert.Error(t, err)
+}
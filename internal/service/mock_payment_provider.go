@@ -0,0 +1,135 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"go-commerce/internal/domain"
+)
+
+// MockPaymentProvider talks to a MockPaymentGateway over HTTP the way a real
+// gateway integration would
+type MockPaymentProvider struct {
+	baseURL string
+	client  *http.Client
+}
+
+func NewMockPaymentProvider(baseURL string) domain.PaymentProvider {
+	return &MockPaymentProvider{
+		baseURL: baseURL,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+func (p *MockPaymentProvider) Name() string {
+	return MockPaymentGatewayName
+}
+
+func (p *MockPaymentProvider) CreateCharge(req *domain.ChargeRequest) (*domain.Charge, error) {
+	var charge mockCharge
+	err := p.call(http.MethodPost, "/charges", mockChargeRequest{
+		IntentID:  req.IntentID,
+		Method:    req.Method,
+		Amount:    req.Amount,
+		ExpiresAt: req.ExpiresAt,
+	}, &charge)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.Charge{
+		Provider:       MockPaymentGatewayName,
+		Reference:      charge.Reference,
+		VirtualAccount: charge.VirtualAccount,
+		QRPayload:      charge.QRPayload,
+		PaymentURL:     charge.PaymentURL,
+	}, nil
+}
+
+func (p *MockPaymentProvider) GetStatus(reference string) (*domain.ChargeStatus, error) {
+	var charge mockCharge
+	if err := p.call(http.MethodGet, "/charges/"+reference, nil, &charge); err != nil {
+		return nil, err
+	}
+	return &domain.ChargeStatus{Status: charge.Status, PaidAt: charge.PaidAt}, nil
+}
+
+func (p *MockPaymentProvider) Refund(reference string, amount float64, idempotencyKey string) (string, error) {
+	var refund struct {
+		Reference string `json:"reference"`
+	}
+	err := p.callWithKey(http.MethodPost, "/charges/"+reference+"/refunds", idempotencyKey, map[string]float64{"amount": amount}, &refund)
+	if err != nil {
+		return "", err
+	}
+	return refund.Reference, nil
+}
+
+func (p *MockPaymentProvider) ParseWebhook(body []byte) (*domain.PaymentWebhook, error) {
+	var req domain.PaymentCallbackRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, errors.New("invalid callback body")
+	}
+
+	payment := &domain.PaymentWebhook{Status: req.Status, GatewayRef: req.GatewayRef}
+	if req.PaidAt != "" {
+		paidAt, err := time.Parse(time.RFC3339, req.PaidAt)
+		if err != nil {
+			return nil, errors.New("paid_at must be an RFC 3339 timestamp")
+		}
+		payment.PaidAt = &paidAt
+	}
+	return payment, nil
+}
+
+func (p *MockPaymentProvider) call(method, path string, in, out interface{}) error {
+	return p.callWithKey(method, path, "", in, out)
+}
+
+// callWithKey sends the request with an Idempotency-Key header, so the
+// gateway applies a retried request once
+func (p *MockPaymentProvider) callWithKey(method, path, idempotencyKey string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		payload, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequest(method, p.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("payment gateway unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var gatewayErr struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&gatewayErr)
+		return fmt.Errorf("payment gateway returned %d: %s", resp.StatusCode, gatewayErr.Error)
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
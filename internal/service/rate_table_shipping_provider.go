@@ -0,0 +1,86 @@
+package service
+
+import (
+	"go-commerce/internal/domain"
+)
+
+// RateTableShippingProviderName is the provider name of the shipping rate table
+const RateTableShippingProviderName = "rate_table"
+
+// RateTableShippingProvider prices parcels from the shipping_rates table. A
+// courier service can have rows for the nationwide fallback, for province
+// pairs and for city pairs; the most specific row matching the parcel wins.
+type RateTableShippingProvider struct {
+	rateRepo domain.ShippingRateRepository
+}
+
+func NewRateTableShippingProvider(rateRepo domain.ShippingRateRepository) domain.ShippingRateProvider {
+	return &RateTableShippingProvider{rateRepo: rateRepo}
+}
+
+func (p *RateTableShippingProvider) Name() string {
+	return RateTableShippingProviderName
+}
+
+// Rates charges the price per kg for every started kilogram, at least one
+func (p *RateTableShippingProvider) Rates(parcel domain.ShippingParcel) ([]domain.ShippingRate, error) {
+	rules, err := p.rateRepo.FindMatching(parcel)
+	if err != nil {
+		return nil, err
+	}
+
+	type courierService struct {
+		courier string
+		service string
+	}
+	best := make(map[courierService]*domain.ShippingRateRule)
+	var order []courierService
+	for _, rule := range rules {
+		key := courierService{rule.Courier, rule.Service}
+		current, ok := best[key]
+		if !ok {
+			order = append(order, key)
+		}
+		if !ok || rateRuleSpecificity(rule) > rateRuleSpecificity(current) {
+			best[key] = rule
+		}
+	}
+
+	kilograms := (parcel.WeightGrams + 999) / 1000
+	if kilograms < 1 {
+		kilograms = 1
+	}
+
+	rates := make([]domain.ShippingRate, 0, len(order))
+	for _, key := range order {
+		rule := best[key]
+		rates = append(rates, domain.ShippingRate{
+			Courier:       rule.Courier,
+			Service:       rule.Service,
+			Fee:           rule.PricePerKg * float64(kilograms),
+			EstimatedDays: rule.EstimatedDays,
+		})
+	}
+
+	return rates, nil
+}
+
+// rateRuleSpecificity ranks how closely a rule targets its regions. A city
+// outweighs a province so a city pair beats a province pair.
+func rateRuleSpecificity(rule *domain.ShippingRateRule) int {
+	score := 0
+	for _, region := range []struct {
+		id     string
+		weight int
+	}{
+		{rule.OriginProvinceID, 1},
+		{rule.OriginCityID, 2},
+		{rule.DestinationProvinceID, 1},
+		{rule.DestinationCityID, 2},
+	} {
+		if region.id != "" {
+			score += region.weight
+		}
+	}
+	return score
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"testing"
+
+	"go-commerce/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubShippingRateRepository struct {
+	rules []*domain.ShippingRateRule
+}
+
+func (r *stubShippingRateRepository) FindMatching(parcel domain.ShippingParcel) ([]*domain.ShippingRateRule, error) {
+	return r.rules, nil
+}
+
+func TestRateTableShippingProvider_MostSpecificRuleWins(t *testing.T) {
+	provider := NewRateTableShippingProvider(&stubShippingRateRepository{rules: []*domain.ShippingRateRule{
+		{ID: 1, Courier: "jne", Service: "REG", PricePerKg: 25000, EstimatedDays: "3-5"},
+		{ID: 2, Courier: "jne", Service: "REG", OriginProvinceID: "31", DestinationProvinceID: "31", PricePerKg: 10000, EstimatedDays: "1-2"},
+		{ID: 3, Courier: "jne", Service: "REG", OriginCityID: "3171", DestinationCityID: "3171", PricePerKg: 8000, EstimatedDays: "1"},
+		{ID: 4, Courier: "jnt", Service: "EZ", PricePerKg: 23000},
+	}})
+
+	// 2.1 kg is charged as 3 kg
+	rates, err := provider.Rates(domain.ShippingParcel{
+		OriginProvinceID:      "31",
+		OriginCityID:          "3171",
+		DestinationProvinceID: "31",
+		DestinationCityID:     "3171",
+		WeightGrams:           2100,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []domain.ShippingRate{
+		{Courier: "jne", Service: "REG", Fee: 24000, EstimatedDays: "1"},
+		{Courier: "jnt", Service: "EZ", Fee: 69000},
+	}, rates)
+}
+
+func TestRateTableShippingProvider_ChargesAtLeastOneKilogram(t *testing.T) {
+	provider := NewRateTableShippingProvider(&stubShippingRateRepository{rules: []*domain.ShippingRateRule{
+		{ID: 1, Courier: "jne", Service: "REG", PricePerKg: 25000},
+	}})
+
+	rates, err := provider.Rates(domain.ShippingParcel{WeightGrams: 0})
+
+	require.NoError(t, err)
+	require.Len(t, rates, 1)
+	assert.Equal(t, 25000.0, rates[0].Fee)
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"fmt"
+	"net"
+	"net/smtp"
+
+	"go-commerce/internal/domain"
+)
+
+// SMTPMailer sends emails through an SMTP relay using PLAIN auth when
+// credentials are configured
+type SMTPMailer struct {
+	host     string
+	port     string
+	username string
+	password string
+	from     string
+}
+
+func NewSMTPMailer(host, port, username, password, from string) domain.Mailer {
+	return &SMTPMailer{
+		host:     host,
+		port:     port,
+		username: username,
+		password: password,
+		from:     from,
+	}
+}
+
+func (m *SMTPMailer) Send(msg *domain.EmailMessage) error {
+	var auth smtp.Auth
+	if m.username != "" {
+		auth = smtp.PlainAuth("", m.username, m.password, m.host)
+	}
+
+	addr := net.JoinHostPort(m.host, m.port)
+	if err := smtp.SendMail(addr, auth, m.from, []string{msg.To}, buildMessage(m.from, msg)); err != nil {
+		return fmt.Errorf("send email to %s: %w", msg.To, err)
+	}
+	return nil
+}
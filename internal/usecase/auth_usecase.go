@@ -9,23 +9,47 @@ import (
 	"go-commerce/internal/domain"
 	"go-commerce/pkg/jwt"
 
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
 type AuthUsecase struct {
-	userRepo   domain.UserRepository
-	storeRepo  domain.StoreRepository
-	jwtManager *jwt.JWTManager
-	db         *gorm.DB
+	userRepo         domain.UserRepository
+	storeRepo        domain.StoreRepository
+	roleRepo         domain.RoleRepository
+	refreshTokenRepo domain.RefreshTokenRepository
+	revocationStore  domain.TokenRevocationStore
+	emailVerifier    *EmailVerificationUsecase
+	loginThrottle    *LoginThrottleUsecase
+	twoFactor        *TwoFactorUsecase
+	jwtManager       *jwt.JWTManager
+	db               *gorm.DB
 }
 
-func NewAuthUsecase(userRepo domain.UserRepository, storeRepo domain.StoreRepository, jwtManager *jwt.JWTManager, db *gorm.DB) *AuthUsecase {
+func NewAuthUsecase(
+	userRepo domain.UserRepository,
+	storeRepo domain.StoreRepository,
+	roleRepo domain.RoleRepository,
+	refreshTokenRepo domain.RefreshTokenRepository,
+	revocationStore domain.TokenRevocationStore,
+	emailVerifier *EmailVerificationUsecase,
+	loginThrottle *LoginThrottleUsecase,
+	twoFactor *TwoFactorUsecase,
+	jwtManager *jwt.JWTManager,
+	db *gorm.DB,
+) *AuthUsecase {
 	return &AuthUsecase{
-		userRepo:   userRepo,
-		storeRepo:  storeRepo,
-		jwtManager: jwtManager,
-		db:         db,
+		userRepo:         userRepo,
+		storeRepo:        storeRepo,
+		roleRepo:         roleRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		revocationStore:  revocationStore,
+		emailVerifier:    emailVerifier,
+		loginThrottle:    loginThrottle,
+		twoFactor:        twoFactor,
+		jwtManager:       jwtManager,
+		db:               db,
 	}
 }
 
@@ -80,11 +104,16 @@ func (u *AuthUsecase) Register(req *domain.RegisterRequest) (*domain.AuthRespons
 
 	// Auto create store
 	username := strings.ToLower(strings.ReplaceAll(req.Name, " ", ""))
+	storeStatus := "active" // Auto-activate store on registration
+	if u.emailVerifier != nil && u.emailVerifier.RequiresVerifiedStoreOwner() {
+		// Seller activates the store once the email is verified
+		storeStatus = "inactive"
+	}
 	store := &domain.Store{
 		UserID:      user.ID,
 		Name:        "toko-" + username,
 		Description: "Welcome to " + req.Name + "'s Store",
-		Status:      "active", // Auto-activate store on registration
+		Status:      storeStatus,
 	}
 
 	if err := tx.Create(store).Error; err != nil {
@@ -93,91 +122,357 @@ func (u *AuthUsecase) Register(req *domain.RegisterRequest) (*domain.AuthRespons
 		return nil, errors.New("failed to create store")
 	}
 
+	// Store owners get the seller role
+	if u.roleRepo != nil {
+		sellerRole, err := u.roleRepo.GetByName(domain.RoleSeller)
+		if err != nil {
+			tx.Rollback()
+			log.Printf("Error getting seller role: %v", err)
+			return nil, errors.New("failed to assign seller role")
+		}
+		if err := u.roleRepo.AssignToUserWithTx(tx, user.ID, sellerRole.ID); err != nil {
+			tx.Rollback()
+			log.Printf("Error assigning seller role: %v", err)
+			return nil, errors.New("failed to assign seller role")
+		}
+	}
+
 	// Commit transaction
 	if err := tx.Commit().Error; err != nil {
 		return nil, errors.New("failed to complete registration")
 	}
 
-	// Send welcome email async
-	go func() {
-		// Simulate sending welcome email
-		time.Sleep(100 * time.Millisecond)
-		// log.Printf("Welcome email sent to %s", user.Email)
-	}()
-
-	// Generate tokens
-	accessToken, err := u.jwtManager.GenerateAccessToken(user.ID, user.Email, user.IsAdmin)
-	if err != nil {
-		return nil, errors.New("failed to generate access token")
-	}
-
-	refreshToken, err := u.jwtManager.GenerateRefreshToken(user.ID)
-	if err != nil {
-		return nil, errors.New("failed to generate refresh token")
+	// Send verification email async
+	if u.emailVerifier != nil {
+		recipient := *user
+		go func() {
+			if err := u.emailVerifier.SendVerificationEmail(&recipient); err != nil {
+				log.Printf("Error sending verification email to %s: %v", recipient.Email, err)
+			}
+		}()
 	}
 
-	// Remove password from response
-	user.Password = ""
-
-	return &domain.AuthResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		User:         user,
-	}, nil
+	// Generate tokens, starting a new refresh token family
+	return u.issueTokens(user, uuid.NewString())
 }
 
 func (u *AuthUsecase) Login(req *domain.LoginRequest) (*domain.AuthResponse, error) {
+	// Refuse early while the client IP is locked out
+	if u.loginThrottle != nil {
+		if err := u.loginThrottle.CheckIP(req.ClientIP); err != nil {
+			return nil, err
+		}
+	}
+
 	// Get user by email
 	user, err := u.userRepo.GetByEmail(req.Email)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
+			if u.loginThrottle != nil {
+				if lockErr := u.loginThrottle.RecordFailure(req.ClientIP, nil); lockErr != nil {
+					return nil, lockErr
+				}
+			}
 			return nil, errors.New("invalid email or password")
 		}
 		return nil, errors.New("failed to get user")
 	}
 
+	if u.loginThrottle != nil {
+		if err := u.loginThrottle.CheckAccount(user); err != nil {
+			return nil, err
+		}
+	}
+
 	// Verify password
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		if u.loginThrottle != nil {
+			if lockErr := u.loginThrottle.RecordFailure(req.ClientIP, user); lockErr != nil {
+				return nil, lockErr
+			}
+		}
 		return nil, errors.New("invalid email or password")
 	}
 
+	// With 2FA the login only completes at VerifyTwoFactorLogin, so failure
+	// counters are kept until the second factor is checked
+	if u.twoFactor != nil && u.twoFactor.IsEnabled(user) {
+		challengeToken, err := u.twoFactor.IssueChallenge(user)
+		if err != nil {
+			return nil, err
+		}
+		return &domain.AuthResponse{
+			TwoFactorRequired: true,
+			ChallengeToken:    challengeToken,
+		}, nil
+	}
+
+	return u.completeLogin(user)
+}
+
+// VerifyTwoFactorLogin completes a login that returned a challenge token.
+// Wrong codes count as failed logins for throttling.
+func (u *AuthUsecase) VerifyTwoFactorLogin(req *domain.TwoFactorLoginRequest) (*domain.AuthResponse, error) {
+	if u.twoFactor == nil {
+		return nil, errors.New("two-factor authentication is not available")
+	}
+
+	if u.loginThrottle != nil {
+		if err := u.loginThrottle.CheckIP(req.ClientIP); err != nil {
+			return nil, err
+		}
+	}
+
+	user, err := u.twoFactor.ResolveChallenge(req.ChallengeToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.loginThrottle != nil {
+		if err := u.loginThrottle.CheckAccount(user); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := u.twoFactor.VerifyCode(user, req.Code); err != nil {
+		if u.loginThrottle != nil && err.Error() == "INVALID_TWO_FACTOR_CODE" {
+			if lockErr := u.loginThrottle.RecordFailure(req.ClientIP, user); lockErr != nil {
+				return nil, lockErr
+			}
+		}
+		return nil, err
+	}
+
+	return u.completeLogin(user)
+}
+
+func (u *AuthUsecase) completeLogin(user *domain.User) (*domain.AuthResponse, error) {
+	if u.loginThrottle != nil {
+		u.loginThrottle.RecordSuccess(user)
+	}
+
 	// Update last login async
 	go func() {
 		now := time.Now()
 		u.userRepo.UpdateLastLogin(user.ID, now)
 	}()
 
-	// Generate tokens
-	accessToken, err := u.jwtManager.GenerateAccessToken(user.ID, user.Email, user.IsAdmin)
+	// Generate tokens, starting a new refresh token family
+	return u.issueTokens(user, uuid.NewString())
+}
+
+func (u *AuthUsecase) GetUserByID(id uint64) (*domain.User, error) {
+	user, err := u.userRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, errors.New("failed to get user")
+	}
+
+	// Remove password from response
+	user.Password = ""
+	return user, nil
+}
+
+// RefreshToken exchanges a valid refresh token for a new access/refresh pair.
+// The presented token is rotated out; presenting it again revokes the whole
+// token family, since only a stolen copy would be replayed.
+func (u *AuthUsecase) RefreshToken(req *domain.RefreshTokenRequest) (*domain.AuthResponse, error) {
+	claims, err := u.jwtManager.ValidateRefreshToken(req.RefreshToken)
+	if err != nil {
+		return nil, errors.New("invalid or expired refresh token")
+	}
+
+	stored, err := u.refreshTokenRepo.GetByTokenID(claims.ID)
+	if err != nil {
+		return nil, errors.New("invalid or expired refresh token")
+	}
+
+	if stored.UserID != claims.UserID || stored.FamilyID != claims.FamilyID {
+		return nil, errors.New("invalid or expired refresh token")
+	}
+
+	if stored.RevokedAt != nil {
+		return nil, errors.New("refresh token revoked")
+	}
+
+	if stored.RotatedAt != nil {
+		// Reuse detected - invalidate every token in this family
+		if err := u.refreshTokenRepo.RevokeFamily(stored.FamilyID); err != nil {
+			log.Printf("Error revoking refresh token family %s: %v", stored.FamilyID, err)
+		}
+		return nil, errors.New("refresh token reuse detected")
+	}
+
+	user, err := u.userRepo.GetByID(claims.UserID)
+	if err != nil {
+		return nil, errors.New("invalid or expired refresh token")
+	}
+
+	if user.Status == "blocked" {
+		return nil, errors.New("account is blocked")
+	}
+
+	newTokenID := uuid.NewString()
+	rotated, err := u.refreshTokenRepo.MarkRotated(stored.TokenID, newTokenID)
+	if err != nil {
+		return nil, errors.New("failed to rotate refresh token")
+	}
+	if !rotated {
+		// Lost a race against another refresh with the same token
+		if err := u.refreshTokenRepo.RevokeFamily(stored.FamilyID); err != nil {
+			log.Printf("Error revoking refresh token family %s: %v", stored.FamilyID, err)
+		}
+		return nil, errors.New("refresh token reuse detected")
+	}
+
+	return u.issueTokensWithID(user, newTokenID, stored.FamilyID)
+}
+
+// Logout revokes the access token used for the request. When the client also
+// sends its refresh token, that login's refresh token family is revoked too.
+func (u *AuthUsecase) Logout(userID uint64, tokenID string, expiresAt time.Time, req *domain.LogoutRequest) error {
+	if tokenID == "" {
+		return errors.New("token cannot be revoked")
+	}
+
+	if err := u.revocationStore.Revoke(tokenID, userID, expiresAt); err != nil {
+		log.Printf("Error revoking token %s: %v", tokenID, err)
+		return errors.New("failed to logout")
+	}
+
+	if req != nil && req.RefreshToken != "" {
+		claims, err := u.jwtManager.ValidateRefreshToken(req.RefreshToken)
+		if err == nil && claims.UserID == userID {
+			if err := u.refreshTokenRepo.RevokeFamily(claims.FamilyID); err != nil {
+				log.Printf("Error revoking refresh token family %s: %v", claims.FamilyID, err)
+				return errors.New("failed to logout")
+			}
+		}
+	}
+
+	return nil
+}
+
+// LogoutAll revokes every access and refresh token issued to the user so far,
+// including the token of the request
+func (u *AuthUsecase) LogoutAll(userID uint64) error {
+	if err := revokeAllSessions(u.revocationStore, u.refreshTokenRepo, u.jwtManager, userID); err != nil {
+		return errors.New("failed to logout from all devices")
+	}
+	return nil
+}
+
+// revokeAllSessions blocks every access token issued to the user up to the
+// end of the current second and revokes all of the user's refresh tokens
+func revokeAllSessions(revocationStore domain.TokenRevocationStore, refreshTokenRepo domain.RefreshTokenRepository, jwtManager *jwt.JWTManager, userID uint64) error {
+	// JWT timestamps have second precision, so the cutoff is rounded up:
+	// no token from this second survives, and the user has no session left
+	// to keep anyway
+	revokedBefore := time.Now().Truncate(time.Second).Add(time.Second)
+	if err := revokeAccessTokens(revocationStore, jwtManager, userID, revokedBefore); err != nil {
+		return err
+	}
+
+	if err := refreshTokenRepo.RevokeByUserID(userID); err != nil {
+		log.Printf("Error revoking refresh tokens for user %d: %v", userID, err)
+		return err
+	}
+
+	return nil
+}
+
+// revokeAccessTokens blocks every access token issued to the user before
+// revokedBefore
+func revokeAccessTokens(revocationStore domain.TokenRevocationStore, jwtManager *jwt.JWTManager, userID uint64, revokedBefore time.Time) error {
+	expiresAt := revokedBefore.Add(jwtManager.AccessTokenDuration())
+
+	if err := revocationStore.RevokeAllForUser(userID, revokedBefore, expiresAt); err != nil {
+		log.Printf("Error revoking tokens for user %d: %v", userID, err)
+		return err
+	}
+	return nil
+}
+
+// issueTokens generates an access token and a persisted refresh token that
+// belongs to familyID
+func (u *AuthUsecase) issueTokens(user *domain.User, familyID string) (*domain.AuthResponse, error) {
+	return u.issueTokensWithID(user, uuid.NewString(), familyID)
+}
+
+func (u *AuthUsecase) issueTokensWithID(user *domain.User, tokenID, familyID string) (*domain.AuthResponse, error) {
+	roles, permissions, err := u.loadRoles(user.ID)
+	if err != nil {
+		log.Printf("Error loading roles for user %d: %v", user.ID, err)
+		return nil, errors.New("failed to load user roles")
+	}
+
+	// Admins who still have to set up a required second factor get a token
+	// without admin permissions; enrolling and logging in again restores them
+	isAdmin := user.IsAdmin
+	setupRequired := u.twoFactor != nil && u.twoFactor.SetupRequired(user, permissions)
+	if setupRequired {
+		isAdmin = false
+		permissions = nil
+	}
+
+	accessToken, err := u.jwtManager.GenerateAccessToken(user.ID, user.Email, isAdmin, roles, permissions)
 	if err != nil {
 		return nil, errors.New("failed to generate access token")
 	}
 
-	refreshToken, err := u.jwtManager.GenerateRefreshToken(user.ID)
+	refreshToken, refreshClaims, err := u.jwtManager.GenerateRefreshToken(user.ID, tokenID, familyID)
 	if err != nil {
 		return nil, errors.New("failed to generate refresh token")
 	}
 
+	if err := u.refreshTokenRepo.Create(&domain.RefreshToken{
+		TokenID:   tokenID,
+		FamilyID:  familyID,
+		UserID:    user.ID,
+		ExpiresAt: refreshClaims.ExpiresAt.Time,
+	}); err != nil {
+		log.Printf("Error storing refresh token: %v", err)
+		return nil, errors.New("failed to generate refresh token")
+	}
+
 	// Remove password from response
 	user.Password = ""
 
 	return &domain.AuthResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		User:         user,
+		AccessToken:            accessToken,
+		RefreshToken:           refreshToken,
+		User:                   user,
+		Roles:                  roles,
+		TwoFactorSetupRequired: setupRequired,
 	}, nil
 }
 
-func (u *AuthUsecase) GetUserByID(id uint64) (*domain.User, error) {
-	user, err := u.userRepo.GetByID(id)
+// loadRoles returns the names of the user's roles and the distinct
+// permissions they grant
+func (u *AuthUsecase) loadRoles(userID uint64) ([]string, []string, error) {
+	if u.roleRepo == nil {
+		return nil, nil, nil
+	}
+
+	userRoles, err := u.roleRepo.GetUserRoles(userID)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+		return nil, nil, err
+	}
+
+	roles := make([]string, 0, len(userRoles))
+	permissions := []string{}
+	seen := make(map[string]bool)
+	for _, role := range userRoles {
+		roles = append(roles, role.Name)
+		for _, permission := range role.Permissions {
+			if !seen[permission.Name] {
+				seen[permission.Name] = true
+				permissions = append(permissions, permission.Name)
+			}
 		}
-		return nil, errors.New("failed to get user")
 	}
 
-	// Remove password from response
-	user.Password = ""
-	return user, nil
+	return roles, permissions, nil
 }
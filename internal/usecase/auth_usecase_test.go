@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"go-commerce/internal/domain"
+	"go-commerce/internal/repository/memory"
 	"go-commerce/internal/usecase/mocks"
 	"go-commerce/pkg/jwt"
 
@@ -18,13 +19,15 @@ func TestAuthUsecase_Login_Success(t *testing.T) {
 	// Setup
 	mockUserRepo := new(mocks.MockUserRepository)
 	mockStoreRepo := new(mocks.MockStoreRepository)
+	mockRefreshTokenRepo := new(mocks.MockRefreshTokenRepository)
 	jwtManager := jwt.NewJWTManager("test-secret", 24, 168)
 	
 	authUsecase := &AuthUsecase{
-		userRepo:   mockUserRepo,
-		storeRepo:  mockStoreRepo,
-		jwtManager: jwtManager,
-		db:         nil, // Not needed for login test
+		userRepo:         mockUserRepo,
+		storeRepo:        mockStoreRepo,
+		refreshTokenRepo: mockRefreshTokenRepo,
+		jwtManager:       jwtManager,
+		db:               nil, // Not needed for login test
 	}
 
 	// Test data
@@ -48,6 +51,7 @@ func TestAuthUsecase_Login_Success(t *testing.T) {
 	// Mock expectations
 	mockUserRepo.On("GetByEmail", email).Return(user, nil)
 	mockUserRepo.On("UpdateLastLogin", user.ID, mock.AnythingOfType("time.Time")).Return(nil)
+	mockRefreshTokenRepo.On("Create", mock.AnythingOfType("*domain.RefreshToken")).Return(nil)
 
 	// Execute
 	result, err := authUsecase.Login(req)
@@ -64,6 +68,7 @@ func TestAuthUsecase_Login_Success(t *testing.T) {
 	// Wait a bit for goroutine to complete
 	time.Sleep(10 * time.Millisecond)
 	mockUserRepo.AssertExpectations(t)
+	mockRefreshTokenRepo.AssertExpectations(t)
 }
 
 func TestAuthUsecase_Login_InvalidEmail(t *testing.T) {
@@ -206,4 +211,214 @@ func TestAuthUsecase_GetUserByID_NotFound(t *testing.T) {
 	assert.Contains(t, err.Error(), "user not found")
 
 	mockUserRepo.AssertExpectations(t)
-}
\ No newline at end of file
+}
+
+func TestAuthUsecase_RefreshToken_Success(t *testing.T) {
+	// Setup
+	mockUserRepo := new(mocks.MockUserRepository)
+	mockRefreshTokenRepo := new(mocks.MockRefreshTokenRepository)
+	jwtManager := jwt.NewJWTManager("test-secret", 24, 168)
+
+	authUsecase := NewAuthUsecase(mockUserRepo, nil, nil, mockRefreshTokenRepo, nil, nil, nil, nil, jwtManager, nil)
+
+	user := &domain.User{ID: 1, Email: "test@example.com", Status: "active"}
+	token, _, err := jwtManager.GenerateRefreshToken(user.ID, "token-1", "family-1")
+	assert.NoError(t, err)
+
+	stored := &domain.RefreshToken{TokenID: "token-1", FamilyID: "family-1", UserID: user.ID}
+
+	// Mock expectations
+	mockRefreshTokenRepo.On("GetByTokenID", "token-1").Return(stored, nil)
+	mockUserRepo.On("GetByID", user.ID).Return(user, nil)
+	mockRefreshTokenRepo.On("MarkRotated", "token-1", mock.AnythingOfType("string")).Return(true, nil)
+	mockRefreshTokenRepo.On("Create", mock.MatchedBy(func(rt *domain.RefreshToken) bool {
+		return rt.FamilyID == "family-1" && rt.UserID == user.ID && rt.TokenID != "token-1"
+	})).Return(nil)
+
+	// Execute
+	result, err := authUsecase.RefreshToken(&domain.RefreshTokenRequest{RefreshToken: token})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotEmpty(t, result.AccessToken)
+	assert.NotEmpty(t, result.RefreshToken)
+	assert.NotEqual(t, token, result.RefreshToken)
+
+	newClaims, err := jwtManager.ValidateRefreshToken(result.RefreshToken)
+	assert.NoError(t, err)
+	assert.Equal(t, "family-1", newClaims.FamilyID)
+
+	mockUserRepo.AssertExpectations(t)
+	mockRefreshTokenRepo.AssertExpectations(t)
+}
+
+func TestAuthUsecase_RefreshToken_ReuseRevokesFamily(t *testing.T) {
+	// Setup
+	mockUserRepo := new(mocks.MockUserRepository)
+	mockRefreshTokenRepo := new(mocks.MockRefreshTokenRepository)
+	jwtManager := jwt.NewJWTManager("test-secret", 24, 168)
+
+	authUsecase := NewAuthUsecase(mockUserRepo, nil, nil, mockRefreshTokenRepo, nil, nil, nil, nil, jwtManager, nil)
+
+	token, _, err := jwtManager.GenerateRefreshToken(1, "token-1", "family-1")
+	assert.NoError(t, err)
+
+	rotatedAt := time.Now().Add(-time.Minute)
+	stored := &domain.RefreshToken{TokenID: "token-1", FamilyID: "family-1", UserID: 1, RotatedAt: &rotatedAt}
+
+	// Mock expectations
+	mockRefreshTokenRepo.On("GetByTokenID", "token-1").Return(stored, nil)
+	mockRefreshTokenRepo.On("RevokeFamily", "family-1").Return(nil)
+
+	// Execute
+	result, err := authUsecase.RefreshToken(&domain.RefreshTokenRequest{RefreshToken: token})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Contains(t, err.Error(), "reuse detected")
+
+	mockRefreshTokenRepo.AssertExpectations(t)
+	mockUserRepo.AssertNotCalled(t, "GetByID", mock.Anything)
+}
+
+func TestAuthUsecase_RefreshToken_ConcurrentRotationRevokesFamily(t *testing.T) {
+	// Setup
+	mockUserRepo := new(mocks.MockUserRepository)
+	mockRefreshTokenRepo := new(mocks.MockRefreshTokenRepository)
+	jwtManager := jwt.NewJWTManager("test-secret", 24, 168)
+
+	authUsecase := NewAuthUsecase(mockUserRepo, nil, nil, mockRefreshTokenRepo, nil, nil, nil, nil, jwtManager, nil)
+
+	token, _, err := jwtManager.GenerateRefreshToken(1, "token-1", "family-1")
+	assert.NoError(t, err)
+
+	stored := &domain.RefreshToken{TokenID: "token-1", FamilyID: "family-1", UserID: 1}
+
+	// Mock expectations - another request rotated the token first
+	mockRefreshTokenRepo.On("GetByTokenID", "token-1").Return(stored, nil)
+	mockUserRepo.On("GetByID", uint64(1)).Return(&domain.User{ID: 1, Status: "active"}, nil)
+	mockRefreshTokenRepo.On("MarkRotated", "token-1", mock.AnythingOfType("string")).Return(false, nil)
+	mockRefreshTokenRepo.On("RevokeFamily", "family-1").Return(nil)
+
+	// Execute
+	result, err := authUsecase.RefreshToken(&domain.RefreshTokenRequest{RefreshToken: token})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	mockRefreshTokenRepo.AssertExpectations(t)
+	mockRefreshTokenRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestAuthUsecase_RefreshToken_InvalidToken(t *testing.T) {
+	// Setup
+	mockRefreshTokenRepo := new(mocks.MockRefreshTokenRepository)
+	jwtManager := jwt.NewJWTManager("test-secret", 24, 168)
+
+	authUsecase := NewAuthUsecase(nil, nil, nil, mockRefreshTokenRepo, nil, nil, nil, nil, jwtManager, nil)
+
+	// Access tokens must not be accepted by the refresh endpoint
+	accessToken, err := jwtManager.GenerateAccessToken(1, "test@example.com", false, nil, nil)
+	assert.NoError(t, err)
+
+	// Execute
+	result, err := authUsecase.RefreshToken(&domain.RefreshTokenRequest{RefreshToken: accessToken})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	mockRefreshTokenRepo.AssertNotCalled(t, "GetByTokenID", mock.Anything)
+}
+
+func TestAuthUsecase_Logout_RevokesTokenAndRefreshFamily(t *testing.T) {
+	// Setup
+	mockRefreshTokenRepo := new(mocks.MockRefreshTokenRepository)
+	revocationStore := memory.NewTokenRevocationStore()
+	jwtManager := jwt.NewJWTManager("test-secret", 24, 168)
+
+	authUsecase := NewAuthUsecase(nil, nil, nil, mockRefreshTokenRepo, revocationStore, nil, nil, nil, jwtManager, nil)
+
+	accessToken, err := jwtManager.GenerateAccessToken(1, "test@example.com", false, nil, nil)
+	assert.NoError(t, err)
+	claims, err := jwtManager.ValidateToken(accessToken)
+	assert.NoError(t, err)
+
+	refreshToken, _, err := jwtManager.GenerateRefreshToken(1, "token-1", "family-1")
+	assert.NoError(t, err)
+
+	// Mock expectations
+	mockRefreshTokenRepo.On("RevokeFamily", "family-1").Return(nil)
+
+	// Execute
+	err = authUsecase.Logout(1, claims.ID, claims.ExpiresAt.Time, &domain.LogoutRequest{RefreshToken: refreshToken})
+
+	// Assert
+	assert.NoError(t, err)
+	revoked, err := revocationStore.IsRevoked(claims.ID, 1, claims.IssuedAt.Time)
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+	mockRefreshTokenRepo.AssertExpectations(t)
+}
+
+func TestAuthUsecase_Logout_IgnoresForeignRefreshToken(t *testing.T) {
+	// Setup
+	mockRefreshTokenRepo := new(mocks.MockRefreshTokenRepository)
+	revocationStore := memory.NewTokenRevocationStore()
+	jwtManager := jwt.NewJWTManager("test-secret", 24, 168)
+
+	authUsecase := NewAuthUsecase(nil, nil, nil, mockRefreshTokenRepo, revocationStore, nil, nil, nil, jwtManager, nil)
+
+	// Refresh token belongs to another user
+	refreshToken, _, err := jwtManager.GenerateRefreshToken(2, "token-2", "family-2")
+	assert.NoError(t, err)
+
+	// Execute
+	err = authUsecase.Logout(1, "access-1", time.Now().Add(time.Hour), &domain.LogoutRequest{RefreshToken: refreshToken})
+
+	// Assert
+	assert.NoError(t, err)
+	mockRefreshTokenRepo.AssertNotCalled(t, "RevokeFamily", mock.Anything)
+}
+
+func TestAuthUsecase_LogoutAll_RevokesExistingTokens(t *testing.T) {
+	// Setup
+	mockRefreshTokenRepo := new(mocks.MockRefreshTokenRepository)
+	revocationStore := memory.NewTokenRevocationStore()
+	jwtManager := jwt.NewJWTManager("test-secret", 24, 168)
+
+	authUsecase := NewAuthUsecase(nil, nil, nil, mockRefreshTokenRepo, revocationStore, nil, nil, nil, jwtManager, nil)
+
+	accessToken, err := jwtManager.GenerateAccessToken(1, "test@example.com", false, nil, nil)
+	assert.NoError(t, err)
+	claims, err := jwtManager.ValidateToken(accessToken)
+	assert.NoError(t, err)
+
+	// Mock expectations
+	mockRefreshTokenRepo.On("RevokeByUserID", uint64(1)).Return(nil)
+
+	// Execute
+	err = authUsecase.LogoutAll(1)
+
+	// Assert
+	assert.NoError(t, err)
+	revoked, err := revocationStore.IsRevoked(claims.ID, 1, claims.IssuedAt.Time)
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+
+	// Tokens issued earlier are revoked by the cutoff
+	revoked, err = revocationStore.IsRevoked("earlier-token", 1, claims.IssuedAt.Time.Add(-time.Second))
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+
+	// Other tokens issued within the same second are revoked too
+	revoked, err = revocationStore.IsRevoked("same-second-token", 1, time.Now().Truncate(time.Second))
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+
+	// Tokens issued from the next second on remain valid
+	revoked, err = revocationStore.IsRevoked("later-token", 1, time.Now().Truncate(time.Second).Add(time.Second))
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+	mockRefreshTokenRepo.AssertExpectations(t)
+}
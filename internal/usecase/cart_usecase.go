@@ -0,0 +1,187 @@
+package usecase
+
+import (
+	"errors"
+
+	"go-commerce/internal/domain"
+)
+
+type CartUsecase struct {
+	cartRepo           domain.CartRepository
+	transactionUsecase *TransactionUsecase
+}
+
+func NewCartUsecase(cartRepo domain.CartRepository, transactionUsecase *TransactionUsecase) *CartUsecase {
+	return &CartUsecase{
+		cartRepo:           cartRepo,
+		transactionUsecase: transactionUsecase,
+	}
+}
+
+// GetCart returns the user's cart with every item re-checked against the
+// current product, store and stock, using the same rules as checkout
+func (u *CartUsecase) GetCart(userID uint64) (*domain.Cart, error) {
+	cart, err := u.getCart(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	cart.CheckoutReady = len(cart.Items) > 0
+	for _, item := range cart.Items {
+		product, variant, err := u.transactionUsecase.ValidateItem(item.ProductID, item.VariantID, item.Quantity)
+		item.Product = product
+		item.Variant = variant
+		if product != nil {
+			item.UnitPrice = product.HargaKonsumen
+			if variant != nil {
+				item.UnitPrice = variant.PriceFor(product)
+			}
+			item.Subtotal = item.UnitPrice * float64(item.Quantity)
+		}
+
+		if err != nil {
+			item.Issue = err.Error()
+			cart.CheckoutReady = false
+			continue
+		}
+
+		item.Available = true
+		cart.Total += item.Subtotal
+	}
+
+	return cart, nil
+}
+
+// AddItem puts a product, or one of its variants, into the cart. Adding a
+// product or variant that is already in the cart increases its quantity.
+func (u *CartUsecase) AddItem(userID uint64, req *domain.AddCartItemRequest) (*domain.Cart, error) {
+	cart, err := u.getCart(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	quantity := req.Quantity
+	if existing := findCartItem(cart, req.ProductID, req.VariantID); existing != nil {
+		quantity += existing.Quantity
+	}
+
+	if _, _, err := u.transactionUsecase.ValidateItem(req.ProductID, req.VariantID, quantity); err != nil {
+		return nil, err
+	}
+
+	if err := u.cartRepo.SaveItem(cart.ID, req.ProductID, req.VariantID, quantity); err != nil {
+		return nil, errors.New("failed to update cart")
+	}
+
+	return u.GetCart(userID)
+}
+
+// UpdateItem sets the quantity of a product or variant that is already in the
+// cart
+func (u *CartUsecase) UpdateItem(userID, productID, variantID uint64, req *domain.UpdateCartItemRequest) (*domain.Cart, error) {
+	cart, err := u.getCart(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if findCartItem(cart, productID, variantID) == nil {
+		return nil, errors.New("CART_ITEM_NOT_FOUND")
+	}
+
+	if _, _, err := u.transactionUsecase.ValidateItem(productID, variantID, req.Quantity); err != nil {
+		return nil, err
+	}
+
+	if err := u.cartRepo.SaveItem(cart.ID, productID, variantID, req.Quantity); err != nil {
+		return nil, errors.New("failed to update cart")
+	}
+
+	return u.GetCart(userID)
+}
+
+func (u *CartUsecase) RemoveItem(userID, productID, variantID uint64) (*domain.Cart, error) {
+	cart, err := u.getCart(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	removed, err := u.cartRepo.RemoveItem(cart.ID, productID, variantID)
+	if err != nil {
+		return nil, errors.New("failed to update cart")
+	}
+	if !removed {
+		return nil, errors.New("CART_ITEM_NOT_FOUND")
+	}
+
+	return u.GetCart(userID)
+}
+
+func (u *CartUsecase) ClearCart(userID uint64) error {
+	cart, err := u.getCart(userID)
+	if err != nil {
+		return err
+	}
+
+	if err := u.cartRepo.Clear(cart.ID); err != nil {
+		return errors.New("failed to clear cart")
+	}
+	return nil
+}
+
+// Checkout turns the cart into a checkout with one order per store. The cart
+// is emptied in the same database transaction that creates the orders, so
+// either both happen or neither does.
+func (u *CartUsecase) Checkout(userID uint64, req *domain.CheckoutCartRequest) (*domain.Checkout, error) {
+	cart, err := u.getCart(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cart.Items) == 0 {
+		return nil, errors.New("CART_EMPTY")
+	}
+
+	trxReq := &domain.CreateTransactionRequest{
+		AlamatPengiriman: req.AlamatPengiriman,
+		MetodeBayar:      req.MetodeBayar,
+		Shipping:         req.Shipping,
+		Items:            make([]domain.CreateTransactionItemRequest, 0, len(cart.Items)),
+	}
+	for _, item := range cart.Items {
+		trxReq.Items = append(trxReq.Items, domain.CreateTransactionItemRequest{
+			ProductID: item.ProductID,
+			VariantID: item.VariantID,
+			Quantity:  item.Quantity,
+		})
+	}
+
+	return u.transactionUsecase.CreateTransactionWithHook(userID, trxReq, func(dbTx interface{}, _ *domain.Checkout) error {
+		removed, err := u.cartRepo.ClearWithTx(dbTx, cart.ID)
+		if err != nil {
+			return errors.New("failed to clear cart")
+		}
+
+		// The cart was modified by another request while checking out
+		if removed != int64(len(cart.Items)) {
+			return errors.New("CART_CHANGED")
+		}
+		return nil
+	})
+}
+
+func (u *CartUsecase) getCart(userID uint64) (*domain.Cart, error) {
+	cart, err := u.cartRepo.GetOrCreateByUserID(userID)
+	if err != nil {
+		return nil, errors.New("failed to get cart")
+	}
+	return cart, nil
+}
+
+func findCartItem(cart *domain.Cart, productID, variantID uint64) *domain.CartItem {
+	for _, item := range cart.Items {
+		if item.ProductID == productID && item.VariantID == variantID {
+			return item
+		}
+	}
+	return nil
+}
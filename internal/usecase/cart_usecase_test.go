@@ -0,0 +1,332 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"go-commerce/internal/domain"
+	"go-commerce/internal/usecase/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func cartTestProduct(stock int) *domain.Product {
+	return &domain.Product{
+		ID:            1,
+		NamaProduk:    "Test Product",
+		HargaKonsumen: 10000.0,
+		Stok:          stock,
+		IDToko:        1,
+		Status:        "active",
+	}
+}
+
+func TestCartUsecase_AddItem_MergesExistingQuantity(t *testing.T) {
+	// Setup
+	mockCartRepo := new(mocks.MockCartRepository)
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockCheckoutRepo := new(mocks.MockCheckoutRepository)
+	mockTransactionItemRepo := new(mocks.MockTransactionItemRepository)
+	mockProductLogRepo := new(mocks.MockProductLogRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockAddressRepo := new(mocks.MockAddressRepository)
+	mockUserRepo := new(mocks.MockUserRepository)
+	mockStoreRepo := new(mocks.StoreRepositoryMock)
+	mockReservationRepo := new(mocks.MockStockReservationRepository)
+
+	transactionUsecase := NewTransactionUsecase(
+		mockTransactionRepo,
+		mockCheckoutRepo,
+		mockTransactionItemRepo,
+		mockProductLogRepo,
+		mockProductRepo,
+		mockAddressRepo,
+		mockUserRepo,
+		mockStoreRepo,
+		NewStockReservationUsecase(mockReservationRepo, mockProductRepo, mockTransactionRepo, StockReservationConfig{HoldTTL: 30 * time.Minute}),
+		nil,
+		nil,
+		newTestInvoiceNumberer(),
+		nil,
+	)
+	cartUsecase := NewCartUsecase(mockCartRepo, transactionUsecase)
+
+	cart := &domain.Cart{ID: 5, UserID: 1, Items: []*domain.CartItem{{CartID: 5, ProductID: 1, Quantity: 2}}}
+	req := &domain.AddCartItemRequest{ProductID: 1, Quantity: 3}
+
+	// Mock expectations
+	mockCartRepo.On("GetOrCreateByUserID", uint64(1)).Return(cart, nil)
+	mockProductRepo.On("GetByID", uint64(1)).Return(cartTestProduct(10), nil)
+	mockStoreRepo.On("GetByID", uint64(1)).Return(&domain.Store{ID: 1, Status: "active"}, nil)
+	mockCartRepo.On("SaveItem", uint64(5), uint64(1), uint64(0), 5).Return(nil)
+
+	// Execute
+	_, err := cartUsecase.AddItem(1, req)
+
+	// Assert
+	assert.NoError(t, err)
+	mockCartRepo.AssertExpectations(t)
+}
+
+func TestCartUsecase_AddItem_InsufficientStock(t *testing.T) {
+	// Setup
+	mockCartRepo := new(mocks.MockCartRepository)
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockCheckoutRepo := new(mocks.MockCheckoutRepository)
+	mockTransactionItemRepo := new(mocks.MockTransactionItemRepository)
+	mockProductLogRepo := new(mocks.MockProductLogRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockAddressRepo := new(mocks.MockAddressRepository)
+	mockUserRepo := new(mocks.MockUserRepository)
+	mockStoreRepo := new(mocks.StoreRepositoryMock)
+	mockReservationRepo := new(mocks.MockStockReservationRepository)
+
+	transactionUsecase := NewTransactionUsecase(
+		mockTransactionRepo,
+		mockCheckoutRepo,
+		mockTransactionItemRepo,
+		mockProductLogRepo,
+		mockProductRepo,
+		mockAddressRepo,
+		mockUserRepo,
+		mockStoreRepo,
+		NewStockReservationUsecase(mockReservationRepo, mockProductRepo, mockTransactionRepo, StockReservationConfig{HoldTTL: 30 * time.Minute}),
+		nil,
+		nil,
+		newTestInvoiceNumberer(),
+		nil,
+	)
+	cartUsecase := NewCartUsecase(mockCartRepo, transactionUsecase)
+
+	cart := &domain.Cart{ID: 5, UserID: 1, Items: []*domain.CartItem{{CartID: 5, ProductID: 1, Quantity: 2}}}
+	req := &domain.AddCartItemRequest{ProductID: 1, Quantity: 2}
+
+	// Mock expectations
+	mockCartRepo.On("GetOrCreateByUserID", uint64(1)).Return(cart, nil)
+	mockProductRepo.On("GetByID", uint64(1)).Return(cartTestProduct(3), nil)
+	mockStoreRepo.On("GetByID", uint64(1)).Return(&domain.Store{ID: 1, Status: "active"}, nil)
+
+	// Execute
+	result, err := cartUsecase.AddItem(1, req)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Equal(t, "INSUFFICIENT_STOCK", err.Error())
+	assert.Nil(t, result)
+	mockCartRepo.AssertNotCalled(t, "SaveItem", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCartUsecase_GetCart_FlagsUnavailableItems(t *testing.T) {
+	// Setup
+	mockCartRepo := new(mocks.MockCartRepository)
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockCheckoutRepo := new(mocks.MockCheckoutRepository)
+	mockTransactionItemRepo := new(mocks.MockTransactionItemRepository)
+	mockProductLogRepo := new(mocks.MockProductLogRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockAddressRepo := new(mocks.MockAddressRepository)
+	mockUserRepo := new(mocks.MockUserRepository)
+	mockStoreRepo := new(mocks.StoreRepositoryMock)
+	mockReservationRepo := new(mocks.MockStockReservationRepository)
+
+	transactionUsecase := NewTransactionUsecase(
+		mockTransactionRepo,
+		mockCheckoutRepo,
+		mockTransactionItemRepo,
+		mockProductLogRepo,
+		mockProductRepo,
+		mockAddressRepo,
+		mockUserRepo,
+		mockStoreRepo,
+		NewStockReservationUsecase(mockReservationRepo, mockProductRepo, mockTransactionRepo, StockReservationConfig{HoldTTL: 30 * time.Minute}),
+		nil,
+		nil,
+		newTestInvoiceNumberer(),
+		nil,
+	)
+	cartUsecase := NewCartUsecase(mockCartRepo, transactionUsecase)
+
+	cart := &domain.Cart{ID: 5, UserID: 1, Items: []*domain.CartItem{{CartID: 5, ProductID: 1, Quantity: 4}}}
+
+	// Mock expectations
+	mockCartRepo.On("GetOrCreateByUserID", uint64(1)).Return(cart, nil)
+	mockProductRepo.On("GetByID", uint64(1)).Return(cartTestProduct(3), nil)
+	mockStoreRepo.On("GetByID", uint64(1)).Return(&domain.Store{ID: 1, Status: "active"}, nil)
+
+	// Execute
+	result, err := cartUsecase.GetCart(1)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.False(t, result.CheckoutReady)
+	assert.False(t, result.Items[0].Available)
+	assert.Equal(t, "INSUFFICIENT_STOCK", result.Items[0].Issue)
+	assert.Equal(t, 40000.0, result.Items[0].Subtotal)
+	assert.Equal(t, 0.0, result.Total)
+}
+
+func TestCartUsecase_Checkout_ClearsCartInTransaction(t *testing.T) {
+	// Setup
+	mockCartRepo := new(mocks.MockCartRepository)
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockCheckoutRepo := new(mocks.MockCheckoutRepository)
+	mockTransactionItemRepo := new(mocks.MockTransactionItemRepository)
+	mockProductLogRepo := new(mocks.MockProductLogRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockAddressRepo := new(mocks.MockAddressRepository)
+	mockUserRepo := new(mocks.MockUserRepository)
+	mockStoreRepo := new(mocks.StoreRepositoryMock)
+	mockReservationRepo := new(mocks.MockStockReservationRepository)
+
+	transactionUsecase := NewTransactionUsecase(
+		mockTransactionRepo,
+		mockCheckoutRepo,
+		mockTransactionItemRepo,
+		mockProductLogRepo,
+		mockProductRepo,
+		mockAddressRepo,
+		mockUserRepo,
+		mockStoreRepo,
+		NewStockReservationUsecase(mockReservationRepo, mockProductRepo, mockTransactionRepo, StockReservationConfig{HoldTTL: 30 * time.Minute}),
+		nil,
+		nil,
+		newTestInvoiceNumberer(),
+		nil,
+	)
+	cartUsecase := NewCartUsecase(mockCartRepo, transactionUsecase)
+
+	cart := &domain.Cart{ID: 5, UserID: 1, Items: []*domain.CartItem{{CartID: 5, ProductID: 1, Quantity: 2}}}
+	req := &domain.CheckoutCartRequest{AlamatPengiriman: 1, MetodeBayar: "transfer"}
+	mockTx := "mock_transaction"
+
+	// Mock expectations
+	mockCartRepo.On("GetOrCreateByUserID", uint64(1)).Return(cart, nil)
+	mockAddressRepo.On("CheckOwnership", uint64(1), uint64(1)).Return(true)
+	mockUserRepo.On("GetByID", uint64(1)).Return(&domain.User{ID: 1}, nil)
+	mockTransactionRepo.On("BeginTx").Return(mockTx, nil)
+	mockProductRepo.On("GetByID", uint64(1)).Return(cartTestProduct(10), nil)
+	mockStoreRepo.On("GetByID", uint64(1)).Return(&domain.Store{ID: 1, Status: "active"}, nil)
+	mockProductLogRepo.On("Create", mock.AnythingOfType("*domain.ProductLog")).Return(nil)
+	mockCheckoutRepo.On("CreateWithTx", mockTx, mock.AnythingOfType("*domain.Checkout")).Return(nil)
+	mockTransactionRepo.On("CreateWithTx", mockTx, mock.AnythingOfType("*domain.Transaction")).Return(nil)
+	mockTransactionItemRepo.On("CreateWithTx", mockTx, mock.AnythingOfType("*domain.TransactionItem")).Return(nil)
+	mockProductRepo.On("GetStockWithLock", mockTx, uint64(1), uint64(0)).Return(10, nil)
+	mockProductRepo.On("UpdateHeldStockWithTx", mockTx, uint64(1), uint64(0), 2).Return(nil)
+	mockReservationRepo.On("CreateWithTx", mockTx, mock.AnythingOfType("*domain.StockReservation")).Return(nil)
+	mockCartRepo.On("ClearWithTx", mockTx, uint64(5)).Return(int64(1), nil)
+	mockTransactionRepo.On("CommitTx", mockTx).Return(nil)
+
+	// Execute
+	result, err := cartUsecase.Checkout(1, req)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 20000.0, result.HargaTotal)
+	mockCartRepo.AssertExpectations(t)
+	mockTransactionRepo.AssertExpectations(t)
+}
+
+func TestCartUsecase_Checkout_RollsBackWhenCartChanged(t *testing.T) {
+	// Setup
+	mockCartRepo := new(mocks.MockCartRepository)
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockCheckoutRepo := new(mocks.MockCheckoutRepository)
+	mockTransactionItemRepo := new(mocks.MockTransactionItemRepository)
+	mockProductLogRepo := new(mocks.MockProductLogRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockAddressRepo := new(mocks.MockAddressRepository)
+	mockUserRepo := new(mocks.MockUserRepository)
+	mockStoreRepo := new(mocks.StoreRepositoryMock)
+	mockReservationRepo := new(mocks.MockStockReservationRepository)
+
+	transactionUsecase := NewTransactionUsecase(
+		mockTransactionRepo,
+		mockCheckoutRepo,
+		mockTransactionItemRepo,
+		mockProductLogRepo,
+		mockProductRepo,
+		mockAddressRepo,
+		mockUserRepo,
+		mockStoreRepo,
+		NewStockReservationUsecase(mockReservationRepo, mockProductRepo, mockTransactionRepo, StockReservationConfig{HoldTTL: 30 * time.Minute}),
+		nil,
+		nil,
+		newTestInvoiceNumberer(),
+		nil,
+	)
+	cartUsecase := NewCartUsecase(mockCartRepo, transactionUsecase)
+
+	cart := &domain.Cart{ID: 5, UserID: 1, Items: []*domain.CartItem{{CartID: 5, ProductID: 1, Quantity: 2}}}
+	req := &domain.CheckoutCartRequest{AlamatPengiriman: 1, MetodeBayar: "transfer"}
+	mockTx := "mock_transaction"
+
+	// Mock expectations
+	mockCartRepo.On("GetOrCreateByUserID", uint64(1)).Return(cart, nil)
+	mockAddressRepo.On("CheckOwnership", uint64(1), uint64(1)).Return(true)
+	mockUserRepo.On("GetByID", uint64(1)).Return(&domain.User{ID: 1}, nil)
+	mockTransactionRepo.On("BeginTx").Return(mockTx, nil)
+	mockProductRepo.On("GetByID", uint64(1)).Return(cartTestProduct(10), nil)
+	mockStoreRepo.On("GetByID", uint64(1)).Return(&domain.Store{ID: 1, Status: "active"}, nil)
+	mockProductLogRepo.On("Create", mock.AnythingOfType("*domain.ProductLog")).Return(nil)
+	mockCheckoutRepo.On("CreateWithTx", mockTx, mock.AnythingOfType("*domain.Checkout")).Return(nil)
+	mockTransactionRepo.On("CreateWithTx", mockTx, mock.AnythingOfType("*domain.Transaction")).Return(nil)
+	mockTransactionItemRepo.On("CreateWithTx", mockTx, mock.AnythingOfType("*domain.TransactionItem")).Return(nil)
+	mockProductRepo.On("GetStockWithLock", mockTx, uint64(1), uint64(0)).Return(10, nil)
+	mockProductRepo.On("UpdateHeldStockWithTx", mockTx, uint64(1), uint64(0), 2).Return(nil)
+	mockReservationRepo.On("CreateWithTx", mockTx, mock.AnythingOfType("*domain.StockReservation")).Return(nil)
+	mockCartRepo.On("ClearWithTx", mockTx, uint64(5)).Return(int64(2), nil)
+	mockTransactionRepo.On("RollbackTx", mockTx).Return(nil)
+
+	// Execute
+	result, err := cartUsecase.Checkout(1, req)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Equal(t, "CART_CHANGED", err.Error())
+	assert.Nil(t, result)
+	mockTransactionRepo.AssertCalled(t, "RollbackTx", mockTx)
+	mockTransactionRepo.AssertNotCalled(t, "CommitTx", mockTx)
+}
+
+func TestCartUsecase_Checkout_EmptyCart(t *testing.T) {
+	// Setup
+	mockCartRepo := new(mocks.MockCartRepository)
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockCheckoutRepo := new(mocks.MockCheckoutRepository)
+	mockTransactionItemRepo := new(mocks.MockTransactionItemRepository)
+	mockProductLogRepo := new(mocks.MockProductLogRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockAddressRepo := new(mocks.MockAddressRepository)
+	mockUserRepo := new(mocks.MockUserRepository)
+	mockStoreRepo := new(mocks.StoreRepositoryMock)
+	mockReservationRepo := new(mocks.MockStockReservationRepository)
+
+	transactionUsecase := NewTransactionUsecase(
+		mockTransactionRepo,
+		mockCheckoutRepo,
+		mockTransactionItemRepo,
+		mockProductLogRepo,
+		mockProductRepo,
+		mockAddressRepo,
+		mockUserRepo,
+		mockStoreRepo,
+		NewStockReservationUsecase(mockReservationRepo, mockProductRepo, mockTransactionRepo, StockReservationConfig{HoldTTL: 30 * time.Minute}),
+		nil,
+		nil,
+		newTestInvoiceNumberer(),
+		nil,
+	)
+	cartUsecase := NewCartUsecase(mockCartRepo, transactionUsecase)
+
+	// Mock expectations
+	mockCartRepo.On("GetOrCreateByUserID", uint64(1)).Return(&domain.Cart{ID: 5, UserID: 1}, nil)
+
+	// Execute
+	result, err := cartUsecase.Checkout(1, &domain.CheckoutCartRequest{AlamatPengiriman: 1, MetodeBayar: "transfer"})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Equal(t, "CART_EMPTY", err.Error())
+	assert.Nil(t, result)
+}
@@ -13,11 +13,13 @@ import (
 
 type CategoryUsecase struct {
 	categoryRepo domain.CategoryRepository
+	suggestions  *SearchSuggestUsecase
 }
 
-func NewCategoryUsecase(categoryRepo domain.CategoryRepository) *CategoryUsecase {
+func NewCategoryUsecase(categoryRepo domain.CategoryRepository, suggestions *SearchSuggestUsecase) *CategoryUsecase {
 	return &CategoryUsecase{
 		categoryRepo: categoryRepo,
+		suggestions:  suggestions,
 	}
 }
 
@@ -66,6 +68,8 @@ func (u *CategoryUsecase) CreateCategory(req *domain.CreateCategoryRequest) (*do
 		}()
 	}
 
+	u.categoryChanged(category)
+
 	return category, nil
 }
 
@@ -154,6 +158,8 @@ func (u *CategoryUsecase) UpdateCategory(id uint64, req *domain.UpdateCategoryRe
 		}()
 	}
 
+	u.categoryChanged(existingCategory)
+
 	return existingCategory, nil
 }
 
@@ -189,6 +195,10 @@ func (u *CategoryUsecase) DeleteCategory(id uint64) error {
 		return errors.New("failed to delete category")
 	}
 
+	if u.suggestions != nil {
+		u.suggestions.CategoryRemoved(id)
+	}
+
 	return nil
 }
 
@@ -310,6 +320,9 @@ func (u *CategoryUsecase) DeactivateCategory(categoryID uint64) error {
 		return errors.New("failed to deactivate category")
 	}
 
+	category.Status = "inactive"
+	u.categoryChanged(category)
+
 	return nil
 }
 
@@ -341,5 +354,15 @@ func (u *CategoryUsecase) ActivateCategory(categoryID uint64) error {
 		return errors.New("failed to activate category")
 	}
 
+	category.Status = "active"
+	u.categoryChanged(category)
+
 	return nil
+}
+
+// categoryChanged refreshes category in the search suggestions
+func (u *CategoryUsecase) categoryChanged(category *domain.Category) {
+	if u.suggestions != nil {
+		u.suggestions.CategoryChanged(category)
+	}
 }
\ No newline at end of file
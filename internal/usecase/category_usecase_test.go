@@ -14,7 +14,7 @@ import (
 func TestCategoryUsecase_CreateCategory_Success(t *testing.T) {
 	// Setup
 	mockCategoryRepo := new(mocks.MockCategoryRepository)
-	categoryUsecase := NewCategoryUsecase(mockCategoryRepo)
+	categoryUsecase := NewCategoryUsecase(mockCategoryRepo, nil)
 
 	req := &domain.CreateCategoryRequest{
 		Name: "Electronics",
@@ -42,7 +42,7 @@ func TestCategoryUsecase_CreateCategory_Success(t *testing.T) {
 func TestCategoryUsecase_CreateCategory_NameAlreadyExists(t *testing.T) {
 	// Setup
 	mockCategoryRepo := new(mocks.MockCategoryRepository)
-	categoryUsecase := NewCategoryUsecase(mockCategoryRepo)
+	categoryUsecase := NewCategoryUsecase(mockCategoryRepo, nil)
 
 	req := &domain.CreateCategoryRequest{
 		Name: "Electronics",
@@ -71,7 +71,7 @@ func TestCategoryUsecase_CreateCategory_NameAlreadyExists(t *testing.T) {
 func TestCategoryUsecase_GetCategoryByID_Success(t *testing.T) {
 	// Setup
 	mockCategoryRepo := new(mocks.MockCategoryRepository)
-	categoryUsecase := NewCategoryUsecase(mockCategoryRepo)
+	categoryUsecase := NewCategoryUsecase(mockCategoryRepo, nil)
 
 	categoryID := uint64(1)
 	category := &domain.Category{
@@ -98,7 +98,7 @@ func TestCategoryUsecase_GetCategoryByID_Success(t *testing.T) {
 func TestCategoryUsecase_GetCategoryByID_NotFound(t *testing.T) {
 	// Setup
 	mockCategoryRepo := new(mocks.MockCategoryRepository)
-	categoryUsecase := NewCategoryUsecase(mockCategoryRepo)
+	categoryUsecase := NewCategoryUsecase(mockCategoryRepo, nil)
 
 	categoryID := uint64(999)
 
@@ -119,7 +119,7 @@ func TestCategoryUsecase_GetCategoryByID_NotFound(t *testing.T) {
 func TestCategoryUsecase_UpdateCategory_Success(t *testing.T) {
 	// Setup
 	mockCategoryRepo := new(mocks.MockCategoryRepository)
-	categoryUsecase := NewCategoryUsecase(mockCategoryRepo)
+	categoryUsecase := NewCategoryUsecase(mockCategoryRepo, nil)
 
 	categoryID := uint64(1)
 	existingCategory := &domain.Category{
@@ -155,7 +155,7 @@ func TestCategoryUsecase_UpdateCategory_Success(t *testing.T) {
 func TestCategoryUsecase_UpdateCategory_NameAlreadyExists(t *testing.T) {
 	// Setup
 	mockCategoryRepo := new(mocks.MockCategoryRepository)
-	categoryUsecase := NewCategoryUsecase(mockCategoryRepo)
+	categoryUsecase := NewCategoryUsecase(mockCategoryRepo, nil)
 
 	categoryID := uint64(1)
 	existingCategory := &domain.Category{
@@ -192,7 +192,7 @@ func TestCategoryUsecase_UpdateCategory_NameAlreadyExists(t *testing.T) {
 func TestCategoryUsecase_DeleteCategory_Success(t *testing.T) {
 	// Setup
 	mockCategoryRepo := new(mocks.MockCategoryRepository)
-	categoryUsecase := NewCategoryUsecase(mockCategoryRepo)
+	categoryUsecase := NewCategoryUsecase(mockCategoryRepo, nil)
 
 	categoryID := uint64(1)
 	category := &domain.Category{
@@ -218,7 +218,7 @@ func TestCategoryUsecase_DeleteCategory_Success(t *testing.T) {
 func TestCategoryUsecase_GetAllCategories_Success(t *testing.T) {
 	// Setup
 	mockCategoryRepo := new(mocks.MockCategoryRepository)
-	categoryUsecase := NewCategoryUsecase(mockCategoryRepo)
+	categoryUsecase := NewCategoryUsecase(mockCategoryRepo, nil)
 
 	page := 1
 	limit := 10
@@ -251,7 +251,7 @@ func TestCategoryUsecase_GetAllCategories_Success(t *testing.T) {
 func TestCategoryUsecase_GetCategoryBySlug_Success(t *testing.T) {
 	// Setup
 	mockCategoryRepo := new(mocks.MockCategoryRepository)
-	categoryUsecase := NewCategoryUsecase(mockCategoryRepo)
+	categoryUsecase := NewCategoryUsecase(mockCategoryRepo, nil)
 
 	slug := "electronics"
 	category := &domain.Category{
@@ -277,7 +277,7 @@ func TestCategoryUsecase_GetCategoryBySlug_Success(t *testing.T) {
 func TestCategoryUsecase_GetRootCategories_Success(t *testing.T) {
 	// Setup
 	mockCategoryRepo := new(mocks.MockCategoryRepository)
-	categoryUsecase := NewCategoryUsecase(mockCategoryRepo)
+	categoryUsecase := NewCategoryUsecase(mockCategoryRepo, nil)
 
 	page := 1
 	limit := 10
@@ -308,7 +308,7 @@ func TestCategoryUsecase_GetRootCategories_Success(t *testing.T) {
 func TestCategoryUsecase_GetChildrenByParentID_Success(t *testing.T) {
 	// Setup
 	mockCategoryRepo := new(mocks.MockCategoryRepository)
-	categoryUsecase := NewCategoryUsecase(mockCategoryRepo)
+	categoryUsecase := NewCategoryUsecase(mockCategoryRepo, nil)
 
 	parentID := uint64(1)
 	parentCategory := &domain.Category{
@@ -340,7 +340,7 @@ func TestCategoryUsecase_GetChildrenByParentID_Success(t *testing.T) {
 func TestCategoryUsecase_DeactivateCategory_Success(t *testing.T) {
 	// Setup
 	mockCategoryRepo := new(mocks.MockCategoryRepository)
-	categoryUsecase := NewCategoryUsecase(mockCategoryRepo)
+	categoryUsecase := NewCategoryUsecase(mockCategoryRepo, nil)
 
 	categoryID := uint64(1)
 	category := &domain.Category{
@@ -366,7 +366,7 @@ func TestCategoryUsecase_DeactivateCategory_Success(t *testing.T) {
 func TestCategoryUsecase_DeactivateCategory_HasActiveChildren(t *testing.T) {
 	// Setup
 	mockCategoryRepo := new(mocks.MockCategoryRepository)
-	categoryUsecase := NewCategoryUsecase(mockCategoryRepo)
+	categoryUsecase := NewCategoryUsecase(mockCategoryRepo, nil)
 
 	categoryID := uint64(1)
 	category := &domain.Category{
@@ -391,7 +391,7 @@ func TestCategoryUsecase_DeactivateCategory_HasActiveChildren(t *testing.T) {
 func TestCategoryUsecase_DeactivateCategory_HasActiveProducts(t *testing.T) {
 	// Setup
 	mockCategoryRepo := new(mocks.MockCategoryRepository)
-	categoryUsecase := NewCategoryUsecase(mockCategoryRepo)
+	categoryUsecase := NewCategoryUsecase(mockCategoryRepo, nil)
 
 	categoryID := uint64(1)
 	category := &domain.Category{
@@ -417,7 +417,7 @@ func TestCategoryUsecase_DeactivateCategory_HasActiveProducts(t *testing.T) {
 func TestCategoryUsecase_ActivateCategory_Success(t *testing.T) {
 	// Setup
 	mockCategoryRepo := new(mocks.MockCategoryRepository)
-	categoryUsecase := NewCategoryUsecase(mockCategoryRepo)
+	categoryUsecase := NewCategoryUsecase(mockCategoryRepo, nil)
 
 	categoryID := uint64(1)
 	parentID := uint64(2)
@@ -444,7 +444,7 @@ func TestCategoryUsecase_ActivateCategory_Success(t *testing.T) {
 func TestCategoryUsecase_ActivateCategory_ParentInactive(t *testing.T) {
 	// Setup
 	mockCategoryRepo := new(mocks.MockCategoryRepository)
-	categoryUsecase := NewCategoryUsecase(mockCategoryRepo)
+	categoryUsecase := NewCategoryUsecase(mockCategoryRepo, nil)
 
 	categoryID := uint64(1)
 	parentID := uint64(2)
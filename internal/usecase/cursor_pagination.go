@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"errors"
+	"slices"
+
+	"go-commerce/internal/domain"
+)
+
+// cursorRow is an entity that can be paged by cursor
+type cursorRow interface {
+	Cursor(sortBy string) (*domain.Cursor, bool)
+}
+
+// normalizeCursorPage applies the list defaults to page and checks that
+// entities E can be paged in its sort order
+func normalizeCursorPage[E any, T interface {
+	*E
+	cursorRow
+}](page *domain.CursorPage, defaultSort string) error {
+	if page.Limit < 1 || page.Limit > 100 {
+		page.Limit = 10
+	}
+	if page.SortBy == "" {
+		page.SortBy = defaultSort
+	}
+
+	if _, ok := T(new(E)).Cursor(page.SortBy); !ok {
+		return errors.New("INVALID_SORT_BY")
+	}
+	return nil
+}
+
+// cursorResult turns the rows a repository read for page, nearest to the
+// cursor first and one more than the limit if there are, into the page in
+// list order and the cursors of the pages around it
+func cursorResult[T cursorRow](rows []T, page *domain.CursorPage) ([]T, *domain.CursorLinks) {
+	more := len(rows) > page.Limit
+	if more {
+		rows = rows[:page.Limit]
+	}
+
+	backward := page.Cursor != nil && page.Cursor.Backward
+	if backward {
+		slices.Reverse(rows)
+	}
+
+	links := &domain.CursorLinks{}
+	if len(rows) == 0 {
+		return rows, links
+	}
+
+	// A backward page was reached from the page after it, and a forward page
+	// with a cursor from the page before it
+	if more || backward {
+		links.Next, _ = rows[len(rows)-1].Cursor(page.SortBy)
+	}
+	if (backward && more) || (!backward && page.Cursor != nil) {
+		links.Prev, _ = rows[0].Cursor(page.SortBy)
+		links.Prev.Backward = true
+	}
+
+	return rows, links
+}
@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"go-commerce/internal/domain"
+	"go-commerce/pkg/jwt"
+)
+
+// EmailVerificationConfig controls verification links and which actions
+// require a verified email address
+type EmailVerificationConfig struct {
+	VerificationURL     string
+	TokenTTL            time.Duration
+	ResendCooldown      time.Duration
+	RequireForCheckout  bool
+	RequireForStoreOpen bool
+}
+
+type EmailVerificationUsecase struct {
+	userRepo   domain.UserRepository
+	mailer     domain.Mailer
+	jwtManager *jwt.JWTManager
+	config     EmailVerificationConfig
+}
+
+func NewEmailVerificationUsecase(userRepo domain.UserRepository, mailer domain.Mailer, jwtManager *jwt.JWTManager, config EmailVerificationConfig) *EmailVerificationUsecase {
+	return &EmailVerificationUsecase{
+		userRepo:   userRepo,
+		mailer:     mailer,
+		jwtManager: jwtManager,
+		config:     config,
+	}
+}
+
+// SendVerificationEmail emails a fresh verification link to the user
+func (u *EmailVerificationUsecase) SendVerificationEmail(user *domain.User) error {
+	token, err := u.jwtManager.GenerateEmailVerificationToken(user.ID, user.Email, u.config.TokenTTL)
+	if err != nil {
+		return errors.New("failed to generate verification token")
+	}
+
+	if _, err := u.userRepo.ReserveVerificationEmail(user.ID, time.Now(), time.Now()); err != nil {
+		log.Printf("Error recording verification email for user %d: %v", user.ID, err)
+	}
+
+	return u.sendVerificationLink(user, token)
+}
+
+// ResendVerificationEmail sends a new verification link unless the email is
+// already verified or the previous link was sent within the cooldown window
+func (u *EmailVerificationUsecase) ResendVerificationEmail(userID uint64) error {
+	user, err := u.userRepo.GetByID(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return errors.New("EMAIL_ALREADY_VERIFIED")
+	}
+
+	now := time.Now()
+	reserved, err := u.userRepo.ReserveVerificationEmail(user.ID, now, now.Add(-u.config.ResendCooldown))
+	if err != nil {
+		return errors.New("failed to send verification email")
+	}
+	if !reserved {
+		return errors.New("VERIFICATION_EMAIL_RATE_LIMITED")
+	}
+
+	token, err := u.jwtManager.GenerateEmailVerificationToken(user.ID, user.Email, u.config.TokenTTL)
+	if err != nil {
+		return errors.New("failed to generate verification token")
+	}
+
+	return u.sendVerificationLink(user, token)
+}
+
+// VerifyEmail marks the user's email as verified when the token is valid and
+// still matches the user's current email address
+func (u *EmailVerificationUsecase) VerifyEmail(req *domain.VerifyEmailRequest) error {
+	claims, err := u.jwtManager.ValidateEmailVerificationToken(req.Token)
+	if err != nil {
+		return errors.New("invalid or expired verification token")
+	}
+
+	user, err := u.userRepo.GetByID(claims.UserID)
+	if err != nil || user.Email != claims.Email {
+		return errors.New("invalid or expired verification token")
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return errors.New("EMAIL_ALREADY_VERIFIED")
+	}
+
+	if err := u.userRepo.MarkEmailVerified(user.ID, time.Now()); err != nil {
+		return errors.New("failed to verify email")
+	}
+
+	return nil
+}
+
+// RequiresVerifiedStoreOwner reports whether store opening is limited to
+// users with a verified email
+func (u *EmailVerificationUsecase) RequiresVerifiedStoreOwner() bool {
+	return u.config.RequireForStoreOpen
+}
+
+// EnsureCanCheckout returns EMAIL_NOT_VERIFIED when checkout requires a
+// verified email and the user has none
+func (u *EmailVerificationUsecase) EnsureCanCheckout(user *domain.User) error {
+	if u.config.RequireForCheckout && user.EmailVerifiedAt == nil {
+		return errors.New("EMAIL_NOT_VERIFIED")
+	}
+	return nil
+}
+
+// EnsureCanOpenStore returns EMAIL_NOT_VERIFIED when opening a store requires
+// a verified email and the user has none
+func (u *EmailVerificationUsecase) EnsureCanOpenStore(userID uint64) error {
+	if !u.config.RequireForStoreOpen {
+		return nil
+	}
+
+	user, err := u.userRepo.GetByID(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if user.EmailVerifiedAt == nil {
+		return errors.New("EMAIL_NOT_VERIFIED")
+	}
+	return nil
+}
+
+func (u *EmailVerificationUsecase) sendVerificationLink(user *domain.User, token string) error {
+	link := u.config.VerificationURL + "?token=" + url.QueryEscape(token)
+	msg := &domain.EmailMessage{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease verify your email address by opening the link below:\n\n%s\n\nVerification token: %s\n\nThe link expires in %d hour(s). If you did not create an account, you can ignore this email.\n",
+			user.Name, link, token, int(u.config.TokenTTL.Hours())),
+	}
+
+	if err := u.mailer.Send(msg); err != nil {
+		log.Printf("Error sending verification email to user %d: %v", user.ID, err)
+		return errors.New("failed to send verification email")
+	}
+	return nil
+}
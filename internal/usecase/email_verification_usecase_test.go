@@ -0,0 +1,158 @@
+package usecase
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"go-commerce/internal/domain"
+	"go-commerce/internal/usecase/mocks"
+	"go-commerce/pkg/jwt"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestEmailVerificationUsecase(userRepo domain.UserRepository, mailer domain.Mailer, config EmailVerificationConfig) (*EmailVerificationUsecase, *jwt.JWTManager) {
+	jwtManager := jwt.NewJWTManager("test-secret", 24, 168)
+	config.VerificationURL = "http://localhost/verify-email"
+	config.TokenTTL = time.Hour
+	config.ResendCooldown = time.Minute
+	return NewEmailVerificationUsecase(userRepo, mailer, jwtManager, config), jwtManager
+}
+
+func TestEmailVerificationUsecase_ResendVerificationEmail_SendsToken(t *testing.T) {
+	// Setup
+	mockUserRepo := new(mocks.MockUserRepository)
+	mockMailer := new(mocks.MockMailer)
+	emailVerificationUsecase, jwtManager := newTestEmailVerificationUsecase(mockUserRepo, mockMailer, EmailVerificationConfig{})
+
+	user := &domain.User{ID: 1, Name: "Test User", Email: "test@example.com"}
+
+	var sent *domain.EmailMessage
+
+	// Mock expectations
+	mockUserRepo.On("GetByID", uint64(1)).Return(user, nil)
+	mockUserRepo.On("ReserveVerificationEmail", uint64(1), mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return(true, nil)
+	mockMailer.On("Send", mock.AnythingOfType("*domain.EmailMessage")).Run(func(args mock.Arguments) {
+		sent = args.Get(0).(*domain.EmailMessage)
+	}).Return(nil)
+
+	// Execute
+	err := emailVerificationUsecase.ResendVerificationEmail(1)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotNil(t, sent)
+	assert.Equal(t, "test@example.com", sent.To)
+
+	// The emailed link carries a token that verifies this user
+	link := sent.Body[strings.Index(sent.Body, "http://localhost/verify-email?token="):]
+	link = strings.Fields(link)[0]
+	parsed, err := url.Parse(link)
+	assert.NoError(t, err)
+	claims, err := jwtManager.ValidateEmailVerificationToken(parsed.Query().Get("token"))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), claims.UserID)
+
+	mockUserRepo.AssertExpectations(t)
+	mockMailer.AssertExpectations(t)
+}
+
+func TestEmailVerificationUsecase_ResendVerificationEmail_RateLimited(t *testing.T) {
+	// Setup
+	mockUserRepo := new(mocks.MockUserRepository)
+	mockMailer := new(mocks.MockMailer)
+	emailVerificationUsecase, _ := newTestEmailVerificationUsecase(mockUserRepo, mockMailer, EmailVerificationConfig{})
+
+	user := &domain.User{ID: 1, Email: "test@example.com"}
+
+	// Mock expectations
+	mockUserRepo.On("GetByID", uint64(1)).Return(user, nil)
+	mockUserRepo.On("ReserveVerificationEmail", uint64(1), mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return(false, nil)
+
+	// Execute
+	err := emailVerificationUsecase.ResendVerificationEmail(1)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Equal(t, "VERIFICATION_EMAIL_RATE_LIMITED", err.Error())
+	mockMailer.AssertNotCalled(t, "Send", mock.Anything)
+}
+
+func TestEmailVerificationUsecase_VerifyEmail_Success(t *testing.T) {
+	// Setup
+	mockUserRepo := new(mocks.MockUserRepository)
+	emailVerificationUsecase, jwtManager := newTestEmailVerificationUsecase(mockUserRepo, nil, EmailVerificationConfig{})
+
+	user := &domain.User{ID: 1, Email: "test@example.com"}
+	token, err := jwtManager.GenerateEmailVerificationToken(1, "test@example.com", time.Hour)
+	assert.NoError(t, err)
+
+	// Mock expectations
+	mockUserRepo.On("GetByID", uint64(1)).Return(user, nil)
+	mockUserRepo.On("MarkEmailVerified", uint64(1), mock.AnythingOfType("time.Time")).Return(nil)
+
+	// Execute
+	err = emailVerificationUsecase.VerifyEmail(&domain.VerifyEmailRequest{Token: token})
+
+	// Assert
+	assert.NoError(t, err)
+	mockUserRepo.AssertExpectations(t)
+}
+
+func TestEmailVerificationUsecase_VerifyEmail_EmailChanged(t *testing.T) {
+	// Setup
+	mockUserRepo := new(mocks.MockUserRepository)
+	emailVerificationUsecase, jwtManager := newTestEmailVerificationUsecase(mockUserRepo, nil, EmailVerificationConfig{})
+
+	user := &domain.User{ID: 1, Email: "new@example.com"}
+	token, err := jwtManager.GenerateEmailVerificationToken(1, "old@example.com", time.Hour)
+	assert.NoError(t, err)
+
+	// Mock expectations
+	mockUserRepo.On("GetByID", uint64(1)).Return(user, nil)
+
+	// Execute
+	err = emailVerificationUsecase.VerifyEmail(&domain.VerifyEmailRequest{Token: token})
+
+	// Assert
+	assert.Error(t, err)
+	mockUserRepo.AssertNotCalled(t, "MarkEmailVerified", mock.Anything, mock.Anything)
+}
+
+func TestEmailVerificationUsecase_VerifyEmail_InvalidToken(t *testing.T) {
+	// Setup
+	mockUserRepo := new(mocks.MockUserRepository)
+	emailVerificationUsecase, _ := newTestEmailVerificationUsecase(mockUserRepo, nil, EmailVerificationConfig{})
+
+	// Execute
+	err := emailVerificationUsecase.VerifyEmail(&domain.VerifyEmailRequest{Token: "invalid"})
+
+	// Assert
+	assert.Error(t, err)
+	mockUserRepo.AssertNotCalled(t, "GetByID", mock.Anything)
+}
+
+func TestStoreUsecase_CreateStore_RequiresVerifiedEmail(t *testing.T) {
+	// Setup
+	mockStoreRepo := new(mocks.MockStoreRepository)
+	mockUserRepo := new(mocks.MockUserRepository)
+	emailVerificationUsecase, _ := newTestEmailVerificationUsecase(mockUserRepo, nil, EmailVerificationConfig{RequireForStoreOpen: true})
+	storeUsecase := NewStoreUsecase(mockStoreRepo, emailVerificationUsecase, nil)
+
+	// Mock expectations
+	mockStoreRepo.On("GetByUserID", uint64(1)).Return(nil, errors.New("record not found"))
+	mockUserRepo.On("GetByID", uint64(1)).Return(&domain.User{ID: 1}, nil)
+
+	// Execute
+	store, err := storeUsecase.CreateStore(1, &domain.CreateStoreRequest{Name: "Test Store"})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, store)
+	assert.Equal(t, "EMAIL_NOT_VERIFIED", err.Error())
+	mockStoreRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
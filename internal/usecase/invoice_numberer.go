@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"go-commerce/internal/domain"
+)
+
+// Default code formats. {YYYYMMDD} is the day of the checkout, {store} the
+// store ID and {seq} the number of the code within the day.
+const (
+	DefaultInvoiceFormat  = "INV/{YYYYMMDD}/{store}/{seq}"
+	DefaultCheckoutFormat = "CHK/{YYYYMMDD}/{seq}"
+)
+
+// InvoiceNumberConfig holds the formats of invoice and checkout codes. Empty
+// formats use the defaults.
+type InvoiceNumberConfig struct {
+	InvoiceFormat  string
+	CheckoutFormat string
+}
+
+// InvoiceNumberer builds invoice and checkout codes from per-day sequences,
+// so codes never collide, even when one buyer checks out twice in a second
+type InvoiceNumberer struct {
+	sequenceRepo domain.InvoiceSequenceRepository
+	config       InvoiceNumberConfig
+}
+
+func NewInvoiceNumberer(sequenceRepo domain.InvoiceSequenceRepository, config InvoiceNumberConfig) *InvoiceNumberer {
+	if config.InvoiceFormat == "" {
+		config.InvoiceFormat = DefaultInvoiceFormat
+	}
+	if config.CheckoutFormat == "" {
+		config.CheckoutFormat = DefaultCheckoutFormat
+	}
+
+	return &InvoiceNumberer{
+		sequenceRepo: sequenceRepo,
+		config:       config,
+	}
+}
+
+// InvoiceCode returns the next invoice code of the store for the day of at
+func (n *InvoiceNumberer) InvoiceCode(storeID uint64, at time.Time) (string, error) {
+	return n.next(domain.SequenceInvoice, n.config.InvoiceFormat, storeID, at)
+}
+
+// CheckoutCode returns the next checkout code for the day of at
+func (n *InvoiceNumberer) CheckoutCode(at time.Time) (string, error) {
+	return n.next(domain.SequenceCheckout, n.config.CheckoutFormat, 0, at)
+}
+
+func (n *InvoiceNumberer) next(name, format string, storeID uint64, at time.Time) (string, error) {
+	// A format without the store shares one sequence between all stores
+	scope := storeID
+	if !strings.Contains(format, "{store}") {
+		scope = 0
+	}
+
+	year, month, day := at.Date()
+	seq, err := n.sequenceRepo.Next(name, time.Date(year, month, day, 0, 0, 0, 0, at.Location()), scope)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.NewReplacer(
+		"{YYYYMMDD}", at.Format("20060102"),
+		"{store}", strconv.FormatUint(storeID, 10),
+		"{seq}", strconv.FormatInt(seq, 10),
+	).Replace(format), nil
+}
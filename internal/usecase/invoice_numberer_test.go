@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"go-commerce/internal/domain"
+	"go-commerce/internal/usecase/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// newTestInvoiceNumberer numbers every code 1, for tests that only need codes
+func newTestInvoiceNumberer() *InvoiceNumberer {
+	sequenceRepo := new(mocks.MockInvoiceSequenceRepository)
+	sequenceRepo.On("Next", mock.Anything, mock.Anything, mock.Anything).Return(int64(1), nil)
+	return NewInvoiceNumberer(sequenceRepo, InvoiceNumberConfig{})
+}
+
+func TestInvoiceNumberer_InvoiceCode_PerStoreDailySequence(t *testing.T) {
+	// Setup
+	sequenceRepo := new(mocks.MockInvoiceSequenceRepository)
+	numberer := NewInvoiceNumberer(sequenceRepo, InvoiceNumberConfig{})
+	at := time.Date(2026, 10, 17, 23, 59, 30, 0, time.Local)
+	day := time.Date(2026, 10, 17, 0, 0, 0, 0, time.Local)
+
+	// Mock expectations
+	sequenceRepo.On("Next", domain.SequenceInvoice, day, uint64(12)).Return(int64(3), nil)
+	sequenceRepo.On("Next", domain.SequenceCheckout, day, uint64(0)).Return(int64(41), nil)
+
+	// Execute
+	invoice, errInvoice := numberer.InvoiceCode(12, at)
+	checkout, errCheckout := numberer.CheckoutCode(at)
+
+	// Assert
+	assert.NoError(t, errInvoice)
+	assert.NoError(t, errCheckout)
+	assert.Equal(t, "INV/20261017/12/3", invoice)
+	assert.Equal(t, "CHK/20261017/41", checkout)
+	sequenceRepo.AssertExpectations(t)
+}
+
+func TestInvoiceNumberer_InvoiceCode_FormatWithoutStoreSharesSequence(t *testing.T) {
+	// Setup
+	sequenceRepo := new(mocks.MockInvoiceSequenceRepository)
+	numberer := NewInvoiceNumberer(sequenceRepo, InvoiceNumberConfig{InvoiceFormat: "{YYYYMMDD}-{seq}"})
+	at := time.Date(2026, 10, 17, 8, 0, 0, 0, time.Local)
+
+	// Mock expectations - codes without the store must not repeat across stores
+	sequenceRepo.On("Next", domain.SequenceInvoice, mock.AnythingOfType("time.Time"), uint64(0)).Return(int64(7), nil)
+
+	// Execute
+	invoice, err := numberer.InvoiceCode(12, at)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "20261017-7", invoice)
+}
@@ -0,0 +1,167 @@
+package usecase
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"go-commerce/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// LoginThrottleConfig controls when failed logins start locking an account or
+// client IP and how long the lockout lasts
+type LoginThrottleConfig struct {
+	MaxAccountAttempts int
+	MaxIPAttempts      int
+	AttemptWindow      time.Duration
+	BaseLockout        time.Duration
+	MaxLockout         time.Duration
+}
+
+type LoginThrottleUsecase struct {
+	throttleRepo domain.LoginThrottleRepository
+	userRepo     domain.UserRepository
+	config       LoginThrottleConfig
+}
+
+func NewLoginThrottleUsecase(throttleRepo domain.LoginThrottleRepository, userRepo domain.UserRepository, config LoginThrottleConfig) *LoginThrottleUsecase {
+	return &LoginThrottleUsecase{
+		throttleRepo: throttleRepo,
+		userRepo:     userRepo,
+		config:       config,
+	}
+}
+
+// CheckIP refuses logins from a client IP that is currently locked out
+func (u *LoginThrottleUsecase) CheckIP(ip string) error {
+	if ip == "" {
+		return nil
+	}
+
+	throttle, err := u.throttleRepo.Get(domain.LoginThrottleScopeIP, ip)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("Error reading login throttle for %s: %v", ip, err)
+		}
+		return nil
+	}
+
+	if throttle.LockedUntil != nil && time.Now().Before(*throttle.LockedUntil) {
+		return &domain.ThrottleError{Code: "TOO_MANY_LOGIN_ATTEMPTS", RetryAfter: time.Until(*throttle.LockedUntil)}
+	}
+	return nil
+}
+
+// CheckAccount refuses logins to an account whose lockout has not expired yet
+func (u *LoginThrottleUsecase) CheckAccount(user *domain.User) error {
+	if user.Status != "locked" || user.LockedUntil == nil {
+		return nil
+	}
+
+	if time.Now().Before(*user.LockedUntil) {
+		return &domain.ThrottleError{Code: "ACCOUNT_LOCKED", RetryAfter: time.Until(*user.LockedUntil)}
+	}
+	return nil
+}
+
+// RecordFailure counts a failed login for the client IP and, when the email
+// matched an account, for that account. It returns a ThrottleError when this
+// failure triggered a lockout.
+func (u *LoginThrottleUsecase) RecordFailure(ip string, user *domain.User) error {
+	now := time.Now()
+	var lockErr error
+
+	if ip != "" {
+		if until, locked := u.registerFailure(domain.LoginThrottleScopeIP, ip, u.config.MaxIPAttempts, now); locked {
+			lockErr = &domain.ThrottleError{Code: "TOO_MANY_LOGIN_ATTEMPTS", RetryAfter: until.Sub(now)}
+		}
+	}
+
+	if user != nil {
+		subject := strconv.FormatUint(user.ID, 10)
+		if until, locked := u.registerFailure(domain.LoginThrottleScopeAccount, subject, u.config.MaxAccountAttempts, now); locked {
+			if err := u.userRepo.LockAccount(user.ID, until); err != nil {
+				log.Printf("Error locking user %d: %v", user.ID, err)
+			}
+			lockErr = &domain.ThrottleError{Code: "ACCOUNT_LOCKED", RetryAfter: until.Sub(now)}
+		}
+	}
+
+	return lockErr
+}
+
+// RecordSuccess clears the account's failure counter after a successful login.
+// The IP counter is kept so one valid account cannot reset it.
+func (u *LoginThrottleUsecase) RecordSuccess(user *domain.User) {
+	if err := u.throttleRepo.Reset(domain.LoginThrottleScopeAccount, strconv.FormatUint(user.ID, 10)); err != nil {
+		log.Printf("Error resetting login throttle for user %d: %v", user.ID, err)
+	}
+
+	if user.Status == "locked" {
+		if err := u.userRepo.UnlockAccount(user.ID); err != nil {
+			log.Printf("Error unlocking user %d: %v", user.ID, err)
+		}
+		user.Status = "active"
+		user.LockedUntil = nil
+	}
+}
+
+// UnlockAccount lifts a lockout before it expires (admin only)
+func (u *LoginThrottleUsecase) UnlockAccount(userID uint64) error {
+	user, err := u.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("user not found")
+		}
+		return errors.New("failed to get user")
+	}
+
+	if user.Status != "locked" {
+		return errors.New("ACCOUNT_NOT_LOCKED")
+	}
+
+	if err := u.userRepo.UnlockAccount(user.ID); err != nil {
+		return errors.New("failed to unlock account")
+	}
+
+	if err := u.throttleRepo.Reset(domain.LoginThrottleScopeAccount, strconv.FormatUint(user.ID, 10)); err != nil {
+		return errors.New("failed to unlock account")
+	}
+
+	return nil
+}
+
+// registerFailure counts a failure and locks the subject once maxAttempts is
+// reached. Every further failure doubles the lockout up to MaxLockout.
+func (u *LoginThrottleUsecase) registerFailure(scope, subject string, maxAttempts int, now time.Time) (time.Time, bool) {
+	throttle, err := u.throttleRepo.RegisterFailure(scope, subject, now, u.config.AttemptWindow)
+	if err != nil {
+		log.Printf("Error recording failed login for %s %s: %v", scope, subject, err)
+		return time.Time{}, false
+	}
+
+	if maxAttempts <= 0 || throttle.FailedCount < maxAttempts {
+		return time.Time{}, false
+	}
+
+	until := now.Add(u.lockoutDuration(throttle.FailedCount - maxAttempts))
+	if err := u.throttleRepo.Lock(scope, subject, until); err != nil {
+		log.Printf("Error locking %s %s: %v", scope, subject, err)
+		return time.Time{}, false
+	}
+	return until, true
+}
+
+func (u *LoginThrottleUsecase) lockoutDuration(excess int) time.Duration {
+	duration := u.config.BaseLockout
+	for i := 0; i < excess && duration < u.config.MaxLockout; i++ {
+		duration *= 2
+	}
+	if u.config.MaxLockout > 0 && duration > u.config.MaxLockout {
+		duration = u.config.MaxLockout
+	}
+	return duration
+}
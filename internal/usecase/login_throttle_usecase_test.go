@@ -0,0 +1,152 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go-commerce/internal/domain"
+	"go-commerce/internal/usecase/mocks"
+	"go-commerce/pkg/jwt"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+func testLoginThrottleConfig() LoginThrottleConfig {
+	return LoginThrottleConfig{
+		MaxAccountAttempts: 3,
+		MaxIPAttempts:      10,
+		AttemptWindow:      15 * time.Minute,
+		BaseLockout:        time.Minute,
+		MaxLockout:         time.Hour,
+	}
+}
+
+func TestLoginThrottleUsecase_LockoutDuration_DoublesUpToMax(t *testing.T) {
+	// Setup
+	loginThrottleUsecase := NewLoginThrottleUsecase(nil, nil, testLoginThrottleConfig())
+
+	// Assert
+	assert.Equal(t, time.Minute, loginThrottleUsecase.lockoutDuration(0))
+	assert.Equal(t, 2*time.Minute, loginThrottleUsecase.lockoutDuration(1))
+	assert.Equal(t, 8*time.Minute, loginThrottleUsecase.lockoutDuration(3))
+	assert.Equal(t, time.Hour, loginThrottleUsecase.lockoutDuration(20))
+}
+
+func TestLoginThrottleUsecase_RecordFailure_LocksAccountAtThreshold(t *testing.T) {
+	// Setup
+	mockThrottleRepo := new(mocks.MockLoginThrottleRepository)
+	mockUserRepo := new(mocks.MockUserRepository)
+	loginThrottleUsecase := NewLoginThrottleUsecase(mockThrottleRepo, mockUserRepo, testLoginThrottleConfig())
+
+	user := &domain.User{ID: 1, Status: "active"}
+
+	// Mock expectations
+	mockThrottleRepo.On("RegisterFailure", domain.LoginThrottleScopeIP, "10.0.0.1", mock.AnythingOfType("time.Time"), 15*time.Minute).
+		Return(&domain.LoginThrottle{FailedCount: 3}, nil)
+	mockThrottleRepo.On("RegisterFailure", domain.LoginThrottleScopeAccount, "1", mock.AnythingOfType("time.Time"), 15*time.Minute).
+		Return(&domain.LoginThrottle{FailedCount: 3}, nil)
+	mockThrottleRepo.On("Lock", domain.LoginThrottleScopeAccount, "1", mock.AnythingOfType("time.Time")).Return(nil)
+	mockUserRepo.On("LockAccount", uint64(1), mock.AnythingOfType("time.Time")).Return(nil)
+
+	// Execute
+	err := loginThrottleUsecase.RecordFailure("10.0.0.1", user)
+
+	// Assert
+	var throttleErr *domain.ThrottleError
+	assert.True(t, errors.As(err, &throttleErr))
+	assert.Equal(t, "ACCOUNT_LOCKED", throttleErr.Code)
+	assert.Equal(t, "60", throttleErr.RetryAfterSeconds())
+	mockThrottleRepo.AssertExpectations(t)
+	mockUserRepo.AssertExpectations(t)
+}
+
+func TestLoginThrottleUsecase_CheckIP_Locked(t *testing.T) {
+	// Setup
+	mockThrottleRepo := new(mocks.MockLoginThrottleRepository)
+	loginThrottleUsecase := NewLoginThrottleUsecase(mockThrottleRepo, nil, testLoginThrottleConfig())
+
+	lockedUntil := time.Now().Add(2 * time.Minute)
+
+	// Mock expectations
+	mockThrottleRepo.On("Get", domain.LoginThrottleScopeIP, "10.0.0.1").
+		Return(&domain.LoginThrottle{FailedCount: 10, LockedUntil: &lockedUntil}, nil)
+
+	// Execute
+	err := loginThrottleUsecase.CheckIP("10.0.0.1")
+
+	// Assert
+	assert.Error(t, err)
+	assert.Equal(t, "TOO_MANY_LOGIN_ATTEMPTS", err.Error())
+}
+
+func TestLoginThrottleUsecase_UnlockAccount_Success(t *testing.T) {
+	// Setup
+	mockThrottleRepo := new(mocks.MockLoginThrottleRepository)
+	mockUserRepo := new(mocks.MockUserRepository)
+	loginThrottleUsecase := NewLoginThrottleUsecase(mockThrottleRepo, mockUserRepo, testLoginThrottleConfig())
+
+	lockedUntil := time.Now().Add(time.Hour)
+	user := &domain.User{ID: 1, Status: "locked", LockedUntil: &lockedUntil}
+
+	// Mock expectations
+	mockUserRepo.On("GetByID", uint64(1)).Return(user, nil)
+	mockUserRepo.On("UnlockAccount", uint64(1)).Return(nil)
+	mockThrottleRepo.On("Reset", domain.LoginThrottleScopeAccount, "1").Return(nil)
+
+	// Execute
+	err := loginThrottleUsecase.UnlockAccount(1)
+
+	// Assert
+	assert.NoError(t, err)
+	mockUserRepo.AssertExpectations(t)
+	mockThrottleRepo.AssertExpectations(t)
+}
+
+func TestLoginThrottleUsecase_UnlockAccount_NotLocked(t *testing.T) {
+	// Setup
+	mockUserRepo := new(mocks.MockUserRepository)
+	loginThrottleUsecase := NewLoginThrottleUsecase(nil, mockUserRepo, testLoginThrottleConfig())
+
+	// Mock expectations
+	mockUserRepo.On("GetByID", uint64(1)).Return(&domain.User{ID: 1, Status: "active"}, nil)
+
+	// Execute
+	err := loginThrottleUsecase.UnlockAccount(1)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Equal(t, "ACCOUNT_NOT_LOCKED", err.Error())
+}
+
+func TestAuthUsecase_Login_LockedAccountRefusedWithoutPasswordCheck(t *testing.T) {
+	// Setup
+	mockUserRepo := new(mocks.MockUserRepository)
+	mockThrottleRepo := new(mocks.MockLoginThrottleRepository)
+	loginThrottleUsecase := NewLoginThrottleUsecase(mockThrottleRepo, mockUserRepo, testLoginThrottleConfig())
+	jwtManager := jwt.NewJWTManager("test-secret", 24, 168)
+
+	authUsecase := NewAuthUsecase(mockUserRepo, nil, nil, nil, nil, nil, loginThrottleUsecase, nil, jwtManager, nil)
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
+	lockedUntil := time.Now().Add(5 * time.Minute)
+	user := &domain.User{ID: 1, Email: "test@example.com", Password: string(hashedPassword), Status: "locked", LockedUntil: &lockedUntil}
+
+	// Mock expectations
+	mockThrottleRepo.On("Get", domain.LoginThrottleScopeIP, "10.0.0.1").Return(nil, gorm.ErrRecordNotFound)
+	mockUserRepo.On("GetByEmail", "test@example.com").Return(user, nil)
+
+	// Execute - even the correct password is refused while locked
+	result, err := authUsecase.Login(&domain.LoginRequest{Email: "test@example.com", Password: "password123", ClientIP: "10.0.0.1"})
+
+	// Assert
+	assert.Nil(t, result)
+	var throttleErr *domain.ThrottleError
+	assert.True(t, errors.As(err, &throttleErr))
+	assert.Equal(t, "ACCOUNT_LOCKED", throttleErr.Code)
+	assert.True(t, throttleErr.RetryAfter > 4*time.Minute)
+	mockThrottleRepo.AssertNotCalled(t, "RegisterFailure", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,39 @@
+package mocks
+
+import (
+	"go-commerce/internal/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCartRepository struct {
+	mock.Mock
+}
+
+func (m *MockCartRepository) GetOrCreateByUserID(userID uint64) (*domain.Cart, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Cart), args.Error(1)
+}
+
+func (m *MockCartRepository) SaveItem(cartID, productID, variantID uint64, quantity int) error {
+	args := m.Called(cartID, productID, variantID, quantity)
+	return args.Error(0)
+}
+
+func (m *MockCartRepository) RemoveItem(cartID, productID, variantID uint64) (bool, error) {
+	args := m.Called(cartID, productID, variantID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockCartRepository) Clear(cartID uint64) error {
+	args := m.Called(cartID)
+	return args.Error(0)
+}
+
+func (m *MockCartRepository) ClearWithTx(dbTx interface{}, cartID uint64) (int64, error) {
+	args := m.Called(dbTx, cartID)
+	return args.Get(0).(int64), args.Error(1)
+}
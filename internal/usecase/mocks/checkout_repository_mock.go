@@ -0,0 +1,61 @@
+package mocks
+
+import (
+	"time"
+
+	"go-commerce/internal/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCheckoutRepository struct {
+	mock.Mock
+}
+
+func (m *MockCheckoutRepository) CreateWithTx(dbTx interface{}, checkout *domain.Checkout) error {
+	args := m.Called(dbTx, checkout)
+	return args.Error(0)
+}
+
+func (m *MockCheckoutRepository) GetByID(id uint64) (*domain.Checkout, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Checkout), args.Error(1)
+}
+
+func (m *MockCheckoutRepository) UpdateStatus(id uint64, status string) error {
+	args := m.Called(id, status)
+	return args.Error(0)
+}
+
+func (m *MockCheckoutRepository) UpdateStatusWithTx(dbTx interface{}, id uint64, status string) error {
+	args := m.Called(dbTx, id, status)
+	return args.Error(0)
+}
+
+func (m *MockCheckoutRepository) SubtractTotalWithTx(dbTx interface{}, id uint64, amount float64) error {
+	args := m.Called(dbTx, id, amount)
+	return args.Error(0)
+}
+
+func (m *MockCheckoutRepository) TransitionStatusWithTx(dbTx interface{}, id uint64, from, to string) (bool, error) {
+	args := m.Called(dbTx, id, from, to)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockCheckoutRepository) MarkPaidWithTx(dbTx interface{}, id uint64, paidAt time.Time) (bool, error) {
+	args := m.Called(dbTx, id, paidAt)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockCheckoutRepository) HasPendingIntentWithTx(dbTx interface{}, id uint64) (bool, error) {
+	args := m.Called(dbTx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockCheckoutRepository) ExpirePendingIntentsWithTx(dbTx interface{}, id uint64) error {
+	args := m.Called(dbTx, id)
+	return args.Error(0)
+}
@@ -0,0 +1,58 @@
+package mocks
+
+import (
+	"go-commerce/internal/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockDisputeRepository struct {
+	mock.Mock
+}
+
+func (m *MockDisputeRepository) CreateWithTx(dbTx interface{}, dispute *domain.Dispute) error {
+	args := m.Called(dbTx, dispute)
+	return args.Error(0)
+}
+
+func (m *MockDisputeRepository) GetByID(id uint64) (*domain.Dispute, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Dispute), args.Error(1)
+}
+
+func (m *MockDisputeRepository) GetByIDWithLock(dbTx interface{}, id uint64) (*domain.Dispute, error) {
+	args := m.Called(dbTx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Dispute), args.Error(1)
+}
+
+func (m *MockDisputeRepository) GetByTransactionID(transactionID uint64) (*domain.Dispute, error) {
+	args := m.Called(transactionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Dispute), args.Error(1)
+}
+
+func (m *MockDisputeRepository) HasOpenWithTx(dbTx interface{}, transactionID uint64) (bool, error) {
+	args := m.Called(dbTx, transactionID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockDisputeRepository) GetByStatus(status string, limit, offset int) ([]*domain.Dispute, int64, error) {
+	args := m.Called(status, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]*domain.Dispute), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockDisputeRepository) ResolveWithTx(dbTx interface{}, dispute *domain.Dispute) error {
+	args := m.Called(dbTx, dispute)
+	return args.Error(0)
+}
@@ -0,0 +1,16 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockInvoiceSequenceRepository struct {
+	mock.Mock
+}
+
+func (m *MockInvoiceSequenceRepository) Next(name string, day time.Time, storeID uint64) (int64, error) {
+	args := m.Called(name, day, storeID)
+	return args.Get(0).(int64), args.Error(1)
+}
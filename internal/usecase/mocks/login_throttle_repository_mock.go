@@ -0,0 +1,44 @@
+package mocks
+
+import (
+	"time"
+
+	"go-commerce/internal/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockLoginThrottleRepository struct {
+	mock.Mock
+}
+
+func (m *MockLoginThrottleRepository) Get(scope, subject string) (*domain.LoginThrottle, error) {
+	args := m.Called(scope, subject)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.LoginThrottle), args.Error(1)
+}
+
+func (m *MockLoginThrottleRepository) RegisterFailure(scope, subject string, now time.Time, window time.Duration) (*domain.LoginThrottle, error) {
+	args := m.Called(scope, subject, now, window)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.LoginThrottle), args.Error(1)
+}
+
+func (m *MockLoginThrottleRepository) Lock(scope, subject string, until time.Time) error {
+	args := m.Called(scope, subject, until)
+	return args.Error(0)
+}
+
+func (m *MockLoginThrottleRepository) Reset(scope, subject string) error {
+	args := m.Called(scope, subject)
+	return args.Error(0)
+}
+
+func (m *MockLoginThrottleRepository) DeleteStale(before time.Time) (int64, error) {
+	args := m.Called(before)
+	return args.Get(0).(int64), args.Error(1)
+}
@@ -0,0 +1,16 @@
+package mocks
+
+import (
+	"go-commerce/internal/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockMailer struct {
+	mock.Mock
+}
+
+func (m *MockMailer) Send(msg *domain.EmailMessage) error {
+	args := m.Called(msg)
+	return args.Error(0)
+}
@@ -0,0 +1,31 @@
+package mocks
+
+import (
+	"go-commerce/internal/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockPaymentEventRepository struct {
+	mock.Mock
+}
+
+func (m *MockPaymentEventRepository) Create(event *domain.PaymentEvent) error {
+	args := m.Called(event)
+	return args.Error(0)
+}
+
+func (m *MockPaymentEventRepository) Claim(event *domain.PaymentEvent) (bool, error) {
+	args := m.Called(event)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockPaymentEventRepository) UpdateOutcome(id uint64, outcome, message string) error {
+	args := m.Called(id, outcome, message)
+	return args.Error(0)
+}
+
+func (m *MockPaymentEventRepository) ReleaseClaim(id uint64, message string) error {
+	args := m.Called(id, message)
+	return args.Error(0)
+}
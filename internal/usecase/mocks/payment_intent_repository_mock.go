@@ -0,0 +1,67 @@
+package mocks
+
+import (
+	"time"
+
+	"go-commerce/internal/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockPaymentIntentRepository struct {
+	mock.Mock
+}
+
+func (m *MockPaymentIntentRepository) Create(intent *domain.PaymentIntent) error {
+	args := m.Called(intent)
+	return args.Error(0)
+}
+
+func (m *MockPaymentIntentRepository) GetByID(id uint) (*domain.PaymentIntent, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.PaymentIntent), args.Error(1)
+}
+
+func (m *MockPaymentIntentRepository) GetByCheckoutID(checkoutID uint) (*domain.PaymentIntent, error) {
+	args := m.Called(checkoutID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.PaymentIntent), args.Error(1)
+}
+
+func (m *MockPaymentIntentRepository) UpdateStatus(id uint, status domain.PaymentIntentStatus) error {
+	args := m.Called(id, status)
+	return args.Error(0)
+}
+
+func (m *MockPaymentIntentRepository) AttachCharge(id uint, charge *domain.Charge) error {
+	args := m.Called(id, charge)
+	return args.Error(0)
+}
+
+func (m *MockPaymentIntentRepository) TransitionStatus(id uint, from, to domain.PaymentIntentStatus) (bool, error) {
+	args := m.Called(id, from, to)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockPaymentIntentRepository) TransitionStatusWithTx(dbTx interface{}, id uint, from, to domain.PaymentIntentStatus) (bool, error) {
+	args := m.Called(dbTx, id, from, to)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockPaymentIntentRepository) GetExpiredPendingWithLock(dbTx interface{}, now time.Time, limit int) ([]*domain.PaymentIntent, error) {
+	args := m.Called(dbTx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.PaymentIntent), args.Error(1)
+}
+
+func (m *MockPaymentIntentRepository) ExpireByCheckoutID(checkoutID uint) error {
+	args := m.Called(checkoutID)
+	return args.Error(0)
+}
@@ -0,0 +1,62 @@
+package mocks
+
+import (
+	"time"
+
+	"go-commerce/internal/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockPaymentIntentUsecase struct {
+	mock.Mock
+}
+
+func (m *MockPaymentIntentUsecase) CreatePaymentIntent(checkoutID uint, method string) (*domain.PaymentIntent, error) {
+	args := m.Called(checkoutID, method)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.PaymentIntent), args.Error(1)
+}
+
+func (m *MockPaymentIntentUsecase) CreatePaymentIntentForOrder(transactionID uint64, method string) (*domain.PaymentIntent, error) {
+	args := m.Called(transactionID, method)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.PaymentIntent), args.Error(1)
+}
+
+func (m *MockPaymentIntentUsecase) ProcessPaymentSuccess(intentID uint, paidAt time.Time) error {
+	args := m.Called(intentID, paidAt)
+	return args.Error(0)
+}
+
+func (m *MockPaymentIntentUsecase) ProcessPaymentFailed(intentID uint) error {
+	args := m.Called(intentID)
+	return args.Error(0)
+}
+
+func (m *MockPaymentIntentUsecase) ExpireIntentsByCheckoutID(checkoutID uint) error {
+	args := m.Called(checkoutID)
+	return args.Error(0)
+}
+
+func (m *MockPaymentIntentUsecase) ExpireStaleIntents() (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockPaymentIntentUsecase) GetPaymentIntent(userID uint64, intentID uint) (*domain.PaymentIntent, error) {
+	args := m.Called(userID, intentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.PaymentIntent), args.Error(1)
+}
+
+func (m *MockPaymentIntentUsecase) RefundPayment(checkoutID uint, amount float64, idempotencyKey string) (string, error) {
+	args := m.Called(checkoutID, amount, idempotencyKey)
+	return args.String(0), args.Error(1)
+}
@@ -0,0 +1,48 @@
+package mocks
+
+import (
+	"go-commerce/internal/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockPaymentProvider struct {
+	mock.Mock
+}
+
+func (m *MockPaymentProvider) Name() string {
+	args := m.Called()
+	return args.String(0)
+}
+
+func (m *MockPaymentProvider) CreateCharge(req *domain.ChargeRequest) (*domain.Charge, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Charge), args.Error(1)
+}
+
+func (m *MockPaymentProvider) GetStatus(reference string) (*domain.ChargeStatus, error) {
+	args := m.Called(reference)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ChargeStatus), args.Error(1)
+}
+
+func (m *MockPaymentProvider) Refund(reference string, amount float64, idempotencyKey string) (string, error) {
+	args := m.Called(reference, amount, idempotencyKey)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockPaymentProvider) ParseWebhook(body []byte) (*domain.PaymentWebhook, error) {
+	args := m.Called(body)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	if decode, ok := args.Get(0).(func([]byte) *domain.PaymentWebhook); ok {
+		return decode(body), args.Error(1)
+	}
+	return args.Get(0).(*domain.PaymentWebhook), args.Error(1)
+}
@@ -64,6 +64,30 @@ func (m *ProductRepositoryMock) GetAllWithFilter(filter *domain.ProductFilter) (
 	return args.Get(0).([]*domain.Product), args.Get(1).(int64), args.Error(2)
 }
 
+func (m *ProductRepositoryMock) GetAllWithCursor(filter *domain.ProductFilter, page *domain.CursorPage) ([]*domain.Product, error) {
+	args := m.Called(filter, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Product), args.Error(1)
+}
+
+func (m *ProductRepositoryMock) GetByTokoIDWithCursor(tokoID uint64, search string, page *domain.CursorPage) ([]*domain.Product, error) {
+	args := m.Called(tokoID, search, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Product), args.Error(1)
+}
+
+func (m *ProductRepositoryMock) GetByIDs(ids []uint64) ([]*domain.Product, error) {
+	args := m.Called(ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Product), args.Error(1)
+}
+
 func (m *ProductRepositoryMock) SearchBySlug(slugPattern string, limit, offset int) ([]*domain.Product, int64, error) {
 	args := m.Called(slugPattern, limit, offset)
 	return args.Get(0).([]*domain.Product), args.Get(1).(int64), args.Error(2)
@@ -110,8 +134,13 @@ func (m *ProductRepositoryMock) GetByIDForManagement(id uint64) (*domain.Product
 	return args.Get(0).(*domain.Product), args.Error(1)
 }
 
-func (m *ProductRepositoryMock) UpdateStockWithTx(dbTx interface{}, productID uint64, quantity int) error {
-	args := m.Called(dbTx, productID, quantity)
+func (m *ProductRepositoryMock) UpdateStockWithTx(dbTx interface{}, productID, variantID uint64, quantity int) error {
+	args := m.Called(dbTx, productID, variantID, quantity)
+	return args.Error(0)
+}
+
+func (m *ProductRepositoryMock) UpdateHeldStockWithTx(dbTx interface{}, productID, variantID uint64, quantity int) error {
+	args := m.Called(dbTx, productID, variantID, quantity)
 	return args.Error(0)
 }
 
@@ -120,7 +149,12 @@ func (m *ProductRepositoryMock) UpdateSoldCountWithTx(dbTx interface{}, productI
 	return args.Error(0)
 }
 
-func (m *ProductRepositoryMock) GetStockWithLock(dbTx interface{}, productID uint64) (int, error) {
-	args := m.Called(dbTx, productID)
+func (m *ProductRepositoryMock) AddRatingWithTx(dbTx interface{}, productID uint64, rating, count int) error {
+	args := m.Called(dbTx, productID, rating, count)
+	return args.Error(0)
+}
+
+func (m *ProductRepositoryMock) GetStockWithLock(dbTx interface{}, productID, variantID uint64) (int, error) {
+	args := m.Called(dbTx, productID, variantID)
 	return args.Get(0).(int), args.Error(1)
 }
\ No newline at end of file
@@ -0,0 +1,29 @@
+package mocks
+
+import (
+	"go-commerce/internal/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockProductSearchIndex struct {
+	mock.Mock
+}
+
+func (m *MockProductSearchIndex) Index(product *domain.Product) error {
+	args := m.Called(product)
+	return args.Error(0)
+}
+
+func (m *MockProductSearchIndex) Remove(productID uint64) error {
+	args := m.Called(productID)
+	return args.Error(0)
+}
+
+func (m *MockProductSearchIndex) Search(query *domain.ProductSearchQuery) (*domain.ProductSearchResult, error) {
+	args := m.Called(query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ProductSearchResult), args.Error(1)
+}
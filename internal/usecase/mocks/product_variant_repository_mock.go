@@ -0,0 +1,63 @@
+package mocks
+
+import (
+	"go-commerce/internal/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockProductVariantRepository struct {
+	mock.Mock
+}
+
+func (m *MockProductVariantRepository) ReplaceOptions(productID uint64, options []*domain.ProductOption) error {
+	args := m.Called(productID, options)
+	return args.Error(0)
+}
+
+func (m *MockProductVariantRepository) GetOptionsByProductID(productID uint64) ([]*domain.ProductOption, error) {
+	args := m.Called(productID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.ProductOption), args.Error(1)
+}
+
+func (m *MockProductVariantRepository) Create(variant *domain.ProductVariant) error {
+	args := m.Called(variant)
+	return args.Error(0)
+}
+
+func (m *MockProductVariantRepository) GetByID(id uint64) (*domain.ProductVariant, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ProductVariant), args.Error(1)
+}
+
+func (m *MockProductVariantRepository) GetByProductID(productID uint64) ([]*domain.ProductVariant, error) {
+	args := m.Called(productID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.ProductVariant), args.Error(1)
+}
+
+func (m *MockProductVariantRepository) GetBySKU(sku string) (*domain.ProductVariant, error) {
+	args := m.Called(sku)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ProductVariant), args.Error(1)
+}
+
+func (m *MockProductVariantRepository) Update(variant *domain.ProductVariant) error {
+	args := m.Called(variant)
+	return args.Error(0)
+}
+
+func (m *MockProductVariantRepository) Delete(id uint64) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
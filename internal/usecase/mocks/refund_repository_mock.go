@@ -0,0 +1,37 @@
+package mocks
+
+import (
+	"go-commerce/internal/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRefundRepository struct {
+	mock.Mock
+}
+
+func (m *MockRefundRepository) CreateWithTx(dbTx interface{}, refund *domain.Refund) error {
+	args := m.Called(dbTx, refund)
+	return args.Error(0)
+}
+
+func (m *MockRefundRepository) GetByID(id uint64) (*domain.Refund, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Refund), args.Error(1)
+}
+
+func (m *MockRefundRepository) GetByTransactionID(transactionID uint64) ([]*domain.Refund, error) {
+	args := m.Called(transactionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Refund), args.Error(1)
+}
+
+func (m *MockRefundRepository) UpdateStatus(id uint64, status domain.RefundStatus, providerRef string) error {
+	args := m.Called(id, status, providerRef)
+	return args.Error(0)
+}
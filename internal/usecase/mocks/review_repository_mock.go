@@ -0,0 +1,53 @@
+package mocks
+
+import (
+	"go-commerce/internal/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockReviewRepository struct {
+	mock.Mock
+}
+
+func (m *MockReviewRepository) CreateWithTx(dbTx interface{}, review *domain.Review) error {
+	args := m.Called(dbTx, review)
+	return args.Error(0)
+}
+
+func (m *MockReviewRepository) GetByID(id uint64) (*domain.Review, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Review), args.Error(1)
+}
+
+func (m *MockReviewRepository) GetByIDWithLock(dbTx interface{}, id uint64) (*domain.Review, error) {
+	args := m.Called(dbTx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Review), args.Error(1)
+}
+
+func (m *MockReviewRepository) GetByTransactionItemID(transactionItemID uint64) (*domain.Review, error) {
+	args := m.Called(transactionItemID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Review), args.Error(1)
+}
+
+func (m *MockReviewRepository) GetVisibleByProductID(productID uint64, limit, offset int) ([]*domain.Review, int64, error) {
+	args := m.Called(productID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]*domain.Review), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockReviewRepository) UpdateWithTx(dbTx interface{}, review *domain.Review) error {
+	args := m.Called(dbTx, review)
+	return args.Error(0)
+}
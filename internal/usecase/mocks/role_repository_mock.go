@@ -0,0 +1,50 @@
+package mocks
+
+import (
+	"go-commerce/internal/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRoleRepository struct {
+	mock.Mock
+}
+
+func (m *MockRoleRepository) GetAll() ([]*domain.Role, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Role), args.Error(1)
+}
+
+func (m *MockRoleRepository) GetByName(name string) (*domain.Role, error) {
+	args := m.Called(name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Role), args.Error(1)
+}
+
+func (m *MockRoleRepository) GetUserRoles(userID uint64) ([]*domain.Role, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Role), args.Error(1)
+}
+
+func (m *MockRoleRepository) AssignToUser(userID, roleID uint64) error {
+	args := m.Called(userID, roleID)
+	return args.Error(0)
+}
+
+func (m *MockRoleRepository) AssignToUserWithTx(dbTx interface{}, userID, roleID uint64) error {
+	args := m.Called(dbTx, userID, roleID)
+	return args.Error(0)
+}
+
+func (m *MockRoleRepository) RevokeFromUser(userID, roleID uint64) (bool, error) {
+	args := m.Called(userID, roleID)
+	return args.Bool(0), args.Error(1)
+}
@@ -0,0 +1,51 @@
+package mocks
+
+import (
+	"time"
+
+	"go-commerce/internal/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSearchSuggestIndex struct {
+	mock.Mock
+}
+
+func (m *MockSearchSuggestIndex) Load(entries []*domain.SuggestionEntry) {
+	m.Called(entries)
+}
+
+func (m *MockSearchSuggestIndex) Put(entry *domain.SuggestionEntry) {
+	m.Called(entry)
+}
+
+func (m *MockSearchSuggestIndex) Remove(kind string, id uint64) {
+	m.Called(kind, id)
+}
+
+func (m *MockSearchSuggestIndex) RecordQuery(query string, count int64) {
+	m.Called(query, count)
+}
+
+func (m *MockSearchSuggestIndex) Suggest(prefix string, limit int) *domain.SearchSuggestions {
+	args := m.Called(prefix, limit)
+	return args.Get(0).(*domain.SearchSuggestions)
+}
+
+type MockSearchQueryRepository struct {
+	mock.Mock
+}
+
+func (m *MockSearchQueryRepository) Increment(query string, searchedAt time.Time) error {
+	args := m.Called(query, searchedAt)
+	return args.Error(0)
+}
+
+func (m *MockSearchQueryRepository) GetPopular(limit int) ([]*domain.SearchQuery, error) {
+	args := m.Called(limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.SearchQuery), args.Error(1)
+}
@@ -0,0 +1,24 @@
+package mocks
+
+import (
+	"go-commerce/internal/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSettingRepository struct {
+	mock.Mock
+}
+
+func (m *MockSettingRepository) Get(name string) (*domain.Setting, error) {
+	args := m.Called(name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Setting), args.Error(1)
+}
+
+func (m *MockSettingRepository) Set(name, value string) error {
+	args := m.Called(name, value)
+	return args.Error(0)
+}
@@ -0,0 +1,61 @@
+package mocks
+
+import (
+	"time"
+
+	"go-commerce/internal/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockShipmentRepository struct {
+	mock.Mock
+}
+
+func (m *MockShipmentRepository) CreateWithTx(dbTx interface{}, shipment *domain.Shipment) error {
+	args := m.Called(dbTx, shipment)
+	return args.Error(0)
+}
+
+func (m *MockShipmentRepository) GetByTransactionID(transactionID uint64) (*domain.Shipment, error) {
+	args := m.Called(transactionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Shipment), args.Error(1)
+}
+
+func (m *MockShipmentRepository) GetDueForTracking(polledBefore time.Time, limit int) ([]*domain.Shipment, error) {
+	args := m.Called(polledBefore, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Shipment), args.Error(1)
+}
+
+func (m *MockShipmentRepository) AddEvents(shipmentID uint64, events []*domain.ShipmentEvent) (int, error) {
+	args := m.Called(shipmentID, events)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockShipmentRepository) MarkPolled(id uint64, at time.Time) error {
+	args := m.Called(id, at)
+	return args.Error(0)
+}
+
+func (m *MockShipmentRepository) MarkDeliveredWithTx(dbTx interface{}, id uint64, deliveredAt time.Time) error {
+	args := m.Called(dbTx, id, deliveredAt)
+	return args.Error(0)
+}
+
+type MockCourierTracker struct {
+	mock.Mock
+}
+
+func (m *MockCourierTracker) Track(courier, trackingNumber string) (*domain.TrackingResult, error) {
+	args := m.Called(courier, trackingNumber)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.TrackingResult), args.Error(1)
+}
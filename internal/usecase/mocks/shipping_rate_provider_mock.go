@@ -0,0 +1,36 @@
+package mocks
+
+import (
+	"go-commerce/internal/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockShippingRateProvider struct {
+	mock.Mock
+}
+
+func (m *MockShippingRateProvider) Name() string {
+	args := m.Called()
+	return args.String(0)
+}
+
+func (m *MockShippingRateProvider) Rates(parcel domain.ShippingParcel) ([]domain.ShippingRate, error) {
+	args := m.Called(parcel)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.ShippingRate), args.Error(1)
+}
+
+type MockShippingRateRepository struct {
+	mock.Mock
+}
+
+func (m *MockShippingRateRepository) FindMatching(parcel domain.ShippingParcel) ([]*domain.ShippingRateRule, error) {
+	args := m.Called(parcel)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.ShippingRateRule), args.Error(1)
+}
@@ -0,0 +1,52 @@
+package mocks
+
+import (
+	"time"
+
+	"go-commerce/internal/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockStockReservationRepository struct {
+	mock.Mock
+}
+
+func (m *MockStockReservationRepository) CreateWithTx(dbTx interface{}, reservation *domain.StockReservation) error {
+	args := m.Called(dbTx, reservation)
+	return args.Error(0)
+}
+
+func (m *MockStockReservationRepository) GetHeldByCheckoutIDWithTx(dbTx interface{}, checkoutID uint64) ([]*domain.StockReservation, error) {
+	args := m.Called(dbTx, checkoutID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.StockReservation), args.Error(1)
+}
+
+func (m *MockStockReservationRepository) GetHeldByTransactionIDWithTx(dbTx interface{}, transactionID uint64) ([]*domain.StockReservation, error) {
+	args := m.Called(dbTx, transactionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.StockReservation), args.Error(1)
+}
+
+func (m *MockStockReservationRepository) GetExpiredCheckoutIDs(now time.Time, limit int) ([]uint64, error) {
+	args := m.Called(now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uint64), args.Error(1)
+}
+
+func (m *MockStockReservationRepository) TransitionWithTx(dbTx interface{}, id uint64, from, to string) (bool, error) {
+	args := m.Called(dbTx, id, from, to)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockStockReservationRepository) ExtendByCheckoutID(checkoutID uint64, until time.Time) error {
+	args := m.Called(checkoutID, until)
+	return args.Error(0)
+}
@@ -56,6 +56,14 @@ func (m *MockStoreRepository) GetActiveStores(limit, offset int, search string)
 	return args.Get(0).([]*domain.Store), args.Get(1).(int64), args.Error(2)
 }
 
+func (m *MockStoreRepository) GetActiveStoresWithCursor(search string, page *domain.CursorPage) ([]*domain.Store, error) {
+	args := m.Called(search, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Store), args.Error(1)
+}
+
 func (m *MockStoreRepository) GetPendingStores(limit, offset int, search string) ([]*domain.Store, int64, error) {
 	args := m.Called(limit, offset, search)
 	if args.Get(0) == nil {
@@ -70,4 +78,9 @@ func (m *MockStoreRepository) GetActiveStoreByUserID(userID uint64) (*domain.Sto
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*domain.Store), args.Error(1)
+}
+
+func (m *MockStoreRepository) AddRatingWithTx(dbTx interface{}, storeID uint64, rating, count int) error {
+	args := m.Called(dbTx, storeID, rating, count)
+	return args.Error(0)
 }
\ No newline at end of file
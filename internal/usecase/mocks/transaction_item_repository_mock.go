@@ -26,4 +26,17 @@ func (m *MockTransactionItemRepository) GetByTransactionID(transactionID uint64)
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]*domain.TransactionItem), args.Error(1)
-}
\ No newline at end of file
+}
+
+func (m *MockTransactionItemRepository) GetByID(id uint64) (*domain.TransactionItem, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.TransactionItem), args.Error(1)
+}
+
+func (m *MockTransactionItemRepository) AddRefundedQuantityWithTx(dbTx interface{}, id uint64, quantity int) error {
+	args := m.Called(dbTx, id, quantity)
+	return args.Error(0)
+}
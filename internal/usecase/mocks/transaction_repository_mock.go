@@ -1,6 +1,8 @@
 package mocks
 
 import (
+	"time"
+
 	"go-commerce/internal/domain"
 
 	"github.com/stretchr/testify/mock"
@@ -23,6 +25,14 @@ func (m *MockTransactionRepository) GetByID(id uint64) (*domain.Transaction, err
 	return args.Get(0).(*domain.Transaction), args.Error(1)
 }
 
+func (m *MockTransactionRepository) GetByInvoice(kodeInvoice string) (*domain.Transaction, error) {
+	args := m.Called(kodeInvoice)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Transaction), args.Error(1)
+}
+
 func (m *MockTransactionRepository) GetByUserID(userID uint64, limit, offset int) ([]*domain.Transaction, int64, error) {
 	args := m.Called(userID, limit, offset)
 	if args.Get(0) == nil {
@@ -31,6 +41,14 @@ func (m *MockTransactionRepository) GetByUserID(userID uint64, limit, offset int
 	return args.Get(0).([]*domain.Transaction), args.Get(1).(int64), args.Error(2)
 }
 
+func (m *MockTransactionRepository) GetByUserIDWithCursor(userID uint64, page *domain.CursorPage) ([]*domain.Transaction, error) {
+	args := m.Called(userID, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Transaction), args.Error(1)
+}
+
 func (m *MockTransactionRepository) GetByStoreID(storeID uint64, limit, offset int) ([]*domain.Transaction, int64, error) {
 	args := m.Called(storeID, limit, offset)
 	if args.Get(0) == nil {
@@ -72,22 +90,33 @@ func (m *MockTransactionRepository) GetByStatus(status string, limit, offset int
 	return args.Get(0).([]*domain.Transaction), args.Get(1).(int64), args.Error(2)
 }
 
-func (m *MockTransactionRepository) UpdateStatus(id uint64, status string) error {
-	args := m.Called(id, status)
-	return args.Error(0)
+func (m *MockTransactionRepository) TransitionStatusWithTx(dbTx interface{}, id uint64, from, to string, at time.Time) (bool, error) {
+	args := m.Called(dbTx, id, from, to, at)
+	return args.Bool(0), args.Error(1)
 }
 
-func (m *MockTransactionRepository) UpdatePaymentStatus(id uint64, paymentStatus string) error {
-	args := m.Called(id, paymentStatus)
-	return args.Error(0)
+func (m *MockTransactionRepository) TransitionOrderStatusWithTx(dbTx interface{}, id uint64, from, to string, at time.Time) (bool, error) {
+	args := m.Called(dbTx, id, from, to, at)
+	return args.Bool(0), args.Error(1)
 }
 
-func (m *MockTransactionRepository) UpdateOrderStatus(id uint64, orderStatus string) error {
-	args := m.Called(id, orderStatus)
-	return args.Error(0)
+func (m *MockTransactionRepository) GetByIDWithLock(dbTx interface{}, id uint64) (*domain.Transaction, error) {
+	args := m.Called(dbTx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Transaction), args.Error(1)
 }
 
-func (m *MockTransactionRepository) UpdateStatusWithTx(dbTx interface{}, id uint64, status string) error {
-	args := m.Called(dbTx, id, status)
+func (m *MockTransactionRepository) AddRefundedAmountWithTx(dbTx interface{}, id uint64, amount float64) error {
+	args := m.Called(dbTx, id, amount)
 	return args.Error(0)
-}
\ No newline at end of file
+}
+
+func (m *MockTransactionRepository) GetDueForCompletion(shippedBefore time.Time, limit int) ([]*domain.Transaction, error) {
+	args := m.Called(shippedBefore, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Transaction), args.Error(1)
+}
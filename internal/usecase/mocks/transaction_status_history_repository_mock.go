@@ -0,0 +1,24 @@
+package mocks
+
+import (
+	"go-commerce/internal/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTransactionStatusHistoryRepository struct {
+	mock.Mock
+}
+
+func (m *MockTransactionStatusHistoryRepository) CreateWithTx(dbTx interface{}, entry *domain.TransactionStatusHistory) error {
+	args := m.Called(dbTx, entry)
+	return args.Error(0)
+}
+
+func (m *MockTransactionStatusHistoryRepository) GetByTransactionID(transactionID uint64) ([]*domain.TransactionStatusHistory, error) {
+	args := m.Called(transactionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.TransactionStatusHistory), args.Error(1)
+}
@@ -0,0 +1,26 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTwoFactorRecoveryCodeRepository struct {
+	mock.Mock
+}
+
+func (m *MockTwoFactorRecoveryCodeRepository) ReplaceForUser(userID uint64, codeHashes []string) error {
+	args := m.Called(userID, codeHashes)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRecoveryCodeRepository) Consume(userID uint64, codeHash string, usedAt time.Time) (bool, error) {
+	args := m.Called(userID, codeHash, usedAt)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTwoFactorRecoveryCodeRepository) DeleteByUserID(userID uint64) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
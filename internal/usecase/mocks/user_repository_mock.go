@@ -58,4 +58,43 @@ func (m *MockUserRepository) UpdateLastLogin(userID uint64, lastLogin time.Time)
 func (m *MockUserRepository) UpdateProfile(userID uint64, updates map[string]interface{}) error {
 	args := m.Called(userID, updates)
 	return args.Error(0)
-}
\ No newline at end of file
+}
+
+func (m *MockUserRepository) MarkEmailVerified(userID uint64, verifiedAt time.Time) error {
+	args := m.Called(userID, verifiedAt)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) ReserveVerificationEmail(userID uint64, sentAt, notBefore time.Time) (bool, error) {
+	args := m.Called(userID, sentAt, notBefore)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUserRepository) LockAccount(userID uint64, until time.Time) error {
+	args := m.Called(userID, until)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UnlockAccount(userID uint64) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+func (m *MockUserRepository) SetTOTPSecret(userID uint64, secret string) error {
+	args := m.Called(userID, secret)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) EnableTOTP(userID uint64, enabledAt time.Time) error {
+	args := m.Called(userID, enabledAt)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) DisableTOTP(userID uint64) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UseTOTPStep(userID uint64, step int64) (bool, error) {
+	args := m.Called(userID, step)
+	return args.Bool(0), args.Error(1)
+}
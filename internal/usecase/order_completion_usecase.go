@@ -0,0 +1,399 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"go-commerce/internal/domain"
+)
+
+const (
+	// autoConfirmBatch is how many orders are loaded per query
+	autoConfirmBatch = 50
+	// autoConfirmLimit caps the orders completed per run
+	autoConfirmLimit = 1000
+)
+
+// OrderCompletionConfig holds the confirmation window of shipped orders
+type OrderCompletionConfig struct {
+	// AutoConfirmAfter is how long after shipping the buyer can dispute an
+	// order before it is completed automatically
+	AutoConfirmAfter time.Duration
+}
+
+// OrderCompletionUsecase completes shipped orders, moving their payment to
+// done so the money can be paid out to the seller. The buyer completes an
+// order by confirming delivery, or it completes itself once the confirmation
+// window ends. Until then the buyer can dispute it, which holds the order
+// until an admin resolves the dispute.
+type OrderCompletionUsecase struct {
+	disputeRepo     domain.DisputeRepository
+	transactionRepo domain.TransactionRepository
+	storeRepo       domain.StoreRepository
+	orderStatus     *OrderStatusUsecase
+	refundUsecase   *RefundUsecase
+	config          OrderCompletionConfig
+}
+
+func NewOrderCompletionUsecase(
+	disputeRepo domain.DisputeRepository,
+	transactionRepo domain.TransactionRepository,
+	storeRepo domain.StoreRepository,
+	orderStatus *OrderStatusUsecase,
+	refundUsecase *RefundUsecase,
+	config OrderCompletionConfig,
+) *OrderCompletionUsecase {
+	return &OrderCompletionUsecase{
+		disputeRepo:     disputeRepo,
+		transactionRepo: transactionRepo,
+		storeRepo:       storeRepo,
+		orderStatus:     orderStatus,
+		refundUsecase:   refundUsecase,
+		config:          config,
+	}
+}
+
+// ConfirmDelivered - Buyer confirms the order arrived, which completes it. An
+// order still shipped is marked delivered first.
+func (u *OrderCompletionUsecase) ConfirmDelivered(userID, transactionID uint64) error {
+	transaction, err := u.transactionRepo.GetByID(transactionID)
+	if err != nil {
+		return errors.New("transaction not found")
+	}
+
+	// Check ownership
+	if transaction.UserID != userID {
+		return errors.New("forbidden")
+	}
+
+	if transaction.Status != domain.PaymentStatusPaid {
+		return errors.New("payment not completed")
+	}
+
+	buyer := domain.StatusActor{Role: domain.StatusActorBuyer, UserID: userID}
+	completed, err := u.complete(transactionID, buyer, "confirmed by buyer")
+	if err != nil {
+		return err
+	}
+	if !completed {
+		return errors.New("INVALID_STATUS_TRANSITION")
+	}
+	return nil
+}
+
+// AutoConfirmOrders completes every paid order whose confirmation window
+// ended without a dispute and returns how many were completed. Transitions
+// are conditional, so running it on several instances at once is harmless.
+func (u *OrderCompletionUsecase) AutoConfirmOrders() (int, error) {
+	shippedBefore := time.Now().Add(-u.config.AutoConfirmAfter)
+	reason := "auto-confirmed after the confirmation window"
+
+	completed := 0
+	processed := 0
+	for processed < autoConfirmLimit {
+		transactions, err := u.transactionRepo.GetDueForCompletion(shippedBefore, autoConfirmBatch)
+		if err != nil {
+			return completed, err
+		}
+		if len(transactions) == 0 {
+			break
+		}
+
+		// Orders that fail stay due; stop when a whole batch failed so they
+		// are retried on the next run instead of in a loop
+		progress := false
+		for _, transaction := range transactions {
+			processed++
+			ok, err := u.complete(transaction.ID, domain.SystemActor, reason)
+			if err != nil {
+				log.Printf("Error auto-confirming transaction %d: %v", transaction.ID, err)
+				continue
+			}
+			progress = true
+			if ok {
+				completed++
+			}
+		}
+		if !progress {
+			break
+		}
+	}
+
+	return completed, nil
+}
+
+// OpenDispute - Buyer disputes a shipped order within the confirmation
+// window, with a reason and evidence photos. The order is not completed
+// while the dispute is open.
+func (u *OrderCompletionUsecase) OpenDispute(userID, transactionID uint64, req *domain.OpenDisputeRequest, photoURLs []string) (*domain.Dispute, error) {
+	if len(photoURLs) > domain.MaxDisputePhotos {
+		return nil, errors.New("too many photos")
+	}
+
+	dbTx, err := u.transactionRepo.BeginTx()
+	if err != nil {
+		return nil, err
+	}
+
+	// Lock the order so it cannot be completed while the dispute is opened
+	transaction, err := u.transactionRepo.GetByIDWithLock(dbTx, transactionID)
+	if err != nil {
+		u.transactionRepo.RollbackTx(dbTx)
+		return nil, errors.New("transaction not found")
+	}
+
+	// Check ownership
+	if transaction.UserID != userID {
+		u.transactionRepo.RollbackTx(dbTx)
+		return nil, errors.New("forbidden")
+	}
+
+	if transaction.Status != domain.PaymentStatusPaid || transaction.ShippedAt == nil ||
+		(transaction.OrderStatus != domain.OrderStatusShipped && transaction.OrderStatus != domain.OrderStatusDelivered) {
+		u.transactionRepo.RollbackTx(dbTx)
+		return nil, errors.New("NOT_DISPUTABLE")
+	}
+
+	if !time.Now().Before(transaction.ShippedAt.Add(u.config.AutoConfirmAfter)) {
+		u.transactionRepo.RollbackTx(dbTx)
+		return nil, errors.New("DISPUTE_WINDOW_CLOSED")
+	}
+
+	if _, err := u.disputeRepo.GetByTransactionID(transactionID); err == nil {
+		u.transactionRepo.RollbackTx(dbTx)
+		return nil, errors.New("DISPUTE_ALREADY_OPENED")
+	}
+
+	dispute := &domain.Dispute{
+		TransactionID: transactionID,
+		UserID:        userID,
+		Reason:        req.Reason,
+		Status:        domain.DisputeStatusOpen,
+	}
+	for _, url := range photoURLs {
+		dispute.Photos = append(dispute.Photos, &domain.DisputePhoto{URL: url})
+	}
+
+	if err := u.disputeRepo.CreateWithTx(dbTx, dispute); err != nil {
+		u.transactionRepo.RollbackTx(dbTx)
+		return nil, err
+	}
+
+	if err := u.transactionRepo.CommitTx(dbTx); err != nil {
+		return nil, err
+	}
+
+	return dispute, nil
+}
+
+// GetDispute returns the dispute of an order. Only the buyer, the seller of
+// the order and admins can see it.
+func (u *OrderCompletionUsecase) GetDispute(userID, transactionID uint64, isAdmin bool) (*domain.Dispute, error) {
+	transaction, err := u.transactionRepo.GetByID(transactionID)
+	if err != nil {
+		return nil, errors.New("transaction not found")
+	}
+
+	if !isAdmin && transaction.UserID != userID {
+		store, err := u.storeRepo.GetByID(transaction.StoreID)
+		if err != nil || store.UserID != userID {
+			return nil, errors.New("forbidden")
+		}
+	}
+
+	dispute, err := u.disputeRepo.GetByTransactionID(transactionID)
+	if err != nil {
+		return nil, errors.New("dispute not found")
+	}
+
+	return dispute, nil
+}
+
+// GetDisputeByID - Admin gets a dispute
+func (u *OrderCompletionUsecase) GetDisputeByID(disputeID uint64) (*domain.Dispute, error) {
+	dispute, err := u.disputeRepo.GetByID(disputeID)
+	if err != nil {
+		return nil, errors.New("dispute not found")
+	}
+	return dispute, nil
+}
+
+// ListDisputes - Admin lists disputes by status, oldest first
+func (u *OrderCompletionUsecase) ListDisputes(status string, page, limit int) ([]*domain.Dispute, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+	if status == "" {
+		status = domain.DisputeStatusOpen
+	}
+
+	offset := (page - 1) * limit
+	return u.disputeRepo.GetByStatus(status, limit, offset)
+}
+
+// ResolveDispute - Admin settles an open dispute. A refund returns everything
+// not refunded yet; a partial refund returns the given items or amount and
+// completes the order; a rejection completes the order as it is.
+func (u *OrderCompletionUsecase) ResolveDispute(adminID, disputeID uint64, req *domain.ResolveDisputeRequest) (*domain.Dispute, error) {
+	if req.Resolution == domain.DisputeResolutionPartialRefund && len(req.Items) == 0 && req.Amount == 0 {
+		return nil, errors.New("partial refund requires items or an amount")
+	}
+
+	dbTx, err := u.transactionRepo.BeginTx()
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			u.transactionRepo.RollbackTx(dbTx)
+			panic(r)
+		}
+	}()
+
+	dispute, refund, err := u.resolveWithTx(dbTx, adminID, disputeID, req)
+	if err != nil {
+		u.transactionRepo.RollbackTx(dbTx)
+		return nil, err
+	}
+
+	if err := u.transactionRepo.CommitTx(dbTx); err != nil {
+		return nil, err
+	}
+
+	// The refund is on the ledger; return the money now that it is committed
+	if refund != nil {
+		if _, err := u.refundUsecase.SendRefund(refund); err != nil {
+			return nil, err
+		}
+	}
+
+	return dispute, nil
+}
+
+func (u *OrderCompletionUsecase) resolveWithTx(dbTx interface{}, adminID, disputeID uint64, req *domain.ResolveDisputeRequest) (*domain.Dispute, *domain.Refund, error) {
+	dispute, err := u.disputeRepo.GetByIDWithLock(dbTx, disputeID)
+	if err != nil {
+		return nil, nil, errors.New("dispute not found")
+	}
+
+	if dispute.Status != domain.DisputeStatusOpen {
+		return nil, nil, errors.New("DISPUTE_ALREADY_RESOLVED")
+	}
+
+	reason := fmt.Sprintf("dispute #%d", dispute.ID)
+	if req.Note != "" {
+		reason += ": " + req.Note
+	}
+
+	var refund *domain.Refund
+	switch req.Resolution {
+	case domain.DisputeResolutionRefund, domain.DisputeResolutionPartialRefund:
+		refundReq := &domain.RefundRequest{Reason: reason}
+		dispute.Status = domain.DisputeStatusRefunded
+		if req.Resolution == domain.DisputeResolutionPartialRefund {
+			refundReq.Items = req.Items
+			refundReq.Amount = req.Amount
+			dispute.Status = domain.DisputeStatusPartiallyRefunded
+		}
+
+		refund, err = u.refundUsecase.RefundWithTx(dbTx, adminID, dispute.TransactionID, refundReq)
+		if err != nil {
+			return nil, nil, err
+		}
+		dispute.RefundID = &refund.ID
+	default:
+		dispute.Status = domain.DisputeStatusRejected
+	}
+
+	now := time.Now()
+	dispute.ResolutionNote = req.Note
+	dispute.ResolvedBy = &adminID
+	dispute.ResolvedAt = &now
+	if err := u.disputeRepo.ResolveWithTx(dbTx, dispute); err != nil {
+		return nil, nil, err
+	}
+
+	// A full refund closed the order already; anything else completes it
+	admin := domain.StatusActor{Role: domain.StatusActorAdmin, UserID: adminID}
+	if _, err := u.completeWithTx(dbTx, dispute.TransactionID, admin, reason); err != nil {
+		return nil, nil, err
+	}
+
+	return dispute, refund, nil
+}
+
+// complete completes the order in a database transaction of its own
+func (u *OrderCompletionUsecase) complete(transactionID uint64, actor domain.StatusActor, reason string) (bool, error) {
+	dbTx, err := u.transactionRepo.BeginTx()
+	if err != nil {
+		return false, err
+	}
+
+	completed, err := u.completeWithTx(dbTx, transactionID, actor, reason)
+	if err != nil {
+		u.transactionRepo.RollbackTx(dbTx)
+		return false, err
+	}
+
+	if err := u.transactionRepo.CommitTx(dbTx); err != nil {
+		return false, err
+	}
+
+	return completed, nil
+}
+
+// completeWithTx moves a paid, shipped or delivered order to done and reports
+// whether it did. Orders no longer paid, e.g. refunded meanwhile, are left
+// alone. The order is locked first so a dispute cannot be opened in between.
+func (u *OrderCompletionUsecase) completeWithTx(dbTx interface{}, transactionID uint64, actor domain.StatusActor, reason string) (bool, error) {
+	transaction, err := u.transactionRepo.GetByIDWithLock(dbTx, transactionID)
+	if err != nil {
+		return false, errors.New("transaction not found")
+	}
+
+	if transaction.Status != domain.PaymentStatusPaid {
+		return false, nil
+	}
+
+	open, err := u.disputeRepo.HasOpenWithTx(dbTx, transactionID)
+	if err != nil {
+		return false, err
+	}
+	if open {
+		return false, errors.New("DISPUTE_OPEN")
+	}
+
+	if transaction.OrderStatus == domain.OrderStatusShipped {
+		err = u.orderStatus.TransitionWithTx(dbTx, transactionID, StatusChange{
+			Field:  domain.StatusFieldOrder,
+			From:   domain.OrderStatusShipped,
+			To:     domain.OrderStatusDelivered,
+			Actor:  actor,
+			Reason: reason,
+		})
+		if err != nil {
+			return false, err
+		}
+	} else if transaction.OrderStatus != domain.OrderStatusDelivered {
+		return false, errors.New("INVALID_STATUS_TRANSITION")
+	}
+
+	err = u.orderStatus.TransitionWithTx(dbTx, transactionID, StatusChange{
+		Field:  domain.StatusFieldPayment,
+		From:   domain.PaymentStatusPaid,
+		To:     domain.PaymentStatusDone,
+		Actor:  actor,
+		Reason: reason,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,407 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"go-commerce/internal/domain"
+	"go-commerce/internal/usecase/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func daysAgo(days int) *time.Time {
+	at := time.Now().AddDate(0, 0, -days)
+	return &at
+}
+
+func TestOrderCompletionUsecase_ConfirmDelivered_CompletesShippedOrder(t *testing.T) {
+	// Setup
+	mockDisputeRepo := new(mocks.MockDisputeRepository)
+	mockStoreRepo := new(mocks.StoreRepositoryMock)
+	mockRefundRepo := new(mocks.MockRefundRepository)
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockTransactionItemRepo := new(mocks.MockTransactionItemRepository)
+	mockCheckoutRepo := new(mocks.MockCheckoutRepository)
+	mockProductLogRepo := new(mocks.MockProductLogRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockHistoryRepo := new(mocks.MockTransactionStatusHistoryRepository)
+	mockPaymentIntentUsecase := new(mocks.MockPaymentIntentUsecase)
+
+	orderStatusUsecase := NewOrderStatusUsecase(mockTransactionRepo, mockHistoryRepo)
+	refundUsecase := NewRefundUsecase(
+		mockRefundRepo,
+		mockTransactionRepo,
+		mockTransactionItemRepo,
+		mockCheckoutRepo,
+		mockProductLogRepo,
+		mockProductRepo,
+		orderStatusUsecase,
+		mockPaymentIntentUsecase,
+	)
+	orderCompletionUsecase := NewOrderCompletionUsecase(
+		mockDisputeRepo,
+		mockTransactionRepo,
+		mockStoreRepo,
+		orderStatusUsecase,
+		refundUsecase,
+		OrderCompletionConfig{AutoConfirmAfter: 7 * 24 * time.Hour},
+	)
+	mockTx := "mock_transaction"
+	transaction := &domain.Transaction{ID: 1, UserID: 3, Status: "paid", OrderStatus: "shipped", ShippedAt: daysAgo(2)}
+
+	// Mock expectations
+	mockTransactionRepo.On("GetByID", uint64(1)).Return(transaction, nil)
+	mockTransactionRepo.On("BeginTx").Return(mockTx, nil)
+	mockTransactionRepo.On("GetByIDWithLock", mockTx, uint64(1)).Return(transaction, nil)
+	mockDisputeRepo.On("HasOpenWithTx", mockTx, uint64(1)).Return(false, nil)
+	mockTransactionRepo.On("TransitionOrderStatusWithTx", mockTx, uint64(1), "shipped", "delivered", mock.AnythingOfType("time.Time")).Return(true, nil)
+	mockTransactionRepo.On("TransitionStatusWithTx", mockTx, uint64(1), "paid", "done", mock.AnythingOfType("time.Time")).Return(true, nil)
+	mockHistoryRepo.On("CreateWithTx", mockTx, mock.AnythingOfType("*domain.TransactionStatusHistory")).Return(nil)
+	mockTransactionRepo.On("CommitTx", mockTx).Return(nil)
+
+	// Execute
+	err := orderCompletionUsecase.ConfirmDelivered(3, 1)
+
+	// Assert
+	assert.NoError(t, err)
+	mockTransactionRepo.AssertExpectations(t)
+	mockHistoryRepo.AssertNumberOfCalls(t, "CreateWithTx", 2)
+}
+
+func TestOrderCompletionUsecase_ConfirmDelivered_DisputeOpen(t *testing.T) {
+	// Setup
+	mockDisputeRepo := new(mocks.MockDisputeRepository)
+	mockStoreRepo := new(mocks.StoreRepositoryMock)
+	mockRefundRepo := new(mocks.MockRefundRepository)
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockTransactionItemRepo := new(mocks.MockTransactionItemRepository)
+	mockCheckoutRepo := new(mocks.MockCheckoutRepository)
+	mockProductLogRepo := new(mocks.MockProductLogRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockHistoryRepo := new(mocks.MockTransactionStatusHistoryRepository)
+	mockPaymentIntentUsecase := new(mocks.MockPaymentIntentUsecase)
+
+	orderStatusUsecase := NewOrderStatusUsecase(mockTransactionRepo, mockHistoryRepo)
+	refundUsecase := NewRefundUsecase(
+		mockRefundRepo,
+		mockTransactionRepo,
+		mockTransactionItemRepo,
+		mockCheckoutRepo,
+		mockProductLogRepo,
+		mockProductRepo,
+		orderStatusUsecase,
+		mockPaymentIntentUsecase,
+	)
+	orderCompletionUsecase := NewOrderCompletionUsecase(
+		mockDisputeRepo,
+		mockTransactionRepo,
+		mockStoreRepo,
+		orderStatusUsecase,
+		refundUsecase,
+		OrderCompletionConfig{AutoConfirmAfter: 7 * 24 * time.Hour},
+	)
+	mockTx := "mock_transaction"
+	transaction := &domain.Transaction{ID: 1, UserID: 3, Status: "paid", OrderStatus: "delivered", ShippedAt: daysAgo(2)}
+
+	// Mock expectations
+	mockTransactionRepo.On("GetByID", uint64(1)).Return(transaction, nil)
+	mockTransactionRepo.On("BeginTx").Return(mockTx, nil)
+	mockTransactionRepo.On("GetByIDWithLock", mockTx, uint64(1)).Return(transaction, nil)
+	mockDisputeRepo.On("HasOpenWithTx", mockTx, uint64(1)).Return(true, nil)
+	mockTransactionRepo.On("RollbackTx", mockTx).Return(nil)
+
+	// Execute
+	err := orderCompletionUsecase.ConfirmDelivered(3, 1)
+
+	// Assert
+	assert.EqualError(t, err, "DISPUTE_OPEN")
+	mockTransactionRepo.AssertNotCalled(t, "TransitionStatusWithTx", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockTransactionRepo.AssertCalled(t, "RollbackTx", mockTx)
+}
+
+func TestOrderCompletionUsecase_AutoConfirmOrders_SkipsRefundedOrders(t *testing.T) {
+	// Setup
+	mockDisputeRepo := new(mocks.MockDisputeRepository)
+	mockStoreRepo := new(mocks.StoreRepositoryMock)
+	mockRefundRepo := new(mocks.MockRefundRepository)
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockTransactionItemRepo := new(mocks.MockTransactionItemRepository)
+	mockCheckoutRepo := new(mocks.MockCheckoutRepository)
+	mockProductLogRepo := new(mocks.MockProductLogRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockHistoryRepo := new(mocks.MockTransactionStatusHistoryRepository)
+	mockPaymentIntentUsecase := new(mocks.MockPaymentIntentUsecase)
+
+	orderStatusUsecase := NewOrderStatusUsecase(mockTransactionRepo, mockHistoryRepo)
+	refundUsecase := NewRefundUsecase(
+		mockRefundRepo,
+		mockTransactionRepo,
+		mockTransactionItemRepo,
+		mockCheckoutRepo,
+		mockProductLogRepo,
+		mockProductRepo,
+		orderStatusUsecase,
+		mockPaymentIntentUsecase,
+	)
+	orderCompletionUsecase := NewOrderCompletionUsecase(
+		mockDisputeRepo,
+		mockTransactionRepo,
+		mockStoreRepo,
+		orderStatusUsecase,
+		refundUsecase,
+		OrderCompletionConfig{AutoConfirmAfter: 7 * 24 * time.Hour},
+	)
+	mockTx := "mock_transaction"
+	due := []*domain.Transaction{
+		{ID: 1, Status: "paid", OrderStatus: "delivered", ShippedAt: daysAgo(8)},
+		{ID: 2, Status: "paid", OrderStatus: "shipped", ShippedAt: daysAgo(9)},
+	}
+
+	// Mock expectations - order 2 was refunded since it was loaded
+	mockTransactionRepo.On("GetDueForCompletion", mock.AnythingOfType("time.Time"), autoConfirmBatch).Return(due, nil).Once()
+	mockTransactionRepo.On("GetDueForCompletion", mock.AnythingOfType("time.Time"), autoConfirmBatch).Return([]*domain.Transaction{}, nil).Once()
+	mockTransactionRepo.On("BeginTx").Return(mockTx, nil)
+	mockTransactionRepo.On("GetByIDWithLock", mockTx, uint64(1)).Return(due[0], nil)
+	mockTransactionRepo.On("GetByIDWithLock", mockTx, uint64(2)).Return(&domain.Transaction{ID: 2, Status: "refunded", OrderStatus: "shipped"}, nil)
+	mockDisputeRepo.On("HasOpenWithTx", mockTx, uint64(1)).Return(false, nil)
+	mockTransactionRepo.On("TransitionStatusWithTx", mockTx, uint64(1), "paid", "done", mock.AnythingOfType("time.Time")).Return(true, nil)
+	mockHistoryRepo.On("CreateWithTx", mockTx, mock.AnythingOfType("*domain.TransactionStatusHistory")).Return(nil)
+	mockTransactionRepo.On("CommitTx", mockTx).Return(nil)
+
+	// Execute
+	completed, err := orderCompletionUsecase.AutoConfirmOrders()
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, completed)
+	mockTransactionRepo.AssertNumberOfCalls(t, "TransitionStatusWithTx", 1)
+	mockTransactionRepo.AssertNotCalled(t, "TransitionOrderStatusWithTx", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestOrderCompletionUsecase_OpenDispute_CreatesDisputeWithPhotos(t *testing.T) {
+	// Setup
+	mockDisputeRepo := new(mocks.MockDisputeRepository)
+	mockStoreRepo := new(mocks.StoreRepositoryMock)
+	mockRefundRepo := new(mocks.MockRefundRepository)
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockTransactionItemRepo := new(mocks.MockTransactionItemRepository)
+	mockCheckoutRepo := new(mocks.MockCheckoutRepository)
+	mockProductLogRepo := new(mocks.MockProductLogRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockHistoryRepo := new(mocks.MockTransactionStatusHistoryRepository)
+	mockPaymentIntentUsecase := new(mocks.MockPaymentIntentUsecase)
+
+	orderStatusUsecase := NewOrderStatusUsecase(mockTransactionRepo, mockHistoryRepo)
+	refundUsecase := NewRefundUsecase(
+		mockRefundRepo,
+		mockTransactionRepo,
+		mockTransactionItemRepo,
+		mockCheckoutRepo,
+		mockProductLogRepo,
+		mockProductRepo,
+		orderStatusUsecase,
+		mockPaymentIntentUsecase,
+	)
+	orderCompletionUsecase := NewOrderCompletionUsecase(
+		mockDisputeRepo,
+		mockTransactionRepo,
+		mockStoreRepo,
+		orderStatusUsecase,
+		refundUsecase,
+		OrderCompletionConfig{AutoConfirmAfter: 7 * 24 * time.Hour},
+	)
+	mockTx := "mock_transaction"
+	req := &domain.OpenDisputeRequest{Reason: "the screen arrived cracked"}
+	photos := []string{"/uploads/disputes/a.jpg", "/uploads/disputes/b.jpg"}
+
+	// Mock expectations
+	mockTransactionRepo.On("BeginTx").Return(mockTx, nil)
+	mockTransactionRepo.On("GetByIDWithLock", mockTx, uint64(1)).Return(&domain.Transaction{ID: 1, UserID: 3, Status: "paid", OrderStatus: "delivered", ShippedAt: daysAgo(3)}, nil)
+	mockDisputeRepo.On("GetByTransactionID", uint64(1)).Return(nil, assert.AnError)
+	mockDisputeRepo.On("CreateWithTx", mockTx, mock.AnythingOfType("*domain.Dispute")).Return(nil)
+	mockTransactionRepo.On("CommitTx", mockTx).Return(nil)
+
+	// Execute
+	dispute, err := orderCompletionUsecase.OpenDispute(3, 1, req, photos)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, domain.DisputeStatusOpen, dispute.Status)
+	assert.Equal(t, uint64(3), dispute.UserID)
+	assert.Len(t, dispute.Photos, 2)
+	assert.Equal(t, "/uploads/disputes/b.jpg", dispute.Photos[1].URL)
+	mockDisputeRepo.AssertExpectations(t)
+}
+
+func TestOrderCompletionUsecase_OpenDispute_WindowClosed(t *testing.T) {
+	// Setup
+	mockDisputeRepo := new(mocks.MockDisputeRepository)
+	mockStoreRepo := new(mocks.StoreRepositoryMock)
+	mockRefundRepo := new(mocks.MockRefundRepository)
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockTransactionItemRepo := new(mocks.MockTransactionItemRepository)
+	mockCheckoutRepo := new(mocks.MockCheckoutRepository)
+	mockProductLogRepo := new(mocks.MockProductLogRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockHistoryRepo := new(mocks.MockTransactionStatusHistoryRepository)
+	mockPaymentIntentUsecase := new(mocks.MockPaymentIntentUsecase)
+
+	orderStatusUsecase := NewOrderStatusUsecase(mockTransactionRepo, mockHistoryRepo)
+	refundUsecase := NewRefundUsecase(
+		mockRefundRepo,
+		mockTransactionRepo,
+		mockTransactionItemRepo,
+		mockCheckoutRepo,
+		mockProductLogRepo,
+		mockProductRepo,
+		orderStatusUsecase,
+		mockPaymentIntentUsecase,
+	)
+	orderCompletionUsecase := NewOrderCompletionUsecase(
+		mockDisputeRepo,
+		mockTransactionRepo,
+		mockStoreRepo,
+		orderStatusUsecase,
+		refundUsecase,
+		OrderCompletionConfig{AutoConfirmAfter: 7 * 24 * time.Hour},
+	)
+	mockTx := "mock_transaction"
+	req := &domain.OpenDisputeRequest{Reason: "the screen arrived cracked"}
+
+	// Mock expectations - shipped 8 days ago with a 7 day window
+	mockTransactionRepo.On("BeginTx").Return(mockTx, nil)
+	mockTransactionRepo.On("GetByIDWithLock", mockTx, uint64(1)).Return(&domain.Transaction{ID: 1, UserID: 3, Status: "paid", OrderStatus: "shipped", ShippedAt: daysAgo(8)}, nil)
+	mockTransactionRepo.On("RollbackTx", mockTx).Return(nil)
+
+	// Execute
+	dispute, err := orderCompletionUsecase.OpenDispute(3, 1, req, nil)
+
+	// Assert
+	assert.EqualError(t, err, "DISPUTE_WINDOW_CLOSED")
+	assert.Nil(t, dispute)
+	mockDisputeRepo.AssertNotCalled(t, "CreateWithTx", mock.Anything, mock.Anything)
+}
+
+func TestOrderCompletionUsecase_ResolveDispute_PartialRefundCompletesOrder(t *testing.T) {
+	// Setup
+	mockDisputeRepo := new(mocks.MockDisputeRepository)
+	mockStoreRepo := new(mocks.StoreRepositoryMock)
+	mockRefundRepo := new(mocks.MockRefundRepository)
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockTransactionItemRepo := new(mocks.MockTransactionItemRepository)
+	mockCheckoutRepo := new(mocks.MockCheckoutRepository)
+	mockProductLogRepo := new(mocks.MockProductLogRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockHistoryRepo := new(mocks.MockTransactionStatusHistoryRepository)
+	mockPaymentIntentUsecase := new(mocks.MockPaymentIntentUsecase)
+
+	orderStatusUsecase := NewOrderStatusUsecase(mockTransactionRepo, mockHistoryRepo)
+	refundUsecase := NewRefundUsecase(
+		mockRefundRepo,
+		mockTransactionRepo,
+		mockTransactionItemRepo,
+		mockCheckoutRepo,
+		mockProductLogRepo,
+		mockProductRepo,
+		orderStatusUsecase,
+		mockPaymentIntentUsecase,
+	)
+	orderCompletionUsecase := NewOrderCompletionUsecase(
+		mockDisputeRepo,
+		mockTransactionRepo,
+		mockStoreRepo,
+		orderStatusUsecase,
+		refundUsecase,
+		OrderCompletionConfig{AutoConfirmAfter: 7 * 24 * time.Hour},
+	)
+	mockTx := "mock_transaction"
+	req := &domain.ResolveDisputeRequest{
+		Resolution: domain.DisputeResolutionPartialRefund,
+		Items:      []domain.RefundItemRequest{{TransactionItemID: 12, Quantity: 1}},
+		Note:       "one item missing",
+	}
+
+	// Mock expectations
+	mockTransactionRepo.On("BeginTx").Return(mockTx, nil)
+	mockDisputeRepo.On("GetByIDWithLock", mockTx, uint64(4)).Return(&domain.Dispute{ID: 4, TransactionID: 1, Status: "open"}, nil)
+	mockTransactionRepo.On("GetByIDWithLock", mockTx, uint64(1)).Return(refundTestTransaction(), nil)
+	mockProductLogRepo.On("GetByID", uint64(80)).Return(&domain.ProductLog{ID: 80, ProductID: 8}, nil)
+	mockTransactionItemRepo.On("AddRefundedQuantityWithTx", mockTx, uint64(12), 1).Return(nil)
+	mockProductRepo.On("UpdateStockWithTx", mockTx, uint64(8), uint64(0), -1).Return(nil)
+	mockProductRepo.On("UpdateSoldCountWithTx", mockTx, uint64(8), -1).Return(nil)
+	mockTransactionRepo.On("AddRefundedAmountWithTx", mockTx, uint64(1), 5000.0).Return(nil)
+	mockRefundRepo.On("CreateWithTx", mockTx, mock.MatchedBy(func(refund *domain.Refund) bool {
+		refund.ID = 33
+		return refund.Status == domain.RefundStatusPending
+	})).Return(nil)
+	mockDisputeRepo.On("ResolveWithTx", mockTx, mock.AnythingOfType("*domain.Dispute")).Return(nil)
+	mockDisputeRepo.On("HasOpenWithTx", mockTx, uint64(1)).Return(false, nil)
+	mockTransactionRepo.On("TransitionStatusWithTx", mockTx, uint64(1), "paid", "done", mock.AnythingOfType("time.Time")).Return(true, nil)
+	mockHistoryRepo.On("CreateWithTx", mockTx, mock.AnythingOfType("*domain.TransactionStatusHistory")).Return(nil)
+	mockTransactionRepo.On("CommitTx", mockTx).Return(nil)
+	mockPaymentIntentUsecase.On("RefundPayment", uint(9), 5000.0, "refund-33").Return("MOCK-1-R3", nil)
+	mockRefundRepo.On("UpdateStatus", uint64(33), domain.RefundStatusCompleted, "MOCK-1-R3").Return(nil)
+
+	// Execute
+	dispute, err := orderCompletionUsecase.ResolveDispute(5, 4, req)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, domain.DisputeStatusPartiallyRefunded, dispute.Status)
+	assert.Equal(t, "one item missing", dispute.ResolutionNote)
+	assert.Equal(t, uint64(5), *dispute.ResolvedBy)
+	assert.NotNil(t, dispute.RefundID)
+	mockTransactionRepo.AssertExpectations(t)
+	mockDisputeRepo.AssertExpectations(t)
+	mockRefundRepo.AssertExpectations(t)
+}
+
+func TestOrderCompletionUsecase_ResolveDispute_AlreadyResolved(t *testing.T) {
+	// Setup
+	mockDisputeRepo := new(mocks.MockDisputeRepository)
+	mockStoreRepo := new(mocks.StoreRepositoryMock)
+	mockRefundRepo := new(mocks.MockRefundRepository)
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockTransactionItemRepo := new(mocks.MockTransactionItemRepository)
+	mockCheckoutRepo := new(mocks.MockCheckoutRepository)
+	mockProductLogRepo := new(mocks.MockProductLogRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockHistoryRepo := new(mocks.MockTransactionStatusHistoryRepository)
+	mockPaymentIntentUsecase := new(mocks.MockPaymentIntentUsecase)
+
+	orderStatusUsecase := NewOrderStatusUsecase(mockTransactionRepo, mockHistoryRepo)
+	refundUsecase := NewRefundUsecase(
+		mockRefundRepo,
+		mockTransactionRepo,
+		mockTransactionItemRepo,
+		mockCheckoutRepo,
+		mockProductLogRepo,
+		mockProductRepo,
+		orderStatusUsecase,
+		mockPaymentIntentUsecase,
+	)
+	orderCompletionUsecase := NewOrderCompletionUsecase(
+		mockDisputeRepo,
+		mockTransactionRepo,
+		mockStoreRepo,
+		orderStatusUsecase,
+		refundUsecase,
+		OrderCompletionConfig{AutoConfirmAfter: 7 * 24 * time.Hour},
+	)
+	mockTx := "mock_transaction"
+
+	// Mock expectations
+	mockTransactionRepo.On("BeginTx").Return(mockTx, nil)
+	mockDisputeRepo.On("GetByIDWithLock", mockTx, uint64(4)).Return(&domain.Dispute{ID: 4, TransactionID: 1, Status: "rejected"}, nil)
+	mockTransactionRepo.On("RollbackTx", mockTx).Return(nil)
+
+	// Execute
+	dispute, err := orderCompletionUsecase.ResolveDispute(5, 4, &domain.ResolveDisputeRequest{Resolution: domain.DisputeResolutionReject})
+
+	// Assert
+	assert.EqualError(t, err, "DISPUTE_ALREADY_RESOLVED")
+	assert.Nil(t, dispute)
+	mockTransactionRepo.AssertNotCalled(t, "GetByIDWithLock", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"errors"
+	"time"
+
+	"go-commerce/internal/domain"
+)
+
+// StatusChange is one transition of the payment or order status of an order.
+// At stamps paid_at, shipped_at or delivered_at and defaults to now.
+type StatusChange struct {
+	Field  string
+	From   string
+	To     string
+	Actor  domain.StatusActor
+	Reason string
+	At     time.Time
+}
+
+// OrderStatusUsecase is the only place where the statuses of an order change.
+// Every change is checked against the state machine, applied only while the
+// order is still in the expected status, and written to the status history
+// in the same database transaction.
+type OrderStatusUsecase struct {
+	transactionRepo domain.TransactionRepository
+	historyRepo     domain.TransactionStatusHistoryRepository
+}
+
+func NewOrderStatusUsecase(
+	transactionRepo domain.TransactionRepository,
+	historyRepo domain.TransactionStatusHistoryRepository,
+) *OrderStatusUsecase {
+	return &OrderStatusUsecase{
+		transactionRepo: transactionRepo,
+		historyRepo:     historyRepo,
+	}
+}
+
+// TransitionWithTx applies the change to the order. It fails with
+// INVALID_STATUS_TRANSITION when the state machine forbids the change or the
+// order left the from status meanwhile.
+func (u *OrderStatusUsecase) TransitionWithTx(dbTx interface{}, transactionID uint64, change StatusChange) error {
+	if !domain.CanTransition(change.Field, change.From, change.To) {
+		return errors.New("INVALID_STATUS_TRANSITION")
+	}
+
+	at := change.At
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	var moved bool
+	var err error
+	if change.Field == domain.StatusFieldOrder {
+		moved, err = u.transactionRepo.TransitionOrderStatusWithTx(dbTx, transactionID, change.From, change.To, at)
+	} else {
+		moved, err = u.transactionRepo.TransitionStatusWithTx(dbTx, transactionID, change.From, change.To, at)
+	}
+	if err != nil {
+		return err
+	}
+	if !moved {
+		return errors.New("INVALID_STATUS_TRANSITION")
+	}
+
+	entry := &domain.TransactionStatusHistory{
+		TransactionID: transactionID,
+		Field:         change.Field,
+		FromStatus:    change.From,
+		ToStatus:      change.To,
+		ActorRole:     change.Actor.Role,
+		Reason:        change.Reason,
+	}
+	if change.Actor.UserID != 0 {
+		actorID := change.Actor.UserID
+		entry.ActorID = &actorID
+	}
+	return u.historyRepo.CreateWithTx(dbTx, entry)
+}
+
+// Transition applies the change in a database transaction of its own
+func (u *OrderStatusUsecase) Transition(transactionID uint64, change StatusChange) error {
+	dbTx, err := u.transactionRepo.BeginTx()
+	if err != nil {
+		return err
+	}
+
+	if err := u.TransitionWithTx(dbTx, transactionID, change); err != nil {
+		u.transactionRepo.RollbackTx(dbTx)
+		return err
+	}
+
+	return u.transactionRepo.CommitTx(dbTx)
+}
+
+// Timeline returns the current statuses of the order with its status history
+func (u *OrderStatusUsecase) Timeline(transaction *domain.Transaction) (*domain.TransactionTimeline, error) {
+	events, err := u.historyRepo.GetByTransactionID(transaction.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.TransactionTimeline{
+		TransactionID: transaction.ID,
+		KodeInvoice:   transaction.KodeInvoice,
+		Status:        transaction.Status,
+		OrderStatus:   transaction.OrderStatus,
+		CreatedAt:     transaction.CreatedAt,
+		PaidAt:        transaction.PaidAt,
+		ShippedAt:     transaction.ShippedAt,
+		DeliveredAt:   transaction.DeliveredAt,
+		CompletedAt:   transaction.CompletedAt,
+		Events:        events,
+	}, nil
+}
@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"go-commerce/internal/domain"
+	"go-commerce/internal/usecase/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestOrderStatusUsecase_TransitionWithTx_RejectsIllegalTransition(t *testing.T) {
+	// Setup
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockHistoryRepo := new(mocks.MockTransactionStatusHistoryRepository)
+	orderStatusUsecase := NewOrderStatusUsecase(mockTransactionRepo, mockHistoryRepo)
+
+	// Execute - an unpaid order cannot be shipped, nor a cancelled one paid
+	errShip := orderStatusUsecase.TransitionWithTx("mock_transaction", 7, StatusChange{
+		Field: domain.StatusFieldOrder,
+		From:  domain.OrderStatusCreated,
+		To:    domain.OrderStatusShipped,
+		Actor: domain.StatusActor{Role: domain.StatusActorSeller, UserID: 2},
+	})
+	errPay := orderStatusUsecase.TransitionWithTx("mock_transaction", 7, StatusChange{
+		Field: domain.StatusFieldPayment,
+		From:  domain.PaymentStatusCancelled,
+		To:    domain.PaymentStatusPaid,
+		Actor: domain.SystemActor,
+	})
+
+	// Assert
+	assert.EqualError(t, errShip, "INVALID_STATUS_TRANSITION")
+	assert.EqualError(t, errPay, "INVALID_STATUS_TRANSITION")
+	mockTransactionRepo.AssertNotCalled(t, "TransitionOrderStatusWithTx", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockTransactionRepo.AssertNotCalled(t, "TransitionStatusWithTx", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockHistoryRepo.AssertNotCalled(t, "CreateWithTx", mock.Anything, mock.Anything)
+}
+
+func TestOrderStatusUsecase_TransitionWithTx_ConcurrentChange(t *testing.T) {
+	// Setup
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockHistoryRepo := new(mocks.MockTransactionStatusHistoryRepository)
+	orderStatusUsecase := NewOrderStatusUsecase(mockTransactionRepo, mockHistoryRepo)
+	mockTx := "mock_transaction"
+
+	// Mock expectations - the order was shipped by another request meanwhile
+	mockTransactionRepo.On("TransitionOrderStatusWithTx", mockTx, uint64(7), "processed", "shipped", mock.AnythingOfType("time.Time")).Return(false, nil)
+
+	// Execute
+	err := orderStatusUsecase.TransitionWithTx(mockTx, 7, StatusChange{
+		Field: domain.StatusFieldOrder,
+		From:  domain.OrderStatusProcessed,
+		To:    domain.OrderStatusShipped,
+		Actor: domain.StatusActor{Role: domain.StatusActorSeller, UserID: 2},
+	})
+
+	// Assert
+	assert.EqualError(t, err, "INVALID_STATUS_TRANSITION")
+	mockHistoryRepo.AssertNotCalled(t, "CreateWithTx", mock.Anything, mock.Anything)
+}
+
+func TestTransactionUsecase_GetTransactionTimeline_Access(t *testing.T) {
+	// Setup
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockStoreRepo := new(mocks.StoreRepositoryMock)
+	mockHistoryRepo := new(mocks.MockTransactionStatusHistoryRepository)
+
+	transactionUsecase := NewTransactionUsecase(
+		mockTransactionRepo,
+		new(mocks.MockCheckoutRepository),
+		new(mocks.MockTransactionItemRepository),
+		new(mocks.MockProductLogRepository),
+		new(mocks.ProductRepositoryMock),
+		new(mocks.MockAddressRepository),
+		new(mocks.MockUserRepository),
+		mockStoreRepo,
+		nil,
+		NewOrderStatusUsecase(mockTransactionRepo, mockHistoryRepo),
+		nil,
+		newTestInvoiceNumberer(),
+		nil,
+	)
+
+	paidAt := time.Date(2026, 10, 17, 9, 30, 0, 0, time.UTC)
+	transaction := &domain.Transaction{ID: 7, UserID: 1, StoreID: 10, Status: "paid", OrderStatus: "created", PaidAt: &paidAt}
+	events := []*domain.TransactionStatusHistory{
+		{ID: 1, TransactionID: 7, Field: domain.StatusFieldPayment, FromStatus: "pending", ToStatus: "paid", ActorRole: domain.StatusActorSystem},
+	}
+
+	// Mock expectations
+	mockTransactionRepo.On("GetByID", uint64(7)).Return(transaction, nil)
+	mockStoreRepo.On("GetByID", uint64(10)).Return(&domain.Store{ID: 10, UserID: 2}, nil)
+	mockHistoryRepo.On("GetByTransactionID", uint64(7)).Return(events, nil)
+
+	// Execute
+	buyerTimeline, errBuyer := transactionUsecase.GetTransactionTimeline(1, 7, false)
+	_, errSeller := transactionUsecase.GetTransactionTimeline(2, 7, false)
+	_, errAdmin := transactionUsecase.GetTransactionTimeline(9, 7, true)
+	_, errStranger := transactionUsecase.GetTransactionTimeline(3, 7, false)
+
+	// Assert
+	assert.NoError(t, errBuyer)
+	assert.Equal(t, &paidAt, buyerTimeline.PaidAt)
+	assert.Len(t, buyerTimeline.Events, 1)
+	assert.NoError(t, errSeller)
+	assert.NoError(t, errAdmin)
+	assert.EqualError(t, errStranger, "forbidden")
+}
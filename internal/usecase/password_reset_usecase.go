@@ -0,0 +1,169 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"go-commerce/internal/domain"
+	"go-commerce/pkg/jwt"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// PasswordResetConfig controls the reset link sent to users
+type PasswordResetConfig struct {
+	ResetURL string
+	TokenTTL time.Duration
+}
+
+type PasswordResetUsecase struct {
+	userRepo          domain.UserRepository
+	passwordResetRepo domain.PasswordResetRepository
+	refreshTokenRepo  domain.RefreshTokenRepository
+	revocationStore   domain.TokenRevocationStore
+	mailer            domain.Mailer
+	jwtManager        *jwt.JWTManager
+	config            PasswordResetConfig
+}
+
+func NewPasswordResetUsecase(
+	userRepo domain.UserRepository,
+	passwordResetRepo domain.PasswordResetRepository,
+	refreshTokenRepo domain.RefreshTokenRepository,
+	revocationStore domain.TokenRevocationStore,
+	mailer domain.Mailer,
+	jwtManager *jwt.JWTManager,
+	config PasswordResetConfig,
+) *PasswordResetUsecase {
+	return &PasswordResetUsecase{
+		userRepo:          userRepo,
+		passwordResetRepo: passwordResetRepo,
+		refreshTokenRepo:  refreshTokenRepo,
+		revocationStore:   revocationStore,
+		mailer:            mailer,
+		jwtManager:        jwtManager,
+		config:            config,
+	}
+}
+
+// ForgotPassword emails a reset link when the address belongs to an account.
+// It reports success either way so callers cannot probe which emails exist;
+// the link is stored and mailed in the background, so known and unknown
+// emails are answered equally fast.
+func (u *PasswordResetUsecase) ForgotPassword(req *domain.ForgotPasswordRequest) error {
+	user, err := u.userRepo.GetByEmail(req.Email)
+	if err != nil {
+		return nil
+	}
+
+	if user.Status == "blocked" {
+		return nil
+	}
+
+	go u.sendResetLink(user)
+
+	return nil
+}
+
+// sendResetLink replaces the user's reset token with a new one and mails its
+// link. It runs in the background, so failures are only logged.
+func (u *PasswordResetUsecase) sendResetLink(user *domain.User) {
+	token, err := generateResetToken()
+	if err != nil {
+		log.Printf("Error generating password reset token: %v", err)
+		return
+	}
+
+	now := time.Now()
+
+	// Only the most recent link stays usable
+	if err := u.passwordResetRepo.InvalidateByUserID(user.ID, now); err != nil {
+		log.Printf("Error invalidating password reset tokens for user %d: %v", user.ID, err)
+		return
+	}
+
+	resetToken := &domain.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: hashResetToken(token),
+		ExpiresAt: now.Add(u.config.TokenTTL),
+	}
+	if err := u.passwordResetRepo.Create(resetToken); err != nil {
+		log.Printf("Error storing password reset token for user %d: %v", user.ID, err)
+		return
+	}
+
+	link := u.config.ResetURL + "?token=" + url.QueryEscape(token)
+	msg := &domain.EmailMessage{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nWe received a request to reset your password. Open the link below to choose a new one:\n\n%s\n\nReset token: %s\n\nThe link expires in %d minute(s) and can only be used once. If you did not request a password reset, you can ignore this email.\n",
+			user.Name, link, token, int(u.config.TokenTTL.Minutes())),
+	}
+	if err := u.mailer.Send(msg); err != nil {
+		log.Printf("Error sending password reset email to user %d: %v", user.ID, err)
+	}
+}
+
+// ResetPassword consumes a reset token, stores the new password and revokes
+// every existing session of the user
+func (u *PasswordResetUsecase) ResetPassword(req *domain.ResetPasswordRequest) error {
+	resetToken, err := u.passwordResetRepo.GetByTokenHash(hashResetToken(req.Token))
+	if err != nil {
+		return errors.New("invalid or expired reset token")
+	}
+
+	now := time.Now()
+	if resetToken.UsedAt != nil || now.After(resetToken.ExpiresAt) {
+		return errors.New("invalid or expired reset token")
+	}
+
+	user, err := u.userRepo.GetByID(resetToken.UserID)
+	if err != nil {
+		return errors.New("invalid or expired reset token")
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return errors.New("failed to hash password")
+	}
+
+	consumed, err := u.passwordResetRepo.MarkUsed(resetToken.ID, now)
+	if err != nil {
+		return errors.New("failed to reset password")
+	}
+	if !consumed {
+		return errors.New("invalid or expired reset token")
+	}
+
+	user.Password = string(hashedPassword)
+	if err := u.userRepo.Update(user); err != nil {
+		return errors.New("failed to reset password")
+	}
+
+	if err := revokeAllSessions(u.revocationStore, u.refreshTokenRepo, u.jwtManager, user.ID); err != nil {
+		return errors.New("password changed but failed to revoke existing sessions")
+	}
+
+	return nil
+}
+
+// generateResetToken returns 32 random bytes encoded for use in URLs
+func generateResetToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,178 @@
+package usecase
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go-commerce/internal/domain"
+	"go-commerce/internal/repository/memory"
+	"go-commerce/internal/usecase/mocks"
+	"go-commerce/pkg/jwt"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func newTestPasswordResetUsecase(userRepo domain.UserRepository, passwordResetRepo domain.PasswordResetRepository, refreshTokenRepo domain.RefreshTokenRepository, revocationStore domain.TokenRevocationStore, mailer domain.Mailer) *PasswordResetUsecase {
+	jwtManager := jwt.NewJWTManager("test-secret", 24, 168)
+	return NewPasswordResetUsecase(userRepo, passwordResetRepo, refreshTokenRepo, revocationStore, mailer, jwtManager, PasswordResetConfig{
+		ResetURL: "http://localhost/reset-password",
+		TokenTTL: 30 * time.Minute,
+	})
+}
+
+// extractToken returns the value following "Reset token: " in an email body
+func extractToken(body string) string {
+	const marker = "Reset token: "
+	rest := body[strings.Index(body, marker)+len(marker):]
+	return strings.Fields(rest)[0]
+}
+
+func TestPasswordResetUsecase_ForgotPassword_SendsHashedSingleUseToken(t *testing.T) {
+	// Setup
+	mockUserRepo := new(mocks.MockUserRepository)
+	mockPasswordResetRepo := new(mocks.MockPasswordResetRepository)
+	mockMailer := new(mocks.MockMailer)
+	passwordResetUsecase := newTestPasswordResetUsecase(mockUserRepo, mockPasswordResetRepo, nil, nil, mockMailer)
+
+	user := &domain.User{ID: 1, Name: "Test User", Email: "test@example.com", Status: "active"}
+
+	var stored *domain.PasswordResetToken
+	var sent *domain.EmailMessage
+	mailed := make(chan struct{})
+
+	// Mock expectations
+	mockUserRepo.On("GetByEmail", "test@example.com").Return(user, nil)
+	mockPasswordResetRepo.On("InvalidateByUserID", uint64(1), mock.AnythingOfType("time.Time")).Return(nil)
+	mockPasswordResetRepo.On("Create", mock.AnythingOfType("*domain.PasswordResetToken")).Run(func(args mock.Arguments) {
+		stored = args.Get(0).(*domain.PasswordResetToken)
+	}).Return(nil)
+	mockMailer.On("Send", mock.AnythingOfType("*domain.EmailMessage")).Run(func(args mock.Arguments) {
+		sent = args.Get(0).(*domain.EmailMessage)
+		close(mailed)
+	}).Return(nil)
+
+	// Execute
+	err := passwordResetUsecase.ForgotPassword(&domain.ForgotPasswordRequest{Email: "test@example.com"})
+
+	// Assert
+	assert.NoError(t, err)
+
+	// The link is mailed in the background
+	select {
+	case <-mailed:
+	case <-time.After(time.Second):
+		t.Fatal("reset link was not mailed")
+	}
+	assert.NotNil(t, sent)
+	assert.Equal(t, "test@example.com", sent.To)
+
+	// Only the hash of the emailed token is stored
+	token := extractToken(sent.Body)
+	assert.NotEqual(t, token, stored.TokenHash)
+	assert.Equal(t, hashResetToken(token), stored.TokenHash)
+	assert.True(t, stored.ExpiresAt.After(time.Now()))
+
+	mockUserRepo.AssertExpectations(t)
+	mockPasswordResetRepo.AssertExpectations(t)
+	mockMailer.AssertExpectations(t)
+}
+
+func TestPasswordResetUsecase_ForgotPassword_UnknownEmailDoesNotReveal(t *testing.T) {
+	// Setup
+	mockUserRepo := new(mocks.MockUserRepository)
+	mockPasswordResetRepo := new(mocks.MockPasswordResetRepository)
+	mockMailer := new(mocks.MockMailer)
+	passwordResetUsecase := newTestPasswordResetUsecase(mockUserRepo, mockPasswordResetRepo, nil, nil, mockMailer)
+
+	// Mock expectations
+	mockUserRepo.On("GetByEmail", "unknown@example.com").Return(nil, errors.New("record not found"))
+
+	// Execute
+	err := passwordResetUsecase.ForgotPassword(&domain.ForgotPasswordRequest{Email: "unknown@example.com"})
+
+	// Assert
+	assert.NoError(t, err)
+	mockPasswordResetRepo.AssertNotCalled(t, "Create", mock.Anything)
+	mockMailer.AssertNotCalled(t, "Send", mock.Anything)
+}
+
+func TestPasswordResetUsecase_ResetPassword_Success(t *testing.T) {
+	// Setup
+	mockUserRepo := new(mocks.MockUserRepository)
+	mockPasswordResetRepo := new(mocks.MockPasswordResetRepository)
+	mockRefreshTokenRepo := new(mocks.MockRefreshTokenRepository)
+	revocationStore := memory.NewTokenRevocationStore()
+	passwordResetUsecase := newTestPasswordResetUsecase(mockUserRepo, mockPasswordResetRepo, mockRefreshTokenRepo, revocationStore, nil)
+
+	token := "reset-token"
+	resetToken := &domain.PasswordResetToken{ID: 5, UserID: 1, TokenHash: hashResetToken(token), ExpiresAt: time.Now().Add(time.Minute)}
+	user := &domain.User{ID: 1, Email: "test@example.com", Password: "old-hash"}
+	issuedAt := time.Now().Add(-time.Minute)
+
+	// Mock expectations
+	mockPasswordResetRepo.On("GetByTokenHash", hashResetToken(token)).Return(resetToken, nil)
+	mockUserRepo.On("GetByID", uint64(1)).Return(user, nil)
+	mockPasswordResetRepo.On("MarkUsed", uint64(5), mock.AnythingOfType("time.Time")).Return(true, nil)
+	mockUserRepo.On("Update", mock.AnythingOfType("*domain.User")).Return(nil)
+	mockRefreshTokenRepo.On("RevokeByUserID", uint64(1)).Return(nil)
+
+	// Execute
+	err := passwordResetUsecase.ResetPassword(&domain.ResetPasswordRequest{Token: token, NewPassword: "newpassword"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(user.Password), []byte("newpassword")))
+
+	// Access tokens issued before the reset are revoked
+	revoked, err := revocationStore.IsRevoked("old-access-token", 1, issuedAt)
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+
+	mockUserRepo.AssertExpectations(t)
+	mockPasswordResetRepo.AssertExpectations(t)
+	mockRefreshTokenRepo.AssertExpectations(t)
+}
+
+func TestPasswordResetUsecase_ResetPassword_TokenAlreadyUsed(t *testing.T) {
+	// Setup
+	mockUserRepo := new(mocks.MockUserRepository)
+	mockPasswordResetRepo := new(mocks.MockPasswordResetRepository)
+	passwordResetUsecase := newTestPasswordResetUsecase(mockUserRepo, mockPasswordResetRepo, nil, nil, nil)
+
+	usedAt := time.Now().Add(-time.Minute)
+	resetToken := &domain.PasswordResetToken{ID: 5, UserID: 1, ExpiresAt: time.Now().Add(time.Minute), UsedAt: &usedAt}
+
+	// Mock expectations
+	mockPasswordResetRepo.On("GetByTokenHash", hashResetToken("reset-token")).Return(resetToken, nil)
+
+	// Execute
+	err := passwordResetUsecase.ResetPassword(&domain.ResetPasswordRequest{Token: "reset-token", NewPassword: "newpassword"})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Equal(t, "invalid or expired reset token", err.Error())
+	mockUserRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestPasswordResetUsecase_ResetPassword_ExpiredToken(t *testing.T) {
+	// Setup
+	mockUserRepo := new(mocks.MockUserRepository)
+	mockPasswordResetRepo := new(mocks.MockPasswordResetRepository)
+	passwordResetUsecase := newTestPasswordResetUsecase(mockUserRepo, mockPasswordResetRepo, nil, nil, nil)
+
+	resetToken := &domain.PasswordResetToken{ID: 5, UserID: 1, ExpiresAt: time.Now().Add(-time.Minute)}
+
+	// Mock expectations
+	mockPasswordResetRepo.On("GetByTokenHash", hashResetToken("reset-token")).Return(resetToken, nil)
+
+	// Execute
+	err := passwordResetUsecase.ResetPassword(&domain.ResetPasswordRequest{Token: "reset-token", NewPassword: "newpassword"})
+
+	// Assert
+	assert.Error(t, err)
+	mockPasswordResetRepo.AssertNotCalled(t, "MarkUsed", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,157 @@
+package usecase
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"go-commerce/internal/domain"
+	"go-commerce/pkg/webhook"
+)
+
+// PaymentCallbackConfig holds the shared secret of every payment gateway and
+// how far a callback's timestamp may drift from the server clock
+type PaymentCallbackConfig struct {
+	GatewaySecrets map[string]string
+	Tolerance      time.Duration
+}
+
+// PaymentCallbackUsecase verifies gateway callbacks before they can settle a
+// payment intent and keeps an audit log of every callback
+type PaymentCallbackUsecase struct {
+	paymentEventRepo  domain.PaymentEventRepository
+	paymentIntentRepo domain.PaymentIntentRepository
+	paymentIntentUC   domain.PaymentIntentUsecase
+	providers         *PaymentProviders
+	config            PaymentCallbackConfig
+}
+
+func NewPaymentCallbackUsecase(
+	paymentEventRepo domain.PaymentEventRepository,
+	paymentIntentRepo domain.PaymentIntentRepository,
+	paymentIntentUC domain.PaymentIntentUsecase,
+	providers *PaymentProviders,
+	config PaymentCallbackConfig,
+) *PaymentCallbackUsecase {
+	return &PaymentCallbackUsecase{
+		paymentEventRepo:  paymentEventRepo,
+		paymentIntentRepo: paymentIntentRepo,
+		paymentIntentUC:   paymentIntentUC,
+		providers:         providers,
+		config:            config,
+	}
+}
+
+// HandleCallback verifies the signature of a callback and applies it to the
+// payment intent. The intent ID of the URL is not signed, so the callback
+// must carry the reference of the charge the gateway issued for that intent.
+// Each gateway reference is applied once; replays report duplicate and change
+// nothing.
+func (u *PaymentCallbackUsecase) HandleCallback(callback *domain.PaymentCallback) (bool, error) {
+	intentID := uint64(callback.IntentID)
+	event := &domain.PaymentEvent{
+		PaymentIntentID: &intentID,
+		Gateway:         callback.Gateway,
+		Payload:         string(callback.Body),
+		RemoteIP:        callback.RemoteIP,
+	}
+
+	provider, ok := u.providers.ByName(callback.Gateway)
+	if !ok {
+		return false, u.reject(event, errors.New("UNKNOWN_GATEWAY"))
+	}
+	secret, ok := u.config.GatewaySecrets[callback.Gateway]
+	if !ok {
+		return false, u.reject(event, errors.New("UNKNOWN_GATEWAY"))
+	}
+
+	err := webhook.Verify(secret, callback.Timestamp, callback.Signature, callback.Body, time.Now(), u.config.Tolerance)
+	if err != nil {
+		return false, u.reject(event, err)
+	}
+
+	// Only a verified body is parsed, by the provider that knows its format
+	payment, err := provider.ParseWebhook(callback.Body)
+	if err != nil {
+		return false, u.reject(event, err)
+	}
+	if payment.Status != "success" && payment.Status != "failed" {
+		return false, u.reject(event, errors.New("invalid payment status"))
+	}
+	if payment.GatewayRef == "" {
+		return false, u.reject(event, errors.New("gateway_ref is required"))
+	}
+	event.Status = payment.Status
+	event.GatewayRef = &payment.GatewayRef
+
+	// A callback signed for one charge must not settle another intent
+	intent, err := u.paymentIntentRepo.GetByID(callback.IntentID)
+	if err != nil {
+		return false, u.reject(event, errors.New("payment intent not found"))
+	}
+	if intent.Provider != callback.Gateway || intent.ProviderRef != payment.GatewayRef {
+		return false, u.reject(event, errors.New("GATEWAY_REF_MISMATCH"))
+	}
+
+	paidAt := time.Now()
+	if payment.PaidAt != nil {
+		paidAt = *payment.PaidAt
+	}
+	if payment.Status == "success" {
+		event.PaidAt = &paidAt
+	}
+
+	// Claim the gateway reference; a replay finds it taken
+	event.ClaimedRef = &payment.GatewayRef
+	event.Outcome = domain.PaymentEventReceived
+	claimed, err := u.paymentEventRepo.Claim(event)
+	if err != nil {
+		return false, err
+	}
+	if !claimed {
+		event.ID = 0
+		event.ClaimedRef = nil
+		event.Outcome = domain.PaymentEventDuplicate
+		u.record(event)
+		return true, nil
+	}
+
+	switch payment.Status {
+	case "success":
+		err = u.paymentIntentUC.ProcessPaymentSuccess(callback.IntentID, paidAt)
+	case "failed":
+		err = u.paymentIntentUC.ProcessPaymentFailed(callback.IntentID)
+	}
+	if err != nil {
+		// Free the reference so the gateway's retry is not taken for a replay
+		if releaseErr := u.paymentEventRepo.ReleaseClaim(event.ID, truncate(err.Error(), 255)); releaseErr != nil {
+			log.Printf("Error releasing payment event %d: %v", event.ID, releaseErr)
+		}
+		return false, err
+	}
+
+	if err := u.paymentEventRepo.UpdateOutcome(event.ID, domain.PaymentEventProcessed, ""); err != nil {
+		log.Printf("Error updating payment event %d: %v", event.ID, err)
+	}
+	return false, nil
+}
+
+func (u *PaymentCallbackUsecase) reject(event *domain.PaymentEvent, err error) error {
+	event.Outcome = domain.PaymentEventRejected
+	event.Message = truncate(err.Error(), 255)
+	u.record(event)
+	return err
+}
+
+func (u *PaymentCallbackUsecase) record(event *domain.PaymentEvent) {
+	if err := u.paymentEventRepo.Create(event); err != nil {
+		log.Printf("Error recording payment event from %s: %v", event.Gateway, err)
+	}
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max]
+}
@@ -0,0 +1,192 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"go-commerce/internal/domain"
+	"go-commerce/internal/usecase/mocks"
+	"go-commerce/pkg/webhook"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// newJSONCallbackProviders registers a provider that decodes the documented
+// JSON callback body
+func newJSONCallbackProviders() *PaymentProviders {
+	provider := new(mocks.MockPaymentProvider)
+	provider.On("Name").Return("midtrans")
+	provider.On("ParseWebhook", mock.Anything).Return(func(body []byte) *domain.PaymentWebhook {
+		var req domain.PaymentCallbackRequest
+		json.Unmarshal(body, &req)
+		payment := &domain.PaymentWebhook{Status: req.Status, GatewayRef: req.GatewayRef}
+		if paidAt, err := time.Parse(time.RFC3339, req.PaidAt); err == nil {
+			payment.PaidAt = &paidAt
+		}
+		return payment
+	}, nil)
+	providers := NewPaymentProviders()
+	providers.Register(provider, "transfer")
+	return providers
+}
+
+func signedCallback(secret string, body string) *domain.PaymentCallback {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	return &domain.PaymentCallback{
+		IntentID:  11,
+		Gateway:   "midtrans",
+		Timestamp: timestamp,
+		Signature: webhook.Sign(secret, timestamp, []byte(body)),
+		Body:      []byte(body),
+	}
+}
+
+func TestPaymentCallbackUsecase_HandleCallback_ProcessesSignedSuccess(t *testing.T) {
+	// Setup
+	mockEventRepo := new(mocks.MockPaymentEventRepository)
+	mockPaymentIntentRepo := new(mocks.MockPaymentIntentRepository)
+	mockIntentUsecase := new(mocks.MockPaymentIntentUsecase)
+	callbackUsecase := NewPaymentCallbackUsecase(mockEventRepo, mockPaymentIntentRepo, mockIntentUsecase, newJSONCallbackProviders(), PaymentCallbackConfig{
+		GatewaySecrets: map[string]string{"midtrans": "gateway-secret"},
+		Tolerance:      5 * time.Minute,
+	})
+	callback := signedCallback("gateway-secret", `{"status":"success","gateway_ref":"PAY-1","paid_at":"2026-10-17T09:30:00Z"}`)
+	paidAt := time.Date(2026, 10, 17, 9, 30, 0, 0, time.UTC)
+
+	// Mock expectations
+	mockPaymentIntentRepo.On("GetByID", uint(11)).Return(&domain.PaymentIntent{ID: 11, Provider: "midtrans", ProviderRef: "PAY-1"}, nil)
+	mockEventRepo.On("Claim", mock.MatchedBy(func(event *domain.PaymentEvent) bool {
+		event.ID = 90
+		return *event.ClaimedRef == "PAY-1" && event.PaidAt.Equal(paidAt)
+	})).Return(true, nil)
+	mockIntentUsecase.On("ProcessPaymentSuccess", uint(11), paidAt).Return(nil)
+	mockEventRepo.On("UpdateOutcome", uint64(90), domain.PaymentEventProcessed, "").Return(nil)
+
+	// Execute
+	duplicate, err := callbackUsecase.HandleCallback(callback)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.False(t, duplicate)
+	mockEventRepo.AssertExpectations(t)
+	mockIntentUsecase.AssertExpectations(t)
+}
+
+func TestPaymentCallbackUsecase_HandleCallback_IgnoresReplayedRef(t *testing.T) {
+	// Setup
+	mockEventRepo := new(mocks.MockPaymentEventRepository)
+	mockPaymentIntentRepo := new(mocks.MockPaymentIntentRepository)
+	mockIntentUsecase := new(mocks.MockPaymentIntentUsecase)
+	callbackUsecase := NewPaymentCallbackUsecase(mockEventRepo, mockPaymentIntentRepo, mockIntentUsecase, newJSONCallbackProviders(), PaymentCallbackConfig{
+		GatewaySecrets: map[string]string{"midtrans": "gateway-secret"},
+		Tolerance:      5 * time.Minute,
+	})
+	callback := signedCallback("gateway-secret", `{"status":"success","gateway_ref":"PAY-1"}`)
+
+	// Mock expectations
+	mockPaymentIntentRepo.On("GetByID", uint(11)).Return(&domain.PaymentIntent{ID: 11, Provider: "midtrans", ProviderRef: "PAY-1"}, nil)
+	mockEventRepo.On("Claim", mock.AnythingOfType("*domain.PaymentEvent")).Return(false, nil)
+	mockEventRepo.On("Create", mock.MatchedBy(func(event *domain.PaymentEvent) bool {
+		return event.Outcome == domain.PaymentEventDuplicate && event.ClaimedRef == nil
+	})).Return(nil)
+
+	// Execute
+	duplicate, err := callbackUsecase.HandleCallback(callback)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, duplicate)
+	mockEventRepo.AssertExpectations(t)
+	mockIntentUsecase.AssertNotCalled(t, "ProcessPaymentSuccess", mock.Anything, mock.Anything)
+}
+
+func TestPaymentCallbackUsecase_HandleCallback_RejectsForgedSignature(t *testing.T) {
+	// Setup
+	mockEventRepo := new(mocks.MockPaymentEventRepository)
+	mockPaymentIntentRepo := new(mocks.MockPaymentIntentRepository)
+	mockIntentUsecase := new(mocks.MockPaymentIntentUsecase)
+	callbackUsecase := NewPaymentCallbackUsecase(mockEventRepo, mockPaymentIntentRepo, mockIntentUsecase, newJSONCallbackProviders(), PaymentCallbackConfig{
+		GatewaySecrets: map[string]string{"midtrans": "gateway-secret"},
+		Tolerance:      5 * time.Minute,
+	})
+	callback := signedCallback("guessed-secret", `{"status":"success","gateway_ref":"PAY-1"}`)
+
+	// Mock expectations
+	mockEventRepo.On("Create", mock.MatchedBy(func(event *domain.PaymentEvent) bool {
+		return event.Outcome == domain.PaymentEventRejected && event.Message == "INVALID_SIGNATURE"
+	})).Return(nil)
+
+	// Execute
+	duplicate, err := callbackUsecase.HandleCallback(callback)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Equal(t, "INVALID_SIGNATURE", err.Error())
+	assert.False(t, duplicate)
+	mockEventRepo.AssertExpectations(t)
+	mockEventRepo.AssertNotCalled(t, "Claim", mock.Anything)
+	mockIntentUsecase.AssertNotCalled(t, "ProcessPaymentSuccess", mock.Anything, mock.Anything)
+	mockPaymentIntentRepo.AssertNotCalled(t, "GetByID", mock.Anything)
+}
+
+func TestPaymentCallbackUsecase_HandleCallback_RejectsRefOfOtherIntent(t *testing.T) {
+	// Setup
+	mockEventRepo := new(mocks.MockPaymentEventRepository)
+	mockPaymentIntentRepo := new(mocks.MockPaymentIntentRepository)
+	mockIntentUsecase := new(mocks.MockPaymentIntentUsecase)
+	callbackUsecase := NewPaymentCallbackUsecase(mockEventRepo, mockPaymentIntentRepo, mockIntentUsecase, newJSONCallbackProviders(), PaymentCallbackConfig{
+		GatewaySecrets: map[string]string{"midtrans": "gateway-secret"},
+		Tolerance:      5 * time.Minute,
+	})
+	// A genuine callback for the charge of another intent, posted to intent 11
+	callback := signedCallback("gateway-secret", `{"status":"success","gateway_ref":"PAY-9"}`)
+
+	// Mock expectations
+	mockPaymentIntentRepo.On("GetByID", uint(11)).Return(&domain.PaymentIntent{ID: 11, Provider: "midtrans", ProviderRef: "PAY-1"}, nil)
+	mockEventRepo.On("Create", mock.MatchedBy(func(event *domain.PaymentEvent) bool {
+		return event.Outcome == domain.PaymentEventRejected && event.Message == "GATEWAY_REF_MISMATCH"
+	})).Return(nil)
+
+	// Execute
+	duplicate, err := callbackUsecase.HandleCallback(callback)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Equal(t, "GATEWAY_REF_MISMATCH", err.Error())
+	assert.False(t, duplicate)
+	mockEventRepo.AssertExpectations(t)
+	mockEventRepo.AssertNotCalled(t, "Claim", mock.Anything)
+	mockIntentUsecase.AssertNotCalled(t, "ProcessPaymentSuccess", mock.Anything, mock.Anything)
+}
+
+func TestPaymentCallbackUsecase_HandleCallback_ReleasesRefWhenProcessingFails(t *testing.T) {
+	// Setup
+	mockEventRepo := new(mocks.MockPaymentEventRepository)
+	mockPaymentIntentRepo := new(mocks.MockPaymentIntentRepository)
+	mockIntentUsecase := new(mocks.MockPaymentIntentUsecase)
+	callbackUsecase := NewPaymentCallbackUsecase(mockEventRepo, mockPaymentIntentRepo, mockIntentUsecase, newJSONCallbackProviders(), PaymentCallbackConfig{
+		GatewaySecrets: map[string]string{"midtrans": "gateway-secret"},
+		Tolerance:      5 * time.Minute,
+	})
+	callback := signedCallback("gateway-secret", `{"status":"failed","gateway_ref":"PAY-2"}`)
+
+	// Mock expectations
+	mockPaymentIntentRepo.On("GetByID", uint(11)).Return(&domain.PaymentIntent{ID: 11, Provider: "midtrans", ProviderRef: "PAY-2"}, nil)
+	mockEventRepo.On("Claim", mock.MatchedBy(func(event *domain.PaymentEvent) bool {
+		event.ID = 91
+		return true
+	})).Return(true, nil)
+	mockIntentUsecase.On("ProcessPaymentFailed", uint(11)).Return(errors.New("payment intent not found"))
+	mockEventRepo.On("ReleaseClaim", uint64(91), "payment intent not found").Return(nil)
+
+	// Execute
+	_, err := callbackUsecase.HandleCallback(callback)
+
+	// Assert
+	assert.Error(t, err)
+	mockEventRepo.AssertExpectations(t)
+}
@@ -2,62 +2,128 @@ package usecase
 
 import (
 	"errors"
+	"fmt"
+	"log"
 	"go-commerce/internal/domain"
 	"time"
 )
 
+// PaymentIntentConfig controls how long an intent can be paid
+type PaymentIntentConfig struct {
+	TTL time.Duration
+}
+
+// expirySweepLimit caps how many intents one sweep expires
+const expirySweepLimit = 100
+
 type paymentIntentUsecase struct {
 	paymentIntentRepo domain.PaymentIntentRepository
+	checkoutRepo      domain.CheckoutRepository
 	transactionRepo   domain.TransactionRepository
 	transactionUC     *TransactionUsecase
+	providers         *PaymentProviders
+	config            PaymentIntentConfig
 }
 
 func NewPaymentIntentUsecase(
 	paymentIntentRepo domain.PaymentIntentRepository,
+	checkoutRepo domain.CheckoutRepository,
 	transactionRepo domain.TransactionRepository,
 	transactionUC *TransactionUsecase,
+	providers *PaymentProviders,
+	config PaymentIntentConfig,
 ) domain.PaymentIntentUsecase {
 	return &paymentIntentUsecase{
 		paymentIntentRepo: paymentIntentRepo,
+		checkoutRepo:      checkoutRepo,
 		transactionRepo:   transactionRepo,
 		transactionUC:     transactionUC,
+		providers:         providers,
+		config:            config,
 	}
 }
 
-func (uc *paymentIntentUsecase) CreatePaymentIntent(trxID uint, method string) (*domain.PaymentIntent, error) {
-	// Check if transaction exists and is pending
-	trx, err := uc.transactionRepo.GetByID(uint64(trxID))
+// CreatePaymentIntent starts the payment of a checkout. One intent pays for
+// every order in the checkout; the provider of the method issues the charge.
+func (uc *paymentIntentUsecase) CreatePaymentIntent(checkoutID uint, method string) (*domain.PaymentIntent, error) {
+	provider, ok := uc.providers.ForMethod(method)
+	if !ok {
+		return nil, errors.New("PAYMENT_METHOD_NOT_SUPPORTED")
+	}
+
+	// Check if checkout exists and is pending
+	checkout, err := uc.checkoutRepo.GetByID(uint64(checkoutID))
 	if err != nil {
-		return nil, errors.New("transaction not found")
+		return nil, errors.New("checkout not found")
 	}
 	
-	if trx.Status != "pending" {
-		return nil, errors.New("transaction is not in pending status")
+	if checkout.Status != "pending" {
+		return nil, errors.New("checkout is not in pending status")
 	}
 	
 	// Check if payment intent already exists
-	existing, _ := uc.paymentIntentRepo.GetByTrxID(trxID)
-	if existing != nil {
+	existing, _ := uc.paymentIntentRepo.GetByCheckoutID(checkoutID)
+	if existing != nil && existing.Status == domain.PaymentIntentStatusPending {
 		return existing, nil // Return existing intent (idempotent)
 	}
 	
-	// Create new payment intent with 30 minutes expiry
+	// Create new payment intent that expires after the configured TTL
 	intent := &domain.PaymentIntent{
-		TrxID:     trxID,
-		Method:    method,
-		Status:    domain.PaymentIntentStatusPending,
-		ExpiredAt: time.Now().Add(30 * time.Minute),
+		CheckoutID: checkoutID,
+		Method:     method,
+		Status:     domain.PaymentIntentStatusPending,
+		Amount:     checkout.HargaTotal,
+		ExpiredAt:  time.Now().Add(uc.config.TTL),
 	}
 	
 	err = uc.paymentIntentRepo.Create(intent)
 	if err != nil {
 		return nil, err
 	}
+
+	charge, err := provider.CreateCharge(&domain.ChargeRequest{
+		IntentID:  intent.ID,
+		Method:    method,
+		Amount:    intent.Amount,
+		ExpiresAt: intent.ExpiredAt,
+	})
+	if err != nil {
+		// Nothing can pay this intent, so let the buyer try again
+		log.Printf("Error creating %s charge for payment intent %d: %v", provider.Name(), intent.ID, err)
+		uc.paymentIntentRepo.UpdateStatus(intent.ID, domain.PaymentIntentStatusFailed)
+		return nil, errors.New("PAYMENT_PROVIDER_UNAVAILABLE")
+	}
+
+	err = uc.paymentIntentRepo.AttachCharge(intent.ID, charge)
+	if err != nil {
+		return nil, err
+	}
+	intent.Provider = charge.Provider
+	intent.ProviderRef = charge.Reference
+	intent.VirtualAccount = charge.VirtualAccount
+	intent.QRPayload = charge.QRPayload
+	intent.PaymentURL = charge.PaymentURL
+
+	// Keep the stock held for as long as the intent can be paid
+	if err := uc.transactionUC.ExtendStockHold(uint64(checkoutID), intent.ExpiredAt); err != nil {
+		log.Printf("Error extending stock hold of checkout %d: %v", checkoutID, err)
+	}
 	
 	return intent, nil
 }
 
-func (uc *paymentIntentUsecase) ProcessPaymentSuccess(intentID uint) error {
+// CreatePaymentIntentForOrder pays the checkout the order belongs to, for
+// clients that still pay per order
+func (uc *paymentIntentUsecase) CreatePaymentIntentForOrder(transactionID uint64, method string) (*domain.PaymentIntent, error) {
+	transaction, err := uc.transactionRepo.GetByID(transactionID)
+	if err != nil {
+		return nil, errors.New("transaction not found")
+	}
+
+	return uc.CreatePaymentIntent(uint(transaction.CheckoutID), method)
+}
+
+func (uc *paymentIntentUsecase) ProcessPaymentSuccess(intentID uint, paidAt time.Time) error {
 	intent, err := uc.paymentIntentRepo.GetByID(intentID)
 	if err != nil {
 		return errors.New("payment intent not found")
@@ -65,25 +131,118 @@ func (uc *paymentIntentUsecase) ProcessPaymentSuccess(intentID uint) error {
 	
 	// Decision table logic
 	switch {
-	case intent.Status == domain.PaymentIntentStatusSuccess && intent.Transaction.Status == "paid":
+	case intent.Status == domain.PaymentIntentStatusSuccess && intent.Checkout.Status == "paid":
 		// Idempotent - already processed
 		return nil
 		
-	case intent.Status == domain.PaymentIntentStatusPending && intent.Transaction.Status == "pending":
-		// Process payment success
-		err = uc.paymentIntentRepo.UpdateStatus(intentID, domain.PaymentIntentStatusSuccess)
-		if err != nil {
-			return err
-		}
-		
-		// Update transaction to paid
-		return uc.transactionUC.OnPaymentPaid(uint64(intent.TrxID))
-		
+	case intent.Status == domain.PaymentIntentStatusPending && intent.Checkout.Status == "pending":
+		return uc.settlePaidIntent(intent, paidAt)
+
+	case intent.Status == domain.PaymentIntentStatusRefunded || intent.Status == domain.PaymentIntentStatusNeedsRefund:
+		// Idempotent - a late payment already sent back
+		return nil
+
+	case (intent.Status == domain.PaymentIntentStatusPending || intent.Status == domain.PaymentIntentStatusExpired) &&
+		(intent.Checkout.Status == "cancelled" || intent.Checkout.Status == "failed"):
+		// The buyer paid a charge the shop no longer wants, e.g. into the
+		// virtual account of a cancelled checkout
+		return uc.refundLatePayment(intent)
+
 	default:
 		return errors.New("invalid payment intent state for success processing")
 	}
 }
 
+// settlePaidIntent marks the intent, its checkout and the orders paid in one
+// database transaction, unless the expiry sweep got to the intent first
+func (uc *paymentIntentUsecase) settlePaidIntent(intent *domain.PaymentIntent, paidAt time.Time) error {
+	dbTx, err := uc.transactionRepo.BeginTx()
+	if err != nil {
+		return err
+	}
+
+	settled, err := uc.paymentIntentRepo.TransitionStatusWithTx(dbTx, intent.ID, domain.PaymentIntentStatusPending, domain.PaymentIntentStatusSuccess)
+	if err != nil {
+		uc.transactionRepo.RollbackTx(dbTx)
+		return err
+	}
+	if !settled {
+		uc.transactionRepo.RollbackTx(dbTx)
+		return errors.New("invalid payment intent state for success processing")
+	}
+
+	err = uc.transactionUC.MarkCheckoutPaidWithTx(dbTx, uint64(intent.CheckoutID), paidAt)
+	if err != nil {
+		uc.transactionRepo.RollbackTx(dbTx)
+		if err.Error() == "STOCK_NOT_AVAILABLE" {
+			// The holds expired and the stock was sold meanwhile
+			return uc.settleWithoutStock(intent)
+		}
+		return err
+	}
+
+	return uc.transactionRepo.CommitTx(dbTx)
+}
+
+// settleWithoutStock records the payment of an intent whose checkout can no
+// longer be fulfilled, fails the checkout and refunds the buyer. An intent
+// its provider could not refund is left as needs_refund.
+func (uc *paymentIntentUsecase) settleWithoutStock(intent *domain.PaymentIntent) error {
+	dbTx, err := uc.transactionRepo.BeginTx()
+	if err != nil {
+		return err
+	}
+
+	settled, err := uc.paymentIntentRepo.TransitionStatusWithTx(dbTx, intent.ID, domain.PaymentIntentStatusPending, domain.PaymentIntentStatusSuccess)
+	if err != nil {
+		uc.transactionRepo.RollbackTx(dbTx)
+		return err
+	}
+	if !settled {
+		uc.transactionRepo.RollbackTx(dbTx)
+		return errors.New("invalid payment intent state for success processing")
+	}
+
+	err = uc.transactionUC.FailCheckoutWithTx(dbTx, uint64(intent.CheckoutID), "stock no longer available")
+	if err != nil {
+		uc.transactionRepo.RollbackTx(dbTx)
+		return err
+	}
+
+	if err := uc.transactionRepo.CommitTx(dbTx); err != nil {
+		return err
+	}
+
+	return uc.returnPayment(intent)
+}
+
+// refundLatePayment records the payment of an intent whose checkout was
+// already cancelled or failed and refunds the buyer
+func (uc *paymentIntentUsecase) refundLatePayment(intent *domain.PaymentIntent) error {
+	settled, err := uc.paymentIntentRepo.TransitionStatus(intent.ID, intent.Status, domain.PaymentIntentStatusSuccess)
+	if err != nil {
+		return err
+	}
+	if !settled {
+		return errors.New("invalid payment intent state for success processing")
+	}
+
+	return uc.returnPayment(intent)
+}
+
+// returnPayment refunds the whole amount of a paid intent through its
+// provider. An intent its provider could not refund is left as needs_refund.
+func (uc *paymentIntentUsecase) returnPayment(intent *domain.PaymentIntent) error {
+	if _, err := uc.refundIntent(intent, intent.Amount, fmt.Sprintf("intent-%d", intent.ID)); err != nil {
+		log.Printf("Error refunding payment intent %d: %v", intent.ID, err)
+		_, err = uc.paymentIntentRepo.TransitionStatus(intent.ID, domain.PaymentIntentStatusSuccess, domain.PaymentIntentStatusNeedsRefund)
+		return err
+	}
+
+	_, err := uc.paymentIntentRepo.TransitionStatus(intent.ID, domain.PaymentIntentStatusSuccess, domain.PaymentIntentStatusRefunded)
+	return err
+}
+
 func (uc *paymentIntentUsecase) ProcessPaymentFailed(intentID uint) error {
 	intent, err := uc.paymentIntentRepo.GetByID(intentID)
 	if err != nil {
@@ -95,15 +254,139 @@ func (uc *paymentIntentUsecase) ProcessPaymentFailed(intentID uint) error {
 	}
 	
 	// Update intent status
-	err = uc.paymentIntentRepo.UpdateStatus(intentID, domain.PaymentIntentStatusFailed)
+	settled, err := uc.paymentIntentRepo.TransitionStatus(intentID, domain.PaymentIntentStatusPending, domain.PaymentIntentStatusFailed)
 	if err != nil {
 		return err
 	}
+	if !settled {
+		return nil // Idempotent
+	}
 	
-	// Update transaction to failed
-	return uc.transactionUC.OnPaymentFailed(uint64(intent.TrxID))
+	// Update checkout and its orders to failed
+	return uc.transactionUC.OnPaymentFailed(uint64(intent.CheckoutID))
+}
+
+func (uc *paymentIntentUsecase) GetPaymentIntent(userID uint64, intentID uint) (*domain.PaymentIntent, error) {
+	intent, err := uc.paymentIntentRepo.GetByID(intentID)
+	if err != nil {
+		return nil, errors.New("payment intent not found")
+	}
+
+	if intent.Checkout == nil || intent.Checkout.UserID != userID {
+		return nil, errors.New("access denied")
+	}
+
+	if intent.Status != domain.PaymentIntentStatusPending || intent.ProviderRef == "" {
+		return intent, nil
+	}
+
+	// The callback may have been lost; ask the provider directly
+	settled, err := uc.syncWithProvider(intent)
+	if err != nil {
+		log.Printf("Error checking payment intent %d with %s: %v", intent.ID, intent.Provider, err)
+		return intent, nil
+	}
+	if !settled {
+		return intent, nil
+	}
+	return uc.paymentIntentRepo.GetByID(intentID)
 }
 
-func (uc *paymentIntentUsecase) ExpireIntentsByTrxID(trxID uint) error {
-	return uc.paymentIntentRepo.ExpireByTrxID(trxID)
-}
\ No newline at end of file
+func (uc *paymentIntentUsecase) RefundPayment(checkoutID uint, amount float64, idempotencyKey string) (string, error) {
+	intent, err := uc.paymentIntentRepo.GetByCheckoutID(checkoutID)
+	if err != nil {
+		return "", errors.New("payment intent not found")
+	}
+
+	if intent.Status != domain.PaymentIntentStatusSuccess {
+		return "", errors.New("payment intent is not paid")
+	}
+
+	return uc.refundIntent(intent, amount, idempotencyKey)
+}
+
+// refundIntent returns amount of the intent's charge through its provider
+func (uc *paymentIntentUsecase) refundIntent(intent *domain.PaymentIntent, amount float64, idempotencyKey string) (string, error) {
+	// Payments settled without a provider, e.g. simulated ones, have nothing to return
+	if intent.ProviderRef == "" {
+		return "", nil
+	}
+
+	provider, ok := uc.providers.ByName(intent.Provider)
+	if !ok {
+		return "", errors.New("PAYMENT_PROVIDER_UNAVAILABLE")
+	}
+	return provider.Refund(intent.ProviderRef, amount, idempotencyKey)
+}
+
+// syncWithProvider applies the provider's status of a pending intent and
+// reports whether the intent was settled
+func (uc *paymentIntentUsecase) syncWithProvider(intent *domain.PaymentIntent) (bool, error) {
+	provider, ok := uc.providers.ByName(intent.Provider)
+	if !ok {
+		return false, errors.New("PAYMENT_PROVIDER_UNAVAILABLE")
+	}
+
+	status, err := provider.GetStatus(intent.ProviderRef)
+	if err != nil {
+		return false, err
+	}
+
+	switch status.Status {
+	case domain.ChargeStatusPaid:
+		paidAt := time.Now()
+		if status.PaidAt != nil {
+			paidAt = *status.PaidAt
+		}
+		return true, uc.ProcessPaymentSuccess(intent.ID, paidAt)
+	case domain.ChargeStatusFailed, domain.ChargeStatusExpired:
+		return true, uc.ProcessPaymentFailed(intent.ID)
+	default:
+		return false, nil
+	}
+}
+
+func (uc *paymentIntentUsecase) ExpireIntentsByCheckoutID(checkoutID uint) error {
+	return uc.paymentIntentRepo.ExpireByCheckoutID(checkoutID)
+}
+
+// ExpireStaleIntents expires pending intents whose payment window closed and
+// fails their checkouts. Each intent is settled in its own database
+// transaction while its row is locked, so instances sweeping at the same time
+// skip each other's intents and a re-run finds nothing left to do.
+func (uc *paymentIntentUsecase) ExpireStaleIntents() (int, error) {
+	expired := 0
+	for expired < expirySweepLimit {
+		dbTx, err := uc.transactionRepo.BeginTx()
+		if err != nil {
+			return expired, err
+		}
+
+		intents, err := uc.paymentIntentRepo.GetExpiredPendingWithLock(dbTx, time.Now(), 1)
+		if err != nil || len(intents) == 0 {
+			uc.transactionRepo.RollbackTx(dbTx)
+			return expired, err
+		}
+		intent := intents[0]
+
+		_, err = uc.paymentIntentRepo.TransitionStatusWithTx(dbTx, intent.ID, domain.PaymentIntentStatusPending, domain.PaymentIntentStatusExpired)
+		if err != nil {
+			uc.transactionRepo.RollbackTx(dbTx)
+			return expired, err
+		}
+
+		// Cancels the orders and gives back their stock holds
+		err = uc.transactionUC.FailCheckoutWithTx(dbTx, uint64(intent.CheckoutID), "payment expired")
+		if err != nil {
+			uc.transactionRepo.RollbackTx(dbTx)
+			return expired, err
+		}
+
+		if err := uc.transactionRepo.CommitTx(dbTx); err != nil {
+			return expired, err
+		}
+		expired++
+	}
+
+	return expired, nil
+}
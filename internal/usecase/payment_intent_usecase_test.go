@@ -0,0 +1,661 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go-commerce/internal/domain"
+	"go-commerce/internal/usecase/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPaymentIntentUsecase_ExpireStaleIntents_FailsCheckout(t *testing.T) {
+	// Setup
+	mockPaymentIntentRepo := new(mocks.MockPaymentIntentRepository)
+	mockCheckoutRepo := new(mocks.MockCheckoutRepository)
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockReservationRepo := new(mocks.MockStockReservationRepository)
+	mockHistoryRepo := new(mocks.MockTransactionStatusHistoryRepository)
+	mockProvider := new(mocks.MockPaymentProvider)
+	mockProvider.On("Name").Return("mock")
+	providers := NewPaymentProviders()
+	providers.Register(mockProvider, "transfer", "ewallet")
+
+	transactionUsecase := NewTransactionUsecase(
+		mockTransactionRepo,
+		mockCheckoutRepo,
+		new(mocks.MockTransactionItemRepository),
+		new(mocks.MockProductLogRepository),
+		mockProductRepo,
+		new(mocks.MockAddressRepository),
+		new(mocks.MockUserRepository),
+		new(mocks.StoreRepositoryMock),
+		NewStockReservationUsecase(mockReservationRepo, mockProductRepo, mockTransactionRepo, StockReservationConfig{HoldTTL: 30 * time.Minute}),
+		NewOrderStatusUsecase(mockTransactionRepo, mockHistoryRepo),
+		nil,
+		newTestInvoiceNumberer(),
+		nil,
+	)
+	paymentIntentUsecase := NewPaymentIntentUsecase(mockPaymentIntentRepo, mockCheckoutRepo, mockTransactionRepo, transactionUsecase, providers, PaymentIntentConfig{TTL: 30 * time.Minute})
+
+	checkout := &domain.Checkout{
+		ID:     3,
+		Status: "pending",
+		Transactions: []*domain.Transaction{
+			{ID: 7, Status: "pending", OrderStatus: "created"},
+		},
+	}
+	firstTx := "first_transaction"
+	secondTx := "second_transaction"
+
+	// Mock expectations - the second sweep round finds nothing left
+	mockTransactionRepo.On("BeginTx").Return(firstTx, nil).Once()
+	mockTransactionRepo.On("BeginTx").Return(secondTx, nil).Once()
+	mockPaymentIntentRepo.On("GetExpiredPendingWithLock", firstTx, mock.AnythingOfType("time.Time"), 1).Return([]*domain.PaymentIntent{{ID: 11, CheckoutID: 3, Status: domain.PaymentIntentStatusPending}}, nil)
+	mockPaymentIntentRepo.On("GetExpiredPendingWithLock", secondTx, mock.AnythingOfType("time.Time"), 1).Return([]*domain.PaymentIntent{}, nil)
+	mockPaymentIntentRepo.On("TransitionStatusWithTx", firstTx, uint(11), domain.PaymentIntentStatusPending, domain.PaymentIntentStatusExpired).Return(true, nil)
+	mockCheckoutRepo.On("GetByID", uint64(3)).Return(checkout, nil)
+	mockCheckoutRepo.On("TransitionStatusWithTx", firstTx, uint64(3), "pending", "failed").Return(true, nil)
+	mockReservationRepo.On("GetHeldByCheckoutIDWithTx", firstTx, uint64(3)).Return([]*domain.StockReservation{}, nil)
+	mockTransactionRepo.On("TransitionStatusWithTx", firstTx, uint64(7), "pending", "failed", mock.AnythingOfType("time.Time")).Return(true, nil)
+	mockTransactionRepo.On("TransitionOrderStatusWithTx", firstTx, uint64(7), "created", "cancelled", mock.AnythingOfType("time.Time")).Return(true, nil)
+	mockHistoryRepo.On("CreateWithTx", firstTx, mock.MatchedBy(func(entry *domain.TransactionStatusHistory) bool {
+		return entry.ActorRole == domain.StatusActorSystem && entry.Reason == "payment expired"
+	})).Return(nil).Twice()
+	mockTransactionRepo.On("CommitTx", firstTx).Return(nil)
+	mockTransactionRepo.On("RollbackTx", secondTx).Return(nil)
+
+	// Execute
+	expired, err := paymentIntentUsecase.ExpireStaleIntents()
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, expired)
+	mockPaymentIntentRepo.AssertExpectations(t)
+	mockCheckoutRepo.AssertExpectations(t)
+	mockTransactionRepo.AssertExpectations(t)
+}
+
+func TestPaymentIntentUsecase_ExpireStaleIntents_LeavesPaidCheckout(t *testing.T) {
+	// Setup
+	mockPaymentIntentRepo := new(mocks.MockPaymentIntentRepository)
+	mockCheckoutRepo := new(mocks.MockCheckoutRepository)
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockReservationRepo := new(mocks.MockStockReservationRepository)
+	mockHistoryRepo := new(mocks.MockTransactionStatusHistoryRepository)
+	mockProvider := new(mocks.MockPaymentProvider)
+	mockProvider.On("Name").Return("mock")
+	providers := NewPaymentProviders()
+	providers.Register(mockProvider, "transfer", "ewallet")
+
+	transactionUsecase := NewTransactionUsecase(
+		mockTransactionRepo,
+		mockCheckoutRepo,
+		new(mocks.MockTransactionItemRepository),
+		new(mocks.MockProductLogRepository),
+		mockProductRepo,
+		new(mocks.MockAddressRepository),
+		new(mocks.MockUserRepository),
+		new(mocks.StoreRepositoryMock),
+		NewStockReservationUsecase(mockReservationRepo, mockProductRepo, mockTransactionRepo, StockReservationConfig{HoldTTL: 30 * time.Minute}),
+		NewOrderStatusUsecase(mockTransactionRepo, mockHistoryRepo),
+		nil,
+		newTestInvoiceNumberer(),
+		nil,
+	)
+	paymentIntentUsecase := NewPaymentIntentUsecase(mockPaymentIntentRepo, mockCheckoutRepo, mockTransactionRepo, transactionUsecase, providers, PaymentIntentConfig{TTL: 30 * time.Minute})
+
+	firstTx := "first_transaction"
+	secondTx := "second_transaction"
+
+	// Mock expectations - the checkout was paid before the sweep reached it
+	mockTransactionRepo.On("BeginTx").Return(firstTx, nil).Once()
+	mockTransactionRepo.On("BeginTx").Return(secondTx, nil).Once()
+	mockPaymentIntentRepo.On("GetExpiredPendingWithLock", firstTx, mock.AnythingOfType("time.Time"), 1).Return([]*domain.PaymentIntent{{ID: 11, CheckoutID: 3, Status: domain.PaymentIntentStatusPending}}, nil)
+	mockPaymentIntentRepo.On("GetExpiredPendingWithLock", secondTx, mock.AnythingOfType("time.Time"), 1).Return([]*domain.PaymentIntent{}, nil)
+	mockPaymentIntentRepo.On("TransitionStatusWithTx", firstTx, uint(11), domain.PaymentIntentStatusPending, domain.PaymentIntentStatusExpired).Return(true, nil)
+	mockCheckoutRepo.On("GetByID", uint64(3)).Return(&domain.Checkout{ID: 3, Status: "paid"}, nil)
+	mockTransactionRepo.On("CommitTx", firstTx).Return(nil)
+	mockTransactionRepo.On("RollbackTx", secondTx).Return(nil)
+
+	// Execute
+	expired, err := paymentIntentUsecase.ExpireStaleIntents()
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, expired)
+	mockCheckoutRepo.AssertNotCalled(t, "TransitionStatusWithTx", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockTransactionRepo.AssertNotCalled(t, "TransitionStatusWithTx", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPaymentIntentUsecase_ProcessPaymentSuccess_RejectsExpiredIntent(t *testing.T) {
+	// Setup
+	mockPaymentIntentRepo := new(mocks.MockPaymentIntentRepository)
+	mockCheckoutRepo := new(mocks.MockCheckoutRepository)
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockReservationRepo := new(mocks.MockStockReservationRepository)
+	mockHistoryRepo := new(mocks.MockTransactionStatusHistoryRepository)
+	mockProvider := new(mocks.MockPaymentProvider)
+	mockProvider.On("Name").Return("mock")
+	providers := NewPaymentProviders()
+	providers.Register(mockProvider, "transfer", "ewallet")
+
+	transactionUsecase := NewTransactionUsecase(
+		mockTransactionRepo,
+		mockCheckoutRepo,
+		new(mocks.MockTransactionItemRepository),
+		new(mocks.MockProductLogRepository),
+		mockProductRepo,
+		new(mocks.MockAddressRepository),
+		new(mocks.MockUserRepository),
+		new(mocks.StoreRepositoryMock),
+		NewStockReservationUsecase(mockReservationRepo, mockProductRepo, mockTransactionRepo, StockReservationConfig{HoldTTL: 30 * time.Minute}),
+		NewOrderStatusUsecase(mockTransactionRepo, mockHistoryRepo),
+		nil,
+		newTestInvoiceNumberer(),
+		nil,
+	)
+	paymentIntentUsecase := NewPaymentIntentUsecase(mockPaymentIntentRepo, mockCheckoutRepo, mockTransactionRepo, transactionUsecase, providers, PaymentIntentConfig{TTL: 30 * time.Minute})
+
+	intent := &domain.PaymentIntent{
+		ID:         11,
+		CheckoutID: 3,
+		Status:     domain.PaymentIntentStatusPending,
+		Checkout:   &domain.Checkout{ID: 3, Status: "pending"},
+	}
+	mockTx := "mock_transaction"
+
+	// Mock expectations - the sweep expired the intent after it was read
+	mockPaymentIntentRepo.On("GetByID", uint(11)).Return(intent, nil)
+	mockTransactionRepo.On("BeginTx").Return(mockTx, nil)
+	mockPaymentIntentRepo.On("TransitionStatusWithTx", mockTx, uint(11), domain.PaymentIntentStatusPending, domain.PaymentIntentStatusSuccess).Return(false, nil)
+	mockTransactionRepo.On("RollbackTx", mockTx).Return(nil)
+
+	// Execute
+	err := paymentIntentUsecase.ProcessPaymentSuccess(11, time.Now())
+
+	// Assert
+	assert.Error(t, err)
+	mockCheckoutRepo.AssertNotCalled(t, "GetByID", mock.Anything)
+	mockTransactionRepo.AssertNotCalled(t, "CommitTx", mock.Anything)
+}
+
+func TestPaymentIntentUsecase_ProcessPaymentSuccess_RefundsWhenStockSoldMeanwhile(t *testing.T) {
+	// Setup
+	mockPaymentIntentRepo := new(mocks.MockPaymentIntentRepository)
+	mockCheckoutRepo := new(mocks.MockCheckoutRepository)
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockProductLogRepo := new(mocks.MockProductLogRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockReservationRepo := new(mocks.MockStockReservationRepository)
+	mockHistoryRepo := new(mocks.MockTransactionStatusHistoryRepository)
+	mockProvider := new(mocks.MockPaymentProvider)
+	mockProvider.On("Name").Return("mock")
+	providers := NewPaymentProviders()
+	providers.Register(mockProvider, "transfer", "ewallet")
+
+	transactionUsecase := NewTransactionUsecase(
+		mockTransactionRepo,
+		mockCheckoutRepo,
+		new(mocks.MockTransactionItemRepository),
+		mockProductLogRepo,
+		mockProductRepo,
+		new(mocks.MockAddressRepository),
+		new(mocks.MockUserRepository),
+		new(mocks.StoreRepositoryMock),
+		NewStockReservationUsecase(mockReservationRepo, mockProductRepo, mockTransactionRepo, StockReservationConfig{HoldTTL: 30 * time.Minute}),
+		NewOrderStatusUsecase(mockTransactionRepo, mockHistoryRepo),
+		nil,
+		newTestInvoiceNumberer(),
+		nil,
+	)
+	paymentIntentUsecase := NewPaymentIntentUsecase(mockPaymentIntentRepo, mockCheckoutRepo, mockTransactionRepo, transactionUsecase, providers, PaymentIntentConfig{TTL: 30 * time.Minute})
+
+	intent := &domain.PaymentIntent{
+		ID:          11,
+		CheckoutID:  3,
+		Status:      domain.PaymentIntentStatusPending,
+		Amount:      50000,
+		Provider:    "mock",
+		ProviderRef: "MOCK-1",
+		Checkout:    &domain.Checkout{ID: 3, Status: "pending"},
+	}
+	checkout := &domain.Checkout{
+		ID:     3,
+		Status: "pending",
+		Transactions: []*domain.Transaction{
+			{ID: 7, Status: "pending", OrderStatus: "created", TransactionItems: []*domain.TransactionItem{{ProductLogID: 100, Quantity: 1}}},
+		},
+	}
+	paidAt := time.Date(2026, 10, 17, 9, 30, 0, 0, time.UTC)
+	paidTx := "paid_transaction"
+	failTx := "fail_transaction"
+
+	// Mock expectations - the hold expired and the stock was sold before the payment came in
+	mockPaymentIntentRepo.On("GetByID", uint(11)).Return(intent, nil)
+	mockTransactionRepo.On("BeginTx").Return(paidTx, nil).Once()
+	mockPaymentIntentRepo.On("TransitionStatusWithTx", paidTx, uint(11), domain.PaymentIntentStatusPending, domain.PaymentIntentStatusSuccess).Return(true, nil)
+	mockCheckoutRepo.On("GetByID", uint64(3)).Return(checkout, nil)
+	mockProductLogRepo.On("GetByID", uint64(100)).Return(&domain.ProductLog{ID: 100, ProductID: 1}, nil)
+	mockCheckoutRepo.On("MarkPaidWithTx", paidTx, uint64(3), paidAt).Return(true, nil)
+	mockProductRepo.On("GetStockWithLock", paidTx, uint64(1), uint64(0)).Return(0, nil)
+	mockReservationRepo.On("GetHeldByCheckoutIDWithTx", paidTx, uint64(3)).Return([]*domain.StockReservation{}, nil)
+	mockTransactionRepo.On("RollbackTx", paidTx).Return(nil)
+	mockTransactionRepo.On("BeginTx").Return(failTx, nil).Once()
+	mockPaymentIntentRepo.On("TransitionStatusWithTx", failTx, uint(11), domain.PaymentIntentStatusPending, domain.PaymentIntentStatusSuccess).Return(true, nil)
+	mockCheckoutRepo.On("TransitionStatusWithTx", failTx, uint64(3), "pending", "failed").Return(true, nil)
+	mockReservationRepo.On("GetHeldByCheckoutIDWithTx", failTx, uint64(3)).Return([]*domain.StockReservation{}, nil)
+	mockTransactionRepo.On("TransitionStatusWithTx", failTx, uint64(7), "pending", "failed", mock.AnythingOfType("time.Time")).Return(true, nil)
+	mockTransactionRepo.On("TransitionOrderStatusWithTx", failTx, uint64(7), "created", "cancelled", mock.AnythingOfType("time.Time")).Return(true, nil)
+	mockHistoryRepo.On("CreateWithTx", failTx, mock.MatchedBy(func(entry *domain.TransactionStatusHistory) bool {
+		return entry.Reason == "stock no longer available"
+	})).Return(nil).Twice()
+	mockTransactionRepo.On("CommitTx", failTx).Return(nil)
+	mockProvider.On("Refund", "MOCK-1", 50000.0, "intent-11").Return("MOCK-RF-1", nil)
+	mockPaymentIntentRepo.On("TransitionStatus", uint(11), domain.PaymentIntentStatusSuccess, domain.PaymentIntentStatusRefunded).Return(true, nil)
+
+	// Execute
+	err := paymentIntentUsecase.ProcessPaymentSuccess(11, paidAt)
+
+	// Assert
+	assert.NoError(t, err)
+	mockPaymentIntentRepo.AssertExpectations(t)
+	mockCheckoutRepo.AssertExpectations(t)
+	mockTransactionRepo.AssertExpectations(t)
+	mockProvider.AssertExpectations(t)
+	mockTransactionRepo.AssertNotCalled(t, "CommitTx", paidTx)
+}
+
+func TestPaymentIntentUsecase_ProcessPaymentSuccess_FlagsFailedRefund(t *testing.T) {
+	// Setup
+	mockPaymentIntentRepo := new(mocks.MockPaymentIntentRepository)
+	mockCheckoutRepo := new(mocks.MockCheckoutRepository)
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockProductLogRepo := new(mocks.MockProductLogRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockReservationRepo := new(mocks.MockStockReservationRepository)
+	mockHistoryRepo := new(mocks.MockTransactionStatusHistoryRepository)
+	mockProvider := new(mocks.MockPaymentProvider)
+	mockProvider.On("Name").Return("mock")
+	providers := NewPaymentProviders()
+	providers.Register(mockProvider, "transfer", "ewallet")
+
+	transactionUsecase := NewTransactionUsecase(
+		mockTransactionRepo,
+		mockCheckoutRepo,
+		new(mocks.MockTransactionItemRepository),
+		mockProductLogRepo,
+		mockProductRepo,
+		new(mocks.MockAddressRepository),
+		new(mocks.MockUserRepository),
+		new(mocks.StoreRepositoryMock),
+		NewStockReservationUsecase(mockReservationRepo, mockProductRepo, mockTransactionRepo, StockReservationConfig{HoldTTL: 30 * time.Minute}),
+		NewOrderStatusUsecase(mockTransactionRepo, mockHistoryRepo),
+		nil,
+		newTestInvoiceNumberer(),
+		nil,
+	)
+	paymentIntentUsecase := NewPaymentIntentUsecase(mockPaymentIntentRepo, mockCheckoutRepo, mockTransactionRepo, transactionUsecase, providers, PaymentIntentConfig{TTL: 30 * time.Minute})
+
+	intent := &domain.PaymentIntent{
+		ID:          11,
+		CheckoutID:  3,
+		Status:      domain.PaymentIntentStatusPending,
+		Amount:      50000,
+		Provider:    "mock",
+		ProviderRef: "MOCK-1",
+		Checkout:    &domain.Checkout{ID: 3, Status: "pending"},
+	}
+	checkout := &domain.Checkout{
+		ID:     3,
+		Status: "pending",
+		Transactions: []*domain.Transaction{
+			{ID: 7, Status: "pending", OrderStatus: "created", TransactionItems: []*domain.TransactionItem{{ProductLogID: 100, Quantity: 1}}},
+		},
+	}
+	paidAt := time.Date(2026, 10, 17, 9, 30, 0, 0, time.UTC)
+	paidTx := "paid_transaction"
+	failTx := "fail_transaction"
+
+	// Mock expectations - the hold expired and the stock was sold before the payment came in
+	mockPaymentIntentRepo.On("GetByID", uint(11)).Return(intent, nil)
+	mockTransactionRepo.On("BeginTx").Return(paidTx, nil).Once()
+	mockPaymentIntentRepo.On("TransitionStatusWithTx", paidTx, uint(11), domain.PaymentIntentStatusPending, domain.PaymentIntentStatusSuccess).Return(true, nil)
+	mockCheckoutRepo.On("GetByID", uint64(3)).Return(checkout, nil)
+	mockProductLogRepo.On("GetByID", uint64(100)).Return(&domain.ProductLog{ID: 100, ProductID: 1}, nil)
+	mockCheckoutRepo.On("MarkPaidWithTx", paidTx, uint64(3), paidAt).Return(true, nil)
+	mockProductRepo.On("GetStockWithLock", paidTx, uint64(1), uint64(0)).Return(0, nil)
+	mockReservationRepo.On("GetHeldByCheckoutIDWithTx", paidTx, uint64(3)).Return([]*domain.StockReservation{}, nil)
+	mockTransactionRepo.On("RollbackTx", paidTx).Return(nil)
+	mockTransactionRepo.On("BeginTx").Return(failTx, nil).Once()
+	mockPaymentIntentRepo.On("TransitionStatusWithTx", failTx, uint(11), domain.PaymentIntentStatusPending, domain.PaymentIntentStatusSuccess).Return(true, nil)
+	mockCheckoutRepo.On("TransitionStatusWithTx", failTx, uint64(3), "pending", "failed").Return(true, nil)
+	mockReservationRepo.On("GetHeldByCheckoutIDWithTx", failTx, uint64(3)).Return([]*domain.StockReservation{}, nil)
+	mockTransactionRepo.On("TransitionStatusWithTx", failTx, uint64(7), "pending", "failed", mock.AnythingOfType("time.Time")).Return(true, nil)
+	mockTransactionRepo.On("TransitionOrderStatusWithTx", failTx, uint64(7), "created", "cancelled", mock.AnythingOfType("time.Time")).Return(true, nil)
+	mockHistoryRepo.On("CreateWithTx", failTx, mock.MatchedBy(func(entry *domain.TransactionStatusHistory) bool {
+		return entry.Reason == "stock no longer available"
+	})).Return(nil).Twice()
+	mockTransactionRepo.On("CommitTx", failTx).Return(nil)
+	mockProvider.On("Refund", "MOCK-1", 50000.0, "intent-11").Return("", errors.New("provider unavailable"))
+	mockPaymentIntentRepo.On("TransitionStatus", uint(11), domain.PaymentIntentStatusSuccess, domain.PaymentIntentStatusNeedsRefund).Return(true, nil)
+
+	// Execute
+	err := paymentIntentUsecase.ProcessPaymentSuccess(11, paidAt)
+
+	// Assert
+	assert.NoError(t, err)
+	mockPaymentIntentRepo.AssertExpectations(t)
+	mockPaymentIntentRepo.AssertNotCalled(t, "TransitionStatus", uint(11), domain.PaymentIntentStatusSuccess, domain.PaymentIntentStatusRefunded)
+}
+
+func TestPaymentIntentUsecase_ProcessPaymentSuccess_RefundsPaymentOfCancelledCheckout(t *testing.T) {
+	// Setup
+	mockPaymentIntentRepo := new(mocks.MockPaymentIntentRepository)
+	mockCheckoutRepo := new(mocks.MockCheckoutRepository)
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockProvider := new(mocks.MockPaymentProvider)
+	mockProvider.On("Name").Return("mock")
+	providers := NewPaymentProviders()
+	providers.Register(mockProvider, "transfer", "ewallet")
+
+	paymentIntentUsecase := NewPaymentIntentUsecase(mockPaymentIntentRepo, mockCheckoutRepo, mockTransactionRepo, nil, providers, PaymentIntentConfig{TTL: 30 * time.Minute})
+
+	intent := &domain.PaymentIntent{
+		ID:          11,
+		CheckoutID:  3,
+		Status:      domain.PaymentIntentStatusExpired,
+		Amount:      50000,
+		Provider:    "mock",
+		ProviderRef: "MOCK-1",
+		Checkout:    &domain.Checkout{ID: 3, Status: "cancelled"},
+	}
+	paidAt := time.Date(2026, 10, 17, 9, 30, 0, 0, time.UTC)
+
+	// Mock expectations - the buyer paid into the virtual account after cancelling
+	mockPaymentIntentRepo.On("GetByID", uint(11)).Return(intent, nil)
+	mockPaymentIntentRepo.On("TransitionStatus", uint(11), domain.PaymentIntentStatusExpired, domain.PaymentIntentStatusSuccess).Return(true, nil)
+	mockProvider.On("Refund", "MOCK-1", 50000.0, "intent-11").Return("MOCK-RF-1", nil)
+	mockPaymentIntentRepo.On("TransitionStatus", uint(11), domain.PaymentIntentStatusSuccess, domain.PaymentIntentStatusRefunded).Return(true, nil)
+
+	// Execute
+	err := paymentIntentUsecase.ProcessPaymentSuccess(11, paidAt)
+
+	// Assert
+	assert.NoError(t, err)
+	mockPaymentIntentRepo.AssertExpectations(t)
+	mockProvider.AssertExpectations(t)
+	mockTransactionRepo.AssertNotCalled(t, "BeginTx")
+}
+
+func TestPaymentIntentUsecase_CreatePaymentIntent_IssuesProviderCharge(t *testing.T) {
+	// Setup
+	mockPaymentIntentRepo := new(mocks.MockPaymentIntentRepository)
+	mockCheckoutRepo := new(mocks.MockCheckoutRepository)
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockReservationRepo := new(mocks.MockStockReservationRepository)
+	mockHistoryRepo := new(mocks.MockTransactionStatusHistoryRepository)
+	mockProvider := new(mocks.MockPaymentProvider)
+	mockProvider.On("Name").Return("mock")
+	providers := NewPaymentProviders()
+	providers.Register(mockProvider, "transfer", "ewallet")
+
+	transactionUsecase := NewTransactionUsecase(
+		mockTransactionRepo,
+		mockCheckoutRepo,
+		new(mocks.MockTransactionItemRepository),
+		new(mocks.MockProductLogRepository),
+		mockProductRepo,
+		new(mocks.MockAddressRepository),
+		new(mocks.MockUserRepository),
+		new(mocks.StoreRepositoryMock),
+		NewStockReservationUsecase(mockReservationRepo, mockProductRepo, mockTransactionRepo, StockReservationConfig{HoldTTL: 30 * time.Minute}),
+		NewOrderStatusUsecase(mockTransactionRepo, mockHistoryRepo),
+		nil,
+		newTestInvoiceNumberer(),
+		nil,
+	)
+	paymentIntentUsecase := NewPaymentIntentUsecase(mockPaymentIntentRepo, mockCheckoutRepo, mockTransactionRepo, transactionUsecase, providers, PaymentIntentConfig{TTL: 30 * time.Minute})
+
+	checkout := &domain.Checkout{ID: 3, UserID: 1, HargaTotal: 45000, Status: "pending"}
+	charge := &domain.Charge{Provider: "mock", Reference: "MOCK-1", VirtualAccount: "8808000000000001"}
+
+	// Mock expectations
+	mockCheckoutRepo.On("GetByID", uint64(3)).Return(checkout, nil)
+	mockPaymentIntentRepo.On("GetByCheckoutID", uint(3)).Return(nil, errors.New("record not found"))
+	mockPaymentIntentRepo.On("Create", mock.AnythingOfType("*domain.PaymentIntent")).Run(func(args mock.Arguments) {
+		args.Get(0).(*domain.PaymentIntent).ID = 11
+	}).Return(nil)
+	mockProvider.On("CreateCharge", mock.MatchedBy(func(req *domain.ChargeRequest) bool {
+		return req.IntentID == 11 && req.Method == "transfer" && req.Amount == 45000
+	})).Return(charge, nil)
+	mockPaymentIntentRepo.On("AttachCharge", uint(11), charge).Return(nil)
+	mockReservationRepo.On("ExtendByCheckoutID", uint64(3), mock.AnythingOfType("time.Time")).Return(nil)
+
+	// Execute
+	intent, err := paymentIntentUsecase.CreatePaymentIntent(3, "transfer")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "MOCK-1", intent.ProviderRef)
+	assert.Equal(t, "8808000000000001", intent.VirtualAccount)
+	assert.Equal(t, 45000.0, intent.Amount)
+	mockProvider.AssertExpectations(t)
+	mockPaymentIntentRepo.AssertExpectations(t)
+}
+
+func TestPaymentIntentUsecase_CreatePaymentIntentForOrder_PaysWholeCheckout(t *testing.T) {
+	// Setup
+	mockPaymentIntentRepo := new(mocks.MockPaymentIntentRepository)
+	mockCheckoutRepo := new(mocks.MockCheckoutRepository)
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockReservationRepo := new(mocks.MockStockReservationRepository)
+	mockHistoryRepo := new(mocks.MockTransactionStatusHistoryRepository)
+	mockProvider := new(mocks.MockPaymentProvider)
+	mockProvider.On("Name").Return("mock")
+	providers := NewPaymentProviders()
+	providers.Register(mockProvider, "transfer", "ewallet")
+
+	transactionUsecase := NewTransactionUsecase(
+		mockTransactionRepo,
+		mockCheckoutRepo,
+		new(mocks.MockTransactionItemRepository),
+		new(mocks.MockProductLogRepository),
+		mockProductRepo,
+		new(mocks.MockAddressRepository),
+		new(mocks.MockUserRepository),
+		new(mocks.StoreRepositoryMock),
+		NewStockReservationUsecase(mockReservationRepo, mockProductRepo, mockTransactionRepo, StockReservationConfig{HoldTTL: 30 * time.Minute}),
+		NewOrderStatusUsecase(mockTransactionRepo, mockHistoryRepo),
+		nil,
+		newTestInvoiceNumberer(),
+		nil,
+	)
+	paymentIntentUsecase := NewPaymentIntentUsecase(mockPaymentIntentRepo, mockCheckoutRepo, mockTransactionRepo, transactionUsecase, providers, PaymentIntentConfig{TTL: 30 * time.Minute})
+
+	// Order 8 is one of the two orders of checkout 3
+	transaction := &domain.Transaction{ID: 8, UserID: 1, CheckoutID: 3, HargaTotal: 20000, Status: "pending"}
+	checkout := &domain.Checkout{ID: 3, UserID: 1, HargaTotal: 45000, Status: "pending"}
+	charge := &domain.Charge{Provider: "mock", Reference: "MOCK-1", VirtualAccount: "8808000000000001"}
+
+	// Mock expectations
+	mockTransactionRepo.On("GetByID", uint64(8)).Return(transaction, nil)
+	mockCheckoutRepo.On("GetByID", uint64(3)).Return(checkout, nil)
+	mockPaymentIntentRepo.On("GetByCheckoutID", uint(3)).Return(nil, errors.New("record not found"))
+	mockPaymentIntentRepo.On("Create", mock.AnythingOfType("*domain.PaymentIntent")).Run(func(args mock.Arguments) {
+		args.Get(0).(*domain.PaymentIntent).ID = 11
+	}).Return(nil)
+	mockProvider.On("CreateCharge", mock.MatchedBy(func(req *domain.ChargeRequest) bool {
+		return req.IntentID == 11 && req.Amount == 45000
+	})).Return(charge, nil)
+	mockPaymentIntentRepo.On("AttachCharge", uint(11), charge).Return(nil)
+	mockReservationRepo.On("ExtendByCheckoutID", uint64(3), mock.AnythingOfType("time.Time")).Return(nil)
+
+	// Execute
+	intent, err := paymentIntentUsecase.CreatePaymentIntentForOrder(8, "transfer")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, uint(3), intent.CheckoutID)
+	assert.Equal(t, 45000.0, intent.Amount)
+	mockTransactionRepo.AssertExpectations(t)
+	mockProvider.AssertExpectations(t)
+	mockPaymentIntentRepo.AssertExpectations(t)
+}
+
+func TestPaymentIntentUsecase_CreatePaymentIntent_UnsupportedMethod(t *testing.T) {
+	// Setup
+	mockPaymentIntentRepo := new(mocks.MockPaymentIntentRepository)
+	mockCheckoutRepo := new(mocks.MockCheckoutRepository)
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockReservationRepo := new(mocks.MockStockReservationRepository)
+	mockHistoryRepo := new(mocks.MockTransactionStatusHistoryRepository)
+	mockProvider := new(mocks.MockPaymentProvider)
+	mockProvider.On("Name").Return("mock")
+	providers := NewPaymentProviders()
+	providers.Register(mockProvider, "transfer", "ewallet")
+
+	transactionUsecase := NewTransactionUsecase(
+		mockTransactionRepo,
+		mockCheckoutRepo,
+		new(mocks.MockTransactionItemRepository),
+		new(mocks.MockProductLogRepository),
+		mockProductRepo,
+		new(mocks.MockAddressRepository),
+		new(mocks.MockUserRepository),
+		new(mocks.StoreRepositoryMock),
+		NewStockReservationUsecase(mockReservationRepo, mockProductRepo, mockTransactionRepo, StockReservationConfig{HoldTTL: 30 * time.Minute}),
+		NewOrderStatusUsecase(mockTransactionRepo, mockHistoryRepo),
+		nil,
+		newTestInvoiceNumberer(),
+		nil,
+	)
+	paymentIntentUsecase := NewPaymentIntentUsecase(mockPaymentIntentRepo, mockCheckoutRepo, mockTransactionRepo, transactionUsecase, providers, PaymentIntentConfig{TTL: 30 * time.Minute})
+
+	// Execute
+	intent, err := paymentIntentUsecase.CreatePaymentIntent(3, "credit_card")
+
+	// Assert
+	assert.Error(t, err)
+	assert.Equal(t, "PAYMENT_METHOD_NOT_SUPPORTED", err.Error())
+	assert.Nil(t, intent)
+	mockPaymentIntentRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestPaymentIntentUsecase_GetPaymentIntent_SettlesFromProviderStatus(t *testing.T) {
+	// Setup
+	mockPaymentIntentRepo := new(mocks.MockPaymentIntentRepository)
+	mockCheckoutRepo := new(mocks.MockCheckoutRepository)
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockReservationRepo := new(mocks.MockStockReservationRepository)
+	mockHistoryRepo := new(mocks.MockTransactionStatusHistoryRepository)
+	mockProvider := new(mocks.MockPaymentProvider)
+	mockProvider.On("Name").Return("mock")
+	providers := NewPaymentProviders()
+	providers.Register(mockProvider, "transfer", "ewallet")
+
+	transactionUsecase := NewTransactionUsecase(
+		mockTransactionRepo,
+		mockCheckoutRepo,
+		new(mocks.MockTransactionItemRepository),
+		new(mocks.MockProductLogRepository),
+		mockProductRepo,
+		new(mocks.MockAddressRepository),
+		new(mocks.MockUserRepository),
+		new(mocks.StoreRepositoryMock),
+		NewStockReservationUsecase(mockReservationRepo, mockProductRepo, mockTransactionRepo, StockReservationConfig{HoldTTL: 30 * time.Minute}),
+		NewOrderStatusUsecase(mockTransactionRepo, mockHistoryRepo),
+		nil,
+		newTestInvoiceNumberer(),
+		nil,
+	)
+	paymentIntentUsecase := NewPaymentIntentUsecase(mockPaymentIntentRepo, mockCheckoutRepo, mockTransactionRepo, transactionUsecase, providers, PaymentIntentConfig{TTL: 30 * time.Minute})
+
+	paidAt := time.Date(2026, 10, 17, 9, 30, 0, 0, time.UTC)
+	pending := &domain.PaymentIntent{
+		ID:          11,
+		CheckoutID:  3,
+		Status:      domain.PaymentIntentStatusPending,
+		Provider:    "mock",
+		ProviderRef: "MOCK-1",
+		Checkout:    &domain.Checkout{ID: 3, UserID: 1, Status: "pending"},
+	}
+	paid := &domain.PaymentIntent{ID: 11, CheckoutID: 3, Status: domain.PaymentIntentStatusSuccess, Checkout: &domain.Checkout{ID: 3, UserID: 1, Status: "paid"}}
+	mockTx := "mock_transaction"
+
+	// Mock expectations - the callback was lost but the provider reports the payment
+	mockPaymentIntentRepo.On("GetByID", uint(11)).Return(pending, nil).Twice()
+	mockProvider.On("GetStatus", "MOCK-1").Return(&domain.ChargeStatus{Status: domain.ChargeStatusPaid, PaidAt: &paidAt}, nil)
+	mockTransactionRepo.On("BeginTx").Return(mockTx, nil)
+	mockPaymentIntentRepo.On("TransitionStatusWithTx", mockTx, uint(11), domain.PaymentIntentStatusPending, domain.PaymentIntentStatusSuccess).Return(true, nil)
+	mockCheckoutRepo.On("GetByID", uint64(3)).Return(&domain.Checkout{ID: 3, UserID: 1, Status: "pending"}, nil)
+	mockCheckoutRepo.On("MarkPaidWithTx", mockTx, uint64(3), paidAt).Return(true, nil)
+	mockReservationRepo.On("GetHeldByCheckoutIDWithTx", mockTx, uint64(3)).Return([]*domain.StockReservation{}, nil)
+	mockTransactionRepo.On("CommitTx", mockTx).Return(nil)
+	mockPaymentIntentRepo.On("GetByID", uint(11)).Return(paid, nil).Once()
+
+	// Execute
+	intent, err := paymentIntentUsecase.GetPaymentIntent(1, 11)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, domain.PaymentIntentStatusSuccess, intent.Status)
+	mockCheckoutRepo.AssertExpectations(t)
+	mockTransactionRepo.AssertExpectations(t)
+	mockPaymentIntentRepo.AssertNotCalled(t, "TransitionStatus", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPaymentIntentUsecase_GetPaymentIntent_OtherBuyer(t *testing.T) {
+	// Setup
+	mockPaymentIntentRepo := new(mocks.MockPaymentIntentRepository)
+	mockCheckoutRepo := new(mocks.MockCheckoutRepository)
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockReservationRepo := new(mocks.MockStockReservationRepository)
+	mockHistoryRepo := new(mocks.MockTransactionStatusHistoryRepository)
+	mockProvider := new(mocks.MockPaymentProvider)
+	mockProvider.On("Name").Return("mock")
+	providers := NewPaymentProviders()
+	providers.Register(mockProvider, "transfer", "ewallet")
+
+	transactionUsecase := NewTransactionUsecase(
+		mockTransactionRepo,
+		mockCheckoutRepo,
+		new(mocks.MockTransactionItemRepository),
+		new(mocks.MockProductLogRepository),
+		mockProductRepo,
+		new(mocks.MockAddressRepository),
+		new(mocks.MockUserRepository),
+		new(mocks.StoreRepositoryMock),
+		NewStockReservationUsecase(mockReservationRepo, mockProductRepo, mockTransactionRepo, StockReservationConfig{HoldTTL: 30 * time.Minute}),
+		NewOrderStatusUsecase(mockTransactionRepo, mockHistoryRepo),
+		nil,
+		newTestInvoiceNumberer(),
+		nil,
+	)
+	paymentIntentUsecase := NewPaymentIntentUsecase(mockPaymentIntentRepo, mockCheckoutRepo, mockTransactionRepo, transactionUsecase, providers, PaymentIntentConfig{TTL: 30 * time.Minute})
+
+	intent := &domain.PaymentIntent{ID: 11, CheckoutID: 3, Status: domain.PaymentIntentStatusPending, Checkout: &domain.Checkout{ID: 3, UserID: 2}}
+
+	// Mock expectations
+	mockPaymentIntentRepo.On("GetByID", uint(11)).Return(intent, nil)
+
+	// Execute
+	result, err := paymentIntentUsecase.GetPaymentIntent(1, 11)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Equal(t, "access denied", err.Error())
+	assert.Nil(t, result)
+	mockProvider.AssertNotCalled(t, "GetStatus", mock.Anything)
+}
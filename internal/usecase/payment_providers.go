@@ -0,0 +1,51 @@
+package usecase
+
+import (
+	"fmt"
+
+	"go-commerce/internal/domain"
+)
+
+// PaymentProviders routes each payment method to the provider that collects
+// it, and each gateway callback to the provider that sent it
+type PaymentProviders struct {
+	byMethod map[string]domain.PaymentProvider
+	byName   map[string]domain.PaymentProvider
+}
+
+func NewPaymentProviders() *PaymentProviders {
+	return &PaymentProviders{
+		byMethod: make(map[string]domain.PaymentProvider),
+		byName:   make(map[string]domain.PaymentProvider),
+	}
+}
+
+// Register makes provider collect the given methods. A later registration of
+// the same method replaces the earlier one.
+func (p *PaymentProviders) Register(provider domain.PaymentProvider, methods ...string) {
+	p.byName[provider.Name()] = provider
+	for _, method := range methods {
+		p.byMethod[method] = provider
+	}
+}
+
+func (p *PaymentProviders) ForMethod(method string) (domain.PaymentProvider, bool) {
+	provider, ok := p.byMethod[method]
+	return provider, ok
+}
+
+func (p *PaymentProviders) ByName(name string) (domain.PaymentProvider, bool) {
+	provider, ok := p.byName[name]
+	return provider, ok
+}
+
+// Validate reports the first of methods that no provider collects, so a
+// misconfigured deployment fails at startup instead of at checkout
+func (p *PaymentProviders) Validate(methods ...string) error {
+	for _, method := range methods {
+		if _, ok := p.byMethod[method]; !ok {
+			return fmt.Errorf("no payment provider for method %s", method)
+		}
+	}
+	return nil
+}
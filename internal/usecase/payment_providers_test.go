@@ -0,0 +1,24 @@
+package usecase
+
+import (
+	"testing"
+
+	"go-commerce/internal/usecase/mocks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaymentProviders_Validate_MethodWithoutProvider(t *testing.T) {
+	// Setup
+	mockProvider := new(mocks.MockPaymentProvider)
+	mockProvider.On("Name").Return("mock")
+	providers := NewPaymentProviders()
+	providers.Register(mockProvider, "transfer", "ewallet")
+
+	// Execute
+	err := providers.Validate("transfer", "ewallet", "credit_card")
+
+	// Assert
+	assert.EqualError(t, err, "no payment provider for method credit_card")
+	assert.NoError(t, providers.Validate("transfer"))
+}
@@ -2,6 +2,8 @@ package usecase
 
 import (
 	"errors"
+	"log"
+	"strconv"
 
 	"go-commerce/internal/domain"
 	"go-commerce/pkg/utils"
@@ -12,6 +14,8 @@ type ProductUsecase struct {
 	photoRepo    domain.PhotoProdukRepository
 	storeRepo    domain.StoreRepository
 	categoryRepo domain.CategoryRepository
+	searchIndex  domain.ProductSearchIndex
+	suggestions  *SearchSuggestUsecase
 }
 
 func NewProductUsecase(
@@ -19,12 +23,16 @@ func NewProductUsecase(
 	photoRepo domain.PhotoProdukRepository,
 	storeRepo domain.StoreRepository,
 	categoryRepo domain.CategoryRepository,
+	searchIndex domain.ProductSearchIndex,
+	suggestions *SearchSuggestUsecase,
 ) *ProductUsecase {
 	return &ProductUsecase{
 		productRepo:  productRepo,
 		photoRepo:    photoRepo,
 		storeRepo:    storeRepo,
 		categoryRepo: categoryRepo,
+		searchIndex:  searchIndex,
+		suggestions:  suggestions,
 	}
 }
 
@@ -85,10 +93,7 @@ func (u *ProductUsecase) CreateProduct(userID uint64, req *domain.CreateProductR
 	}
 
 	// Update search index async
-	go func() {
-		// Simulate updating search index
-		// updateSearchIndex(product.ID, product.NamaProduk)
-	}()
+	go u.indexProduct(createdProduct)
 
 	return createdProduct, nil
 }
@@ -146,7 +151,7 @@ func (u *ProductUsecase) GetAllProducts(filter *domain.ProductFilter) ([]*domain
 	}
 
 	// Use advanced filtering if available, otherwise fallback to basic
-	if filter.MinPrice != "" || filter.MaxPrice != "" ||
+	if filter.MinPrice != "" || filter.MaxPrice != "" || filter.StoreID != "" || filter.MinRating != "" ||
 		(filter.SortBy != "" && filter.SortBy != "newest") {
 		return u.productRepo.GetAllWithFilter(filter)
 	}
@@ -156,6 +161,133 @@ func (u *ProductUsecase) GetAllProducts(filter *domain.ProductFilter) ([]*domain
 	return u.productRepo.GetAll(filter.Limit, offset, filter.Search, filter.CategoryID)
 }
 
+// GetMyProductsByCursor pages the products of the user's store by cursor,
+// newest first unless page.SortBy asks otherwise
+func (u *ProductUsecase) GetMyProductsByCursor(userID uint64, search string, page *domain.CursorPage) ([]*domain.Product, *domain.CursorLinks, error) {
+	store, err := u.storeRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, nil, errors.New("store not found")
+	}
+
+	if err := normalizeCursorPage[domain.Product](page, "newest"); err != nil {
+		return nil, nil, err
+	}
+
+	products, err := u.productRepo.GetByTokoIDWithCursor(store.ID, search, page)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	products, links := cursorResult(products, page)
+	return products, links, nil
+}
+
+// GetAllProductsByCursor pages the active products matching filter by
+// cursor, newest first unless page.SortBy asks otherwise. Relevance has no
+// stable key to page by, so searches use GetAllProducts or SearchProducts.
+func (u *ProductUsecase) GetAllProductsByCursor(filter *domain.ProductFilter, page *domain.CursorPage) ([]*domain.Product, *domain.CursorLinks, error) {
+	if err := normalizeCursorPage[domain.Product](page, "newest"); err != nil {
+		return nil, nil, err
+	}
+
+	products, err := u.productRepo.GetAllWithCursor(filter, page)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	products, links := cursorResult(products, page)
+	return products, links, nil
+}
+
+// SearchProducts finds products through the search index, ranked by
+// relevance unless filter.SortBy asks otherwise, with facet counts over all
+// matches. Unparsable category, store, price and rating filters are ignored.
+func (u *ProductUsecase) SearchProducts(filter *domain.ProductFilter) ([]*domain.Product, int64, *domain.ProductSearchFacets, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 || filter.Limit > 100 {
+		filter.Limit = 10
+	}
+
+	query := &domain.ProductSearchQuery{
+		Query:  filter.Search,
+		SortBy: filter.SortBy,
+		Limit:  filter.Limit,
+		Offset: (filter.Page - 1) * filter.Limit,
+	}
+	query.CategoryID, _ = strconv.ParseUint(filter.CategoryID, 10, 64)
+	query.StoreID, _ = strconv.ParseUint(filter.StoreID, 10, 64)
+	if price, err := strconv.ParseFloat(filter.MinPrice, 64); err == nil {
+		query.MinPrice = &price
+	}
+	if price, err := strconv.ParseFloat(filter.MaxPrice, 64); err == nil {
+		query.MaxPrice = &price
+	}
+	if rating, err := strconv.ParseFloat(filter.MinRating, 64); err == nil {
+		query.MinRating = &rating
+	}
+
+	if u.searchIndex == nil {
+		products, total, err := u.GetAllProducts(filter)
+		return products, total, nil, err
+	}
+
+	result, err := u.searchIndex.Search(query)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	products, err := u.productRepo.GetByIDs(result.ProductIDs)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	// Count each search once, not once per page
+	if u.suggestions != nil && filter.Page == 1 {
+		go u.suggestions.LogQuery(filter.Search)
+	}
+
+	return products, result.Total, result.Facets, nil
+}
+
+// RebuildSearchIndex indexes every active product. In-process indexes start
+// empty and need this once at startup.
+func (u *ProductUsecase) RebuildSearchIndex() (int, error) {
+	const batchSize = 100
+
+	indexed := 0
+	for offset := 0; ; offset += batchSize {
+		products, _, err := u.productRepo.GetAll(batchSize, offset, "", "")
+		if err != nil {
+			return indexed, err
+		}
+		for _, product := range products {
+			if err := u.searchIndex.Index(product); err != nil {
+				return indexed, err
+			}
+			indexed++
+		}
+		if len(products) < batchSize {
+			return indexed, nil
+		}
+	}
+}
+
+// indexProduct refreshes product in the search index and suggestions. It
+// runs in the background, so failures are only logged.
+func (u *ProductUsecase) indexProduct(product *domain.Product) {
+	if u.suggestions != nil {
+		u.suggestions.ProductChanged(product)
+	}
+	if u.searchIndex == nil {
+		return
+	}
+	if err := u.searchIndex.Index(product); err != nil {
+		log.Printf("Error indexing product %d: %v", product.ID, err)
+	}
+}
+
 func (u *ProductUsecase) UpdateProduct(userID, productID uint64, req *domain.UpdateProductRequest) (*domain.Product, error) {
 	// Get user's store
 	store, err := u.storeRepo.GetByUserID(userID)
@@ -242,7 +374,15 @@ func (u *ProductUsecase) UpdateProduct(userID, productID uint64, req *domain.Upd
 		}()
 	}
 
-	return u.productRepo.GetByIDForManagement(productID)
+	updatedProduct, err := u.productRepo.GetByIDForManagement(productID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Update search index async
+	go u.indexProduct(updatedProduct)
+
+	return updatedProduct, nil
 }
 
 func (u *ProductUsecase) DeleteProduct(userID, productID uint64) error {
@@ -275,6 +415,18 @@ func (u *ProductUsecase) DeleteProduct(userID, productID uint64) error {
 		u.categoryRepo.UpdateHasActiveProduct(categoryID)
 	}()
 
+	// Update search index async
+	if u.suggestions != nil {
+		u.suggestions.ProductRemoved(productID)
+	}
+	if u.searchIndex != nil {
+		go func() {
+			if err := u.searchIndex.Remove(productID); err != nil {
+				log.Printf("Error removing product %d from search index: %v", productID, err)
+			}
+		}()
+	}
+
 	return nil
 }
 
@@ -415,7 +567,7 @@ func (u *ProductUsecase) ActivateProduct(userID, productID uint64) error {
 	}
 
 	// Check stock (optional reject based on business requirement)
-	if product.Stok <= 0 {
+	if product.TotalStock() <= 0 {
 		return errors.New("cannot activate product: out of stock") // 409 conflict
 	}
 
@@ -430,6 +582,9 @@ func (u *ProductUsecase) ActivateProduct(userID, productID uint64) error {
 		u.categoryRepo.UpdateHasActiveProduct(product.IDCategory)
 	}()
 
+	// Update search index async
+	go u.indexProduct(product)
+
 	return nil
 }
 
@@ -469,6 +624,9 @@ func (u *ProductUsecase) DeactivateProduct(userID, productID uint64) error {
 		u.categoryRepo.UpdateHasActiveProduct(product.IDCategory)
 	}()
 
+	// Update search index async
+	go u.indexProduct(product)
+
 	return nil
 }
 
@@ -495,6 +653,9 @@ func (u *ProductUsecase) SuspendProduct(productID uint64) error {
 		u.categoryRepo.UpdateHasActiveProduct(product.IDCategory)
 	}()
 
+	// Update search index async
+	go u.indexProduct(product)
+
 	return nil
 }
 
@@ -521,5 +682,8 @@ func (u *ProductUsecase) UnsuspendProduct(productID uint64) error {
 		u.categoryRepo.UpdateHasActiveProduct(product.IDCategory)
 	}()
 
+	// Update search index async
+	go u.indexProduct(product)
+
 	return nil
 }
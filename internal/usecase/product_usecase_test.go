@@ -3,6 +3,7 @@ package usecase
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"go-commerce/internal/domain"
 	"go-commerce/internal/usecase/mocks"
@@ -18,7 +19,7 @@ func TestProductUsecase_CreateProduct_Success(t *testing.T) {
 	storeRepo := new(mocks.StoreRepositoryMock)
 	categoryRepo := new(mocks.CategoryRepositoryMock)
 
-	usecase := NewProductUsecase(productRepo, photoRepo, storeRepo, categoryRepo)
+	usecase := NewProductUsecase(productRepo, photoRepo, storeRepo, categoryRepo, nil, nil)
 
 	// Test data
 	userID := uint64(1)
@@ -82,7 +83,7 @@ func TestProductUsecase_CreateProduct_StoreNotFound(t *testing.T) {
 	storeRepo := new(mocks.StoreRepositoryMock)
 	categoryRepo := new(mocks.CategoryRepositoryMock)
 
-	usecase := NewProductUsecase(productRepo, photoRepo, storeRepo, categoryRepo)
+	usecase := NewProductUsecase(productRepo, photoRepo, storeRepo, categoryRepo, nil, nil)
 
 	// Test data
 	userID := uint64(1)
@@ -114,7 +115,7 @@ func TestProductUsecase_CreateProduct_CategoryNotFound(t *testing.T) {
 	storeRepo := new(mocks.StoreRepositoryMock)
 	categoryRepo := new(mocks.CategoryRepositoryMock)
 
-	usecase := NewProductUsecase(productRepo, photoRepo, storeRepo, categoryRepo)
+	usecase := NewProductUsecase(productRepo, photoRepo, storeRepo, categoryRepo, nil, nil)
 
 	// Test data
 	userID := uint64(1)
@@ -149,7 +150,7 @@ func TestProductUsecase_GetMyProducts_Success(t *testing.T) {
 	storeRepo := new(mocks.StoreRepositoryMock)
 	categoryRepo := new(mocks.CategoryRepositoryMock)
 
-	usecase := NewProductUsecase(productRepo, photoRepo, storeRepo, categoryRepo)
+	usecase := NewProductUsecase(productRepo, photoRepo, storeRepo, categoryRepo, nil, nil)
 
 	// Test data
 	userID := uint64(1)
@@ -183,7 +184,7 @@ func TestProductUsecase_UpdateProduct_Success(t *testing.T) {
 	storeRepo := new(mocks.StoreRepositoryMock)
 	categoryRepo := new(mocks.CategoryRepositoryMock)
 
-	usecase := NewProductUsecase(productRepo, photoRepo, storeRepo, categoryRepo)
+	usecase := NewProductUsecase(productRepo, photoRepo, storeRepo, categoryRepo, nil, nil)
 
 	// Test data
 	userID := uint64(1)
@@ -235,4 +236,153 @@ func TestProductUsecase_UpdateProduct_Success(t *testing.T) {
 	storeRepo.AssertExpectations(t)
 	productRepo.AssertExpectations(t)
 	categoryRepo.AssertExpectations(t)
-}
\ No newline at end of file
+}
+
+func TestProductUsecase_CreateProduct_IndexesForSearch(t *testing.T) {
+	// Setup mocks
+	productRepo := new(mocks.ProductRepositoryMock)
+	photoRepo := new(mocks.PhotoProdukRepositoryMock)
+	storeRepo := new(mocks.StoreRepositoryMock)
+	categoryRepo := new(mocks.CategoryRepositoryMock)
+	searchIndex := new(mocks.MockProductSearchIndex)
+
+	usecase := NewProductUsecase(productRepo, photoRepo, storeRepo, categoryRepo, searchIndex, nil)
+
+	req := &domain.CreateProductRequest{NamaProduk: "Kemeja Flanel", HargaKonsumen: 150000, IDCategory: 1}
+	created := &domain.Product{ID: 7, NamaProduk: req.NamaProduk, Status: "active"}
+	indexed := make(chan *domain.Product, 1)
+
+	// Setup expectations
+	storeRepo.On("GetByUserID", uint64(1)).Return(&domain.Store{ID: 1, UserID: 1}, nil)
+	categoryRepo.On("GetByID", uint64(1)).Return(&domain.Category{ID: 1, Status: "active", IsLeaf: true}, nil)
+	categoryRepo.On("UpdateHasActiveProduct", uint64(1)).Return(nil).Maybe()
+	productRepo.On("GetBySlug", "kemeja-flanel").Return(nil, errors.New("not found"))
+	productRepo.On("Create", mock.AnythingOfType("*domain.Product")).Return(nil)
+	productRepo.On("GetByID", mock.AnythingOfType("uint64")).Return(created, nil)
+	searchIndex.On("Index", created).Run(func(args mock.Arguments) {
+		indexed <- args.Get(0).(*domain.Product)
+	}).Return(nil)
+
+	// Execute
+	_, err := usecase.CreateProduct(1, req)
+
+	// Assert
+	assert.NoError(t, err)
+	select {
+	case product := <-indexed:
+		assert.Equal(t, uint64(7), product.ID)
+	case <-time.After(time.Second):
+		t.Fatal("product was not indexed")
+	}
+}
+
+func TestProductUsecase_SearchProducts_KeepsIndexOrder(t *testing.T) {
+	// Setup mocks
+	productRepo := new(mocks.ProductRepositoryMock)
+	searchIndex := new(mocks.MockProductSearchIndex)
+
+	usecase := NewProductUsecase(productRepo, new(mocks.PhotoProdukRepositoryMock), new(mocks.StoreRepositoryMock), new(mocks.CategoryRepositoryMock), searchIndex, nil)
+
+	filter := &domain.ProductFilter{
+		Search:     "kemeja pria",
+		CategoryID: "10",
+		MinPrice:   "50000",
+		MaxPrice:   "not-a-number",
+		Page:       2,
+		Limit:      5,
+	}
+	facets := &domain.ProductSearchFacets{
+		Categories: []domain.FacetCount{{Value: "10", Label: "Kemeja", Count: 7}},
+	}
+	products := []*domain.Product{{ID: 3}, {ID: 1}}
+
+	// Setup expectations
+	searchIndex.On("Search", mock.MatchedBy(func(q *domain.ProductSearchQuery) bool {
+		return q.Query == "kemeja pria" && q.CategoryID == 10 && q.StoreID == 0 &&
+			q.MinPrice != nil && *q.MinPrice == 50000 && q.MaxPrice == nil &&
+			q.Limit == 5 && q.Offset == 5
+	})).Return(&domain.ProductSearchResult{ProductIDs: []uint64{3, 1}, Total: 7, Facets: facets}, nil)
+	productRepo.On("GetByIDs", []uint64{3, 1}).Return(products, nil)
+
+	// Execute
+	result, total, resultFacets, err := usecase.SearchProducts(filter)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, products, result)
+	assert.Equal(t, int64(7), total)
+	assert.Equal(t, facets, resultFacets)
+	searchIndex.AssertExpectations(t)
+	productRepo.AssertExpectations(t)
+}
+
+func TestProductUsecase_GetAllProductsByCursor_PagesForwardAndBack(t *testing.T) {
+	// Setup mocks
+	productRepo := new(mocks.ProductRepositoryMock)
+	usecase := NewProductUsecase(productRepo, nil, nil, nil, nil, nil)
+
+	products := []*domain.Product{
+		{ID: 5, HargaKonsumen: 10000},
+		{ID: 3, HargaKonsumen: 20000},
+		{ID: 4, HargaKonsumen: 30000},
+	}
+	filter := &domain.ProductFilter{CategoryID: "2"}
+
+	// Mock expectations: the repository reads one row more than the limit
+	productRepo.On("GetAllWithCursor", filter, mock.MatchedBy(func(page *domain.CursorPage) bool {
+		return page.Cursor == nil && page.SortBy == "price_asc" && page.Limit == 2
+	})).Return(products, nil).Once()
+
+	// Execute: first page
+	page := &domain.CursorPage{SortBy: "price_asc", Limit: 2}
+	result, links, err := usecase.GetAllProductsByCursor(filter, page)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, products[:2], result)
+	assert.Nil(t, links.Prev)
+	assert.Equal(t, &domain.Cursor{SortBy: "price_asc", Key: "20000", ID: 3}, links.Next)
+
+	// Mock expectations: walking back from the last row reads it nearest first
+	productRepo.On("GetAllWithCursor", filter, mock.MatchedBy(func(page *domain.CursorPage) bool {
+		return page.Cursor != nil && page.Cursor.Backward
+	})).Return([]*domain.Product{products[1], products[0]}, nil).Once()
+
+	// Execute: the page before the third row
+	page = &domain.CursorPage{Limit: 2, SortBy: "price_asc", Cursor: &domain.Cursor{SortBy: "price_asc", Key: "30000", ID: 4, Backward: true}}
+	result, links, err = usecase.GetAllProductsByCursor(filter, page)
+
+	// Assert: back in list order, with nothing before it
+	assert.NoError(t, err)
+	assert.Equal(t, products[:2], result)
+	assert.Nil(t, links.Prev)
+	assert.Equal(t, &domain.Cursor{SortBy: "price_asc", Key: "20000", ID: 3}, links.Next)
+	productRepo.AssertExpectations(t)
+}
+
+func TestProductUsecase_GetAllProductsByCursor_LastPageAndUnsupportedSort(t *testing.T) {
+	// Setup mocks
+	productRepo := new(mocks.ProductRepositoryMock)
+	usecase := NewProductUsecase(productRepo, nil, nil, nil, nil, nil)
+	createdAt := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	// Mock expectations
+	productRepo.On("GetAllWithCursor", mock.Anything, mock.Anything).
+		Return([]*domain.Product{{ID: 7, CreatedAt: createdAt}}, nil)
+
+	// Execute: a forward page without a following row is the last one
+	page := &domain.CursorPage{Cursor: &domain.Cursor{SortBy: "newest", Key: createdAt.Add(time.Hour).Format(time.RFC3339Nano), ID: 9}}
+	result, links, err := usecase.GetAllProductsByCursor(&domain.ProductFilter{}, page)
+
+	// Assert: defaults applied, and only a way back
+	assert.NoError(t, err)
+	assert.Len(t, result, 1)
+	assert.Equal(t, "newest", page.SortBy)
+	assert.Equal(t, 10, page.Limit)
+	assert.Nil(t, links.Next)
+	assert.Equal(t, &domain.Cursor{SortBy: "newest", Key: "2026-03-01T10:00:00Z", ID: 7, Backward: true}, links.Prev)
+
+	// Relevance has no key to page by
+	_, _, err = usecase.GetAllProductsByCursor(&domain.ProductFilter{}, &domain.CursorPage{SortBy: "relevance"})
+	assert.EqualError(t, err, "INVALID_SORT_BY")
+}
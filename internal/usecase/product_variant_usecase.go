@@ -0,0 +1,266 @@
+package usecase
+
+import (
+	"errors"
+
+	"go-commerce/internal/domain"
+)
+
+// ProductVariantUsecase lets sellers split a product into variants, e.g. per
+// size and color. The options of a product list the values a variant can
+// pick; every variant picks exactly one value of each option and has its own
+// SKU and stock.
+type ProductVariantUsecase struct {
+	variantRepo domain.ProductVariantRepository
+	productRepo domain.ProductRepository
+	photoRepo   domain.PhotoProdukRepository
+	storeRepo   domain.StoreRepository
+}
+
+func NewProductVariantUsecase(
+	variantRepo domain.ProductVariantRepository,
+	productRepo domain.ProductRepository,
+	photoRepo domain.PhotoProdukRepository,
+	storeRepo domain.StoreRepository,
+) *ProductVariantUsecase {
+	return &ProductVariantUsecase{
+		variantRepo: variantRepo,
+		productRepo: productRepo,
+		photoRepo:   photoRepo,
+		storeRepo:   storeRepo,
+	}
+}
+
+// SetOptions replaces the options of a product. Existing variants must still
+// fit the new options, so values in use cannot be removed.
+func (u *ProductVariantUsecase) SetOptions(userID, productID uint64, req *domain.SetProductOptionsRequest) ([]*domain.ProductOption, error) {
+	if err := u.checkOwnership(userID, productID); err != nil {
+		return nil, err
+	}
+
+	options := make([]*domain.ProductOption, 0, len(req.Options))
+	names := make(map[string]bool, len(req.Options))
+	for i, optionReq := range req.Options {
+		if names[optionReq.Name] {
+			return nil, errors.New("DUPLICATE_OPTION")
+		}
+		names[optionReq.Name] = true
+
+		values := make(map[string]bool, len(optionReq.Values))
+		for _, value := range optionReq.Values {
+			if values[value] {
+				return nil, errors.New("DUPLICATE_OPTION_VALUE")
+			}
+			values[value] = true
+		}
+
+		options = append(options, &domain.ProductOption{
+			IDProduk: productID,
+			Name:     optionReq.Name,
+			Values:   optionReq.Values,
+			Position: i + 1,
+		})
+	}
+
+	variants, err := u.variantRepo.GetByProductID(productID)
+	if err != nil {
+		return nil, err
+	}
+	for _, variant := range variants {
+		if !matchesOptions(options, variant.Attributes) {
+			return nil, errors.New("OPTIONS_IN_USE")
+		}
+	}
+
+	if err := u.variantRepo.ReplaceOptions(productID, options); err != nil {
+		return nil, err
+	}
+
+	return options, nil
+}
+
+// CreateVariant adds a SKU to a product. Its attributes must pick one value of
+// every option of the product, and no other variant may pick the same values.
+func (u *ProductVariantUsecase) CreateVariant(userID, productID uint64, req *domain.CreateProductVariantRequest) (*domain.ProductVariant, error) {
+	if err := u.checkOwnership(userID, productID); err != nil {
+		return nil, err
+	}
+
+	options, err := u.variantRepo.GetOptionsByProductID(productID)
+	if err != nil {
+		return nil, err
+	}
+	if len(options) == 0 {
+		return nil, errors.New("PRODUCT_HAS_NO_OPTIONS")
+	}
+	if !matchesOptions(options, req.Attributes) {
+		return nil, errors.New("INVALID_VARIANT_ATTRIBUTES")
+	}
+
+	variants, err := u.variantRepo.GetByProductID(productID)
+	if err != nil {
+		return nil, err
+	}
+	for _, variant := range variants {
+		if sameAttributes(variant.Attributes, req.Attributes) {
+			return nil, errors.New("VARIANT_ALREADY_EXISTS")
+		}
+	}
+
+	if err := u.checkSKU(req.SKU, 0); err != nil {
+		return nil, err
+	}
+	if err := u.checkPhoto(productID, req.IDFoto); err != nil {
+		return nil, err
+	}
+
+	variant := &domain.ProductVariant{
+		IDProduk:      productID,
+		SKU:           req.SKU,
+		Attributes:    req.Attributes,
+		HargaReseller: req.HargaReseller,
+		HargaKonsumen: req.HargaKonsumen,
+		Stok:          req.Stok,
+		Berat:         req.Berat,
+		IDFoto:        req.IDFoto,
+		Status:        getProductStatus(req.Status),
+	}
+
+	if err := u.variantRepo.Create(variant); err != nil {
+		return nil, err
+	}
+
+	return u.variantRepo.GetByID(variant.ID)
+}
+
+// UpdateVariant changes the SKU, price and weight overrides, stock, photo or
+// status of a variant. Its attributes cannot change; create another variant
+// instead.
+func (u *ProductVariantUsecase) UpdateVariant(userID, productID, variantID uint64, req *domain.UpdateProductVariantRequest) (*domain.ProductVariant, error) {
+	if err := u.checkOwnership(userID, productID); err != nil {
+		return nil, err
+	}
+
+	variant, err := u.getVariant(productID, variantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.SKU != nil && *req.SKU != variant.SKU {
+		if err := u.checkSKU(*req.SKU, variant.ID); err != nil {
+			return nil, err
+		}
+		variant.SKU = *req.SKU
+	}
+	if req.HargaReseller != nil {
+		variant.HargaReseller = req.HargaReseller
+	}
+	if req.HargaKonsumen != nil {
+		variant.HargaKonsumen = req.HargaKonsumen
+	}
+	if req.Stok != nil {
+		variant.Stok = *req.Stok
+	}
+	if req.Berat != nil {
+		variant.Berat = req.Berat
+	}
+	if req.IDFoto != nil {
+		if err := u.checkPhoto(productID, req.IDFoto); err != nil {
+			return nil, err
+		}
+		variant.IDFoto = req.IDFoto
+	}
+	if req.Status != nil {
+		variant.Status = *req.Status
+	}
+
+	if err := u.variantRepo.Update(variant); err != nil {
+		return nil, err
+	}
+
+	return u.variantRepo.GetByID(variant.ID)
+}
+
+// DeleteVariant removes a variant from sale. Stock it still holds for unpaid
+// checkouts is settled as usual.
+func (u *ProductVariantUsecase) DeleteVariant(userID, productID, variantID uint64) error {
+	if err := u.checkOwnership(userID, productID); err != nil {
+		return err
+	}
+
+	if _, err := u.getVariant(productID, variantID); err != nil {
+		return err
+	}
+
+	return u.variantRepo.Delete(variantID)
+}
+
+func (u *ProductVariantUsecase) checkOwnership(userID, productID uint64) error {
+	store, err := u.storeRepo.GetByUserID(userID)
+	if err != nil {
+		return errors.New("store not found")
+	}
+	return u.productRepo.CheckOwnership(productID, store.ID)
+}
+
+func (u *ProductVariantUsecase) getVariant(productID, variantID uint64) (*domain.ProductVariant, error) {
+	variant, err := u.variantRepo.GetByID(variantID)
+	if err != nil || variant.IDProduk != productID {
+		return nil, errors.New("variant not found")
+	}
+	return variant, nil
+}
+
+// checkSKU fails when another variant than variantID already uses sku
+func (u *ProductVariantUsecase) checkSKU(sku string, variantID uint64) error {
+	existing, err := u.variantRepo.GetBySKU(sku)
+	if err == nil && existing.ID != variantID {
+		return errors.New("SKU_ALREADY_EXISTS")
+	}
+	return nil
+}
+
+// checkPhoto fails unless photoID is empty or a photo of the product
+func (u *ProductVariantUsecase) checkPhoto(productID uint64, photoID *uint64) error {
+	if photoID == nil {
+		return nil
+	}
+
+	photos, err := u.photoRepo.GetByProductID(productID)
+	if err != nil {
+		return err
+	}
+	for _, photo := range photos {
+		if photo.ID == *photoID {
+			return nil
+		}
+	}
+	return errors.New("photo not found")
+}
+
+// matchesOptions reports whether attributes pick one value of every option
+// and nothing else
+func matchesOptions(options []*domain.ProductOption, attributes map[string]string) bool {
+	if len(attributes) != len(options) {
+		return false
+	}
+	for _, option := range options {
+		value, ok := attributes[option.Name]
+		if !ok || !option.HasValue(value) {
+			return false
+		}
+	}
+	return true
+}
+
+func sameAttributes(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, value := range a {
+		if b[name] != value {
+			return false
+		}
+	}
+	return true
+}
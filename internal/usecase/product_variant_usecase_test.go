@@ -0,0 +1,171 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+
+	"go-commerce/internal/domain"
+	"go-commerce/internal/usecase/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func testProductOptions() []*domain.ProductOption {
+	return []*domain.ProductOption{
+		{ID: 1, IDProduk: 1, Name: "Ukuran", Values: []string{"S", "M", "L"}, Position: 1},
+		{ID: 2, IDProduk: 1, Name: "Warna", Values: []string{"Hitam", "Putih"}, Position: 2},
+	}
+}
+
+func TestProductVariantUsecase_CreateVariant_Success(t *testing.T) {
+	// Setup
+	mockVariantRepo := new(mocks.MockProductVariantRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockPhotoRepo := new(mocks.PhotoProdukRepositoryMock)
+	mockStoreRepo := new(mocks.StoreRepositoryMock)
+	variantUsecase := NewProductVariantUsecase(mockVariantRepo, mockProductRepo, mockPhotoRepo, mockStoreRepo)
+	price := 90000.0
+	req := &domain.CreateProductVariantRequest{
+		SKU:           "KAOS-M-HTM",
+		Attributes:    map[string]string{"Ukuran": "M", "Warna": "Hitam"},
+		HargaKonsumen: &price,
+		Stok:          10,
+	}
+
+	var created *domain.ProductVariant
+
+	// Mock expectations - user 1 owns store 1, which sells product 1
+	mockStoreRepo.On("GetByUserID", uint64(1)).Return(&domain.Store{ID: 1, UserID: 1}, nil)
+	mockProductRepo.On("CheckOwnership", uint64(1), uint64(1)).Return(nil)
+	mockVariantRepo.On("GetOptionsByProductID", uint64(1)).Return(testProductOptions(), nil)
+	mockVariantRepo.On("GetByProductID", uint64(1)).Return([]*domain.ProductVariant{
+		{ID: 5, IDProduk: 1, SKU: "KAOS-S-HTM", Attributes: map[string]string{"Ukuran": "S", "Warna": "Hitam"}},
+	}, nil)
+	mockVariantRepo.On("GetBySKU", "KAOS-M-HTM").Return(nil, errors.New("variant not found"))
+	mockVariantRepo.On("Create", mock.AnythingOfType("*domain.ProductVariant")).Run(func(args mock.Arguments) {
+		created = args.Get(0).(*domain.ProductVariant)
+		created.ID = 6
+	}).Return(nil)
+	mockVariantRepo.On("GetByID", uint64(6)).Return(&domain.ProductVariant{ID: 6, IDProduk: 1, SKU: "KAOS-M-HTM"}, nil)
+
+	// Execute
+	variant, err := variantUsecase.CreateVariant(1, 1, req)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(6), variant.ID)
+	assert.Equal(t, "active", created.Status)
+	assert.Equal(t, 10, created.Stok)
+	assert.Equal(t, &price, created.HargaKonsumen)
+}
+
+func TestProductVariantUsecase_CreateVariant_RejectsInvalidAttributes(t *testing.T) {
+	cases := map[string]map[string]string{
+		"unknown value":  {"Ukuran": "XL", "Warna": "Hitam"},
+		"missing option": {"Ukuran": "M"},
+		"extra option":   {"Ukuran": "M", "Warna": "Hitam", "Bahan": "Katun"},
+	}
+
+	for name, attributes := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Setup
+			mockVariantRepo := new(mocks.MockProductVariantRepository)
+			mockProductRepo := new(mocks.ProductRepositoryMock)
+			mockPhotoRepo := new(mocks.PhotoProdukRepositoryMock)
+			mockStoreRepo := new(mocks.StoreRepositoryMock)
+			variantUsecase := NewProductVariantUsecase(mockVariantRepo, mockProductRepo, mockPhotoRepo, mockStoreRepo)
+
+			// Mock expectations - user 1 owns store 1, which sells product 1
+			mockStoreRepo.On("GetByUserID", uint64(1)).Return(&domain.Store{ID: 1, UserID: 1}, nil)
+			mockProductRepo.On("CheckOwnership", uint64(1), uint64(1)).Return(nil)
+			mockVariantRepo.On("GetOptionsByProductID", uint64(1)).Return(testProductOptions(), nil)
+
+			// Execute
+			variant, err := variantUsecase.CreateVariant(1, 1, &domain.CreateProductVariantRequest{SKU: "X", Attributes: attributes})
+
+			// Assert
+			assert.Nil(t, variant)
+			assert.EqualError(t, err, "INVALID_VARIANT_ATTRIBUTES")
+			mockVariantRepo.AssertNotCalled(t, "Create", mock.Anything)
+		})
+	}
+}
+
+func TestProductVariantUsecase_CreateVariant_DuplicateAttributes(t *testing.T) {
+	// Setup
+	mockVariantRepo := new(mocks.MockProductVariantRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockPhotoRepo := new(mocks.PhotoProdukRepositoryMock)
+	mockStoreRepo := new(mocks.StoreRepositoryMock)
+	variantUsecase := NewProductVariantUsecase(mockVariantRepo, mockProductRepo, mockPhotoRepo, mockStoreRepo)
+
+	// Mock expectations - user 1 owns store 1, which sells product 1
+	mockStoreRepo.On("GetByUserID", uint64(1)).Return(&domain.Store{ID: 1, UserID: 1}, nil)
+	mockProductRepo.On("CheckOwnership", uint64(1), uint64(1)).Return(nil)
+	mockVariantRepo.On("GetOptionsByProductID", uint64(1)).Return(testProductOptions(), nil)
+	mockVariantRepo.On("GetByProductID", uint64(1)).Return([]*domain.ProductVariant{
+		{ID: 5, IDProduk: 1, Attributes: map[string]string{"Ukuran": "M", "Warna": "Hitam"}},
+	}, nil)
+
+	// Execute
+	variant, err := variantUsecase.CreateVariant(1, 1, &domain.CreateProductVariantRequest{
+		SKU:        "KAOS-M-HTM-2",
+		Attributes: map[string]string{"Warna": "Hitam", "Ukuran": "M"},
+	})
+
+	// Assert
+	assert.Nil(t, variant)
+	assert.EqualError(t, err, "VARIANT_ALREADY_EXISTS")
+}
+
+func TestProductVariantUsecase_SetOptions_KeepsValuesInUse(t *testing.T) {
+	// Setup
+	mockVariantRepo := new(mocks.MockProductVariantRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockPhotoRepo := new(mocks.PhotoProdukRepositoryMock)
+	mockStoreRepo := new(mocks.StoreRepositoryMock)
+	variantUsecase := NewProductVariantUsecase(mockVariantRepo, mockProductRepo, mockPhotoRepo, mockStoreRepo)
+	req := &domain.SetProductOptionsRequest{Options: []domain.ProductOptionRequest{
+		{Name: "Ukuran", Values: []string{"S", "L"}},
+		{Name: "Warna", Values: []string{"Hitam", "Putih"}},
+	}}
+
+	// Mock expectations - user 1 owns store 1, which sells product 1
+	mockStoreRepo.On("GetByUserID", uint64(1)).Return(&domain.Store{ID: 1, UserID: 1}, nil)
+	mockProductRepo.On("CheckOwnership", uint64(1), uint64(1)).Return(nil)
+	mockVariantRepo.On("GetByProductID", uint64(1)).Return([]*domain.ProductVariant{
+		{ID: 5, IDProduk: 1, Attributes: map[string]string{"Ukuran": "M", "Warna": "Hitam"}},
+	}, nil)
+
+	// Execute
+	options, err := variantUsecase.SetOptions(1, 1, req)
+
+	// Assert
+	assert.Nil(t, options)
+	assert.EqualError(t, err, "OPTIONS_IN_USE")
+	mockVariantRepo.AssertNotCalled(t, "ReplaceOptions", mock.Anything, mock.Anything)
+}
+
+func TestProductVariantUsecase_UpdateVariant_OtherProduct(t *testing.T) {
+	// Setup
+	mockVariantRepo := new(mocks.MockProductVariantRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockPhotoRepo := new(mocks.PhotoProdukRepositoryMock)
+	mockStoreRepo := new(mocks.StoreRepositoryMock)
+	variantUsecase := NewProductVariantUsecase(mockVariantRepo, mockProductRepo, mockPhotoRepo, mockStoreRepo)
+	stock := 3
+
+	// Mock expectations - user 1 owns store 1, which sells product 1
+	mockStoreRepo.On("GetByUserID", uint64(1)).Return(&domain.Store{ID: 1, UserID: 1}, nil)
+	mockProductRepo.On("CheckOwnership", uint64(1), uint64(1)).Return(nil)
+	mockVariantRepo.On("GetByID", uint64(9)).Return(&domain.ProductVariant{ID: 9, IDProduk: 2}, nil)
+
+	// Execute
+	variant, err := variantUsecase.UpdateVariant(1, 1, 9, &domain.UpdateProductVariantRequest{Stok: &stock})
+
+	// Assert
+	assert.Nil(t, variant)
+	assert.EqualError(t, err, "variant not found")
+	mockVariantRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
@@ -0,0 +1,341 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+
+	"go-commerce/internal/domain"
+)
+
+// RefundUsecase keeps the refund ledger of paid orders. Refunds of one order
+// run while the order row is locked, so its refundable balance and the
+// refundable quantity of its items can never be exceeded.
+type RefundUsecase struct {
+	refundRepo           domain.RefundRepository
+	transactionRepo      domain.TransactionRepository
+	transactionItemRepo  domain.TransactionItemRepository
+	checkoutRepo         domain.CheckoutRepository
+	productLogRepo       domain.ProductLogRepository
+	productRepo          domain.ProductRepository
+	orderStatus          *OrderStatusUsecase
+	paymentIntentUsecase domain.PaymentIntentUsecase
+}
+
+func NewRefundUsecase(
+	refundRepo domain.RefundRepository,
+	transactionRepo domain.TransactionRepository,
+	transactionItemRepo domain.TransactionItemRepository,
+	checkoutRepo domain.CheckoutRepository,
+	productLogRepo domain.ProductLogRepository,
+	productRepo domain.ProductRepository,
+	orderStatus *OrderStatusUsecase,
+	paymentIntentUsecase domain.PaymentIntentUsecase,
+) *RefundUsecase {
+	return &RefundUsecase{
+		refundRepo:           refundRepo,
+		transactionRepo:      transactionRepo,
+		transactionItemRepo:  transactionItemRepo,
+		checkoutRepo:         checkoutRepo,
+		productLogRepo:       productLogRepo,
+		productRepo:          productRepo,
+		orderStatus:          orderStatus,
+		paymentIntentUsecase: paymentIntentUsecase,
+	}
+}
+
+// RefundTransaction - Admin refunds part or all of a paid order. Refunded
+// items give their stock and sold count back. Once the whole order total is
+// refunded the order is marked refunded. The refund is committed to the
+// ledger as pending before the payment provider returns the money, so a
+// crash in between leaves a refund to retry rather than money returned
+// without a record.
+func (u *RefundUsecase) RefundTransaction(actorID, transactionID uint64, req *domain.RefundRequest) (*domain.Refund, error) {
+	dbTx, err := u.transactionRepo.BeginTx()
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			u.transactionRepo.RollbackTx(dbTx)
+			panic(r)
+		}
+	}()
+
+	refund, err := u.RefundWithTx(dbTx, actorID, transactionID, req)
+	if err != nil {
+		u.transactionRepo.RollbackTx(dbTx)
+		return nil, err
+	}
+
+	if err := u.transactionRepo.CommitTx(dbTx); err != nil {
+		return nil, err
+	}
+
+	return u.SendRefund(refund)
+}
+
+// SendRefund asks the payment provider to return the money of a committed
+// refund and records the outcome. The refund ID is the idempotency key, so
+// sending a refund again never pays out twice.
+func (u *RefundUsecase) SendRefund(refund *domain.Refund) (*domain.Refund, error) {
+	providerRef, err := u.paymentIntentUsecase.RefundPayment(uint(refund.CheckoutID), refund.Amount, fmt.Sprintf("refund-%d", refund.ID))
+	if err != nil {
+		log.Printf("Error refunding transaction %d at the payment provider: %v", refund.TransactionID, err)
+		if err := u.refundRepo.UpdateStatus(refund.ID, domain.RefundStatusFailed, ""); err != nil {
+			log.Printf("Error marking refund %d failed: %v", refund.ID, err)
+		}
+		return nil, errors.New("PAYMENT_REFUND_FAILED")
+	}
+
+	if err := u.refundRepo.UpdateStatus(refund.ID, domain.RefundStatusCompleted, providerRef); err != nil {
+		return nil, err
+	}
+	refund.Status = domain.RefundStatusCompleted
+	refund.ProviderRef = providerRef
+
+	return refund, nil
+}
+
+// RetryRefund sends a pending or failed refund to the payment provider again
+func (u *RefundUsecase) RetryRefund(refundID uint64) (*domain.Refund, error) {
+	refund, err := u.refundRepo.GetByID(refundID)
+	if err != nil {
+		return nil, errors.New("refund not found")
+	}
+
+	if refund.Status == domain.RefundStatusCompleted {
+		return nil, errors.New("REFUND_ALREADY_COMPLETED")
+	}
+
+	return u.SendRefund(refund)
+}
+
+// GetTransactionRefunds returns the refund ledger and refundable balance of
+// an order
+func (u *RefundUsecase) GetTransactionRefunds(transactionID uint64) (*domain.TransactionRefunds, error) {
+	transaction, err := u.transactionRepo.GetByID(transactionID)
+	if err != nil {
+		return nil, errors.New("transaction not found")
+	}
+
+	refunds, err := u.refundRepo.GetByTransactionID(transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.TransactionRefunds{
+		TransactionID:    transaction.ID,
+		HargaTotal:       transaction.HargaTotal,
+		RefundedAmount:   transaction.RefundedAmount,
+		RefundableAmount: roundCents(transaction.RefundableAmount()),
+		Refunds:          refunds,
+	}, nil
+}
+
+// RefundWithTx records a pending refund within the caller's database
+// transaction, e.g. when an admin settles a dispute with a refund. Once the
+// transaction is committed the caller sends it with SendRefund.
+func (u *RefundUsecase) RefundWithTx(dbTx interface{}, actorID, transactionID uint64, req *domain.RefundRequest) (*domain.Refund, error) {
+	transaction, err := u.transactionRepo.GetByIDWithLock(dbTx, transactionID)
+	if err != nil {
+		return nil, errors.New("transaction not found")
+	}
+
+	// Can only refund paid transactions
+	if transaction.Status != domain.PaymentStatusPaid {
+		return nil, errors.New("NOT_REFUNDABLE")
+	}
+
+	balance := roundCents(transaction.RefundableAmount())
+
+	items, err := u.refundItems(transaction, req)
+	if err != nil {
+		return nil, err
+	}
+
+	amount := roundCents(req.Amount)
+	if amount == 0 {
+		if len(req.Items) == 0 {
+			amount = balance
+		} else {
+			for _, item := range items {
+				amount += item.Amount
+			}
+			amount = roundCents(amount)
+		}
+	}
+	if amount <= 0 {
+		return nil, errors.New("NOT_REFUNDABLE")
+	}
+	if amount > balance {
+		return nil, errors.New("REFUND_EXCEEDS_BALANCE")
+	}
+
+	if err := u.restockWithTx(dbTx, items); err != nil {
+		return nil, err
+	}
+
+	err = u.transactionRepo.AddRefundedAmountWithTx(dbTx, transactionID, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	if amount == balance {
+		admin := domain.StatusActor{Role: domain.StatusActorAdmin, UserID: actorID}
+		if err := u.closeRefundedWithTx(dbTx, transaction, admin, req.Reason); err != nil {
+			return nil, err
+		}
+	}
+
+	refund := &domain.Refund{
+		TransactionID: transaction.ID,
+		CheckoutID:    transaction.CheckoutID,
+		Amount:        amount,
+		Reason:        req.Reason,
+		RefundedBy:    actorID,
+		Status:        domain.RefundStatusPending,
+		Items:         items,
+	}
+	if err := u.refundRepo.CreateWithTx(dbTx, refund); err != nil {
+		return nil, err
+	}
+
+	return refund, nil
+}
+
+// refundItems resolves the requested items of the order. Without items and
+// amount everything not refunded yet is returned; an amount alone refunds
+// money only.
+func (u *RefundUsecase) refundItems(transaction *domain.Transaction, req *domain.RefundRequest) ([]*domain.RefundItem, error) {
+	requested := make(map[uint64]int)
+	var order []uint64
+	if len(req.Items) == 0 && req.Amount == 0 {
+		for _, item := range transaction.TransactionItems {
+			if remaining := item.Quantity - item.RefundedQuantity; remaining > 0 {
+				requested[item.ID] = remaining
+				order = append(order, item.ID)
+			}
+		}
+	}
+	for _, item := range req.Items {
+		if _, ok := requested[item.TransactionItemID]; !ok {
+			order = append(order, item.TransactionItemID)
+		}
+		requested[item.TransactionItemID] += item.Quantity
+	}
+
+	itemsByID := make(map[uint64]*domain.TransactionItem, len(transaction.TransactionItems))
+	for _, item := range transaction.TransactionItems {
+		itemsByID[item.ID] = item
+	}
+
+	items := make([]*domain.RefundItem, 0, len(order))
+	for _, id := range order {
+		item, ok := itemsByID[id]
+		if !ok {
+			return nil, errors.New("transaction item not found")
+		}
+
+		quantity := requested[id]
+		if quantity > item.Quantity-item.RefundedQuantity {
+			return nil, errors.New("REFUND_EXCEEDS_QUANTITY")
+		}
+
+		productLog, err := u.productLogRepo.GetByID(item.ProductLogID)
+		if err != nil {
+			return nil, errors.New("product log not found")
+		}
+
+		items = append(items, &domain.RefundItem{
+			TransactionItemID: item.ID,
+			ProductID:         productLog.ProductID,
+			VariantID:         productLog.VariantID,
+			Quantity:          quantity,
+			Amount:            roundCents(item.HargaSatuan * float64(quantity)),
+		})
+	}
+
+	return items, nil
+}
+
+// restockWithTx gives the stock and sold count of refunded items back
+func (u *RefundUsecase) restockWithTx(dbTx interface{}, items []*domain.RefundItem) error {
+	quantities := make(map[stockKey]int)
+	sold := make(map[uint64]int)
+	for _, item := range items {
+		err := u.transactionItemRepo.AddRefundedQuantityWithTx(dbTx, item.TransactionItemID, item.Quantity)
+		if err != nil {
+			return err
+		}
+		quantities[stockKey{ProductID: item.ProductID, VariantID: item.VariantID}] += item.Quantity
+		sold[item.ProductID] += item.Quantity
+	}
+
+	// Same order as the stock reservations to avoid deadlocks
+	for _, key := range sortedStockKeys(quantities) {
+		if err := u.productRepo.UpdateStockWithTx(dbTx, key.ProductID, key.VariantID, -quantities[key]); err != nil {
+			return err
+		}
+	}
+	for _, productID := range sortedProductIDs(sold) {
+		if err := u.productRepo.UpdateSoldCountWithTx(dbTx, productID, -sold[productID]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// closeRefundedWithTx marks a fully refunded order, and its checkout once none
+// of its orders remains paid. Orders that were not shipped yet are cancelled;
+// shipped and delivered ones keep their fulfilment status.
+func (u *RefundUsecase) closeRefundedWithTx(dbTx interface{}, transaction *domain.Transaction, actor domain.StatusActor, reason string) error {
+	if reason == "" {
+		reason = "refunded"
+	}
+
+	err := u.orderStatus.TransitionWithTx(dbTx, transaction.ID, StatusChange{
+		Field:  domain.StatusFieldPayment,
+		From:   domain.PaymentStatusPaid,
+		To:     domain.PaymentStatusRefunded,
+		Actor:  actor,
+		Reason: reason,
+	})
+	if err != nil {
+		return err
+	}
+
+	if domain.CanTransition(domain.StatusFieldOrder, transaction.OrderStatus, domain.OrderStatusCancelled) {
+		err = u.orderStatus.TransitionWithTx(dbTx, transaction.ID, StatusChange{
+			Field:  domain.StatusFieldOrder,
+			From:   transaction.OrderStatus,
+			To:     domain.OrderStatusCancelled,
+			Actor:  actor,
+			Reason: reason,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	checkout, err := u.checkoutRepo.GetByID(transaction.CheckoutID)
+	if err != nil {
+		return errors.New("checkout not found")
+	}
+
+	for _, order := range checkout.Transactions {
+		if order.ID != transaction.ID && order.Status == domain.PaymentStatusPaid {
+			return nil
+		}
+	}
+
+	return u.checkoutRepo.UpdateStatusWithTx(dbTx, checkout.ID, "refunded")
+}
+
+// roundCents rounds an amount to whole cents, the precision of the ledger
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,348 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+
+	"go-commerce/internal/domain"
+	"go-commerce/internal/usecase/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// refundTestTransaction is a paid order of 3 x 10000 (product 7) and 1 x 5000
+// (product 8), where one unit of the first item was refunded already
+func refundTestTransaction() *domain.Transaction {
+	return &domain.Transaction{
+		ID:             1,
+		CheckoutID:     9,
+		HargaTotal:     35000,
+		RefundedAmount: 10000,
+		Status:         "paid",
+		OrderStatus:    "delivered",
+		TransactionItems: []*domain.TransactionItem{
+			{ID: 11, ProductLogID: 70, Quantity: 3, RefundedQuantity: 1, HargaSatuan: 10000, HargaTotal: 30000},
+			{ID: 12, ProductLogID: 80, Quantity: 1, HargaSatuan: 5000, HargaTotal: 5000},
+		},
+	}
+}
+
+func TestRefundUsecase_RefundTransaction_PartialItemRefund(t *testing.T) {
+	// Setup
+	mockRefundRepo := new(mocks.MockRefundRepository)
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockTransactionItemRepo := new(mocks.MockTransactionItemRepository)
+	mockCheckoutRepo := new(mocks.MockCheckoutRepository)
+	mockProductLogRepo := new(mocks.MockProductLogRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockHistoryRepo := new(mocks.MockTransactionStatusHistoryRepository)
+	mockPaymentIntentUsecase := new(mocks.MockPaymentIntentUsecase)
+
+	refundUsecase := NewRefundUsecase(
+		mockRefundRepo,
+		mockTransactionRepo,
+		mockTransactionItemRepo,
+		mockCheckoutRepo,
+		mockProductLogRepo,
+		mockProductRepo,
+		NewOrderStatusUsecase(mockTransactionRepo, mockHistoryRepo),
+		mockPaymentIntentUsecase,
+	)
+	mockTx := "mock_transaction"
+	req := &domain.RefundRequest{
+		Items:  []domain.RefundItemRequest{{TransactionItemID: 11, Quantity: 1}},
+		Reason: "damaged",
+	}
+
+	// Mock expectations
+	mockTransactionRepo.On("BeginTx").Return(mockTx, nil)
+	mockTransactionRepo.On("GetByIDWithLock", mockTx, uint64(1)).Return(refundTestTransaction(), nil)
+	mockProductLogRepo.On("GetByID", uint64(70)).Return(&domain.ProductLog{ID: 70, ProductID: 7}, nil)
+	mockTransactionItemRepo.On("AddRefundedQuantityWithTx", mockTx, uint64(11), 1).Return(nil)
+	mockProductRepo.On("UpdateStockWithTx", mockTx, uint64(7), uint64(0), -1).Return(nil)
+	mockProductRepo.On("UpdateSoldCountWithTx", mockTx, uint64(7), -1).Return(nil)
+	mockTransactionRepo.On("AddRefundedAmountWithTx", mockTx, uint64(1), 10000.0).Return(nil)
+	mockRefundRepo.On("CreateWithTx", mockTx, mock.MatchedBy(func(refund *domain.Refund) bool {
+		refund.ID = 30
+		return refund.Status == domain.RefundStatusPending
+	})).Return(nil)
+	mockTransactionRepo.On("CommitTx", mockTx).Return(nil)
+	mockPaymentIntentUsecase.On("RefundPayment", uint(9), 10000.0, "refund-30").Return("MOCK-1-R2", nil)
+	mockRefundRepo.On("UpdateStatus", uint64(30), domain.RefundStatusCompleted, "MOCK-1-R2").Return(nil)
+
+	// Execute
+	refund, err := refundUsecase.RefundTransaction(5, 1, req)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 10000.0, refund.Amount)
+	assert.Equal(t, uint64(5), refund.RefundedBy)
+	assert.Equal(t, "MOCK-1-R2", refund.ProviderRef)
+	assert.Equal(t, domain.RefundStatusCompleted, refund.Status)
+	assert.Len(t, refund.Items, 1)
+	assert.Equal(t, uint64(7), refund.Items[0].ProductID)
+	mockProductRepo.AssertExpectations(t)
+	mockTransactionRepo.AssertNotCalled(t, "TransitionStatusWithTx", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRefundUsecase_RefundTransaction_FullRefundClosesOrder(t *testing.T) {
+	// Setup
+	mockRefundRepo := new(mocks.MockRefundRepository)
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockTransactionItemRepo := new(mocks.MockTransactionItemRepository)
+	mockCheckoutRepo := new(mocks.MockCheckoutRepository)
+	mockProductLogRepo := new(mocks.MockProductLogRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockHistoryRepo := new(mocks.MockTransactionStatusHistoryRepository)
+	mockPaymentIntentUsecase := new(mocks.MockPaymentIntentUsecase)
+
+	refundUsecase := NewRefundUsecase(
+		mockRefundRepo,
+		mockTransactionRepo,
+		mockTransactionItemRepo,
+		mockCheckoutRepo,
+		mockProductLogRepo,
+		mockProductRepo,
+		NewOrderStatusUsecase(mockTransactionRepo, mockHistoryRepo),
+		mockPaymentIntentUsecase,
+	)
+	mockTx := "mock_transaction"
+	checkout := &domain.Checkout{ID: 9, Transactions: []*domain.Transaction{
+		{ID: 1, Status: "paid"},
+		{ID: 2, Status: "refunded"},
+	}}
+
+	// Mock expectations
+	mockTransactionRepo.On("BeginTx").Return(mockTx, nil)
+	mockTransactionRepo.On("GetByIDWithLock", mockTx, uint64(1)).Return(refundTestTransaction(), nil)
+	mockProductLogRepo.On("GetByID", uint64(70)).Return(&domain.ProductLog{ID: 70, ProductID: 7}, nil)
+	mockProductLogRepo.On("GetByID", uint64(80)).Return(&domain.ProductLog{ID: 80, ProductID: 8}, nil)
+	mockTransactionItemRepo.On("AddRefundedQuantityWithTx", mockTx, uint64(11), 2).Return(nil)
+	mockTransactionItemRepo.On("AddRefundedQuantityWithTx", mockTx, uint64(12), 1).Return(nil)
+	mockProductRepo.On("UpdateStockWithTx", mockTx, uint64(7), uint64(0), -2).Return(nil)
+	mockProductRepo.On("UpdateSoldCountWithTx", mockTx, uint64(7), -2).Return(nil)
+	mockProductRepo.On("UpdateStockWithTx", mockTx, uint64(8), uint64(0), -1).Return(nil)
+	mockProductRepo.On("UpdateSoldCountWithTx", mockTx, uint64(8), -1).Return(nil)
+	mockTransactionRepo.On("AddRefundedAmountWithTx", mockTx, uint64(1), 25000.0).Return(nil)
+	mockTransactionRepo.On("TransitionStatusWithTx", mockTx, uint64(1), "paid", "refunded", mock.AnythingOfType("time.Time")).Return(true, nil)
+	mockHistoryRepo.On("CreateWithTx", mockTx, mock.AnythingOfType("*domain.TransactionStatusHistory")).Return(nil)
+	mockCheckoutRepo.On("GetByID", uint64(9)).Return(checkout, nil)
+	mockCheckoutRepo.On("UpdateStatusWithTx", mockTx, uint64(9), "refunded").Return(nil)
+	mockRefundRepo.On("CreateWithTx", mockTx, mock.MatchedBy(func(refund *domain.Refund) bool {
+		refund.ID = 31
+		return true
+	})).Return(nil)
+	mockTransactionRepo.On("CommitTx", mockTx).Return(nil)
+	mockPaymentIntentUsecase.On("RefundPayment", uint(9), 25000.0, "refund-31").Return("", nil)
+	mockRefundRepo.On("UpdateStatus", uint64(31), domain.RefundStatusCompleted, "").Return(nil)
+
+	// Execute
+	refund, err := refundUsecase.RefundTransaction(5, 1, &domain.RefundRequest{})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 25000.0, refund.Amount)
+	assert.Len(t, refund.Items, 2)
+	// Delivered orders keep their fulfilment status
+	mockTransactionRepo.AssertNotCalled(t, "TransitionOrderStatusWithTx", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockHistoryRepo.AssertNumberOfCalls(t, "CreateWithTx", 1)
+	mockTransactionRepo.AssertExpectations(t)
+	mockCheckoutRepo.AssertExpectations(t)
+	mockProductRepo.AssertExpectations(t)
+}
+
+func TestRefundUsecase_RefundTransaction_ExceedsRefundableQuantity(t *testing.T) {
+	// Setup
+	mockRefundRepo := new(mocks.MockRefundRepository)
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockTransactionItemRepo := new(mocks.MockTransactionItemRepository)
+	mockCheckoutRepo := new(mocks.MockCheckoutRepository)
+	mockProductLogRepo := new(mocks.MockProductLogRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockHistoryRepo := new(mocks.MockTransactionStatusHistoryRepository)
+	mockPaymentIntentUsecase := new(mocks.MockPaymentIntentUsecase)
+
+	refundUsecase := NewRefundUsecase(
+		mockRefundRepo,
+		mockTransactionRepo,
+		mockTransactionItemRepo,
+		mockCheckoutRepo,
+		mockProductLogRepo,
+		mockProductRepo,
+		NewOrderStatusUsecase(mockTransactionRepo, mockHistoryRepo),
+		mockPaymentIntentUsecase,
+	)
+	mockTx := "mock_transaction"
+	req := &domain.RefundRequest{Items: []domain.RefundItemRequest{{TransactionItemID: 11, Quantity: 3}}}
+
+	// Mock expectations
+	mockTransactionRepo.On("BeginTx").Return(mockTx, nil)
+	mockTransactionRepo.On("GetByIDWithLock", mockTx, uint64(1)).Return(refundTestTransaction(), nil)
+	mockTransactionRepo.On("RollbackTx", mockTx).Return(nil)
+
+	// Execute
+	refund, err := refundUsecase.RefundTransaction(5, 1, req)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Equal(t, "REFUND_EXCEEDS_QUANTITY", err.Error())
+	assert.Nil(t, refund)
+	mockPaymentIntentUsecase.AssertNotCalled(t, "RefundPayment", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRefundUsecase_RefundTransaction_ExceedsBalance(t *testing.T) {
+	// Setup
+	mockRefundRepo := new(mocks.MockRefundRepository)
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockTransactionItemRepo := new(mocks.MockTransactionItemRepository)
+	mockCheckoutRepo := new(mocks.MockCheckoutRepository)
+	mockProductLogRepo := new(mocks.MockProductLogRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockHistoryRepo := new(mocks.MockTransactionStatusHistoryRepository)
+	mockPaymentIntentUsecase := new(mocks.MockPaymentIntentUsecase)
+
+	refundUsecase := NewRefundUsecase(
+		mockRefundRepo,
+		mockTransactionRepo,
+		mockTransactionItemRepo,
+		mockCheckoutRepo,
+		mockProductLogRepo,
+		mockProductRepo,
+		NewOrderStatusUsecase(mockTransactionRepo, mockHistoryRepo),
+		mockPaymentIntentUsecase,
+	)
+	mockTx := "mock_transaction"
+
+	// Mock expectations
+	mockTransactionRepo.On("BeginTx").Return(mockTx, nil)
+	mockTransactionRepo.On("GetByIDWithLock", mockTx, uint64(1)).Return(refundTestTransaction(), nil)
+	mockTransactionRepo.On("RollbackTx", mockTx).Return(nil)
+
+	// Execute
+	refund, err := refundUsecase.RefundTransaction(5, 1, &domain.RefundRequest{Amount: 25000.01})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Equal(t, "REFUND_EXCEEDS_BALANCE", err.Error())
+	assert.Nil(t, refund)
+}
+
+func TestRefundUsecase_RefundTransaction_ProviderFailureKeepsFailedRefund(t *testing.T) {
+	// Setup
+	mockRefundRepo := new(mocks.MockRefundRepository)
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockTransactionItemRepo := new(mocks.MockTransactionItemRepository)
+	mockCheckoutRepo := new(mocks.MockCheckoutRepository)
+	mockProductLogRepo := new(mocks.MockProductLogRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockHistoryRepo := new(mocks.MockTransactionStatusHistoryRepository)
+	mockPaymentIntentUsecase := new(mocks.MockPaymentIntentUsecase)
+
+	refundUsecase := NewRefundUsecase(
+		mockRefundRepo,
+		mockTransactionRepo,
+		mockTransactionItemRepo,
+		mockCheckoutRepo,
+		mockProductLogRepo,
+		mockProductRepo,
+		NewOrderStatusUsecase(mockTransactionRepo, mockHistoryRepo),
+		mockPaymentIntentUsecase,
+	)
+	mockTx := "mock_transaction"
+
+	// Mock expectations
+	mockTransactionRepo.On("BeginTx").Return(mockTx, nil)
+	mockTransactionRepo.On("GetByIDWithLock", mockTx, uint64(1)).Return(refundTestTransaction(), nil)
+	mockTransactionRepo.On("AddRefundedAmountWithTx", mockTx, uint64(1), 5000.0).Return(nil)
+	mockRefundRepo.On("CreateWithTx", mockTx, mock.MatchedBy(func(refund *domain.Refund) bool {
+		refund.ID = 32
+		return refund.Status == domain.RefundStatusPending
+	})).Return(nil)
+	mockTransactionRepo.On("CommitTx", mockTx).Return(nil)
+	mockPaymentIntentUsecase.On("RefundPayment", uint(9), 5000.0, "refund-32").Return("", errors.New("charge is not paid"))
+	mockRefundRepo.On("UpdateStatus", uint64(32), domain.RefundStatusFailed, "").Return(nil)
+
+	// Execute
+	refund, err := refundUsecase.RefundTransaction(5, 1, &domain.RefundRequest{Amount: 5000})
+
+	// Assert - the refund stays on the ledger to be retried
+	assert.Error(t, err)
+	assert.Equal(t, "PAYMENT_REFUND_FAILED", err.Error())
+	assert.Nil(t, refund)
+	mockTransactionRepo.AssertCalled(t, "CommitTx", mockTx)
+	mockRefundRepo.AssertExpectations(t)
+}
+
+func TestRefundUsecase_RetryRefund_SendsWithSameKey(t *testing.T) {
+	// Setup
+	mockRefundRepo := new(mocks.MockRefundRepository)
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockTransactionItemRepo := new(mocks.MockTransactionItemRepository)
+	mockCheckoutRepo := new(mocks.MockCheckoutRepository)
+	mockProductLogRepo := new(mocks.MockProductLogRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockHistoryRepo := new(mocks.MockTransactionStatusHistoryRepository)
+	mockPaymentIntentUsecase := new(mocks.MockPaymentIntentUsecase)
+
+	refundUsecase := NewRefundUsecase(
+		mockRefundRepo,
+		mockTransactionRepo,
+		mockTransactionItemRepo,
+		mockCheckoutRepo,
+		mockProductLogRepo,
+		mockProductRepo,
+		NewOrderStatusUsecase(mockTransactionRepo, mockHistoryRepo),
+		mockPaymentIntentUsecase,
+	)
+
+	// Mock expectations
+	mockRefundRepo.On("GetByID", uint64(32)).Return(&domain.Refund{ID: 32, TransactionID: 1, CheckoutID: 9, Amount: 5000, Status: domain.RefundStatusFailed}, nil)
+	mockPaymentIntentUsecase.On("RefundPayment", uint(9), 5000.0, "refund-32").Return("MOCK-1-R2", nil)
+	mockRefundRepo.On("UpdateStatus", uint64(32), domain.RefundStatusCompleted, "MOCK-1-R2").Return(nil)
+
+	// Execute
+	refund, err := refundUsecase.RetryRefund(32)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, domain.RefundStatusCompleted, refund.Status)
+	assert.Equal(t, "MOCK-1-R2", refund.ProviderRef)
+	mockRefundRepo.AssertExpectations(t)
+	mockTransactionRepo.AssertNotCalled(t, "BeginTx")
+}
+
+func TestRefundUsecase_RetryRefund_AlreadyCompleted(t *testing.T) {
+	// Setup
+	mockRefundRepo := new(mocks.MockRefundRepository)
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockTransactionItemRepo := new(mocks.MockTransactionItemRepository)
+	mockCheckoutRepo := new(mocks.MockCheckoutRepository)
+	mockProductLogRepo := new(mocks.MockProductLogRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockHistoryRepo := new(mocks.MockTransactionStatusHistoryRepository)
+	mockPaymentIntentUsecase := new(mocks.MockPaymentIntentUsecase)
+
+	refundUsecase := NewRefundUsecase(
+		mockRefundRepo,
+		mockTransactionRepo,
+		mockTransactionItemRepo,
+		mockCheckoutRepo,
+		mockProductLogRepo,
+		mockProductRepo,
+		NewOrderStatusUsecase(mockTransactionRepo, mockHistoryRepo),
+		mockPaymentIntentUsecase,
+	)
+
+	// Mock expectations
+	mockRefundRepo.On("GetByID", uint64(30)).Return(&domain.Refund{ID: 30, CheckoutID: 9, Amount: 10000, Status: domain.RefundStatusCompleted}, nil)
+
+	// Execute
+	refund, err := refundUsecase.RetryRefund(30)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Equal(t, "REFUND_ALREADY_COMPLETED", err.Error())
+	assert.Nil(t, refund)
+	mockPaymentIntentUsecase.AssertNotCalled(t, "RefundPayment", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,289 @@
+package usecase
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"go-commerce/internal/domain"
+)
+
+// ReviewUsecase handles product reviews. The rating sum and count of a
+// product and its store are adjusted in the same database transaction as the
+// review, so their averages always match the visible reviews.
+type ReviewUsecase struct {
+	reviewRepo          domain.ReviewRepository
+	transactionRepo     domain.TransactionRepository
+	transactionItemRepo domain.TransactionItemRepository
+	productRepo         domain.ProductRepository
+	storeRepo           domain.StoreRepository
+	searchIndex         domain.ProductSearchIndex
+}
+
+func NewReviewUsecase(
+	reviewRepo domain.ReviewRepository,
+	transactionRepo domain.TransactionRepository,
+	transactionItemRepo domain.TransactionItemRepository,
+	productRepo domain.ProductRepository,
+	storeRepo domain.StoreRepository,
+	searchIndex domain.ProductSearchIndex,
+) *ReviewUsecase {
+	return &ReviewUsecase{
+		reviewRepo:          reviewRepo,
+		transactionRepo:     transactionRepo,
+		transactionItemRepo: transactionItemRepo,
+		productRepo:         productRepo,
+		storeRepo:           storeRepo,
+		searchIndex:         searchIndex,
+	}
+}
+
+// CreateReview - Buyer reviews an item of one of their orders once the order
+// is delivered or done. Each order item can be reviewed once.
+func (u *ReviewUsecase) CreateReview(userID uint64, req *domain.CreateReviewRequest, photoURLs []string) (*domain.Review, error) {
+	if len(photoURLs) > domain.MaxReviewPhotos {
+		return nil, errors.New("too many photos")
+	}
+
+	item, err := u.transactionItemRepo.GetByID(req.TransactionItemID)
+	if err != nil || item.Transaction == nil || item.ProductLog == nil {
+		return nil, errors.New("transaction item not found")
+	}
+
+	// Check ownership
+	if item.Transaction.UserID != userID {
+		return nil, errors.New("forbidden")
+	}
+
+	transaction := item.Transaction
+	if transaction.OrderStatus != domain.OrderStatusDelivered && transaction.Status != domain.PaymentStatusDone {
+		return nil, errors.New("NOT_REVIEWABLE")
+	}
+	// Items refunded in full were given back and cannot be reviewed
+	if transaction.Status == domain.PaymentStatusRefunded || item.RefundedQuantity >= item.Quantity {
+		return nil, errors.New("NOT_REVIEWABLE")
+	}
+
+	if _, err := u.reviewRepo.GetByTransactionItemID(item.ID); err == nil {
+		return nil, errors.New("REVIEW_ALREADY_EXISTS")
+	}
+
+	review := &domain.Review{
+		TransactionItemID: item.ID,
+		TransactionID:     item.TransactionID,
+		ProductID:         item.ProductLog.ProductID,
+		StoreID:           item.StoreID,
+		UserID:            userID,
+		Rating:            req.Rating,
+		Comment:           req.Comment,
+		Status:            domain.ReviewStatusVisible,
+	}
+	for _, url := range photoURLs {
+		review.Photos = append(review.Photos, &domain.ReviewPhoto{URL: url})
+	}
+
+	dbTx, err := u.transactionRepo.BeginTx()
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			u.transactionRepo.RollbackTx(dbTx)
+			panic(r)
+		}
+	}()
+
+	// The unique index on the order item turns a concurrent second review
+	// into an error here
+	if err := u.reviewRepo.CreateWithTx(dbTx, review); err != nil {
+		u.transactionRepo.RollbackTx(dbTx)
+		return nil, err
+	}
+
+	if err := u.addRatingWithTx(dbTx, review, 1); err != nil {
+		u.transactionRepo.RollbackTx(dbTx)
+		return nil, err
+	}
+
+	if err := u.transactionRepo.CommitTx(dbTx); err != nil {
+		return nil, err
+	}
+
+	go u.reindexProduct(review.ProductID)
+
+	return review, nil
+}
+
+// ReplyReview - Seller answers a review of their store once
+func (u *ReviewUsecase) ReplyReview(userID, reviewID uint64, req *domain.ReplyReviewRequest) (*domain.Review, error) {
+	store, err := u.storeRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, errors.New("store not found")
+	}
+
+	dbTx, err := u.transactionRepo.BeginTx()
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			u.transactionRepo.RollbackTx(dbTx)
+			panic(r)
+		}
+	}()
+
+	// Lock the review so two replies cannot both pass the check
+	review, err := u.reviewRepo.GetByIDWithLock(dbTx, reviewID)
+	if err != nil {
+		u.transactionRepo.RollbackTx(dbTx)
+		return nil, errors.New("review not found")
+	}
+
+	// Check ownership
+	if review.StoreID != store.ID {
+		u.transactionRepo.RollbackTx(dbTx)
+		return nil, errors.New("forbidden")
+	}
+
+	if review.Reply != "" {
+		u.transactionRepo.RollbackTx(dbTx)
+		return nil, errors.New("REVIEW_ALREADY_REPLIED")
+	}
+
+	now := time.Now()
+	review.Reply = req.Reply
+	review.RepliedAt = &now
+
+	if err := u.reviewRepo.UpdateWithTx(dbTx, review); err != nil {
+		u.transactionRepo.RollbackTx(dbTx)
+		return nil, err
+	}
+
+	if err := u.transactionRepo.CommitTx(dbTx); err != nil {
+		return nil, err
+	}
+
+	return review, nil
+}
+
+// HideReview - Admin hides an abusive review. It no longer shows on the
+// product and no longer counts towards the product and store ratings.
+func (u *ReviewUsecase) HideReview(adminID, reviewID uint64, req *domain.HideReviewRequest) (*domain.Review, error) {
+	return u.moderate(reviewID, func(review *domain.Review) (int, error) {
+		if review.Status == domain.ReviewStatusHidden {
+			return 0, errors.New("REVIEW_ALREADY_HIDDEN")
+		}
+
+		now := time.Now()
+		review.Status = domain.ReviewStatusHidden
+		review.HiddenReason = req.Reason
+		review.HiddenBy = &adminID
+		review.HiddenAt = &now
+		return -1, nil
+	})
+}
+
+// UnhideReview - Admin shows a hidden review again
+func (u *ReviewUsecase) UnhideReview(reviewID uint64) (*domain.Review, error) {
+	return u.moderate(reviewID, func(review *domain.Review) (int, error) {
+		if review.Status != domain.ReviewStatusHidden {
+			return 0, errors.New("REVIEW_NOT_HIDDEN")
+		}
+
+		review.Status = domain.ReviewStatusVisible
+		review.HiddenReason = ""
+		review.HiddenBy = nil
+		review.HiddenAt = nil
+		return 1, nil
+	})
+}
+
+// moderate changes the status of the locked review with change, which
+// returns whether the review joins (1) or leaves (-1) the ratings
+func (u *ReviewUsecase) moderate(reviewID uint64, change func(review *domain.Review) (int, error)) (*domain.Review, error) {
+	dbTx, err := u.transactionRepo.BeginTx()
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			u.transactionRepo.RollbackTx(dbTx)
+			panic(r)
+		}
+	}()
+
+	review, err := u.reviewRepo.GetByIDWithLock(dbTx, reviewID)
+	if err != nil {
+		u.transactionRepo.RollbackTx(dbTx)
+		return nil, errors.New("review not found")
+	}
+
+	sign, err := change(review)
+	if err != nil {
+		u.transactionRepo.RollbackTx(dbTx)
+		return nil, err
+	}
+
+	if err := u.reviewRepo.UpdateWithTx(dbTx, review); err != nil {
+		u.transactionRepo.RollbackTx(dbTx)
+		return nil, err
+	}
+
+	if err := u.addRatingWithTx(dbTx, review, sign); err != nil {
+		u.transactionRepo.RollbackTx(dbTx)
+		return nil, err
+	}
+
+	if err := u.transactionRepo.CommitTx(dbTx); err != nil {
+		return nil, err
+	}
+
+	go u.reindexProduct(review.ProductID)
+
+	return review, nil
+}
+
+// GetProductReviews lists the visible reviews of a product, newest first
+func (u *ReviewUsecase) GetProductReviews(productID uint64, page, limit int) ([]*domain.Review, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	if _, err := u.productRepo.GetByID(productID); err != nil {
+		return nil, 0, errors.New("product not found")
+	}
+
+	offset := (page - 1) * limit
+	return u.reviewRepo.GetVisibleByProductID(productID, limit, offset)
+}
+
+// addRatingWithTx adds review to (sign 1) or takes it out of (sign -1) the
+// ratings of its product and store
+func (u *ReviewUsecase) addRatingWithTx(dbTx interface{}, review *domain.Review, sign int) error {
+	if err := u.productRepo.AddRatingWithTx(dbTx, review.ProductID, sign*review.Rating, sign); err != nil {
+		return err
+	}
+	return u.storeRepo.AddRatingWithTx(dbTx, review.StoreID, sign*review.Rating, sign)
+}
+
+// reindexProduct refreshes the rating of a product in the search index. It
+// runs in the background, so failures are only logged.
+func (u *ReviewUsecase) reindexProduct(productID uint64) {
+	if u.searchIndex == nil {
+		return
+	}
+	product, err := u.productRepo.GetByID(productID)
+	if err != nil {
+		log.Printf("Error loading product %d for indexing: %v", productID, err)
+		return
+	}
+	if err := u.searchIndex.Index(product); err != nil {
+		log.Printf("Error indexing product %d: %v", productID, err)
+	}
+}
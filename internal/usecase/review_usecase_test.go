@@ -0,0 +1,210 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+
+	"go-commerce/internal/domain"
+	"go-commerce/internal/usecase/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func reviewableItem(orderStatus, paymentStatus string) *domain.TransactionItem {
+	return &domain.TransactionItem{
+		ID:            10,
+		TransactionID: 1,
+		StoreID:       4,
+		Quantity:      1,
+		Transaction:   &domain.Transaction{ID: 1, UserID: 3, Status: paymentStatus, OrderStatus: orderStatus},
+		ProductLog:    &domain.ProductLog{ID: 20, ProductID: 7},
+	}
+}
+
+func TestReviewUsecase_CreateReview_AddsRatingToProductAndStore(t *testing.T) {
+	// Setup
+	mockReviewRepo := new(mocks.MockReviewRepository)
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockTransactionItemRepo := new(mocks.MockTransactionItemRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockStoreRepo := new(mocks.MockStoreRepository)
+	reviewUsecase := NewReviewUsecase(mockReviewRepo, mockTransactionRepo, mockTransactionItemRepo, mockProductRepo, mockStoreRepo, nil)
+	mockTx := "mock_transaction"
+	req := &domain.CreateReviewRequest{TransactionItemID: 10, Rating: 4, Comment: "Sesuai deskripsi"}
+
+	// Mock expectations
+	mockTransactionItemRepo.On("GetByID", uint64(10)).Return(reviewableItem("delivered", "paid"), nil)
+	mockReviewRepo.On("GetByTransactionItemID", uint64(10)).Return(nil, errors.New("record not found"))
+	mockTransactionRepo.On("BeginTx").Return(mockTx, nil)
+	mockReviewRepo.On("CreateWithTx", mockTx, mock.AnythingOfType("*domain.Review")).Return(nil)
+	mockProductRepo.On("AddRatingWithTx", mockTx, uint64(7), 4, 1).Return(nil)
+	mockStoreRepo.On("AddRatingWithTx", mockTx, uint64(4), 4, 1).Return(nil)
+	mockTransactionRepo.On("CommitTx", mockTx).Return(nil)
+
+	// Execute
+	review, err := reviewUsecase.CreateReview(3, req, []string{"/uploads/reviews/a.jpg"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), review.ProductID)
+	assert.Equal(t, uint64(4), review.StoreID)
+	assert.Equal(t, domain.ReviewStatusVisible, review.Status)
+	assert.Len(t, review.Photos, 1)
+	mockProductRepo.AssertExpectations(t)
+	mockStoreRepo.AssertExpectations(t)
+	mockTransactionRepo.AssertExpectations(t)
+}
+
+func TestReviewUsecase_CreateReview_OrderNotDelivered(t *testing.T) {
+	// Setup
+	mockReviewRepo := new(mocks.MockReviewRepository)
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockTransactionItemRepo := new(mocks.MockTransactionItemRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockStoreRepo := new(mocks.MockStoreRepository)
+	reviewUsecase := NewReviewUsecase(mockReviewRepo, mockTransactionRepo, mockTransactionItemRepo, mockProductRepo, mockStoreRepo, nil)
+	req := &domain.CreateReviewRequest{TransactionItemID: 10, Rating: 5}
+
+	// Mock expectations
+	mockTransactionItemRepo.On("GetByID", uint64(10)).Return(reviewableItem("shipped", "paid"), nil)
+
+	// Execute
+	review, err := reviewUsecase.CreateReview(3, req, nil)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, review)
+	assert.Equal(t, "NOT_REVIEWABLE", err.Error())
+	mockTransactionRepo.AssertNotCalled(t, "BeginTx")
+}
+
+func TestReviewUsecase_CreateReview_NotOwnOrder(t *testing.T) {
+	// Setup
+	mockReviewRepo := new(mocks.MockReviewRepository)
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockTransactionItemRepo := new(mocks.MockTransactionItemRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockStoreRepo := new(mocks.MockStoreRepository)
+	reviewUsecase := NewReviewUsecase(mockReviewRepo, mockTransactionRepo, mockTransactionItemRepo, mockProductRepo, mockStoreRepo, nil)
+	req := &domain.CreateReviewRequest{TransactionItemID: 10, Rating: 5}
+
+	// Mock expectations
+	mockTransactionItemRepo.On("GetByID", uint64(10)).Return(reviewableItem("delivered", "done"), nil)
+
+	// Execute
+	review, err := reviewUsecase.CreateReview(99, req, nil)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, review)
+	assert.Equal(t, "forbidden", err.Error())
+}
+
+func TestReviewUsecase_CreateReview_AlreadyReviewed(t *testing.T) {
+	// Setup
+	mockReviewRepo := new(mocks.MockReviewRepository)
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockTransactionItemRepo := new(mocks.MockTransactionItemRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockStoreRepo := new(mocks.MockStoreRepository)
+	reviewUsecase := NewReviewUsecase(mockReviewRepo, mockTransactionRepo, mockTransactionItemRepo, mockProductRepo, mockStoreRepo, nil)
+	req := &domain.CreateReviewRequest{TransactionItemID: 10, Rating: 5}
+
+	// Mock expectations
+	mockTransactionItemRepo.On("GetByID", uint64(10)).Return(reviewableItem("delivered", "done"), nil)
+	mockReviewRepo.On("GetByTransactionItemID", uint64(10)).Return(&domain.Review{ID: 1}, nil)
+
+	// Execute
+	review, err := reviewUsecase.CreateReview(3, req, nil)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, review)
+	assert.Equal(t, "REVIEW_ALREADY_EXISTS", err.Error())
+	mockTransactionRepo.AssertNotCalled(t, "BeginTx")
+}
+
+func TestReviewUsecase_ReplyReview_OnlyOnce(t *testing.T) {
+	// Setup
+	mockReviewRepo := new(mocks.MockReviewRepository)
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockTransactionItemRepo := new(mocks.MockTransactionItemRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockStoreRepo := new(mocks.MockStoreRepository)
+	reviewUsecase := NewReviewUsecase(mockReviewRepo, mockTransactionRepo, mockTransactionItemRepo, mockProductRepo, mockStoreRepo, nil)
+	mockTx := "mock_transaction"
+	review := &domain.Review{ID: 1, StoreID: 4, Reply: "Terima kasih"}
+
+	// Mock expectations
+	mockStoreRepo.On("GetByUserID", uint64(5)).Return(&domain.Store{ID: 4}, nil)
+	mockTransactionRepo.On("BeginTx").Return(mockTx, nil)
+	mockReviewRepo.On("GetByIDWithLock", mockTx, uint64(1)).Return(review, nil)
+	mockTransactionRepo.On("RollbackTx", mockTx).Return(nil)
+
+	// Execute
+	result, err := reviewUsecase.ReplyReview(5, 1, &domain.ReplyReviewRequest{Reply: "Sekali lagi"})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, "REVIEW_ALREADY_REPLIED", err.Error())
+	mockReviewRepo.AssertNotCalled(t, "UpdateWithTx", mock.Anything, mock.Anything)
+}
+
+func TestReviewUsecase_HideReview_TakesRatingOut(t *testing.T) {
+	// Setup
+	mockReviewRepo := new(mocks.MockReviewRepository)
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockTransactionItemRepo := new(mocks.MockTransactionItemRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockStoreRepo := new(mocks.MockStoreRepository)
+	reviewUsecase := NewReviewUsecase(mockReviewRepo, mockTransactionRepo, mockTransactionItemRepo, mockProductRepo, mockStoreRepo, nil)
+	mockTx := "mock_transaction"
+	review := &domain.Review{ID: 1, ProductID: 7, StoreID: 4, Rating: 1, Status: domain.ReviewStatusVisible}
+
+	// Mock expectations
+	mockTransactionRepo.On("BeginTx").Return(mockTx, nil)
+	mockReviewRepo.On("GetByIDWithLock", mockTx, uint64(1)).Return(review, nil)
+	mockReviewRepo.On("UpdateWithTx", mockTx, review).Return(nil)
+	mockProductRepo.On("AddRatingWithTx", mockTx, uint64(7), -1, -1).Return(nil)
+	mockStoreRepo.On("AddRatingWithTx", mockTx, uint64(4), -1, -1).Return(nil)
+	mockTransactionRepo.On("CommitTx", mockTx).Return(nil)
+
+	// Execute
+	result, err := reviewUsecase.HideReview(2, 1, &domain.HideReviewRequest{Reason: "Kata-kata kasar"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, domain.ReviewStatusHidden, result.Status)
+	assert.Equal(t, "Kata-kata kasar", result.HiddenReason)
+	assert.Equal(t, uint64(2), *result.HiddenBy)
+	mockProductRepo.AssertExpectations(t)
+	mockStoreRepo.AssertExpectations(t)
+}
+
+func TestReviewUsecase_UnhideReview_NotHidden(t *testing.T) {
+	// Setup
+	mockReviewRepo := new(mocks.MockReviewRepository)
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockTransactionItemRepo := new(mocks.MockTransactionItemRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockStoreRepo := new(mocks.MockStoreRepository)
+	reviewUsecase := NewReviewUsecase(mockReviewRepo, mockTransactionRepo, mockTransactionItemRepo, mockProductRepo, mockStoreRepo, nil)
+	mockTx := "mock_transaction"
+	review := &domain.Review{ID: 1, ProductID: 7, StoreID: 4, Rating: 5, Status: domain.ReviewStatusVisible}
+
+	// Mock expectations
+	mockTransactionRepo.On("BeginTx").Return(mockTx, nil)
+	mockReviewRepo.On("GetByIDWithLock", mockTx, uint64(1)).Return(review, nil)
+	mockTransactionRepo.On("RollbackTx", mockTx).Return(nil)
+
+	// Execute
+	result, err := reviewUsecase.UnhideReview(1)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, "REVIEW_NOT_HIDDEN", err.Error())
+	mockProductRepo.AssertNotCalled(t, "AddRatingWithTx", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
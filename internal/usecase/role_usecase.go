@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"go-commerce/internal/domain"
+	"go-commerce/pkg/jwt"
+
+	"gorm.io/gorm"
+)
+
+type RoleUsecase struct {
+	roleRepo        domain.RoleRepository
+	userRepo        domain.UserRepository
+	revocationStore domain.TokenRevocationStore
+	jwtManager      *jwt.JWTManager
+}
+
+func NewRoleUsecase(roleRepo domain.RoleRepository, userRepo domain.UserRepository, revocationStore domain.TokenRevocationStore, jwtManager *jwt.JWTManager) *RoleUsecase {
+	return &RoleUsecase{
+		roleRepo:        roleRepo,
+		userRepo:        userRepo,
+		revocationStore: revocationStore,
+		jwtManager:      jwtManager,
+	}
+}
+
+func (u *RoleUsecase) GetAllRoles() ([]*domain.Role, error) {
+	roles, err := u.roleRepo.GetAll()
+	if err != nil {
+		return nil, errors.New("failed to get roles")
+	}
+	return roles, nil
+}
+
+func (u *RoleUsecase) GetUserRoles(userID uint64) ([]*domain.Role, error) {
+	if _, err := u.userRepo.GetByID(userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, errors.New("failed to get user")
+	}
+
+	roles, err := u.roleRepo.GetUserRoles(userID)
+	if err != nil {
+		return nil, errors.New("failed to get user roles")
+	}
+	return roles, nil
+}
+
+// AssignRole grants a role to the user. The user's current access tokens are
+// revoked so the next refresh picks up the new permissions.
+func (u *RoleUsecase) AssignRole(userID uint64, req *domain.AssignRoleRequest) error {
+	role, err := u.findUserAndRole(userID, req.Role)
+	if err != nil {
+		return err
+	}
+
+	if err := u.roleRepo.AssignToUser(userID, role.ID); err != nil {
+		return errors.New("failed to assign role")
+	}
+
+	u.invalidateClaims(userID)
+	return nil
+}
+
+// RevokeRole removes a role from the user. Admins cannot revoke their own
+// roles so they cannot lock themselves out by accident.
+func (u *RoleUsecase) RevokeRole(actorID, userID uint64, roleName string) error {
+	if actorID == userID {
+		return errors.New("CANNOT_REVOKE_OWN_ROLE")
+	}
+
+	role, err := u.findUserAndRole(userID, roleName)
+	if err != nil {
+		return err
+	}
+
+	revoked, err := u.roleRepo.RevokeFromUser(userID, role.ID)
+	if err != nil {
+		return errors.New("failed to revoke role")
+	}
+	if !revoked {
+		return errors.New("ROLE_NOT_ASSIGNED")
+	}
+
+	u.invalidateClaims(userID)
+	return nil
+}
+
+func (u *RoleUsecase) findUserAndRole(userID uint64, roleName string) (*domain.Role, error) {
+	if _, err := u.userRepo.GetByID(userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, errors.New("failed to get user")
+	}
+
+	role, err := u.roleRepo.GetByName(roleName)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("role not found")
+		}
+		return nil, errors.New("failed to get role")
+	}
+	return role, nil
+}
+
+func (u *RoleUsecase) invalidateClaims(userID uint64) {
+	if u.revocationStore == nil {
+		return
+	}
+	// JWT timestamps have second precision. Refresh tokens stay valid, and
+	// tokens issued in the current second too, so the client can pick up
+	// the new claims right away.
+	if err := revokeAccessTokens(u.revocationStore, u.jwtManager, userID, time.Now().Truncate(time.Second)); err != nil {
+		log.Printf("Error invalidating tokens after role change for user %d: %v", userID, err)
+	}
+}
@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"go-commerce/internal/domain"
+	"go-commerce/internal/repository/memory"
+	"go-commerce/internal/usecase/mocks"
+	"go-commerce/pkg/jwt"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+func TestRoleUsecase_AssignRole_RevokesExistingAccessTokens(t *testing.T) {
+	// Setup
+	mockRoleRepo := new(mocks.MockRoleRepository)
+	mockUserRepo := new(mocks.MockUserRepository)
+	revocationStore := memory.NewTokenRevocationStore()
+	jwtManager := jwt.NewJWTManager("test-secret", 24, 168)
+	roleUsecase := NewRoleUsecase(mockRoleRepo, mockUserRepo, revocationStore, jwtManager)
+
+	issuedAt := time.Now().Add(-time.Minute)
+
+	// Mock expectations
+	mockUserRepo.On("GetByID", uint64(2)).Return(&domain.User{ID: 2}, nil)
+	mockRoleRepo.On("GetByName", domain.RoleSupport).Return(&domain.Role{ID: 4, Name: domain.RoleSupport}, nil)
+	mockRoleRepo.On("AssignToUser", uint64(2), uint64(4)).Return(nil)
+
+	// Execute
+	err := roleUsecase.AssignRole(2, &domain.AssignRoleRequest{Role: domain.RoleSupport})
+
+	// Assert
+	assert.NoError(t, err)
+	revoked, err := revocationStore.IsRevoked("old-access-token", 2, issuedAt)
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+	mockRoleRepo.AssertExpectations(t)
+}
+
+func TestRoleUsecase_AssignRole_UnknownRole(t *testing.T) {
+	// Setup
+	mockRoleRepo := new(mocks.MockRoleRepository)
+	mockUserRepo := new(mocks.MockUserRepository)
+	roleUsecase := NewRoleUsecase(mockRoleRepo, mockUserRepo, nil, nil)
+
+	// Mock expectations
+	mockUserRepo.On("GetByID", uint64(2)).Return(&domain.User{ID: 2}, nil)
+	mockRoleRepo.On("GetByName", "owner").Return(nil, gorm.ErrRecordNotFound)
+
+	// Execute
+	err := roleUsecase.AssignRole(2, &domain.AssignRoleRequest{Role: "owner"})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Equal(t, "role not found", err.Error())
+	mockRoleRepo.AssertNotCalled(t, "AssignToUser", uint64(2), uint64(0))
+}
+
+func TestRoleUsecase_RevokeRole_OwnRoleForbidden(t *testing.T) {
+	// Setup
+	mockRoleRepo := new(mocks.MockRoleRepository)
+	mockUserRepo := new(mocks.MockUserRepository)
+	roleUsecase := NewRoleUsecase(mockRoleRepo, mockUserRepo, nil, nil)
+
+	// Execute
+	err := roleUsecase.RevokeRole(1, 1, domain.RoleSuperAdmin)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Equal(t, "CANNOT_REVOKE_OWN_ROLE", err.Error())
+	mockUserRepo.AssertNotCalled(t, "GetByID", uint64(1))
+}
+
+func TestRoleUsecase_RevokeRole_NotAssigned(t *testing.T) {
+	// Setup
+	mockRoleRepo := new(mocks.MockRoleRepository)
+	mockUserRepo := new(mocks.MockUserRepository)
+	roleUsecase := NewRoleUsecase(mockRoleRepo, mockUserRepo, nil, nil)
+
+	// Mock expectations
+	mockUserRepo.On("GetByID", uint64(2)).Return(&domain.User{ID: 2}, nil)
+	mockRoleRepo.On("GetByName", domain.RoleFinanceAdmin).Return(&domain.Role{ID: 3, Name: domain.RoleFinanceAdmin}, nil)
+	mockRoleRepo.On("RevokeFromUser", uint64(2), uint64(3)).Return(false, nil)
+
+	// Execute
+	err := roleUsecase.RevokeRole(1, 2, domain.RoleFinanceAdmin)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Equal(t, "ROLE_NOT_ASSIGNED", err.Error())
+	mockRoleRepo.AssertExpectations(t)
+}
+
+func TestAuthUsecase_Login_TokenCarriesRolesAndPermissions(t *testing.T) {
+	// Setup
+	mockUserRepo := new(mocks.MockUserRepository)
+	mockRoleRepo := new(mocks.MockRoleRepository)
+	mockRefreshTokenRepo := new(mocks.MockRefreshTokenRepository)
+	jwtManager := jwt.NewJWTManager("test-secret", 24, 168)
+	authUsecase := NewAuthUsecase(mockUserRepo, nil, mockRoleRepo, mockRefreshTokenRepo, nil, nil, nil, nil, jwtManager, nil)
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
+	user := &domain.User{ID: 7, Email: "finance@example.com", Password: string(hashedPassword)}
+	roles := []*domain.Role{
+		{ID: 3, Name: domain.RoleFinanceAdmin, Permissions: []*domain.Permission{
+			{Name: domain.PermissionTransactionsRefund},
+			{Name: domain.PermissionPaymentsSimulate},
+		}},
+	}
+
+	// Mock expectations
+	mockUserRepo.On("GetByEmail", user.Email).Return(user, nil)
+	mockUserRepo.On("UpdateLastLogin", user.ID, mock.AnythingOfType("time.Time")).Return(nil).Maybe()
+	mockRoleRepo.On("GetUserRoles", user.ID).Return(roles, nil)
+	mockRefreshTokenRepo.On("Create", mock.AnythingOfType("*domain.RefreshToken")).Return(nil)
+
+	// Execute
+	result, err := authUsecase.Login(&domain.LoginRequest{Email: user.Email, Password: "password123"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{domain.RoleFinanceAdmin}, result.Roles)
+	claims, err := jwtManager.ValidateToken(result.AccessToken)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{domain.RoleFinanceAdmin}, claims.Roles)
+	assert.ElementsMatch(t, []string{domain.PermissionTransactionsRefund, domain.PermissionPaymentsSimulate}, claims.Permissions)
+}
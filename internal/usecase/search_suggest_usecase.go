@@ -0,0 +1,196 @@
+package usecase
+
+import (
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go-commerce/internal/domain"
+	"go-commerce/pkg/utils"
+)
+
+const (
+	// suggestRebuildBatchSize is how many rows a rebuild reads per query
+	suggestRebuildBatchSize = 500
+	// popularQueryLimit is how many logged queries a rebuild loads
+	popularQueryLimit = 1000
+	// maxLoggedQueryLength matches search_queries.query
+	maxLoggedQueryLength = 100
+)
+
+// SearchSuggestUsecase completes what buyers type into the search box with
+// product names, categories, active stores and popular past searches. It
+// answers from an in-process prefix index, so suggestions never wait on the
+// database; the product, category and store usecases update the index as
+// they change data.
+type SearchSuggestUsecase struct {
+	index        domain.SearchSuggestIndex
+	queryRepo    domain.SearchQueryRepository
+	productRepo  domain.ProductRepository
+	categoryRepo domain.CategoryRepository
+	storeRepo    domain.StoreRepository
+}
+
+func NewSearchSuggestUsecase(
+	index domain.SearchSuggestIndex,
+	queryRepo domain.SearchQueryRepository,
+	productRepo domain.ProductRepository,
+	categoryRepo domain.CategoryRepository,
+	storeRepo domain.StoreRepository,
+) *SearchSuggestUsecase {
+	return &SearchSuggestUsecase{
+		index:        index,
+		queryRepo:    queryRepo,
+		productRepo:  productRepo,
+		categoryRepo: categoryRepo,
+		storeRepo:    storeRepo,
+	}
+}
+
+// Suggest returns up to limit completions of prefix per kind
+func (u *SearchSuggestUsecase) Suggest(prefix string, limit int) *domain.SearchSuggestions {
+	if limit < 1 || limit > 10 {
+		limit = 5
+	}
+	return u.index.Suggest(prefix, limit)
+}
+
+// LogQuery counts a search so that later suggestions rank by it. Failures to
+// store the count are only logged; the search itself already succeeded.
+func (u *SearchSuggestUsecase) LogQuery(query string) {
+	normalized := strings.Join(utils.SearchTokens(query), " ")
+	if normalized == "" || utf8.RuneCountInString(normalized) > maxLoggedQueryLength {
+		return
+	}
+
+	if err := u.queryRepo.Increment(normalized, time.Now()); err != nil {
+		log.Printf("Error logging search query %q: %v", normalized, err)
+	}
+	u.index.RecordQuery(normalized, 1)
+}
+
+// Rebuild reloads the index from the database. It runs at startup and
+// periodically, which also picks up changes made through other app
+// instances and new sold counts.
+func (u *SearchSuggestUsecase) Rebuild() (int, error) {
+	var entries []*domain.SuggestionEntry
+
+	for offset := 0; ; offset += suggestRebuildBatchSize {
+		products, _, err := u.productRepo.GetAll(suggestRebuildBatchSize, offset, "", "")
+		if err != nil {
+			return 0, err
+		}
+		for _, product := range products {
+			entries = append(entries, productSuggestion(product))
+		}
+		if len(products) < suggestRebuildBatchSize {
+			break
+		}
+	}
+
+	for offset := 0; ; offset += suggestRebuildBatchSize {
+		categories, _, err := u.categoryRepo.GetAll(suggestRebuildBatchSize, offset)
+		if err != nil {
+			return 0, err
+		}
+		for _, category := range categories {
+			if category.Status == "active" {
+				entries = append(entries, categorySuggestion(category))
+			}
+		}
+		if len(categories) < suggestRebuildBatchSize {
+			break
+		}
+	}
+
+	for offset := 0; ; offset += suggestRebuildBatchSize {
+		stores, _, err := u.storeRepo.GetActiveStores(suggestRebuildBatchSize, offset, "")
+		if err != nil {
+			return 0, err
+		}
+		for _, store := range stores {
+			entries = append(entries, storeSuggestion(store))
+		}
+		if len(stores) < suggestRebuildBatchSize {
+			break
+		}
+	}
+
+	queries, err := u.queryRepo.GetPopular(popularQueryLimit)
+	if err != nil {
+		return 0, err
+	}
+	for _, query := range queries {
+		entries = append(entries, &domain.SuggestionEntry{
+			Kind:   domain.SuggestionQuery,
+			Text:   query.Query,
+			Weight: query.SearchCount,
+		})
+	}
+
+	u.index.Load(entries)
+	return len(entries), nil
+}
+
+// ProductChanged suggests product while it is active and drops it otherwise
+func (u *SearchSuggestUsecase) ProductChanged(product *domain.Product) {
+	if product.Status != "active" {
+		u.index.Remove(domain.SuggestionProduct, product.ID)
+		return
+	}
+	u.index.Put(productSuggestion(product))
+}
+
+func (u *SearchSuggestUsecase) ProductRemoved(productID uint64) {
+	u.index.Remove(domain.SuggestionProduct, productID)
+}
+
+// CategoryChanged suggests category while it is active and drops it otherwise
+func (u *SearchSuggestUsecase) CategoryChanged(category *domain.Category) {
+	if category.Status != "active" {
+		u.index.Remove(domain.SuggestionCategory, category.ID)
+		return
+	}
+	u.index.Put(categorySuggestion(category))
+}
+
+func (u *SearchSuggestUsecase) CategoryRemoved(categoryID uint64) {
+	u.index.Remove(domain.SuggestionCategory, categoryID)
+}
+
+// StoreChanged suggests store while it is active and drops it otherwise
+func (u *SearchSuggestUsecase) StoreChanged(store *domain.Store) {
+	if store.Status != "active" {
+		u.index.Remove(domain.SuggestionStore, store.ID)
+		return
+	}
+	u.index.Put(storeSuggestion(store))
+}
+
+func productSuggestion(product *domain.Product) *domain.SuggestionEntry {
+	return &domain.SuggestionEntry{
+		Kind:   domain.SuggestionProduct,
+		ID:     product.ID,
+		Text:   product.NamaProduk,
+		Slug:   product.Slug,
+		Weight: int64(product.SoldCount),
+	}
+}
+
+func categorySuggestion(category *domain.Category) *domain.SuggestionEntry {
+	return &domain.SuggestionEntry{
+		Kind: domain.SuggestionCategory,
+		ID:   category.ID,
+		Text: category.Name,
+		Slug: category.Slug,
+	}
+}
+
+func storeSuggestion(store *domain.Store) *domain.SuggestionEntry {
+	return &domain.SuggestionEntry{
+		Kind: domain.SuggestionStore,
+		ID:   store.ID,
+		Text: store.Name,
+	}
+}
@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"testing"
+
+	"go-commerce/internal/domain"
+	"go-commerce/internal/usecase/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSearchSuggestUsecase_LogQuery_Normalizes(t *testing.T) {
+	// Setup
+	mockIndex := new(mocks.MockSearchSuggestIndex)
+	mockQueryRepo := new(mocks.MockSearchQueryRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockCategoryRepo := new(mocks.CategoryRepositoryMock)
+	mockStoreRepo := new(mocks.StoreRepositoryMock)
+	suggestUsecase := NewSearchSuggestUsecase(mockIndex, mockQueryRepo, mockProductRepo, mockCategoryRepo, mockStoreRepo)
+
+	// Mock expectations
+	mockQueryRepo.On("Increment", "kemeja flanel", mock.AnythingOfType("time.Time")).Return(nil)
+	mockIndex.On("RecordQuery", "kemeja flanel", int64(1)).Return()
+
+	// Execute
+	suggestUsecase.LogQuery("  Kemeja, FLANEL ")
+
+	// Assert
+	mockQueryRepo.AssertExpectations(t)
+	mockIndex.AssertExpectations(t)
+}
+
+func TestSearchSuggestUsecase_LogQuery_IgnoresEmptyQueries(t *testing.T) {
+	// Setup
+	mockIndex := new(mocks.MockSearchSuggestIndex)
+	mockQueryRepo := new(mocks.MockSearchQueryRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockCategoryRepo := new(mocks.CategoryRepositoryMock)
+	mockStoreRepo := new(mocks.StoreRepositoryMock)
+	suggestUsecase := NewSearchSuggestUsecase(mockIndex, mockQueryRepo, mockProductRepo, mockCategoryRepo, mockStoreRepo)
+
+	// Execute
+	suggestUsecase.LogQuery(" -- ")
+
+	// Assert
+	mockQueryRepo.AssertNotCalled(t, "Increment", mock.Anything, mock.Anything)
+	mockIndex.AssertNotCalled(t, "RecordQuery", mock.Anything, mock.Anything)
+}
+
+func TestSearchSuggestUsecase_Rebuild_LoadsActiveEntries(t *testing.T) {
+	// Setup
+	mockIndex := new(mocks.MockSearchSuggestIndex)
+	mockQueryRepo := new(mocks.MockSearchQueryRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockCategoryRepo := new(mocks.CategoryRepositoryMock)
+	mockStoreRepo := new(mocks.StoreRepositoryMock)
+	suggestUsecase := NewSearchSuggestUsecase(mockIndex, mockQueryRepo, mockProductRepo, mockCategoryRepo, mockStoreRepo)
+	var loaded []*domain.SuggestionEntry
+
+	// Mock expectations
+	mockProductRepo.On("GetAll", suggestRebuildBatchSize, 0, "", "").Return([]*domain.Product{
+		{ID: 1, NamaProduk: "Kemeja Flanel", Slug: "kemeja-flanel", Status: "active", SoldCount: 12},
+	}, int64(1), nil)
+	mockCategoryRepo.On("GetAll", suggestRebuildBatchSize, 0).Return([]*domain.Category{
+		{ID: 10, Name: "Kemeja", Slug: "kemeja", Status: "active"},
+		{ID: 11, Name: "Jas", Slug: "jas", Status: "inactive"},
+	}, int64(2), nil)
+	mockStoreRepo.On("GetActiveStores", suggestRebuildBatchSize, 0, "").Return([]*domain.Store{
+		{ID: 100, Name: "Toko Baju", Status: "active"},
+	}, int64(1), nil)
+	mockQueryRepo.On("GetPopular", popularQueryLimit).Return([]*domain.SearchQuery{
+		{Query: "kemeja", SearchCount: 40},
+	}, nil)
+	mockIndex.On("Load", mock.Anything).Run(func(args mock.Arguments) {
+		loaded = args.Get(0).([]*domain.SuggestionEntry)
+	}).Return()
+
+	// Execute
+	count, err := suggestUsecase.Rebuild()
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 4, count)
+	assert.Equal(t, []*domain.SuggestionEntry{
+		{Kind: domain.SuggestionProduct, ID: 1, Text: "Kemeja Flanel", Slug: "kemeja-flanel", Weight: 12},
+		{Kind: domain.SuggestionCategory, ID: 10, Text: "Kemeja", Slug: "kemeja"},
+		{Kind: domain.SuggestionStore, ID: 100, Text: "Toko Baju"},
+		{Kind: domain.SuggestionQuery, Text: "kemeja", Weight: 40},
+	}, loaded)
+}
+
+func TestSearchSuggestUsecase_StoreChanged_DropsInactiveStores(t *testing.T) {
+	// Setup
+	mockIndex := new(mocks.MockSearchSuggestIndex)
+	mockQueryRepo := new(mocks.MockSearchQueryRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockCategoryRepo := new(mocks.CategoryRepositoryMock)
+	mockStoreRepo := new(mocks.StoreRepositoryMock)
+	suggestUsecase := NewSearchSuggestUsecase(mockIndex, mockQueryRepo, mockProductRepo, mockCategoryRepo, mockStoreRepo)
+
+	// Mock expectations
+	mockIndex.On("Remove", domain.SuggestionStore, uint64(100)).Return()
+
+	// Execute
+	suggestUsecase.StoreChanged(&domain.Store{ID: 100, Name: "Toko Baju", Status: "suspended"})
+
+	// Assert
+	mockIndex.AssertExpectations(t)
+	mockIndex.AssertNotCalled(t, "Put", mock.Anything)
+}
@@ -0,0 +1,230 @@
+package usecase
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"go-commerce/internal/domain"
+)
+
+const (
+	// trackingPollBatch is how many shipments are loaded per query
+	trackingPollBatch = 50
+	// trackingPollLimit caps the shipments polled per run so one slow courier
+	// cannot keep the poller busy forever
+	trackingPollLimit = 500
+)
+
+// ShipmentUsecase records how orders are shipped and follows the parcels at
+// the couriers until they are delivered
+type ShipmentUsecase struct {
+	shipmentRepo    domain.ShipmentRepository
+	transactionRepo domain.TransactionRepository
+	storeRepo       domain.StoreRepository
+	orderStatus     *OrderStatusUsecase
+	tracker         domain.CourierTracker
+}
+
+func NewShipmentUsecase(
+	shipmentRepo domain.ShipmentRepository,
+	transactionRepo domain.TransactionRepository,
+	storeRepo domain.StoreRepository,
+	orderStatus *OrderStatusUsecase,
+	tracker domain.CourierTracker,
+) *ShipmentUsecase {
+	return &ShipmentUsecase{
+		shipmentRepo:    shipmentRepo,
+		transactionRepo: transactionRepo,
+		storeRepo:       storeRepo,
+		orderStatus:     orderStatus,
+		tracker:         tracker,
+	}
+}
+
+// ShipOrder - Seller hands the order to a courier. The order is marked shipped
+// and the shipment recorded in one database transaction.
+func (u *ShipmentUsecase) ShipOrder(sellerID, transactionID uint64, req *domain.ShipOrderRequest) (*domain.Shipment, error) {
+	transaction, err := u.transactionRepo.GetByID(transactionID)
+	if err != nil {
+		return nil, errors.New("transaction not found")
+	}
+
+	// Validate seller owns the store of this order
+	store, err := u.storeRepo.GetByID(transaction.StoreID)
+	if err != nil || store.UserID != sellerID {
+		return nil, errors.New("forbidden")
+	}
+
+	shipment := &domain.Shipment{
+		TransactionID:  transactionID,
+		Courier:        req.Courier,
+		Service:        req.Service,
+		TrackingNumber: req.TrackingNumber,
+		Status:         domain.ShipmentStatusInTransit,
+	}
+	if req.EstimatedDeliveryDate != "" {
+		estimated, err := time.ParseInLocation("2006-01-02", req.EstimatedDeliveryDate, time.Local)
+		if err != nil {
+			return nil, errors.New("invalid estimated delivery date")
+		}
+		year, month, day := time.Now().Date()
+		if estimated.Before(time.Date(year, month, day, 0, 0, 0, 0, time.Local)) {
+			return nil, errors.New("estimated delivery date is in the past")
+		}
+		shipment.EstimatedDeliveryDate = &estimated
+	}
+
+	dbTx, err := u.transactionRepo.BeginTx()
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.orderStatus.TransitionWithTx(dbTx, transactionID, StatusChange{
+		Field:  domain.StatusFieldOrder,
+		From:   transaction.OrderStatus,
+		To:     domain.OrderStatusShipped,
+		Actor:  domain.StatusActor{Role: domain.StatusActorSeller, UserID: sellerID},
+		Reason: "shipped with " + req.Courier + " " + req.TrackingNumber,
+	})
+	if err != nil {
+		u.transactionRepo.RollbackTx(dbTx)
+		return nil, err
+	}
+
+	if err := u.shipmentRepo.CreateWithTx(dbTx, shipment); err != nil {
+		u.transactionRepo.RollbackTx(dbTx)
+		return nil, err
+	}
+
+	if err := u.transactionRepo.CommitTx(dbTx); err != nil {
+		return nil, err
+	}
+
+	return shipment, nil
+}
+
+// GetShipment returns the shipment of an order with its tracking events. Only
+// the buyer, the seller of the order and admins can see it.
+func (u *ShipmentUsecase) GetShipment(userID, transactionID uint64, isAdmin bool) (*domain.Shipment, error) {
+	transaction, err := u.transactionRepo.GetByID(transactionID)
+	if err != nil {
+		return nil, errors.New("transaction not found")
+	}
+
+	if !isAdmin && transaction.UserID != userID {
+		store, err := u.storeRepo.GetByID(transaction.StoreID)
+		if err != nil || store.UserID != userID {
+			return nil, errors.New("forbidden")
+		}
+	}
+
+	shipment, err := u.shipmentRepo.GetByTransactionID(transactionID)
+	if err != nil {
+		return nil, errors.New("shipment not found")
+	}
+
+	return shipment, nil
+}
+
+// PollTracking asks the couriers for news on every parcel still in transit,
+// stores new tracking events and marks orders delivered once the courier
+// reports delivery. It returns how many orders were marked delivered. Events
+// are deduplicated and the delivery transition is conditional, so running it
+// on several instances at once is harmless.
+func (u *ShipmentUsecase) PollTracking() (int, error) {
+	// Shipments polled during this run are stamped at or after polledBefore
+	// and drop out of the next batch
+	polledBefore := time.Now().Truncate(time.Second)
+
+	delivered := 0
+	polled := 0
+	for polled < trackingPollLimit {
+		shipments, err := u.shipmentRepo.GetDueForTracking(polledBefore, trackingPollBatch)
+		if err != nil {
+			return delivered, err
+		}
+		if len(shipments) == 0 {
+			break
+		}
+
+		for _, shipment := range shipments {
+			polled++
+			ok, err := u.pollShipment(shipment)
+			if err != nil {
+				log.Printf("Error polling tracking of shipment %d (%s %s): %v", shipment.ID, shipment.Courier, shipment.TrackingNumber, err)
+			}
+			if ok {
+				delivered++
+			}
+		}
+	}
+
+	return delivered, nil
+}
+
+// pollShipment tracks one parcel and reports whether its order was marked
+// delivered
+func (u *ShipmentUsecase) pollShipment(shipment *domain.Shipment) (bool, error) {
+	// Stamp the poll first so a failing courier is retried on the next run
+	// instead of the next batch
+	if err := u.shipmentRepo.MarkPolled(shipment.ID, time.Now()); err != nil {
+		return false, err
+	}
+
+	result, err := u.tracker.Track(shipment.Courier, shipment.TrackingNumber)
+	if err != nil {
+		return false, err
+	}
+
+	events := make([]*domain.ShipmentEvent, 0, len(result.Events))
+	for _, event := range result.Events {
+		events = append(events, &domain.ShipmentEvent{
+			Status:      event.Status,
+			Description: event.Description,
+			Location:    event.Location,
+			OccurredAt:  event.OccurredAt,
+		})
+	}
+	if _, err := u.shipmentRepo.AddEvents(shipment.ID, events); err != nil {
+		return false, err
+	}
+
+	if result.DeliveredAt == nil {
+		return false, nil
+	}
+
+	if err := u.markDelivered(shipment, *result.DeliveredAt); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// markDelivered moves the order from shipped to delivered on behalf of the
+// courier and closes the shipment
+func (u *ShipmentUsecase) markDelivered(shipment *domain.Shipment, deliveredAt time.Time) error {
+	dbTx, err := u.transactionRepo.BeginTx()
+	if err != nil {
+		return err
+	}
+
+	err = u.orderStatus.TransitionWithTx(dbTx, shipment.TransactionID, StatusChange{
+		Field:  domain.StatusFieldOrder,
+		From:   domain.OrderStatusShipped,
+		To:     domain.OrderStatusDelivered,
+		Actor:  domain.SystemActor,
+		Reason: "delivered per courier tracking",
+		At:     deliveredAt,
+	})
+	if err != nil {
+		u.transactionRepo.RollbackTx(dbTx)
+		return err
+	}
+
+	if err := u.shipmentRepo.MarkDeliveredWithTx(dbTx, shipment.ID, deliveredAt); err != nil {
+		u.transactionRepo.RollbackTx(dbTx)
+		return err
+	}
+
+	return u.transactionRepo.CommitTx(dbTx)
+}
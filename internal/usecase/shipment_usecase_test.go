@@ -0,0 +1,175 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"go-commerce/internal/domain"
+	"go-commerce/internal/usecase/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestShipmentUsecase_ShipOrder_RecordsShipment(t *testing.T) {
+	// Setup
+	mockShipmentRepo := new(mocks.MockShipmentRepository)
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockStoreRepo := new(mocks.StoreRepositoryMock)
+	mockHistoryRepo := new(mocks.MockTransactionStatusHistoryRepository)
+	mockTracker := new(mocks.MockCourierTracker)
+
+	shipmentUsecase := NewShipmentUsecase(
+		mockShipmentRepo,
+		mockTransactionRepo,
+		mockStoreRepo,
+		NewOrderStatusUsecase(mockTransactionRepo, mockHistoryRepo),
+		mockTracker,
+	)
+	mockTx := "mock_transaction"
+	estimated := time.Now().AddDate(0, 0, 3).Format("2006-01-02")
+	req := &domain.ShipOrderRequest{Courier: "jne", Service: "REG", TrackingNumber: "JNE123", EstimatedDeliveryDate: estimated}
+
+	// Mock expectations
+	mockTransactionRepo.On("GetByID", uint64(7)).Return(&domain.Transaction{ID: 7, StoreID: 10, Status: "paid", OrderStatus: "processed"}, nil)
+	mockStoreRepo.On("GetByID", uint64(10)).Return(&domain.Store{ID: 10, UserID: 2}, nil)
+	mockTransactionRepo.On("BeginTx").Return(mockTx, nil)
+	mockTransactionRepo.On("TransitionOrderStatusWithTx", mockTx, uint64(7), "processed", "shipped", mock.AnythingOfType("time.Time")).Return(true, nil)
+	mockHistoryRepo.On("CreateWithTx", mockTx, mock.AnythingOfType("*domain.TransactionStatusHistory")).Return(nil)
+	mockShipmentRepo.On("CreateWithTx", mockTx, mock.AnythingOfType("*domain.Shipment")).Return(nil)
+	mockTransactionRepo.On("CommitTx", mockTx).Return(nil)
+
+	// Execute
+	shipment, err := shipmentUsecase.ShipOrder(2, 7, req)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), shipment.TransactionID)
+	assert.Equal(t, "JNE123", shipment.TrackingNumber)
+	assert.Equal(t, domain.ShipmentStatusInTransit, shipment.Status)
+	assert.Equal(t, estimated, shipment.EstimatedDeliveryDate.Format("2006-01-02"))
+	mockTransactionRepo.AssertExpectations(t)
+	mockShipmentRepo.AssertExpectations(t)
+}
+
+func TestShipmentUsecase_ShipOrder_NotProcessed(t *testing.T) {
+	// Setup
+	mockShipmentRepo := new(mocks.MockShipmentRepository)
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockStoreRepo := new(mocks.StoreRepositoryMock)
+	mockHistoryRepo := new(mocks.MockTransactionStatusHistoryRepository)
+	mockTracker := new(mocks.MockCourierTracker)
+
+	shipmentUsecase := NewShipmentUsecase(
+		mockShipmentRepo,
+		mockTransactionRepo,
+		mockStoreRepo,
+		NewOrderStatusUsecase(mockTransactionRepo, mockHistoryRepo),
+		mockTracker,
+	)
+	mockTx := "mock_transaction"
+	req := &domain.ShipOrderRequest{Courier: "jne", Service: "REG", TrackingNumber: "JNE123"}
+
+	// Mock expectations - created orders must be processed before shipping
+	mockTransactionRepo.On("GetByID", uint64(7)).Return(&domain.Transaction{ID: 7, StoreID: 10, Status: "paid", OrderStatus: "created"}, nil)
+	mockStoreRepo.On("GetByID", uint64(10)).Return(&domain.Store{ID: 10, UserID: 2}, nil)
+	mockTransactionRepo.On("BeginTx").Return(mockTx, nil)
+	mockTransactionRepo.On("RollbackTx", mockTx).Return(nil)
+
+	// Execute
+	shipment, err := shipmentUsecase.ShipOrder(2, 7, req)
+
+	// Assert
+	assert.EqualError(t, err, "INVALID_STATUS_TRANSITION")
+	assert.Nil(t, shipment)
+	mockShipmentRepo.AssertNotCalled(t, "CreateWithTx", mock.Anything, mock.Anything)
+}
+
+func TestShipmentUsecase_GetShipment_Access(t *testing.T) {
+	// Setup
+	mockShipmentRepo := new(mocks.MockShipmentRepository)
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockStoreRepo := new(mocks.StoreRepositoryMock)
+	mockHistoryRepo := new(mocks.MockTransactionStatusHistoryRepository)
+	mockTracker := new(mocks.MockCourierTracker)
+
+	shipmentUsecase := NewShipmentUsecase(
+		mockShipmentRepo,
+		mockTransactionRepo,
+		mockStoreRepo,
+		NewOrderStatusUsecase(mockTransactionRepo, mockHistoryRepo),
+		mockTracker,
+	)
+	shipment := &domain.Shipment{ID: 3, TransactionID: 7, Courier: "jne", TrackingNumber: "JNE123"}
+
+	// Mock expectations
+	mockTransactionRepo.On("GetByID", uint64(7)).Return(&domain.Transaction{ID: 7, UserID: 1, StoreID: 10}, nil)
+	mockStoreRepo.On("GetByID", uint64(10)).Return(&domain.Store{ID: 10, UserID: 2}, nil)
+	mockShipmentRepo.On("GetByTransactionID", uint64(7)).Return(shipment, nil)
+
+	// Execute
+	buyerShipment, errBuyer := shipmentUsecase.GetShipment(1, 7, false)
+	_, errSeller := shipmentUsecase.GetShipment(2, 7, false)
+	_, errStranger := shipmentUsecase.GetShipment(3, 7, false)
+
+	// Assert
+	assert.NoError(t, errBuyer)
+	assert.Equal(t, shipment, buyerShipment)
+	assert.NoError(t, errSeller)
+	assert.EqualError(t, errStranger, "forbidden")
+}
+
+func TestShipmentUsecase_PollTracking_MarksDelivered(t *testing.T) {
+	// Setup
+	mockShipmentRepo := new(mocks.MockShipmentRepository)
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockStoreRepo := new(mocks.StoreRepositoryMock)
+	mockHistoryRepo := new(mocks.MockTransactionStatusHistoryRepository)
+	mockTracker := new(mocks.MockCourierTracker)
+
+	shipmentUsecase := NewShipmentUsecase(
+		mockShipmentRepo,
+		mockTransactionRepo,
+		mockStoreRepo,
+		NewOrderStatusUsecase(mockTransactionRepo, mockHistoryRepo),
+		mockTracker,
+	)
+	mockTx := "mock_transaction"
+	inTransit := &domain.Shipment{ID: 3, TransactionID: 7, Courier: "jne", TrackingNumber: "JNE123"}
+	delivered := &domain.Shipment{ID: 4, TransactionID: 8, Courier: "sicepat", TrackingNumber: "SCP456"}
+	pickedUp := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	deliveredAt := time.Date(2026, 10, 17, 14, 0, 0, 0, time.UTC)
+
+	// Mock expectations - one batch, then nothing is due any more
+	mockShipmentRepo.On("GetDueForTracking", mock.AnythingOfType("time.Time"), trackingPollBatch).Return([]*domain.Shipment{inTransit, delivered}, nil).Once()
+	mockShipmentRepo.On("GetDueForTracking", mock.AnythingOfType("time.Time"), trackingPollBatch).Return([]*domain.Shipment{}, nil).Once()
+	mockShipmentRepo.On("MarkPolled", mock.Anything, mock.AnythingOfType("time.Time")).Return(nil)
+	mockTracker.On("Track", "jne", "JNE123").Return(&domain.TrackingResult{
+		Events: []domain.TrackingEvent{{Status: "picked_up", OccurredAt: pickedUp}},
+	}, nil)
+	mockTracker.On("Track", "sicepat", "SCP456").Return(&domain.TrackingResult{
+		Events: []domain.TrackingEvent{
+			{Status: "picked_up", OccurredAt: pickedUp},
+			{Status: "delivered", OccurredAt: deliveredAt},
+		},
+		DeliveredAt: &deliveredAt,
+	}, nil)
+	mockShipmentRepo.On("AddEvents", uint64(3), mock.Anything).Return(1, nil)
+	mockShipmentRepo.On("AddEvents", uint64(4), mock.Anything).Return(2, nil)
+	mockTransactionRepo.On("BeginTx").Return(mockTx, nil)
+	mockTransactionRepo.On("TransitionOrderStatusWithTx", mockTx, uint64(8), "shipped", "delivered", deliveredAt).Return(true, nil)
+	mockHistoryRepo.On("CreateWithTx", mockTx, mock.AnythingOfType("*domain.TransactionStatusHistory")).Return(nil)
+	mockShipmentRepo.On("MarkDeliveredWithTx", mockTx, uint64(4), deliveredAt).Return(nil)
+	mockTransactionRepo.On("CommitTx", mockTx).Return(nil)
+
+	// Execute
+	count, err := shipmentUsecase.PollTracking()
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	mockTransactionRepo.AssertNotCalled(t, "TransitionOrderStatusWithTx", mock.Anything, uint64(7), mock.Anything, mock.Anything, mock.Anything)
+	mockShipmentRepo.AssertNumberOfCalls(t, "MarkPolled", 2)
+	mockShipmentRepo.AssertExpectations(t)
+	mockHistoryRepo.AssertExpectations(t)
+}
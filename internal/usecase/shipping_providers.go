@@ -0,0 +1,57 @@
+package usecase
+
+import (
+	"log"
+
+	"go-commerce/internal/domain"
+)
+
+// ShippingRateProviders routes each courier to the provider that prices its
+// services, e.g. the rate table or a courier's own rate API
+type ShippingRateProviders struct {
+	byCourier map[string]domain.ShippingRateProvider
+	providers []domain.ShippingRateProvider
+}
+
+func NewShippingRateProviders() *ShippingRateProviders {
+	return &ShippingRateProviders{
+		byCourier: make(map[string]domain.ShippingRateProvider),
+	}
+}
+
+// Register makes provider price the given couriers. A later registration of
+// the same courier replaces the earlier one.
+func (p *ShippingRateProviders) Register(provider domain.ShippingRateProvider, couriers ...string) {
+	known := false
+	for _, registered := range p.providers {
+		if registered == provider {
+			known = true
+		}
+	}
+	if !known {
+		p.providers = append(p.providers, provider)
+	}
+
+	for _, courier := range couriers {
+		p.byCourier[courier] = provider
+	}
+}
+
+// Rates collects the rates of every provider, keeping only the couriers each
+// provider is registered for. A failing provider only hides its own couriers.
+func (p *ShippingRateProviders) Rates(parcel domain.ShippingParcel) []domain.ShippingRate {
+	rates := make([]domain.ShippingRate, 0)
+	for _, provider := range p.providers {
+		providerRates, err := provider.Rates(parcel)
+		if err != nil {
+			log.Printf("Error getting shipping rates from %s: %v", provider.Name(), err)
+			continue
+		}
+		for _, rate := range providerRates {
+			if p.byCourier[rate.Courier] == provider {
+				rates = append(rates, rate)
+			}
+		}
+	}
+	return rates
+}
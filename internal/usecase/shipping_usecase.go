@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"errors"
+	"sort"
+
+	"go-commerce/internal/domain"
+)
+
+// ShippingUsecase prices what each store sends to the buyer, from the weight
+// of the parcel and the regions of the store and the delivery address
+type ShippingUsecase struct {
+	productRepo domain.ProductRepository
+	storeRepo   domain.StoreRepository
+	addressRepo domain.AddressRepository
+	providers   *ShippingRateProviders
+}
+
+func NewShippingUsecase(
+	productRepo domain.ProductRepository,
+	storeRepo domain.StoreRepository,
+	addressRepo domain.AddressRepository,
+	providers *ShippingRateProviders,
+) *ShippingUsecase {
+	return &ShippingUsecase{
+		productRepo: productRepo,
+		storeRepo:   storeRepo,
+		addressRepo: addressRepo,
+		providers:   providers,
+	}
+}
+
+// Quote returns the shipping options of every store in the items, cheapest
+// first, so the buyer can pick a courier service per store before checkout
+func (u *ShippingUsecase) Quote(userID uint64, req *domain.ShippingQuoteRequest) ([]*domain.ShippingQuote, error) {
+	if !u.addressRepo.CheckOwnership(req.AlamatPengiriman, userID) {
+		return nil, errors.New("address not found or access denied")
+	}
+
+	address, err := u.addressRepo.GetByID(req.AlamatPengiriman)
+	if err != nil {
+		return nil, errors.New("address not found or access denied")
+	}
+
+	// Group the parcel weight by store
+	var quotes []*domain.ShippingQuote
+	quotesByStore := make(map[uint64]*domain.ShippingQuote)
+	for _, item := range req.Items {
+		product, err := u.productRepo.GetByID(item.ProductID)
+		if err != nil {
+			return nil, errors.New("product not found or not available")
+		}
+
+		quote, ok := quotesByStore[product.IDToko]
+		if !ok {
+			quote = &domain.ShippingQuote{StoreID: product.IDToko}
+			quotesByStore[product.IDToko] = quote
+			quotes = append(quotes, quote)
+		}
+		quote.WeightGrams += product.Berat * item.Quantity
+	}
+
+	for _, quote := range quotes {
+		rates, err := u.storeRates(quote.StoreID, address, quote.WeightGrams)
+		if err != nil {
+			return nil, err
+		}
+		quote.Rates = rates
+	}
+
+	return quotes, nil
+}
+
+// RateForOrder prices the parcel of one store with the courier service the
+// buyer chose, or with the cheapest one when choice is nil
+func (u *ShippingUsecase) RateForOrder(storeID uint64, address *domain.Address, weightGrams int, choice *domain.ShippingChoiceRequest) (*domain.ShippingRate, error) {
+	rates, err := u.storeRates(storeID, address, weightGrams)
+	if err != nil {
+		return nil, err
+	}
+	if len(rates) == 0 {
+		return nil, errors.New("SHIPPING_NOT_AVAILABLE")
+	}
+
+	if choice == nil {
+		return &rates[0], nil
+	}
+
+	for i := range rates {
+		if rates[i].Courier == choice.Courier && rates[i].Service == choice.Service {
+			return &rates[i], nil
+		}
+	}
+	return nil, errors.New("SHIPPING_SERVICE_NOT_AVAILABLE")
+}
+
+// storeRates returns the rates from the store to the address, cheapest first
+func (u *ShippingUsecase) storeRates(storeID uint64, address *domain.Address, weightGrams int) ([]domain.ShippingRate, error) {
+	store, err := u.storeRepo.GetByID(storeID)
+	if err != nil {
+		return nil, errors.New("store not found")
+	}
+
+	rates := u.providers.Rates(domain.ShippingParcel{
+		OriginProvinceID:      store.ProvinceID,
+		OriginCityID:          store.CityID,
+		DestinationProvinceID: address.ProvinceID,
+		DestinationCityID:     address.CityID,
+		WeightGrams:           weightGrams,
+	})
+	sort.SliceStable(rates, func(i, j int) bool {
+		return rates[i].Fee < rates[j].Fee
+	})
+
+	return rates, nil
+}
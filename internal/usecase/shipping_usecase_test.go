@@ -0,0 +1,170 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"go-commerce/internal/domain"
+	"go-commerce/internal/usecase/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// shippingTestAddress is a Bandung address; the test stores ship from Jakarta
+var shippingTestAddress = &domain.Address{ID: 1, UserID: 1, ProvinceID: "32", CityID: "3273"}
+
+func TestShippingUsecase_Quote_GroupsWeightByStore(t *testing.T) {
+	// Setup
+	provider := new(mocks.MockShippingRateProvider)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockStoreRepo := new(mocks.StoreRepositoryMock)
+	mockAddressRepo := new(mocks.MockAddressRepository)
+	providers := NewShippingRateProviders()
+	providers.Register(provider, "jne", "sicepat")
+	shippingUsecase := NewShippingUsecase(mockProductRepo, mockStoreRepo, mockAddressRepo, providers)
+
+	req := &domain.ShippingQuoteRequest{
+		AlamatPengiriman: 1,
+		Items: []domain.CreateTransactionItemRequest{
+			{ProductID: 1, Quantity: 2},
+			{ProductID: 2, Quantity: 1},
+			{ProductID: 3, Quantity: 1},
+		},
+	}
+
+	// Mock expectations - products 1 and 3 belong to store 10, product 2 to store 20
+	mockAddressRepo.On("CheckOwnership", uint64(1), uint64(1)).Return(true)
+	mockAddressRepo.On("GetByID", uint64(1)).Return(shippingTestAddress, nil)
+	mockProductRepo.On("GetByID", uint64(1)).Return(&domain.Product{ID: 1, IDToko: 10, Berat: 400}, nil)
+	mockProductRepo.On("GetByID", uint64(2)).Return(&domain.Product{ID: 2, IDToko: 20, Berat: 1500}, nil)
+	mockProductRepo.On("GetByID", uint64(3)).Return(&domain.Product{ID: 3, IDToko: 10, Berat: 300}, nil)
+	mockStoreRepo.On("GetByID", uint64(10)).Return(&domain.Store{ID: 10, ProvinceID: "31", CityID: "3171"}, nil)
+	mockStoreRepo.On("GetByID", uint64(20)).Return(&domain.Store{ID: 20, ProvinceID: "31", CityID: "3174"}, nil)
+	provider.On("Rates", mock.MatchedBy(func(parcel domain.ShippingParcel) bool {
+		return parcel.WeightGrams == 1100 && parcel.OriginCityID == "3171" && parcel.DestinationCityID == "3273"
+	})).Return([]domain.ShippingRate{
+		{Courier: "jne", Service: "YES", Fee: 90000},
+		{Courier: "jne", Service: "REG", Fee: 24000},
+		// Priced by this provider but routed to another one
+		{Courier: "tiki", Service: "ECO", Fee: 10000},
+	}, nil)
+	provider.On("Rates", mock.MatchedBy(func(parcel domain.ShippingParcel) bool {
+		return parcel.WeightGrams == 1500 && parcel.OriginCityID == "3174"
+	})).Return([]domain.ShippingRate{{Courier: "sicepat", Service: "REG", Fee: 44000}}, nil)
+
+	// Execute
+	quotes, err := shippingUsecase.Quote(1, req)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, quotes, 2)
+	assert.Equal(t, uint64(10), quotes[0].StoreID)
+	assert.Equal(t, 1100, quotes[0].WeightGrams)
+	assert.Equal(t, []domain.ShippingRate{
+		{Courier: "jne", Service: "REG", Fee: 24000},
+		{Courier: "jne", Service: "YES", Fee: 90000},
+	}, quotes[0].Rates)
+	assert.Equal(t, uint64(20), quotes[1].StoreID)
+	assert.Len(t, quotes[1].Rates, 1)
+}
+
+func TestShippingUsecase_RateForOrder_UnknownService(t *testing.T) {
+	// Setup
+	provider := new(mocks.MockShippingRateProvider)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockStoreRepo := new(mocks.StoreRepositoryMock)
+	mockAddressRepo := new(mocks.MockAddressRepository)
+	providers := NewShippingRateProviders()
+	providers.Register(provider, "jne", "sicepat")
+	shippingUsecase := NewShippingUsecase(mockProductRepo, mockStoreRepo, mockAddressRepo, providers)
+
+	// Mock expectations
+	mockStoreRepo.On("GetByID", uint64(10)).Return(&domain.Store{ID: 10, ProvinceID: "31"}, nil)
+	provider.On("Rates", mock.Anything).Return([]domain.ShippingRate{{Courier: "jne", Service: "REG", Fee: 24000}}, nil)
+
+	// Execute
+	rate, err := shippingUsecase.RateForOrder(10, shippingTestAddress, 1000, &domain.ShippingChoiceRequest{StoreID: 10, Courier: "jne", Service: "YES"})
+
+	// Assert
+	assert.EqualError(t, err, "SHIPPING_SERVICE_NOT_AVAILABLE")
+	assert.Nil(t, rate)
+}
+
+func TestTransactionUsecase_CreateTransaction_AddsShippingFee(t *testing.T) {
+	// Setup
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockCheckoutRepo := new(mocks.MockCheckoutRepository)
+	mockTransactionItemRepo := new(mocks.MockTransactionItemRepository)
+	mockProductLogRepo := new(mocks.MockProductLogRepository)
+	mockUserRepo := new(mocks.MockUserRepository)
+	mockReservationRepo := new(mocks.MockStockReservationRepository)
+	provider := new(mocks.MockShippingRateProvider)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockStoreRepo := new(mocks.StoreRepositoryMock)
+	mockAddressRepo := new(mocks.MockAddressRepository)
+	providers := NewShippingRateProviders()
+	providers.Register(provider, "jne", "sicepat")
+	shippingUsecase := NewShippingUsecase(mockProductRepo, mockStoreRepo, mockAddressRepo, providers)
+
+	transactionUsecase := NewTransactionUsecase(
+		mockTransactionRepo,
+		mockCheckoutRepo,
+		mockTransactionItemRepo,
+		mockProductLogRepo,
+		mockProductRepo,
+		mockAddressRepo,
+		mockUserRepo,
+		mockStoreRepo,
+		NewStockReservationUsecase(mockReservationRepo, mockProductRepo, mockTransactionRepo, StockReservationConfig{HoldTTL: 30 * time.Minute}),
+		nil,
+		shippingUsecase,
+		newTestInvoiceNumberer(),
+		nil,
+	)
+
+	req := &domain.CreateTransactionRequest{
+		AlamatPengiriman: 1,
+		MetodeBayar:      "transfer",
+		Items:            []domain.CreateTransactionItemRequest{{ProductID: 1, Quantity: 3}},
+		Shipping:         []domain.ShippingChoiceRequest{{StoreID: 10, Courier: "jne", Service: "YES"}},
+	}
+
+	mockTx := "mock_transaction"
+
+	// Mock expectations
+	mockAddressRepo.On("CheckOwnership", uint64(1), uint64(1)).Return(true)
+	mockAddressRepo.On("GetByID", uint64(1)).Return(shippingTestAddress, nil)
+	mockUserRepo.On("GetByID", uint64(1)).Return(&domain.User{ID: 1}, nil)
+	mockTransactionRepo.On("BeginTx").Return(mockTx, nil)
+	mockProductRepo.On("GetByID", uint64(1)).Return(&domain.Product{ID: 1, HargaKonsumen: 10000, Stok: 5, IDToko: 10, Status: "active", Berat: 500}, nil)
+	mockStoreRepo.On("GetByID", uint64(10)).Return(&domain.Store{ID: 10, UserID: 2, Status: "active", ProvinceID: "31", CityID: "3171"}, nil)
+	provider.On("Rates", mock.MatchedBy(func(parcel domain.ShippingParcel) bool {
+		return parcel.WeightGrams == 1500
+	})).Return([]domain.ShippingRate{
+		{Courier: "jne", Service: "REG", Fee: 24000},
+		{Courier: "jne", Service: "YES", Fee: 90000},
+	}, nil)
+	mockProductLogRepo.On("Create", mock.AnythingOfType("*domain.ProductLog")).Return(nil)
+	mockCheckoutRepo.On("CreateWithTx", mockTx, mock.AnythingOfType("*domain.Checkout")).Return(nil)
+	mockTransactionRepo.On("CreateWithTx", mockTx, mock.AnythingOfType("*domain.Transaction")).Return(nil)
+	mockTransactionItemRepo.On("CreateWithTx", mockTx, mock.AnythingOfType("*domain.TransactionItem")).Return(nil)
+	mockProductRepo.On("GetStockWithLock", mockTx, uint64(1), uint64(0)).Return(5, nil)
+	mockProductRepo.On("UpdateHeldStockWithTx", mockTx, uint64(1), uint64(0), 3).Return(nil)
+	mockReservationRepo.On("CreateWithTx", mockTx, mock.AnythingOfType("*domain.StockReservation")).Return(nil)
+	mockTransactionRepo.On("CommitTx", mockTx).Return(nil)
+
+	// Execute
+	result, err := transactionUsecase.CreateTransaction(1, req)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 120000.0, result.HargaTotal)
+	order := result.Transactions[0]
+	assert.Equal(t, "jne", order.ShippingCourier)
+	assert.Equal(t, "YES", order.ShippingService)
+	assert.Equal(t, 1500, order.ShippingWeight)
+	assert.Equal(t, 90000.0, order.ShippingFee)
+	assert.Equal(t, 120000.0, order.HargaTotal)
+	assert.Equal(t, 30000.0, order.TransactionItems[0].HargaTotal)
+}
@@ -0,0 +1,278 @@
+package usecase
+
+import (
+	"errors"
+	"log"
+	"sort"
+	"time"
+
+	"go-commerce/internal/domain"
+)
+
+// StockReservationConfig controls how long checkouts hold stock before payment
+type StockReservationConfig struct {
+	HoldTTL time.Duration
+}
+
+// StockLine is a quantity of one product, or one variant of it, in one order
+// of a checkout
+type StockLine struct {
+	TransactionID uint64
+	ProductID     uint64
+	VariantID     uint64
+	Quantity      int
+}
+
+// stockKey identifies the row that keeps the stock of a line: a variant, or
+// the product itself when VariantID is 0
+type stockKey struct {
+	ProductID uint64
+	VariantID uint64
+}
+
+// StockReservationUsecase keeps the reservation ledger. Every change to a
+// hold happens while the stock row is locked, so the held stock counter on
+// the product or variant always matches the held reservations.
+type StockReservationUsecase struct {
+	reservationRepo domain.StockReservationRepository
+	productRepo     domain.ProductRepository
+	transactionRepo domain.TransactionRepository
+	config          StockReservationConfig
+}
+
+func NewStockReservationUsecase(
+	reservationRepo domain.StockReservationRepository,
+	productRepo domain.ProductRepository,
+	transactionRepo domain.TransactionRepository,
+	config StockReservationConfig,
+) *StockReservationUsecase {
+	return &StockReservationUsecase{
+		reservationRepo: reservationRepo,
+		productRepo:     productRepo,
+		transactionRepo: transactionRepo,
+		config:          config,
+	}
+}
+
+// HoldWithTx reserves the stock of every line for the checkout. It fails with
+// INSUFFICIENT_STOCK when another checkout already holds or bought the stock.
+func (u *StockReservationUsecase) HoldWithTx(dbTx interface{}, checkoutID uint64, lines []StockLine) error {
+	required := requiredStock(lines)
+
+	for _, key := range sortedStockKeys(required) {
+		available, err := u.productRepo.GetStockWithLock(dbTx, key.ProductID, key.VariantID)
+		if err != nil {
+			return errors.New("product not found or not available")
+		}
+		if available < required[key] {
+			return errors.New("INSUFFICIENT_STOCK")
+		}
+
+		if err := u.productRepo.UpdateHeldStockWithTx(dbTx, key.ProductID, key.VariantID, required[key]); err != nil {
+			return err
+		}
+	}
+
+	expiresAt := time.Now().Add(u.config.HoldTTL)
+	for _, line := range lines {
+		err := u.reservationRepo.CreateWithTx(dbTx, &domain.StockReservation{
+			CheckoutID:    checkoutID,
+			TransactionID: line.TransactionID,
+			ProductID:     line.ProductID,
+			VariantID:     line.VariantID,
+			Quantity:      line.Quantity,
+			Status:        domain.StockReservationHeld,
+			ExpiresAt:     expiresAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ConvertWithTx turns the holds of a paid checkout into stock deductions.
+// Lines whose hold was already released, e.g. after it expired, are bought
+// from the available stock instead; STOCK_NOT_AVAILABLE is returned when that
+// is no longer enough.
+func (u *StockReservationUsecase) ConvertWithTx(dbTx interface{}, checkoutID uint64, lines []StockLine) error {
+	required := requiredStock(lines)
+	keys := sortedStockKeys(required)
+
+	available := make(map[stockKey]int, len(keys))
+	for _, key := range keys {
+		stock, err := u.productRepo.GetStockWithLock(dbTx, key.ProductID, key.VariantID)
+		if err != nil {
+			return err
+		}
+		available[key] = stock
+	}
+
+	holds, err := u.reservationRepo.GetHeldByCheckoutIDWithTx(dbTx, checkoutID)
+	if err != nil {
+		return err
+	}
+
+	converted := make(map[stockKey]int)
+	for _, hold := range holds {
+		key := stockKey{ProductID: hold.ProductID, VariantID: hold.VariantID}
+		if _, ok := required[key]; !ok {
+			continue
+		}
+
+		ok, err := u.reservationRepo.TransitionWithTx(dbTx, hold.ID, domain.StockReservationHeld, domain.StockReservationConverted)
+		if err != nil {
+			return err
+		}
+		if ok {
+			converted[key] += hold.Quantity
+		}
+	}
+
+	sold := make(map[uint64]int)
+	for _, key := range keys {
+		// Held stock is already excluded from the available stock
+		if required[key]-converted[key] > available[key] {
+			return errors.New("STOCK_NOT_AVAILABLE")
+		}
+
+		if err := u.productRepo.UpdateStockWithTx(dbTx, key.ProductID, key.VariantID, required[key]); err != nil {
+			return err
+		}
+		if converted[key] > 0 {
+			if err := u.productRepo.UpdateHeldStockWithTx(dbTx, key.ProductID, key.VariantID, -converted[key]); err != nil {
+				return err
+			}
+		}
+		sold[key.ProductID] += required[key]
+	}
+
+	// The sold count is kept per product, whichever variants were bought
+	for _, productID := range sortedProductIDs(sold) {
+		if err := u.productRepo.UpdateSoldCountWithTx(dbTx, productID, sold[productID]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ReleaseCheckoutWithTx gives back every stock hold of the checkout
+func (u *StockReservationUsecase) ReleaseCheckoutWithTx(dbTx interface{}, checkoutID uint64) error {
+	holds, err := u.reservationRepo.GetHeldByCheckoutIDWithTx(dbTx, checkoutID)
+	if err != nil {
+		return err
+	}
+	return u.release(dbTx, holds)
+}
+
+// ReleaseTransactionWithTx gives back the stock holds of one order
+func (u *StockReservationUsecase) ReleaseTransactionWithTx(dbTx interface{}, transactionID uint64) error {
+	holds, err := u.reservationRepo.GetHeldByTransactionIDWithTx(dbTx, transactionID)
+	if err != nil {
+		return err
+	}
+	return u.release(dbTx, holds)
+}
+
+// ExtendHold keeps the checkout's stock held until at least until, e.g. for
+// as long as its payment intent can still be paid
+func (u *StockReservationUsecase) ExtendHold(checkoutID uint64, until time.Time) error {
+	return u.reservationRepo.ExtendByCheckoutID(checkoutID, until)
+}
+
+// ReleaseExpired gives back the stock of checkouts whose holds expired and
+// returns how many checkouts were released
+func (u *StockReservationUsecase) ReleaseExpired() (int, error) {
+	checkoutIDs, err := u.reservationRepo.GetExpiredCheckoutIDs(time.Now(), 100)
+	if err != nil {
+		return 0, err
+	}
+
+	released := 0
+	for _, checkoutID := range checkoutIDs {
+		dbTx, err := u.transactionRepo.BeginTx()
+		if err != nil {
+			return released, err
+		}
+
+		if err := u.ReleaseCheckoutWithTx(dbTx, checkoutID); err != nil {
+			u.transactionRepo.RollbackTx(dbTx)
+			log.Printf("Error releasing stock held by checkout %d: %v", checkoutID, err)
+			continue
+		}
+
+		if err := u.transactionRepo.CommitTx(dbTx); err != nil {
+			log.Printf("Error releasing stock held by checkout %d: %v", checkoutID, err)
+			continue
+		}
+		released++
+	}
+
+	return released, nil
+}
+
+func (u *StockReservationUsecase) release(dbTx interface{}, holds []*domain.StockReservation) error {
+	quantities := make(map[stockKey]int)
+	for _, hold := range holds {
+		quantities[stockKey{ProductID: hold.ProductID, VariantID: hold.VariantID}] += hold.Quantity
+	}
+
+	// Lock the stock rows first, in the same order as HoldWithTx
+	for _, key := range sortedStockKeys(quantities) {
+		if _, err := u.productRepo.GetStockWithLock(dbTx, key.ProductID, key.VariantID); err != nil {
+			return err
+		}
+	}
+
+	for _, hold := range holds {
+		ok, err := u.reservationRepo.TransitionWithTx(dbTx, hold.ID, domain.StockReservationHeld, domain.StockReservationReleased)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue // Settled by a concurrent release or payment
+		}
+
+		if err := u.productRepo.UpdateHeldStockWithTx(dbTx, hold.ProductID, hold.VariantID, -hold.Quantity); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func requiredStock(lines []StockLine) map[stockKey]int {
+	required := make(map[stockKey]int)
+	for _, line := range lines {
+		required[stockKey{ProductID: line.ProductID, VariantID: line.VariantID}] += line.Quantity
+	}
+	return required
+}
+
+// sortedStockKeys gives a fixed lock order, by product and then variant, so
+// concurrent checkouts of the same stock cannot deadlock
+func sortedStockKeys(quantities map[stockKey]int) []stockKey {
+	keys := make([]stockKey, 0, len(quantities))
+	for key := range quantities {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].ProductID != keys[j].ProductID {
+			return keys[i].ProductID < keys[j].ProductID
+		}
+		return keys[i].VariantID < keys[j].VariantID
+	})
+	return keys
+}
+
+// sortedProductIDs gives the same product order as sortedStockKeys
+func sortedProductIDs(quantities map[uint64]int) []uint64 {
+	productIDs := make([]uint64, 0, len(quantities))
+	for productID := range quantities {
+		productIDs = append(productIDs, productID)
+	}
+	sort.Slice(productIDs, func(i, j int) bool { return productIDs[i] < productIDs[j] })
+	return productIDs
+}
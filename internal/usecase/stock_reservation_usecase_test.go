@@ -0,0 +1,143 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"go-commerce/internal/domain"
+	"go-commerce/internal/usecase/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestStockReservationUsecase_HoldWithTx_RejectsHeldStock(t *testing.T) {
+	// Setup
+	mockReservationRepo := new(mocks.MockStockReservationRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	reservationUsecase := NewStockReservationUsecase(mockReservationRepo, mockProductRepo, mockTransactionRepo, StockReservationConfig{HoldTTL: 30 * time.Minute})
+	mockTx := "mock_transaction"
+
+	// Mock expectations - only one unit is left once other checkouts' holds are excluded
+	mockProductRepo.On("GetStockWithLock", mockTx, uint64(1), uint64(0)).Return(1, nil)
+
+	// Execute
+	err := reservationUsecase.HoldWithTx(mockTx, 3, []StockLine{{TransactionID: 7, ProductID: 1, Quantity: 2}})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Equal(t, "INSUFFICIENT_STOCK", err.Error())
+	mockProductRepo.AssertNotCalled(t, "UpdateHeldStockWithTx", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockReservationRepo.AssertNotCalled(t, "CreateWithTx", mock.Anything, mock.Anything)
+}
+
+func TestStockReservationUsecase_ConvertWithTx_ReleasedHoldNeedsAvailableStock(t *testing.T) {
+	// Setup
+	mockReservationRepo := new(mocks.MockStockReservationRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	reservationUsecase := NewStockReservationUsecase(mockReservationRepo, mockProductRepo, mockTransactionRepo, StockReservationConfig{HoldTTL: 30 * time.Minute})
+	mockTx := "mock_transaction"
+
+	// Mock expectations - the hold expired and the stock was sold to someone else
+	mockProductRepo.On("GetStockWithLock", mockTx, uint64(1), uint64(0)).Return(0, nil)
+	mockReservationRepo.On("GetHeldByCheckoutIDWithTx", mockTx, uint64(3)).Return([]*domain.StockReservation{}, nil)
+
+	// Execute
+	err := reservationUsecase.ConvertWithTx(mockTx, 3, []StockLine{{TransactionID: 7, ProductID: 1, Quantity: 2}})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Equal(t, "STOCK_NOT_AVAILABLE", err.Error())
+	mockProductRepo.AssertNotCalled(t, "UpdateStockWithTx", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestStockReservationUsecase_ConvertWithTx_DeductsEachVariant(t *testing.T) {
+	// Setup
+	mockReservationRepo := new(mocks.MockStockReservationRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	reservationUsecase := NewStockReservationUsecase(mockReservationRepo, mockProductRepo, mockTransactionRepo, StockReservationConfig{HoldTTL: 30 * time.Minute})
+	mockTx := "mock_transaction"
+
+	lines := []StockLine{
+		{TransactionID: 7, ProductID: 1, VariantID: 12, Quantity: 1},
+		{TransactionID: 7, ProductID: 1, VariantID: 11, Quantity: 2},
+	}
+	holds := []*domain.StockReservation{
+		{ID: 41, CheckoutID: 3, ProductID: 1, VariantID: 12, Quantity: 1, Status: domain.StockReservationHeld},
+		{ID: 42, CheckoutID: 3, ProductID: 1, VariantID: 11, Quantity: 2, Status: domain.StockReservationHeld},
+	}
+
+	// Mock expectations - each variant keeps its own stock, the product its sold count
+	mockProductRepo.On("GetStockWithLock", mockTx, uint64(1), uint64(11)).Return(0, nil)
+	mockProductRepo.On("GetStockWithLock", mockTx, uint64(1), uint64(12)).Return(0, nil)
+	mockReservationRepo.On("GetHeldByCheckoutIDWithTx", mockTx, uint64(3)).Return(holds, nil)
+	mockReservationRepo.On("TransitionWithTx", mockTx, mock.Anything, domain.StockReservationHeld, domain.StockReservationConverted).Return(true, nil)
+	mockProductRepo.On("UpdateStockWithTx", mockTx, uint64(1), uint64(11), 2).Return(nil).Once()
+	mockProductRepo.On("UpdateHeldStockWithTx", mockTx, uint64(1), uint64(11), -2).Return(nil).Once()
+	mockProductRepo.On("UpdateStockWithTx", mockTx, uint64(1), uint64(12), 1).Return(nil).Once()
+	mockProductRepo.On("UpdateHeldStockWithTx", mockTx, uint64(1), uint64(12), -1).Return(nil).Once()
+	mockProductRepo.On("UpdateSoldCountWithTx", mockTx, uint64(1), 3).Return(nil).Once()
+
+	// Execute
+	err := reservationUsecase.ConvertWithTx(mockTx, 3, lines)
+
+	// Assert
+	assert.NoError(t, err)
+	mockProductRepo.AssertExpectations(t)
+}
+
+func TestStockReservationUsecase_ReleaseCheckoutWithTx_SkipsSettledHolds(t *testing.T) {
+	// Setup
+	mockReservationRepo := new(mocks.MockStockReservationRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	reservationUsecase := NewStockReservationUsecase(mockReservationRepo, mockProductRepo, mockTransactionRepo, StockReservationConfig{HoldTTL: 30 * time.Minute})
+	mockTx := "mock_transaction"
+
+	holds := []*domain.StockReservation{
+		{ID: 41, CheckoutID: 3, ProductID: 1, Quantity: 2, Status: domain.StockReservationHeld},
+		{ID: 42, CheckoutID: 3, ProductID: 2, Quantity: 1, Status: domain.StockReservationHeld},
+	}
+
+	// Mock expectations - hold 42 was settled by a concurrent payment
+	mockReservationRepo.On("GetHeldByCheckoutIDWithTx", mockTx, uint64(3)).Return(holds, nil)
+	mockProductRepo.On("GetStockWithLock", mockTx, uint64(1), uint64(0)).Return(0, nil)
+	mockProductRepo.On("GetStockWithLock", mockTx, uint64(2), uint64(0)).Return(0, nil)
+	mockReservationRepo.On("TransitionWithTx", mockTx, uint64(41), domain.StockReservationHeld, domain.StockReservationReleased).Return(true, nil)
+	mockReservationRepo.On("TransitionWithTx", mockTx, uint64(42), domain.StockReservationHeld, domain.StockReservationReleased).Return(false, nil)
+	mockProductRepo.On("UpdateHeldStockWithTx", mockTx, uint64(1), uint64(0), -2).Return(nil).Once()
+
+	// Execute
+	err := reservationUsecase.ReleaseCheckoutWithTx(mockTx, 3)
+
+	// Assert
+	assert.NoError(t, err)
+	mockProductRepo.AssertExpectations(t)
+	mockProductRepo.AssertNotCalled(t, "UpdateHeldStockWithTx", mockTx, uint64(2), uint64(0), -1)
+}
+
+func TestStockReservationUsecase_ReleaseExpired_CommitsEachCheckout(t *testing.T) {
+	// Setup
+	mockReservationRepo := new(mocks.MockStockReservationRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	reservationUsecase := NewStockReservationUsecase(mockReservationRepo, mockProductRepo, mockTransactionRepo, StockReservationConfig{HoldTTL: 30 * time.Minute})
+	mockTx := "mock_transaction"
+
+	// Mock expectations
+	mockReservationRepo.On("GetExpiredCheckoutIDs", mock.AnythingOfType("time.Time"), 100).Return([]uint64{3, 4}, nil)
+	mockTransactionRepo.On("BeginTx").Return(mockTx, nil)
+	mockReservationRepo.On("GetHeldByCheckoutIDWithTx", mockTx, mock.AnythingOfType("uint64")).Return([]*domain.StockReservation{}, nil)
+	mockTransactionRepo.On("CommitTx", mockTx).Return(nil).Twice()
+
+	// Execute
+	released, err := reservationUsecase.ReleaseExpired()
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 2, released)
+	mockTransactionRepo.AssertExpectations(t)
+}
@@ -11,12 +11,16 @@ import (
 )
 
 type StoreUsecase struct {
-	storeRepo domain.StoreRepository
+	storeRepo     domain.StoreRepository
+	emailVerifier *EmailVerificationUsecase
+	suggestions   *SearchSuggestUsecase
 }
 
-func NewStoreUsecase(storeRepo domain.StoreRepository) *StoreUsecase {
+func NewStoreUsecase(storeRepo domain.StoreRepository, emailVerifier *EmailVerificationUsecase, suggestions *SearchSuggestUsecase) *StoreUsecase {
 	return &StoreUsecase{
-		storeRepo: storeRepo,
+		storeRepo:     storeRepo,
+		emailVerifier: emailVerifier,
+		suggestions:   suggestions,
 	}
 }
 
@@ -27,6 +31,12 @@ func (u *StoreUsecase) CreateStore(userID uint64, req *domain.CreateStoreRequest
 		return nil, errors.New("STORE_ALREADY_EXISTS")
 	}
 
+	if u.emailVerifier != nil {
+		if err := u.emailVerifier.EnsureCanOpenStore(userID); err != nil {
+			return nil, err
+		}
+	}
+
 	store := &domain.Store{
 		UserID:      userID,
 		Name:        req.Name,
@@ -39,6 +49,7 @@ func (u *StoreUsecase) CreateStore(userID uint64, req *domain.CreateStoreRequest
 	if err := u.storeRepo.Create(store); err != nil {
 		return nil, errors.New("failed to create store")
 	}
+	u.storeChanged(store)
 
 	// Get created store with relations
 	return u.storeRepo.GetByID(store.ID)
@@ -81,10 +92,17 @@ func (u *StoreUsecase) UpdateMyStore(userID uint64, req *domain.UpdateStoreReque
 	if req.PhotoURL != nil {
 		store.PhotoURL = *req.PhotoURL
 	}
+	if req.ProvinceID != nil {
+		store.ProvinceID = *req.ProvinceID
+	}
+	if req.CityID != nil {
+		store.CityID = *req.CityID
+	}
 
 	if err := u.storeRepo.Update(store); err != nil {
 		return nil, errors.New("failed to update store")
 	}
+	u.storeChanged(store)
 
 	return store, nil
 }
@@ -170,8 +188,14 @@ func (u *StoreUsecase) ActivateStore(userID uint64) error {
 		return errors.New("STORE_PROFILE_INCOMPLETE")
 	}
 
+	if u.emailVerifier != nil {
+		if err := u.emailVerifier.EnsureCanOpenStore(userID); err != nil {
+			return err
+		}
+	}
+
 	store.Status = "active"
-	return u.storeRepo.Update(store)
+	return u.updateStatus(store)
 }
 
 // DeactivateStore allows seller to deactivate their store
@@ -186,7 +210,7 @@ func (u *StoreUsecase) DeactivateStore(userID uint64) error {
 	}
 
 	store.Status = "inactive"
-	return u.storeRepo.Update(store)
+	return u.updateStatus(store)
 }
 
 // SuspendStore allows admin to suspend any store
@@ -201,7 +225,7 @@ func (u *StoreUsecase) SuspendStore(storeID uint64) error {
 	}
 
 	store.Status = "suspended"
-	return u.storeRepo.Update(store)
+	return u.updateStatus(store)
 }
 
 // UnsuspendStore allows admin to unsuspend a store
@@ -217,7 +241,23 @@ func (u *StoreUsecase) UnsuspendStore(storeID uint64) error {
 
 	// Set to inactive, let seller activate it
 	store.Status = "inactive"
-	return u.storeRepo.Update(store)
+	return u.updateStatus(store)
+}
+
+// updateStatus saves a status change and refreshes the store in the search
+// suggestions, which only list active stores
+func (u *StoreUsecase) updateStatus(store *domain.Store) error {
+	if err := u.storeRepo.Update(store); err != nil {
+		return err
+	}
+	u.storeChanged(store)
+	return nil
+}
+
+func (u *StoreUsecase) storeChanged(store *domain.Store) {
+	if u.suggestions != nil {
+		u.suggestions.StoreChanged(store)
+	}
 }
 
 // GetStorePublic returns store only if it's active
@@ -261,6 +301,23 @@ func (u *StoreUsecase) GetActiveStores(page, limit int, search string) ([]*domai
 
 	return stores, meta, nil
 }
+
+// GetActiveStoresByCursor pages the active stores by cursor, newest first
+// unless page.SortBy asks otherwise
+func (u *StoreUsecase) GetActiveStoresByCursor(search string, page *domain.CursorPage) ([]*domain.Store, *domain.CursorLinks, error) {
+	if err := normalizeCursorPage[domain.Store](page, "newest"); err != nil {
+		return nil, nil, err
+	}
+
+	stores, err := u.storeRepo.GetActiveStoresWithCursor(search, page)
+	if err != nil {
+		return nil, nil, errors.New("failed to get stores")
+	}
+
+	stores, links := cursorResult(stores, page)
+	return stores, links, nil
+}
+
 // ApproveStore allows admin to approve pending store
 // COMMENTED: Pending approval logic disabled
 /*
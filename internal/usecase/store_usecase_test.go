@@ -14,7 +14,7 @@ import (
 func TestStoreUsecase_GetMyStore_Success(t *testing.T) {
 	// Setup
 	mockStoreRepo := new(mocks.MockStoreRepository)
-	storeUsecase := NewStoreUsecase(mockStoreRepo)
+	storeUsecase := NewStoreUsecase(mockStoreRepo, nil, nil)
 
 	userID := uint64(1)
 	store := &domain.Store{
@@ -43,7 +43,7 @@ func TestStoreUsecase_GetMyStore_Success(t *testing.T) {
 func TestStoreUsecase_GetMyStore_NotFound(t *testing.T) {
 	// Setup
 	mockStoreRepo := new(mocks.MockStoreRepository)
-	storeUsecase := NewStoreUsecase(mockStoreRepo)
+	storeUsecase := NewStoreUsecase(mockStoreRepo, nil, nil)
 
 	userID := uint64(999)
 
@@ -64,7 +64,7 @@ func TestStoreUsecase_GetMyStore_NotFound(t *testing.T) {
 func TestStoreUsecase_UpdateMyStore_Success(t *testing.T) {
 	// Setup
 	mockStoreRepo := new(mocks.MockStoreRepository)
-	storeUsecase := NewStoreUsecase(mockStoreRepo)
+	storeUsecase := NewStoreUsecase(mockStoreRepo, nil, nil)
 
 	userID := uint64(1)
 	existingStore := &domain.Store{
@@ -101,7 +101,7 @@ func TestStoreUsecase_UpdateMyStore_Success(t *testing.T) {
 func TestStoreUsecase_GetStoreByID_Success(t *testing.T) {
 	// Setup
 	mockStoreRepo := new(mocks.MockStoreRepository)
-	storeUsecase := NewStoreUsecase(mockStoreRepo)
+	storeUsecase := NewStoreUsecase(mockStoreRepo, nil, nil)
 
 	storeID := uint64(1)
 	store := &domain.Store{
@@ -129,7 +129,7 @@ func TestStoreUsecase_GetStoreByID_Success(t *testing.T) {
 func TestStoreUsecase_GetAllStores_Success(t *testing.T) {
 	// Setup
 	mockStoreRepo := new(mocks.MockStoreRepository)
-	storeUsecase := NewStoreUsecase(mockStoreRepo)
+	storeUsecase := NewStoreUsecase(mockStoreRepo, nil, nil)
 
 	page := 1
 	limit := 10
@@ -163,7 +163,7 @@ func TestStoreUsecase_GetAllStores_Success(t *testing.T) {
 func TestStoreUsecase_GetAllStores_WithPagination(t *testing.T) {
 	// Setup
 	mockStoreRepo := new(mocks.MockStoreRepository)
-	storeUsecase := NewStoreUsecase(mockStoreRepo)
+	storeUsecase := NewStoreUsecase(mockStoreRepo, nil, nil)
 
 	page := 2
 	limit := 5
@@ -196,7 +196,7 @@ func TestStoreUsecase_GetAllStores_WithPagination(t *testing.T) {
 func TestStoreUsecase_CreateStore_Success(t *testing.T) {
 	// Setup
 	mockStoreRepo := new(mocks.MockStoreRepository)
-	storeUsecase := NewStoreUsecase(mockStoreRepo)
+	storeUsecase := NewStoreUsecase(mockStoreRepo, nil, nil)
 
 	userID := uint64(1)
 	req := &domain.CreateStoreRequest{
@@ -9,35 +9,67 @@ import (
 
 type TransactionUsecase struct {
 	transactionRepo     domain.TransactionRepository
+	checkoutRepo        domain.CheckoutRepository
 	transactionItemRepo domain.TransactionItemRepository
 	productLogRepo      domain.ProductLogRepository
 	productRepo         domain.ProductRepository
 	addressRepo         domain.AddressRepository
 	userRepo            domain.UserRepository
 	storeRepo           domain.StoreRepository
+	stockReservation    *StockReservationUsecase
+	orderStatus         *OrderStatusUsecase
+	shipping            *ShippingUsecase
+	invoiceNumberer     *InvoiceNumberer
+	emailVerifier       *EmailVerificationUsecase
 }
 
 func NewTransactionUsecase(
 	transactionRepo domain.TransactionRepository,
+	checkoutRepo domain.CheckoutRepository,
 	transactionItemRepo domain.TransactionItemRepository,
 	productLogRepo domain.ProductLogRepository,
 	productRepo domain.ProductRepository,
 	addressRepo domain.AddressRepository,
 	userRepo domain.UserRepository,
 	storeRepo domain.StoreRepository,
+	stockReservation *StockReservationUsecase,
+	orderStatus *OrderStatusUsecase,
+	shipping *ShippingUsecase,
+	invoiceNumberer *InvoiceNumberer,
+	emailVerifier *EmailVerificationUsecase,
 ) *TransactionUsecase {
 	return &TransactionUsecase{
 		transactionRepo:     transactionRepo,
+		checkoutRepo:        checkoutRepo,
 		transactionItemRepo: transactionItemRepo,
 		productLogRepo:      productLogRepo,
 		productRepo:         productRepo,
 		addressRepo:         addressRepo,
 		userRepo:            userRepo,
 		storeRepo:           storeRepo,
+		stockReservation:    stockReservation,
+		orderStatus:         orderStatus,
+		shipping:            shipping,
+		invoiceNumberer:     invoiceNumberer,
+		emailVerifier:       emailVerifier,
 	}
 }
 
-func (u *TransactionUsecase) CreateTransaction(userID uint64, req *domain.CreateTransactionRequest) (*domain.Transaction, error) {
+// CheckoutHook runs inside the checkout database transaction once the order
+// rows are written. Returning an error rolls the whole checkout back.
+type CheckoutHook func(dbTx interface{}, checkout *domain.Checkout) error
+
+// CreateTransaction creates a checkout for the requested items. The items are
+// split into one order per store so each seller handles only their own part,
+// while a single payment covers the whole checkout.
+func (u *TransactionUsecase) CreateTransaction(userID uint64, req *domain.CreateTransactionRequest) (*domain.Checkout, error) {
+	return u.CreateTransactionWithHook(userID, req, nil)
+}
+
+// CreateTransactionWithHook creates the checkout like CreateTransaction and
+// runs hook before committing, so callers can make their own writes atomic
+// with the order
+func (u *TransactionUsecase) CreateTransactionWithHook(userID uint64, req *domain.CreateTransactionRequest, hook CheckoutHook) (*domain.Checkout, error) {
 	// Validate address exists and belongs to user
 	if !u.addressRepo.CheckOwnership(req.AlamatPengiriman, userID) {
 		return nil, errors.New("address not found or access denied")
@@ -49,6 +81,12 @@ func (u *TransactionUsecase) CreateTransaction(userID uint64, req *domain.Create
 		return nil, errors.New("user not found")
 	}
 
+	if u.emailVerifier != nil {
+		if err := u.emailVerifier.EnsureCanCheckout(user); err != nil {
+			return nil, err
+		}
+	}
+
 	// Begin database transaction
 	dbTx, err := u.transactionRepo.BeginTx()
 	if err != nil {
@@ -62,40 +100,19 @@ func (u *TransactionUsecase) CreateTransaction(userID uint64, req *domain.Create
 		}
 	}()
 
+	now := time.Now()
 	var totalAmount float64
-	var transactionItems []*domain.TransactionItem
+	var orders []*domain.Transaction
+	ordersByStore := make(map[uint64]*domain.Transaction)
+	itemStock := make(map[*domain.TransactionItem]stockKey)
+	weights := make(map[uint64]int)
 
-	// Validate products and calculate total
+	// Validate products, calculate totals and group items by store
 	for _, itemReq := range req.Items {
-		product, err := u.productRepo.GetByID(itemReq.ProductID)
+		product, variant, err := u.ValidateItem(itemReq.ProductID, itemReq.VariantID, itemReq.Quantity)
 		if err != nil {
 			u.transactionRepo.RollbackTx(dbTx)
-			return nil, errors.New("product not found or not available")
-		}
-
-		// Get store and validate status
-		store, err := u.storeRepo.GetByID(product.IDToko)
-		if err != nil {
-			u.transactionRepo.RollbackTx(dbTx)
-			return nil, errors.New("store not found")
-		}
-
-		// Check if store is active
-		if store.Status != "active" {
-			u.transactionRepo.RollbackTx(dbTx)
-			return nil, errors.New("STORE_NOT_AVAILABLE")
-		}
-
-		// Check if product is active
-		if product.Status != "active" {
-			u.transactionRepo.RollbackTx(dbTx)
-			return nil, errors.New("PRODUCT_NOT_AVAILABLE")
-		}
-
-		// Check stock availability
-		if product.Stok < itemReq.Quantity {
-			u.transactionRepo.RollbackTx(dbTx)
-			return nil, errors.New("INSUFFICIENT_STOCK")
+			return nil, err
 		}
 
 		// Create product log first
@@ -109,6 +126,17 @@ func (u *TransactionUsecase) CreateTransaction(userID uint64, req *domain.Create
 			StoreID:       product.IDToko,
 			CategoryID:    product.IDCategory,
 		}
+		namaProduk := product.NamaProduk
+		berat := product.Berat
+		if variant != nil {
+			productLog.HargaReseller = variant.ResellerPriceFor(product)
+			productLog.HargaKonsumen = variant.PriceFor(product)
+			productLog.VariantID = variant.ID
+			productLog.VariantSKU = variant.SKU
+			productLog.VariantAttributes = variant.Attributes
+			namaProduk = fmt.Sprintf("%s (%s)", product.NamaProduk, variant.Label(product.Options))
+			berat = variant.WeightFor(product)
+		}
 
 		// Create product log synchronously to get ID
 		err = u.productLogRepo.Create(productLog)
@@ -117,49 +145,122 @@ func (u *TransactionUsecase) CreateTransaction(userID uint64, req *domain.Create
 			return nil, err
 		}
 
-		hargaSatuan := product.HargaKonsumen
+		hargaSatuan := productLog.HargaKonsumen
 		hargaTotal := hargaSatuan * float64(itemReq.Quantity)
 		totalAmount += hargaTotal
 
-		transactionItems = append(transactionItems, &domain.TransactionItem{
+		order, ok := ordersByStore[product.IDToko]
+		if !ok {
+			kodeInvoice, err := u.invoiceNumberer.InvoiceCode(product.IDToko, now)
+			if err != nil {
+				u.transactionRepo.RollbackTx(dbTx)
+				return nil, err
+			}
+
+			order = &domain.Transaction{
+				UserID:           userID,
+				StoreID:          product.IDToko,
+				AlamatPengiriman: req.AlamatPengiriman,
+				KodeInvoice:      kodeInvoice,
+				MetodeBayar:      req.MetodeBayar,
+				Status:           "pending",
+				OrderStatus:      "created",
+			}
+			ordersByStore[product.IDToko] = order
+			orders = append(orders, order)
+		}
+
+		item := &domain.TransactionItem{
 			ProductLogID:       productLog.ID,
 			StoreID:            product.IDToko,
 			Quantity:           itemReq.Quantity,
 			HargaSatuan:        hargaSatuan,
 			HargaTotal:         hargaTotal,
-			NamaProdukSnapshot: product.NamaProduk,
-		})
+			NamaProdukSnapshot: namaProduk,
+		}
+		itemStock[item] = stockKey{ProductID: product.ID, VariantID: productLog.VariantID}
+
+		order.HargaTotal += hargaTotal
+		order.TransactionItems = append(order.TransactionItems, item)
+		weights[product.IDToko] += berat * itemReq.Quantity
 	}
 
-	// Generate invoice code
-	invoiceCode := fmt.Sprintf("INV-%d-%d", userID, time.Now().Unix())
+	// Add the shipping fee line of every order to its total
+	if u.shipping != nil {
+		shippingTotal, err := u.addShippingFees(orders, weights, req)
+		if err != nil {
+			u.transactionRepo.RollbackTx(dbTx)
+			return nil, err
+		}
+		totalAmount += shippingTotal
+	}
 
-	// Create transaction
-	transaction := &domain.Transaction{
+	// Create the checkout the buyer pays for
+	kodeCheckout, err := u.invoiceNumberer.CheckoutCode(now)
+	if err != nil {
+		u.transactionRepo.RollbackTx(dbTx)
+		return nil, err
+	}
+
+	checkout := &domain.Checkout{
 		UserID:           userID,
 		AlamatPengiriman: req.AlamatPengiriman,
 		HargaTotal:       totalAmount,
-		KodeInvoice:      invoiceCode,
+		KodeCheckout:     kodeCheckout,
 		MetodeBayar:      req.MetodeBayar,
 		Status:           "pending",
-		OrderStatus:      "created",
 	}
 
-	err = u.transactionRepo.CreateWithTx(dbTx, transaction)
+	err = u.checkoutRepo.CreateWithTx(dbTx, checkout)
 	if err != nil {
 		u.transactionRepo.RollbackTx(dbTx)
 		return nil, err
 	}
 
-	// Create transaction items
-	for _, item := range transactionItems {
-		item.TransactionID = transaction.ID
-		
-		err = u.transactionItemRepo.CreateWithTx(dbTx, item)
+	// Create one order per store with its items
+	var lines []StockLine
+	for _, order := range orders {
+		items := order.TransactionItems
+		order.TransactionItems = nil
+		order.CheckoutID = checkout.ID
+
+		err = u.transactionRepo.CreateWithTx(dbTx, order)
 		if err != nil {
 			u.transactionRepo.RollbackTx(dbTx)
 			return nil, err
 		}
+
+		for _, item := range items {
+			item.TransactionID = order.ID
+
+			err = u.transactionItemRepo.CreateWithTx(dbTx, item)
+			if err != nil {
+				u.transactionRepo.RollbackTx(dbTx)
+				return nil, err
+			}
+
+			lines = append(lines, StockLine{
+				TransactionID: order.ID,
+				ProductID:     itemStock[item].ProductID,
+				VariantID:     itemStock[item].VariantID,
+				Quantity:      item.Quantity,
+			})
+		}
+		order.TransactionItems = items
+	}
+
+	// Hold the stock under row locks so two buyers cannot both get the last unit
+	err = u.stockReservation.HoldWithTx(dbTx, checkout.ID, lines)
+	if err != nil {
+		u.transactionRepo.RollbackTx(dbTx)
+		return nil, err
+	}
+
+	if hook != nil {
+		if err := hook(dbTx, checkout); err != nil {
+			u.transactionRepo.RollbackTx(dbTx)
+			return nil, err
+		}
 	}
 
 	// Commit transaction
@@ -170,10 +271,95 @@ func (u *TransactionUsecase) CreateTransaction(userID uint64, req *domain.Create
 	}
 
 	// Load relations for response
-	transaction.User = user
-	transaction.TransactionItems = transactionItems
+	checkout.User = user
+	checkout.Transactions = orders
 
-	return transaction, nil
+	return checkout, nil
+}
+
+// addShippingFees prices the parcel of every order with the courier service
+// the buyer chose for its store, or the cheapest one, and returns the sum of
+// the fees
+func (u *TransactionUsecase) addShippingFees(orders []*domain.Transaction, weights map[uint64]int, req *domain.CreateTransactionRequest) (float64, error) {
+	address, err := u.addressRepo.GetByID(req.AlamatPengiriman)
+	if err != nil {
+		return 0, errors.New("address not found or access denied")
+	}
+
+	choices := make(map[uint64]*domain.ShippingChoiceRequest, len(req.Shipping))
+	for i := range req.Shipping {
+		choices[req.Shipping[i].StoreID] = &req.Shipping[i]
+	}
+
+	var total float64
+	for _, order := range orders {
+		weight := weights[order.StoreID]
+		rate, err := u.shipping.RateForOrder(order.StoreID, address, weight, choices[order.StoreID])
+		if err != nil {
+			return 0, err
+		}
+
+		order.ShippingCourier = rate.Courier
+		order.ShippingService = rate.Service
+		order.ShippingWeight = weight
+		order.ShippingFee = rate.Fee
+		order.HargaTotal += rate.Fee
+		total += rate.Fee
+	}
+
+	return total, nil
+}
+
+// ValidateItem checks that quantity units of a product, or of one of its
+// variants, can be bought right now: the product, variant and store are
+// active and there is enough stock. Products with variants are only sold per
+// variant. The product and variant are returned whenever they were found, even
+// if the check fails.
+func (u *TransactionUsecase) ValidateItem(productID, variantID uint64, quantity int) (*domain.Product, *domain.ProductVariant, error) {
+	product, err := u.productRepo.GetByID(productID)
+	if err != nil {
+		return nil, nil, errors.New("product not found or not available")
+	}
+
+	var variant *domain.ProductVariant
+	if variantID != 0 {
+		variant = product.Variant(variantID)
+		if variant == nil {
+			return product, nil, errors.New("VARIANT_NOT_FOUND")
+		}
+	} else if product.HasVariants() {
+		return product, nil, errors.New("VARIANT_REQUIRED")
+	}
+
+	// Get store and validate status
+	store, err := u.storeRepo.GetByID(product.IDToko)
+	if err != nil {
+		return product, variant, errors.New("store not found")
+	}
+
+	// Check if store is active
+	if store.Status != "active" {
+		return product, variant, errors.New("STORE_NOT_AVAILABLE")
+	}
+
+	// Check if product is active
+	if product.Status != "active" {
+		return product, variant, errors.New("PRODUCT_NOT_AVAILABLE")
+	}
+
+	// Check stock availability, not counting stock held by unpaid checkouts
+	available := product.AvailableStock()
+	if variant != nil {
+		if variant.Status != "active" {
+			return product, variant, errors.New("VARIANT_NOT_AVAILABLE")
+		}
+		available = variant.AvailableStock()
+	}
+	if available < quantity {
+		return product, variant, errors.New("INSUFFICIENT_STOCK")
+	}
+
+	return product, variant, nil
 }
 
 func (u *TransactionUsecase) GetTransactionByID(userID, transactionID uint64) (*domain.Transaction, error) {
@@ -190,6 +376,21 @@ func (u *TransactionUsecase) GetTransactionByID(userID, transactionID uint64) (*
 	return transaction, nil
 }
 
+// GetTransactionByInvoice returns the order with the invoice code to its
+// buyer, the seller of its store or an admin
+func (u *TransactionUsecase) GetTransactionByInvoice(userID uint64, kodeInvoice string, isAdmin bool) (*domain.Transaction, error) {
+	transaction, err := u.transactionRepo.GetByInvoice(kodeInvoice)
+	if err != nil {
+		return nil, errors.New("transaction not found or access denied")
+	}
+
+	if !isAdmin && transaction.UserID != userID && !u.ownsStore(userID, transaction.StoreID) {
+		return nil, errors.New("transaction not found or access denied")
+	}
+
+	return transaction, nil
+}
+
 func (u *TransactionUsecase) GetMyTransactions(userID uint64, page, limit int) ([]*domain.Transaction, int64, error) {
 	if page < 1 {
 		page = 1
@@ -202,114 +403,164 @@ func (u *TransactionUsecase) GetMyTransactions(userID uint64, page, limit int) (
 	return u.transactionRepo.GetByUserID(userID, limit, offset)
 }
 
-func (u *TransactionUsecase) UpdatePaymentStatus(userID, transactionID uint64, status string) error {
-	// Validate status
-	validStatuses := []string{"pending", "paid", "cancelled", "shipped", "done"}
-	validStatus := false
-	for _, s := range validStatuses {
-		if s == status {
-			validStatus = true
-			break
-		}
+// GetMyTransactionsByCursor pages the user's transactions by cursor, newest
+// first unless page.SortBy asks otherwise
+func (u *TransactionUsecase) GetMyTransactionsByCursor(userID uint64, page *domain.CursorPage) ([]*domain.Transaction, *domain.CursorLinks, error) {
+	if err := normalizeCursorPage[domain.Transaction](page, "newest"); err != nil {
+		return nil, nil, err
 	}
-	if !validStatus {
-		return errors.New("invalid status")
+
+	transactions, err := u.transactionRepo.GetByUserIDWithCursor(userID, page)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	transactions, links := cursorResult(transactions, page)
+	return transactions, links, nil
+}
+
+// GetCheckoutByID returns a checkout of the buyer with all of its orders
+func (u *TransactionUsecase) GetCheckoutByID(userID, checkoutID uint64) (*domain.Checkout, error) {
+	checkout, err := u.checkoutRepo.GetByID(checkoutID)
+	if err != nil {
+		return nil, errors.New("checkout not found")
 	}
 
 	// Check ownership
+	if checkout.UserID != userID {
+		return nil, errors.New("checkout not found or access denied")
+	}
+
+	return checkout, nil
+}
+
+// ExtendStockHold keeps the stock of an unpaid checkout held until until
+func (u *TransactionUsecase) ExtendStockHold(checkoutID uint64, until time.Time) error {
+	return u.stockReservation.ExtendHold(checkoutID, until)
+}
+
+// GetSellerTransactions lists the orders placed at the seller's store
+func (u *TransactionUsecase) GetSellerTransactions(sellerID uint64, page, limit int) ([]*domain.Transaction, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	store, err := u.storeRepo.GetByUserID(sellerID)
+	if err != nil {
+		return nil, 0, errors.New("store not found")
+	}
+
+	offset := (page - 1) * limit
+	return u.transactionRepo.GetByStoreID(store.ID, limit, offset)
+}
+
+// GetSellerTransactionByID returns one order of the seller's store
+func (u *TransactionUsecase) GetSellerTransactionByID(sellerID, transactionID uint64) (*domain.Transaction, error) {
 	transaction, err := u.transactionRepo.GetByID(transactionID)
 	if err != nil {
-		return errors.New("transaction not found")
+		return nil, errors.New("transaction not found")
 	}
-	if transaction.UserID != userID {
-		return errors.New("access denied")
+
+	if !u.ownsStore(sellerID, transaction.StoreID) {
+		return nil, errors.New("transaction not found or access denied")
 	}
 
-	return u.transactionRepo.UpdateStatus(transactionID, status)
+	return transaction, nil
 }
 
-// OnPaymentPaid - Used by payment intent system
-func (u *TransactionUsecase) OnPaymentPaid(transactionID uint64) error {
-	transaction, err := u.transactionRepo.GetByID(transactionID)
+// MarkCheckoutPaidWithTx settles every order of a pending checkout and turns
+// their stock holds into deductions; paidAt is when the gateway received the
+// money. The caller settles the payment intent in the same dbTx. It fails
+// with STOCK_NOT_AVAILABLE when the holds expired and the stock was sold
+// meanwhile.
+func (u *TransactionUsecase) MarkCheckoutPaidWithTx(dbTx interface{}, checkoutID uint64, paidAt time.Time) error {
+	checkout, err := u.checkoutRepo.GetByID(checkoutID)
 	if err != nil {
 		return err
 	}
 
-	// Check if transaction is cancelled
-	if transaction.Status == "cancelled" || transaction.OrderStatus == "cancelled" {
+	// Check if checkout is cancelled
+	if checkout.Status == "cancelled" {
 		return errors.New("transaction cancelled")
 	}
 
 	// Idempotent check - prevent double processing
-	if transaction.Status == "paid" {
+	if checkout.Status == "paid" {
 		return nil // Already processed successfully
 	}
-	if transaction.Status != "pending" {
+	if checkout.Status != "pending" {
 		return errors.New("invalid transaction state")
 	}
 
-	// Begin atomic database transaction
-	dbTx, err := u.transactionRepo.BeginTx()
+	orders := activeOrders(checkout)
+
+	// Resolve the products of every order
+	var lines []StockLine
+	for _, order := range orders {
+		for _, item := range order.TransactionItems {
+			// Get product log to find actual product ID
+			productLog, err := u.productLogRepo.GetByID(item.ProductLogID)
+			if err != nil {
+				return errors.New("product log not found")
+			}
+
+			lines = append(lines, StockLine{
+				TransactionID: order.ID,
+				ProductID:     productLog.ProductID,
+				VariantID:     productLog.VariantID,
+				Quantity:      item.Quantity,
+			})
+		}
+	}
+
+	// Claim the checkout first so a concurrent expiry or failure cannot settle it too
+	claimed, err := u.checkoutRepo.MarkPaidWithTx(dbTx, checkoutID, paidAt)
 	if err != nil {
 		return err
 	}
+	if !claimed {
+		return errors.New("invalid transaction state")
+	}
 
-	defer func() {
-		if r := recover(); r != nil {
-			u.transactionRepo.RollbackTx(dbTx)
-			panic(r)
-		}
-	}()
-
-	// Lock and validate stock for all items atomically
-	for _, item := range transaction.TransactionItems {
-		// Get product log to find actual product ID
-		productLog, err := u.productLogRepo.GetByID(item.ProductLogID)
-		if err != nil {
-			u.transactionRepo.RollbackTx(dbTx)
-			return errors.New("product log not found")
-		}
-
-		productID := productLog.ProductID
+	// Turn the stock holds into deductions
+	err = u.stockReservation.ConvertWithTx(dbTx, checkoutID, lines)
+	if err != nil {
+		return err
+	}
 
-		// Lock product row and get current stock
-		currentStock, err := u.productRepo.GetStockWithLock(dbTx, productID)
+	// Mark every order as paid
+	for _, order := range orders {
+		err = u.orderStatus.TransitionWithTx(dbTx, order.ID, StatusChange{
+			Field:  domain.StatusFieldPayment,
+			From:   domain.PaymentStatusPending,
+			To:     domain.PaymentStatusPaid,
+			Actor:  domain.SystemActor,
+			Reason: "payment received",
+			At:     paidAt,
+		})
 		if err != nil {
-			u.transactionRepo.RollbackTx(dbTx)
 			return err
 		}
-
-		// Validate stock availability at payment time
-		if currentStock < item.Quantity {
-			u.transactionRepo.RollbackTx(dbTx)
-			// Mark transaction as failed due to insufficient stock
-			u.transactionRepo.UpdateStatus(transactionID, "failed")
-			return errors.New("STOCK_NOT_AVAILABLE")
-		}
 	}
 
-	// All stock validated - proceed with deduction
-	for _, item := range transaction.TransactionItems {
-		productLog, _ := u.productLogRepo.GetByID(item.ProductLogID)
-		productID := productLog.ProductID
+	return nil
+}
 
-		// Deduct stock atomically
-		err = u.productRepo.UpdateStockWithTx(dbTx, productID, item.Quantity)
-		if err != nil {
-			u.transactionRepo.RollbackTx(dbTx)
-			return err
-		}
+// OnPaymentFailed - Used by payment intent system
+func (u *TransactionUsecase) OnPaymentFailed(checkoutID uint64) error {
+	return u.failCheckout(checkoutID, "payment failed")
+}
 
-		// Update sold count
-		err = u.productRepo.UpdateSoldCountWithTx(dbTx, productID, item.Quantity)
-		if err != nil {
-			u.transactionRepo.RollbackTx(dbTx)
-			return err
-		}
+func (u *TransactionUsecase) failCheckout(checkoutID uint64, reason string) error {
+	dbTx, err := u.transactionRepo.BeginTx()
+	if err != nil {
+		return err
 	}
 
-	// Mark transaction as paid
-	err = u.transactionRepo.UpdateStatusWithTx(dbTx, transactionID, "paid")
+	err = u.FailCheckoutWithTx(dbTx, checkoutID, reason)
 	if err != nil {
 		u.transactionRepo.RollbackTx(dbTx)
 		return err
@@ -318,203 +569,229 @@ func (u *TransactionUsecase) OnPaymentPaid(transactionID uint64) error {
 	return u.transactionRepo.CommitTx(dbTx)
 }
 
-// OnPaymentFailed - Used by payment intent system
-func (u *TransactionUsecase) OnPaymentFailed(transactionID uint64) error {
-	transaction, err := u.transactionRepo.GetByID(transactionID)
+// FailCheckoutWithTx fails an unpaid checkout, cancels its orders and gives
+// back their stock holds. Checkouts that are no longer pending, e.g. because
+// they were paid meanwhile, are left alone. The reason goes to the status
+// history of the orders.
+func (u *TransactionUsecase) FailCheckoutWithTx(dbTx interface{}, checkoutID uint64, reason string) error {
+	checkout, err := u.checkoutRepo.GetByID(checkoutID)
 	if err != nil {
 		return err
 	}
 
 	// Idempotent check
-	if transaction.Status != "pending" {
+	if checkout.Status != "pending" {
 		return nil
 	}
 
-	// Update status
-	err = u.transactionRepo.UpdateStatus(transactionID, "failed")
+	failed, err := u.checkoutRepo.TransitionStatusWithTx(dbTx, checkoutID, "pending", "failed")
 	if err != nil {
 		return err
 	}
-
-	return u.transactionRepo.UpdateOrderStatus(transactionID, "cancelled")
-}
-
-// ProcessOrder - Seller processes order
-func (u *TransactionUsecase) ProcessOrder(sellerID, transactionID uint64) error {
-	transaction, err := u.transactionRepo.GetByID(transactionID)
-	if err != nil {
-		return errors.New("transaction not found")
+	if !failed {
+		return nil // Settled concurrently
 	}
 
-	// Check if transaction is cancelled
-	if transaction.Status == "cancelled" || transaction.OrderStatus == "cancelled" {
-		return errors.New("transaction cancelled")
+	err = u.stockReservation.ReleaseCheckoutWithTx(dbTx, checkoutID)
+	if err != nil {
+		return err
 	}
 
-	// Validate seller owns store in transaction
-	for _, item := range transaction.TransactionItems {
-		store, err := u.storeRepo.GetByID(item.StoreID)
-		if err != nil || store.UserID != sellerID {
-			return errors.New("forbidden: seller does not own store")
+	// Every order of the checkout is cancelled with it
+	for _, order := range activeOrders(checkout) {
+		err = u.orderStatus.TransitionWithTx(dbTx, order.ID, StatusChange{
+			Field:  domain.StatusFieldPayment,
+			From:   domain.PaymentStatusPending,
+			To:     domain.PaymentStatusFailed,
+			Actor:  domain.SystemActor,
+			Reason: reason,
+		})
+		if err != nil {
+			return err
 		}
-	}
-
-	// Validate payment status
-	if transaction.Status != "paid" {
-		return errors.New("payment not completed")
-	}
 
-	// Validate order status
-	if transaction.OrderStatus != "created" {
-		return errors.New("invalid state")
+		err = u.orderStatus.TransitionWithTx(dbTx, order.ID, StatusChange{
+			Field:  domain.StatusFieldOrder,
+			From:   domain.OrderStatusCreated,
+			To:     domain.OrderStatusCancelled,
+			Actor:  domain.SystemActor,
+			Reason: reason,
+		})
+		if err != nil {
+			return err
+		}
 	}
 
-	return u.transactionRepo.UpdateOrderStatus(transactionID, "processed")
+	return nil
 }
 
-// ShipOrder - Seller ships order
-func (u *TransactionUsecase) ShipOrder(sellerID, transactionID uint64) error {
+// ProcessOrder - Seller processes order
+func (u *TransactionUsecase) ProcessOrder(sellerID, transactionID uint64) error {
 	transaction, err := u.transactionRepo.GetByID(transactionID)
 	if err != nil {
 		return errors.New("transaction not found")
 	}
 
-	// Validate seller owns store
-	for _, item := range transaction.TransactionItems {
-		store, err := u.storeRepo.GetByID(item.StoreID)
-		if err != nil || store.UserID != sellerID {
-			return errors.New("forbidden")
-		}
+	// Validate seller owns the store of this order
+	if !u.ownsStore(sellerID, transaction.StoreID) {
+		return errors.New("forbidden: seller does not own store")
 	}
 
-	// Validate order status
-	if transaction.OrderStatus != "processed" {
-		return errors.New("order not ready")
+	// Only paid orders are fulfilled
+	if transaction.Status != domain.PaymentStatusPaid {
+		return errors.New("payment not completed")
 	}
 
-	return u.transactionRepo.UpdateOrderStatus(transactionID, "shipped")
+	return u.orderStatus.Transition(transactionID, StatusChange{
+		Field: domain.StatusFieldOrder,
+		From:  transaction.OrderStatus,
+		To:    domain.OrderStatusProcessed,
+		Actor: domain.StatusActor{Role: domain.StatusActorSeller, UserID: sellerID},
+	})
 }
 
-// ConfirmDelivered - Buyer confirms delivery
-func (u *TransactionUsecase) ConfirmDelivered(userID, transactionID uint64) error {
+// CancelTransaction - Buyer cancels one order of an unpaid checkout. The
+// amount due for the checkout drops accordingly, and the checkout itself is
+// cancelled with its last order. While a payment intent for the old amount
+// is pending only the last order can be cancelled. The updated checkout is
+// returned.
+func (u *TransactionUsecase) CancelTransaction(userID, transactionID uint64) (*domain.Checkout, error) {
 	transaction, err := u.transactionRepo.GetByID(transactionID)
 	if err != nil {
-		return errors.New("transaction not found")
+		return nil, errors.New("transaction not found")
 	}
 
 	// Check ownership
 	if transaction.UserID != userID {
-		return errors.New("forbidden")
+		return nil, errors.New("forbidden")
 	}
 
-	// Validate order status
-	if transaction.OrderStatus != "shipped" {
-		return errors.New("order not shipped")
+	// Check if paid - should use refund instead
+	if transaction.Status == domain.PaymentStatusPaid {
+		return nil, errors.New("use refund for paid transactions")
 	}
 
-	return u.transactionRepo.UpdateOrderStatus(transactionID, "delivered")
-}
-
-// CancelTransaction - Buyer cancels transaction
-func (u *TransactionUsecase) CancelTransaction(userID, transactionID uint64) error {
-	transaction, err := u.transactionRepo.GetByID(transactionID)
+	checkout, err := u.checkoutRepo.GetByID(transaction.CheckoutID)
 	if err != nil {
-		return errors.New("transaction not found")
+		return nil, errors.New("checkout not found")
 	}
 
-	// Check ownership
-	if transaction.UserID != userID {
-		return errors.New("forbidden")
+	remaining := 0
+	for _, order := range activeOrders(checkout) {
+		if order.ID != transactionID {
+			remaining++
+		}
 	}
 
-	// Check if paid - should use refund instead
-	if transaction.Status == "paid" {
-		return errors.New("use refund for paid transactions")
+	dbTx, err := u.transactionRepo.BeginTx()
+	if err != nil {
+		return nil, err
 	}
 
-	// Can only cancel created orders (not processed, shipped, or delivered)
-	if transaction.OrderStatus != "created" {
-		return errors.New("cannot cancel processed order")
+	// A pending intent charges the old amount, so the amount due may not
+	// drop under it. Cancelling the last order expires the intent with the
+	// checkout; a payment arriving later is refunded.
+	if remaining > 0 {
+		pending, err := u.checkoutRepo.HasPendingIntentWithTx(dbTx, checkout.ID)
+		if err != nil {
+			u.transactionRepo.RollbackTx(dbTx)
+			return nil, err
+		}
+		if pending {
+			u.transactionRepo.RollbackTx(dbTx)
+			return nil, errors.New("PAYMENT_INTENT_PENDING")
+		}
+	} else {
+		err = u.checkoutRepo.ExpirePendingIntentsWithTx(dbTx, checkout.ID)
+		if err != nil {
+			u.transactionRepo.RollbackTx(dbTx)
+			return nil, err
+		}
 	}
 
-	// Update both status and order status to cancelled
-	err = u.transactionRepo.UpdateStatus(transactionID, "cancelled")
+	// Cancel both the payment and the fulfilment of the order
+	buyer := domain.StatusActor{Role: domain.StatusActorBuyer, UserID: userID}
+	err = u.orderStatus.TransitionWithTx(dbTx, transactionID, StatusChange{
+		Field:  domain.StatusFieldPayment,
+		From:   transaction.Status,
+		To:     domain.PaymentStatusCancelled,
+		Actor:  buyer,
+		Reason: "cancelled by buyer",
+	})
 	if err != nil {
-		return err
+		u.transactionRepo.RollbackTx(dbTx)
+		return nil, err
 	}
 
-	err = u.transactionRepo.UpdateOrderStatus(transactionID, "cancelled")
+	err = u.orderStatus.TransitionWithTx(dbTx, transactionID, StatusChange{
+		Field:  domain.StatusFieldOrder,
+		From:   transaction.OrderStatus,
+		To:     domain.OrderStatusCancelled,
+		Actor:  buyer,
+		Reason: "cancelled by buyer",
+	})
 	if err != nil {
-		return err
+		u.transactionRepo.RollbackTx(dbTx)
+		return nil, err
 	}
 
-	return nil
-}
-
-// RefundTransaction - Admin/System refunds transaction
-func (u *TransactionUsecase) RefundTransaction(transactionID uint64) error {
-	transaction, err := u.transactionRepo.GetByID(transactionID)
+	err = u.stockReservation.ReleaseTransactionWithTx(dbTx, transactionID)
 	if err != nil {
-		return errors.New("transaction not found")
-	}
-
-	// Can only refund paid transactions
-	if transaction.Status != "paid" {
-		return errors.New("NOT_REFUNDABLE")
+		u.transactionRepo.RollbackTx(dbTx)
+		return nil, err
 	}
 
-	// Begin database transaction
-	dbTx, err := u.transactionRepo.BeginTx()
+	err = u.checkoutRepo.SubtractTotalWithTx(dbTx, checkout.ID, transaction.HargaTotal)
 	if err != nil {
-		return err
+		u.transactionRepo.RollbackTx(dbTx)
+		return nil, err
 	}
+	checkout.HargaTotal -= transaction.HargaTotal
 
-	defer func() {
-		if r := recover(); r != nil {
+	if remaining == 0 {
+		err = u.checkoutRepo.UpdateStatusWithTx(dbTx, checkout.ID, "cancelled")
+		if err != nil {
 			u.transactionRepo.RollbackTx(dbTx)
-			panic(r)
+			return nil, err
 		}
-	}()
+		checkout.Status = "cancelled"
+	}
 
-	// Update payment status to refunded
-	err = u.transactionRepo.UpdateStatus(transactionID, "refunded")
-	if err != nil {
-		u.transactionRepo.RollbackTx(dbTx)
-		return err
+	if err := u.transactionRepo.CommitTx(dbTx); err != nil {
+		return nil, err
 	}
 
-	// Update order status to cancelled
-	err = u.transactionRepo.UpdateOrderStatus(transactionID, "cancelled")
+	return checkout, nil
+}
+
+// GetTransactionTimeline returns the status history of an order to its
+// buyer, the seller of its store or an admin
+func (u *TransactionUsecase) GetTransactionTimeline(userID, transactionID uint64, isAdmin bool) (*domain.TransactionTimeline, error) {
+	transaction, err := u.transactionRepo.GetByID(transactionID)
 	if err != nil {
-		u.transactionRepo.RollbackTx(dbTx)
-		return err
+		return nil, errors.New("transaction not found")
 	}
 
-	// Restore stock and reduce sold count for each item
-	for _, item := range transaction.TransactionItems {
-		// Get product ID from product log
-		productLogs, err := u.productLogRepo.GetByProductID(item.ProductLogID)
-		if err != nil || len(productLogs) == 0 {
-			u.transactionRepo.RollbackTx(dbTx)
-			return errors.New("product log not found")
-		}
+	if !isAdmin && transaction.UserID != userID && !u.ownsStore(userID, transaction.StoreID) {
+		return nil, errors.New("forbidden")
+	}
 
-		productID := productLogs[0].ProductID
+	return u.orderStatus.Timeline(transaction)
+}
 
-		// Restore stock (add back)
-		err = u.productRepo.UpdateStockWithTx(dbTx, productID, -item.Quantity)
-		if err != nil {
-			u.transactionRepo.RollbackTx(dbTx)
-			return err
-		}
+// ownsStore reports whether the store exists and belongs to the seller
+func (u *TransactionUsecase) ownsStore(sellerID, storeID uint64) bool {
+	store, err := u.storeRepo.GetByID(storeID)
+	return err == nil && store.UserID == sellerID
+}
 
-		// Reduce sold count
-		err = u.productRepo.UpdateSoldCountWithTx(dbTx, productID, -item.Quantity)
-		if err != nil {
-			u.transactionRepo.RollbackTx(dbTx)
-			return err
+// activeOrders returns the orders of a checkout that were not cancelled
+func activeOrders(checkout *domain.Checkout) []*domain.Transaction {
+	var orders []*domain.Transaction
+	for _, order := range checkout.Transactions {
+		if order.OrderStatus != "cancelled" {
+			orders = append(orders, order)
 		}
 	}
-
-	return u.transactionRepo.CommitTx(dbTx)
+	return orders
 }
\ No newline at end of file
@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"testing"
+	"time"
 
 	"go-commerce/internal/domain"
 	"go-commerce/internal/usecase/mocks"
@@ -13,6 +14,7 @@ import (
 func TestTransactionUsecase_GetTransactionByID_Success(t *testing.T) {
 	// Setup
 	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockCheckoutRepo := new(mocks.MockCheckoutRepository)
 	mockTransactionItemRepo := new(mocks.MockTransactionItemRepository)
 	mockProductLogRepo := new(mocks.MockProductLogRepository)
 	mockProductRepo := new(mocks.ProductRepositoryMock)
@@ -22,12 +24,18 @@ func TestTransactionUsecase_GetTransactionByID_Success(t *testing.T) {
 
 	transactionUsecase := NewTransactionUsecase(
 		mockTransactionRepo,
+		mockCheckoutRepo,
 		mockTransactionItemRepo,
 		mockProductLogRepo,
 		mockProductRepo,
 		mockAddressRepo,
 		mockUserRepo,
 		mockStoreRepo,
+		nil,
+		nil,
+		nil,
+		newTestInvoiceNumberer(),
+		nil,
 	)
 
 	userID := uint64(1)
@@ -57,6 +65,7 @@ func TestTransactionUsecase_GetTransactionByID_Success(t *testing.T) {
 func TestTransactionUsecase_GetTransactionByID_AccessDenied(t *testing.T) {
 	// Setup
 	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockCheckoutRepo := new(mocks.MockCheckoutRepository)
 	mockTransactionItemRepo := new(mocks.MockTransactionItemRepository)
 	mockProductLogRepo := new(mocks.MockProductLogRepository)
 	mockProductRepo := new(mocks.ProductRepositoryMock)
@@ -66,12 +75,18 @@ func TestTransactionUsecase_GetTransactionByID_AccessDenied(t *testing.T) {
 
 	transactionUsecase := NewTransactionUsecase(
 		mockTransactionRepo,
+		mockCheckoutRepo,
 		mockTransactionItemRepo,
 		mockProductLogRepo,
 		mockProductRepo,
 		mockAddressRepo,
 		mockUserRepo,
 		mockStoreRepo,
+		nil,
+		nil,
+		nil,
+		newTestInvoiceNumberer(),
+		nil,
 	)
 
 	userID := uint64(1)
@@ -100,6 +115,7 @@ func TestTransactionUsecase_GetTransactionByID_AccessDenied(t *testing.T) {
 func TestTransactionUsecase_GetMyTransactions_Success(t *testing.T) {
 	// Setup
 	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockCheckoutRepo := new(mocks.MockCheckoutRepository)
 	mockTransactionItemRepo := new(mocks.MockTransactionItemRepository)
 	mockProductLogRepo := new(mocks.MockProductLogRepository)
 	mockProductRepo := new(mocks.ProductRepositoryMock)
@@ -109,12 +125,18 @@ func TestTransactionUsecase_GetMyTransactions_Success(t *testing.T) {
 
 	transactionUsecase := NewTransactionUsecase(
 		mockTransactionRepo,
+		mockCheckoutRepo,
 		mockTransactionItemRepo,
 		mockProductLogRepo,
 		mockProductRepo,
 		mockAddressRepo,
 		mockUserRepo,
 		mockStoreRepo,
+		nil,
+		nil,
+		nil,
+		newTestInvoiceNumberer(),
+		nil,
 	)
 
 	userID := uint64(1)
@@ -146,21 +168,29 @@ func TestTransactionUsecase_GetMyTransactions_Success(t *testing.T) {
 func TestTransactionUsecase_CreateTransaction_Success(t *testing.T) {
 	// Setup
 	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockCheckoutRepo := new(mocks.MockCheckoutRepository)
 	mockTransactionItemRepo := new(mocks.MockTransactionItemRepository)
 	mockProductLogRepo := new(mocks.MockProductLogRepository)
 	mockProductRepo := new(mocks.ProductRepositoryMock)
 	mockAddressRepo := new(mocks.MockAddressRepository)
 	mockUserRepo := new(mocks.MockUserRepository)
 	mockStoreRepo := new(mocks.StoreRepositoryMock)
+	mockReservationRepo := new(mocks.MockStockReservationRepository)
 
 	transactionUsecase := NewTransactionUsecase(
 		mockTransactionRepo,
+		mockCheckoutRepo,
 		mockTransactionItemRepo,
 		mockProductLogRepo,
 		mockProductRepo,
 		mockAddressRepo,
 		mockUserRepo,
 		mockStoreRepo,
+		NewStockReservationUsecase(mockReservationRepo, mockProductRepo, mockTransactionRepo, StockReservationConfig{HoldTTL: 30 * time.Minute}),
+		nil,
+		nil,
+		newTestInvoiceNumberer(),
+		nil,
 	)
 
 	userID := uint64(1)
@@ -203,8 +233,12 @@ func TestTransactionUsecase_CreateTransaction_Success(t *testing.T) {
 	mockProductRepo.On("GetByID", productID).Return(product, nil)
 	mockStoreRepo.On("GetByID", uint64(1)).Return(store, nil)
 	mockProductLogRepo.On("Create", mock.AnythingOfType("*domain.ProductLog")).Return(nil)
+	mockCheckoutRepo.On("CreateWithTx", mockTx, mock.AnythingOfType("*domain.Checkout")).Return(nil)
 	mockTransactionRepo.On("CreateWithTx", mockTx, mock.AnythingOfType("*domain.Transaction")).Return(nil)
 	mockTransactionItemRepo.On("CreateWithTx", mockTx, mock.AnythingOfType("*domain.TransactionItem")).Return(nil)
+	mockProductRepo.On("GetStockWithLock", mockTx, productID, uint64(0)).Return(10, nil)
+	mockProductRepo.On("UpdateHeldStockWithTx", mockTx, productID, uint64(0), 2).Return(nil)
+	mockReservationRepo.On("CreateWithTx", mockTx, mock.AnythingOfType("*domain.StockReservation")).Return(nil)
 	mockTransactionRepo.On("CommitTx", mockTx).Return(nil)
 
 	// Execute
@@ -217,14 +251,493 @@ func TestTransactionUsecase_CreateTransaction_Success(t *testing.T) {
 	assert.Equal(t, addressID, result.AlamatPengiriman)
 	assert.Equal(t, "transfer", result.MetodeBayar)
 	assert.Equal(t, "pending", result.Status)
-	assert.Equal(t, "created", result.OrderStatus)
 	assert.Equal(t, 20000.0, result.HargaTotal) // 2 * 10000
+	assert.Len(t, result.Transactions, 1)
+	assert.Equal(t, "created", result.Transactions[0].OrderStatus)
+	assert.Equal(t, "INV/"+time.Now().Format("20060102")+"/1/1", result.Transactions[0].KodeInvoice)
 
 	// Verify all mocks called
 	mockAddressRepo.AssertExpectations(t)
 	mockUserRepo.AssertExpectations(t)
 	mockTransactionRepo.AssertExpectations(t)
+	mockCheckoutRepo.AssertExpectations(t)
 	mockProductRepo.AssertExpectations(t)
 	mockProductLogRepo.AssertExpectations(t)
 	mockTransactionItemRepo.AssertExpectations(t)
-}
\ No newline at end of file
+	mockReservationRepo.AssertExpectations(t)
+}
+func TestTransactionUsecase_CreateTransaction_SplitsOrdersByStore(t *testing.T) {
+	// Setup
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockCheckoutRepo := new(mocks.MockCheckoutRepository)
+	mockTransactionItemRepo := new(mocks.MockTransactionItemRepository)
+	mockProductLogRepo := new(mocks.MockProductLogRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockAddressRepo := new(mocks.MockAddressRepository)
+	mockUserRepo := new(mocks.MockUserRepository)
+	mockStoreRepo := new(mocks.StoreRepositoryMock)
+	mockReservationRepo := new(mocks.MockStockReservationRepository)
+
+	transactionUsecase := NewTransactionUsecase(
+		mockTransactionRepo,
+		mockCheckoutRepo,
+		mockTransactionItemRepo,
+		mockProductLogRepo,
+		mockProductRepo,
+		mockAddressRepo,
+		mockUserRepo,
+		mockStoreRepo,
+		NewStockReservationUsecase(mockReservationRepo, mockProductRepo, mockTransactionRepo, StockReservationConfig{HoldTTL: 30 * time.Minute}),
+		nil,
+		nil,
+		newTestInvoiceNumberer(),
+		nil,
+	)
+
+	req := &domain.CreateTransactionRequest{
+		AlamatPengiriman: 1,
+		MetodeBayar:      "transfer",
+		Items: []domain.CreateTransactionItemRequest{
+			{ProductID: 1, Quantity: 1},
+			{ProductID: 2, Quantity: 2},
+			{ProductID: 3, Quantity: 1},
+		},
+	}
+
+	mockTx := "mock_transaction"
+
+	// Mock expectations - products 1 and 3 belong to store 10, product 2 to store 20
+	mockAddressRepo.On("CheckOwnership", uint64(1), uint64(1)).Return(true)
+	mockUserRepo.On("GetByID", uint64(1)).Return(&domain.User{ID: 1}, nil)
+	mockTransactionRepo.On("BeginTx").Return(mockTx, nil)
+	mockProductRepo.On("GetByID", uint64(1)).Return(&domain.Product{ID: 1, HargaKonsumen: 1000, Stok: 5, IDToko: 10, Status: "active"}, nil)
+	mockProductRepo.On("GetByID", uint64(2)).Return(&domain.Product{ID: 2, HargaKonsumen: 2000, Stok: 5, IDToko: 20, Status: "active"}, nil)
+	mockProductRepo.On("GetByID", uint64(3)).Return(&domain.Product{ID: 3, HargaKonsumen: 3000, Stok: 5, IDToko: 10, Status: "active"}, nil)
+	mockStoreRepo.On("GetByID", uint64(10)).Return(&domain.Store{ID: 10, UserID: 2, Status: "active"}, nil)
+	mockStoreRepo.On("GetByID", uint64(20)).Return(&domain.Store{ID: 20, UserID: 3, Status: "active"}, nil)
+	mockProductLogRepo.On("Create", mock.AnythingOfType("*domain.ProductLog")).Return(nil)
+	mockCheckoutRepo.On("CreateWithTx", mockTx, mock.AnythingOfType("*domain.Checkout")).Return(nil)
+	mockTransactionRepo.On("CreateWithTx", mockTx, mock.AnythingOfType("*domain.Transaction")).Return(nil).Twice()
+	mockTransactionItemRepo.On("CreateWithTx", mockTx, mock.AnythingOfType("*domain.TransactionItem")).Return(nil).Times(3)
+	mockProductRepo.On("GetStockWithLock", mockTx, uint64(1), uint64(0)).Return(5, nil)
+	mockProductRepo.On("GetStockWithLock", mockTx, uint64(2), uint64(0)).Return(5, nil)
+	mockProductRepo.On("GetStockWithLock", mockTx, uint64(3), uint64(0)).Return(5, nil)
+	mockProductRepo.On("UpdateHeldStockWithTx", mockTx, uint64(1), uint64(0), 1).Return(nil)
+	mockProductRepo.On("UpdateHeldStockWithTx", mockTx, uint64(2), uint64(0), 2).Return(nil)
+	mockProductRepo.On("UpdateHeldStockWithTx", mockTx, uint64(3), uint64(0), 1).Return(nil)
+	mockReservationRepo.On("CreateWithTx", mockTx, mock.AnythingOfType("*domain.StockReservation")).Return(nil).Times(3)
+	mockTransactionRepo.On("CommitTx", mockTx).Return(nil)
+
+	// Execute
+	result, err := transactionUsecase.CreateTransaction(1, req)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 8000.0, result.HargaTotal)
+	assert.Len(t, result.Transactions, 2)
+	assert.Equal(t, uint64(10), result.Transactions[0].StoreID)
+	assert.Equal(t, 4000.0, result.Transactions[0].HargaTotal)
+	assert.Len(t, result.Transactions[0].TransactionItems, 2)
+	assert.Equal(t, uint64(20), result.Transactions[1].StoreID)
+	assert.Equal(t, 4000.0, result.Transactions[1].HargaTotal)
+	assert.Len(t, result.Transactions[1].TransactionItems, 1)
+	assert.NotEqual(t, result.Transactions[0].KodeInvoice, result.Transactions[1].KodeInvoice)
+
+	mockTransactionRepo.AssertExpectations(t)
+	mockTransactionItemRepo.AssertExpectations(t)
+}
+
+func TestTransactionUsecase_ProcessOrder_OnlyStoreOwner(t *testing.T) {
+	// Setup
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockCheckoutRepo := new(mocks.MockCheckoutRepository)
+	mockStoreRepo := new(mocks.StoreRepositoryMock)
+	mockHistoryRepo := new(mocks.MockTransactionStatusHistoryRepository)
+
+	transactionUsecase := NewTransactionUsecase(
+		mockTransactionRepo,
+		mockCheckoutRepo,
+		new(mocks.MockTransactionItemRepository),
+		new(mocks.MockProductLogRepository),
+		new(mocks.ProductRepositoryMock),
+		new(mocks.MockAddressRepository),
+		new(mocks.MockUserRepository),
+		mockStoreRepo,
+		nil,
+		NewOrderStatusUsecase(mockTransactionRepo, mockHistoryRepo),
+		nil,
+		newTestInvoiceNumberer(),
+		nil,
+	)
+
+	transaction := &domain.Transaction{ID: 7, StoreID: 10, Status: "paid", OrderStatus: "created"}
+	mockTx := "mock_transaction"
+
+	// Mock expectations
+	mockTransactionRepo.On("GetByID", uint64(7)).Return(transaction, nil)
+	mockStoreRepo.On("GetByID", uint64(10)).Return(&domain.Store{ID: 10, UserID: 2}, nil)
+	mockTransactionRepo.On("BeginTx").Return(mockTx, nil).Once()
+	mockTransactionRepo.On("TransitionOrderStatusWithTx", mockTx, uint64(7), "created", "processed", mock.AnythingOfType("time.Time")).Return(true, nil).Once()
+	mockHistoryRepo.On("CreateWithTx", mockTx, mock.MatchedBy(func(entry *domain.TransactionStatusHistory) bool {
+		return entry.ActorRole == domain.StatusActorSeller && *entry.ActorID == 2
+	})).Return(nil).Once()
+	mockTransactionRepo.On("CommitTx", mockTx).Return(nil).Once()
+
+	// Execute
+	errOtherSeller := transactionUsecase.ProcessOrder(3, 7)
+	errOwner := transactionUsecase.ProcessOrder(2, 7)
+
+	// Assert
+	assert.Error(t, errOtherSeller)
+	assert.Contains(t, errOtherSeller.Error(), "forbidden")
+	assert.NoError(t, errOwner)
+	mockTransactionRepo.AssertExpectations(t)
+}
+
+func TestTransactionUsecase_MarkCheckoutPaidWithTx_SettlesEveryOrder(t *testing.T) {
+	// Setup
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockCheckoutRepo := new(mocks.MockCheckoutRepository)
+	mockProductLogRepo := new(mocks.MockProductLogRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockReservationRepo := new(mocks.MockStockReservationRepository)
+	mockHistoryRepo := new(mocks.MockTransactionStatusHistoryRepository)
+
+	transactionUsecase := NewTransactionUsecase(
+		mockTransactionRepo,
+		mockCheckoutRepo,
+		new(mocks.MockTransactionItemRepository),
+		mockProductLogRepo,
+		mockProductRepo,
+		new(mocks.MockAddressRepository),
+		new(mocks.MockUserRepository),
+		new(mocks.StoreRepositoryMock),
+		NewStockReservationUsecase(mockReservationRepo, mockProductRepo, mockTransactionRepo, StockReservationConfig{HoldTTL: 30 * time.Minute}),
+		NewOrderStatusUsecase(mockTransactionRepo, mockHistoryRepo),
+		nil,
+		newTestInvoiceNumberer(),
+		nil,
+	)
+
+	checkout := &domain.Checkout{
+		ID:     3,
+		Status: "pending",
+		Transactions: []*domain.Transaction{
+			{ID: 7, StoreID: 10, Status: "pending", OrderStatus: "created", TransactionItems: []*domain.TransactionItem{{ProductLogID: 100, Quantity: 1}}},
+			{ID: 8, StoreID: 20, Status: "pending", OrderStatus: "created", TransactionItems: []*domain.TransactionItem{{ProductLogID: 200, Quantity: 2}}},
+			{ID: 9, StoreID: 30, Status: "cancelled", OrderStatus: "cancelled", TransactionItems: []*domain.TransactionItem{{ProductLogID: 300, Quantity: 1}}},
+		},
+	}
+	mockTx := "mock_transaction"
+
+	// Mock expectations
+	mockCheckoutRepo.On("GetByID", uint64(3)).Return(checkout, nil)
+	paidAt := time.Date(2026, 10, 17, 9, 30, 0, 0, time.UTC)
+	mockCheckoutRepo.On("MarkPaidWithTx", mockTx, uint64(3), paidAt).Return(true, nil)
+	mockProductLogRepo.On("GetByID", uint64(100)).Return(&domain.ProductLog{ID: 100, ProductID: 1}, nil)
+	mockProductLogRepo.On("GetByID", uint64(200)).Return(&domain.ProductLog{ID: 200, ProductID: 2}, nil)
+	mockProductRepo.On("GetStockWithLock", mockTx, uint64(1), uint64(0)).Return(5, nil)
+	mockProductRepo.On("GetStockWithLock", mockTx, uint64(2), uint64(0)).Return(5, nil)
+	mockReservationRepo.On("GetHeldByCheckoutIDWithTx", mockTx, uint64(3)).Return([]*domain.StockReservation{
+		{ID: 41, CheckoutID: 3, TransactionID: 7, ProductID: 1, Quantity: 1, Status: domain.StockReservationHeld},
+		{ID: 42, CheckoutID: 3, TransactionID: 8, ProductID: 2, Quantity: 2, Status: domain.StockReservationHeld},
+	}, nil)
+	mockReservationRepo.On("TransitionWithTx", mockTx, uint64(41), domain.StockReservationHeld, domain.StockReservationConverted).Return(true, nil)
+	mockReservationRepo.On("TransitionWithTx", mockTx, uint64(42), domain.StockReservationHeld, domain.StockReservationConverted).Return(true, nil)
+	mockProductRepo.On("UpdateHeldStockWithTx", mockTx, uint64(1), uint64(0), -1).Return(nil)
+	mockProductRepo.On("UpdateHeldStockWithTx", mockTx, uint64(2), uint64(0), -2).Return(nil)
+	mockProductRepo.On("UpdateStockWithTx", mockTx, uint64(1), uint64(0), 1).Return(nil)
+	mockProductRepo.On("UpdateStockWithTx", mockTx, uint64(2), uint64(0), 2).Return(nil)
+	mockProductRepo.On("UpdateSoldCountWithTx", mockTx, uint64(1), 1).Return(nil)
+	mockProductRepo.On("UpdateSoldCountWithTx", mockTx, uint64(2), 2).Return(nil)
+	mockTransactionRepo.On("TransitionStatusWithTx", mockTx, uint64(7), "pending", "paid", paidAt).Return(true, nil)
+	mockTransactionRepo.On("TransitionStatusWithTx", mockTx, uint64(8), "pending", "paid", paidAt).Return(true, nil)
+	mockHistoryRepo.On("CreateWithTx", mockTx, mock.AnythingOfType("*domain.TransactionStatusHistory")).Return(nil).Twice()
+
+	// Execute
+	err := transactionUsecase.MarkCheckoutPaidWithTx(mockTx, 3, paidAt)
+
+	// Assert
+	assert.NoError(t, err)
+	mockTransactionRepo.AssertExpectations(t)
+	mockCheckoutRepo.AssertExpectations(t)
+	mockProductRepo.AssertExpectations(t)
+	mockReservationRepo.AssertExpectations(t)
+	mockHistoryRepo.AssertExpectations(t)
+	mockTransactionRepo.AssertNotCalled(t, "TransitionStatusWithTx", mockTx, uint64(9), "pending", "paid", paidAt)
+}
+
+func TestTransactionUsecase_CancelTransaction_LastOrderCancelsCheckout(t *testing.T) {
+	// Setup
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockCheckoutRepo := new(mocks.MockCheckoutRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockReservationRepo := new(mocks.MockStockReservationRepository)
+	mockHistoryRepo := new(mocks.MockTransactionStatusHistoryRepository)
+
+	transactionUsecase := NewTransactionUsecase(
+		mockTransactionRepo,
+		mockCheckoutRepo,
+		new(mocks.MockTransactionItemRepository),
+		new(mocks.MockProductLogRepository),
+		mockProductRepo,
+		new(mocks.MockAddressRepository),
+		new(mocks.MockUserRepository),
+		new(mocks.StoreRepositoryMock),
+		NewStockReservationUsecase(mockReservationRepo, mockProductRepo, mockTransactionRepo, StockReservationConfig{HoldTTL: 30 * time.Minute}),
+		NewOrderStatusUsecase(mockTransactionRepo, mockHistoryRepo),
+		nil,
+		newTestInvoiceNumberer(),
+		nil,
+	)
+
+	transaction := &domain.Transaction{ID: 8, UserID: 1, CheckoutID: 3, HargaTotal: 4000, Status: "pending", OrderStatus: "created"}
+	checkout := &domain.Checkout{
+		ID:         3,
+		UserID:     1,
+		HargaTotal: 4000,
+		Status:     "pending",
+		Transactions: []*domain.Transaction{
+			{ID: 7, Status: "cancelled", OrderStatus: "cancelled"},
+			{ID: 8, Status: "pending", OrderStatus: "created"},
+		},
+	}
+	mockTx := "mock_transaction"
+
+	// Mock expectations
+	mockTransactionRepo.On("GetByID", uint64(8)).Return(transaction, nil)
+	mockCheckoutRepo.On("GetByID", uint64(3)).Return(checkout, nil)
+	mockTransactionRepo.On("BeginTx").Return(mockTx, nil)
+	mockCheckoutRepo.On("ExpirePendingIntentsWithTx", mockTx, uint64(3)).Return(nil)
+	mockTransactionRepo.On("TransitionStatusWithTx", mockTx, uint64(8), "pending", "cancelled", mock.AnythingOfType("time.Time")).Return(true, nil)
+	mockTransactionRepo.On("TransitionOrderStatusWithTx", mockTx, uint64(8), "created", "cancelled", mock.AnythingOfType("time.Time")).Return(true, nil)
+	mockHistoryRepo.On("CreateWithTx", mockTx, mock.AnythingOfType("*domain.TransactionStatusHistory")).Return(nil).Twice()
+	mockReservationRepo.On("GetHeldByTransactionIDWithTx", mockTx, uint64(8)).Return([]*domain.StockReservation{
+		{ID: 42, CheckoutID: 3, TransactionID: 8, ProductID: 2, Quantity: 2, Status: domain.StockReservationHeld},
+	}, nil)
+	mockProductRepo.On("GetStockWithLock", mockTx, uint64(2), uint64(0)).Return(3, nil)
+	mockReservationRepo.On("TransitionWithTx", mockTx, uint64(42), domain.StockReservationHeld, domain.StockReservationReleased).Return(true, nil)
+	mockProductRepo.On("UpdateHeldStockWithTx", mockTx, uint64(2), uint64(0), -2).Return(nil)
+	mockCheckoutRepo.On("SubtractTotalWithTx", mockTx, uint64(3), 4000.0).Return(nil)
+	mockCheckoutRepo.On("UpdateStatusWithTx", mockTx, uint64(3), "cancelled").Return(nil)
+	mockTransactionRepo.On("CommitTx", mockTx).Return(nil)
+
+	// Execute
+	result, err := transactionUsecase.CancelTransaction(1, 8)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "cancelled", result.Status)
+	assert.Equal(t, 0.0, result.HargaTotal)
+	mockCheckoutRepo.AssertExpectations(t)
+	mockReservationRepo.AssertExpectations(t)
+}
+
+func TestTransactionUsecase_CancelTransaction_RefusedWhileIntentPending(t *testing.T) {
+	// Setup
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockCheckoutRepo := new(mocks.MockCheckoutRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockReservationRepo := new(mocks.MockStockReservationRepository)
+	mockHistoryRepo := new(mocks.MockTransactionStatusHistoryRepository)
+
+	transactionUsecase := NewTransactionUsecase(
+		mockTransactionRepo,
+		mockCheckoutRepo,
+		new(mocks.MockTransactionItemRepository),
+		new(mocks.MockProductLogRepository),
+		mockProductRepo,
+		new(mocks.MockAddressRepository),
+		new(mocks.MockUserRepository),
+		new(mocks.StoreRepositoryMock),
+		NewStockReservationUsecase(mockReservationRepo, mockProductRepo, mockTransactionRepo, StockReservationConfig{HoldTTL: 30 * time.Minute}),
+		NewOrderStatusUsecase(mockTransactionRepo, mockHistoryRepo),
+		nil,
+		newTestInvoiceNumberer(),
+		nil,
+	)
+
+	transaction := &domain.Transaction{ID: 8, UserID: 1, CheckoutID: 3, HargaTotal: 4000, Status: "pending", OrderStatus: "created"}
+	checkout := &domain.Checkout{
+		ID:         3,
+		UserID:     1,
+		HargaTotal: 9000,
+		Status:     "pending",
+		Transactions: []*domain.Transaction{
+			{ID: 7, Status: "pending", OrderStatus: "created"},
+			{ID: 8, Status: "pending", OrderStatus: "created"},
+		},
+	}
+	mockTx := "mock_transaction"
+
+	// Mock expectations - an intent for the full 9000 is waiting for payment
+	mockTransactionRepo.On("GetByID", uint64(8)).Return(transaction, nil)
+	mockCheckoutRepo.On("GetByID", uint64(3)).Return(checkout, nil)
+	mockTransactionRepo.On("BeginTx").Return(mockTx, nil)
+	mockCheckoutRepo.On("HasPendingIntentWithTx", mockTx, uint64(3)).Return(true, nil)
+	mockTransactionRepo.On("RollbackTx", mockTx).Return(nil)
+
+	// Execute
+	result, err := transactionUsecase.CancelTransaction(1, 8)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, "PAYMENT_INTENT_PENDING", err.Error())
+	mockTransactionRepo.AssertExpectations(t)
+	mockCheckoutRepo.AssertExpectations(t)
+	mockCheckoutRepo.AssertNotCalled(t, "SubtractTotalWithTx", mockTx, uint64(3), 4000.0)
+	mockTransactionRepo.AssertNotCalled(t, "TransitionStatusWithTx", mockTx, uint64(8), "pending", "cancelled", mock.Anything)
+	mockTransactionRepo.AssertNotCalled(t, "CommitTx", mockTx)
+}
+
+func TestTransactionUsecase_CreateTransaction_HoldsVariantStock(t *testing.T) {
+	// Setup
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockCheckoutRepo := new(mocks.MockCheckoutRepository)
+	mockTransactionItemRepo := new(mocks.MockTransactionItemRepository)
+	mockProductLogRepo := new(mocks.MockProductLogRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockAddressRepo := new(mocks.MockAddressRepository)
+	mockUserRepo := new(mocks.MockUserRepository)
+	mockStoreRepo := new(mocks.StoreRepositoryMock)
+	mockReservationRepo := new(mocks.MockStockReservationRepository)
+
+	transactionUsecase := NewTransactionUsecase(
+		mockTransactionRepo,
+		mockCheckoutRepo,
+		mockTransactionItemRepo,
+		mockProductLogRepo,
+		mockProductRepo,
+		mockAddressRepo,
+		mockUserRepo,
+		mockStoreRepo,
+		NewStockReservationUsecase(mockReservationRepo, mockProductRepo, mockTransactionRepo, StockReservationConfig{HoldTTL: 30 * time.Minute}),
+		nil,
+		nil,
+		newTestInvoiceNumberer(),
+		nil,
+	)
+
+	req := &domain.CreateTransactionRequest{
+		AlamatPengiriman: 1,
+		MetodeBayar:      "transfer",
+		Items: []domain.CreateTransactionItemRequest{
+			{ProductID: 1, VariantID: 12, Quantity: 2},
+		},
+	}
+
+	largePrice := 125000.0
+	product := &domain.Product{
+		ID:            1,
+		NamaProduk:    "Kaos Polos",
+		Slug:          "kaos-polos",
+		HargaKonsumen: 100000,
+		IDToko:        1,
+		IDCategory:    1,
+		Status:        "active",
+		Options: []domain.ProductOption{
+			{Name: "Ukuran", Values: []string{"M", "L"}, Position: 1},
+			{Name: "Warna", Values: []string{"Hitam"}, Position: 2},
+		},
+		Variants: []domain.ProductVariant{
+			{ID: 11, IDProduk: 1, SKU: "KAOS-M-HTM", Attributes: map[string]string{"Ukuran": "M", "Warna": "Hitam"}, Stok: 5, Status: "active"},
+			{ID: 12, IDProduk: 1, SKU: "KAOS-L-HTM", Attributes: map[string]string{"Ukuran": "L", "Warna": "Hitam"}, HargaKonsumen: &largePrice, Stok: 3, Status: "active"},
+		},
+	}
+
+	mockTx := "mock_transaction"
+	var productLog *domain.ProductLog
+	var item *domain.TransactionItem
+	var reservation *domain.StockReservation
+
+	// Mock expectations
+	mockAddressRepo.On("CheckOwnership", uint64(1), uint64(1)).Return(true)
+	mockUserRepo.On("GetByID", uint64(1)).Return(&domain.User{ID: 1}, nil)
+	mockTransactionRepo.On("BeginTx").Return(mockTx, nil)
+	mockProductRepo.On("GetByID", uint64(1)).Return(product, nil)
+	mockStoreRepo.On("GetByID", uint64(1)).Return(&domain.Store{ID: 1, Status: "active"}, nil)
+	mockProductLogRepo.On("Create", mock.AnythingOfType("*domain.ProductLog")).
+		Run(func(args mock.Arguments) { productLog = args.Get(0).(*domain.ProductLog) }).Return(nil)
+	mockCheckoutRepo.On("CreateWithTx", mockTx, mock.AnythingOfType("*domain.Checkout")).Return(nil)
+	mockTransactionRepo.On("CreateWithTx", mockTx, mock.AnythingOfType("*domain.Transaction")).Return(nil)
+	mockTransactionItemRepo.On("CreateWithTx", mockTx, mock.AnythingOfType("*domain.TransactionItem")).
+		Run(func(args mock.Arguments) { item = args.Get(1).(*domain.TransactionItem) }).Return(nil)
+	mockProductRepo.On("GetStockWithLock", mockTx, uint64(1), uint64(12)).Return(3, nil)
+	mockProductRepo.On("UpdateHeldStockWithTx", mockTx, uint64(1), uint64(12), 2).Return(nil)
+	mockReservationRepo.On("CreateWithTx", mockTx, mock.AnythingOfType("*domain.StockReservation")).
+		Run(func(args mock.Arguments) { reservation = args.Get(1).(*domain.StockReservation) }).Return(nil)
+	mockTransactionRepo.On("CommitTx", mockTx).Return(nil)
+
+	// Execute
+	result, err := transactionUsecase.CreateTransaction(1, req)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 250000.0, result.HargaTotal) // 2 * the variant price
+	assert.Equal(t, uint64(12), productLog.VariantID)
+	assert.Equal(t, "KAOS-L-HTM", productLog.VariantSKU)
+	assert.Equal(t, map[string]string{"Ukuran": "L", "Warna": "Hitam"}, productLog.VariantAttributes)
+	assert.Equal(t, 125000.0, productLog.HargaKonsumen)
+	assert.Equal(t, "Kaos Polos (L, Hitam)", item.NamaProdukSnapshot)
+	assert.Equal(t, uint64(12), reservation.VariantID)
+	mockProductRepo.AssertExpectations(t)
+}
+
+func TestTransactionUsecase_CreateTransaction_VariantRequired(t *testing.T) {
+	// Setup
+	mockTransactionRepo := new(mocks.MockTransactionRepository)
+	mockProductRepo := new(mocks.ProductRepositoryMock)
+	mockAddressRepo := new(mocks.MockAddressRepository)
+	mockUserRepo := new(mocks.MockUserRepository)
+
+	transactionUsecase := NewTransactionUsecase(
+		mockTransactionRepo,
+		nil,
+		nil,
+		nil,
+		mockProductRepo,
+		mockAddressRepo,
+		mockUserRepo,
+		nil,
+		nil,
+		nil,
+		nil,
+		newTestInvoiceNumberer(),
+		nil,
+	)
+
+	req := &domain.CreateTransactionRequest{
+		AlamatPengiriman: 1,
+		MetodeBayar:      "transfer",
+		Items: []domain.CreateTransactionItemRequest{
+			{ProductID: 1, Quantity: 1},
+		},
+	}
+
+	product := &domain.Product{
+		ID:     1,
+		Stok:   10,
+		Status: "active",
+		Variants: []domain.ProductVariant{
+			{ID: 11, IDProduk: 1, Stok: 5, Status: "active"},
+		},
+	}
+
+	mockTx := "mock_transaction"
+
+	// Mock expectations
+	mockAddressRepo.On("CheckOwnership", uint64(1), uint64(1)).Return(true)
+	mockUserRepo.On("GetByID", uint64(1)).Return(&domain.User{ID: 1}, nil)
+	mockTransactionRepo.On("BeginTx").Return(mockTx, nil)
+	mockTransactionRepo.On("RollbackTx", mockTx).Return(nil)
+	mockProductRepo.On("GetByID", uint64(1)).Return(product, nil)
+
+	// Execute
+	result, err := transactionUsecase.CreateTransaction(1, req)
+
+	// Assert
+	assert.Nil(t, result)
+	assert.EqualError(t, err, "VARIANT_REQUIRED")
+	mockTransactionRepo.AssertCalled(t, "RollbackTx", mockTx)
+}
@@ -0,0 +1,311 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-commerce/internal/domain"
+	"go-commerce/pkg/jwt"
+	"go-commerce/pkg/totp"
+
+	"gorm.io/gorm"
+)
+
+// TwoFactorConfig controls TOTP enrollment and the login challenge
+type TwoFactorConfig struct {
+	Issuer            string
+	ChallengeTTL      time.Duration
+	RecoveryCodeCount int
+}
+
+type TwoFactorUsecase struct {
+	userRepo         domain.UserRepository
+	recoveryCodeRepo domain.TwoFactorRecoveryCodeRepository
+	settingRepo      domain.SettingRepository
+	jwtManager       *jwt.JWTManager
+	config           TwoFactorConfig
+}
+
+func NewTwoFactorUsecase(
+	userRepo domain.UserRepository,
+	recoveryCodeRepo domain.TwoFactorRecoveryCodeRepository,
+	settingRepo domain.SettingRepository,
+	jwtManager *jwt.JWTManager,
+	config TwoFactorConfig,
+) *TwoFactorUsecase {
+	return &TwoFactorUsecase{
+		userRepo:         userRepo,
+		recoveryCodeRepo: recoveryCodeRepo,
+		settingRepo:      settingRepo,
+		jwtManager:       jwtManager,
+		config:           config,
+	}
+}
+
+// Enroll generates a new TOTP secret for the user. 2FA is only switched on
+// once Confirm receives a valid code for it.
+func (u *TwoFactorUsecase) Enroll(userID uint64) (*domain.TwoFactorEnrollResponse, error) {
+	user, err := u.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TOTPEnabledAt != nil {
+		return nil, errors.New("TWO_FACTOR_ALREADY_ENABLED")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, errors.New("failed to generate two-factor secret")
+	}
+
+	if err := u.userRepo.SetTOTPSecret(user.ID, secret); err != nil {
+		return nil, errors.New("failed to start two-factor enrollment")
+	}
+
+	return &domain.TwoFactorEnrollResponse{
+		Secret:     secret,
+		OTPAuthURI: totp.URI(u.config.Issuer, user.Email, secret),
+	}, nil
+}
+
+// Confirm enables 2FA when the code matches the pending secret and returns
+// the recovery codes. They are only shown this once.
+func (u *TwoFactorUsecase) Confirm(userID uint64, req *domain.TwoFactorCodeRequest) (*domain.TwoFactorRecoveryCodesResponse, error) {
+	user, err := u.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TOTPEnabledAt != nil {
+		return nil, errors.New("TWO_FACTOR_ALREADY_ENABLED")
+	}
+	if user.TOTPSecret == "" {
+		return nil, errors.New("TWO_FACTOR_NOT_ENROLLED")
+	}
+
+	if err := u.verifyTOTP(user, req.Code); err != nil {
+		return nil, err
+	}
+
+	codes, err := u.replaceRecoveryCodes(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.userRepo.EnableTOTP(user.ID, time.Now()); err != nil {
+		return nil, errors.New("failed to enable two-factor authentication")
+	}
+
+	return &domain.TwoFactorRecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// Disable turns 2FA off after checking a current TOTP or recovery code
+func (u *TwoFactorUsecase) Disable(userID uint64, req *domain.TwoFactorCodeRequest) error {
+	user, err := u.getUser(userID)
+	if err != nil {
+		return err
+	}
+
+	if user.TOTPEnabledAt == nil {
+		return errors.New("TWO_FACTOR_NOT_ENABLED")
+	}
+
+	if err := u.VerifyCode(user, req.Code); err != nil {
+		return err
+	}
+
+	if err := u.userRepo.DisableTOTP(user.ID); err != nil {
+		return errors.New("failed to disable two-factor authentication")
+	}
+	if err := u.recoveryCodeRepo.DeleteByUserID(user.ID); err != nil {
+		log.Printf("Error deleting recovery codes for user %d: %v", user.ID, err)
+	}
+
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces every recovery code of the user after
+// checking a current TOTP code
+func (u *TwoFactorUsecase) RegenerateRecoveryCodes(userID uint64, req *domain.TwoFactorCodeRequest) (*domain.TwoFactorRecoveryCodesResponse, error) {
+	user, err := u.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TOTPEnabledAt == nil {
+		return nil, errors.New("TWO_FACTOR_NOT_ENABLED")
+	}
+
+	if err := u.verifyTOTP(user, req.Code); err != nil {
+		return nil, err
+	}
+
+	codes, err := u.replaceRecoveryCodes(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.TwoFactorRecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// IsEnabled reports whether logins of the user need a second factor
+func (u *TwoFactorUsecase) IsEnabled(user *domain.User) bool {
+	return user.TOTPEnabledAt != nil
+}
+
+// IssueChallenge returns the token that lets the user finish a login with a
+// second factor
+func (u *TwoFactorUsecase) IssueChallenge(user *domain.User) (string, error) {
+	token, err := u.jwtManager.GenerateTwoFactorChallengeToken(user.ID, u.config.ChallengeTTL)
+	if err != nil {
+		return "", errors.New("failed to generate two-factor challenge")
+	}
+	return token, nil
+}
+
+// ResolveChallenge returns the user a challenge token was issued to
+func (u *TwoFactorUsecase) ResolveChallenge(challengeToken string) (*domain.User, error) {
+	claims, err := u.jwtManager.ValidateTwoFactorChallengeToken(challengeToken)
+	if err != nil {
+		return nil, errors.New("invalid or expired two-factor challenge")
+	}
+
+	user, err := u.userRepo.GetByID(claims.UserID)
+	if err != nil || user.TOTPEnabledAt == nil {
+		return nil, errors.New("invalid or expired two-factor challenge")
+	}
+	return user, nil
+}
+
+// VerifyCode accepts either a TOTP code or an unused recovery code. Each code
+// works only once.
+func (u *TwoFactorUsecase) VerifyCode(user *domain.User, code string) error {
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		return u.verifyTOTP(user, code)
+	}
+
+	used, err := u.recoveryCodeRepo.Consume(user.ID, hashRecoveryCode(code), time.Now())
+	if err != nil {
+		return errors.New("failed to verify two-factor code")
+	}
+	if !used {
+		return errors.New("INVALID_TWO_FACTOR_CODE")
+	}
+	return nil
+}
+
+// SetupRequired reports whether the user holds admin privileges while the
+// policy requires 2FA for admins and 2FA is not enabled yet. Such users get
+// tokens without admin permissions until they enroll. When the policy cannot
+// be read it is assumed to require 2FA, so an outage never grants admin
+// permissions the policy would withhold.
+func (u *TwoFactorUsecase) SetupRequired(user *domain.User, permissions []string) bool {
+	if user.TOTPEnabledAt != nil {
+		return false
+	}
+	if !user.IsAdmin && len(permissions) == 0 {
+		return false
+	}
+
+	policy, err := u.GetPolicy()
+	if err != nil {
+		log.Printf("Error reading two-factor policy: %v", err)
+		return true
+	}
+	return policy.RequireForAdmins
+}
+
+func (u *TwoFactorUsecase) GetPolicy() (*domain.TwoFactorPolicy, error) {
+	setting, err := u.settingRepo.Get(domain.SettingRequireTwoFactorForAdmins)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &domain.TwoFactorPolicy{}, nil
+		}
+		return nil, errors.New("failed to get two-factor policy")
+	}
+
+	required, _ := strconv.ParseBool(setting.Value)
+	return &domain.TwoFactorPolicy{RequireForAdmins: required}, nil
+}
+
+// UpdatePolicy switches the admin 2FA requirement. It applies to tokens
+// issued from now on, at login or refresh.
+func (u *TwoFactorUsecase) UpdatePolicy(req *domain.UpdateTwoFactorPolicyRequest) (*domain.TwoFactorPolicy, error) {
+	if err := u.settingRepo.Set(domain.SettingRequireTwoFactorForAdmins, strconv.FormatBool(*req.RequireForAdmins)); err != nil {
+		return nil, errors.New("failed to update two-factor policy")
+	}
+	return &domain.TwoFactorPolicy{RequireForAdmins: *req.RequireForAdmins}, nil
+}
+
+func (u *TwoFactorUsecase) getUser(userID uint64) (*domain.User, error) {
+	user, err := u.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, errors.New("failed to get user")
+	}
+	return user, nil
+}
+
+func (u *TwoFactorUsecase) verifyTOTP(user *domain.User, code string) error {
+	step, ok := totp.Validate(user.TOTPSecret, strings.TrimSpace(code), time.Now())
+	if !ok {
+		return errors.New("INVALID_TWO_FACTOR_CODE")
+	}
+
+	// Refuse a code that was already used, e.g. observed by a shoulder surfer
+	fresh, err := u.userRepo.UseTOTPStep(user.ID, step)
+	if err != nil {
+		return errors.New("failed to verify two-factor code")
+	}
+	if !fresh {
+		return errors.New("INVALID_TWO_FACTOR_CODE")
+	}
+	return nil
+}
+
+func (u *TwoFactorUsecase) replaceRecoveryCodes(userID uint64) ([]string, error) {
+	codes := make([]string, 0, u.config.RecoveryCodeCount)
+	hashes := make([]string, 0, u.config.RecoveryCodeCount)
+	for i := 0; i < u.config.RecoveryCodeCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, errors.New("failed to generate recovery codes")
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+
+	if err := u.recoveryCodeRepo.ReplaceForUser(userID, hashes); err != nil {
+		return nil, errors.New("failed to store recovery codes")
+	}
+	return codes, nil
+}
+
+// generateRecoveryCode returns a code like "k3m9x-q2w7p"
+func generateRecoveryCode() (string, error) {
+	b := make([]byte, 7)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	raw := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b))[:10]
+	return raw[:5] + "-" + raw[5:], nil
+}
+
+// hashRecoveryCode ignores case and separators so users can type the code
+// the way it is easiest for them
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,174 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go-commerce/internal/domain"
+	"go-commerce/internal/usecase/mocks"
+	"go-commerce/pkg/jwt"
+	"go-commerce/pkg/totp"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func newTestTwoFactorUsecase(userRepo *mocks.MockUserRepository, recoveryCodeRepo *mocks.MockTwoFactorRecoveryCodeRepository, settingRepo *mocks.MockSettingRepository, jwtManager *jwt.JWTManager) *TwoFactorUsecase {
+	return NewTwoFactorUsecase(userRepo, recoveryCodeRepo, settingRepo, jwtManager, TwoFactorConfig{
+		Issuer:            "Go Commerce",
+		ChallengeTTL:      5 * time.Minute,
+		RecoveryCodeCount: 10,
+	})
+}
+
+func TestTwoFactorUsecase_Confirm_EnablesAndReturnsRecoveryCodes(t *testing.T) {
+	// Setup
+	mockUserRepo := new(mocks.MockUserRepository)
+	mockRecoveryCodeRepo := new(mocks.MockTwoFactorRecoveryCodeRepository)
+	twoFactorUsecase := newTestTwoFactorUsecase(mockUserRepo, mockRecoveryCodeRepo, nil, nil)
+
+	secret, _ := totp.GenerateSecret()
+	user := &domain.User{ID: 1, Email: "seller@example.com", TOTPSecret: secret}
+	code, _ := totp.Code(secret, totp.Step(time.Now()))
+
+	// Mock expectations
+	mockUserRepo.On("GetByID", user.ID).Return(user, nil)
+	mockUserRepo.On("UseTOTPStep", user.ID, mock.AnythingOfType("int64")).Return(true, nil)
+	mockRecoveryCodeRepo.On("ReplaceForUser", user.ID, mock.MatchedBy(func(hashes []string) bool {
+		return len(hashes) == 10
+	})).Return(nil)
+	mockUserRepo.On("EnableTOTP", user.ID, mock.AnythingOfType("time.Time")).Return(nil)
+
+	// Execute
+	result, err := twoFactorUsecase.Confirm(user.ID, &domain.TwoFactorCodeRequest{Code: code})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, result.RecoveryCodes, 10)
+	assert.Regexp(t, `^[a-z2-7]{5}-[a-z2-7]{5}$`, result.RecoveryCodes[0])
+	mockUserRepo.AssertExpectations(t)
+	mockRecoveryCodeRepo.AssertExpectations(t)
+}
+
+func TestTwoFactorUsecase_Confirm_RejectsReplayedCode(t *testing.T) {
+	// Setup
+	mockUserRepo := new(mocks.MockUserRepository)
+	mockRecoveryCodeRepo := new(mocks.MockTwoFactorRecoveryCodeRepository)
+	twoFactorUsecase := newTestTwoFactorUsecase(mockUserRepo, mockRecoveryCodeRepo, nil, nil)
+
+	secret, _ := totp.GenerateSecret()
+	user := &domain.User{ID: 1, TOTPSecret: secret}
+	code, _ := totp.Code(secret, totp.Step(time.Now()))
+
+	// Mock expectations
+	mockUserRepo.On("GetByID", user.ID).Return(user, nil)
+	mockUserRepo.On("UseTOTPStep", user.ID, mock.AnythingOfType("int64")).Return(false, nil)
+
+	// Execute
+	result, err := twoFactorUsecase.Confirm(user.ID, &domain.TwoFactorCodeRequest{Code: code})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, "INVALID_TWO_FACTOR_CODE", err.Error())
+	mockUserRepo.AssertNotCalled(t, "EnableTOTP", user.ID, mock.Anything)
+}
+
+func TestAuthUsecase_Login_TwoFactorChallengeThenRecoveryCode(t *testing.T) {
+	// Setup
+	mockUserRepo := new(mocks.MockUserRepository)
+	mockRecoveryCodeRepo := new(mocks.MockTwoFactorRecoveryCodeRepository)
+	mockRefreshTokenRepo := new(mocks.MockRefreshTokenRepository)
+	jwtManager := jwt.NewJWTManager("test-secret", 24, 168)
+	twoFactorUsecase := newTestTwoFactorUsecase(mockUserRepo, mockRecoveryCodeRepo, nil, jwtManager)
+	authUsecase := NewAuthUsecase(mockUserRepo, nil, nil, mockRefreshTokenRepo, nil, nil, nil, twoFactorUsecase, jwtManager, nil)
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
+	enabledAt := time.Now()
+	user := &domain.User{ID: 3, Email: "seller@example.com", Password: string(hashedPassword), TOTPSecret: "ABCDEFGH", TOTPEnabledAt: &enabledAt}
+
+	// Mock expectations
+	mockUserRepo.On("GetByEmail", user.Email).Return(user, nil)
+	mockUserRepo.On("GetByID", user.ID).Return(user, nil)
+	mockRecoveryCodeRepo.On("Consume", user.ID, hashRecoveryCode("abcde-fghij"), mock.AnythingOfType("time.Time")).Return(true, nil)
+	mockUserRepo.On("UpdateLastLogin", user.ID, mock.AnythingOfType("time.Time")).Return(nil).Maybe()
+	mockRefreshTokenRepo.On("Create", mock.AnythingOfType("*domain.RefreshToken")).Return(nil)
+
+	// Execute
+	challenge, err := authUsecase.Login(&domain.LoginRequest{Email: user.Email, Password: "password123"})
+	assert.NoError(t, err)
+
+	// Assert: the password step only yields a challenge
+	assert.True(t, challenge.TwoFactorRequired)
+	assert.NotEmpty(t, challenge.ChallengeToken)
+	assert.Empty(t, challenge.AccessToken)
+	assert.Empty(t, challenge.RefreshToken)
+
+	// The challenge is not usable as an access token
+	_, err = jwtManager.ValidateToken(challenge.ChallengeToken)
+	assert.Error(t, err)
+
+	// Recovery codes are accepted regardless of case and separators
+	result, err := authUsecase.VerifyTwoFactorLogin(&domain.TwoFactorLoginRequest{
+		ChallengeToken: challenge.ChallengeToken,
+		Code:           "ABCDE FGHIJ",
+	})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, result.AccessToken)
+	assert.NotEmpty(t, result.RefreshToken)
+	mockRecoveryCodeRepo.AssertExpectations(t)
+}
+
+func TestAuthUsecase_Login_AdminWithoutRequiredTwoFactorGetsNoPermissions(t *testing.T) {
+	// Setup
+	mockUserRepo := new(mocks.MockUserRepository)
+	mockRoleRepo := new(mocks.MockRoleRepository)
+	mockSettingRepo := new(mocks.MockSettingRepository)
+	mockRefreshTokenRepo := new(mocks.MockRefreshTokenRepository)
+	jwtManager := jwt.NewJWTManager("test-secret", 24, 168)
+	twoFactorUsecase := newTestTwoFactorUsecase(mockUserRepo, nil, mockSettingRepo, jwtManager)
+	authUsecase := NewAuthUsecase(mockUserRepo, nil, mockRoleRepo, mockRefreshTokenRepo, nil, nil, nil, twoFactorUsecase, jwtManager, nil)
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
+	user := &domain.User{ID: 9, Email: "admin@example.com", Password: string(hashedPassword), IsAdmin: true}
+	roles := []*domain.Role{
+		{Name: domain.RoleSuperAdmin, Permissions: []*domain.Permission{{Name: domain.PermissionRolesManage}}},
+	}
+
+	// Mock expectations
+	mockUserRepo.On("GetByEmail", user.Email).Return(user, nil)
+	mockUserRepo.On("UpdateLastLogin", user.ID, mock.AnythingOfType("time.Time")).Return(nil).Maybe()
+	mockRoleRepo.On("GetUserRoles", user.ID).Return(roles, nil)
+	mockSettingRepo.On("Get", domain.SettingRequireTwoFactorForAdmins).Return(&domain.Setting{Value: "true"}, nil)
+	mockRefreshTokenRepo.On("Create", mock.AnythingOfType("*domain.RefreshToken")).Return(nil)
+
+	// Execute
+	result, err := authUsecase.Login(&domain.LoginRequest{Email: user.Email, Password: "password123"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, result.TwoFactorSetupRequired)
+	claims, err := jwtManager.ValidateToken(result.AccessToken)
+	assert.NoError(t, err)
+	assert.False(t, claims.IsAdmin)
+	assert.Empty(t, claims.Permissions)
+}
+
+func TestTwoFactorUsecase_SetupRequired_FailsClosedWhenPolicyUnreadable(t *testing.T) {
+	// Setup
+	mockSettingRepo := new(mocks.MockSettingRepository)
+	twoFactorUsecase := newTestTwoFactorUsecase(nil, nil, mockSettingRepo, nil)
+	user := &domain.User{ID: 9, Email: "admin@example.com", IsAdmin: true}
+
+	// Mock expectations
+	mockSettingRepo.On("Get", domain.SettingRequireTwoFactorForAdmins).Return(nil, errors.New("connection refused"))
+
+	// Execute
+	required := twoFactorUsecase.SetupRequired(user, []string{domain.PermissionRolesManage})
+
+	// Assert
+	assert.True(t, required)
+	mockSettingRepo.AssertExpectations(t)
+}
@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN email_verification_sent_at TIMESTAMP NULL AFTER email_verified_at;
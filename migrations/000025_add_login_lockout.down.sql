@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS login_throttles;
+
+UPDATE users SET status = 'active' WHERE status = 'locked';
+ALTER TABLE users DROP COLUMN locked_until;
+ALTER TABLE users MODIFY COLUMN status ENUM('active', 'blocked') DEFAULT 'active';
@@ -0,0 +1,13 @@
+ALTER TABLE users MODIFY COLUMN status ENUM('active', 'blocked', 'locked') DEFAULT 'active';
+ALTER TABLE users ADD COLUMN locked_until TIMESTAMP NULL AFTER status;
+
+CREATE TABLE login_throttles (
+    scope VARCHAR(16) NOT NULL,
+    subject VARCHAR(64) NOT NULL,
+    failed_count INT NOT NULL DEFAULT 0,
+    last_failed_at TIMESTAMP NOT NULL,
+    locked_until TIMESTAMP NULL,
+    PRIMARY KEY (scope, subject)
+);
+
+CREATE INDEX idx_login_throttles_last_failed_at ON login_throttles(last_failed_at);
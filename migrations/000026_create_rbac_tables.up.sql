@@ -0,0 +1,82 @@
+CREATE TABLE roles (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(64) NOT NULL,
+    description VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_roles_name ON roles(name);
+
+CREATE TABLE permissions (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(64) NOT NULL,
+    description VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_permissions_name ON permissions(name);
+
+CREATE TABLE role_permissions (
+    id_role BIGINT UNSIGNED NOT NULL,
+    id_permission BIGINT UNSIGNED NOT NULL,
+    PRIMARY KEY (id_role, id_permission),
+    FOREIGN KEY (id_role) REFERENCES roles(id) ON DELETE CASCADE,
+    FOREIGN KEY (id_permission) REFERENCES permissions(id) ON DELETE CASCADE
+);
+
+CREATE TABLE user_roles (
+    id_user BIGINT UNSIGNED NOT NULL,
+    id_role BIGINT UNSIGNED NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id_user, id_role),
+    FOREIGN KEY (id_user) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (id_role) REFERENCES roles(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_user_roles_role ON user_roles(id_role);
+
+-- Seed roles
+INSERT INTO roles (name, description) VALUES
+('super_admin', 'Full administrative access'),
+('catalog_admin', 'Moderates stores, products and categories'),
+('finance_admin', 'Handles refunds and payments'),
+('support', 'Helps users with their accounts'),
+('seller', 'Store owner');
+
+-- Seed permissions
+INSERT INTO permissions (name, description) VALUES
+('stores:suspend', 'Suspend and unsuspend stores'),
+('products:moderate', 'Review products by status and suspend them'),
+('categories:manage', 'Create, update and delete categories'),
+('transactions:refund', 'Refund transactions'),
+('payments:simulate', 'Simulate payment gateway results'),
+('users:unlock', 'Unlock accounts locked by failed logins'),
+('roles:manage', 'Assign and revoke user roles');
+
+-- Grant permissions to roles
+INSERT INTO role_permissions (id_role, id_permission)
+SELECT r.id, p.id FROM roles r CROSS JOIN permissions p WHERE r.name = 'super_admin';
+
+INSERT INTO role_permissions (id_role, id_permission)
+SELECT r.id, p.id FROM roles r JOIN permissions p
+ON p.name IN ('stores:suspend', 'products:moderate', 'categories:manage')
+WHERE r.name = 'catalog_admin';
+
+INSERT INTO role_permissions (id_role, id_permission)
+SELECT r.id, p.id FROM roles r JOIN permissions p
+ON p.name IN ('transactions:refund', 'payments:simulate')
+WHERE r.name = 'finance_admin';
+
+INSERT INTO role_permissions (id_role, id_permission)
+SELECT r.id, p.id FROM roles r JOIN permissions p
+ON p.name IN ('users:unlock')
+WHERE r.name = 'support';
+
+-- Existing admins keep full access, existing store owners become sellers
+INSERT INTO user_roles (id_user, id_role)
+SELECT u.id, r.id FROM users u JOIN roles r ON r.name = 'super_admin'
+WHERE u.is_admin = TRUE AND u.deleted_at IS NULL;
+
+INSERT INTO user_roles (id_user, id_role)
+SELECT DISTINCT t.id_user, r.id FROM toko t JOIN roles r ON r.name = 'seller'
+WHERE t.deleted_at IS NULL;
@@ -0,0 +1,8 @@
+DELETE FROM permissions WHERE name = 'security:manage';
+
+DROP TABLE IF EXISTS settings;
+DROP TABLE IF EXISTS two_factor_recovery_codes;
+
+ALTER TABLE users DROP COLUMN totp_last_step;
+ALTER TABLE users DROP COLUMN totp_enabled_at;
+ALTER TABLE users DROP COLUMN totp_secret;
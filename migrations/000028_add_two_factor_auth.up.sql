@@ -0,0 +1,29 @@
+ALTER TABLE users ADD COLUMN totp_secret VARCHAR(64) NULL AFTER locked_until;
+ALTER TABLE users ADD COLUMN totp_enabled_at TIMESTAMP NULL AFTER totp_secret;
+ALTER TABLE users ADD COLUMN totp_last_step BIGINT NOT NULL DEFAULT 0 AFTER totp_enabled_at;
+
+CREATE TABLE two_factor_recovery_codes (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    id_user BIGINT UNSIGNED NOT NULL,
+    code_hash CHAR(64) NOT NULL,
+    used_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (id_user) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_two_factor_recovery_codes_user_hash ON two_factor_recovery_codes(id_user, code_hash);
+
+CREATE TABLE settings (
+    name VARCHAR(100) PRIMARY KEY,
+    value VARCHAR(255) NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
+
+INSERT INTO settings (name, value) VALUES ('auth.require_two_factor_for_admins', 'false');
+
+INSERT INTO permissions (name, description) VALUES
+('security:manage', 'Change account security policies');
+
+INSERT INTO role_permissions (id_role, id_permission)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'security:manage'
+WHERE r.name = 'super_admin';
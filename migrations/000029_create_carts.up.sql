@@ -0,0 +1,22 @@
+CREATE TABLE carts (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    id_user BIGINT UNSIGNED NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (id_user) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_carts_user ON carts(id_user);
+
+CREATE TABLE cart_items (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    id_cart BIGINT UNSIGNED NOT NULL,
+    id_produk BIGINT UNSIGNED NOT NULL,
+    kuantitas INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (id_cart) REFERENCES carts(id) ON DELETE CASCADE,
+    FOREIGN KEY (id_produk) REFERENCES produk(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_cart_items_cart_product ON cart_items(id_cart, id_produk);
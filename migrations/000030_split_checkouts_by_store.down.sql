@@ -0,0 +1,63 @@
+ALTER TABLE payment_intents ADD COLUMN trx_id BIGINT UNSIGNED NULL AFTER id;
+
+-- Point each intent at the first order of its checkout
+UPDATE payment_intents
+JOIN (
+    SELECT id_checkout, MIN(id) AS id_trx
+    FROM trx
+    GROUP BY id_checkout
+) first_order ON first_order.id_checkout = payment_intents.checkout_id
+SET payment_intents.trx_id = first_order.id_trx;
+
+DELETE FROM payment_intents WHERE trx_id IS NULL;
+
+ALTER TABLE payment_intents MODIFY COLUMN trx_id BIGINT UNSIGNED NOT NULL;
+CREATE INDEX idx_payment_intents_trx_id ON payment_intents(trx_id);
+DROP INDEX idx_payment_intents_checkout_id ON payment_intents;
+ALTER TABLE payment_intents DROP COLUMN checkout_id;
+
+-- Bring back the intents of transactions that no longer exist
+INSERT INTO payment_intents (id, trx_id, method, status, expired_at, created_at, updated_at)
+SELECT id, trx_id, method, status, expired_at, created_at, updated_at
+FROM payment_intents_orphaned;
+
+DROP TABLE payment_intents_orphaned;
+
+-- Merge the orders of each checkout back into its first order
+UPDATE trx
+JOIN (
+    SELECT id_checkout, MIN(id) AS id_trx, SUM(harga_total) AS harga_total
+    FROM trx
+    GROUP BY id_checkout
+) checkout_total ON checkout_total.id_trx = trx.id
+SET trx.harga_total = checkout_total.harga_total;
+
+UPDATE detail_trx
+JOIN trx ON trx.id = detail_trx.id_trx
+JOIN (
+    SELECT id_checkout, MIN(id) AS id_trx
+    FROM trx
+    GROUP BY id_checkout
+) first_order ON first_order.id_checkout = trx.id_checkout
+SET detail_trx.id_trx = first_order.id_trx;
+
+DELETE trx FROM trx
+JOIN (
+    SELECT id_checkout, MIN(id) AS id_trx
+    FROM trx
+    GROUP BY id_checkout
+) first_order ON first_order.id_checkout = trx.id_checkout
+WHERE trx.id <> first_order.id_trx;
+
+ALTER TABLE trx
+DROP FOREIGN KEY fk_trx_checkout,
+DROP FOREIGN KEY fk_trx_toko;
+
+DROP INDEX idx_trx_checkout ON trx;
+DROP INDEX idx_trx_toko ON trx;
+
+ALTER TABLE trx
+DROP COLUMN id_checkout,
+DROP COLUMN id_toko;
+
+DROP TABLE IF EXISTS checkouts;
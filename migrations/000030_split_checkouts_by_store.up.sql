@@ -0,0 +1,102 @@
+-- A checkout is what the buyer pays for. It holds one order (trx row) per store
+CREATE TABLE checkouts (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    id_user BIGINT UNSIGNED NOT NULL,
+    alamat_pengiriman BIGINT UNSIGNED NOT NULL,
+    harga_total DECIMAL(14,2) NOT NULL,
+    kode_checkout VARCHAR(255) NOT NULL,
+    metode_bayar ENUM('transfer', 'cod', 'ewallet', 'credit_card'),
+    status_pembayaran ENUM('pending', 'paid', 'failed', 'refunded', 'cancelled') DEFAULT 'pending',
+    paid_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (id_user) REFERENCES users(id) ON DELETE RESTRICT,
+    FOREIGN KEY (alamat_pengiriman) REFERENCES alamat(id) ON DELETE RESTRICT
+);
+
+CREATE UNIQUE INDEX idx_checkouts_kode ON checkouts(kode_checkout);
+CREATE INDEX idx_checkouts_user ON checkouts(id_user);
+
+ALTER TABLE trx
+ADD COLUMN id_checkout BIGINT UNSIGNED NULL AFTER id_user,
+ADD COLUMN id_toko BIGINT UNSIGNED NULL AFTER id_checkout;
+
+-- Every existing transaction becomes a checkout of its own
+INSERT INTO checkouts (id_user, alamat_pengiriman, harga_total, kode_checkout, metode_bayar, status_pembayaran, paid_at, created_at, updated_at)
+SELECT id_user, alamat_pengiriman, harga_total, CONCAT('CHK-TRX-', id), metode_bayar,
+    CASE
+        WHEN status_pembayaran IN ('paid', 'shipped', 'done') THEN 'paid'
+        ELSE status_pembayaran
+    END,
+    paid_at, created_at, updated_at
+FROM trx;
+
+UPDATE trx
+JOIN checkouts ON checkouts.kode_checkout = CONCAT('CHK-TRX-', trx.id)
+SET trx.id_checkout = checkouts.id;
+
+-- Every order belongs to one store. An existing transaction with items from
+-- several stores keeps the items of its first store, and the items of each
+-- other store become an order of their own in the same checkout
+UPDATE trx
+JOIN (
+    SELECT id_trx, MIN(id_toko) AS id_toko
+    FROM detail_trx
+    GROUP BY id_trx
+) first_store ON first_store.id_trx = trx.id
+SET trx.id_toko = first_store.id_toko;
+
+INSERT INTO trx (id_user, id_checkout, id_toko, alamat_pengiriman, harga_total, kode_invoice, metode_bayar, status_pembayaran, order_status, paid_at, shipped_at, created_at, updated_at)
+SELECT trx.id_user, trx.id_checkout, detail_trx.id_toko, trx.alamat_pengiriman, SUM(detail_trx.harga_total),
+    CONCAT(trx.kode_invoice, '-', detail_trx.id_toko), trx.metode_bayar, trx.status_pembayaran, trx.order_status,
+    trx.paid_at, trx.shipped_at, trx.created_at, trx.updated_at
+FROM trx
+JOIN detail_trx ON detail_trx.id_trx = trx.id
+WHERE detail_trx.id_toko <> trx.id_toko
+GROUP BY trx.id, detail_trx.id_toko;
+
+-- The first order no longer charges for the items that moved; the checkout
+-- keeps the original total
+UPDATE trx
+JOIN (
+    SELECT detail_trx.id_trx, SUM(detail_trx.harga_total) AS harga_total
+    FROM detail_trx
+    JOIN trx ON trx.id = detail_trx.id_trx
+    WHERE detail_trx.id_toko <> trx.id_toko
+    GROUP BY detail_trx.id_trx
+) moved ON moved.id_trx = trx.id
+SET trx.harga_total = trx.harga_total - moved.harga_total;
+
+UPDATE detail_trx
+JOIN trx first_order ON first_order.id = detail_trx.id_trx
+JOIN trx store_order ON store_order.id_checkout = first_order.id_checkout AND store_order.id_toko = detail_trx.id_toko
+SET detail_trx.id_trx = store_order.id
+WHERE detail_trx.id_toko <> first_order.id_toko;
+
+ALTER TABLE trx
+ADD CONSTRAINT fk_trx_checkout FOREIGN KEY (id_checkout) REFERENCES checkouts(id) ON DELETE CASCADE,
+ADD CONSTRAINT fk_trx_toko FOREIGN KEY (id_toko) REFERENCES toko(id) ON DELETE RESTRICT;
+
+CREATE INDEX idx_trx_checkout ON trx(id_checkout);
+CREATE INDEX idx_trx_toko ON trx(id_toko);
+
+-- One payment covers the whole checkout
+ALTER TABLE payment_intents ADD COLUMN checkout_id BIGINT UNSIGNED NULL AFTER id;
+
+UPDATE payment_intents
+JOIN trx ON trx.id = payment_intents.trx_id
+SET payment_intents.checkout_id = trx.id_checkout;
+
+-- Intents of transactions that no longer exist have no checkout to pay;
+-- their history is kept aside
+CREATE TABLE payment_intents_orphaned LIKE payment_intents;
+
+INSERT INTO payment_intents_orphaned
+SELECT * FROM payment_intents WHERE checkout_id IS NULL;
+
+DELETE FROM payment_intents WHERE checkout_id IS NULL;
+
+ALTER TABLE payment_intents MODIFY COLUMN checkout_id BIGINT UNSIGNED NOT NULL;
+CREATE INDEX idx_payment_intents_checkout_id ON payment_intents(checkout_id);
+DROP INDEX idx_payment_intents_trx_id ON payment_intents;
+ALTER TABLE payment_intents DROP COLUMN trx_id;
@@ -0,0 +1,21 @@
+-- Stock held by unpaid checkouts. Available stock is stok - stok_ditahan
+ALTER TABLE produk ADD COLUMN stok_ditahan INT NOT NULL DEFAULT 0 AFTER stok;
+
+CREATE TABLE stock_reservations (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    id_checkout BIGINT UNSIGNED NOT NULL,
+    id_trx BIGINT UNSIGNED NOT NULL,
+    id_produk BIGINT UNSIGNED NOT NULL,
+    kuantitas INT NOT NULL,
+    status ENUM('held', 'released', 'converted') NOT NULL DEFAULT 'held',
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (id_checkout) REFERENCES checkouts(id) ON DELETE CASCADE,
+    FOREIGN KEY (id_trx) REFERENCES trx(id) ON DELETE CASCADE,
+    FOREIGN KEY (id_produk) REFERENCES produk(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_stock_reservations_checkout ON stock_reservations(id_checkout, status);
+CREATE INDEX idx_stock_reservations_trx ON stock_reservations(id_trx, status);
+CREATE INDEX idx_stock_reservations_expiry ON stock_reservations(status, expires_at);
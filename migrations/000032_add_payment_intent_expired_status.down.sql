@@ -0,0 +1,4 @@
+UPDATE payment_intents SET status = 'failed' WHERE status = 'expired';
+
+ALTER TABLE payment_intents
+    MODIFY COLUMN status ENUM('pending', 'success', 'failed') NOT NULL DEFAULT 'pending';
@@ -0,0 +1,3 @@
+-- Intents that were never paid before expired_at are swept to 'expired'
+ALTER TABLE payment_intents
+    MODIFY COLUMN status ENUM('pending', 'success', 'failed', 'expired') NOT NULL DEFAULT 'pending';
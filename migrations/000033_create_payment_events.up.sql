@@ -0,0 +1,23 @@
+-- Audit log of every payment gateway callback. claimed_ref is set only on the
+-- event that processed a gateway reference, so the unique key rejects replays
+-- while duplicates and rejected callbacks are still logged.
+CREATE TABLE payment_events (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    payment_intent_id BIGINT UNSIGNED NULL,
+    gateway VARCHAR(50) NOT NULL,
+    gateway_ref VARCHAR(255) NULL,
+    claimed_ref VARCHAR(255) NULL,
+    status VARCHAR(20) NULL,
+    outcome ENUM('received', 'processed', 'duplicate', 'rejected', 'failed') NOT NULL,
+    message VARCHAR(255) NULL,
+    payload TEXT NULL,
+    paid_at TIMESTAMP NULL,
+    remote_ip VARCHAR(45) NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    UNIQUE KEY uniq_payment_events_claimed_ref (gateway, claimed_ref),
+    INDEX idx_payment_events_intent (payment_intent_id),
+    INDEX idx_payment_events_gateway_ref (gateway, gateway_ref),
+    INDEX idx_payment_events_created (created_at)
+);
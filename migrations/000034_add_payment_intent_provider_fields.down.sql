@@ -0,0 +1,9 @@
+DROP INDEX idx_payment_intents_provider_ref ON payment_intents;
+
+ALTER TABLE payment_intents
+    DROP COLUMN payment_url,
+    DROP COLUMN qr_payload,
+    DROP COLUMN virtual_account,
+    DROP COLUMN provider_ref,
+    DROP COLUMN provider,
+    DROP COLUMN amount;
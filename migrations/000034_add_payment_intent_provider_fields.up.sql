@@ -0,0 +1,13 @@
+ALTER TABLE payment_intents
+    ADD COLUMN amount DECIMAL(14,2) NOT NULL DEFAULT 0 AFTER status,
+    ADD COLUMN provider VARCHAR(50) NULL AFTER amount,
+    ADD COLUMN provider_ref VARCHAR(255) NULL AFTER provider,
+    ADD COLUMN virtual_account VARCHAR(50) NULL AFTER provider_ref,
+    ADD COLUMN qr_payload TEXT NULL AFTER virtual_account,
+    ADD COLUMN payment_url VARCHAR(500) NULL AFTER qr_payload;
+
+UPDATE payment_intents
+JOIN checkouts ON checkouts.id = payment_intents.checkout_id
+SET payment_intents.amount = checkouts.harga_total;
+
+CREATE INDEX idx_payment_intents_provider_ref ON payment_intents(provider, provider_ref);
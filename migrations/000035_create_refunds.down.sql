@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS refund_items;
+DROP TABLE IF EXISTS refunds;
+
+ALTER TABLE detail_trx DROP COLUMN refunded_quantity;
+ALTER TABLE trx DROP COLUMN refunded_amount;
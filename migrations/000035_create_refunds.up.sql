@@ -0,0 +1,39 @@
+-- Refund ledger. A paid order can be refunded in parts; refunded_amount and
+-- refunded_quantity track what is left to refund.
+ALTER TABLE trx ADD COLUMN refunded_amount DECIMAL(14,2) NOT NULL DEFAULT 0 AFTER harga_total;
+ALTER TABLE detail_trx ADD COLUMN refunded_quantity INT NOT NULL DEFAULT 0 AFTER kuantitas;
+
+-- Orders refunded before the ledger existed were refunded in full
+UPDATE trx SET refunded_amount = harga_total WHERE status_pembayaran = 'refunded';
+UPDATE detail_trx d JOIN trx t ON t.id = d.id_trx
+SET d.refunded_quantity = d.kuantitas
+WHERE t.status_pembayaran = 'refunded';
+
+CREATE TABLE refunds (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    id_trx BIGINT UNSIGNED NOT NULL,
+    id_checkout BIGINT UNSIGNED NOT NULL,
+    amount DECIMAL(14,2) NOT NULL,
+    reason VARCHAR(255) NULL,
+    refunded_by BIGINT UNSIGNED NOT NULL,
+    provider_ref VARCHAR(255) NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (id_trx) REFERENCES trx(id) ON DELETE CASCADE,
+    FOREIGN KEY (id_checkout) REFERENCES checkouts(id) ON DELETE CASCADE,
+    FOREIGN KEY (refunded_by) REFERENCES users(id) ON DELETE RESTRICT
+);
+
+CREATE INDEX idx_refunds_trx ON refunds(id_trx);
+
+CREATE TABLE refund_items (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    id_refund BIGINT UNSIGNED NOT NULL,
+    id_detail_trx BIGINT UNSIGNED NOT NULL,
+    id_produk BIGINT UNSIGNED NOT NULL,
+    kuantitas INT NOT NULL,
+    amount DECIMAL(14,2) NOT NULL,
+    FOREIGN KEY (id_refund) REFERENCES refunds(id) ON DELETE CASCADE,
+    FOREIGN KEY (id_detail_trx) REFERENCES detail_trx(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_refund_items_refund ON refund_items(id_refund);
@@ -0,0 +1,3 @@
+ALTER TABLE trx DROP COLUMN delivered_at;
+
+DROP TABLE IF EXISTS trx_status_history;
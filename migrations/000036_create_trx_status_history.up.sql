@@ -0,0 +1,18 @@
+-- Every payment and order status change of an order, with who made it
+CREATE TABLE trx_status_history (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    id_trx BIGINT UNSIGNED NOT NULL,
+    field ENUM('payment', 'order') NOT NULL,
+    from_status VARCHAR(20) NOT NULL,
+    to_status VARCHAR(20) NOT NULL,
+    actor_role ENUM('buyer', 'seller', 'admin', 'system') NOT NULL,
+    actor_id BIGINT UNSIGNED NULL,
+    reason VARCHAR(255) NULL,
+    created_at TIMESTAMP(3) DEFAULT CURRENT_TIMESTAMP(3),
+    FOREIGN KEY (id_trx) REFERENCES trx(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_trx_status_history_trx ON trx_status_history(id_trx, created_at);
+
+-- Delivery is stamped like payment and shipping
+ALTER TABLE trx ADD COLUMN delivered_at TIMESTAMP NULL AFTER shipped_at;
@@ -0,0 +1,33 @@
+-- How an order was shipped: courier, service level and tracking number
+CREATE TABLE shipments (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    id_trx BIGINT UNSIGNED NOT NULL,
+    courier VARCHAR(20) NOT NULL,
+    service VARCHAR(50) NOT NULL,
+    tracking_number VARCHAR(100) NOT NULL,
+    estimated_delivery_date DATE NULL,
+    status ENUM('in_transit', 'delivered') DEFAULT 'in_transit',
+    delivered_at TIMESTAMP NULL,
+    last_polled_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY idx_shipments_trx (id_trx),
+    FOREIGN KEY (id_trx) REFERENCES trx(id) ON DELETE CASCADE
+);
+
+-- The tracking poller picks in-transit shipments polled longest ago
+CREATE INDEX idx_shipments_polling ON shipments(status, last_polled_at);
+
+-- Tracking updates reported by the courier; re-polling the same history
+-- must not duplicate events
+CREATE TABLE shipment_events (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    id_shipment BIGINT UNSIGNED NOT NULL,
+    status VARCHAR(50) NOT NULL,
+    description VARCHAR(255) NULL,
+    location VARCHAR(255) NULL,
+    occurred_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY idx_shipment_events_unique (id_shipment, occurred_at, status),
+    FOREIGN KEY (id_shipment) REFERENCES shipments(id) ON DELETE CASCADE
+);
@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS shipping_rates;
+
+ALTER TABLE trx
+    DROP COLUMN shipping_fee,
+    DROP COLUMN shipping_weight,
+    DROP COLUMN shipping_service,
+    DROP COLUMN shipping_courier;
+
+ALTER TABLE toko
+    DROP COLUMN city_id,
+    DROP COLUMN province_id;
@@ -0,0 +1,43 @@
+-- Where each store ships from, using the region IDs of the addresses
+ALTER TABLE toko
+    ADD COLUMN province_id VARCHAR(10) NULL AFTER rating,
+    ADD COLUMN city_id VARCHAR(10) NULL AFTER province_id;
+
+-- Shipping fee line of each order
+ALTER TABLE trx
+    ADD COLUMN shipping_courier VARCHAR(20) NULL AFTER harga_total,
+    ADD COLUMN shipping_service VARCHAR(50) NULL AFTER shipping_courier,
+    ADD COLUMN shipping_weight INT NOT NULL DEFAULT 0 AFTER shipping_service,
+    ADD COLUMN shipping_fee DECIMAL(12,2) NOT NULL DEFAULT 0 AFTER shipping_weight;
+
+-- Price per kg of each courier service between regions. NULL regions match
+-- any region; the most specific matching row wins.
+CREATE TABLE shipping_rates (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    courier VARCHAR(20) NOT NULL,
+    service VARCHAR(50) NOT NULL,
+    origin_province_id VARCHAR(10) NULL,
+    origin_city_id VARCHAR(10) NULL,
+    destination_province_id VARCHAR(10) NULL,
+    destination_city_id VARCHAR(10) NULL,
+    price_per_kg DECIMAL(12,2) NOT NULL,
+    estimated_days VARCHAR(10) NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_shipping_rates_courier ON shipping_rates(courier, service);
+
+-- Nationwide fallbacks, then cheaper rates within a province and a city
+INSERT INTO shipping_rates (courier, service, origin_province_id, origin_city_id, destination_province_id, destination_city_id, price_per_kg, estimated_days) VALUES
+('jne', 'REG', NULL, NULL, NULL, NULL, 25000, '3-5'),
+('jne', 'YES', NULL, NULL, NULL, NULL, 45000, '1-2'),
+('jnt', 'EZ', NULL, NULL, NULL, NULL, 23000, '3-5'),
+('sicepat', 'REG', NULL, NULL, NULL, NULL, 22000, '3-6'),
+('pos', 'Kilat Khusus', NULL, NULL, NULL, NULL, 20000, '4-7'),
+('jne', 'REG', '31', NULL, '31', NULL, 10000, '1-2'),
+('jnt', 'EZ', '31', NULL, '31', NULL, 9000, '1-2'),
+('sicepat', 'REG', '31', NULL, '31', NULL, 9000, '1-2'),
+('jne', 'REG', '31', NULL, '32', NULL, 12000, '2-3'),
+('jne', 'REG', '32', NULL, '31', NULL, 12000, '2-3'),
+('jne', 'REG', '31', '3171', '31', '3171', 8000, '1');
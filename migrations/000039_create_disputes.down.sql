@@ -0,0 +1,7 @@
+DELETE FROM permissions WHERE name = 'disputes:resolve';
+
+DROP TABLE IF EXISTS dispute_photos;
+DROP TABLE IF EXISTS disputes;
+
+DROP INDEX idx_trx_completion ON trx;
+ALTER TABLE trx DROP COLUMN completed_at;
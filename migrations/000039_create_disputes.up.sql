@@ -0,0 +1,44 @@
+-- Completed orders are stamped when they move to done
+ALTER TABLE trx ADD COLUMN completed_at TIMESTAMP NULL AFTER shipped_at;
+
+-- The auto-confirm job picks paid orders shipped longest ago
+CREATE INDEX idx_trx_completion ON trx(status_pembayaran, order_status, shipped_at);
+
+-- Buyer complaints about shipped orders, one per order
+CREATE TABLE disputes (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    id_trx BIGINT UNSIGNED NOT NULL,
+    id_user BIGINT UNSIGNED NOT NULL,
+    reason TEXT NOT NULL,
+    status ENUM('open', 'refunded', 'partially_refunded', 'rejected') DEFAULT 'open',
+    resolution_note VARCHAR(255) NULL,
+    id_refund BIGINT UNSIGNED NULL,
+    resolved_by BIGINT UNSIGNED NULL,
+    resolved_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY idx_disputes_trx (id_trx),
+    FOREIGN KEY (id_trx) REFERENCES trx(id) ON DELETE CASCADE,
+    FOREIGN KEY (id_user) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (id_refund) REFERENCES refunds(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_disputes_status ON disputes(status);
+
+-- Evidence photos uploaded by the buyer
+CREATE TABLE dispute_photos (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    id_dispute BIGINT UNSIGNED NOT NULL,
+    url VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (id_dispute) REFERENCES disputes(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_dispute_photos_dispute ON dispute_photos(id_dispute);
+
+INSERT INTO permissions (name, description) VALUES
+('disputes:resolve', 'Review and resolve order disputes');
+
+INSERT INTO role_permissions (id_role, id_permission)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'disputes:resolve'
+WHERE r.name IN ('super_admin', 'finance_admin');
@@ -0,0 +1,9 @@
+-- Per-day counters behind invoice and checkout codes, e.g. INV/20261017/12/3.
+-- Invoices count per store; checkouts use store 0.
+CREATE TABLE invoice_sequences (
+    name VARCHAR(20) NOT NULL,
+    day DATE NOT NULL,
+    id_toko BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    last_seq BIGINT UNSIGNED NOT NULL,
+    PRIMARY KEY (name, day, id_toko)
+);
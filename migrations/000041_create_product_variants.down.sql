@@ -0,0 +1,17 @@
+ALTER TABLE log_produk
+    DROP COLUMN variant_attributes,
+    DROP COLUMN variant_sku,
+    DROP COLUMN id_varian;
+
+-- Keep one line per product before restoring the old unique index
+DELETE ci FROM cart_items ci
+JOIN cart_items other ON other.id_cart = ci.id_cart AND other.id_produk = ci.id_produk AND other.id < ci.id;
+CREATE UNIQUE INDEX idx_cart_items_cart_product ON cart_items(id_cart, id_produk);
+DROP INDEX idx_cart_items_cart_variant ON cart_items;
+
+ALTER TABLE cart_items DROP COLUMN id_varian;
+ALTER TABLE refund_items DROP COLUMN id_varian;
+ALTER TABLE stock_reservations DROP COLUMN id_varian;
+
+DROP TABLE IF EXISTS varian_produk;
+DROP TABLE IF EXISTS opsi_produk;
@@ -0,0 +1,52 @@
+-- Dimensions a product varies in, e.g. size or color, with their values
+CREATE TABLE opsi_produk (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    id_produk BIGINT UNSIGNED NOT NULL,
+    name VARCHAR(50) NOT NULL,
+    option_values JSON NOT NULL,
+    position INT DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (id_produk) REFERENCES produk(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_opsi_produk_produk ON opsi_produk(id_produk);
+
+-- Sellable SKUs. NULL price and weight fall back to the product.
+CREATE TABLE varian_produk (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    id_produk BIGINT UNSIGNED NOT NULL,
+    sku VARCHAR(100) NOT NULL,
+    attributes JSON NOT NULL,
+    harga_reseller DECIMAL(12,2) NULL,
+    harga_konsumen DECIMAL(12,2) NULL,
+    stok INT NOT NULL DEFAULT 0,
+    stok_ditahan INT NOT NULL DEFAULT 0,
+    berat INT NULL,
+    id_foto BIGINT UNSIGNED NULL,
+    status ENUM('active', 'inactive') DEFAULT 'active',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL,
+    FOREIGN KEY (id_produk) REFERENCES produk(id) ON DELETE CASCADE,
+    FOREIGN KEY (id_foto) REFERENCES foto_produk(id) ON DELETE SET NULL
+);
+
+CREATE UNIQUE INDEX idx_varian_produk_sku ON varian_produk(sku);
+CREATE INDEX idx_varian_produk_produk ON varian_produk(id_produk);
+CREATE INDEX idx_varian_produk_deleted_at ON varian_produk(deleted_at);
+
+-- Stock holds, cart lines and refunds point at a variant; 0 means the product
+ALTER TABLE stock_reservations ADD COLUMN id_varian BIGINT UNSIGNED NOT NULL DEFAULT 0 AFTER id_produk;
+ALTER TABLE refund_items ADD COLUMN id_varian BIGINT UNSIGNED NOT NULL DEFAULT 0 AFTER id_produk;
+ALTER TABLE cart_items ADD COLUMN id_varian BIGINT UNSIGNED NOT NULL DEFAULT 0 AFTER id_produk;
+
+-- The new index covers id_cart for its foreign key before the old one goes
+CREATE UNIQUE INDEX idx_cart_items_cart_variant ON cart_items(id_cart, id_produk, id_varian);
+DROP INDEX idx_cart_items_cart_product ON cart_items;
+
+-- Order snapshots record the variant that was bought
+ALTER TABLE log_produk
+    ADD COLUMN id_varian BIGINT UNSIGNED NOT NULL DEFAULT 0 AFTER id_category,
+    ADD COLUMN variant_sku VARCHAR(100) NULL AFTER id_varian,
+    ADD COLUMN variant_attributes JSON NULL AFTER variant_sku;
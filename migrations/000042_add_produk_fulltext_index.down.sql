@@ -0,0 +1,2 @@
+DROP INDEX idx_produk_fulltext ON produk;
+DROP INDEX idx_produk_fulltext_nama ON produk;
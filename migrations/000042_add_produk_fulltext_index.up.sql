@@ -0,0 +1,4 @@
+-- Full-text indexes behind product search. The name index is matched on its
+-- own as well so that name matches rank above description matches.
+CREATE FULLTEXT INDEX idx_produk_fulltext_nama ON produk(nama_produk);
+CREATE FULLTEXT INDEX idx_produk_fulltext ON produk(nama_produk, deskripsi);
@@ -0,0 +1,9 @@
+-- How often each normalized search text was searched, to rank search
+-- suggestions
+CREATE TABLE search_queries (
+    query VARCHAR(100) NOT NULL,
+    search_count BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    last_searched_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (query),
+    INDEX idx_search_queries_count (search_count)
+);
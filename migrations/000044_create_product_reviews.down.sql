@@ -0,0 +1,14 @@
+DELETE FROM permissions WHERE name = 'reviews:moderate';
+
+DROP TABLE IF EXISTS review_photos;
+DROP TABLE IF EXISTS product_reviews;
+
+ALTER TABLE toko
+    DROP COLUMN rating_sum,
+    DROP COLUMN rating_count;
+
+DROP INDEX idx_produk_rating ON produk;
+ALTER TABLE produk
+    DROP COLUMN rating_sum,
+    DROP COLUMN rating_count,
+    DROP COLUMN rating;
@@ -0,0 +1,60 @@
+-- Ratings are kept up to date from visible reviews: the sum and count change
+-- with every review, and the average is derived from them
+ALTER TABLE produk
+    ADD COLUMN rating DECIMAL(2,1) NOT NULL DEFAULT 0.0 AFTER sold_count,
+    ADD COLUMN rating_count INT NOT NULL DEFAULT 0 AFTER rating,
+    ADD COLUMN rating_sum INT NOT NULL DEFAULT 0 AFTER rating_count;
+
+CREATE INDEX idx_produk_rating ON produk(rating, rating_count);
+
+ALTER TABLE toko
+    ADD COLUMN rating_count INT NOT NULL DEFAULT 0 AFTER rating,
+    ADD COLUMN rating_sum INT NOT NULL DEFAULT 0 AFTER rating_count;
+
+-- Seeded store ratings had no reviews behind them
+UPDATE toko SET rating = 0.0;
+
+-- Buyer reviews, one per purchased order item
+CREATE TABLE product_reviews (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    id_detail_trx BIGINT UNSIGNED NOT NULL,
+    id_trx BIGINT UNSIGNED NOT NULL,
+    id_produk BIGINT UNSIGNED NOT NULL,
+    id_toko BIGINT UNSIGNED NOT NULL,
+    id_user BIGINT UNSIGNED NOT NULL,
+    rating TINYINT UNSIGNED NOT NULL,
+    comment TEXT NULL,
+    status ENUM('visible', 'hidden') DEFAULT 'visible',
+    reply TEXT NULL,
+    replied_at TIMESTAMP NULL,
+    hidden_reason VARCHAR(255) NULL,
+    hidden_by BIGINT UNSIGNED NULL,
+    hidden_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY idx_product_reviews_detail_trx (id_detail_trx),
+    FOREIGN KEY (id_detail_trx) REFERENCES detail_trx(id) ON DELETE CASCADE,
+    FOREIGN KEY (id_produk) REFERENCES produk(id) ON DELETE CASCADE,
+    FOREIGN KEY (id_toko) REFERENCES toko(id) ON DELETE CASCADE,
+    FOREIGN KEY (id_user) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_product_reviews_produk ON product_reviews(id_produk, status, created_at);
+
+-- Photos uploaded with a review
+CREATE TABLE review_photos (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    id_review BIGINT UNSIGNED NOT NULL,
+    url VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (id_review) REFERENCES product_reviews(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_review_photos_review ON review_photos(id_review);
+
+INSERT INTO permissions (name, description) VALUES
+('reviews:moderate', 'Hide abusive product reviews');
+
+INSERT INTO role_permissions (id_role, id_permission)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'reviews:moderate'
+WHERE r.name IN ('super_admin', 'catalog_admin');
@@ -0,0 +1,4 @@
+UPDATE payment_intents SET status = 'success' WHERE status IN ('refunded', 'needs_refund');
+
+ALTER TABLE payment_intents
+    MODIFY COLUMN status ENUM('pending', 'success', 'failed', 'expired') NOT NULL DEFAULT 'pending';
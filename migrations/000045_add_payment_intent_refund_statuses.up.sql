@@ -0,0 +1,4 @@
+-- Intents paid for checkouts that could no longer be fulfilled are refunded,
+-- or left as 'needs_refund' when the provider could not refund them
+ALTER TABLE payment_intents
+    MODIFY COLUMN status ENUM('pending', 'success', 'failed', 'expired', 'refunded', 'needs_refund') NOT NULL DEFAULT 'pending';
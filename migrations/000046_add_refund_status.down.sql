@@ -0,0 +1,2 @@
+DROP INDEX idx_refunds_status ON refunds;
+ALTER TABLE refunds DROP COLUMN status;
@@ -0,0 +1,6 @@
+-- Refunds are recorded as pending before the payment provider is asked to
+-- return the money; refunds made before this were sent to the provider first
+ALTER TABLE refunds
+    ADD COLUMN status ENUM('pending', 'completed', 'failed') NOT NULL DEFAULT 'completed' AFTER provider_ref;
+
+CREATE INDEX idx_refunds_status ON refunds(status);
@@ -0,0 +1,7 @@
+-- Staff who look up orders for buyers need not be able to refund them
+INSERT INTO permissions (name, description) VALUES
+('transactions:view', 'View the timeline, shipment and invoice of any order');
+
+INSERT INTO role_permissions (id_role, id_permission)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'transactions:view'
+WHERE r.name IN ('super_admin', 'finance_admin', 'support');
@@ -1,9 +1,12 @@
 package config
 
 import (
+	"errors"
 	"log"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -13,6 +16,15 @@ type Config struct {
 	App      AppConfig
 	JWT      JWTConfig
 	Upload   UploadConfig
+	Mail     MailConfig
+	Auth     AuthConfig
+	Checkout CheckoutConfig
+	Payment  PaymentConfig
+	Shipping ShippingConfig
+	Order    OrderConfig
+	Invoice  InvoiceConfig
+	Search   SearchConfig
+	Paging   PagingConfig
 }
 
 type DatabaseConfig struct {
@@ -31,10 +43,21 @@ type AppConfig struct {
 	Env  string
 }
 
+// DefaultJWTSecret is the development fallback for JWT_SECRET. It is public,
+// so Validate refuses it in production.
+const DefaultJWTSecret = "your-secret-key"
+
 type JWTConfig struct {
 	Secret             string
 	ExpireHours        int
 	RefreshExpireHours int
+	KeysDir            string
+	SigningKeyID       string
+	KeysReloadMinutes  int
+	AcceptLegacyHS256  bool
+	// AcceptUntypedUntil is a YYYY-MM-DD date until which tokens without a
+	// typ claim still authenticate; empty rejects them
+	AcceptUntypedUntil string
 }
 
 type UploadConfig struct {
@@ -42,6 +65,80 @@ type UploadConfig struct {
 	MaxFileSize int64
 }
 
+type MailConfig struct {
+	Driver       string
+	From         string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	OutputDir    string
+}
+
+type AuthConfig struct {
+	EmailVerificationURL            string
+	EmailVerificationExpireHours    int
+	EmailVerificationResendSeconds  int
+	RequireVerifiedEmailForCheckout bool
+	RequireVerifiedEmailForStore    bool
+	PasswordResetURL                string
+	PasswordResetExpireMinutes      int
+	LoginMaxAccountAttempts         int
+	LoginMaxIPAttempts              int
+	LoginAttemptWindowMinutes       int
+	LoginLockoutBaseSeconds         int
+	LoginLockoutMaxSeconds          int
+	TwoFactorIssuer                 string
+	TwoFactorChallengeMinutes       int
+	TwoFactorRecoveryCodes          int
+}
+
+type CheckoutConfig struct {
+	StockHoldMinutes            int
+	StockReleaseIntervalSeconds int
+}
+
+type PaymentConfig struct {
+	IntentExpireMinutes        int
+	ExpirySweepIntervalSeconds int
+	GatewaySecrets             map[string]string
+	CallbackToleranceSeconds   int
+	CallbackBaseURL            string
+	MockGatewayEnabled         bool
+	MockGatewayAddr            string
+}
+
+type ShippingConfig struct {
+	TrackingFile                string
+	TrackingPollIntervalSeconds int
+}
+
+type OrderConfig struct {
+	AutoConfirmDays            int
+	AutoConfirmIntervalSeconds int
+}
+
+type InvoiceConfig struct {
+	InvoiceFormat  string
+	CheckoutFormat string
+}
+
+// SearchConfig picks the product search index: "mysql" uses the FULLTEXT
+// indexes on produk, "memory" an in-process index that is rebuilt at startup
+// and only suits a single app instance
+type SearchConfig struct {
+	Backend string
+	// SuggestRebuildIntervalMinutes is how often the in-process search
+	// suggestions are reloaded from the database
+	SuggestRebuildIntervalMinutes int
+}
+
+// PagingConfig holds the secret that signs list cursors. It falls back to
+// JWT_SECRET, so rotating either invalidates outstanding cursors.
+type PagingConfig struct {
+	CursorSecret string
+}
+
 func Load() *Config {
 	err := godotenv.Load()
 	if err != nil {
@@ -52,6 +149,31 @@ func Load() *Config {
 	refreshExpireHours, _ := strconv.Atoi(getEnv("JWT_REFRESH_EXPIRE_HOURS", "168"))
 	maxFileSize, _ := strconv.ParseInt(getEnv("MAX_FILE_SIZE", "5242880"), 10, 64)
 	parseTime, _ := strconv.ParseBool(getEnv("DB_PARSE_TIME", "true"))
+	verificationExpireHours, _ := strconv.Atoi(getEnv("EMAIL_VERIFICATION_EXPIRE_HOURS", "24"))
+	verificationResendSeconds, _ := strconv.Atoi(getEnv("EMAIL_VERIFICATION_RESEND_SECONDS", "60"))
+	requireVerifiedCheckout, _ := strconv.ParseBool(getEnv("REQUIRE_VERIFIED_EMAIL_FOR_CHECKOUT", "false"))
+	requireVerifiedStore, _ := strconv.ParseBool(getEnv("REQUIRE_VERIFIED_EMAIL_FOR_STORE", "false"))
+	passwordResetExpireMinutes, _ := strconv.Atoi(getEnv("PASSWORD_RESET_EXPIRE_MINUTES", "30"))
+	loginMaxAccountAttempts, _ := strconv.Atoi(getEnv("LOGIN_MAX_ACCOUNT_ATTEMPTS", "5"))
+	loginMaxIPAttempts, _ := strconv.Atoi(getEnv("LOGIN_MAX_IP_ATTEMPTS", "20"))
+	loginAttemptWindowMinutes, _ := strconv.Atoi(getEnv("LOGIN_ATTEMPT_WINDOW_MINUTES", "15"))
+	loginLockoutBaseSeconds, _ := strconv.Atoi(getEnv("LOGIN_LOCKOUT_BASE_SECONDS", "60"))
+	loginLockoutMaxSeconds, _ := strconv.Atoi(getEnv("LOGIN_LOCKOUT_MAX_SECONDS", "3600"))
+	twoFactorChallengeMinutes, _ := strconv.Atoi(getEnv("TWO_FACTOR_CHALLENGE_MINUTES", "5"))
+	twoFactorRecoveryCodes, _ := strconv.Atoi(getEnv("TWO_FACTOR_RECOVERY_CODES", "10"))
+	keysReloadMinutes, _ := strconv.Atoi(getEnv("JWT_KEYS_RELOAD_MINUTES", "5"))
+	acceptLegacyHS256, _ := strconv.ParseBool(getEnv("JWT_ACCEPT_LEGACY_HS256", "false"))
+	stockHoldMinutes, _ := strconv.Atoi(getEnv("CHECKOUT_STOCK_HOLD_MINUTES", "30"))
+	stockReleaseIntervalSeconds, _ := strconv.Atoi(getEnv("CHECKOUT_STOCK_RELEASE_INTERVAL_SECONDS", "60"))
+	intentExpireMinutes, _ := strconv.Atoi(getEnv("PAYMENT_INTENT_EXPIRE_MINUTES", "30"))
+	expirySweepIntervalSeconds, _ := strconv.Atoi(getEnv("PAYMENT_EXPIRY_SWEEP_INTERVAL_SECONDS", "60"))
+	callbackToleranceSeconds, _ := strconv.Atoi(getEnv("PAYMENT_CALLBACK_TOLERANCE_SECONDS", "300"))
+	trackingPollIntervalSeconds, _ := strconv.Atoi(getEnv("SHIPPING_TRACKING_POLL_INTERVAL_SECONDS", "300"))
+	autoConfirmDays, _ := strconv.Atoi(getEnv("ORDER_AUTO_CONFIRM_DAYS", "7"))
+	autoConfirmIntervalSeconds, _ := strconv.Atoi(getEnv("ORDER_AUTO_CONFIRM_INTERVAL_SECONDS", "3600"))
+	suggestRebuildIntervalMinutes, _ := strconv.Atoi(getEnv("SEARCH_SUGGEST_REBUILD_INTERVAL_MINUTES", "15"))
+	appEnv := getEnv("APP_ENV", "development")
+	mockGatewayEnabled, _ := strconv.ParseBool(getEnv("PAYMENT_MOCK_GATEWAY_ENABLED", strconv.FormatBool(appEnv != "production")))
 
 	return &Config{
 		Database: DatabaseConfig{
@@ -66,18 +188,142 @@ func Load() *Config {
 		},
 		App: AppConfig{
 			Port: getEnv("APP_PORT", "8080"),
-			Env:  getEnv("APP_ENV", "development"),
+			Env:  appEnv,
 		},
 		JWT: JWTConfig{
-			Secret:             getEnv("JWT_SECRET", "your-secret-key"),
+			Secret:             getEnv("JWT_SECRET", DefaultJWTSecret),
 			ExpireHours:        expireHours,
 			RefreshExpireHours: refreshExpireHours,
+			KeysDir:            getEnv("JWT_KEYS_DIR", ""),
+			SigningKeyID:       getEnv("JWT_SIGNING_KEY_ID", ""),
+			KeysReloadMinutes:  keysReloadMinutes,
+			AcceptLegacyHS256:  acceptLegacyHS256,
+			AcceptUntypedUntil: getEnv("JWT_ACCEPT_UNTYPED_UNTIL", ""),
 		},
 		Upload: UploadConfig{
 			Path:        getEnv("UPLOAD_PATH", "./uploads"),
 			MaxFileSize: maxFileSize,
 		},
+		Mail: MailConfig{
+			Driver:       getEnv("MAIL_DRIVER", "log"),
+			From:         getEnv("MAIL_FROM", "no-reply@go-commerce.local"),
+			SMTPHost:     getEnv("SMTP_HOST", "localhost"),
+			SMTPPort:     getEnv("SMTP_PORT", "587"),
+			SMTPUsername: getEnv("SMTP_USERNAME", ""),
+			SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+			OutputDir:    getEnv("MAIL_OUTPUT_DIR", ""),
+		},
+		Auth: AuthConfig{
+			EmailVerificationURL:            getEnv("EMAIL_VERIFICATION_URL", "http://localhost:8080/verify-email"),
+			EmailVerificationExpireHours:    verificationExpireHours,
+			EmailVerificationResendSeconds:  verificationResendSeconds,
+			RequireVerifiedEmailForCheckout: requireVerifiedCheckout,
+			RequireVerifiedEmailForStore:    requireVerifiedStore,
+			PasswordResetURL:                getEnv("PASSWORD_RESET_URL", "http://localhost:8080/reset-password"),
+			PasswordResetExpireMinutes:      passwordResetExpireMinutes,
+			LoginMaxAccountAttempts:         loginMaxAccountAttempts,
+			LoginMaxIPAttempts:              loginMaxIPAttempts,
+			LoginAttemptWindowMinutes:       loginAttemptWindowMinutes,
+			LoginLockoutBaseSeconds:         loginLockoutBaseSeconds,
+			LoginLockoutMaxSeconds:          loginLockoutMaxSeconds,
+			TwoFactorIssuer:                 getEnv("TWO_FACTOR_ISSUER", "Go Commerce"),
+			TwoFactorChallengeMinutes:       twoFactorChallengeMinutes,
+			TwoFactorRecoveryCodes:          twoFactorRecoveryCodes,
+		},
+		Checkout: CheckoutConfig{
+			StockHoldMinutes:            stockHoldMinutes,
+			StockReleaseIntervalSeconds: stockReleaseIntervalSeconds,
+		},
+		Payment: PaymentConfig{
+			IntentExpireMinutes:        intentExpireMinutes,
+			ExpirySweepIntervalSeconds: expirySweepIntervalSeconds,
+			GatewaySecrets:             parseGatewaySecrets(getEnv("PAYMENT_GATEWAY_SECRETS", "")),
+			CallbackToleranceSeconds:   callbackToleranceSeconds,
+			CallbackBaseURL:            getEnv("PAYMENT_CALLBACK_BASE_URL", "http://localhost:8080/api/v1/callbacks/payments"),
+			MockGatewayEnabled:         mockGatewayEnabled,
+			MockGatewayAddr:            getEnv("PAYMENT_MOCK_GATEWAY_ADDR", "127.0.0.1:8090"),
+		},
+		Shipping: ShippingConfig{
+			TrackingFile:                getEnv("SHIPPING_TRACKING_FILE", "./data/courier_tracking.json"),
+			TrackingPollIntervalSeconds: trackingPollIntervalSeconds,
+		},
+		Order: OrderConfig{
+			AutoConfirmDays:            autoConfirmDays,
+			AutoConfirmIntervalSeconds: autoConfirmIntervalSeconds,
+		},
+		Invoice: InvoiceConfig{
+			InvoiceFormat:  getEnv("INVOICE_FORMAT", "INV/{YYYYMMDD}/{store}/{seq}"),
+			CheckoutFormat: getEnv("CHECKOUT_CODE_FORMAT", "CHK/{YYYYMMDD}/{seq}"),
+		},
+		Search: SearchConfig{
+			Backend:                       getEnv("SEARCH_BACKEND", "mysql"),
+			SuggestRebuildIntervalMinutes: suggestRebuildIntervalMinutes,
+		},
+		Paging: PagingConfig{
+			CursorSecret: getEnv("PAGINATION_CURSOR_SECRET", getEnv("JWT_SECRET", DefaultJWTSecret)),
+		},
+	}
+}
+
+// Validate rejects settings that are unsafe for the current environment
+func (c *Config) Validate() error {
+	// Codes are only unique with the day and a sequence number in them
+	for _, code := range []struct{ key, format string }{
+		{"INVOICE_FORMAT", c.Invoice.InvoiceFormat},
+		{"CHECKOUT_CODE_FORMAT", c.Invoice.CheckoutFormat},
+	} {
+		if code.format != "" && (!strings.Contains(code.format, "{YYYYMMDD}") || !strings.Contains(code.format, "{seq}")) {
+			return errors.New(code.key + " must contain {YYYYMMDD} and {seq}")
+		}
+	}
+
+	if c.JWT.AcceptUntypedUntil != "" {
+		if _, err := time.Parse(time.DateOnly, c.JWT.AcceptUntypedUntil); err != nil {
+			return errors.New("JWT_ACCEPT_UNTYPED_UNTIL must be a date like 2026-12-31")
+		}
+	}
+
+	switch c.Search.Backend {
+	case "", "mysql", "memory":
+	default:
+		return errors.New("SEARCH_BACKEND must be mysql or memory")
+	}
+
+	if c.App.Env != "production" {
+		return nil
+	}
+
+	// The shared secret signs tokens without a key directory and verifies
+	// legacy HS256 tokens with one
+	if c.JWT.KeysDir == "" || c.JWT.AcceptLegacyHS256 {
+		if c.JWT.Secret == "" || c.JWT.Secret == DefaultJWTSecret {
+			return errors.New("JWT_SECRET must be set to a non-default value when APP_ENV=production")
+		}
+	}
+
+	// Cursors signed with a public secret can be forged
+	if c.Paging.CursorSecret == "" || c.Paging.CursorSecret == DefaultJWTSecret {
+		return errors.New("PAGINATION_CURSOR_SECRET or JWT_SECRET must be set to a non-default value when APP_ENV=production")
+	}
+
+	// The mock gateway settles payments for anyone who asks
+	if c.Payment.MockGatewayEnabled {
+		return errors.New("PAYMENT_MOCK_GATEWAY_ENABLED must be false when APP_ENV=production")
+	}
+	return nil
+}
+
+// parseGatewaySecrets reads "gateway:secret" pairs separated by commas
+func parseGatewaySecrets(value string) map[string]string {
+	secrets := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		name, secret, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok || name == "" || secret == "" {
+			continue
+		}
+		secrets[name] = secret
 	}
+	return secrets
 }
 
 func getEnv(key, defaultValue string) string {
@@ -13,6 +13,11 @@ func TestLoad_WithDefaults(t *testing.T) {
 		"DB_HOST", "DB_PORT", "DB_USER", "DB_PASSWORD", "DB_NAME",
 		"APP_PORT", "APP_ENV", "JWT_SECRET", "JWT_EXPIRE_HOURS",
 		"JWT_REFRESH_EXPIRE_HOURS", "UPLOAD_PATH", "MAX_FILE_SIZE",
+		"MAIL_DRIVER", "EMAIL_VERIFICATION_EXPIRE_HOURS", "EMAIL_VERIFICATION_RESEND_SECONDS",
+		"REQUIRE_VERIFIED_EMAIL_FOR_CHECKOUT", "REQUIRE_VERIFIED_EMAIL_FOR_STORE",
+		"PASSWORD_RESET_EXPIRE_MINUTES", "LOGIN_MAX_ACCOUNT_ATTEMPTS", "LOGIN_MAX_IP_ATTEMPTS",
+		"TWO_FACTOR_ISSUER", "TWO_FACTOR_CHALLENGE_MINUTES", "TWO_FACTOR_RECOVERY_CODES",
+		"JWT_KEYS_DIR", "JWT_SIGNING_KEY_ID", "JWT_KEYS_RELOAD_MINUTES", "JWT_ACCEPT_LEGACY_HS256",
 	}
 	
 	// Store original values
@@ -49,9 +54,24 @@ func TestLoad_WithDefaults(t *testing.T) {
 	assert.Equal(t, "your-secret-key", config.JWT.Secret)
 	assert.Equal(t, 24, config.JWT.ExpireHours)
 	assert.Equal(t, 168, config.JWT.RefreshExpireHours)
+	assert.Equal(t, "", config.JWT.KeysDir)
+	assert.Equal(t, 5, config.JWT.KeysReloadMinutes)
+	assert.False(t, config.JWT.AcceptLegacyHS256)
 
 	assert.Equal(t, "./uploads", config.Upload.Path)
 	assert.Equal(t, int64(5242880), config.Upload.MaxFileSize)
+
+	assert.Equal(t, "log", config.Mail.Driver)
+	assert.Equal(t, 24, config.Auth.EmailVerificationExpireHours)
+	assert.Equal(t, 60, config.Auth.EmailVerificationResendSeconds)
+	assert.False(t, config.Auth.RequireVerifiedEmailForCheckout)
+	assert.False(t, config.Auth.RequireVerifiedEmailForStore)
+	assert.Equal(t, 30, config.Auth.PasswordResetExpireMinutes)
+	assert.Equal(t, 5, config.Auth.LoginMaxAccountAttempts)
+	assert.Equal(t, 20, config.Auth.LoginMaxIPAttempts)
+	assert.Equal(t, "Go Commerce", config.Auth.TwoFactorIssuer)
+	assert.Equal(t, 5, config.Auth.TwoFactorChallengeMinutes)
+	assert.Equal(t, 10, config.Auth.TwoFactorRecoveryCodes)
 }
 
 func TestLoad_WithEnvironmentVariables(t *testing.T) {
@@ -102,6 +122,7 @@ func TestLoad_WithEnvironmentVariables(t *testing.T) {
 	assert.Equal(t, "production", config.App.Env)
 
 	assert.Equal(t, "test-secret", config.JWT.Secret)
+	assert.Equal(t, "test-secret", config.Paging.CursorSecret)
 	assert.Equal(t, 48, config.JWT.ExpireHours)
 	assert.Equal(t, 336, config.JWT.RefreshExpireHours)
 
@@ -197,4 +218,90 @@ func TestLoad_InvalidIntegerValues(t *testing.T) {
 	assert.Equal(t, 0, config.JWT.ExpireHours)        // strconv.Atoi returns 0 on error
 	assert.Equal(t, 0, config.JWT.RefreshExpireHours) // strconv.Atoi returns 0 on error
 	assert.Equal(t, int64(0), config.Upload.MaxFileSize) // strconv.ParseInt returns 0 on error
-}
\ No newline at end of file
+}
+
+func TestConfig_Validate_RefusesDefaultSecretInProduction(t *testing.T) {
+	config := &Config{
+		App: AppConfig{Env: "production"},
+		JWT: JWTConfig{Secret: DefaultJWTSecret},
+	}
+
+	assert.Error(t, config.Validate())
+
+	config.App.Env = "development"
+	assert.NoError(t, config.Validate())
+}
+
+func TestConfig_Validate_KeyDirWithoutLegacySecret(t *testing.T) {
+	config := &Config{
+		App:    AppConfig{Env: "production"},
+		JWT:    JWTConfig{Secret: DefaultJWTSecret, KeysDir: "/etc/go-commerce/keys"},
+		Paging: PagingConfig{CursorSecret: "cursor-secret"},
+	}
+
+	assert.NoError(t, config.Validate())
+
+	// Accepting legacy HS256 tokens brings the shared secret back into play
+	config.JWT.AcceptLegacyHS256 = true
+	assert.Error(t, config.Validate())
+}
+
+func TestConfig_Validate_RefusesDefaultCursorSecretInProduction(t *testing.T) {
+	config := &Config{
+		App:    AppConfig{Env: "production"},
+		JWT:    JWTConfig{KeysDir: "/etc/go-commerce/keys"},
+		Paging: PagingConfig{CursorSecret: DefaultJWTSecret},
+	}
+
+	assert.Error(t, config.Validate())
+
+	config.Paging.CursorSecret = "cursor-secret"
+	assert.NoError(t, config.Validate())
+}
+
+func TestConfig_Validate_CodeFormatsNeedDayAndSequence(t *testing.T) {
+	config := &Config{
+		App:     AppConfig{Env: "development"},
+		Invoice: InvoiceConfig{InvoiceFormat: "INV/{YYYYMMDD}/{store}/{seq}", CheckoutFormat: "CHK/{YYYYMMDD}/{seq}"},
+	}
+
+	assert.NoError(t, config.Validate())
+
+	// Without the sequence every code of a day would be the same
+	config.Invoice.InvoiceFormat = "INV/{YYYYMMDD}/{store}"
+	assert.Error(t, config.Validate())
+}
+
+func TestConfig_Validate_SearchBackend(t *testing.T) {
+	config := &Config{
+		App:    AppConfig{Env: "development"},
+		Search: SearchConfig{Backend: "memory"},
+	}
+
+	assert.NoError(t, config.Validate())
+
+	config.Search.Backend = "elasticsearch"
+	assert.Error(t, config.Validate())
+}
+
+func TestConfig_Validate_AcceptUntypedUntilIsADate(t *testing.T) {
+	config := &Config{
+		App: AppConfig{Env: "development"},
+		JWT: JWTConfig{AcceptUntypedUntil: "2026-11-30"},
+	}
+
+	assert.NoError(t, config.Validate())
+
+	config.JWT.AcceptUntypedUntil = "next month"
+	assert.Error(t, config.Validate())
+}
+
+func TestParseGatewaySecrets(t *testing.T) {
+	secrets := parseGatewaySecrets("midtrans:abc123, xendit:def:456,broken,:nosecret,empty:")
+
+	assert.Equal(t, map[string]string{
+		"midtrans": "abc123",
+		"xendit":   "def:456",
+	}, secrets)
+	assert.Empty(t, parseGatewaySecrets(""))
+}
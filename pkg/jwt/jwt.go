@@ -2,22 +2,71 @@ package jwt
 
 import (
 	"errors"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	TokenTypeAccess             = "access"
+	TokenTypeRefresh            = "refresh"
+	TokenTypeEmailVerification  = "email_verification"
+	TokenTypeTwoFactorChallenge = "2fa_challenge"
 )
 
 type Claims struct {
-	UserID  uint64 `json:"user_id"`
-	Email   string `json:"email"`
-	IsAdmin bool   `json:"is_admin"`
+	UserID      uint64   `json:"user_id"`
+	Email       string   `json:"email"`
+	IsAdmin     bool     `json:"is_admin"`
+	Roles       []string `json:"roles,omitempty"`
+	Permissions []string `json:"perms,omitempty"`
+	TokenType   string   `json:"typ,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// RefreshClaims are carried by refresh tokens. FamilyID groups every token
+// produced by rotating the same login session.
+type RefreshClaims struct {
+	UserID    uint64 `json:"user_id"`
+	FamilyID  string `json:"fid"`
+	TokenType string `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+// EmailVerificationClaims are carried by email verification links. The email
+// is embedded so a link stops working once the address changes.
+type EmailVerificationClaims struct {
+	UserID    uint64 `json:"user_id"`
+	Email     string `json:"email"`
+	TokenType string `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+// TwoFactorChallengeClaims are carried by the short-lived token a password
+// login returns when the account has two-factor authentication enabled
+type TwoFactorChallengeClaims struct {
+	UserID    uint64 `json:"user_id"`
+	TokenType string `json:"typ"`
 	jwt.RegisteredClaims
 }
 
 type JWTManager struct {
-	secretKey             string
-	accessTokenDuration   time.Duration
-	refreshTokenDuration  time.Duration
+	secretKey            string
+	accessTokenDuration  time.Duration
+	refreshTokenDuration time.Duration
+
+	// Asymmetric signing; keys is nil when tokens are signed with secretKey
+	keyDir       string
+	signingKeyID string
+	mu           sync.RWMutex
+	keys         *KeySet
+
+	// Tokens issued before token types existed carry no typ; they are
+	// accepted as access tokens until this time, and never when it is zero
+	acceptUntypedUntil time.Time
 }
 
 func NewJWTManager(secretKey string, accessHours, refreshHours int) *JWTManager {
@@ -28,49 +77,270 @@ func NewJWTManager(secretKey string, accessHours, refreshHours int) *JWTManager
 	}
 }
 
-func (j *JWTManager) GenerateAccessToken(userID uint64, email string, isAdmin bool) (string, error) {
+// NewKeyDirJWTManager signs tokens with the RSA or Ed25519 keys found in
+// keyDir (see LoadKeySet). When legacySecret is not empty, HS256 tokens signed
+// with it keep verifying so sessions survive the switch to asymmetric keys.
+func NewKeyDirJWTManager(keyDir, signingKeyID, legacySecret string, accessHours, refreshHours int) (*JWTManager, error) {
+	keys, err := LoadKeySet(keyDir, signingKeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	manager := NewJWTManager(legacySecret, accessHours, refreshHours)
+	manager.keyDir = keyDir
+	manager.signingKeyID = signingKeyID
+	manager.keys = keys
+	return manager, nil
+}
+
+// AcceptUntypedTokensUntil keeps tokens without a typ claim working as
+// access tokens until the given time, so sessions from before token types
+// existed are not all cut off at once
+func (j *JWTManager) AcceptUntypedTokensUntil(until time.Time) {
+	j.acceptUntypedUntil = until
+}
+
+// ReloadKeys re-reads the key directory so keys can be rotated without a
+// restart. Old keys keep verifying for as long as their file stays in the
+// directory. On error the current keys stay in use.
+func (j *JWTManager) ReloadKeys() error {
+	if j.keyDir == "" {
+		return nil
+	}
+
+	keys, err := LoadKeySet(j.keyDir, j.signingKeyID)
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	j.keys = keys
+	j.mu.Unlock()
+	return nil
+}
+
+// JWKS returns the public verification keys. It is empty when tokens are
+// signed with a shared secret.
+func (j *JWTManager) JWKS() JSONWebKeySet {
+	keys := j.keySet()
+	if keys == nil {
+		return JSONWebKeySet{Keys: []JSONWebKey{}}
+	}
+	return keys.JWKS()
+}
+
+// GenerateAccessToken issues an access token carrying the user's role names and
+// the permissions those roles grant
+func (j *JWTManager) GenerateAccessToken(userID uint64, email string, isAdmin bool, roles, permissions []string) (string, error) {
 	claims := Claims{
-		UserID:  userID,
-		Email:   email,
-		IsAdmin: isAdmin,
+		UserID:      userID,
+		Email:       email,
+		IsAdmin:     isAdmin,
+		Roles:       roles,
+		Permissions: permissions,
+		TokenType:   TokenTypeAccess,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.accessTokenDuration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(j.secretKey))
+	return j.sign(claims)
 }
 
-func (j *JWTManager) GenerateRefreshToken(userID uint64) (string, error) {
-	claims := jwt.RegisteredClaims{
-		Subject:   string(rune(userID)),
-		ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.refreshTokenDuration)),
-		IssuedAt:  jwt.NewNumericDate(time.Now()),
-		NotBefore: jwt.NewNumericDate(time.Now()),
+// GenerateRefreshToken issues a refresh token identified by tokenID that
+// belongs to the given token family. The returned claims carry the expiry so
+// callers can persist it alongside the token ID.
+func (j *JWTManager) GenerateRefreshToken(userID uint64, tokenID, familyID string) (string, *RefreshClaims, error) {
+	claims := &RefreshClaims{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenType: TokenTypeRefresh,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
+			Subject:   strconv.FormatUint(userID, 10),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.refreshTokenDuration)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(j.secretKey))
+	signed, err := j.sign(claims)
+	if err != nil {
+		return "", nil, err
+	}
+	return signed, claims, nil
+}
+
+// GenerateEmailVerificationToken issues a signed token proving ownership of
+// email, valid for ttl
+func (j *JWTManager) GenerateEmailVerificationToken(userID uint64, email string, ttl time.Duration) (string, error) {
+	claims := EmailVerificationClaims{
+		UserID:    userID,
+		Email:     email,
+		TokenType: TokenTypeEmailVerification,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   strconv.FormatUint(userID, 10),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	return j.sign(claims)
+}
+
+// ValidateEmailVerificationToken verifies signature and expiry of an email
+// verification token and returns its claims
+func (j *JWTManager) ValidateEmailVerificationToken(tokenString string) (*EmailVerificationClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &EmailVerificationClaims{}, j.keyFunc)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*EmailVerificationClaims)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+	if claims.TokenType != TokenTypeEmailVerification || claims.Email == "" {
+		return nil, errors.New("invalid token type")
+	}
+
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil || userID != claims.UserID {
+		return nil, errors.New("invalid token subject")
+	}
+
+	return claims, nil
+}
+
+// GenerateTwoFactorChallengeToken issues a token proving the user passed the
+// password step of a login, valid for ttl
+func (j *JWTManager) GenerateTwoFactorChallengeToken(userID uint64, ttl time.Duration) (string, error) {
+	claims := TwoFactorChallengeClaims{
+		UserID:    userID,
+		TokenType: TokenTypeTwoFactorChallenge,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   strconv.FormatUint(userID, 10),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	return j.sign(claims)
+}
+
+// ValidateTwoFactorChallengeToken verifies signature and expiry of a 2FA
+// challenge token and returns its claims
+func (j *JWTManager) ValidateTwoFactorChallengeToken(tokenString string) (*TwoFactorChallengeClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &TwoFactorChallengeClaims{}, j.keyFunc)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*TwoFactorChallengeClaims)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+	if claims.TokenType != TokenTypeTwoFactorChallenge {
+		return nil, errors.New("invalid token type")
+	}
+
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil || userID != claims.UserID {
+		return nil, errors.New("invalid token subject")
+	}
+
+	return claims, nil
 }
 
 func (j *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
-		}
-		return []byte(j.secretKey), nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, j.keyFunc)
 
 	if err != nil {
 		return nil, err
 	}
 
 	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
+		// Only access tokens authenticate requests
+		if claims.TokenType != TokenTypeAccess && (claims.TokenType != "" || !time.Now().Before(j.acceptUntypedUntil)) {
+			return nil, errors.New("invalid token type")
+		}
 		return claims, nil
 	}
 
 	return nil, errors.New("invalid token")
-}
\ No newline at end of file
+}
+
+// ValidateRefreshToken verifies signature and expiry of a refresh token and
+// returns its claims. Access tokens are rejected.
+func (j *JWTManager) ValidateRefreshToken(tokenString string) (*RefreshClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &RefreshClaims{}, j.keyFunc)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*RefreshClaims)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+	if claims.TokenType != TokenTypeRefresh || claims.ID == "" || claims.FamilyID == "" {
+		return nil, errors.New("invalid token type")
+	}
+
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil || userID != claims.UserID {
+		return nil, errors.New("invalid token subject")
+	}
+
+	return claims, nil
+}
+
+// AccessTokenDuration returns how long issued access tokens stay valid.
+func (j *JWTManager) AccessTokenDuration() time.Duration {
+	return j.accessTokenDuration
+}
+
+// RefreshTokenDuration returns how long issued refresh tokens stay valid.
+func (j *JWTManager) RefreshTokenDuration() time.Duration {
+	return j.refreshTokenDuration
+}
+
+func (j *JWTManager) keySet() *KeySet {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.keys
+}
+
+// sign uses the active asymmetric key when one is configured, recording its
+// kid in the header, and falls back to HS256 otherwise
+func (j *JWTManager) sign(claims jwt.Claims) (string, error) {
+	keys := j.keySet()
+	if keys == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(j.secretKey))
+	}
+
+	token := jwt.NewWithClaims(keys.signing.Method, claims)
+	token.Header["kid"] = keys.signing.ID
+	return token.SignedString(keys.signing.Private)
+}
+
+func (j *JWTManager) keyFunc(token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+		if j.secretKey == "" {
+			return nil, errors.New("unexpected signing method")
+		}
+		return []byte(j.secretKey), nil
+	}
+
+	keys := j.keySet()
+	if keys == nil {
+		return nil, errors.New("unexpected signing method")
+	}
+	return keys.verificationKey(token)
+}
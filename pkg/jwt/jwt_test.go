@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -16,7 +17,7 @@ func TestJWTManager_GenerateAccessToken_Success(t *testing.T) {
 	isAdmin := false
 
 	// Execute
-	token, err := jwtManager.GenerateAccessToken(userID, email, isAdmin)
+	token, err := jwtManager.GenerateAccessToken(userID, email, isAdmin, nil, nil)
 
 	// Assert
 	assert.NoError(t, err)
@@ -30,11 +31,63 @@ func TestJWTManager_GenerateRefreshToken_Success(t *testing.T) {
 	userID := uint64(1)
 
 	// Execute
-	token, err := jwtManager.GenerateRefreshToken(userID)
+	token, claims, err := jwtManager.GenerateRefreshToken(userID, "token-1", "family-1")
 
 	// Assert
 	assert.NoError(t, err)
 	assert.NotEmpty(t, token)
+	assert.Equal(t, "1", claims.Subject)
+	assert.Equal(t, "token-1", claims.ID)
+	assert.Equal(t, "family-1", claims.FamilyID)
+}
+
+func TestJWTManager_ValidateRefreshToken_Success(t *testing.T) {
+	// Setup
+	jwtManager := NewJWTManager("test-secret-key", 24, 168)
+
+	userID := uint64(300)
+	token, _, err := jwtManager.GenerateRefreshToken(userID, "token-1", "family-1")
+	assert.NoError(t, err)
+
+	// Execute
+	claims, err := jwtManager.ValidateRefreshToken(token)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, userID, claims.UserID)
+	assert.Equal(t, "300", claims.Subject)
+	assert.Equal(t, "token-1", claims.ID)
+	assert.Equal(t, "family-1", claims.FamilyID)
+}
+
+func TestJWTManager_ValidateRefreshToken_RejectsAccessToken(t *testing.T) {
+	// Setup
+	jwtManager := NewJWTManager("test-secret-key", 24, 168)
+
+	token, err := jwtManager.GenerateAccessToken(1, "test@example.com", false, nil, nil)
+	assert.NoError(t, err)
+
+	// Execute
+	claims, err := jwtManager.ValidateRefreshToken(token)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, claims)
+}
+
+func TestJWTManager_ValidateToken_RejectsRefreshToken(t *testing.T) {
+	// Setup
+	jwtManager := NewJWTManager("test-secret-key", 24, 168)
+
+	token, _, err := jwtManager.GenerateRefreshToken(1, "token-1", "family-1")
+	assert.NoError(t, err)
+
+	// Execute
+	claims, err := jwtManager.ValidateToken(token)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, claims)
 }
 
 func TestJWTManager_ValidateToken_Success(t *testing.T) {
@@ -46,7 +99,7 @@ func TestJWTManager_ValidateToken_Success(t *testing.T) {
 	isAdmin := true
 
 	// Generate token
-	token, err := jwtManager.GenerateAccessToken(userID, email, isAdmin)
+	token, err := jwtManager.GenerateAccessToken(userID, email, isAdmin, nil, nil)
 	assert.NoError(t, err)
 
 	// Execute
@@ -58,6 +111,34 @@ func TestJWTManager_ValidateToken_Success(t *testing.T) {
 	assert.Equal(t, userID, claims.UserID)
 	assert.Equal(t, email, claims.Email)
 	assert.Equal(t, isAdmin, claims.IsAdmin)
+	assert.NotEmpty(t, claims.ID)
+}
+
+func TestJWTManager_ValidateToken_UntypedTokenOnlyBeforeCutoff(t *testing.T) {
+	// Setup - a token from before access tokens carried a typ
+	jwtManager := NewJWTManager("test-secret-key", 24, 168)
+	untyped, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{
+		UserID: 1,
+		Email:  "test@example.com",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}).SignedString([]byte("test-secret-key"))
+	assert.NoError(t, err)
+
+	// Execute
+	_, strictErr := jwtManager.ValidateToken(untyped)
+	jwtManager.AcceptUntypedTokensUntil(time.Now().Add(24 * time.Hour))
+	claims, migratingErr := jwtManager.ValidateToken(untyped)
+	jwtManager.AcceptUntypedTokensUntil(time.Now().Add(-time.Minute))
+	_, expiredErr := jwtManager.ValidateToken(untyped)
+
+	// Assert
+	assert.EqualError(t, strictErr, "invalid token type")
+	assert.NoError(t, migratingErr)
+	assert.Equal(t, uint64(1), claims.UserID)
+	assert.EqualError(t, expiredErr, "invalid token type")
 }
 
 func TestJWTManager_ValidateToken_InvalidToken(t *testing.T) {
@@ -83,7 +164,7 @@ func TestJWTManager_ValidateToken_ExpiredToken(t *testing.T) {
 	isAdmin := false
 
 	// Generate token (will be expired)
-	token, err := jwtManager.GenerateAccessToken(userID, email, isAdmin)
+	token, err := jwtManager.GenerateAccessToken(userID, email, isAdmin, nil, nil)
 	assert.NoError(t, err)
 
 	// Wait a moment to ensure expiration
@@ -107,7 +188,7 @@ func TestJWTManager_ValidateToken_WrongSecret(t *testing.T) {
 	isAdmin := false
 
 	// Generate token with first manager
-	token, err := jwtManager1.GenerateAccessToken(userID, email, isAdmin)
+	token, err := jwtManager1.GenerateAccessToken(userID, email, isAdmin, nil, nil)
 	assert.NoError(t, err)
 
 	// Try to validate with second manager (different secret)
@@ -116,4 +197,66 @@ func TestJWTManager_ValidateToken_WrongSecret(t *testing.T) {
 	// Assert
 	assert.Error(t, err)
 	assert.Nil(t, claims)
-}
\ No newline at end of file
+}
+
+func TestJWTManager_ValidateEmailVerificationToken_Success(t *testing.T) {
+	// Setup
+	jwtManager := NewJWTManager("test-secret", 24, 168)
+
+	token, err := jwtManager.GenerateEmailVerificationToken(1, "test@example.com", time.Hour)
+	assert.NoError(t, err)
+
+	// Execute
+	claims, err := jwtManager.ValidateEmailVerificationToken(token)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), claims.UserID)
+	assert.Equal(t, "test@example.com", claims.Email)
+}
+
+func TestJWTManager_EmailVerificationToken_NotAcceptedAsAccessToken(t *testing.T) {
+	// Setup
+	jwtManager := NewJWTManager("test-secret", 24, 168)
+
+	token, err := jwtManager.GenerateEmailVerificationToken(1, "test@example.com", time.Hour)
+	assert.NoError(t, err)
+
+	// Execute
+	claims, err := jwtManager.ValidateToken(token)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, claims)
+}
+
+func TestJWTManager_ValidateEmailVerificationToken_Expired(t *testing.T) {
+	// Setup
+	jwtManager := NewJWTManager("test-secret", 24, 168)
+
+	token, err := jwtManager.GenerateEmailVerificationToken(1, "test@example.com", -time.Minute)
+	assert.NoError(t, err)
+
+	// Execute
+	claims, err := jwtManager.ValidateEmailVerificationToken(token)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, claims)
+}
+
+func TestJWTManager_ValidateToken_CarriesRolesAndPermissions(t *testing.T) {
+	// Setup
+	jwtManager := NewJWTManager("test-secret", 24, 168)
+
+	token, err := jwtManager.GenerateAccessToken(1, "test@example.com", false, []string{"finance_admin"}, []string{"transactions:refund"})
+	assert.NoError(t, err)
+
+	// Execute
+	claims, err := jwtManager.ValidateToken(token)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"finance_admin"}, claims.Roles)
+	assert.Equal(t, []string{"transactions:refund"}, claims.Permissions)
+}
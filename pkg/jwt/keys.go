@@ -0,0 +1,206 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// SigningKey is an asymmetric key identified by the kid header of the tokens
+// it signs. Private is nil for verification-only keys.
+type SigningKey struct {
+	ID      string
+	Method  jwt.SigningMethod
+	Private crypto.Signer
+	Public  crypto.PublicKey
+}
+
+// KeySet holds the key used to sign new tokens plus every key that may still
+// verify tokens signed earlier
+type KeySet struct {
+	signing *SigningKey
+	keys    map[string]*SigningKey
+}
+
+// JSONWebKey is the public part of a verification key in JWK format
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// LoadKeySet reads every PEM file in dir. The file name without its
+// extension becomes the kid. Private keys (PKCS#8, or PKCS#1 for RSA) can sign
+// and verify; public keys (*.pub.pem) only verify, which lets retired keys
+// keep validating tokens after their private half is destroyed.
+//
+// New tokens are signed with signingKeyID, or with the private key whose kid
+// sorts last when signingKeyID is empty, so date-named keys rotate naturally.
+func LoadKeySet(dir, signingKeyID string) (*KeySet, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read key directory: %w", err)
+	}
+
+	set := &KeySet{keys: make(map[string]*SigningKey)}
+	var signable []string
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".pem") {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("read key %s: %w", entry.Name(), err)
+		}
+
+		kid := strings.TrimSuffix(strings.TrimSuffix(entry.Name(), ".pem"), ".pub")
+		key, err := parseKey(kid, data)
+		if err != nil {
+			return nil, fmt.Errorf("parse key %s: %w", entry.Name(), err)
+		}
+
+		// A private key supersedes a public key with the same kid
+		if existing, ok := set.keys[kid]; ok && existing.Private != nil {
+			continue
+		}
+		set.keys[kid] = key
+		if key.Private != nil {
+			signable = append(signable, kid)
+		}
+	}
+
+	if signingKeyID == "" {
+		if len(signable) == 0 {
+			return nil, errors.New("no private key found in " + dir)
+		}
+		sort.Strings(signable)
+		signingKeyID = signable[len(signable)-1]
+	}
+
+	signing, ok := set.keys[signingKeyID]
+	if !ok || signing.Private == nil {
+		return nil, fmt.Errorf("signing key %q not found in %s", signingKeyID, dir)
+	}
+	set.signing = signing
+
+	return set, nil
+}
+
+// SigningKeyID returns the kid new tokens are signed with
+func (s *KeySet) SigningKeyID() string {
+	return s.signing.ID
+}
+
+// JWKS returns the public keys of every verification key
+func (s *KeySet) JWKS() JSONWebKeySet {
+	kids := make([]string, 0, len(s.keys))
+	for kid := range s.keys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	set := JSONWebKeySet{Keys: make([]JSONWebKey, 0, len(kids))}
+	for _, kid := range kids {
+		key := s.keys[kid]
+		jwk := JSONWebKey{KeyID: key.ID, Use: "sig", Algorithm: key.Method.Alg()}
+
+		switch pub := key.Public.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+func (s *KeySet) verificationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, errors.New("unknown signing key")
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, errors.New("unexpected signing method")
+	}
+	return key.Public, nil
+}
+
+func parseKey(kid string, data []byte) (*SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return privateKey(kid, parsed)
+	case "RSA PRIVATE KEY":
+		parsed, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return privateKey(kid, parsed)
+	case "PUBLIC KEY":
+		parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return publicKey(kid, parsed)
+	}
+
+	return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+}
+
+func privateKey(kid string, key interface{}) (*SigningKey, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return &SigningKey{ID: kid, Method: jwt.SigningMethodRS256, Private: k, Public: &k.PublicKey}, nil
+	case ed25519.PrivateKey:
+		return &SigningKey{ID: kid, Method: jwt.SigningMethodEdDSA, Private: k, Public: k.Public()}, nil
+	}
+	return nil, errors.New("only RSA and Ed25519 keys are supported")
+}
+
+func publicKey(kid string, key interface{}) (*SigningKey, error) {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return &SigningKey{ID: kid, Method: jwt.SigningMethodRS256, Public: k}, nil
+	case ed25519.PublicKey:
+		return &SigningKey{ID: kid, Method: jwt.SigningMethodEdDSA, Public: k}, nil
+	}
+	return nil, errors.New("only RSA and Ed25519 keys are supported")
+}
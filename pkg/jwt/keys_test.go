@@ -0,0 +1,147 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writePrivateKey(t *testing.T, dir, name string, key interface{}) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0600))
+}
+
+func writePublicKey(t *testing.T, dir, name string, key interface{}) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	require.NoError(t, err)
+	data := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0644))
+}
+
+func tokenKeyID(t *testing.T, tokenString string) string {
+	token, _, err := jwt.NewParser().ParseUnverified(tokenString, &Claims{})
+	require.NoError(t, err)
+	kid, _ := token.Header["kid"].(string)
+	return kid
+}
+
+func TestKeyDirJWTManager_SignsWithNewestKeyAndKid(t *testing.T) {
+	// Setup
+	dir := t.TempDir()
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	writePrivateKey(t, dir, "2026-01-01.pem", rsaKey)
+	writePrivateKey(t, dir, "2026-02-01.pem", edKey)
+
+	// Execute
+	jwtManager, err := NewKeyDirJWTManager(dir, "", "", 24, 168)
+	require.NoError(t, err)
+	token, err := jwtManager.GenerateAccessToken(1, "test@example.com", false, nil, nil)
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, "2026-02-01", tokenKeyID(t, token))
+	claims, err := jwtManager.ValidateToken(token)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), claims.UserID)
+}
+
+func TestKeyDirJWTManager_RotationKeepsOldKeysVerifying(t *testing.T) {
+	// Setup
+	dir := t.TempDir()
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	writePrivateKey(t, dir, "2026-01-01.pem", oldKey)
+
+	jwtManager, err := NewKeyDirJWTManager(dir, "", "", 24, 168)
+	require.NoError(t, err)
+	oldToken, err := jwtManager.GenerateAccessToken(1, "test@example.com", false, nil, nil)
+	require.NoError(t, err)
+
+	// Rotate: new signing key, old key kept as public-only
+	_, newKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	writePrivateKey(t, dir, "2026-02-01.pem", newKey)
+	require.NoError(t, os.Remove(filepath.Join(dir, "2026-01-01.pem")))
+	writePublicKey(t, dir, "2026-01-01.pub.pem", &oldKey.PublicKey)
+
+	// Execute
+	err = jwtManager.ReloadKeys()
+	require.NoError(t, err)
+	newToken, err := jwtManager.GenerateAccessToken(1, "test@example.com", false, nil, nil)
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, "2026-02-01", tokenKeyID(t, newToken))
+	_, err = jwtManager.ValidateToken(newToken)
+	assert.NoError(t, err)
+	_, err = jwtManager.ValidateToken(oldToken)
+	assert.NoError(t, err)
+
+	jwks := jwtManager.JWKS()
+	assert.Len(t, jwks.Keys, 2)
+	assert.Equal(t, "2026-01-01", jwks.Keys[0].KeyID)
+	assert.Equal(t, "RSA", jwks.Keys[0].KeyType)
+	assert.Equal(t, "RS256", jwks.Keys[0].Algorithm)
+	assert.Equal(t, "AQAB", jwks.Keys[0].E)
+	assert.Equal(t, "OKP", jwks.Keys[1].KeyType)
+	assert.Equal(t, "EdDSA", jwks.Keys[1].Algorithm)
+	assert.NotEmpty(t, jwks.Keys[1].X)
+
+	// Once the old key leaves the directory its tokens stop verifying
+	require.NoError(t, os.Remove(filepath.Join(dir, "2026-01-01.pub.pem")))
+	require.NoError(t, jwtManager.ReloadKeys())
+	_, err = jwtManager.ValidateToken(oldToken)
+	assert.Error(t, err)
+}
+
+func TestKeyDirJWTManager_RejectsHS256UnlessLegacySecretSet(t *testing.T) {
+	// Setup
+	dir := t.TempDir()
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	writePrivateKey(t, dir, "current.pem", edKey)
+
+	legacyToken, err := NewJWTManager("old-secret", 24, 168).GenerateAccessToken(1, "test@example.com", false, nil, nil)
+	require.NoError(t, err)
+
+	strict, err := NewKeyDirJWTManager(dir, "", "", 24, 168)
+	require.NoError(t, err)
+	migrating, err := NewKeyDirJWTManager(dir, "", "old-secret", 24, 168)
+	require.NoError(t, err)
+
+	// Execute
+	_, strictErr := strict.ValidateToken(legacyToken)
+	_, migratingErr := migrating.ValidateToken(legacyToken)
+
+	// Assert
+	assert.Error(t, strictErr)
+	assert.NoError(t, migratingErr)
+}
+
+func TestLoadKeySet_UnknownSigningKeyID(t *testing.T) {
+	// Setup
+	dir := t.TempDir()
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	writePrivateKey(t, dir, "current.pem", edKey)
+
+	// Execute
+	_, err = LoadKeySet(dir, "missing")
+
+	// Assert
+	assert.Error(t, err)
+}
@@ -0,0 +1,41 @@
+package pagination
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testCursor struct {
+	Key string `json:"k"`
+	ID  uint64 `json:"i"`
+}
+
+func TestSigner_RoundTrip(t *testing.T) {
+	signer := NewSigner("secret")
+
+	token, err := signer.Encode(testCursor{Key: "2026-01-02T03:04:05Z", ID: 42})
+	assert.NoError(t, err)
+
+	var cursor testCursor
+	assert.NoError(t, signer.Decode(token, &cursor))
+	assert.Equal(t, testCursor{Key: "2026-01-02T03:04:05Z", ID: 42}, cursor)
+}
+
+func TestSigner_RejectsForgedTokens(t *testing.T) {
+	signer := NewSigner("secret")
+	token, _ := signer.Encode(testCursor{Key: "100", ID: 1})
+	payload, signature, _ := strings.Cut(token, ".")
+
+	// A cursor edited by the client keeps the old signature
+	edited, _ := NewSigner("other-secret").Encode(testCursor{Key: "100", ID: 2})
+	editedPayload, _, _ := strings.Cut(edited, ".")
+
+	var cursor testCursor
+	assert.Equal(t, ErrInvalidCursor, signer.Decode(editedPayload+"."+signature, &cursor))
+	assert.Equal(t, ErrInvalidCursor, signer.Decode(edited, &cursor))
+	assert.Equal(t, ErrInvalidCursor, NewSigner("other-secret").Decode(token, &cursor))
+	assert.Equal(t, ErrInvalidCursor, signer.Decode(payload, &cursor))
+	assert.Equal(t, ErrInvalidCursor, signer.Decode("not a token", &cursor))
+}
@@ -0,0 +1,62 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// RFC 6238 appendix B test secret, "12345678901234567890"
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestCode_RFC6238Vectors(t *testing.T) {
+	// The RFC lists 8-digit codes; the last six digits are the 6-digit code
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1111111111: "050471",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+
+	for unix, expected := range vectors {
+		code, err := Code(rfcSecret, Step(time.Unix(unix, 0)))
+		assert.NoError(t, err)
+		assert.Equal(t, expected, code, "time %d", unix)
+	}
+}
+
+func TestValidate_AcceptsAdjacentStepOnly(t *testing.T) {
+	// Setup
+	now := time.Unix(1111111111, 0)
+	previous, _ := Code(rfcSecret, Step(now)-1)
+	stale, _ := Code(rfcSecret, Step(now)-2)
+
+	// Execute
+	step, ok := Validate(rfcSecret, previous, now)
+	_, staleOK := Validate(rfcSecret, stale, now)
+
+	// Assert
+	assert.True(t, ok)
+	assert.Equal(t, Step(now)-1, step)
+	assert.False(t, staleOK)
+}
+
+func TestURI_ContainsSecretAndIssuer(t *testing.T) {
+	uri := URI("Go Commerce", "user@example.com", "ABCDEF")
+
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/Go%20Commerce:user@example.com?"))
+	assert.Contains(t, uri, "secret=ABCDEF")
+	assert.Contains(t, uri, "issuer=Go%20Commerce")
+}
+
+func TestGenerateSecret_Decodes(t *testing.T) {
+	secret, err := GenerateSecret()
+	assert.NoError(t, err)
+
+	_, err = Code(secret, 1)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// stopWords are common Indonesian words that carry no meaning for product
+// search, e.g. "kaos untuk anak" should match on "kaos" and "anak" only
+var stopWords = map[string]bool{
+	"ada": true, "adalah": true, "agar": true, "akan": true, "atau": true,
+	"bagi": true, "bahwa": true, "banyak": true, "beberapa": true, "belum": true,
+	"bisa": true, "buat": true, "dalam": true, "dan": true, "dari": true,
+	"dengan": true, "di": true, "dia": true, "hanya": true, "ini": true,
+	"itu": true, "jadi": true, "juga": true, "ke": true, "kami": true,
+	"kita": true, "lagi": true, "lebih": true, "maka": true, "mau": true,
+	"oleh": true, "pada": true, "para": true, "per": true, "saja": true,
+	"sangat": true, "secara": true, "sudah": true, "tanpa": true, "tapi": true,
+	"telah": true, "tentang": true, "untuk": true, "yang": true,
+}
+
+// IsStopWord reports whether token is an Indonesian stop-word
+func IsStopWord(token string) bool {
+	return stopWords[token]
+}
+
+// SearchTokens splits text into lowercase words of letters and digits
+func SearchTokens(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// SearchTerms is the set of tokens of query worth searching for, in order of
+// appearance and without stop-words
+func SearchTerms(query string) []string {
+	var terms []string
+	seen := make(map[string]bool)
+	for _, token := range SearchTokens(query) {
+		if IsStopWord(token) || seen[token] {
+			continue
+		}
+		seen[token] = true
+		terms = append(terms, token)
+	}
+	return terms
+}
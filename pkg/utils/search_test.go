@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchTerms(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			name:     "Lowercases and splits on punctuation",
+			input:    "Kaos Polos, Hitam-Putih",
+			expected: []string{"kaos", "polos", "hitam", "putih"},
+		},
+		{
+			name:     "Drops Indonesian stop-words",
+			input:    "sepatu lari untuk anak yang murah",
+			expected: []string{"sepatu", "lari", "anak", "murah"},
+		},
+		{
+			name:     "Drops duplicates",
+			input:    "tas tas kulit",
+			expected: []string{"tas", "kulit"},
+		},
+		{
+			name:     "Only stop-words",
+			input:    "dan atau",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, SearchTerms(tt.input))
+		})
+	}
+}
@@ -0,0 +1,48 @@
+// Package webhook signs and verifies webhook bodies with HMAC-SHA256. The
+// signature covers the timestamp and the raw body, "<timestamp>.<body>", and
+// is sent hex encoded.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidSignature = errors.New("INVALID_SIGNATURE")
+	ErrExpired          = errors.New("CALLBACK_EXPIRED")
+)
+
+// Sign returns the hex signature of body sent at timestamp (unix seconds)
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature and that timestamp lies within tolerance of now
+// in either direction, so captured requests cannot be replayed later
+func Verify(secret, timestamp, signature string, body []byte, now time.Time, tolerance time.Duration) error {
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	expected := Sign(secret, timestamp, body)
+	if !hmac.Equal([]byte(expected), []byte(strings.ToLower(signature))) {
+		return ErrInvalidSignature
+	}
+
+	age := now.Sub(time.Unix(unix, 0))
+	if age > tolerance || age < -tolerance {
+		return ErrExpired
+	}
+	return nil
+}
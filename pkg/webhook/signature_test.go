@@ -0,0 +1,41 @@
+package webhook
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerify_AcceptsSignedBody(t *testing.T) {
+	now := time.Unix(1760000000, 0)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	body := []byte(`{"status":"success","gateway_ref":"PAY-1"}`)
+
+	signature := Sign("secret", timestamp, body)
+
+	assert.NoError(t, Verify("secret", timestamp, signature, body, now, 5*time.Minute))
+}
+
+func TestVerify_RejectsTamperedBodyAndWrongSecret(t *testing.T) {
+	now := time.Unix(1760000000, 0)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	body := []byte(`{"status":"success","gateway_ref":"PAY-1"}`)
+	signature := Sign("secret", timestamp, body)
+
+	assert.Equal(t, ErrInvalidSignature, Verify("secret", timestamp, signature, []byte(`{"status":"failed","gateway_ref":"PAY-1"}`), now, 5*time.Minute))
+	assert.Equal(t, ErrInvalidSignature, Verify("other-secret", timestamp, signature, body, now, 5*time.Minute))
+	assert.Equal(t, ErrInvalidSignature, Verify("secret", "not-a-timestamp", signature, body, now, 5*time.Minute))
+}
+
+func TestVerify_RejectsTimestampOutsideTolerance(t *testing.T) {
+	now := time.Unix(1760000000, 0)
+	body := []byte(`{}`)
+
+	old := strconv.FormatInt(now.Add(-6*time.Minute).Unix(), 10)
+	future := strconv.FormatInt(now.Add(6*time.Minute).Unix(), 10)
+
+	assert.Equal(t, ErrExpired, Verify("secret", old, Sign("secret", old, body), body, now, 5*time.Minute))
+	assert.Equal(t, ErrExpired, Verify("secret", future, Sign("secret", future, body), body, now, 5*time.Minute))
+}